@@ -0,0 +1,418 @@
+// Package agentstats computes agent quality scorecards for Gas Town.
+//
+// Every polecat assignment made by gt sling is an attempt. Attempts are
+// rebuilt from the activity events log (sling, done, escalation and merge
+// queue events) and joined with merge-request beads, then aggregated per
+// runtime, model, formula, rig or model route. The result is evidence for
+// choosing which agent and model to sling work to.
+package agentstats
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Dimension is an attribute scorecards are grouped by.
+type Dimension string
+
+// Supported scorecard dimensions.
+const (
+	ByRuntime Dimension = "runtime"
+	ByModel   Dimension = "model"
+	ByFormula Dimension = "formula"
+	ByRig     Dimension = "rig"
+	ByRoute   Dimension = "route"
+)
+
+// Dimensions lists all dimensions in display order.
+var Dimensions = []Dimension{ByRuntime, ByModel, ByFormula, ByRig, ByRoute}
+
+// Placeholder keys for attempts without a recorded value.
+const (
+	unknownKey = "unknown"
+	noneKey    = "none"
+	defaultKey = "default"
+)
+
+// MergeRequest is the part of a merge-request bead scorecards use.
+type MergeRequest struct {
+	ID          string
+	SourceIssue string // Work bead the MR merges
+	Branch      string
+	Closed      bool
+	Merged      bool // Closed with reason "merged"
+	Conflicts   int  // Conflict-resolution cycles (retry_count)
+	Failed      bool // A failed merge attempt was recorded on the bead
+}
+
+// MergeRequestFromIssue extracts scorecard data from a merge-request bead.
+// Returns nil if the issue carries no MR fields.
+func MergeRequestFromIssue(issue *beads.Issue) *MergeRequest {
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		return nil
+	}
+	reason := fields.CloseReason
+	if reason == "" {
+		reason = issue.CloseReason
+	}
+	closed := issue.Status == "closed"
+	return &MergeRequest{
+		ID:          issue.ID,
+		SourceIssue: fields.SourceIssue,
+		Branch:      fields.Branch,
+		Closed:      closed,
+		Merged:      closed && strings.EqualFold(reason, "merged"),
+		Conflicts:   fields.RetryCount,
+		Failed:      fields.LastFailure != "",
+	}
+}
+
+// Attempt is one polecat assignment, from sling to done or abandonment.
+type Attempt struct {
+	Bead        string
+	Polecat     string // Agent address (rig/polecats/name)
+	Rig         string
+	Runtime     string
+	Model       string
+	Formula     string
+	Route       string
+	SlungAt     time.Time
+	DoneAt      time.Time // Zero until gt done
+	Abandoned   bool      // Re-slung before the polecat finished
+	Escalations int
+
+	MR            *MergeRequest
+	MergeFailures int // merge_failed events for the MR
+	ConflictFails int // merge_failed events caused by conflicts
+	Rework        int // changes requested in review, or reverted after merge
+}
+
+// Done reports whether the polecat finished the attempt (gt done).
+func (a *Attempt) Done() bool {
+	return !a.DoneAt.IsZero()
+}
+
+// Succeeded reports whether the attempt finished without its MR being rejected.
+func (a *Attempt) Succeeded() bool {
+	return a.Done() && (a.MR == nil || !a.MR.Closed || a.MR.Merged)
+}
+
+// Failed reports whether the attempt was abandoned or its MR rejected.
+func (a *Attempt) Failed() bool {
+	return a.Abandoned || (a.MR != nil && a.MR.Closed && !a.MR.Merged)
+}
+
+// FirstTryMerge reports whether the attempt's MR merged without any failed
+// merge attempt or conflict.
+func (a *Attempt) FirstTryMerge() bool {
+	return a.MR != nil && a.MR.Merged && a.MergeFailures == 0 && !a.MR.Failed && a.MR.Conflicts == 0
+}
+
+// Conflicts returns the number of merge conflicts the attempt ran into.
+func (a *Attempt) Conflicts() int {
+	if a.MR != nil && a.MR.Conflicts > a.ConflictFails {
+		return a.MR.Conflicts
+	}
+	return a.ConflictFails
+}
+
+// Key returns the attempt's grouping key for a dimension.
+func (a *Attempt) Key(d Dimension) string {
+	switch d {
+	case ByRuntime:
+		return orDefault(a.Runtime, unknownKey)
+	case ByModel:
+		return orDefault(a.Runtime, unknownKey) + "/" + orDefault(a.Model, defaultKey)
+	case ByFormula:
+		return orDefault(a.Formula, noneKey)
+	case ByRig:
+		return orDefault(a.Rig, unknownKey)
+	case ByRoute:
+		return orDefault(a.Route, noneKey)
+	}
+	return unknownKey
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// Scorecard aggregates the attempts sharing a dimension key.
+type Scorecard struct {
+	Key               string  `json:"key"`
+	Slung             int     `json:"slung"`
+	Done              int     `json:"done"`
+	Succeeded         int     `json:"succeeded"`
+	Failed            int     `json:"failed"`
+	SuccessRate       float64 `json:"success_rate"`
+	MRs               int     `json:"mrs"`
+	Merged            int     `json:"merged"`
+	FirstTryMerged    int     `json:"first_try_merged"`
+	FirstTryMergeRate float64 `json:"first_try_merge_rate"`
+	Conflicts         int     `json:"conflicts"`
+	Rework            int     `json:"rework"`
+	MedianTimeToDone  float64 `json:"median_time_to_done_minutes"`
+	Escalations       int     `json:"escalations"`
+	EscalationRate    float64 `json:"escalation_rate"`
+}
+
+// Options filter the attempts a report covers.
+type Options struct {
+	Since      time.Time   // Only attempts slung at or after this time (zero for all)
+	Rig        string      // Only attempts in this rig (empty for all)
+	Dimensions []Dimension // Dimensions to report (nil for all)
+}
+
+// Report holds scorecards per dimension.
+type Report struct {
+	Attempts   int                       `json:"attempts"`
+	Scorecards map[Dimension][]Scorecard `json:"scorecards"`
+}
+
+// LoadEvents reads the town's raw events log, skipping malformed lines.
+// Returns no events if the log does not exist yet.
+func LoadEvents(townRoot string) ([]events.Event, error) {
+	f, err := os.Open(filepath.Join(townRoot, events.EventsFile)) //nolint:gosec // G304: path is constructed from town root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var evts []events.Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e events.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		evts = append(evts, e)
+	}
+	return evts, scanner.Err()
+}
+
+// BuildAttempts reconstructs polecat attempts from events and joins them
+// with merge requests.
+func BuildAttempts(evts []events.Event, mrs []*MergeRequest) []*Attempt {
+	type timed struct {
+		ts time.Time
+		e  events.Event
+	}
+	ordered := make([]timed, 0, len(evts))
+	for _, e := range evts {
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			continue
+		}
+		ordered = append(ordered, timed{ts, e})
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].ts.Before(ordered[j].ts) })
+
+	var attempts []*Attempt
+	byBead := make(map[string]*Attempt)    // Latest attempt per work bead
+	byPolecat := make(map[string]*Attempt) // Latest attempt per polecat
+	failures := make(map[string][]string)  // merge_failed reasons by MR ID or branch
+	rework := make(map[string]int)         // Rework events by MR ID
+
+	for _, t := range ordered {
+		e := t.e
+		switch e.Type {
+		case events.TypeSling:
+			target := payloadString(e, "target")
+			if !strings.Contains(target, "/polecats/") {
+				continue
+			}
+			bead := payloadString(e, "bead")
+			if prev := byBead[bead]; prev != nil && !prev.Done() {
+				prev.Abandoned = true
+			}
+			a := &Attempt{
+				Bead:    bead,
+				Polecat: target,
+				Rig:     strings.SplitN(target, "/", 2)[0],
+				Runtime: payloadString(e, "runtime"),
+				Model:   payloadString(e, "model"),
+				Formula: payloadString(e, "formula"),
+				Route:   payloadString(e, "route"),
+				SlungAt: t.ts,
+			}
+			attempts = append(attempts, a)
+			byBead[bead] = a
+			byPolecat[target] = a
+
+		case events.TypeDone:
+			a := byBead[payloadString(e, "bead")]
+			if a == nil {
+				a = byPolecat[e.Actor]
+			}
+			if a != nil && !a.Done() && !a.Abandoned {
+				a.DoneAt = t.ts
+			}
+
+		case events.TypeEscalationSent:
+			a := byPolecat[e.Actor]
+			if a == nil {
+				a = byPolecat[payloadString(e, "target")]
+			}
+			if a != nil && !a.Done() && !a.Abandoned {
+				a.Escalations++
+			}
+
+		case events.TypeMergeFailed:
+			key := payloadString(e, "mr")
+			if key == "" {
+				key = payloadString(e, "branch")
+			}
+			if key != "" {
+				failures[key] = append(failures[key], payloadString(e, "reason"))
+			}
+
+		case events.TypeMergeChangesRequested, events.TypeMergeReverted:
+			if mr := payloadString(e, "mr"); mr != "" {
+				rework[mr]++
+			}
+		}
+	}
+
+	for _, mr := range mrs {
+		a := byBead[mr.SourceIssue]
+		if a == nil {
+			continue
+		}
+		a.MR = mr
+		reasons := append(append([]string(nil), failures[mr.ID]...), failures[mr.Branch]...)
+		a.MergeFailures = len(reasons)
+		for _, r := range reasons {
+			if strings.Contains(strings.ToLower(r), "conflict") {
+				a.ConflictFails++
+			}
+		}
+		a.Rework = rework[mr.ID]
+	}
+
+	return attempts
+}
+
+// payloadString returns a string payload field, or "".
+func payloadString(e events.Event, key string) string {
+	s, _ := e.Payload[key].(string)
+	return s
+}
+
+// Compute builds a report from events and merge requests.
+func Compute(evts []events.Event, mrs []*MergeRequest, opts Options) *Report {
+	var attempts []*Attempt
+	for _, a := range BuildAttempts(evts, mrs) {
+		if !opts.Since.IsZero() && a.SlungAt.Before(opts.Since) {
+			continue
+		}
+		if opts.Rig != "" && a.Rig != opts.Rig {
+			continue
+		}
+		attempts = append(attempts, a)
+	}
+
+	dims := opts.Dimensions
+	if len(dims) == 0 {
+		dims = Dimensions
+	}
+	report := &Report{
+		Attempts:   len(attempts),
+		Scorecards: make(map[Dimension][]Scorecard, len(dims)),
+	}
+	for _, d := range dims {
+		report.Scorecards[d] = Aggregate(attempts, d)
+	}
+	return report
+}
+
+// Aggregate groups attempts by a dimension into scorecards, busiest first.
+func Aggregate(attempts []*Attempt, d Dimension) []Scorecard {
+	groups := make(map[string][]*Attempt)
+	for _, a := range attempts {
+		key := a.Key(d)
+		groups[key] = append(groups[key], a)
+	}
+
+	cards := make([]Scorecard, 0, len(groups))
+	for key, group := range groups {
+		cards = append(cards, score(key, group))
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		if cards[i].Slung != cards[j].Slung {
+			return cards[i].Slung > cards[j].Slung
+		}
+		return cards[i].Key < cards[j].Key
+	})
+	return cards
+}
+
+// score computes one scorecard.
+func score(key string, attempts []*Attempt) Scorecard {
+	c := Scorecard{Key: key, Slung: len(attempts)}
+	var durations []float64
+	for _, a := range attempts {
+		if a.Done() {
+			c.Done++
+			durations = append(durations, a.DoneAt.Sub(a.SlungAt).Minutes())
+		}
+		if a.Succeeded() {
+			c.Succeeded++
+		}
+		if a.Failed() {
+			c.Failed++
+		}
+		if a.MR != nil {
+			c.MRs++
+			if a.MR.Merged {
+				c.Merged++
+			}
+		}
+		if a.FirstTryMerge() {
+			c.FirstTryMerged++
+		}
+		c.Conflicts += a.Conflicts()
+		c.Rework += a.Rework
+		c.Escalations += a.Escalations
+	}
+
+	c.SuccessRate = ratio(c.Succeeded, c.Succeeded+c.Failed)
+	c.FirstTryMergeRate = ratio(c.FirstTryMerged, c.Merged)
+	c.EscalationRate = ratio(c.Escalations, c.Slung)
+	c.MedianTimeToDone = median(durations)
+	return c
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[mid]
+	}
+	return (values[mid-1] + values[mid]) / 2
+}
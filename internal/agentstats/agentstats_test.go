@@ -0,0 +1,165 @@
+package agentstats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+var base = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func event(minutes int, typ, actor string, payload map[string]interface{}) events.Event {
+	return events.Event{
+		Timestamp: base.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339),
+		Type:      typ,
+		Actor:     actor,
+		Payload:   payload,
+	}
+}
+
+func testEvents() []events.Event {
+	return []events.Event{
+		// Toast (claude/opus) finishes gt-1 in 30m, merged first try
+		event(0, events.TypeSling, "mayor", events.SlingRuntimePayload("gt-1", "gastown/polecats/Toast", "claude", "opus", "p0", "mol-polecat-work")),
+		event(30, events.TypeDone, "gastown/polecats/Toast", events.DonePayload("gt-1", "polecat/Toast/gt-1")),
+
+		// Nux (codex) escalates, then finishes gt-2 in 90m; the MR hits a conflict
+		event(0, events.TypeSling, "mayor", events.SlingRuntimePayload("gt-2", "gastown/polecats/Nux", "codex", "", "", "mol-polecat-work")),
+		event(10, events.TypeEscalationSent, "gastown/polecats/Nux", events.EscalationPayload("hq-esc", "gastown/polecats/Nux", "mayor", "stuck")),
+		event(90, events.TypeDone, "gastown/polecats/Nux", events.DonePayload("gt-2", "polecat/Nux/gt-2")),
+		event(95, events.TypeMergeFailed, "gastown/refinery", events.MergePayload("gt-mr2", "Nux", "polecat/Nux/gt-2", "conflict with main")),
+
+		// Slit (codex) is replaced on gt-3 by Furiosa before finishing
+		event(0, events.TypeSling, "mayor", events.SlingRuntimePayload("gt-3", "gastown/polecats/Slit", "codex", "", "", "")),
+		event(60, events.TypeSling, "mayor", events.SlingRuntimePayload("gt-3", "gastown/polecats/Furiosa", "claude", "opus", "p0", "")),
+
+		// Slinging to crew is not a polecat attempt
+		event(0, events.TypeSling, "mayor", events.SlingPayload("gt-4", "gastown/crew/max")),
+	}
+}
+
+func testMRs() []*MergeRequest {
+	return []*MergeRequest{
+		{ID: "gt-mr1", SourceIssue: "gt-1", Branch: "polecat/Toast/gt-1", Closed: true, Merged: true},
+		{ID: "gt-mr2", SourceIssue: "gt-2", Branch: "polecat/Nux/gt-2", Closed: true, Merged: true, Conflicts: 1},
+	}
+}
+
+func findCard(t *testing.T, cards []Scorecard, key string) Scorecard {
+	t.Helper()
+	for _, c := range cards {
+		if c.Key == key {
+			return c
+		}
+	}
+	t.Fatalf("no scorecard for %q in %+v", key, cards)
+	return Scorecard{}
+}
+
+func TestBuildAttempts(t *testing.T) {
+	attempts := BuildAttempts(testEvents(), testMRs())
+	if len(attempts) != 4 {
+		t.Fatalf("got %d attempts, want 4", len(attempts))
+	}
+
+	toast, nux, slit, furiosa := attempts[0], attempts[1], attempts[2], attempts[3]
+	if !toast.Succeeded() || !toast.FirstTryMerge() {
+		t.Errorf("Toast: succeeded=%v firstTry=%v, want both", toast.Succeeded(), toast.FirstTryMerge())
+	}
+	if nux.Escalations != 1 || nux.Conflicts() != 1 || nux.FirstTryMerge() {
+		t.Errorf("Nux: escalations=%d conflicts=%d firstTry=%v", nux.Escalations, nux.Conflicts(), nux.FirstTryMerge())
+	}
+	if !slit.Abandoned || !slit.Failed() {
+		t.Errorf("Slit should be abandoned and failed: %+v", slit)
+	}
+	if furiosa.Done() || furiosa.Failed() {
+		t.Errorf("Furiosa should still be in flight: %+v", furiosa)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	report := Compute(testEvents(), testMRs(), Options{})
+	if report.Attempts != 4 {
+		t.Fatalf("Attempts = %d, want 4", report.Attempts)
+	}
+
+	codex := findCard(t, report.Scorecards[ByRuntime], "codex")
+	if codex.Slung != 2 || codex.Succeeded != 1 || codex.Failed != 1 || codex.SuccessRate != 0.5 {
+		t.Errorf("codex card = %+v", codex)
+	}
+	if codex.Escalations != 1 || codex.EscalationRate != 0.5 || codex.MedianTimeToDone != 90 {
+		t.Errorf("codex card = %+v", codex)
+	}
+
+	opus := findCard(t, report.Scorecards[ByModel], "claude/opus")
+	if opus.Slung != 2 || opus.Merged != 1 || opus.FirstTryMergeRate != 1 || opus.MedianTimeToDone != 30 {
+		t.Errorf("claude/opus card = %+v", opus)
+	}
+
+	formula := findCard(t, report.Scorecards[ByFormula], "mol-polecat-work")
+	if formula.Merged != 2 || formula.FirstTryMerged != 1 || formula.Conflicts != 1 || formula.MedianTimeToDone != 60 {
+		t.Errorf("formula card = %+v", formula)
+	}
+
+	findCard(t, report.Scorecards[ByRoute], "none")
+	findCard(t, report.Scorecards[ByModel], "codex/default")
+}
+
+func TestCompute_Filters(t *testing.T) {
+	report := Compute(testEvents(), testMRs(), Options{
+		Since:      base.Add(time.Hour),
+		Dimensions: []Dimension{ByRig},
+	})
+	if report.Attempts != 1 {
+		t.Errorf("Attempts since +1h = %d, want 1", report.Attempts)
+	}
+	if len(report.Scorecards) != 1 {
+		t.Errorf("got %d dimensions, want only rig", len(report.Scorecards))
+	}
+
+	report = Compute(testEvents(), testMRs(), Options{Rig: "other"})
+	if report.Attempts != 0 {
+		t.Errorf("Attempts in other rig = %d, want 0", report.Attempts)
+	}
+}
+
+func TestMergeRequestFromIssue(t *testing.T) {
+	mr := MergeRequestFromIssue(&beads.Issue{
+		ID:          "gt-mr1",
+		Status:      "closed",
+		Description: "branch: polecat/Toast/gt-1\nsource_issue: gt-1\nretry_count: 2\nclose_reason: merged",
+	})
+	if mr == nil || !mr.Merged || mr.SourceIssue != "gt-1" || mr.Conflicts != 2 {
+		t.Errorf("MergeRequestFromIssue = %+v", mr)
+	}
+
+	if MergeRequestFromIssue(&beads.Issue{ID: "gt-x", Description: "just prose"}) != nil {
+		t.Error("expected nil for issue without MR fields")
+	}
+}
+
+func TestLoadEvents(t *testing.T) {
+	townRoot := t.TempDir()
+	if evts, err := LoadEvents(townRoot); err != nil || evts != nil {
+		t.Fatalf("missing log: got %v, %v", evts, err)
+	}
+
+	data := `{"ts":"2026-03-01T12:00:00Z","type":"sling","actor":"mayor","payload":{"bead":"gt-1"}}
+not json
+{"ts":"2026-03-01T12:30:00Z","type":"done","actor":"gastown/polecats/Toast"}
+`
+	if err := os.WriteFile(filepath.Join(townRoot, events.EventsFile), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	evts, err := LoadEvents(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evts) != 2 {
+		t.Errorf("got %d events, want 2 (malformed line skipped)", len(evts))
+	}
+}
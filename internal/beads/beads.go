@@ -34,6 +34,7 @@ type Issue struct {
 	CreatedBy   string   `json:"created_by,omitempty"`
 	UpdatedAt   string   `json:"updated_at"`
 	ClosedAt    string   `json:"closed_at,omitempty"`
+	CloseReason string   `json:"close_reason,omitempty"`
 	Parent      string   `json:"parent,omitempty"`
 	Assignee    string   `json:"assignee,omitempty"`
 	Children    []string `json:"children,omitempty"`
@@ -219,6 +220,7 @@ func (b *Beads) run(args ...string) ([]byte, error) {
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+	b.invalidateAfter(args)
 	if err != nil {
 		return nil, b.wrapError(err, stderr.String(), args)
 	}
@@ -287,7 +289,12 @@ func filterBeadsEnv(environ []string) []string {
 }
 
 // List returns issues matching the given options.
+// Served from the read cache when it holds every issue; otherwise bd list.
 func (b *Beads) List(opts ListOptions) ([]*Issue, error) {
+	if issues, ok := b.listCached(opts); ok {
+		return issues, nil
+	}
+
 	args := []string{"list", "--json"}
 
 	if opts.Status != "" {
@@ -400,7 +407,13 @@ func (b *Beads) ReadyWithType(issueType string) ([]*Issue, error) {
 }
 
 // Show returns detailed information about an issue.
+// Served from the read cache when it holds the issue; otherwise bd show
+// (which also resolves IDs routed to other databases).
 func (b *Beads) Show(id string) (*Issue, error) {
+	if found, _ := b.showCached([]string{id}); found[id] != nil {
+		return found[id], nil
+	}
+
 	out, err := b.run("show", id, "--json")
 	if err != nil {
 		return nil, err
@@ -426,12 +439,18 @@ func (b *Beads) ShowMultiple(ids []string) (map[string]*Issue, error) {
 		return make(map[string]*Issue), nil
 	}
 
+	// Serve what the read cache holds; ask bd only for the rest
+	result, missing := b.showCached(ids)
+	if len(missing) == 0 {
+		return result, nil
+	}
+
 	// bd show supports multiple IDs
-	args := append([]string{"show", "--json"}, ids...)
+	args := append([]string{"show", "--json"}, missing...)
 	out, err := b.run(args...)
 	if err != nil {
-		// If bd fails, return empty map (some IDs might not exist)
-		return make(map[string]*Issue), nil
+		// If bd fails, return what we have (some IDs might not exist)
+		return result, nil
 	}
 
 	var issues []*Issue
@@ -439,7 +458,6 @@ func (b *Beads) ShowMultiple(ids []string) (map[string]*Issue, error) {
 		return nil, fmt.Errorf("parsing bd show output: %w", err)
 	}
 
-	result := make(map[string]*Issue, len(issues))
 	for _, issue := range issues {
 		result[issue.ID] = issue
 	}
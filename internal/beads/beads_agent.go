@@ -41,6 +41,9 @@ type AgentFields struct {
 	CleanupStatus     string // ZFC: polecat self-reports git state (clean, has_uncommitted, has_stash, has_unpushed)
 	ActiveMR          string // Currently active merge request bead ID (for traceability)
 	NotificationLevel string // DND mode: verbose, normal, muted (default: normal)
+	Runtime           string // Agent the session runs (e.g., claude, gemini); empty for rig default
+	Model             string // Model selected for the session (e.g., opus); empty for agent default
+	ModelRoute        string // Name of the model route that chose Runtime/Model
 	// Note: RoleBead field removed - role definitions are now config-based.
 	// See internal/config/roles/*.toml and config-based-roles.md.
 }
@@ -97,6 +100,17 @@ func FormatAgentDescription(title string, fields *AgentFields) string {
 		lines = append(lines, "notification_level: null")
 	}
 
+	// Runtime selection is recorded at polecat spawn; omit it until then
+	if fields.Runtime != "" {
+		lines = append(lines, fmt.Sprintf("runtime: %s", fields.Runtime))
+	}
+	if fields.Model != "" {
+		lines = append(lines, fmt.Sprintf("model: %s", fields.Model))
+	}
+	if fields.ModelRoute != "" {
+		lines = append(lines, fmt.Sprintf("model_route: %s", fields.ModelRoute))
+	}
+
 	return strings.Join(lines, "\n")
 }
 
@@ -138,6 +152,12 @@ func ParseAgentFields(description string) *AgentFields {
 			fields.ActiveMR = value
 		case "notification_level":
 			fields.NotificationLevel = value
+		case "runtime":
+			fields.Runtime = value
+		case "model":
+			fields.Model = value
+		case "model_route":
+			fields.ModelRoute = value
 		}
 	}
 
@@ -394,6 +414,28 @@ func (b *Beads) UpdateAgentActiveMR(id string, activeMR string) error {
 	return b.Update(id, UpdateOptions{Description: &description})
 }
 
+// UpdateAgentRuntime records the agent, model and model route a session was
+// started with, for cost/quality analysis in the polecat CV.
+// Empty values clear the corresponding field.
+func (b *Beads) UpdateAgentRuntime(id, runtime, model, route string) error {
+	// First get current issue to preserve other fields
+	issue, err := b.Show(id)
+	if err != nil {
+		return err
+	}
+
+	// Parse existing fields
+	fields := ParseAgentFields(issue.Description)
+	fields.Runtime = runtime
+	fields.Model = model
+	fields.ModelRoute = route
+
+	// Format new description
+	description := FormatAgentDescription(issue.Title, fields)
+
+	return b.Update(id, UpdateOptions{Description: &description})
+}
+
 // UpdateAgentNotificationLevel updates the notification_level field in an agent bead.
 // Valid levels: verbose, normal, muted (DND mode).
 // Pass empty string to reset to default (normal).
@@ -480,6 +522,9 @@ func (b *Beads) CloseAndClearAgentBead(id, reason string) error {
 	fields.HookBead = ""     // Clear hook_bead
 	fields.ActiveMR = ""     // Clear active_mr
 	fields.CleanupStatus = "" // Clear cleanup_status
+	fields.Runtime = ""
+	fields.Model = ""
+	fields.ModelRoute = ""
 	fields.AgentState = "closed"
 
 	// Update description with cleared fields
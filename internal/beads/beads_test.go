@@ -558,6 +558,55 @@ author: someone`,
 	}
 }
 
+// TestMRFieldsReviewRoundTrip tests that review gate fields survive SetMRFields.
+func TestMRFieldsReviewRoundTrip(t *testing.T) {
+	issue := &Issue{Description: "branch: polecat/Nux/gt-xyz\ntarget: main\nreview_state: pending\n\nSome notes"}
+	fields := ParseMRFields(issue)
+	if fields.ReviewState != "pending" {
+		t.Fatalf("ReviewState = %q, want pending", fields.ReviewState)
+	}
+
+	fields.ReviewState = "approved"
+	fields.ReviewedBy = "gastown/crew/max"
+	fields.ReviewedSHA = "abc123"
+	fields.ReviewTaskID = "gt-rev1"
+	issue.Description = SetMRFields(issue, fields)
+
+	got := ParseMRFields(issue)
+	if got.ReviewState != "approved" || got.ReviewedBy != "gastown/crew/max" ||
+		got.ReviewedSHA != "abc123" || got.ReviewTaskID != "gt-rev1" {
+		t.Errorf("review fields = %+v, want approved by gastown/crew/max at abc123 (gt-rev1)", got)
+	}
+	if strings.Count(issue.Description, "review_state:") != 1 {
+		t.Errorf("description has duplicate review_state lines:\n%s", issue.Description)
+	}
+	if !strings.Contains(issue.Description, "Some notes") {
+		t.Errorf("description lost prose:\n%s", issue.Description)
+	}
+}
+
+// TestMRFieldsLandingRoundTrip tests that a pending landing PR is recorded
+// and cleared again.
+func TestMRFieldsLandingRoundTrip(t *testing.T) {
+	issue := &Issue{Description: "branch: polecat/Nux/gt-xyz\ntarget: main"}
+	fields := ParseMRFields(issue)
+	fields.LandingPR = 42
+	fields.LandingSince = "2026-01-02T15:04:05Z"
+	issue.Description = SetMRFields(issue, fields)
+
+	got := ParseMRFields(issue)
+	if got.LandingPR != 42 || got.LandingSince != "2026-01-02T15:04:05Z" {
+		t.Errorf("landing fields = %+v, want PR 42 since 2026-01-02T15:04:05Z", got)
+	}
+
+	got.LandingPR = 0
+	got.LandingSince = ""
+	issue.Description = SetMRFields(issue, got)
+	if strings.Contains(issue.Description, "landing_") {
+		t.Errorf("cleared landing fields still in description:\n%s", issue.Description)
+	}
+}
+
 // TestMRFieldsRoundTrip tests that parse/format round-trips correctly.
 func TestMRFieldsRoundTrip(t *testing.T) {
 	original := &MRFields{
@@ -1108,6 +1157,28 @@ func TestIsAgentSessionBead(t *testing.T) {
 	}
 }
 
+// TestAgentFieldsRuntimeRoundTrip tests that model routing fields survive
+// formatting and parsing, and are omitted when unset.
+func TestAgentFieldsRuntimeRoundTrip(t *testing.T) {
+	fields := &AgentFields{
+		RoleType:   "polecat",
+		Rig:        "gastown",
+		AgentState: "spawning",
+		Runtime:    "claude",
+		Model:      "opus",
+		ModelRoute: "p0-bugs",
+	}
+	got := ParseAgentFields(FormatAgentDescription("Polecat Toast", fields))
+	if got.Runtime != "claude" || got.Model != "opus" || got.ModelRoute != "p0-bugs" {
+		t.Errorf("runtime fields = %q/%q/%q, want claude/opus/p0-bugs", got.Runtime, got.Model, got.ModelRoute)
+	}
+
+	desc := FormatAgentDescription("Polecat Toast", &AgentFields{RoleType: "polecat"})
+	if strings.Contains(desc, "runtime:") || strings.Contains(desc, "model") {
+		t.Errorf("unset runtime fields should be omitted, got:\n%s", desc)
+	}
+}
+
 // TestParseRoleConfig tests parsing role configuration from descriptions.
 func TestParseRoleConfig(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,277 @@
+package beads
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/beadstore"
+)
+
+// Reads are served from an in-process snapshot of the beads database when
+// one is available (see package beadstore); writes always go through bd
+// and invalidate the snapshot. Anything the snapshot cannot answer
+// exactly - an ID it does not hold (possibly routed to another rig), or a
+// list query against a snapshot without ephemeral issues - falls back to bd.
+
+// readOnlyCommands are bd subcommands that never modify the database.
+var readOnlyCommands = map[string]bool{
+	"list":    true,
+	"show":    true,
+	"ready":   true,
+	"blocked": true,
+	"stats":   true,
+	"search":  true,
+	"count":   true,
+	"info":    true,
+	"version": true,
+}
+
+// store returns the read cache for this wrapper's database.
+func (b *Beads) store() *beadstore.Store {
+	return beadstore.For(b.getResolvedBeadsDir())
+}
+
+// snapshot returns a fresh snapshot, or nil if reads must go through bd.
+func (b *Beads) snapshot() *beadstore.Snapshot {
+	snap, err := b.store().Snapshot()
+	if err != nil {
+		return nil
+	}
+	return snap
+}
+
+// invalidateAfter drops the cached snapshot after a bd command that may
+// have written to the database.
+func (b *Beads) invalidateAfter(args []string) {
+	if len(args) > 0 && readOnlyCommands[args[0]] {
+		return
+	}
+	if len(args) > 1 && args[0] == "sync" && args[1] == "--status" {
+		return
+	}
+	b.store().Invalidate()
+}
+
+// listCached answers a List query from the snapshot.
+// ok is false if the snapshot cannot answer it.
+func (b *Beads) listCached(opts ListOptions) (issues []*Issue, ok bool) {
+	snap := b.snapshot()
+	if snap == nil || !snap.Complete {
+		return nil, false
+	}
+
+	label := opts.Label
+	if label == "" && opts.Type != "" {
+		label = "gt:" + opts.Type
+	}
+
+	for _, rec := range snap.Records() {
+		switch opts.Status {
+		case "":
+			if rec.Status == "closed" {
+				continue
+			}
+		case "all":
+		default:
+			if rec.Status != opts.Status {
+				continue
+			}
+		}
+		if label != "" && !containsString(rec.Labels, label) {
+			continue
+		}
+		if opts.Priority >= 0 && rec.Priority != opts.Priority {
+			continue
+		}
+		if opts.Assignee != "" && rec.Assignee != opts.Assignee {
+			continue
+		}
+		if opts.NoAssignee && rec.Assignee != "" {
+			continue
+		}
+		issue := issueFromRecord(snap, rec, false)
+		if opts.Parent != "" && issue.Parent != opts.Parent {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+
+	// bd lists by priority, newest first within a priority.
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Priority != issues[j].Priority {
+			return issues[i].Priority < issues[j].Priority
+		}
+		return issues[i].CreatedAt > issues[j].CreatedAt
+	})
+	return issues, true
+}
+
+// showCached returns the issues the snapshot holds, with show-level detail
+// (dependencies and dependents), and the IDs it does not hold.
+func (b *Beads) showCached(ids []string) (found map[string]*Issue, missing []string) {
+	found = make(map[string]*Issue, len(ids))
+	snap := b.snapshot()
+	if snap == nil {
+		return found, ids
+	}
+	for _, id := range ids {
+		if rec, ok := snap.Get(id); ok {
+			found[id] = issueFromRecord(snap, rec, true)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing
+}
+
+// issueFromRecord builds the Issue bd would print for a record. With
+// detail, Dependencies and Dependents are filled in as bd show does.
+func issueFromRecord(snap *beadstore.Snapshot, rec *beadstore.Record, detail bool) *Issue {
+	issue := &Issue{
+		ID:          rec.ID,
+		Title:       rec.Title,
+		Description: rec.Description,
+		Status:      rec.Status,
+		Priority:    rec.Priority,
+		Type:        rec.IssueType,
+		CreatedAt:   rec.CreatedAt,
+		CreatedBy:   rec.CreatedBy,
+		UpdatedAt:   rec.UpdatedAt,
+		ClosedAt:    rec.ClosedAt,
+		CloseReason: rec.CloseReason,
+		Assignee:    rec.Assignee,
+		Labels:      append([]string(nil), rec.Labels...),
+		HookBead:    rec.HookBead,
+		AgentState:  rec.AgentState,
+	}
+
+	for _, d := range snap.Deps(rec.ID) {
+		issue.DependencyCount++
+		switch d.Type {
+		case beadstore.DepParentChild:
+			issue.Parent = d.DependsOnID
+		case beadstore.DepBlocks, "":
+			issue.DependsOn = append(issue.DependsOn, d.DependsOnID)
+			if target, ok := snap.Get(d.DependsOnID); !ok || target.Status != "closed" {
+				issue.BlockedBy = append(issue.BlockedBy, d.DependsOnID)
+			}
+		}
+		if detail {
+			issue.Dependencies = append(issue.Dependencies, issueDep(snap, d.DependsOnID, d.Type))
+		}
+	}
+	issue.BlockedByCount = len(issue.BlockedBy)
+
+	for _, d := range snap.Dependents(rec.ID) {
+		issue.DependentCount++
+		switch d.Type {
+		case beadstore.DepParentChild:
+			issue.Children = append(issue.Children, d.IssueID)
+		case beadstore.DepBlocks, "":
+			issue.Blocks = append(issue.Blocks, d.IssueID)
+		}
+		if detail {
+			issue.Dependents = append(issue.Dependents, issueDep(snap, d.IssueID, d.Type))
+		}
+	}
+	return issue
+}
+
+func issueDep(snap *beadstore.Snapshot, id, depType string) IssueDep {
+	dep := IssueDep{ID: id, DependencyType: depType}
+	if rec, ok := snap.Get(beadstore.StripExternal(id)); ok {
+		dep.Title = rec.Title
+		dep.Status = rec.Status
+		dep.Priority = rec.Priority
+		dep.Type = rec.IssueType
+	}
+	return dep
+}
+
+// TrackedIssueIDs returns the IDs a convoy tracks, with external references
+// reduced to bare IDs. Reads the snapshot; falls back to bd show.
+func (b *Beads) TrackedIssueIDs(convoyID string) ([]string, error) {
+	if snap := b.snapshot(); snap != nil {
+		if _, ok := snap.Get(convoyID); ok {
+			return snap.Tracked(convoyID), nil
+		}
+	}
+	issue, err := b.Show(convoyID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, d := range issue.Dependencies {
+		if d.DependencyType == beadstore.DepTracks {
+			ids = append(ids, beadstore.StripExternal(d.ID))
+		}
+	}
+	return ids, nil
+}
+
+// TrackingConvoyIDs returns the IDs of issues (convoys) that track issueID.
+// ok is false if the snapshot is unavailable and the caller must query the
+// database some other way.
+func (b *Beads) TrackingConvoyIDs(issueID string) (ids []string, ok bool) {
+	snap := b.snapshot()
+	if snap == nil {
+		return nil, false
+	}
+	return snap.TrackedBy(issueID), true
+}
+
+// ShowRouted fetches issues from any database in the town, routing each ID
+// to its rig by prefix (routes.jsonl). Cached snapshots answer what they
+// hold; the rest go to a single bd show from the town root, which applies
+// bd's own routing. IDs that cannot be found are left out of the result.
+func ShowRouted(townRoot string, ids []string) map[string]*Issue {
+	result := make(map[string]*Issue, len(ids))
+	if len(ids) == 0 {
+		return result
+	}
+
+	routes, _ := LoadRoutes(filepath.Join(townRoot, ".beads"))
+	byDir := make(map[string][]string)
+	var missing []string
+	for _, id := range ids {
+		dir := ""
+		prefix := ExtractPrefix(id)
+		for _, r := range routes {
+			if r.Prefix == prefix {
+				dir = filepath.Join(townRoot, r.Path)
+				break
+			}
+		}
+		if dir == "" {
+			missing = append(missing, id)
+			continue
+		}
+		byDir[dir] = append(byDir[dir], id)
+	}
+
+	for dir, group := range byDir {
+		found, miss := New(dir).showCached(group)
+		for id, issue := range found {
+			result[id] = issue
+		}
+		missing = append(missing, miss...)
+	}
+
+	if len(missing) > 0 {
+		if more, err := New(townRoot).ShowMultiple(missing); err == nil {
+			for id, issue := range more {
+				result[id] = issue
+			}
+		}
+	}
+	return result
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package beads
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeIssuesJSONL sets up a JSONL-only beads dir (no beads.db), which the
+// read cache treats as the complete database.
+func writeIssuesJSONL(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	beadsDir := filepath.Join(dir, ".beads")
+	if err := os.MkdirAll(beadsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(beadsDir, "issues.jsonl"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestListCached(t *testing.T) {
+	dir := writeIssuesJSONL(t, `{"id":"gt-epic","title":"Epic","status":"open","priority":1}
+{"id":"gt-mr1","title":"MR 1","status":"open","priority":2,"labels":["gt:merge-request"],"created_at":"2026-01-01T00:00:00Z","dependencies":[{"depends_on_id":"gt-fix","type":"blocks"}]}
+{"id":"gt-mr2","title":"MR 2","status":"open","priority":2,"labels":["gt:merge-request"],"created_at":"2026-01-02T00:00:00Z","assignee":"gastown/refinery"}
+{"id":"gt-mr3","title":"MR 3","status":"closed","priority":1,"labels":["gt:merge-request"]}
+{"id":"gt-fix","title":"Fix","status":"open","priority":1,"dependencies":[{"depends_on_id":"gt-epic","type":"parent-child"}]}
+`)
+	b := NewIsolated(dir)
+
+	issues, err := b.List(ListOptions{Label: "gt:merge-request", Priority: -1})
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(issues) != 2 || issues[0].ID != "gt-mr2" || issues[1].ID != "gt-mr1" {
+		t.Fatalf("List() = %v, want open MRs newest first", issueIDs(issues))
+	}
+	if mr1 := issues[1]; len(mr1.BlockedBy) != 1 || mr1.BlockedBy[0] != "gt-fix" || mr1.BlockedByCount != 1 {
+		t.Errorf("gt-mr1 BlockedBy = %v (count %d), want [gt-fix]", mr1.BlockedBy, mr1.BlockedByCount)
+	}
+
+	issues, _ = b.List(ListOptions{Label: "gt:merge-request", Status: "all", Priority: 1})
+	if len(issues) != 1 || issues[0].ID != "gt-mr3" {
+		t.Errorf("List(all, P1) = %v, want [gt-mr3]", issueIDs(issues))
+	}
+	issues, _ = b.List(ListOptions{Label: "gt:merge-request", Priority: -1, NoAssignee: true})
+	if len(issues) != 1 || issues[0].ID != "gt-mr1" {
+		t.Errorf("List(no assignee) = %v, want [gt-mr1]", issueIDs(issues))
+	}
+	issues, _ = b.List(ListOptions{Parent: "gt-epic", Priority: -1})
+	if len(issues) != 1 || issues[0].ID != "gt-fix" {
+		t.Errorf("List(parent) = %v, want [gt-fix]", issueIDs(issues))
+	}
+
+	fix, err := b.Show("gt-fix")
+	if err != nil {
+		t.Fatalf("Show() error: %v", err)
+	}
+	if fix.Parent != "gt-epic" || len(fix.Blocks) != 1 || fix.Blocks[0] != "gt-mr1" {
+		t.Errorf("Show(gt-fix) parent=%q blocks=%v", fix.Parent, fix.Blocks)
+	}
+	if len(fix.Dependents) != 1 || fix.Dependents[0].Title != "MR 1" {
+		t.Errorf("Show(gt-fix) Dependents = %+v", fix.Dependents)
+	}
+	epic, _ := b.Show("gt-epic")
+	if len(epic.Children) != 1 || epic.Children[0] != "gt-fix" {
+		t.Errorf("Show(gt-epic) Children = %v", epic.Children)
+	}
+}
+
+func TestListCached_ClosedBlockerDoesNotBlock(t *testing.T) {
+	dir := writeIssuesJSONL(t, `{"id":"gt-a","title":"A","status":"open","dependencies":[{"depends_on_id":"gt-b","type":"blocks"}]}
+{"id":"gt-b","title":"B","status":"closed"}
+`)
+	a, err := NewIsolated(dir).Show("gt-a")
+	if err != nil {
+		t.Fatalf("Show() error: %v", err)
+	}
+	if len(a.DependsOn) != 1 || len(a.BlockedBy) != 0 {
+		t.Errorf("DependsOn=%v BlockedBy=%v, want closed blocker not blocking", a.DependsOn, a.BlockedBy)
+	}
+}
+
+func issueIDs(issues []*Issue) []string {
+	ids := make([]string, len(issues))
+	for i, issue := range issues {
+		ids[i] = issue.ID
+	}
+	return ids
+}
@@ -3,6 +3,7 @@ package beads
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -184,9 +185,26 @@ type MRFields struct {
 	LastConflictSHA string // SHA of main when conflict occurred
 	ConflictTaskID  string // Link to conflict-resolution task (if any)
 
+	// Last failed merge attempt (conflict, tests_fail, build_fail, ...)
+	LastFailure   string // FailureType of the last failed attempt
+	LastFailureAt string // When it failed (RFC3339)
+
 	// Convoy tracking (for priority scoring - convoy starvation prevention)
 	ConvoyID        string // Parent convoy ID if part of a convoy
 	ConvoyCreatedAt string // Convoy creation time (ISO 8601) for starvation prevention
+
+	// Code review gate (only used when the rig requires review)
+	ReviewState  string // pending, approved, changes_requested
+	ReviewedBy   string // Who approved or requested changes
+	ReviewedSHA  string // Branch head that was reviewed (approval is void if the branch moves)
+	ReviewTaskID string // Link to the review task handed to a reviewer
+
+	// Manual queue ordering (gt mq bump): points added to the MR's score
+	ScoreAdjust float64
+
+	// PR landing (only used when the rig lands through a forge)
+	LandingPR    int    // Number of the open landing PR, checked on later passes
+	LandingSince string // When the landing PR was opened (RFC3339)
 }
 
 // ParseMRFields extracts structured merge-request fields from an issue's description.
@@ -255,12 +273,43 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "conflict_task_id", "conflict-task-id", "conflicttaskid":
 			fields.ConflictTaskID = value
 			hasFields = true
+		case "last_failure", "last-failure", "lastfailure":
+			fields.LastFailure = value
+			hasFields = true
+		case "last_failure_at", "last-failure-at", "lastfailureat":
+			fields.LastFailureAt = value
+			hasFields = true
 		case "convoy_id", "convoy-id", "convoyid", "convoy":
 			fields.ConvoyID = value
 			hasFields = true
 		case "convoy_created_at", "convoy-created-at", "convoycreatedat":
 			fields.ConvoyCreatedAt = value
 			hasFields = true
+		case "review_state", "review-state", "reviewstate":
+			fields.ReviewState = value
+			hasFields = true
+		case "reviewed_by", "reviewed-by", "reviewedby":
+			fields.ReviewedBy = value
+			hasFields = true
+		case "reviewed_sha", "reviewed-sha", "reviewedsha":
+			fields.ReviewedSHA = value
+			hasFields = true
+		case "review_task_id", "review-task-id", "reviewtaskid":
+			fields.ReviewTaskID = value
+			hasFields = true
+		case "score_adjust", "score-adjust", "scoreadjust":
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				fields.ScoreAdjust = f
+				hasFields = true
+			}
+		case "landing_pr", "landing-pr", "landingpr":
+			if n, err := parseIntField(value); err == nil {
+				fields.LandingPR = n
+				hasFields = true
+			}
+		case "landing_since", "landing-since", "landingsince":
+			fields.LandingSince = value
+			hasFields = true
 		}
 	}
 
@@ -319,12 +368,39 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.ConflictTaskID != "" {
 		lines = append(lines, "conflict_task_id: "+fields.ConflictTaskID)
 	}
+	if fields.LastFailure != "" {
+		lines = append(lines, "last_failure: "+fields.LastFailure)
+	}
+	if fields.LastFailureAt != "" {
+		lines = append(lines, "last_failure_at: "+fields.LastFailureAt)
+	}
 	if fields.ConvoyID != "" {
 		lines = append(lines, "convoy_id: "+fields.ConvoyID)
 	}
 	if fields.ConvoyCreatedAt != "" {
 		lines = append(lines, "convoy_created_at: "+fields.ConvoyCreatedAt)
 	}
+	if fields.ReviewState != "" {
+		lines = append(lines, "review_state: "+fields.ReviewState)
+	}
+	if fields.ReviewedBy != "" {
+		lines = append(lines, "reviewed_by: "+fields.ReviewedBy)
+	}
+	if fields.ReviewedSHA != "" {
+		lines = append(lines, "reviewed_sha: "+fields.ReviewedSHA)
+	}
+	if fields.ReviewTaskID != "" {
+		lines = append(lines, "review_task_id: "+fields.ReviewTaskID)
+	}
+	if fields.ScoreAdjust != 0 {
+		lines = append(lines, "score_adjust: "+strconv.FormatFloat(fields.ScoreAdjust, 'f', -1, 64))
+	}
+	if fields.LandingPR > 0 {
+		lines = append(lines, fmt.Sprintf("landing_pr: %d", fields.LandingPR))
+	}
+	if fields.LandingSince != "" {
+		lines = append(lines, "landing_since: "+fields.LandingSince)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -339,38 +415,65 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 
 	// Known MR field keys (lowercase)
 	mrKeys := map[string]bool{
-		"branch":             true,
-		"target":             true,
-		"source_issue":       true,
-		"source-issue":       true,
-		"sourceissue":        true,
-		"worker":             true,
-		"rig":                true,
-		"merge_commit":       true,
-		"merge-commit":       true,
-		"mergecommit":        true,
-		"close_reason":       true,
-		"close-reason":       true,
-		"closereason":        true,
-		"agent_bead":         true,
-		"agent-bead":         true,
-		"agentbead":          true,
-		"retry_count":        true,
-		"retry-count":        true,
-		"retrycount":         true,
-		"last_conflict_sha":  true,
-		"last-conflict-sha":  true,
-		"lastconflictsha":    true,
-		"conflict_task_id":   true,
-		"conflict-task-id":   true,
-		"conflicttaskid":     true,
-		"convoy_id":          true,
-		"convoy-id":          true,
-		"convoyid":           true,
-		"convoy":             true,
-		"convoy_created_at":  true,
-		"convoy-created-at":  true,
-		"convoycreatedat":    true,
+		"branch":            true,
+		"target":            true,
+		"source_issue":      true,
+		"source-issue":      true,
+		"sourceissue":       true,
+		"worker":            true,
+		"rig":               true,
+		"merge_commit":      true,
+		"merge-commit":      true,
+		"mergecommit":       true,
+		"close_reason":      true,
+		"close-reason":      true,
+		"closereason":       true,
+		"agent_bead":        true,
+		"agent-bead":        true,
+		"agentbead":         true,
+		"retry_count":       true,
+		"retry-count":       true,
+		"retrycount":        true,
+		"last_conflict_sha": true,
+		"last-conflict-sha": true,
+		"lastconflictsha":   true,
+		"conflict_task_id":  true,
+		"conflict-task-id":  true,
+		"conflicttaskid":    true,
+		"last_failure":      true,
+		"last-failure":      true,
+		"lastfailure":       true,
+		"last_failure_at":   true,
+		"last-failure-at":   true,
+		"lastfailureat":     true,
+		"convoy_id":         true,
+		"convoy-id":         true,
+		"convoyid":          true,
+		"convoy":            true,
+		"convoy_created_at": true,
+		"convoy-created-at": true,
+		"convoycreatedat":   true,
+		"review_state":      true,
+		"review-state":      true,
+		"reviewstate":       true,
+		"reviewed_by":       true,
+		"reviewed-by":       true,
+		"reviewedby":        true,
+		"reviewed_sha":      true,
+		"reviewed-sha":      true,
+		"reviewedsha":       true,
+		"review_task_id":    true,
+		"review-task-id":    true,
+		"reviewtaskid":      true,
+		"score_adjust":      true,
+		"score-adjust":      true,
+		"scoreadjust":       true,
+		"landing_pr":        true,
+		"landing-pr":        true,
+		"landingpr":         true,
+		"landing_since":     true,
+		"landing-since":     true,
+		"landingsince":      true,
 	}
 
 	// Collect non-MR lines from existing description
@@ -0,0 +1,281 @@
+package beadstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqliteTimeout bounds the database dump. A locked database waits up to the
+// busy timeout inside sqlite3; this is the hard stop.
+const sqliteTimeout = 10 * time.Second
+
+// sectionKey marks the start of each result set in the sqlite3 dump. The
+// marker rows let one invocation return several tables even when some of
+// them are empty (sqlite3 -json prints nothing for an empty result).
+const sectionKey = "gt_section"
+
+// dumpQuery reads every table the snapshot needs. SELECT * keeps the dump
+// working across beads schema versions; unknown columns are ignored.
+const dumpQuery = `SELECT 'issues' AS gt_section; SELECT * FROM issues;` +
+	` SELECT 'labels' AS gt_section; SELECT issue_id, label FROM labels;` +
+	` SELECT 'dependencies' AS gt_section; SELECT issue_id, depends_on_id, type FROM dependencies;`
+
+// storedIssue is an issue row as bd stores it, in either the issues table
+// or issues.jsonl.
+type storedIssue struct {
+	ID           string      `json:"id"`
+	Title        string      `json:"title"`
+	Description  string      `json:"description"`
+	Status       string      `json:"status"`
+	Priority     flexInt     `json:"priority"`
+	IssueType    string      `json:"issue_type"`
+	Assignee     string      `json:"assignee"`
+	CreatedAt    flexString  `json:"created_at"`
+	CreatedBy    string      `json:"created_by"`
+	UpdatedAt    flexString  `json:"updated_at"`
+	ClosedAt     flexString  `json:"closed_at"`
+	CloseReason  string      `json:"close_reason"`
+	DeletedAt    flexString  `json:"deleted_at"`
+	HookBead     string      `json:"hook_bead"`
+	AgentState   string      `json:"agent_state"`
+	Ephemeral    flexBool    `json:"ephemeral"`
+	Wisp         flexBool    `json:"wisp"`
+	Labels       []string    `json:"labels"`       // JSONL only
+	Dependencies []storedDep `json:"dependencies"` // JSONL only
+}
+
+type storedDep struct {
+	IssueID     string `json:"issue_id"`
+	DependsOnID string `json:"depends_on_id"`
+	Type        string `json:"type"`
+}
+
+type storedLabel struct {
+	IssueID string `json:"issue_id"`
+	Label   string `json:"label"`
+}
+
+func (si *storedIssue) record() *Record {
+	status := si.Status
+	if si.DeletedAt != "" {
+		status = "tombstone"
+	}
+	return &Record{
+		ID:          si.ID,
+		Title:       si.Title,
+		Description: si.Description,
+		Status:      status,
+		Priority:    int(si.Priority),
+		IssueType:   si.IssueType,
+		Assignee:    si.Assignee,
+		CreatedAt:   normalizeTime(string(si.CreatedAt)),
+		CreatedBy:   si.CreatedBy,
+		UpdatedAt:   normalizeTime(string(si.UpdatedAt)),
+		ClosedAt:    normalizeTime(string(si.ClosedAt)),
+		CloseReason: si.CloseReason,
+		Labels:      si.Labels,
+		HookBead:    si.HookBead,
+		AgentState:  si.AgentState,
+		Ephemeral:   bool(si.Ephemeral) || bool(si.Wisp),
+	}
+}
+
+// loadSQLite dumps beads.db with one read-only sqlite3 invocation.
+func loadSQLite(dbPath string) (*Snapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sqliteTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sqlite3", "-readonly", "-json", "-cmd", ".timeout 2000", dbPath, dumpQuery) //nolint:gosec // G204: fixed query, path from beads dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("sqlite3 %s: %v: %s", dbPath, err, strings.TrimSpace(stderr.String()))
+	}
+	return parseDump(&stdout)
+}
+
+// parseDump parses the concatenated JSON arrays sqlite3 prints for
+// dumpQuery.
+func parseDump(r io.Reader) (*Snapshot, error) {
+	var (
+		records  []*Record
+		byID     = make(map[string]*Record)
+		deps     []Dep
+		section  string
+		sections = make(map[string]bool)
+	)
+
+	dec := json.NewDecoder(r)
+	for {
+		var rows []json.RawMessage
+		if err := dec.Decode(&rows); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("parsing sqlite3 output: %w", err)
+		}
+		if len(rows) == 1 {
+			var marker map[string]json.RawMessage
+			if err := json.Unmarshal(rows[0], &marker); err == nil && len(marker) == 1 {
+				if raw, ok := marker[sectionKey]; ok {
+					if err := json.Unmarshal(raw, &section); err != nil {
+						return nil, fmt.Errorf("parsing sqlite3 output: %w", err)
+					}
+					sections[section] = true
+					continue
+				}
+			}
+		}
+		for _, row := range rows {
+			switch section {
+			case "issues":
+				var si storedIssue
+				if err := json.Unmarshal(row, &si); err != nil {
+					return nil, fmt.Errorf("parsing issue row: %w", err)
+				}
+				rec := si.record()
+				records = append(records, rec)
+				byID[rec.ID] = rec
+			case "labels":
+				var l storedLabel
+				if err := json.Unmarshal(row, &l); err != nil {
+					return nil, fmt.Errorf("parsing label row: %w", err)
+				}
+				if rec, ok := byID[l.IssueID]; ok {
+					rec.Labels = append(rec.Labels, l.Label)
+				}
+			case "dependencies":
+				var d storedDep
+				if err := json.Unmarshal(row, &d); err != nil {
+					return nil, fmt.Errorf("parsing dependency row: %w", err)
+				}
+				deps = append(deps, Dep(d))
+			default:
+				return nil, fmt.Errorf("parsing sqlite3 output: rows outside a section")
+			}
+		}
+	}
+
+	// A failed statement stops sqlite3 before the later markers print.
+	for _, want := range []string{"issues", "labels", "dependencies"} {
+		if !sections[want] {
+			return nil, fmt.Errorf("parsing sqlite3 output: missing %s", want)
+		}
+	}
+	return newSnapshot(records, deps, true), nil
+}
+
+// loadJSONL parses an issues.jsonl export.
+func loadJSONL(path string) (*Snapshot, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseJSONL(f)
+}
+
+func parseJSONL(r io.Reader) (*Snapshot, error) {
+	var (
+		records []*Record
+		deps    []Dep
+	)
+	dec := json.NewDecoder(r)
+	for {
+		var si storedIssue
+		if err := dec.Decode(&si); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("parsing issues.jsonl: %w", err)
+		}
+		records = append(records, si.record())
+		for _, d := range si.Dependencies {
+			if d.IssueID == "" {
+				d.IssueID = si.ID
+			}
+			deps = append(deps, Dep(d))
+		}
+	}
+	return newSnapshot(records, deps, false), nil
+}
+
+// timeLayouts are the forms bd has stored timestamps in.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// normalizeTime converts a stored timestamp to RFC3339, the form bd's JSON
+// output uses. Unrecognized values are returned unchanged.
+func normalizeTime(s string) string {
+	if s == "" {
+		return ""
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format(time.RFC3339Nano)
+		}
+	}
+	return s
+}
+
+// flexString accepts a JSON string, number or null.
+type flexString string
+
+func (f *flexString) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*f = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*f = flexString(s)
+		return nil
+	}
+	*f = flexString(strings.TrimSpace(string(data)))
+	return nil
+}
+
+// flexInt accepts a JSON number, numeric string or null.
+type flexInt int
+
+func (f *flexInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*f = 0
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*f = flexInt(n)
+	return nil
+}
+
+// flexBool accepts a JSON bool, 0/1 (SQLite) or null.
+type flexBool bool
+
+func (f *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true", "1":
+		*f = true
+	default:
+		*f = false
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+// Package beadstore is a read-side view of a beads database.
+//
+// Gas Town reads beads far more often than it writes them: status, the
+// dashboard, the feed and the daemon each ask for lists of agents, MRs and
+// convoys every few seconds. Going through `bd` (or `sqlite3`) for each of
+// those reads costs a process spawn and a database open per query.
+//
+// A Store loads the whole database once into an in-process Snapshot and
+// serves lookups from memory until the underlying files change (mtime and
+// size of beads.db, its WAL, and issues.jsonl). Writes still go through
+// `bd`; callers that write should Invalidate the store afterwards so the
+// next read reloads even when the file clock is coarse.
+//
+// Sources, in order of preference:
+//   - beads.db, dumped with a single read-only sqlite3 invocation. This is
+//     authoritative and includes ephemeral issues (wisps, MR beads).
+//   - issues.jsonl, parsed natively. Ephemeral issues are never exported to
+//     JSONL, so a JSONL snapshot is not Complete when a database exists:
+//     lookups by ID are still safe (a miss means "ask bd"), list queries
+//     are not.
+//
+// If neither source is usable (no files, or a database that is newer than
+// its JSONL export with no sqlite3 on PATH), Snapshot returns ErrUnavailable
+// and callers fall back to `bd`.
+package beadstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable means no fresh snapshot can be built for the beads dir.
+var ErrUnavailable = errors.New("beads store unavailable")
+
+// DisableEnv turns the cache off when set to "0", "off" or "false".
+const DisableEnv = "GT_BEADS_CACHE"
+
+// Dependency types with special meaning.
+const (
+	DepBlocks      = "blocks"
+	DepParentChild = "parent-child"
+	DepTracks      = "tracks"
+)
+
+// Record is one stored issue.
+type Record struct {
+	ID          string
+	Title       string
+	Description string
+	Status      string
+	Priority    int
+	IssueType   string
+	Assignee    string
+	CreatedAt   string // RFC3339 when the stored form could be parsed
+	CreatedBy   string
+	UpdatedAt   string
+	ClosedAt    string
+	CloseReason string
+	Labels      []string
+	HookBead    string
+	AgentState  string
+	Ephemeral   bool
+}
+
+// Dep is a dependency edge: IssueID depends on DependsOnID.
+type Dep struct {
+	IssueID     string
+	DependsOnID string
+	Type        string
+}
+
+// Snapshot is an immutable in-memory copy of a beads database.
+type Snapshot struct {
+	// Complete is true if the snapshot holds every issue bd would list,
+	// including ephemeral ones. Only complete snapshots can answer list
+	// queries.
+	Complete bool
+	// LoadedAt is when the snapshot was read.
+	LoadedAt time.Time
+
+	records    map[string]*Record
+	order      []string         // IDs in load order
+	deps       map[string][]Dep // by IssueID
+	dependents map[string][]Dep // by DependsOnID
+}
+
+// Get returns the record with the given ID.
+func (s *Snapshot) Get(id string) (*Record, bool) {
+	r, ok := s.records[id]
+	return r, ok
+}
+
+// Len returns the number of records.
+func (s *Snapshot) Len() int {
+	return len(s.records)
+}
+
+// Records returns all records in load order. Tombstones are not included.
+func (s *Snapshot) Records() []*Record {
+	out := make([]*Record, 0, len(s.order))
+	for _, id := range s.order {
+		out = append(out, s.records[id])
+	}
+	return out
+}
+
+// ByType returns the records of an issue type (e.g. "convoy") in load order.
+func (s *Snapshot) ByType(issueType string) []*Record {
+	var out []*Record
+	for _, id := range s.order {
+		if r := s.records[id]; r.IssueType == issueType {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Deps returns the dependencies of an issue (edges where it is IssueID).
+func (s *Snapshot) Deps(id string) []Dep {
+	return s.deps[id]
+}
+
+// Dependents returns the edges pointing at an issue (where it is
+// DependsOnID).
+func (s *Snapshot) Dependents(id string) []Dep {
+	return s.dependents[id]
+}
+
+// Tracked returns the IDs an issue (typically a convoy) tracks, with
+// external references ("external:<rig>:<id>") reduced to the bare ID.
+func (s *Snapshot) Tracked(id string) []string {
+	var ids []string
+	for _, d := range s.deps[id] {
+		if d.Type == DepTracks {
+			ids = append(ids, StripExternal(d.DependsOnID))
+		}
+	}
+	return ids
+}
+
+// TrackedBy returns the IDs of issues that track id, directly or through
+// an external reference.
+func (s *Snapshot) TrackedBy(id string) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, d := range s.dependents[id] {
+		if d.Type == DepTracks && !seen[d.IssueID] {
+			seen[d.IssueID] = true
+			ids = append(ids, d.IssueID)
+		}
+	}
+	// External references are keyed by their full form; scan for them.
+	suffix := ":" + id
+	for target, edges := range s.dependents {
+		if target == id || !strings.HasSuffix(target, suffix) {
+			continue
+		}
+		for _, d := range edges {
+			if d.Type == DepTracks && !seen[d.IssueID] {
+				seen[d.IssueID] = true
+				ids = append(ids, d.IssueID)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// StripExternal reduces "external:<rig>:<id>" to "<id>".
+func StripExternal(id string) string {
+	if strings.HasPrefix(id, "external:") {
+		if parts := strings.SplitN(id, ":", 3); len(parts) == 3 {
+			return parts[2]
+		}
+	}
+	return id
+}
+
+func newSnapshot(records []*Record, deps []Dep, complete bool) *Snapshot {
+	s := &Snapshot{
+		Complete:   complete,
+		LoadedAt:   time.Now(),
+		records:    make(map[string]*Record, len(records)),
+		deps:       make(map[string][]Dep),
+		dependents: make(map[string][]Dep),
+	}
+	for _, r := range records {
+		if r.ID == "" || r.Status == "tombstone" {
+			continue
+		}
+		if _, dup := s.records[r.ID]; !dup {
+			s.order = append(s.order, r.ID)
+		}
+		s.records[r.ID] = r
+	}
+	for _, d := range deps {
+		if _, ok := s.records[d.IssueID]; !ok {
+			continue
+		}
+		s.deps[d.IssueID] = append(s.deps[d.IssueID], d)
+		s.dependents[d.DependsOnID] = append(s.dependents[d.DependsOnID], d)
+	}
+	return s
+}
+
+// fileState identifies a version of the files a snapshot was built from.
+type fileState struct {
+	db, wal, jsonl fileStamp
+}
+
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func stamp(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// Store caches snapshots of one beads directory.
+type Store struct {
+	dir string
+
+	mu    sync.Mutex
+	state fileState
+	snap  *Snapshot
+	err   error
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Store)
+)
+
+// For returns the shared store for a beads directory (the .beads dir
+// itself, after redirects). Stores are shared process-wide so every
+// reader of the same database benefits from one cache.
+func For(beadsDir string) *Store {
+	dir := filepath.Clean(beadsDir)
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	s, ok := registry[dir]
+	if !ok {
+		s = &Store{dir: dir}
+		registry[dir] = s
+	}
+	return s
+}
+
+// Enabled reports whether the cache is enabled (see DisableEnv).
+func Enabled() bool {
+	switch strings.ToLower(os.Getenv(DisableEnv)) {
+	case "0", "off", "false", "no":
+		return false
+	}
+	return true
+}
+
+// Dir returns the beads directory the store reads.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Invalidate drops the cached snapshot so the next read reloads.
+func (s *Store) Invalidate() {
+	s.mu.Lock()
+	s.snap = nil
+	s.err = nil
+	s.state = fileState{}
+	s.mu.Unlock()
+}
+
+// Snapshot returns a snapshot that reflects the current files, reloading
+// if anything changed since the last call.
+func (s *Store) Snapshot() (*Snapshot, error) {
+	if !Enabled() {
+		return nil, ErrUnavailable
+	}
+
+	dbPath := filepath.Join(s.dir, "beads.db")
+	state := fileState{
+		db:    stamp(dbPath),
+		wal:   stamp(dbPath + "-wal"),
+		jsonl: stamp(filepath.Join(s.dir, "issues.jsonl")),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if (s.snap != nil || s.err != nil) && state == s.state {
+		return s.snap, s.err
+	}
+
+	s.snap, s.err = s.load(state)
+	s.state = state
+	return s.snap, s.err
+}
+
+func (s *Store) load(state fileState) (*Snapshot, error) {
+	if state.db.exists {
+		if snap, err := loadSQLite(filepath.Join(s.dir, "beads.db")); err == nil {
+			return snap, nil
+		}
+	}
+	if !state.jsonl.exists {
+		return nil, ErrUnavailable
+	}
+	// Without the database we can only trust the JSONL if it is at least as
+	// new as the last database write.
+	dbMod := state.db.modTime
+	if state.wal.exists && state.wal.modTime.After(dbMod) {
+		dbMod = state.wal.modTime
+	}
+	if state.db.exists && dbMod.After(state.jsonl.modTime) {
+		return nil, ErrUnavailable
+	}
+	snap, err := loadJSONL(filepath.Join(s.dir, "issues.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	// In JSONL-only (no-db) mode the export is the whole database.
+	snap.Complete = !state.db.exists
+	return snap, nil
+}
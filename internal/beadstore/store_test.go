@@ -0,0 +1,201 @@
+package beadstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleJSONL = `{"id":"hq-cv-1","title":"Convoy","status":"open","priority":2,"issue_type":"convoy","created_at":"2026-01-02T10:00:00Z","dependencies":[{"issue_id":"hq-cv-1","depends_on_id":"external:gastown:gt-a","type":"tracks"},{"issue_id":"hq-cv-1","depends_on_id":"hq-b","type":"tracks"}]}
+{"id":"hq-b","title":"Task B","status":"closed","priority":1,"issue_type":"task","labels":["gt:task"],"closed_at":"2026-01-03 09:30:00","close_reason":"Merged in hq-mr1"}
+{"id":"hq-gone","title":"Deleted","status":"tombstone"}
+`
+
+func TestParseJSONL(t *testing.T) {
+	snap, err := parseJSONL(strings.NewReader(sampleJSONL))
+	if err != nil {
+		t.Fatalf("parseJSONL() error: %v", err)
+	}
+	if snap.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2 (tombstone skipped)", snap.Len())
+	}
+	b, ok := snap.Get("hq-b")
+	if !ok || b.Status != "closed" || b.Labels[0] != "gt:task" {
+		t.Errorf("Get(hq-b) = %+v, %v", b, ok)
+	}
+	if b.ClosedAt != "2026-01-03T09:30:00Z" {
+		t.Errorf("ClosedAt = %q, want normalized RFC3339", b.ClosedAt)
+	}
+	if b.CloseReason != "Merged in hq-mr1" {
+		t.Errorf("CloseReason = %q", b.CloseReason)
+	}
+	if got := snap.Tracked("hq-cv-1"); strings.Join(got, ",") != "gt-a,hq-b" {
+		t.Errorf("Tracked() = %v, want [gt-a hq-b]", got)
+	}
+	if got := snap.TrackedBy("gt-a"); len(got) != 1 || got[0] != "hq-cv-1" {
+		t.Errorf("TrackedBy(gt-a) = %v, want [hq-cv-1] via external ref", got)
+	}
+	if got := snap.ByType("convoy"); len(got) != 1 || got[0].ID != "hq-cv-1" {
+		t.Errorf("ByType(convoy) = %v", got)
+	}
+}
+
+func TestParseDump(t *testing.T) {
+	// Labels section is empty: sqlite3 prints nothing for it.
+	dump := `[{"gt_section":"issues"}]
+[{"id":"gt-1","title":"One","status":"open","priority":"2","issue_type":"merge-request","ephemeral":1,"created_at":"2026-01-02 10:00:00.5+00:00","assignee":null},
+{"id":"gt-2","title":"Two","status":"closed","priority":1,"issue_type":"task","deleted_at":null,"close_reason":"done"}]
+[{"gt_section":"labels"}]
+[{"gt_section":"dependencies"}]
+[{"issue_id":"gt-1","depends_on_id":"gt-2","type":"blocks"}]
+`
+	snap, err := parseDump(strings.NewReader(dump))
+	if err != nil {
+		t.Fatalf("parseDump() error: %v", err)
+	}
+	if !snap.Complete {
+		t.Error("SQLite snapshot should be Complete")
+	}
+	one, ok := snap.Get("gt-1")
+	if !ok || !one.Ephemeral || one.Priority != 2 || one.Assignee != "" {
+		t.Errorf("Get(gt-1) = %+v, %v", one, ok)
+	}
+	if one.CreatedAt != "2026-01-02T10:00:00.5Z" {
+		t.Errorf("CreatedAt = %q", one.CreatedAt)
+	}
+	if two, _ := snap.Get("gt-2"); two == nil || two.CloseReason != "done" {
+		t.Errorf("Get(gt-2) = %+v, want close_reason loaded", two)
+	}
+	if deps := snap.Deps("gt-1"); len(deps) != 1 || deps[0].DependsOnID != "gt-2" {
+		t.Errorf("Deps(gt-1) = %v", deps)
+	}
+	if deps := snap.Dependents("gt-2"); len(deps) != 1 || deps[0].IssueID != "gt-1" {
+		t.Errorf("Dependents(gt-2) = %v", deps)
+	}
+
+	// A failed statement cuts the dump short; that must not look complete.
+	if _, err := parseDump(strings.NewReader(`[{"gt_section":"issues"}]`)); err == nil {
+		t.Error("parseDump() of a truncated dump succeeded, want error")
+	}
+}
+
+func TestStoreSnapshot_JSONLOnly(t *testing.T) {
+	dir := t.TempDir()
+	jsonl := filepath.Join(dir, "issues.jsonl")
+	if err := os.WriteFile(jsonl, []byte(sampleJSONL), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Store{dir: dir}
+	snap, err := s.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error: %v", err)
+	}
+	if !snap.Complete {
+		t.Error("JSONL snapshot without a database should be Complete")
+	}
+	if again, _ := s.Snapshot(); again != snap {
+		t.Error("unchanged files should return the cached snapshot")
+	}
+
+	// Changing the file reloads.
+	more := sampleJSONL + `{"id":"hq-c","title":"C","status":"open"}` + "\n"
+	if err := os.WriteFile(jsonl, []byte(more), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snap, err = s.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error: %v", err)
+	}
+	if _, ok := snap.Get("hq-c"); !ok {
+		t.Error("snapshot not reloaded after issues.jsonl changed")
+	}
+}
+
+func TestStoreSnapshot_StaleJSONL(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", t.TempDir()) // no sqlite3
+	jsonl := filepath.Join(dir, "issues.jsonl")
+	db := filepath.Join(dir, "beads.db")
+	if err := os.WriteFile(jsonl, []byte(sampleJSONL), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(db, []byte("not really sqlite"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Database written after the export: JSONL may be missing writes.
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(jsonl, old, old); err != nil {
+		t.Fatal(err)
+	}
+	s := &Store{dir: dir}
+	if _, err := s.Snapshot(); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Snapshot() error = %v, want ErrUnavailable", err)
+	}
+
+	// Export caught up: usable for lookups, but not complete (no wisps).
+	if err := os.Chtimes(db, old.Add(-time.Minute), old.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := s.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error: %v", err)
+	}
+	if snap.Complete {
+		t.Error("JSONL snapshot next to a database should not be Complete")
+	}
+}
+
+func TestStoreSnapshot_SQLite(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake sqlite3 is a shell script")
+	}
+	dir := t.TempDir()
+	bin := t.TempDir()
+	script := `#!/bin/sh
+echo '[{"gt_section":"issues"}]'
+echo '[{"id":"gt-wisp","title":"MR","status":"open","ephemeral":1}]'
+echo '[{"gt_section":"labels"}]'
+echo '[{"issue_id":"gt-wisp","label":"gt:merge-request"}]'
+echo '[{"gt_section":"dependencies"}]'
+`
+	if err := os.WriteFile(filepath.Join(bin, "sqlite3"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	if err := os.WriteFile(filepath.Join(dir, "beads.db"), []byte("db"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := (&Store{dir: dir}).Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error: %v", err)
+	}
+	rec, ok := snap.Get("gt-wisp")
+	if !ok || !snap.Complete || len(rec.Labels) != 1 || rec.Labels[0] != "gt:merge-request" {
+		t.Errorf("Snapshot() = %+v (complete=%v)", rec, snap.Complete)
+	}
+}
+
+func TestStoreSnapshot_Disabled(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "issues.jsonl"), []byte(sampleJSONL), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(DisableEnv, "off")
+	if _, err := (&Store{dir: dir}).Snapshot(); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Snapshot() error = %v, want ErrUnavailable when disabled", err)
+	}
+}
+
+func TestFor_Shared(t *testing.T) {
+	dir := t.TempDir()
+	if For(dir) != For(dir+string(filepath.Separator)) {
+		t.Error("For() should return one store per directory")
+	}
+}
@@ -0,0 +1,473 @@
+// Package bridge connects Gas Town mail to a chat system so humans can talk
+// to the town from their phone.
+//
+// Each binding in mayor/bridge.json maps a chat channel to a Gas Town
+// address (usually "overseer"). Unread mail to that address, and
+// optionally every escalation, is posted into the channel. Chat messages
+// in the channel become mail sent from that address: top-level messages
+// start a new mail thread to the binding's recipient (default "mayor/"),
+// and replies in a chat thread continue the mail thread it carries, so
+// ThreadID is preserved across both sides.
+//
+// The chat side is a Transport. Matrix is built in; Fake is an in-memory
+// transport for tests.
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// ChatMessage is a message received from a chat channel.
+type ChatMessage struct {
+	// ID is the transport's ID for the message.
+	ID string
+
+	// Channel is the channel it was posted in.
+	Channel string
+
+	// Thread is the ID of the thread root the message replies in, or ""
+	// for a top-level message.
+	Thread string
+
+	// User is the chat user who wrote it.
+	User string
+
+	Text string
+}
+
+// Transport is a chat system the bridge posts to and reads from.
+type Transport interface {
+	// Name returns the transport kind (matrix, fake).
+	Name() string
+
+	// Run delivers messages from other users to inbound until ctx is
+	// canceled or the connection fails. Messages sent before Run started
+	// are not delivered.
+	Run(ctx context.Context, inbound chan<- ChatMessage) error
+
+	// Post sends text to a channel, in the thread rooted at thread if it is
+	// not "". It returns the ID of the posted message.
+	Post(ctx context.Context, channel, thread, text string) (string, error)
+}
+
+// Mailer is the mail side of the bridge.
+type Mailer interface {
+	// Send delivers a message.
+	Send(msg *mail.Message) error
+
+	// Unread returns the unread messages for an address.
+	Unread(address string) ([]*mail.Message, error)
+
+	// MarkRead marks a message read, keeping it in the mailbox.
+	MarkRead(address, id string) error
+}
+
+// RouterMailer implements Mailer with a mail.Router.
+type RouterMailer struct {
+	Router *mail.Router
+}
+
+// Send implements Mailer.
+func (m *RouterMailer) Send(msg *mail.Message) error {
+	return m.Router.Send(msg)
+}
+
+// Unread implements Mailer.
+func (m *RouterMailer) Unread(address string) ([]*mail.Message, error) {
+	mb, err := m.Router.GetMailbox(address)
+	if err != nil {
+		return nil, err
+	}
+	return mb.ListUnread()
+}
+
+// MarkRead implements Mailer.
+func (m *RouterMailer) MarkRead(address, id string) error {
+	mb, err := m.Router.GetMailbox(address)
+	if err != nil {
+		return err
+	}
+	return mb.MarkReadOnly(id)
+}
+
+// Bridge relays between a transport and mail.
+type Bridge struct {
+	cfg       *Config
+	transport Transport
+	mailer    Mailer
+	logf      func(format string, args ...interface{})
+
+	// EventsPath is the events log tailed for escalations; "" disables it.
+	EventsPath string
+
+	mu    sync.Mutex // guards state
+	state *State
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a bridge. state may be an in-memory &State{}.
+func New(cfg *Config, transport Transport, mailer Mailer, state *State, logf func(format string, args ...interface{})) *Bridge {
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+	return &Bridge{
+		cfg:       cfg,
+		transport: transport,
+		mailer:    mailer,
+		state:     state,
+		logf:      logf,
+	}
+}
+
+// ForTown creates the bridge configured in a town's mayor/bridge.json,
+// relaying through the town's mail router. Returns nil, nil if the town
+// has no bridge configured.
+func ForTown(townRoot string, logf func(format string, args ...interface{})) (*Bridge, error) {
+	cfg, err := LoadConfig(townRoot)
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	transport, err := cfg.NewTransport()
+	if err != nil {
+		return nil, err
+	}
+	state, err := LoadState(StatePath(townRoot))
+	if err != nil {
+		return nil, err
+	}
+	b := New(cfg, transport, &RouterMailer{Router: mail.NewRouter(townRoot)}, state, logf)
+	for _, binding := range cfg.Bindings {
+		if len(binding.AllowedUsers) == 0 {
+			b.logf("bridge: %s has no allowed_users, chat messages will be dropped", binding.Channel)
+		}
+	}
+	b.EventsPath = filepath.Join(townRoot, events.EventsFile)
+	return b, nil
+}
+
+// Start runs the bridge in the background until Stop.
+func (b *Bridge) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.Run(ctx)
+	}()
+}
+
+// Stop stops a bridge started with Start and waits for it to finish.
+func (b *Bridge) Stop() {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.wg.Wait()
+}
+
+// Run relays until ctx is canceled, reconnecting the transport after
+// failures.
+func (b *Bridge) Run(ctx context.Context) {
+	// Escalations raised while the bridge was down are not replayed.
+	b.mu.Lock()
+	if b.state.EventsOffset == 0 {
+		b.state.EventsOffset = fileSize(b.EventsPath)
+	}
+	b.mu.Unlock()
+
+	inbound := make(chan ChatMessage, 16)
+	go func() {
+		for ctx.Err() == nil {
+			if err := b.transport.Run(ctx, inbound); err != nil && ctx.Err() == nil {
+				b.logf("bridge: %s: %v, reconnecting in 10s", b.transport.Name(), err)
+				select {
+				case <-ctx.Done():
+				case <-time.After(10 * time.Second):
+				}
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(b.cfg.Interval())
+	defer ticker.Stop()
+	b.Poll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-inbound:
+			if err := b.HandleChat(msg); err != nil {
+				b.logf("bridge: inbound %s: %v", msg.ID, err)
+			}
+		case <-ticker.C:
+			b.Poll(ctx)
+		}
+	}
+}
+
+// HandleChat converts a chat message into mail. Messages in unbound
+// channels are ignored, and messages from users not in the binding's
+// allowed_users are dropped: they would otherwise be mail from Address.
+func (b *Bridge) HandleChat(cm ChatMessage) error {
+	binding := b.cfg.Binding(cm.Channel)
+	text := strings.TrimSpace(cm.Text)
+	if binding == nil || text == "" {
+		return nil
+	}
+	if !binding.Allows(cm.User) {
+		b.logf("bridge: dropped %s from %q in %s: not in allowed_users", cm.ID, cm.User, cm.Channel)
+		return nil
+	}
+	body := text
+	if cm.User != "" {
+		body += fmt.Sprintf("\n\n(via %s from %s)", b.transport.Name(), cm.User)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var msg *mail.Message
+	thread := b.state.ByChat(cm.Channel, cm.Thread)
+	if cm.Thread != "" && thread != nil {
+		original := &mail.Message{ID: thread.LastMessage, ThreadID: thread.MailThread}
+		msg = mail.NewReplyMessage(binding.Address, thread.Peer, replySubject(thread.Subject), body, original)
+	} else {
+		msg = mail.NewMessage(binding.Address, binding.Recipient(), chatSubject(text), body)
+		root := cm.ID
+		if cm.Thread != "" {
+			// A reply to a chat thread the bridge doesn't know (e.g. a human
+			// conversation) starts a mail thread carried by that chat thread.
+			root = cm.Thread
+		}
+		thread = &Thread{
+			Channel:    cm.Channel,
+			ChatThread: root,
+			MailThread: msg.ThreadID,
+			Subject:    msg.Subject,
+			Peer:       msg.To,
+		}
+		b.state.Add(thread)
+	}
+	if err := b.mailer.Send(msg); err != nil {
+		return fmt.Errorf("sending to %s: %w", msg.To, err)
+	}
+	thread.LastMessage = msg.ID
+	thread.UpdatedAt = time.Now()
+	return b.state.Save()
+}
+
+// Poll posts unread mail for every binding, and new escalations, into
+// their channels.
+func (b *Bridge) Poll(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, binding := range b.cfg.Bindings {
+		if err := b.postMail(ctx, binding); err != nil {
+			b.logf("bridge: %s: %v", binding.Address, err)
+		}
+	}
+	if err := b.postEscalations(ctx); err != nil {
+		b.logf("bridge: escalations: %v", err)
+	}
+	if err := b.state.Save(); err != nil {
+		b.logf("bridge: saving state: %v", err)
+	}
+}
+
+// postMail posts a binding's unread mail, oldest first, and marks it read.
+func (b *Bridge) postMail(ctx context.Context, binding *Binding) error {
+	msgs, err := b.mailer.Unread(binding.Address)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].Timestamp.Before(msgs[j].Timestamp) })
+
+	for _, msg := range msgs {
+		thread := b.state.ByMail(binding.Channel, msg.ThreadID)
+		var chatThread string
+		if thread != nil {
+			chatThread = thread.ChatThread
+		}
+		id, err := b.transport.Post(ctx, binding.Channel, chatThread, formatMail(msg))
+		if err != nil {
+			return fmt.Errorf("posting %s: %w", msg.ID, err)
+		}
+		if thread == nil && msg.ThreadID != "" {
+			thread = &Thread{
+				Channel:    binding.Channel,
+				ChatThread: id,
+				MailThread: msg.ThreadID,
+				Subject:    msg.Subject,
+			}
+			b.state.Add(thread)
+		}
+		if thread != nil {
+			thread.Peer = msg.From
+			thread.LastMessage = msg.ID
+			thread.UpdatedAt = time.Now()
+		}
+		if err := b.mailer.MarkRead(binding.Address, msg.ID); err != nil {
+			return fmt.Errorf("marking %s read: %w", msg.ID, err)
+		}
+	}
+	return nil
+}
+
+// postEscalations posts escalation_sent events appended to the events log
+// since the last poll into channels that want them. Escalations already
+// mailed to a channel's address arrive as mail and are skipped here.
+func (b *Bridge) postEscalations(ctx context.Context) error {
+	if b.EventsPath == "" || !b.wantsEscalations() {
+		return nil
+	}
+	evs, offset, err := readEvents(b.EventsPath, b.state.EventsOffset)
+	if err != nil {
+		return err
+	}
+	for _, ev := range evs {
+		if ev.Type != events.TypeEscalationSent {
+			continue
+		}
+		to := payloadString(ev.Payload, "to")
+		for _, binding := range b.cfg.Bindings {
+			if !binding.Escalations || mailedTo(to, binding.Address) {
+				continue
+			}
+			if _, err := b.transport.Post(ctx, binding.Channel, "", formatEscalation(ev)); err != nil {
+				// Leave the offset so the escalation is retried.
+				return err
+			}
+		}
+	}
+	b.state.EventsOffset = offset
+	return nil
+}
+
+func (b *Bridge) wantsEscalations() bool {
+	for _, binding := range b.cfg.Bindings {
+		if binding.Escalations {
+			return true
+		}
+	}
+	return false
+}
+
+// readEvents returns the complete event lines after offset and the offset
+// after the last one. A log shorter than offset was rotated and is read
+// from the start.
+func readEvents(path string, offset int64) ([]events.Event, int64, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is the town events log
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, offset, err
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil && info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+
+	var evs []events.Event
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			// A partial last line is picked up on the next read.
+			break
+		}
+		offset += int64(len(line))
+		var ev events.Event
+		if json.Unmarshal(line, &ev) == nil {
+			evs = append(evs, ev)
+		}
+	}
+	return evs, offset, nil
+}
+
+func fileSize(path string) int64 {
+	if path == "" {
+		return 0
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// mailedTo reports whether a comma-separated target list includes address.
+func mailedTo(targets, address string) bool {
+	for _, t := range strings.Split(targets, ",") {
+		if strings.TrimSuffix(strings.TrimSpace(t), "/") == strings.TrimSuffix(address, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func payloadString(payload map[string]interface{}, key string) string {
+	s, _ := payload[key].(string)
+	return s
+}
+
+func formatMail(msg *mail.Message) string {
+	var sb strings.Builder
+	if msg.Priority == mail.PriorityUrgent || msg.Priority == mail.PriorityHigh {
+		sb.WriteString("[" + strings.ToUpper(string(msg.Priority)) + "] ")
+	}
+	fmt.Fprintf(&sb, "%s: %s", msg.From, msg.Subject)
+	if body := strings.TrimSpace(msg.Body); body != "" {
+		sb.WriteString("\n\n" + body)
+	}
+	return sb.String()
+}
+
+func formatEscalation(ev events.Event) string {
+	text := fmt.Sprintf("Escalation from %s", ev.Actor)
+	if sev := payloadString(ev.Payload, "severity"); sev != "" {
+		text = fmt.Sprintf("[%s] %s", strings.ToUpper(sev), text)
+	}
+	if reason := payloadString(ev.Payload, "reason"); reason != "" {
+		text += ": " + reason
+	}
+	if id := payloadString(ev.Payload, "rig"); id != "" {
+		text += fmt.Sprintf(" (%s)", id)
+	}
+	return text
+}
+
+// chatSubject derives a mail subject from the first line of a chat message.
+func chatSubject(text string) string {
+	line := strings.TrimSpace(strings.SplitN(text, "\n", 2)[0])
+	if r := []rune(line); len(r) > 60 {
+		line = string(r[:57]) + "..."
+	}
+	return line
+}
+
+func replySubject(subject string) string {
+	if strings.HasPrefix(subject, "Re: ") {
+		return subject
+	}
+	return "Re: " + subject
+}
@@ -0,0 +1,378 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// fakeMailer keeps mailboxes in memory.
+type fakeMailer struct {
+	mu    sync.Mutex
+	sent  []*mail.Message
+	inbox map[string][]*mail.Message
+}
+
+func newFakeMailer() *fakeMailer {
+	return &fakeMailer{inbox: make(map[string][]*mail.Message)}
+}
+
+func (m *fakeMailer) Send(msg *mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *fakeMailer) Unread(address string) ([]*mail.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var unread []*mail.Message
+	for _, msg := range m.inbox[address] {
+		if !msg.Read {
+			unread = append(unread, msg)
+		}
+	}
+	return unread, nil
+}
+
+func (m *fakeMailer) MarkRead(address, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, msg := range m.inbox[address] {
+		if msg.ID == id {
+			msg.Read = true
+		}
+	}
+	return nil
+}
+
+func (m *fakeMailer) deliver(msg *mail.Message) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inbox[msg.To] = append(m.inbox[msg.To], msg)
+}
+
+func (m *fakeMailer) sentCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sent)
+}
+
+func testConfig() *Config {
+	return &Config{
+		Transport: "fake",
+		Bindings: []*Binding{
+			{Channel: "!ops", Address: "overseer", Escalations: true, AllowedUsers: []string{"@steve:example.org"}},
+		},
+	}
+}
+
+func TestOutboundMailAndThreadedReply(t *testing.T) {
+	fake := NewFake()
+	mailer := newFakeMailer()
+	b := New(testConfig(), fake, mailer, &State{}, t.Logf)
+
+	question := mail.NewMessage("mayor/", "overseer", "Deploy gastown?", "Release is green.")
+	mailer.deliver(question)
+	b.Poll(context.Background())
+
+	posts := fake.Posts()
+	if len(posts) != 1 {
+		t.Fatalf("posts = %d, want 1", len(posts))
+	}
+	if posts[0].Channel != "!ops" || posts[0].Thread != "" {
+		t.Errorf("post = %+v, want top-level in !ops", posts[0])
+	}
+	if !strings.Contains(posts[0].Text, "mayor/: Deploy gastown?") || !strings.Contains(posts[0].Text, "Release is green.") {
+		t.Errorf("post text = %q", posts[0].Text)
+	}
+	if !question.Read {
+		t.Error("posted mail was not marked read")
+	}
+
+	// Polling again posts nothing new.
+	b.Poll(context.Background())
+	if n := len(fake.Posts()); n != 1 {
+		t.Errorf("posts after second poll = %d, want 1", n)
+	}
+
+	// A chat reply in the thread becomes a mail reply in the same thread.
+	if err := b.HandleChat(ChatMessage{ID: "$r1", Channel: "!ops", Thread: posts[0].ID, User: "@steve:example.org", Text: "yes, ship it"}); err != nil {
+		t.Fatal(err)
+	}
+	reply := mailer.sent[0]
+	if reply.From != "overseer" || reply.To != "mayor/" {
+		t.Errorf("reply %s → %s, want overseer → mayor/", reply.From, reply.To)
+	}
+	if reply.ThreadID != question.ThreadID || reply.ReplyTo != question.ID {
+		t.Errorf("reply thread = %s/%s, want %s/%s", reply.ThreadID, reply.ReplyTo, question.ThreadID, question.ID)
+	}
+	if reply.Subject != "Re: Deploy gastown?" {
+		t.Errorf("reply subject = %q", reply.Subject)
+	}
+	if !strings.HasPrefix(reply.Body, "yes, ship it") || !strings.Contains(reply.Body, "@steve:example.org") {
+		t.Errorf("reply body = %q", reply.Body)
+	}
+
+	// Mail continuing the thread is posted into the same chat thread.
+	followUp := mail.NewReplyMessage("mayor/", "overseer", "Re: Deploy gastown?", "Shipped.", reply)
+	mailer.deliver(followUp)
+	b.Poll(context.Background())
+	posts = fake.Posts()
+	if len(posts) != 2 || posts[1].Thread != posts[0].ID {
+		t.Fatalf("follow-up posts = %+v, want reply in thread %s", posts, posts[0].ID)
+	}
+}
+
+func TestTopLevelChatStartsThread(t *testing.T) {
+	fake := NewFake()
+	mailer := newFakeMailer()
+	cfg := testConfig()
+	cfg.Bindings[0].To = "gastown/witness"
+	b := New(cfg, fake, mailer, &State{}, nil)
+
+	if err := b.HandleChat(ChatMessage{ID: "$q", Channel: "!ops", User: "@steve:example.org", Text: "What's blocking the convoy?\nIt's been an hour."}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.HandleChat(ChatMessage{ID: "$x", Channel: "!elsewhere", Text: "not bridged"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(mailer.sent) != 1 {
+		t.Fatalf("sent = %d, want 1", len(mailer.sent))
+	}
+	msg := mailer.sent[0]
+	if msg.From != "overseer" || msg.To != "gastown/witness" || msg.Subject != "What's blocking the convoy?" {
+		t.Errorf("msg = %s → %s %q", msg.From, msg.To, msg.Subject)
+	}
+
+	// Mail answering it lands in the chat thread the question started.
+	answer := mail.NewReplyMessage("gastown/witness", "overseer", "Re: What's blocking the convoy?", "gt-42 is stuck in review.", msg)
+	mailer.deliver(answer)
+	b.Poll(context.Background())
+	posts := fake.Posts()
+	if len(posts) != 1 || posts[0].Thread != "$q" {
+		t.Fatalf("posts = %+v, want reply in thread $q", posts)
+	}
+
+	// A further chat reply goes to the witness, answering its mail.
+	if err := b.HandleChat(ChatMessage{ID: "$q2", Channel: "!ops", Thread: "$q", User: "@steve:example.org", Text: "nudge it"}); err != nil {
+		t.Fatal(err)
+	}
+	next := mailer.sent[1]
+	if next.To != "gastown/witness" || next.ThreadID != msg.ThreadID || next.ReplyTo != answer.ID {
+		t.Errorf("next = to %s thread %s reply-to %s", next.To, next.ThreadID, next.ReplyTo)
+	}
+}
+
+func TestChatFromUnlistedUserDropped(t *testing.T) {
+	fake := NewFake()
+	mailer := newFakeMailer()
+	cfg := testConfig()
+	b := New(cfg, fake, mailer, &State{}, t.Logf)
+
+	for _, user := range []string{"@mallory:example.org", ""} {
+		if err := b.HandleChat(ChatMessage{ID: "$m", Channel: "!ops", User: user, Text: "run rm -rf /"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(mailer.sent) != 0 {
+		t.Fatalf("sent = %d, want unlisted users dropped", len(mailer.sent))
+	}
+
+	// An empty list allows no one.
+	cfg.Bindings[0].AllowedUsers = nil
+	if err := b.HandleChat(ChatMessage{ID: "$s", Channel: "!ops", User: "@steve:example.org", Text: "status?"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(mailer.sent) != 0 {
+		t.Errorf("sent = %d, want nothing with empty allowed_users", len(mailer.sent))
+	}
+}
+
+func TestEscalationsPosted(t *testing.T) {
+	dir := t.TempDir()
+	eventsPath := filepath.Join(dir, events.EventsFile)
+	writeEvent := func(ev events.Event) {
+		f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		data, _ := json.Marshal(ev)
+		_, _ = f.Write(append(data, '\n'))
+	}
+
+	fake := NewFake()
+	b := New(testConfig(), fake, newFakeMailer(), &State{}, t.Logf)
+	b.EventsPath = eventsPath
+
+	writeEvent(events.Event{Type: events.TypeEscalationSent, Actor: "gastown/witness",
+		Payload: map[string]interface{}{"rig": "hq-1", "to": "mayor/", "reason": "refinery stuck", "severity": "high"}})
+	writeEvent(events.Event{Type: events.TypeSling, Actor: "mayor"})
+	// Mailed to the bound address: arrives as mail instead.
+	writeEvent(events.Event{Type: events.TypeEscalationSent, Actor: "deacon",
+		Payload: map[string]interface{}{"to": "mayor/,overseer", "reason": "dup"}})
+
+	b.Poll(context.Background())
+	posts := fake.Posts()
+	if len(posts) != 1 {
+		t.Fatalf("posts = %+v, want 1", posts)
+	}
+	if want := "[HIGH] Escalation from gastown/witness: refinery stuck (hq-1)"; posts[0].Text != want {
+		t.Errorf("post = %q, want %q", posts[0].Text, want)
+	}
+
+	b.Poll(context.Background())
+	if n := len(fake.Posts()); n != 1 {
+		t.Errorf("escalation reposted: %d posts", n)
+	}
+}
+
+func TestRunRelaysFakeTransport(t *testing.T) {
+	fake := NewFake()
+	mailer := newFakeMailer()
+	cfg := testConfig()
+	cfg.PollInterval = "10ms"
+	b := New(cfg, fake, mailer, &State{}, t.Logf)
+	b.Start()
+	defer b.Stop()
+
+	fake.Deliver(ChatMessage{ID: "$1", Channel: "!ops", User: "@steve:example.org", Text: "status?"})
+	mailer.deliver(mail.NewMessage("mayor/", "overseer", "All quiet", ""))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for mailer.sentCount() == 0 || len(fake.Posts()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("bridge did not relay: sent=%d posts=%d", mailer.sentCount(), len(fake.Posts()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStatePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon", FileBridgeState)
+	s, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add(&Thread{Channel: "!ops", ChatThread: "$a", MailThread: "thread-1"})
+	s.EventsOffset = 42
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.EventsOffset != 42 || loaded.ByMail("!ops", "thread-1") == nil || loaded.ByChat("!ops", "$a") == nil {
+		t.Errorf("loaded = %+v", loaded)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := &Config{Bindings: []*Binding{{Channel: "!a", Address: "overseer"}, {Channel: "!a", Address: "mayor/"}}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("duplicate channel accepted")
+	}
+	cfg = &Config{Bindings: []*Binding{{Channel: "!a"}}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("binding without address accepted")
+	}
+	if got := (&Binding{}).Recipient(); got != DefaultTo {
+		t.Errorf("Recipient() = %q, want %q", got, DefaultTo)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		syncs int
+		sent  []map[string]interface{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/_matrix/client/v3/sync":
+			syncs++
+			if syncs > 2 {
+				// Park later long polls until the test ends.
+				mu.Unlock()
+				<-r.Context().Done()
+				mu.Lock()
+				return
+			}
+			// The first sync is history and must be skipped.
+			fmt.Fprintf(w, `{"next_batch":"s%[1]d","rooms":{"join":{"!ops:example.org":{"timeline":{"events":[
+				{"event_id":"$old%[1]d","type":"m.room.message","sender":"@steve:example.org","content":{"msgtype":"m.text","body":"hello"}},
+				{"event_id":"$self","type":"m.room.message","sender":"@gt:example.org","content":{"msgtype":"m.text","body":"echo"}},
+				{"event_id":"$th","type":"m.room.message","sender":"@steve:example.org","content":{"msgtype":"m.text","body":"in thread","m.relates_to":{"rel_type":"m.thread","event_id":"$root"}}}
+			]}}}}}`, syncs)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/_matrix/client/v3/rooms/!ops:example.org/send/m.room.message/"):
+			var content map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&content)
+			sent = append(sent, content)
+			_, _ = w.Write([]byte(`{"event_id":"$posted"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	m := NewMatrix(srv.URL, "@gt:example.org", "tok", srv.Client())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inbound := make(chan ChatMessage, 8)
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx, inbound) }()
+
+	var got []ChatMessage
+	for len(got) < 2 {
+		select {
+		case msg := <-inbound:
+			got = append(got, msg)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %+v, want 2 messages", got)
+		}
+	}
+	if got[0].ID != "$old2" || got[0].User != "@steve:example.org" || got[0].Thread != "" {
+		t.Errorf("first = %+v", got[0])
+	}
+	if got[1].ID != "$th" || got[1].Thread != "$root" {
+		t.Errorf("threaded = %+v", got[1])
+	}
+
+	id, err := m.Post(context.Background(), "!ops:example.org", "$root", "reply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "$posted" {
+		t.Errorf("Post id = %q", id)
+	}
+	mu.Lock()
+	rel, _ := sent[0]["m.relates_to"].(map[string]interface{})
+	mu.Unlock()
+	if rel["rel_type"] != "m.thread" || rel["event_id"] != "$root" {
+		t.Errorf("relates_to = %v", rel)
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,284 @@
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// FileBridge is the bridge configuration, under mayor/.
+const FileBridge = "bridge.json"
+
+// FileBridgeState holds thread mappings and the events offset, under daemon/.
+const FileBridgeState = "bridge-state.json"
+
+// Transport kinds.
+const (
+	KindMatrix = "matrix"
+)
+
+// DefaultPollInterval is how often mailboxes and the events log are checked
+// for outbound messages.
+const DefaultPollInterval = 30 * time.Second
+
+// DefaultTo is where top-level chat messages are sent when a binding does
+// not say.
+const DefaultTo = "mayor/"
+
+// Binding maps a chat channel to a Gas Town address.
+type Binding struct {
+	// Channel is the transport's channel ID (a Matrix room ID).
+	Channel string `json:"channel"`
+
+	// Address is the Gas Town identity the channel speaks as. Unread mail
+	// to Address is posted into the channel, and chat messages are sent
+	// from Address. Usually "overseer".
+	Address string `json:"address"`
+
+	// To is where top-level chat messages go (default "mayor/"). Replies in
+	// a chat thread go to whoever last wrote in the mail thread.
+	To string `json:"to,omitempty"`
+
+	// Escalations posts every escalation into the channel, even those not
+	// mailed to Address.
+	Escalations bool `json:"escalations,omitempty"`
+
+	// AllowedUsers are the chat users (@alice:example.org) whose messages
+	// become mail. Messages from anyone else in the channel are dropped;
+	// an empty list drops them all.
+	AllowedUsers []string `json:"allowed_users,omitempty"`
+}
+
+// Allows reports whether a chat user may send mail through the binding.
+func (b *Binding) Allows(user string) bool {
+	if user == "" {
+		return false
+	}
+	for _, u := range b.AllowedUsers {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
+
+// Recipient returns where top-level chat messages are sent.
+func (b *Binding) Recipient() string {
+	if b.To != "" {
+		return b.To
+	}
+	return DefaultTo
+}
+
+// Config is mayor/bridge.json.
+type Config struct {
+	// Transport is the chat system: matrix.
+	Transport string `json:"transport"`
+
+	// Homeserver is the Matrix homeserver URL (https://matrix.example.org).
+	Homeserver string `json:"homeserver,omitempty"`
+
+	// UserID is the bot account (@gastown:example.org). Its own messages
+	// are not bridged back into mail.
+	UserID string `json:"user_id,omitempty"`
+
+	// TokenEnv names the environment variable holding the access token.
+	// Default: MATRIX_TOKEN.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// PollInterval is how often outbound mail is checked (default 30s).
+	PollInterval string `json:"poll_interval,omitempty"`
+
+	Bindings []*Binding `json:"bindings"`
+}
+
+// ConfigPath returns the path of a town's bridge.json.
+func ConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, constants.DirMayor, FileBridge)
+}
+
+// LoadConfig reads a town's bridge.json. Returns nil, nil if the town has
+// no bridge configured.
+func LoadConfig(townRoot string) (*Config, error) {
+	data, err := os.ReadFile(ConfigPath(townRoot)) //nolint:gosec // G304: path is under the town root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", FileBridge, err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", FileBridge, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", FileBridge, err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks that every binding has a channel and address and that
+// no channel is bound twice.
+func (c *Config) Validate() error {
+	seen := make(map[string]bool)
+	for i, b := range c.Bindings {
+		if b.Channel == "" || b.Address == "" {
+			return fmt.Errorf("binding %d needs a channel and an address", i)
+		}
+		if seen[b.Channel] {
+			return fmt.Errorf("channel %s is bound twice", b.Channel)
+		}
+		seen[b.Channel] = true
+	}
+	return nil
+}
+
+// Binding returns the binding for a channel, or nil.
+func (c *Config) Binding(channel string) *Binding {
+	for _, b := range c.Bindings {
+		if b.Channel == channel {
+			return b
+		}
+	}
+	return nil
+}
+
+// Interval returns the outbound poll interval.
+func (c *Config) Interval() time.Duration {
+	if d, err := time.ParseDuration(c.PollInterval); err == nil && d > 0 {
+		return d
+	}
+	return DefaultPollInterval
+}
+
+// NewTransport connects the configured transport, reading its token from
+// the environment.
+func (c *Config) NewTransport() (Transport, error) {
+	switch c.Transport {
+	case KindMatrix:
+		tokenEnv := c.TokenEnv
+		if tokenEnv == "" {
+			tokenEnv = "MATRIX_TOKEN"
+		}
+		token := os.Getenv(tokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("no matrix token, set $%s", tokenEnv)
+		}
+		if c.Homeserver == "" || c.UserID == "" {
+			return nil, fmt.Errorf("matrix needs a homeserver and user_id")
+		}
+		return NewMatrix(c.Homeserver, c.UserID, token, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown bridge transport %q (want matrix)", c.Transport)
+	}
+}
+
+// maxThreads bounds the thread map; the least recently used are dropped.
+const maxThreads = 500
+
+// Thread links a chat thread to a mail thread.
+type Thread struct {
+	Channel string `json:"channel"`
+
+	// ChatThread is the transport's ID of the thread's root chat message.
+	ChatThread string `json:"chat_thread"`
+
+	// MailThread is the mail ThreadID.
+	MailThread string `json:"mail_thread"`
+
+	// Subject is the mail thread's subject.
+	Subject string `json:"subject"`
+
+	// Peer is who chat replies go to: the last other party in the thread.
+	Peer string `json:"peer"`
+
+	// LastMessage is the ID of the latest mail in the thread, which chat
+	// replies answer.
+	LastMessage string `json:"last_message"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// State is the bridge's persistent state in daemon/bridge-state.json.
+type State struct {
+	Threads []*Thread `json:"threads"`
+
+	// EventsOffset is how far into .events.jsonl escalations were read.
+	EventsOffset int64 `json:"events_offset"`
+
+	path string
+}
+
+// StatePath returns the path of a town's bridge state file.
+func StatePath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", FileBridgeState)
+}
+
+// LoadState reads the bridge state. A missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{path: path}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the town root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", FileBridgeState, err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", FileBridgeState, err)
+	}
+	return s, nil
+}
+
+// Save writes the state back to disk. In-memory states (no path) are not
+// saved.
+func (s *State) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating %s dir: %w", FileBridgeState, err)
+	}
+	return util.AtomicWriteJSON(s.path, s)
+}
+
+// ByChat returns the thread for a chat thread root, or nil.
+func (s *State) ByChat(channel, chatThread string) *Thread {
+	for _, t := range s.Threads {
+		if t.Channel == channel && t.ChatThread == chatThread {
+			return t
+		}
+	}
+	return nil
+}
+
+// ByMail returns the chat thread carrying a mail thread in a channel, or nil.
+func (s *State) ByMail(channel, mailThread string) *Thread {
+	for _, t := range s.Threads {
+		if t.Channel == channel && t.MailThread == mailThread {
+			return t
+		}
+	}
+	return nil
+}
+
+// Add records a new thread, dropping the least recently used beyond
+// maxThreads.
+func (s *State) Add(t *Thread) {
+	s.Threads = append(s.Threads, t)
+	if len(s.Threads) <= maxThreads {
+		return
+	}
+	oldest := 0
+	for i, t := range s.Threads {
+		if t.UpdatedAt.Before(s.Threads[oldest].UpdatedAt) {
+			oldest = i
+		}
+	}
+	s.Threads = append(s.Threads[:oldest], s.Threads[oldest+1:]...)
+}
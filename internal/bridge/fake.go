@@ -0,0 +1,70 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Post is a message posted to a Fake transport.
+type Post struct {
+	ID      string
+	Channel string
+	Thread  string
+	Text    string
+}
+
+// Fake is an in-memory Transport for tests. Messages passed to Deliver
+// are handed to Run; posts are recorded.
+type Fake struct {
+	mu    sync.Mutex
+	posts []Post
+	next  int
+	in    chan ChatMessage
+}
+
+// NewFake returns an empty fake transport.
+func NewFake() *Fake {
+	return &Fake{in: make(chan ChatMessage, 16)}
+}
+
+// Name implements Transport.
+func (f *Fake) Name() string { return "fake" }
+
+// Run implements Transport.
+func (f *Fake) Run(ctx context.Context, inbound chan<- ChatMessage) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-f.in:
+			select {
+			case inbound <- msg:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// Post implements Transport.
+func (f *Fake) Post(_ context.Context, channel, thread, text string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next++
+	id := fmt.Sprintf("$fake%d", f.next)
+	f.posts = append(f.posts, Post{ID: id, Channel: channel, Thread: thread, Text: text})
+	return id, nil
+}
+
+// Deliver queues a chat message as if a user had posted it.
+func (f *Fake) Deliver(msg ChatMessage) {
+	f.in <- msg
+}
+
+// Posts returns the messages posted so far.
+func (f *Fake) Posts() []Post {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Post(nil), f.posts...)
+}
@@ -0,0 +1,198 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// matrixSyncTimeout is how long a /sync long poll waits for new events.
+const matrixSyncTimeout = 30 * time.Second
+
+// Matrix is a Transport over the Matrix client-server API. Channels are
+// room IDs; threads use m.thread relations.
+type Matrix struct {
+	homeserver string
+	userID     string
+	token      string
+	http       *http.Client
+	txn        atomic.Int64
+}
+
+// NewMatrix returns a Matrix transport for the bot account userID. hc may
+// be nil.
+func NewMatrix(homeserver, userID, token string, hc *http.Client) *Matrix {
+	if hc == nil {
+		// Long enough for a /sync long poll.
+		hc = &http.Client{Timeout: matrixSyncTimeout + 30*time.Second}
+	}
+	m := &Matrix{
+		homeserver: strings.TrimRight(homeserver, "/"),
+		userID:     userID,
+		token:      token,
+		http:       hc,
+	}
+	m.txn.Store(time.Now().UnixNano())
+	return m
+}
+
+// Name implements Transport.
+func (m *Matrix) Name() string { return KindMatrix }
+
+type matrixSync struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []matrixEvent `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+	} `json:"rooms"`
+}
+
+type matrixEvent struct {
+	EventID string `json:"event_id"`
+	Type    string `json:"type"`
+	Sender  string `json:"sender"`
+	Content struct {
+		MsgType   string `json:"msgtype"`
+		Body      string `json:"body"`
+		RelatesTo *struct {
+			RelType string `json:"rel_type"`
+			EventID string `json:"event_id"`
+		} `json:"m.relates_to"`
+	} `json:"content"`
+}
+
+// Run implements Transport. The first sync only establishes the position
+// in the timeline; its events are history and are skipped.
+func (m *Matrix) Run(ctx context.Context, inbound chan<- ChatMessage) error {
+	var since string
+	first := true
+	for ctx.Err() == nil {
+		q := url.Values{}
+		if since != "" {
+			q.Set("since", since)
+			q.Set("timeout", fmt.Sprint(matrixSyncTimeout.Milliseconds()))
+		}
+		var resp matrixSync
+		if err := m.do(ctx, http.MethodGet, "/_matrix/client/v3/sync?"+q.Encode(), nil, &resp); err != nil {
+			return err
+		}
+		since = resp.NextBatch
+		if first {
+			first = false
+			continue
+		}
+		for room, joined := range resp.Rooms.Join {
+			for _, ev := range joined.Timeline.Events {
+				msg, ok := m.chatMessage(room, ev)
+				if !ok {
+					continue
+				}
+				select {
+				case inbound <- msg:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// chatMessage converts a text message from another user.
+func (m *Matrix) chatMessage(room string, ev matrixEvent) (ChatMessage, bool) {
+	if ev.Type != "m.room.message" || ev.Sender == m.userID {
+		return ChatMessage{}, false
+	}
+	if ev.Content.MsgType != "m.text" && ev.Content.MsgType != "m.notice" {
+		return ChatMessage{}, false
+	}
+	msg := ChatMessage{
+		ID:      ev.EventID,
+		Channel: room,
+		User:    ev.Sender,
+		Text:    ev.Content.Body,
+	}
+	if rel := ev.Content.RelatesTo; rel != nil && rel.RelType == "m.thread" {
+		msg.Thread = rel.EventID
+	}
+	return msg, true
+}
+
+// Post implements Transport.
+func (m *Matrix) Post(ctx context.Context, channel, thread, text string) (string, error) {
+	content := map[string]interface{}{
+		"msgtype": "m.text",
+		"body":    text,
+	}
+	if thread != "" {
+		content["m.relates_to"] = map[string]interface{}{
+			"rel_type":        "m.thread",
+			"event_id":        thread,
+			"is_falling_back": true,
+			"m.in_reply_to":   map[string]string{"event_id": thread},
+		}
+	}
+	path := fmt.Sprintf("/_matrix/client/v3/rooms/%s/send/m.room.message/gt%d",
+		url.PathEscape(channel), m.txn.Add(1))
+	var resp struct {
+		EventID string `json:"event_id"`
+	}
+	if err := m.do(ctx, http.MethodPut, path, content, &resp); err != nil {
+		return "", err
+	}
+	return resp.EventID, nil
+}
+
+func (m *Matrix) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.homeserver+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return fmt.Errorf("%s %s: %d %s", method, strings.SplitN(path, "?", 2)[0], resp.StatusCode, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding %s response: %w", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Package cgroup provides optional cgroup v2 resource limits for agent sessions.
+//
+// Limits are applied by launching the agent command under a transient systemd
+// scope (systemd-run --user --scope) placed in a dedicated slice. The scope's
+// cgroup enforces CPU, memory and task limits for the agent and every process
+// it spawns, so a runaway test suite cannot take down the whole machine.
+//
+// Usage is read back from the cgroup v2 filesystem. On platforms without
+// cgroup v2 support, commands are returned unwrapped and usage is unavailable.
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// SliceName is the systemd slice that holds all Gas Town agent scopes.
+const SliceName = "gastown.slice"
+
+// Errors returned by usage lookups.
+var (
+	ErrUnsupported = errors.New("cgroup v2 resource limits not supported on this system")
+	ErrNotManaged  = errors.New("process is not in a Gas Town cgroup")
+)
+
+// Usage reports resource consumption for a session's cgroup.
+type Usage struct {
+	// Path is the cgroup path relative to the cgroup v2 mount.
+	Path string `json:"path"`
+
+	// MemoryCurrent is the current memory usage in bytes.
+	MemoryCurrent int64 `json:"memory_current"`
+
+	// MemoryMax is the memory limit in bytes (0 = unlimited).
+	MemoryMax int64 `json:"memory_max,omitempty"`
+
+	// CPUUsage is the total CPU time consumed.
+	CPUUsage time.Duration `json:"cpu_usage"`
+
+	// PidsCurrent is the number of tasks in the cgroup.
+	PidsCurrent int64 `json:"pids_current"`
+
+	// PidsMax is the task limit (0 = unlimited).
+	PidsMax int64 `json:"pids_max,omitempty"`
+
+	// OOMKills is the number of processes killed for exceeding MemoryMax.
+	OOMKills int64 `json:"oom_kills"`
+}
+
+// UnitName returns the systemd scope unit name for a session.
+// A timestamp suffix avoids collisions with a scope from a previous
+// incarnation of the same session that systemd has not yet collected.
+func UnitName(sessionID string, now time.Time) string {
+	return fmt.Sprintf("%s-%d.scope", sessionID, now.Unix())
+}
+
+// buildWrapCommand returns command wrapped in a systemd-run scope with the
+// given limits. The original command runs under sh -c so shell constructs
+// like "export X=y && exec claude" keep working.
+func buildWrapCommand(unit string, res config.RoleResourceConfig, command string) string {
+	args := []string{
+		"systemd-run", "--user", "--scope", "--quiet",
+		"--slice=" + SliceName,
+		"--unit=" + unit,
+	}
+	if res.CPUQuota != "" {
+		args = append(args, "-p", "CPUQuota="+res.CPUQuota)
+	}
+	if res.MemoryMax != "" {
+		// Disable swap so memory_max is a real ceiling rather than a swap trigger.
+		args = append(args, "-p", "MemoryMax="+res.MemoryMax, "-p", "MemorySwapMax=0")
+	}
+	if res.PidsMax > 0 {
+		args = append(args, "-p", fmt.Sprintf("TasksMax=%d", res.PidsMax))
+	}
+	args = append(args, "--", "sh", "-c", config.ShellQuote(command))
+	return strings.Join(args, " ")
+}
+
+// parseProcCgroup extracts the unified (v2) hierarchy path from the
+// contents of /proc/<pid>/cgroup. The v2 entry has the form "0::/path".
+func parseProcCgroup(data string) (string, error) {
+	for _, line := range strings.Split(data, "\n") {
+		if strings.HasPrefix(line, "0::") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "0::")), nil
+		}
+	}
+	return "", ErrUnsupported
+}
+
+// isManagedPath reports whether a cgroup path belongs to a Gas Town scope.
+func isManagedPath(path string) bool {
+	return strings.Contains(path, "/"+SliceName+"/")
+}
+
+// parseMax parses a cgroup limit file value. "max" means unlimited (0).
+func parseMax(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "max" || value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// parseKeyed parses a flat-keyed cgroup file (e.g., memory.events, cpu.stat)
+// into a map of key to integer value.
+func parseKeyed(data string) map[string]int64 {
+	result := make(map[string]int64)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if n, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			result[fields[0]] = n
+		}
+	}
+	return result
+}
+
+// FormatBytes renders a byte count in human-readable binary units.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// OOMReason returns a human-readable session death reason for OOM kills.
+func OOMReason(u *Usage) string {
+	if u.MemoryMax > 0 {
+		return fmt.Sprintf("oom-killed: exceeded memory_max (%s)", FormatBytes(u.MemoryMax))
+	}
+	return "oom-killed"
+}
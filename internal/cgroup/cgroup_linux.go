@@ -0,0 +1,101 @@
+//go:build linux
+
+package cgroup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// mountPoint is where the cgroup v2 unified hierarchy is mounted.
+const mountPoint = "/sys/fs/cgroup"
+
+// Supported returns true if cgroup v2 is mounted and systemd-run is available.
+func Supported() bool {
+	if _, err := os.Stat(filepath.Join(mountPoint, "cgroup.controllers")); err != nil {
+		return false
+	}
+	_, err := exec.LookPath("systemd-run")
+	return err == nil
+}
+
+// WrapCommand wraps command so it runs inside a transient cgroup scope with
+// the given limits. Returns command unchanged when no limits are set or the
+// system does not support cgroup v2.
+func WrapCommand(unit string, res config.RoleResourceConfig, command string) string {
+	if !res.IsSet() || !Supported() {
+		return command
+	}
+	return buildWrapCommand(unit, res, command)
+}
+
+// UsageForPID returns resource usage for the Gas Town cgroup containing pid.
+// Returns ErrNotManaged if the process is not running under a Gas Town scope.
+func UsageForPID(pid int) (*Usage, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return nil, fmt.Errorf("reading cgroup for pid %d: %w", pid, err)
+	}
+	path, err := parseProcCgroup(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if !isManagedPath(path) {
+		return nil, ErrNotManaged
+	}
+	return UsageForPath(path)
+}
+
+// UsageForPath returns resource usage for a cgroup path relative to the
+// cgroup v2 mount point.
+func UsageForPath(path string) (*Usage, error) {
+	dir := filepath.Join(mountPoint, path)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("cgroup %s: %w", path, err)
+	}
+
+	u := &Usage{Path: path}
+	if v, err := readMax(dir, "memory.current"); err == nil {
+		u.MemoryCurrent = v
+	}
+	if v, err := readMax(dir, "memory.max"); err == nil {
+		u.MemoryMax = v
+	}
+	if v, err := readMax(dir, "pids.current"); err == nil {
+		u.PidsCurrent = v
+	}
+	if v, err := readMax(dir, "pids.max"); err == nil {
+		u.PidsMax = v
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "cpu.stat")); err == nil {
+		u.CPUUsage = time.Duration(parseKeyed(string(data))["usage_usec"]) * time.Microsecond
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "memory.events")); err == nil {
+		u.OOMKills = parseKeyed(string(data))["oom_kill"]
+	}
+	return u, nil
+}
+
+// readMax reads a single-value cgroup file, treating "max" as unlimited.
+func readMax(dir, name string) (int64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return 0, err
+	}
+	return parseMax(strings.TrimSpace(string(data)))
+}
+
+// ScopeOOMKilled reports whether systemd ended the scope at a cgroup path
+// because the OOM killer fired. The scope's cgroup is removed when it stops,
+// but a failed transient unit stays loaded with its Result until
+// reset-failed, so this still answers after the session is gone.
+func ScopeOOMKilled(path string) bool {
+	out, err := exec.Command("systemctl", "--user", "show", "-p", "Result", "--value", filepath.Base(path)).Output() //nolint:gosec // G204: unit name from our own cgroup path
+	return err == nil && strings.TrimSpace(string(out)) == "oom-kill"
+}
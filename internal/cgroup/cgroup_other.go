@@ -0,0 +1,30 @@
+//go:build !linux
+
+package cgroup
+
+import "github.com/steveyegge/gastown/internal/config"
+
+// Supported returns false: cgroup v2 limits are Linux-only.
+func Supported() bool {
+	return false
+}
+
+// WrapCommand returns command unchanged on non-Linux platforms.
+func WrapCommand(unit string, res config.RoleResourceConfig, command string) string {
+	return command
+}
+
+// UsageForPID is not supported on non-Linux platforms.
+func UsageForPID(pid int) (*Usage, error) {
+	return nil, ErrUnsupported
+}
+
+// UsageForPath is not supported on non-Linux platforms.
+func UsageForPath(path string) (*Usage, error) {
+	return nil, ErrUnsupported
+}
+
+// ScopeOOMKilled always returns false on non-Linux platforms.
+func ScopeOOMKilled(path string) bool {
+	return false
+}
@@ -0,0 +1,84 @@
+package cgroup
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestBuildWrapCommand(t *testing.T) {
+	res := config.RoleResourceConfig{CPUQuota: "200%", MemoryMax: "4G", PidsMax: 512}
+	got := buildWrapCommand("gt-gastown-Toast-1.scope", res, "export GT_ROLE=polecat && exec claude")
+
+	for _, want := range []string{
+		"systemd-run --user --scope --quiet",
+		"--slice=gastown.slice",
+		"--unit=gt-gastown-Toast-1.scope",
+		"-p CPUQuota=200%",
+		"-p MemoryMax=4G -p MemorySwapMax=0",
+		"-p TasksMax=512",
+		"-- sh -c 'export GT_ROLE=polecat && exec claude'",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("buildWrapCommand() = %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestBuildWrapCommand_OnlyConfiguredLimits(t *testing.T) {
+	got := buildWrapCommand("u.scope", config.RoleResourceConfig{PidsMax: 64}, "claude")
+	if strings.Contains(got, "CPUQuota") || strings.Contains(got, "MemoryMax") {
+		t.Errorf("buildWrapCommand() = %q, want only TasksMax", got)
+	}
+	if !strings.Contains(got, "TasksMax=64") {
+		t.Errorf("buildWrapCommand() = %q, missing TasksMax", got)
+	}
+}
+
+func TestUnitName(t *testing.T) {
+	got := UnitName("gt-gastown-Toast", time.Unix(1700000000, 0))
+	if got != "gt-gastown-Toast-1700000000.scope" {
+		t.Errorf("UnitName() = %q", got)
+	}
+}
+
+func TestParseProcCgroup(t *testing.T) {
+	data := "0::/user.slice/user-1000.slice/user@1000.service/gastown.slice/gt-gastown-Toast-1.scope\n"
+	path, err := parseProcCgroup(data)
+	if err != nil {
+		t.Fatalf("parseProcCgroup() error: %v", err)
+	}
+	if !isManagedPath(path) {
+		t.Errorf("isManagedPath(%q) = false, want true", path)
+	}
+
+	if _, err := parseProcCgroup("12:memory:/foo\n"); err != ErrUnsupported {
+		t.Errorf("parseProcCgroup(v1) error = %v, want ErrUnsupported", err)
+	}
+	if isManagedPath("/user.slice/user-1000.slice/session-2.scope") {
+		t.Error("isManagedPath() = true for unmanaged session scope")
+	}
+}
+
+func TestParseKeyedAndMax(t *testing.T) {
+	events := parseKeyed("low 0\nhigh 0\nmax 12\noom 2\noom_kill 1\n")
+	if events["oom_kill"] != 1 {
+		t.Errorf("oom_kill = %d, want 1", events["oom_kill"])
+	}
+
+	if v, _ := parseMax("max"); v != 0 {
+		t.Errorf("parseMax(max) = %d, want 0", v)
+	}
+	if v, _ := parseMax("4294967296\n"); v != 4294967296 {
+		t.Errorf("parseMax() = %d, want 4294967296", v)
+	}
+}
+
+func TestOOMReason(t *testing.T) {
+	got := OOMReason(&Usage{MemoryMax: 4 << 30, OOMKills: 1})
+	if got != "oom-killed: exceeded memory_max (4.0G)" {
+		t.Errorf("OOMReason() = %q", got)
+	}
+}
@@ -49,6 +49,13 @@ type Checkpoint struct {
 
 	// Notes contains optional context from the session.
 	Notes string `json:"notes,omitempty"`
+
+	// SnapshotRef is the refs/gt/checkpoints ref holding a copy of the
+	// uncommitted changes at capture time (empty if the tree was clean).
+	SnapshotRef string `json:"snapshot_ref,omitempty"`
+
+	// SnapshotCommit is the commit SnapshotRef pointed to when written.
+	SnapshotCommit string `json:"snapshot_commit,omitempty"`
 }
 
 // Path returns the checkpoint file path for a given polecat directory.
@@ -115,6 +122,8 @@ func Remove(polecatDir string) error {
 }
 
 // Capture creates a checkpoint by capturing current git and work state.
+// Uncommitted changes are also snapshotted to refs/gt/checkpoints so they
+// survive loss of the worktree (see SnapshotWorktree).
 func Capture(polecatDir string) (*Checkpoint, error) {
 	cp := &Checkpoint{
 		Timestamp: time.Now(),
@@ -153,6 +162,14 @@ func Capture(polecatDir string) (*Checkpoint, error) {
 		cp.Branch = strings.TrimSpace(string(output))
 	}
 
+	// Snapshot the uncommitted work (best effort, like the fields above)
+	if len(cp.ModifiedFiles) > 0 {
+		if snap, err := SnapshotWorktree(polecatDir, NameFromPath(polecatDir), DefaultKeep); err == nil && snap != nil {
+			cp.SnapshotRef = snap.Ref
+			cp.SnapshotCommit = snap.Commit
+		}
+	}
+
 	return cp, nil
 }
 
@@ -0,0 +1,252 @@
+package checkpoint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RefPrefix is the namespace for worktree snapshots. Refs live in the
+// repository's common ref store, so they survive the worktree being
+// removed or corrupted:
+//
+//	refs/gt/checkpoints/<polecat>/<timestamp>
+const RefPrefix = "refs/gt/checkpoints/"
+
+// DefaultKeep is how many snapshots are kept per polecat.
+const DefaultKeep = 5
+
+// refTimeFormat sorts lexically in time order and is valid in a ref name.
+const refTimeFormat = "20060102T150405.000Z"
+
+// Snapshot is a saved copy of a worktree's uncommitted changes.
+//
+// Commit is a commit whose parent is the worktree's HEAD at capture time
+// and whose tree is the working tree (tracked and untracked files, minus
+// ignored ones), so `git diff Parent Commit` is exactly the uncommitted
+// work.
+type Snapshot struct {
+	Ref    string
+	Commit string
+	Parent string
+	Time   time.Time
+}
+
+// SnapshotWorktree saves the uncommitted changes of the worktree at dir
+// under refs/gt/checkpoints/<name>/ and prunes all but the newest keep
+// snapshots for name (keep <= 0 disables pruning). The real index is not
+// touched. Returns nil, nil if there is nothing uncommitted to save.
+func SnapshotWorktree(dir, name string, keep int) (*Snapshot, error) {
+	name = sanitizeRefComponent(name)
+	if name == "" {
+		return nil, fmt.Errorf("snapshot needs a polecat name")
+	}
+
+	head, err := gitOut(dir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	// Stage everything into a throwaway index so the worktree's own index
+	// (and whatever the agent has staged) is left alone.
+	tmp, err := os.CreateTemp("", "gt-checkpoint-index-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp index: %w", err)
+	}
+	indexPath := tmp.Name()
+	_ = tmp.Close()
+	_ = os.Remove(indexPath) // git must create it
+	defer os.Remove(indexPath)
+	env := []string{"GIT_INDEX_FILE=" + indexPath}
+
+	if _, err := gitOut(dir, env, "read-tree", "HEAD"); err != nil {
+		return nil, err
+	}
+	if _, err := gitOut(dir, env, "add", "--all", "--", ":(top)", ":(top,exclude,glob)**/"+Filename); err != nil {
+		return nil, err
+	}
+	tree, err := gitOut(dir, env, "write-tree")
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := gitOut(dir, nil, "rev-parse", "HEAD^{tree}")
+	if err != nil {
+		return nil, err
+	}
+	if tree == headTree {
+		return nil, nil // clean
+	}
+
+	now := time.Now().UTC()
+	msg := fmt.Sprintf("gt checkpoint %s at %s", name, now.Format(time.RFC3339))
+	commitEnv := []string{
+		"GIT_AUTHOR_NAME=Gas Town checkpoint", "GIT_AUTHOR_EMAIL=checkpoint@gastown.local",
+		"GIT_COMMITTER_NAME=Gas Town checkpoint", "GIT_COMMITTER_EMAIL=checkpoint@gastown.local",
+	}
+	commit, err := gitOut(dir, commitEnv, "commit-tree", tree, "-p", head, "-m", msg)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := RefPrefix + name + "/" + now.Format(refTimeFormat)
+	if _, err := gitOut(dir, nil, "update-ref", "-m", msg, ref, commit); err != nil {
+		return nil, err
+	}
+
+	if keep > 0 {
+		if err := PruneSnapshots(dir, name, keep); err != nil {
+			return nil, err
+		}
+	}
+	return &Snapshot{Ref: ref, Commit: commit, Parent: head, Time: now}, nil
+}
+
+// ListSnapshots returns the snapshots saved for name, newest first.
+// With an empty name, snapshots of every polecat are returned.
+func ListSnapshots(dir, name string) ([]Snapshot, error) {
+	prefix := RefPrefix
+	if name != "" {
+		prefix += sanitizeRefComponent(name) + "/"
+	}
+	out, err := gitOut(dir, nil, "for-each-ref", "--sort=-refname",
+		"--format=%(refname) %(objectname) %(parent)", prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var snaps []Snapshot
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		s := Snapshot{Ref: fields[0], Commit: fields[1]}
+		if len(fields) > 2 {
+			s.Parent = fields[2]
+		}
+		if t, err := time.Parse(refTimeFormat, filepath.Base(s.Ref)); err == nil {
+			s.Time = t
+		}
+		snaps = append(snaps, s)
+	}
+	return snaps, nil
+}
+
+// PruneSnapshots deletes all but the newest keep snapshots for name.
+func PruneSnapshots(dir, name string, keep int) error {
+	snaps, err := ListSnapshots(dir, name)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(snaps); i++ {
+		if _, err := gitOut(dir, nil, "update-ref", "-d", snaps[i].Ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveSnapshot finds a snapshot by full ref, by timestamp component, or
+// "latest" (or "") for the newest snapshot of name.
+func ResolveSnapshot(dir, name, which string) (*Snapshot, error) {
+	snaps, err := ListSnapshots(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(snaps) == 0 {
+		return nil, fmt.Errorf("no checkpoint snapshots for %s", name)
+	}
+	if which == "" || which == "latest" {
+		return &snaps[0], nil
+	}
+	for i := range snaps {
+		if snaps[i].Ref == which || filepath.Base(snaps[i].Ref) == which {
+			return &snaps[i], nil
+		}
+	}
+	return nil, fmt.Errorf("checkpoint snapshot %q not found", which)
+}
+
+// Restore re-applies a snapshot's uncommitted changes to the worktree at
+// dir. Only the working tree is modified, never the index or HEAD. The
+// changes apply cleanly when the worktree is at the snapshot's parent
+// commit; otherwise git apply may refuse and nothing is changed.
+func Restore(dir string, snap *Snapshot) error {
+	parent := snap.Parent
+	if parent == "" {
+		p, err := gitOut(dir, nil, "rev-parse", snap.Commit+"^")
+		if err != nil {
+			return err
+		}
+		parent = p
+	}
+
+	cmd := exec.Command("git", "diff", "--binary", "--no-color", "--no-ext-diff", parent, snap.Commit) //nolint:gosec // G204: args are refs/SHAs
+	cmd.Dir = dir
+	diff, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("reading snapshot %s: %w", snap.Ref, err)
+	}
+	if len(bytes.TrimSpace(diff)) == 0 {
+		return nil
+	}
+
+	apply := exec.Command("git", "apply", "--whitespace=nowarn")
+	apply.Dir = dir
+	apply.Stdin = bytes.NewReader(diff)
+	var stderr bytes.Buffer
+	apply.Stderr = &stderr
+	if err := apply.Run(); err != nil {
+		short := parent
+		if len(short) > 12 {
+			short = short[:12]
+		}
+		return fmt.Errorf("snapshot does not apply cleanly (taken on %s): %s\n"+
+			"Apply with conflict markers instead: git diff %s %s | git apply -3",
+			short, strings.TrimSpace(stderr.String()), parent, snap.Commit)
+	}
+	return nil
+}
+
+// NameFromPath derives the polecat (or crew member) name for a worktree
+// from its location: .../polecats/<name>/... or .../crew/<name>/....
+// Falls back to GT_POLECAT, then the directory name.
+func NameFromPath(dir string) string {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(dir)), "/")
+	for i := len(parts) - 2; i >= 0; i-- {
+		if parts[i] == "polecats" || parts[i] == "crew" {
+			return parts[i+1]
+		}
+	}
+	if name := os.Getenv("GT_POLECAT"); name != "" {
+		return name
+	}
+	return filepath.Base(dir)
+}
+
+var unsafeRefChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func sanitizeRefComponent(s string) string {
+	s = unsafeRefChars.ReplaceAllString(s, "-")
+	return strings.Trim(s, ".-")
+}
+
+func gitOut(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...) //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,148 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initRepo creates a git repo with one commit and returns its path.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	writeFile(t, dir, "tracked.txt", "v1\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSnapshotWorktree_Clean(t *testing.T) {
+	dir := initRepo(t)
+	snap, err := SnapshotWorktree(dir, "Toast", DefaultKeep)
+	if err != nil {
+		t.Fatalf("SnapshotWorktree() error: %v", err)
+	}
+	if snap != nil {
+		t.Errorf("SnapshotWorktree() on a clean tree = %+v, want nil", snap)
+	}
+}
+
+func TestSnapshotWorktree_RestoreRoundtrip(t *testing.T) {
+	dir := initRepo(t)
+	writeFile(t, dir, "tracked.txt", "v2\n")
+	writeFile(t, dir, "new.txt", "untracked\n")
+	writeFile(t, dir, Filename, "{}")
+
+	snap, err := SnapshotWorktree(dir, "Toast", DefaultKeep)
+	if err != nil || snap == nil {
+		t.Fatalf("SnapshotWorktree() = %v, %v", snap, err)
+	}
+	if !strings.HasPrefix(snap.Ref, RefPrefix+"Toast/") {
+		t.Errorf("Ref = %q, want under %sToast/", snap.Ref, RefPrefix)
+	}
+
+	// The real index must be untouched: new.txt is still untracked.
+	status, _ := gitOut(dir, nil, "status", "--porcelain")
+	if !strings.Contains(status, "?? new.txt") {
+		t.Errorf("index changed by snapshot; status:\n%s", status)
+	}
+	// The checkpoint file itself is not part of the snapshot.
+	if files, _ := gitOut(dir, nil, "ls-tree", "--name-only", snap.Commit); strings.Contains(files, Filename) {
+		t.Errorf("snapshot includes %s: %s", Filename, files)
+	}
+
+	// Lose the work, then restore it.
+	if _, err := gitOut(dir, nil, "checkout", "--", "tracked.txt"); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(dir, "new.txt"))
+
+	got, err := ResolveSnapshot(dir, "Toast", "")
+	if err != nil {
+		t.Fatalf("ResolveSnapshot() error: %v", err)
+	}
+	if got.Ref != snap.Ref || got.Parent != snap.Parent {
+		t.Errorf("ResolveSnapshot() = %+v, want %+v", got, snap)
+	}
+	if err := Restore(dir, got); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if s := readFile(t, dir, "tracked.txt"); s != "v2\n" {
+		t.Errorf("tracked.txt = %q after restore", s)
+	}
+	if s := readFile(t, dir, "new.txt"); s != "untracked\n" {
+		t.Errorf("new.txt = %q after restore", s)
+	}
+}
+
+func TestSnapshotWorktree_Prunes(t *testing.T) {
+	dir := initRepo(t)
+	for i := 0; i < 4; i++ {
+		writeFile(t, dir, "tracked.txt", strings.Repeat("x", i+1))
+		if _, err := SnapshotWorktree(dir, "Toast", 2); err != nil {
+			t.Fatalf("SnapshotWorktree() error: %v", err)
+		}
+	}
+	snaps, err := ListSnapshots(dir, "Toast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 {
+		t.Fatalf("kept %d snapshots, want 2", len(snaps))
+	}
+	if !snaps[0].Time.After(snaps[1].Time) {
+		t.Errorf("snapshots not newest first: %v, %v", snaps[0].Time, snaps[1].Time)
+	}
+}
+
+func TestNameFromPath(t *testing.T) {
+	t.Setenv("GT_POLECAT", "")
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"/town/gastown/polecats/Toast/gastown", "Toast"},
+		{"/town/gastown/polecats/Toast", "Toast"},
+		{"/town/gastown/crew/max", "max"},
+		{"/tmp/work", "work"},
+	}
+	for _, tt := range tests {
+		if got := NameFromPath(tt.dir); got != tt.want {
+			t.Errorf("NameFromPath(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
@@ -133,7 +133,7 @@ func runActivityEmit(cmd *cobra.Command, args []string) error {
 		}
 		payload = events.EscalationPayload(activityRig, activityTarget, activityTo, activityReason)
 
-	case events.TypeMergeStarted, events.TypeMerged, events.TypeMergeFailed, events.TypeMergeSkipped:
+	case events.TypeMergeStarted, events.TypeMerged, events.TypeMergeFailed, events.TypeMergeSkipped, events.TypeMergeReverted:
 		// Refinery events - flexible payload
 		payload = make(map[string]interface{})
 		if activityRig != "" {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
@@ -27,8 +28,13 @@ Checkpoint data includes:
 - Modified files list
 - Git branch and last commit
 - Timestamp
+- A snapshot of the uncommitted changes
 
-Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.`,
+Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.
+The uncommitted changes (including untracked files) are saved as a commit
+under refs/gt/checkpoints/<polecat>/<timestamp> in the rig's repository, so
+they survive the worktree being lost. The last 5 snapshots per polecat are
+kept. The daemon also snapshots long-running polecats periodically.`,
 }
 
 var checkpointWriteCmd = &cobra.Command{
@@ -59,6 +65,42 @@ var checkpointClearCmd = &cobra.Command{
 	RunE:  runCheckpointClear,
 }
 
+var checkpointListCmd = &cobra.Command{
+	Use:   "list [polecat]",
+	Short: "List saved snapshots of uncommitted work",
+	Long: `List the snapshots saved under refs/gt/checkpoints, newest first.
+
+Defaults to the polecat for the current directory. Use --all for every
+polecat in the repository.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCheckpointList,
+}
+
+var checkpointRestoreCmd = &cobra.Command{
+	Use:   "restore [snapshot]",
+	Short: "Re-apply a snapshot of uncommitted work",
+	Long: `Re-apply the uncommitted changes saved in a checkpoint snapshot to the
+current worktree.
+
+The snapshot may be given as a full ref, as its timestamp component, or
+omitted for the latest snapshot of this polecat. Only the working tree is
+changed; the index and HEAD are left alone. The changes apply cleanly when
+HEAD is the commit the snapshot was taken on.
+
+Examples:
+  gt checkpoint restore
+  gt checkpoint restore 20260118T101500.000Z
+  gt checkpoint restore refs/gt/checkpoints/Toast/20260118T101500.000Z
+  gt checkpoint restore --from Toast   # another polecat's latest snapshot`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCheckpointRestore,
+}
+
+var (
+	checkpointListAll     bool
+	checkpointRestoreFrom string
+)
+
 var (
 	checkpointNotes    string
 	checkpointMolecule string
@@ -69,6 +111,13 @@ func init() {
 	checkpointCmd.AddCommand(checkpointWriteCmd)
 	checkpointCmd.AddCommand(checkpointReadCmd)
 	checkpointCmd.AddCommand(checkpointClearCmd)
+	checkpointCmd.AddCommand(checkpointListCmd)
+	checkpointCmd.AddCommand(checkpointRestoreCmd)
+
+	checkpointListCmd.Flags().BoolVar(&checkpointListAll, "all", false,
+		"List snapshots of every polecat")
+	checkpointRestoreCmd.Flags().StringVar(&checkpointRestoreFrom, "from", "",
+		"Polecat whose snapshots to search (default: this one)")
 
 	checkpointWriteCmd.Flags().StringVar(&checkpointNotes, "notes", "",
 		"Add notes to the checkpoint")
@@ -194,6 +243,9 @@ func runCheckpointRead(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  - %s\n", f)
 		}
 	}
+	if cp.SnapshotRef != "" {
+		fmt.Printf("Snapshot: %s\n", cp.SnapshotRef)
+	}
 	if cp.Notes != "" {
 		fmt.Printf("Notes: %s\n", cp.Notes)
 	}
@@ -218,6 +270,71 @@ func runCheckpointClear(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runCheckpointList(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	name := checkpoint.NameFromPath(cwd)
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if checkpointListAll {
+		name = ""
+	}
+
+	snaps, err := checkpoint.ListSnapshots(cwd, name)
+	if err != nil {
+		return fmt.Errorf("listing snapshots: %w", err)
+	}
+	if len(snaps) == 0 {
+		fmt.Printf("%s No checkpoint snapshots\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	for _, s := range snaps {
+		age := ""
+		if !s.Time.IsZero() {
+			age = fmt.Sprintf(" (%s ago)", time.Since(s.Time).Round(time.Second))
+		}
+		base := s.Parent
+		if len(base) > 12 {
+			base = base[:12]
+		}
+		fmt.Printf("%s%s\n", s.Ref, style.Dim.Render(age))
+		fmt.Printf("  on %s\n", base)
+	}
+	return nil
+}
+
+func runCheckpointRestore(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	name := checkpointRestoreFrom
+	if name == "" {
+		name = checkpoint.NameFromPath(cwd)
+	}
+	which := ""
+	if len(args) > 0 {
+		which = args[0]
+	}
+
+	snap, err := checkpoint.ResolveSnapshot(cwd, name, which)
+	if err != nil {
+		return err
+	}
+	if err := checkpoint.Restore(cwd, snap); err != nil {
+		return fmt.Errorf("restoring %s: %w", snap.Ref, err)
+	}
+
+	fmt.Printf("%s Restored uncommitted work from %s\n", style.Bold.Render("✓"), snap.Ref)
+	return nil
+}
+
 // detectMoleculeContext tries to detect the current molecule and step from beads.
 func detectMoleculeContext(workDir string, ctx RoleInfo) (moleculeID, stepID, stepTitle string) {
 	b := beads.New(workDir)
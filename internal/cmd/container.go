@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/container"
+	"github.com/steveyegge/gastown/internal/style"
+	"golang.org/x/term"
+)
+
+var (
+	containerAttachHost  string
+	containerAttachStart bool
+)
+
+var containerCmd = &cobra.Command{
+	Use:     "container",
+	GroupID: GroupServices,
+	Short:   "Inspect containerized polecat sessions",
+	RunE:    requireSubcommand,
+	Long: `Inspect polecats that run inside containers.
+
+With a "container" section in a rig's settings/config.json, each polecat's
+agent runs in its own container (Docker or Podman, via the engine's local
+API socket) built from the configured image. The polecat's worktree and the
+shared beads directories are bind-mounted at their host paths, so the
+project's toolchain comes from the image while work lands in the rig.
+
+The polecat's session runs 'gt container attach', which relays the session
+terminal to the container, so 'gt peek', 'gt nudge' and 'gt session capture'
+work unchanged. Ending the session stops and removes the container.
+
+Example settings:
+  "container": {
+    "image": "ghcr.io/acme/toolchain:1.4",
+    "cpus": 2,
+    "memory": "4g",
+    "mounts": ["~/.claude:/home/agent/.claude"]
+  }`,
+}
+
+var containerListCmd = &cobra.Command{
+	Use:   "list <rig>",
+	Short: "List a rig's polecat containers",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runContainerList,
+}
+
+var containerAttachCmd = &cobra.Command{
+	Use:    "attach <container>",
+	Short:  "Relay the terminal to a container's TTY (internal)",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE:   runContainerAttach,
+}
+
+func init() {
+	containerAttachCmd.Flags().StringVar(&containerAttachHost, "host", "", "Container engine API endpoint")
+	containerAttachCmd.Flags().BoolVar(&containerAttachStart, "start", false, "Start the container after attaching")
+
+	containerCmd.AddCommand(containerListCmd)
+	containerCmd.AddCommand(containerAttachCmd)
+
+	rootCmd.AddCommand(containerCmd)
+}
+
+func runContainerList(cmd *cobra.Command, args []string) error {
+	_, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	cfg, err := container.LoadConfig(r.Path)
+	if err != nil {
+		return err
+	}
+	if !cfg.IsEnabled() {
+		fmt.Printf("%s Rig %s runs polecats on the host (no container image configured)\n", style.Dim.Render("○"), r.Name)
+		return nil
+	}
+
+	c, err := container.NewClient(container.HostFor(cfg))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	containers, err := c.List(ctx, container.LabelRig+"="+r.Name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Image: %s  Engine: %s\n", style.Bold.Render(cfg.Image), c.Host())
+	if len(containers) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("No polecat containers"))
+		return nil
+	}
+	for _, ct := range containers {
+		fmt.Printf("  %-28s %-10s %s\n", ct.Name(), ct.State, style.Dim.Render(ct.Status))
+	}
+	return nil
+}
+
+func runContainerAttach(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	host := containerAttachHost
+	if host == "" {
+		host = container.DefaultHost("")
+	}
+	c, err := container.NewClient(host)
+	if err != nil {
+		return err
+	}
+
+	opts := container.ProxyOptions{
+		Start: containerAttachStart,
+		In:    os.Stdin,
+		Out:   os.Stdout,
+	}
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		// The container's TTY does line editing and echo; ours must not.
+		if state, err := term.MakeRaw(fd); err == nil {
+			defer func() { _ = term.Restore(fd, state) }()
+		}
+		if cols, rows, err := term.GetSize(fd); err == nil {
+			opts.Rows, opts.Cols = rows, cols
+		}
+	}
+
+	// The session ending (kill, hangup) stops the container with it.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		<-sigCh
+		cancel()
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer stopCancel()
+		_ = c.Stop(stopCtx, name, 10*time.Second)
+	}()
+
+	code, err := container.Proxy(ctx, c, name, opts)
+
+	rmCtx, rmCancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer rmCancel()
+	_ = c.Remove(rmCtx, name)
+
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+	if code != 0 && ctx.Err() == nil {
+		return fmt.Errorf("container %s exited with status %d", name, code)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 	"os/exec"
 	"runtime"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/web"
+	"github.com/steveyegge/gastown/internal/webhook"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -27,8 +29,16 @@ The dashboard shows real-time convoy status with:
 - Convoy list with status indicators
 - Progress tracking for each convoy
 - Last activity indicator (green/yellow/red)
+- Refinery merge queue per rig: MR beads in processing order with score,
+  claim/blocked/review state, retries, last failure and time in queue
+  (set merge_queue.github_prs in a rig's settings to also list GitHub PRs)
 - Auto-refresh every 30 seconds via htmx
 
+If settings/webhooks-in.toml exists, the server also accepts signed
+webhooks from CI and forges at /webhooks/<source>: failed default-branch
+builds become P0 beads slung to the rig, review comments are mailed to the
+polecat that owns the branch, and deploys are logged to .events.jsonl.
+
 Example:
   gt dashboard              # Start on default port 8080
   gt dashboard --port 3000  # Start on port 3000
@@ -44,7 +54,8 @@ func init() {
 
 func runDashboard(cmd *cobra.Command, args []string) error {
 	// Verify we're in a workspace
-	if _, err := workspace.FindFromCwdOrError(); err != nil {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
 		return fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
 
@@ -60,6 +71,17 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("creating convoy handler: %w", err)
 	}
 
+	// Mount the webhook receiver alongside the dashboard if configured
+	mux := http.NewServeMux()
+	mux.Handle("/", handler)
+	receiver, err := webhook.ForTown(townRoot, log.Printf)
+	if err != nil {
+		return fmt.Errorf("loading webhook config: %w", err)
+	}
+	if receiver != nil {
+		mux.Handle(webhook.PathPrefix, receiver)
+	}
+
 	// Build the URL
 	url := fmt.Sprintf("http://localhost:%d", dashboardPort)
 
@@ -70,11 +92,14 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 
 	// Start the server with timeouts
 	fmt.Printf("🚚 Gas Town Dashboard starting at %s\n", url)
+	if receiver != nil {
+		fmt.Printf("   Webhooks at %s%s<source>\n", url, webhook.PathPrefix)
+	}
 	fmt.Printf("   Press Ctrl+C to stop\n")
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", dashboardPort),
-		Handler:           handler,
+		Handler:           mux,
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       30 * time.Second,
 		WriteTimeout:      60 * time.Second,
@@ -72,6 +72,7 @@ Routing checks (fixable):
 Session hook checks:
   - session-hooks            Check settings.json use session-start.sh
   - claude-settings          Check Claude settings.json match templates (fixable)
+  - runtime-settings         Check Gemini/Codex agent settings and AGENTS.md (fixable)
 
 Patrol checks:
   - patrol-molecules-exist   Verify patrol molecules exist
@@ -167,6 +168,7 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	d.Register(doctor.NewRuntimeGitignoreCheck())
 	d.Register(doctor.NewLegacyGastownCheck())
 	d.Register(doctor.NewClaudeSettingsCheck())
+	d.Register(doctor.NewRuntimeSettingsCheck())
 
 	// Priming subsystem check
 	d.Register(doctor.NewPrimingCheck())
@@ -14,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/secrets"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/townlog"
@@ -27,7 +28,7 @@ var doneCmd = &cobra.Command{
 	Long: `Signal that your work is complete and ready for the merge queue.
 
 This is a convenience command for polecats that:
-1. Submits the current branch to the merge queue
+1. Scans the branch for secrets, then submits it to the merge queue
 2. Auto-detects issue ID from branch name
 3. Notifies the Witness with the exit outcome
 4. Exits the Claude session (polecats don't stay alive after completion)
@@ -293,6 +294,31 @@ func runDone(cmd *cobra.Command, args []string) error {
 			goto notifyWitness
 		}
 
+		// Initialize beads
+		bd := beads.New(beads.ResolveBeadsDir(cwd))
+
+		// Determine target branch (auto-detect integration branch if applicable)
+		target := defaultBranch
+		if issueID != "" {
+			if autoTarget, err := detectIntegrationBranch(bd, g, issueID); err == nil && autoTarget != "" {
+				target = autoTarget
+			}
+		}
+
+		// Scan for secrets before anything leaves the worktree. A leaked token
+		// on a pushed branch must be rotated even if the MR is never merged.
+		// Like the Refinery's scan, this reads the allowlist from the target
+		// branch on origin, not from this worktree.
+		fmt.Printf("Scanning branch for secrets...\n")
+		findings, err := secrets.ScanRange(cwd, "origin/"+target, "HEAD")
+		if err != nil {
+			// Can't scan (e.g. origin ref missing) - the Refinery scans again before merging
+			style.PrintWarning("could not scan for secrets: %v", err)
+		} else if len(findings) > 0 {
+			return fmt.Errorf("cannot complete: branch adds possible secrets\n%s\nRemove them from the branch history (and rotate them), or allowlist false positives in %s",
+				secrets.Summary(findings, 20), secrets.AllowlistFile)
+		}
+
 		// CRITICAL: Push branch BEFORE creating MR bead (hq-6dk53, hq-a4ksk)
 		// The MR bead triggers Refinery to process this branch. If the branch
 		// isn't pushed yet, Refinery finds nothing to merge. The worktree gets
@@ -307,16 +333,6 @@ func runDone(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("cannot determine source issue from branch '%s'; use --issue to specify", branch)
 		}
 
-		// Initialize beads
-		bd := beads.New(beads.ResolveBeadsDir(cwd))
-
-		// Determine target branch (auto-detect integration branch if applicable)
-		target := defaultBranch
-		autoTarget, err := detectIntegrationBranch(bd, g, issueID)
-		if err == nil && autoTarget != "" {
-			target = autoTarget
-		}
-
 		// Get source issue for priority inheritance
 		var priority int
 		if donePriority >= 0 {
@@ -340,7 +340,7 @@ func stopAllPolecats(t *tmux.Tmux, townRoot string, rigNames []string, force boo
 			continue
 		}
 
-		polecatMgr := polecat.NewSessionManager(t, r)
+		polecatMgr := polecat.NewSessionManager(session.NewBackend(filepath.Dir(r.Path)), r)
 		infos, err := polecatMgr.List()
 		if err != nil {
 			continue
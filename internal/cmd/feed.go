@@ -80,6 +80,7 @@ MQ (Merge Queue) event symbols:
   ✓  merged          - MR successfully merged (green)
   ✗  merge_failed    - Merge failed (conflict, tests, etc.) (red)
   ⊘  merge_skipped   - MR skipped (already merged, etc.)
+  ↺  merge_reverted  - Merge reverted after the post-merge check failed (red)
 
 Examples:
   gt feed                       # Launch TUI dashboard
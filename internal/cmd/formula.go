@@ -10,9 +10,11 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/formula"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 	"golang.org/x/text/cases"
@@ -26,6 +28,8 @@ var (
 	formulaRunPR      int
 	formulaRunRig     string
 	formulaRunDryRun  bool
+	formulaRunVars    []string
+	formulaExpandVars []string
 	formulaCreateType string
 )
 
@@ -44,6 +48,7 @@ for ephemeral patrol cycles.
 Commands:
   list    List available formulas from all search paths
   show    Display formula details (steps, variables, composition)
+  expand  Print a formula with extends/include flattened
   run     Execute a formula (pour and dispatch)
   create  Create a new formula template
 
@@ -55,6 +60,7 @@ Search paths (in order):
 Examples:
   gt formula list                    # List all formulas
   gt formula show shiny              # Show formula details
+  gt formula expand shiny-enterprise # Show the flattened formula
   gt formula run shiny --pr=123      # Run formula on PR #123
   gt formula create my-workflow      # Create new formula template`,
 }
@@ -85,6 +91,8 @@ Shows:
   - Variables with defaults and constraints
   - Steps with dependencies
   - Composition rules (extends, aspects)
+  - A usage block with each variable's type (string, int, bool, enum,
+    path, bead-id, rig), default and whether it is required
 
 Examples:
   gt formula show shiny
@@ -93,6 +101,42 @@ Examples:
 	RunE: runFormulaShow,
 }
 
+var formulaExpandCmd = &cobra.Command{
+	Use:   "expand <name>",
+	Short: "Print a formula with composition flattened",
+	Long: `Print a formula as TOML with extends and [[include]] resolved.
+
+A formula can inherit from one or more parents and splice in steps from
+step libraries:
+
+  extends = "shiny"            # or ["base", "shiny"]; later parents win
+
+  [[include]]
+  formula = "release-checks"   # step library
+  steps = ["changelog", "tag"] # optional subset (default all)
+  needs = ["test"]             # attach the library's root steps here
+
+Steps, legs, templates and aspects are merged by ID: redefining a
+parent's step overrides its title, description or needs. Vars, inputs and
+prompts merge by name. Referenced formulas are looked up next to the
+formula, then in the search paths, then among the built-in formulas.
+Composition cycles are reported as errors.
+
+[compose] rules (aspects, expand) are applied by bd cook and are not shown.
+
+With --var, workflow control flow is also decided for those values, as gt
+sling does before cooking: for_each steps are fanned out, when conditions
+on vars are settled, and conditions on step outputs are written into step
+descriptions as step.* lines for gt mol step done.
+
+Examples:
+  gt formula expand shiny-enterprise
+  gt formula expand review-loop --var targets=api,web
+  gt formula expand my-release > .beads/formulas/my-release-flat.formula.toml`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaExpand,
+}
+
 var formulaRunCmd = &cobra.Command{
 	Use:   "run [name]",
 	Short: "Execute a formula",
@@ -105,11 +149,17 @@ This command:
 
 For PR-based workflows, use --pr to specify the GitHub PR number.
 
+Variables passed with --var are checked against the formula's declared
+vars/inputs: unknown names, wrong types, enum values outside the allowed
+set and pattern mismatches are rejected. Missing required variables are
+prompted for when running in a terminal.
+
 If no formula name is provided, uses the default formula configured in
 the rig's settings/config.json under workflow.default_formula.
 
 Options:
-  --pr=N      Run formula on GitHub PR #N
+  --pr=N      Run formula on GitHub PR #N (sets the pr variable if declared)
+  --var K=V   Formula variable, can be repeated
   --rig=NAME  Target specific rig (default: current or gastown)
   --dry-run   Show what would happen without executing
 
@@ -117,6 +167,7 @@ Examples:
   gt formula run shiny                    # Run formula in current rig
   gt formula run                          # Run default formula from rig config
   gt formula run shiny --pr=123           # Run on PR #123
+  gt formula run design --var problem="Add caching"
   gt formula run security-audit --rig=beads  # Run in specific rig
   gt formula run release --dry-run        # Preview execution`,
 	Args: cobra.MaximumNArgs(1),
@@ -151,10 +202,14 @@ func init() {
 	// Show flags
 	formulaShowCmd.Flags().BoolVar(&formulaShowJSON, "json", false, "Output as JSON")
 
+	// Expand flags
+	formulaExpandCmd.Flags().StringArrayVar(&formulaExpandVars, "var", nil, "Expand control flow for this variable (key=value), can be repeated")
+
 	// Run flags
 	formulaRunCmd.Flags().IntVar(&formulaRunPR, "pr", 0, "GitHub PR number to run formula on")
 	formulaRunCmd.Flags().StringVar(&formulaRunRig, "rig", "", "Target rig (default: current or gastown)")
 	formulaRunCmd.Flags().BoolVar(&formulaRunDryRun, "dry-run", false, "Preview execution without running")
+	formulaRunCmd.Flags().StringArrayVar(&formulaRunVars, "var", nil, "Formula variable (key=value), can be repeated")
 
 	// Create flags
 	formulaCreateCmd.Flags().StringVar(&formulaCreateType, "type", "task", "Formula type: task, workflow, or patrol")
@@ -162,6 +217,7 @@ func init() {
 	// Add subcommands
 	formulaCmd.AddCommand(formulaListCmd)
 	formulaCmd.AddCommand(formulaShowCmd)
+	formulaCmd.AddCommand(formulaExpandCmd)
 	formulaCmd.AddCommand(formulaRunCmd)
 	formulaCmd.AddCommand(formulaCreateCmd)
 
@@ -192,7 +248,48 @@ func runFormulaShow(cmd *cobra.Command, args []string) error {
 	bdCmd := exec.Command("bd", bdArgs...)
 	bdCmd.Stdout = os.Stdout
 	bdCmd.Stderr = os.Stderr
-	return bdCmd.Run()
+	if err := bdCmd.Run(); err != nil {
+		return err
+	}
+
+	// bd doesn't know gt's variable types; add our own usage block
+	if !formulaShowJSON {
+		if f := findTypedFormula(formulaName); f != nil {
+			fmt.Println()
+			printFormulaUsage(formulaName, f)
+		}
+	}
+	return nil
+}
+
+// runFormulaExpand prints a formula with extends/include resolved.
+func runFormulaExpand(cmd *cobra.Command, args []string) error {
+	formulaName := args[0]
+	path, err := findFormulaFile(formulaName)
+	if err != nil {
+		if path, err = findFormulaFile("mol-" + formulaName); err != nil {
+			return fmt.Errorf("formula '%s' not found in search paths", formulaName)
+		}
+	}
+
+	f, err := parseTypedFormulaFile(path)
+	if err != nil {
+		return fmt.Errorf("expanding %s: %w", formulaName, err)
+	}
+
+	if len(formulaExpandVars) > 0 && f.Type == formula.TypeWorkflow {
+		vars, err := resolveFormulaVars(f, formulaExpandVars)
+		if err != nil {
+			return err
+		}
+		if f, err = expandFormulaForVars(f, vars); err != nil {
+			return fmt.Errorf("expanding %s: %w", formulaName, err)
+		}
+	}
+
+	enc := toml.NewEncoder(os.Stdout)
+	enc.Indent = ""
+	return enc.Encode(f)
 }
 
 // runFormulaRun executes a formula by spawning a convoy of polecats.
@@ -251,15 +348,30 @@ func runFormulaRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("finding formula: %w", err)
 	}
 
-	// Parse the formula
-	f, err := parseFormulaFile(formulaPath)
-	if err != nil {
+	// Parse the formula. The formula package resolves extends/include;
+	// fall back to the lightweight parser for formulas it can't handle.
+	var f *formulaData
+	typed := loadTypedFormulaFile(formulaPath)
+	if typed != nil {
+		f = formulaDataFromTyped(typed)
+	} else if f, err = parseFormulaFile(formulaPath); err != nil {
 		return fmt.Errorf("parsing formula: %w", err)
 	}
 
+	// Validate variables against the formula's declarations. --pr feeds
+	// the pr variable for formulas that declare one; an explicit --var wins.
+	vars := formulaRunVars
+	if formulaRunPR > 0 && typed != nil && typed.GetParam("pr") != nil {
+		vars = append([]string{fmt.Sprintf("pr=%d", formulaRunPR)}, vars...)
+	}
+	vars, err = resolveFormulaVars(typed, vars)
+	if err != nil {
+		return err
+	}
+
 	// Handle dry-run mode
 	if formulaRunDryRun {
-		return dryRunFormula(f, formulaName, targetRig)
+		return dryRunFormula(f, formulaName, targetRig, vars)
 	}
 
 	// Currently only convoy formulas are supported for execution
@@ -270,17 +382,26 @@ func runFormulaRun(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\nTo run '%s' manually:\n", formulaName)
 		fmt.Printf("  1. View formula:   gt formula show %s\n", formulaName)
 		fmt.Printf("  2. Cook to proto:  bd cook %s\n", formulaName)
-		fmt.Printf("  3. Pour molecule:  bd pour %s\n", formulaName)
+		fmt.Printf("  3. Pour molecule:  bd pour %s%s\n", formulaName, formatVarFlags(vars))
 		fmt.Printf("  4. Sling to rig:   gt sling <mol-id> %s\n", targetRig)
 		return nil
 	}
 
 	// Execute convoy formula
-	return executeConvoyFormula(f, formulaName, targetRig)
+	return executeConvoyFormula(f, formulaName, targetRig, vars)
+}
+
+// formatVarFlags renders variables as " --var k=v" flags for display.
+func formatVarFlags(vars []string) string {
+	var b strings.Builder
+	for _, v := range vars {
+		b.WriteString(" --var " + v)
+	}
+	return b.String()
 }
 
 // dryRunFormula shows what would happen without executing
-func dryRunFormula(f *formulaData, formulaName, targetRig string) error {
+func dryRunFormula(f *formulaData, formulaName, targetRig string, vars []string) error {
 	fmt.Printf("%s Would execute formula:\n", style.Dim.Render("[dry-run]"))
 	fmt.Printf("  Formula: %s\n", style.Bold.Render(formulaName))
 	fmt.Printf("  Type:    %s\n", f.Type)
@@ -288,6 +409,9 @@ func dryRunFormula(f *formulaData, formulaName, targetRig string) error {
 	if formulaRunPR > 0 {
 		fmt.Printf("  PR:      #%d\n", formulaRunPR)
 	}
+	for _, v := range vars {
+		fmt.Printf("  Var:     %s\n", v)
+	}
 
 	if f.Type == "convoy" && len(f.Legs) > 0 {
 		fmt.Printf("\n  Legs (%d parallel):\n", len(f.Legs))
@@ -304,7 +428,7 @@ func dryRunFormula(f *formulaData, formulaName, targetRig string) error {
 }
 
 // executeConvoyFormula spawns a convoy of polecats to execute a convoy formula
-func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
+func executeConvoyFormula(f *formulaData, formulaName, targetRig string, vars []string) error {
 	fmt.Printf("%s Executing convoy formula: %s\n\n",
 		style.Bold.Render("🚚"), formulaName)
 
@@ -328,6 +452,9 @@ func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
 	if formulaRunPR > 0 {
 		description += fmt.Sprintf("\nPR: #%d", formulaRunPR)
 	}
+	for _, v := range vars {
+		description += "\nVar: " + v
+	}
 
 	createArgs := []string{
 		"create",
@@ -515,9 +642,53 @@ type formulaSynthesis struct {
 	DependsOn   []string
 }
 
+// formulaDataFromTyped converts a fully parsed formula into formulaData.
+func formulaDataFromTyped(t *formula.Formula) *formulaData {
+	f := &formulaData{
+		Name:        t.Name,
+		Description: t.Description,
+		Type:        string(t.Type),
+		Prompts:     t.Prompts,
+	}
+	if f.Prompts == nil {
+		f.Prompts = make(map[string]string)
+	}
+	for _, leg := range t.Legs {
+		f.Legs = append(f.Legs, formulaLeg{
+			ID:          leg.ID,
+			Title:       leg.Title,
+			Focus:       leg.Focus,
+			Description: leg.Description,
+		})
+	}
+	if t.Synthesis != nil {
+		f.Synthesis = &formulaSynthesis{
+			Title:       t.Synthesis.Title,
+			Description: t.Synthesis.Description,
+			DependsOn:   t.Synthesis.DependsOn,
+		}
+	}
+	return f
+}
+
 // findFormulaFile searches for a formula file by name
 func findFormulaFile(name string) (string, error) {
-	// Search paths in order
+	// Try each path with common extensions
+	extensions := []string{".formula.toml", ".formula.json"}
+	for _, basePath := range formulaSearchPaths() {
+		for _, ext := range extensions {
+			path := filepath.Join(basePath, name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("formula '%s' not found in search paths", name)
+}
+
+// formulaSearchPaths returns the formula directories in lookup order.
+func formulaSearchPaths() []string {
 	searchPaths := []string{}
 
 	// 1. Project .beads/formulas/
@@ -535,18 +706,7 @@ func findFormulaFile(name string) (string, error) {
 		searchPaths = append(searchPaths, filepath.Join(home, ".beads", "formulas"))
 	}
 
-	// Try each path with common extensions
-	extensions := []string{".formula.toml", ".formula.json"}
-	for _, basePath := range searchPaths {
-		for _, ext := range extensions {
-			path := filepath.Join(basePath, name+ext)
-			if _, err := os.Stat(path); err == nil {
-				return path, nil
-			}
-		}
-	}
-
-	return "", fmt.Errorf("formula '%s' not found in search paths", name)
+	return searchPaths
 }
 
 // parseFormulaFile parses a formula file into formulaData
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+// expandFormulaForVars decides a formula's when/for_each for vars and embeds
+// the remaining when/until conditions into step descriptions, so bd can cook
+// it as a plain DAG and gt mol step done can honour the rest at run time.
+func expandFormulaForVars(f *formula.Formula, vars []string) (*formula.Formula, error) {
+	values, err := parseFormulaVarFlags(vars)
+	if err != nil {
+		return nil, err
+	}
+	expanded, err := f.Expand(values)
+	if err != nil {
+		return nil, err
+	}
+	expanded.EmbedControl()
+	return expanded, nil
+}
+
+// prepareControlFormula returns the formula name to cook for a sling. For
+// formulas that use control flow it writes the expansion for vars to the
+// town's formula directory under a content-addressed name
+// (<name>-x<hash>) and returns that name; otherwise it returns name.
+// With write false the name is computed but nothing is written.
+func prepareControlFormula(townRoot, name string, f *formula.Formula, vars []string, write bool) (string, error) {
+	if f == nil || !f.HasControlFlow() {
+		return name, nil
+	}
+	expanded, err := expandFormulaForVars(f, vars)
+	if err != nil {
+		return "", fmt.Errorf("expanding formula %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(expanded); err != nil {
+		return "", fmt.Errorf("encoding expanded formula: %w", err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	expandedName := fmt.Sprintf("%s-x%s", expanded.Name, hex.EncodeToString(sum[:4]))
+
+	if !write {
+		return expandedName, nil
+	}
+
+	expanded.Name = expandedName
+	buf.Reset()
+	if err := enc.Encode(expanded); err != nil {
+		return "", fmt.Errorf("encoding expanded formula: %w", err)
+	}
+	dir := filepath.Join(townRoot, ".beads", "formulas")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating formulas directory: %w", err)
+	}
+	path := filepath.Join(dir, expandedName+".formula.toml")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil { //nolint:gosec // G306: formulas are shared, not secret
+		return "", fmt.Errorf("writing expanded formula: %w", err)
+	}
+	return expandedName, nil
+}
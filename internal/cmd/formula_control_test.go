@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+func TestPrepareControlFormula(t *testing.T) {
+	f, err := formula.Parse([]byte(`
+formula = "review-loop"
+
+[vars.targets]
+required = true
+
+[[steps]]
+id = "review"
+retry = 2
+until = "steps.review.clean"
+
+[[steps]]
+id = "test"
+needs = ["review"]
+for_each = "targets"
+title = "Test {{item}}"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	townRoot := t.TempDir()
+
+	// Dry run computes the name without writing
+	name, err := prepareControlFormula(townRoot, "review-loop", f, []string{"targets=api,web"}, false)
+	if err != nil || !strings.HasPrefix(name, "review-loop-x") {
+		t.Fatalf("prepareControlFormula = %q, %v", name, err)
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, ".beads", "formulas")); !os.IsNotExist(err) {
+		t.Error("dry run wrote the formulas directory")
+	}
+
+	written, err := prepareControlFormula(townRoot, "review-loop", f, []string{"targets=api,web"}, true)
+	if err != nil || written != name {
+		t.Fatalf("prepareControlFormula(write) = %q, %v; want %q", written, err, name)
+	}
+	cooked, err := formula.ParseFile(filepath.Join(townRoot, ".beads", "formulas", name+".formula.toml"))
+	if err != nil {
+		t.Fatalf("expanded formula does not parse: %v", err)
+	}
+	if cooked.Name != name || cooked.HasControlFlow() || cooked.GetStep("test-2") == nil {
+		t.Errorf("expanded formula = %+v", cooked)
+	}
+	if ctrl := formula.ParseStepControl(cooked.GetStep("review").Description); ctrl.Until == "" || ctrl.Retry != 2 {
+		t.Errorf("review control = %+v", ctrl)
+	}
+
+	// Different vars give a different expansion
+	other, err := prepareControlFormula(townRoot, "review-loop", f, []string{"targets=api"}, false)
+	if err != nil || other == name {
+		t.Errorf("prepareControlFormula(other vars) = %q, %v", other, err)
+	}
+
+	// Formulas without control flow are cooked as-is
+	if name, err := prepareControlFormula(townRoot, "plain", nil, nil, true); err != nil || name != "plain" {
+		t.Errorf("prepareControlFormula(nil) = %q, %v", name, err)
+	}
+}
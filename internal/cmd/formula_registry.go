@@ -0,0 +1,514 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Formula registry command flags
+var (
+	formulaScopeRig      string
+	formulaScopeForce    bool
+	formulaInstallOnly   []string
+	formulaInstallSHA256 string
+	formulaInstallSigned bool
+	formulaOutdatedJSON  bool
+)
+
+var formulaInstallCmd = &cobra.Command{
+	Use:   "install <git-url|path>[@version]",
+	Short: "Install formulas from a git repository or path",
+	Long: `Install formulas from a git repository or local path.
+
+The source is searched for *.formula.toml at its root, in formulas/ and in
+.beads/formulas/; a path may also name a single .formula.toml file. Every
+formula found is installed unless --formula selects some. Each formula must
+parse before anything is written.
+
+Versions:
+  <url>              Latest release tag (vX.Y.Z), or the default branch if untagged
+  <url>@v1.2.0       Pinned to a tag or commit; 'gt formula update' leaves it alone
+  <url>@main         Follows the branch on 'gt formula update'
+
+Installed formulas are recorded in .beads/formulas/.lock.json with their
+source, version, commit and sha256. Installing the same version again with
+different content fails (the tag was moved), and files edited locally or
+owned by another source are not replaced without --force.
+
+Scope is the town (<town>/.beads/formulas) unless --rig installs into
+<town>/<rig>/.beads/formulas, which takes precedence for that rig.
+
+Verification:
+  --sha256 <hash>       Require the formula to have this content hash
+  --require-signature   Require a valid GPG/SSH signature on the tag or commit
+                        (git verify-tag / verify-commit); updates keep requiring it
+
+Examples:
+  gt formula install https://github.com/acme/gt-formulas
+  gt formula install git@github.com:acme/gt-formulas@v1.4.0 --formula release
+  gt formula install ../shared/formulas --rig gastown
+  gt formula install ./review.formula.toml --sha256 3f2a...`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaInstall,
+}
+
+var formulaUpdateCmd = &cobra.Command{
+	Use:   "update [name[@version]...]",
+	Short: "Update formulas installed from sources",
+	Long: `Update formulas installed with 'gt formula install' from their sources.
+
+With no arguments every installed formula that isn't pinned is updated to
+its source's latest release tag (or branch head). name@version moves a
+formula to that version, pinned or not. Signed installs are verified again.
+
+Examples:
+  gt formula update
+  gt formula update release@v1.5.0
+  gt formula update --rig gastown`,
+	RunE: runFormulaUpdate,
+}
+
+var formulaRemoveCmd = &cobra.Command{
+	Use:     "remove <name>...",
+	Aliases: []string{"uninstall"},
+	Short:   "Remove formulas installed from sources",
+	Long: `Remove formulas installed with 'gt formula install' and drop them from
+the lockfile. Formulas edited since they were installed are kept unless
+--force is given.
+
+Examples:
+  gt formula remove release
+  gt formula remove review --rig gastown`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFormulaRemove,
+}
+
+var formulaOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "List installed formulas with newer versions or local changes",
+	Long: `Check each formula in the lockfile against its source and report those
+with a newer version, plus any edited or deleted locally.
+
+Examples:
+  gt formula outdated
+  gt formula outdated --rig gastown --json`,
+	Args: cobra.NoArgs,
+	RunE: runFormulaOutdated,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{formulaInstallCmd, formulaUpdateCmd, formulaRemoveCmd, formulaOutdatedCmd} {
+		c.Flags().StringVar(&formulaScopeRig, "rig", "", "Use the rig's formulas instead of the town's")
+	}
+	for _, c := range []*cobra.Command{formulaInstallCmd, formulaUpdateCmd, formulaRemoveCmd} {
+		c.Flags().BoolVar(&formulaScopeForce, "force", false, "Replace or remove formulas edited locally or owned by another source")
+	}
+	formulaInstallCmd.Flags().StringArrayVar(&formulaInstallOnly, "formula", nil, "Install only this formula from the source (repeatable)")
+	formulaInstallCmd.Flags().StringVar(&formulaInstallSHA256, "sha256", "", "Expected sha256 of the formula content")
+	formulaInstallCmd.Flags().BoolVar(&formulaInstallSigned, "require-signature", false, "Require a verified signature on the git tag or commit")
+	formulaOutdatedCmd.Flags().BoolVar(&formulaOutdatedJSON, "json", false, "Output as JSON")
+
+	formulaCmd.AddCommand(formulaInstallCmd)
+	formulaCmd.AddCommand(formulaUpdateCmd)
+	formulaCmd.AddCommand(formulaRemoveCmd)
+	formulaCmd.AddCommand(formulaOutdatedCmd)
+}
+
+// formulaScopeDir returns the formulas directory for the town, or for a rig
+// when rigName is set.
+func formulaScopeDir(rigName string) (string, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if rigName == "" {
+		return filepath.Join(townRoot, ".beads", "formulas"), nil
+	}
+	if _, ok := IsRigName(rigName); !ok {
+		return "", fmt.Errorf("rig '%s' not found", rigName)
+	}
+	return filepath.Join(townRoot, rigName, ".beads", "formulas"), nil
+}
+
+// fetchedSource is a formula source available on local disk.
+type fetchedSource struct {
+	Dir     string            // directory (or file) holding the formulas
+	Entry   formula.LockEntry // source fields for the lockfile
+	cleanup func()
+}
+
+// shortCommit abbreviates a commit hash for display and lock versions.
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// fetchFormulaSource makes a source available locally. Plain paths are read
+// in place. Git URLs, and paths with a version, are cloned to a temporary
+// directory and checked out at the version: the requested ref, else the
+// latest release tag, else the default branch.
+func fetchFormulaSource(src formula.Source, requireSignature bool) (*fetchedSource, error) {
+	location := src.Location
+	if !src.IsGit() {
+		abs, err := filepath.Abs(location)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return nil, fmt.Errorf("source %s: %w", location, err)
+		}
+		location = abs
+		if src.Version == "" {
+			if requireSignature {
+				return nil, fmt.Errorf("signature verification needs a git source (use %s@<version>)", location)
+			}
+			return &fetchedSource{Dir: abs, Entry: formula.LockEntry{Source: abs}, cleanup: func() {}}, nil
+		}
+	}
+
+	tmp, err := os.MkdirTemp("", "gt-formula-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(tmp) }
+	fail := func(err error) (*fetchedSource, error) {
+		cleanup()
+		return nil, err
+	}
+
+	dir := filepath.Join(tmp, "src")
+	if err := git.NewGit("").Clone(location, dir); err != nil {
+		return fail(fmt.Errorf("cloning %s: %w", location, err))
+	}
+	g := git.NewGit(dir)
+
+	ref := src.Version
+	if ref == "" {
+		tags, err := g.LsRemoteTags(dir)
+		if err != nil {
+			return fail(fmt.Errorf("listing tags: %w", err))
+		}
+		ref = formula.LatestVersion(tags)
+	}
+	if ref != "" {
+		if err := g.Checkout(ref); err != nil {
+			return fail(fmt.Errorf("checking out %s: %w", ref, err))
+		}
+	}
+	commit, err := g.Rev("HEAD")
+	if err != nil {
+		return fail(err)
+	}
+
+	entry := formula.LockEntry{Source: location, Ref: src.Version, Version: shortCommit(commit), Commit: commit}
+	isTag := ref != "" && g.IsTag(ref)
+	if isTag {
+		entry.Version = ref
+	}
+	if requireSignature {
+		if isTag {
+			err = g.VerifyTag(ref)
+		} else {
+			err = g.VerifyCommit("HEAD")
+		}
+		if err != nil {
+			return fail(fmt.Errorf("signature verification failed for %s@%s: %w", location, entry.Version, err))
+		}
+		entry.Signed = true
+	}
+	return &fetchedSource{Dir: dir, Entry: entry, cleanup: cleanup}, nil
+}
+
+// formatLockVersion describes a lock entry's version for display.
+func formatLockVersion(e formula.LockEntry) string {
+	if e.Version == "" {
+		return "local"
+	}
+	return e.Version
+}
+
+func runFormulaInstall(cmd *cobra.Command, args []string) error {
+	dir, err := formulaScopeDir(formulaScopeRig)
+	if err != nil {
+		return err
+	}
+	src := formula.ParseSource(args[0])
+
+	fetched, err := fetchFormulaSource(src, formulaInstallSigned)
+	if err != nil {
+		return err
+	}
+	defer fetched.cleanup()
+
+	names, err := formula.InstallFromDir(dir, fetched.Dir, fetched.Entry, formula.InstallOptions{
+		Only:     formulaInstallOnly,
+		Checksum: formulaInstallSHA256,
+		Force:    formulaScopeForce,
+	})
+	if err != nil {
+		return err
+	}
+
+	lock, err := formula.LoadLockFile(dir)
+	if err != nil {
+		return err
+	}
+	verified := "checksum recorded"
+	if fetched.Entry.Signed {
+		verified = "signature verified"
+	}
+	fmt.Printf("%s Installed %d formula(s) from %s (%s, %s)\n",
+		style.Bold.Render("✓"), len(names), fetched.Entry.Source, formatLockVersion(fetched.Entry), verified)
+	for _, name := range names {
+		fmt.Printf("  %s  %s\n", name, style.Dim.Render(lock.Formulas[name].Checksum[:19]))
+	}
+	fmt.Printf("\nInto: %s\n", dir)
+	return nil
+}
+
+func runFormulaUpdate(cmd *cobra.Command, args []string) error {
+	dir, err := formulaScopeDir(formulaScopeRig)
+	if err != nil {
+		return err
+	}
+	lock, err := formula.LoadLockFile(dir)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		args = lock.Names()
+		if len(args) == 0 {
+			fmt.Println("No formulas installed from sources.")
+			return nil
+		}
+	}
+
+	// Group formulas by source and ref so each source is fetched once
+	type group struct {
+		src    formula.Source
+		signed bool
+		names  []string
+	}
+	groups := make(map[string]*group)
+	for _, arg := range args {
+		name, version, _ := strings.Cut(arg, "@")
+		entry, ok := lock.Formulas[name]
+		if !ok {
+			return fmt.Errorf("%s was not installed with gt formula install", name)
+		}
+		if version == "" {
+			if entry.Pinned() {
+				fmt.Printf("%s %s pinned at %s (update with %s@<version>)\n",
+					style.Dim.Render("○"), name, entry.Version, name)
+				continue
+			}
+			version = entry.Ref
+		}
+		key := entry.Source + "@" + version
+		if groups[key] == nil {
+			groups[key] = &group{src: formula.Source{Location: entry.Source, Version: version}}
+		}
+		groups[key].names = append(groups[key].names, name)
+		groups[key].signed = groups[key].signed || entry.Signed
+	}
+
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var failed []string
+	for _, key := range keys {
+		g := groups[key]
+		if err := updateFormulaGroup(dir, lock, g.src, g.names, g.signed); err != nil {
+			style.PrintWarning("%s: %v", g.src, err)
+			failed = append(failed, g.names...)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to update: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// updateFormulaGroup refetches one source and reinstalls the named formulas.
+func updateFormulaGroup(dir string, lock *formula.LockFile, src formula.Source, names []string, signed bool) error {
+	fetched, err := fetchFormulaSource(src, signed)
+	if err != nil {
+		return err
+	}
+	defer fetched.cleanup()
+
+	if _, err := formula.InstallFromDir(dir, fetched.Dir, fetched.Entry, formula.InstallOptions{
+		Only:  names,
+		Force: formulaScopeForce,
+	}); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		before := lock.Formulas[name]
+		after := fetched.Entry
+		switch {
+		case before.Version != "" && before.Version == after.Version:
+			fmt.Printf("%s %s up to date (%s)\n", style.Dim.Render("○"), name, formatLockVersion(after))
+		default:
+			fmt.Printf("%s %s %s → %s\n", style.Bold.Render("✓"), name, formatLockVersion(before), formatLockVersion(after))
+		}
+	}
+	return nil
+}
+
+func runFormulaRemove(cmd *cobra.Command, args []string) error {
+	dir, err := formulaScopeDir(formulaScopeRig)
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if err := formula.RemoveInstalled(dir, name, formulaScopeForce); err != nil {
+			return err
+		}
+		fmt.Printf("%s Removed %s\n", style.Bold.Render("✓"), name)
+	}
+	return nil
+}
+
+// OutdatedFormula is a row of gt formula outdated.
+type OutdatedFormula struct {
+	Name      string `json:"name"`
+	Source    string `json:"source"`
+	Installed string `json:"installed"`
+	Latest    string `json:"latest,omitempty"`
+	Pinned    bool   `json:"pinned,omitempty"`
+	Status    string `json:"status"` // "ok", "outdated", "modified", "missing"
+	Error     string `json:"error,omitempty"`
+}
+
+func runFormulaOutdated(cmd *cobra.Command, args []string) error {
+	dir, err := formulaScopeDir(formulaScopeRig)
+	if err != nil {
+		return err
+	}
+	lock, err := formula.LoadLockFile(dir)
+	if err != nil {
+		return err
+	}
+
+	var rows []OutdatedFormula
+	for _, name := range lock.Names() {
+		rows = append(rows, checkOutdated(dir, name, lock.Formulas[name]))
+	}
+
+	if formulaOutdatedJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+
+	var shown []OutdatedFormula
+	for _, r := range rows {
+		if r.Status != "ok" || r.Error != "" {
+			shown = append(shown, r)
+		}
+	}
+	if len(shown) == 0 {
+		fmt.Printf("%s All %d installed formula(s) are up to date\n", style.Bold.Render("✓"), len(rows))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tINSTALLED\tLATEST\tSTATUS\tSOURCE")
+	for _, r := range shown {
+		status := r.Status
+		if r.Pinned {
+			status += " (pinned)"
+		}
+		if r.Error != "" {
+			status = "error: " + r.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Name, r.Installed, r.Latest, status, r.Source)
+	}
+	return w.Flush()
+}
+
+// checkOutdated compares an installed formula with its file on disk and
+// with its source.
+func checkOutdated(dir, name string, entry formula.LockEntry) OutdatedFormula {
+	row := OutdatedFormula{
+		Name:      name,
+		Source:    entry.Source,
+		Installed: formatLockVersion(entry),
+		Pinned:    entry.Pinned(),
+		Status:    formula.LockedStatus(dir, name, entry),
+	}
+	latest, changed, err := latestFormulaVersion(entry)
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Latest = latest
+	if changed && row.Status == "ok" {
+		row.Status = "outdated"
+	}
+	return row
+}
+
+// latestFormulaVersion returns the newest version available from an entry's
+// source and whether it differs from the installed one. Tag-based installs
+// compare release tags; branch and untagged installs compare commits; path
+// installs compare the source file's checksum.
+func latestFormulaVersion(entry formula.LockEntry) (string, bool, error) {
+	if entry.Commit == "" {
+		path := entry.Source
+		if entry.Path != "" {
+			path = filepath.Join(entry.Source, filepath.FromSlash(entry.Path))
+		}
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is from the lockfile
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, fmt.Errorf("source file %s is gone", path)
+		}
+		if err != nil {
+			return "", false, err
+		}
+		changed := formula.ContentChecksum(data) != entry.Checksum
+		if changed {
+			return "local (changed)", true, nil
+		}
+		return "local", false, nil
+	}
+
+	g := git.NewGit("")
+	tags, err := g.LsRemoteTags(entry.Source)
+	if err != nil {
+		return "", false, fmt.Errorf("listing tags: %w", err)
+	}
+	isTagged := entry.Version != shortCommit(entry.Commit)
+	if latest := formula.LatestVersion(tags); latest != "" && (isTagged || entry.Ref == "") {
+		return latest, formula.CompareVersions(latest, entry.Version) > 0 || !isTagged, nil
+	}
+
+	ref := entry.Ref
+	if ref == "" || entry.Pinned() {
+		ref = "HEAD"
+	}
+	head, err := g.LsRemoteRef(entry.Source, ref)
+	if err != nil {
+		return "", false, err
+	}
+	return shortCommit(head), head != entry.Commit, nil
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+// setupFormulaSourceRepo creates a git repository with review.formula.toml
+// tagged v1.0.0 and v1.1.0 and an untagged commit on top.
+func setupFormulaSourceRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(title string) {
+		t.Helper()
+		body := "formula = \"review\"\n[[steps]]\nid = \"review\"\ntitle = \"" + title + "\"\n"
+		if err := os.WriteFile(filepath.Join(repo, "formulas", "review.formula.toml"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(repo, "formulas"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	run("init", "-q", "-b", "main")
+	for _, v := range []string{"v1.0.0", "v1.1.0", "unreleased"} {
+		write("Review " + v)
+		run("add", "-A")
+		run("commit", "-q", "-m", v)
+		if v != "unreleased" {
+			run("tag", v)
+		}
+	}
+	return repo
+}
+
+func resetFormulaRegistryFlags() {
+	formulaScopeRig = ""
+	formulaScopeForce = false
+	formulaInstallOnly = nil
+	formulaInstallSHA256 = ""
+	formulaInstallSigned = false
+	formulaOutdatedJSON = false
+}
+
+func TestFormulaInstallUpdateOutdated(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := setupFormulaSourceRepo(t)
+	townRoot := setupTestTownForCrewList(t, map[string][]string{"gastown": nil})
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	if err := os.Chdir(townRoot); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer resetFormulaRegistryFlags()
+
+	townDir := filepath.Join(townRoot, ".beads", "formulas")
+	rigDir := filepath.Join(townRoot, "gastown", ".beads", "formulas")
+	installedTitle := func(dir string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, "review.formula.toml"))
+		if err != nil {
+			t.Fatalf("reading installed formula: %v", err)
+		}
+		f, err := formula.Parse(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f.Steps[0].Title
+	}
+
+	// Pinned install into the town
+	captureStdout(t, func() {
+		if err := runFormulaInstall(&cobra.Command{}, []string{repo + "@v1.0.0"}); err != nil {
+			t.Fatalf("install: %v", err)
+		}
+	})
+	if got := installedTitle(townDir); got != "Review v1.0.0" {
+		t.Errorf("installed title = %q", got)
+	}
+
+	// Unpinned git install into a rig picks the latest release tag
+	formulaScopeRig = "gastown"
+	captureStdout(t, func() {
+		if err := runFormulaInstall(&cobra.Command{}, []string{"file://" + repo}); err != nil {
+			t.Fatalf("rig install: %v", err)
+		}
+	})
+	if got := installedTitle(rigDir); got != "Review v1.1.0" {
+		t.Errorf("rig installed title = %q", got)
+	}
+	formulaScopeRig = ""
+
+	// The town copy is pinned: outdated reports it, update leaves it
+	formulaOutdatedJSON = true
+	out := captureStdout(t, func() {
+		if err := runFormulaOutdated(&cobra.Command{}, nil); err != nil {
+			t.Fatalf("outdated: %v", err)
+		}
+	})
+	var rows []OutdatedFormula
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("parsing outdated JSON: %v\n%s", err, out)
+	}
+	if len(rows) != 1 || rows[0].Status != "outdated" || rows[0].Latest != "v1.1.0" || !rows[0].Pinned {
+		t.Errorf("outdated = %+v", rows)
+	}
+
+	captureStdout(t, func() {
+		if err := runFormulaUpdate(&cobra.Command{}, nil); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	})
+	if got := installedTitle(townDir); got != "Review v1.0.0" {
+		t.Errorf("pinned formula updated to %q", got)
+	}
+
+	// Moving explicitly to a branch follows its head
+	captureStdout(t, func() {
+		if err := runFormulaUpdate(&cobra.Command{}, []string{"review@main"}); err != nil {
+			t.Fatalf("update to main: %v", err)
+		}
+	})
+	if got := installedTitle(townDir); got != "Review unreleased" {
+		t.Errorf("title after update to main = %q", got)
+	}
+	lock, err := formula.LoadLockFile(townDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := lock.Formulas["review"]; e.Ref != "main" || e.Pinned() || len(e.Version) != 12 {
+		t.Errorf("lock entry after update to main = %+v", e)
+	}
+
+	// Local edits block removal without --force
+	if err := os.WriteFile(filepath.Join(townDir, "review.formula.toml"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runFormulaRemove(&cobra.Command{}, []string{"review"}); err == nil {
+		t.Error("removed an edited formula without --force")
+	}
+	formulaScopeForce = true
+	captureStdout(t, func() {
+		if err := runFormulaRemove(&cobra.Command{}, []string{"review"}); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+	})
+	if _, err := os.Stat(filepath.Join(townDir, "review.formula.toml")); !os.IsNotExist(err) {
+		t.Error("formula still installed after remove")
+	}
+	if _, err := os.Stat(filepath.Join(rigDir, "review.formula.toml")); err != nil {
+		t.Error("removing from the town touched the rig's formula")
+	}
+}
+
+func TestFormulaInstall_VerificationErrors(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := setupFormulaSourceRepo(t)
+	townRoot := setupTestTownForCrewList(t, nil)
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	if err := os.Chdir(townRoot); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer resetFormulaRegistryFlags()
+
+	// Test tags aren't signed
+	formulaInstallSigned = true
+	err := runFormulaInstall(&cobra.Command{}, []string{repo + "@v1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "signature verification failed") {
+		t.Errorf("unsigned tag error = %v", err)
+	}
+	formulaInstallSigned = false
+
+	formulaInstallSHA256 = "sha256:0000"
+	err = runFormulaInstall(&cobra.Command{}, []string{repo + "@v1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("checksum error = %v", err)
+	}
+	formulaInstallSHA256 = ""
+
+	formulaScopeRig = "nope"
+	if err := runFormulaInstall(&cobra.Command{}, []string{repo}); err == nil {
+		t.Error("expected error for unknown rig")
+	}
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+	"golang.org/x/term"
+)
+
+// findTypedFormula locates a formula by name (trying the mol- prefix too) and
+// parses it with the formula package. Returns nil if the formula isn't in a
+// local search path or can't be parsed; bd remains the source of truth for
+// those, so callers skip typed validation rather than fail.
+func findTypedFormula(name string) *formula.Formula {
+	path, err := findFormulaFile(name)
+	if err != nil {
+		if path, err = findFormulaFile("mol-" + name); err != nil {
+			return nil
+		}
+	}
+	return loadTypedFormulaFile(path)
+}
+
+// loadTypedFormulaFile parses a TOML formula file, warning on parse errors.
+func loadTypedFormulaFile(path string) *formula.Formula {
+	if !strings.HasSuffix(path, ".toml") {
+		return nil
+	}
+	f, err := parseTypedFormulaFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s skipping variable validation: %v\n", style.Dim.Render("Warning:"), err)
+		return nil
+	}
+	return f
+}
+
+// parseTypedFormulaFile parses a formula file, resolving extends and
+// [[include]] from its own directory first, then the formula search paths.
+func parseTypedFormulaFile(path string) (*formula.Formula, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from the formula search paths
+	if err != nil {
+		return nil, fmt.Errorf("reading formula file: %w", err)
+	}
+	dirs := append([]string{filepath.Dir(path)}, formulaSearchPaths()...)
+	return formula.ParseWithLoader(data, formula.NewLoader(dirs...))
+}
+
+// parseFormulaVarFlags parses repeated --var key=value flags. Later values
+// for the same key win.
+func parseFormulaVarFlags(vars []string) (map[string]string, error) {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected key=value", v)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// resolveFormulaVars validates --var flags against the formula's declared
+// variables. In a terminal, missing required variables are prompted for and
+// appended to the returned flags. With no typed formula the flags are only
+// checked for key=value syntax.
+func resolveFormulaVars(f *formula.Formula, vars []string) ([]string, error) {
+	values, err := parseFormulaVarFlags(vars)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return vars, nil
+	}
+
+	resolved := append([]string(nil), vars...)
+	if len(f.MissingVars(values)) > 0 && term.IsTerminal(int(os.Stdin.Fd())) {
+		prompted := promptFormulaVars(f, values)
+		for _, p := range f.Params() {
+			if value, ok := prompted[p.Name]; ok {
+				resolved = append(resolved, p.Name+"="+value)
+			}
+		}
+	}
+
+	// Rig names can only be checked inside a town
+	var isRig func(string) bool
+	if _, err := workspace.FindFromCwd(); err == nil {
+		isRig = func(name string) bool {
+			_, ok := IsRigName(name)
+			return ok
+		}
+	}
+
+	if err := f.ValidateVars(values, isRig); err != nil {
+		return nil, fmt.Errorf("%w\n\nRun 'gt formula show %s' for usage", err, f.Name)
+	}
+	return resolved, nil
+}
+
+// promptFormulaVars asks for each missing required variable until none are
+// left or the user enters an empty value. Answers are added to values and
+// also returned on their own.
+func promptFormulaVars(f *formula.Formula, values map[string]string) map[string]string {
+	prompted := make(map[string]string)
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Printf("%s needs more variables (empty to stop):\n", style.Bold.Render(f.Name))
+	for {
+		missing := f.MissingVars(values)
+		if len(missing) == 0 {
+			return prompted
+		}
+		p := missing[0]
+		if p.Description != "" {
+			fmt.Printf("  %s\n", style.Dim.Render(p.Description))
+		}
+		fmt.Printf("  %s %s: ", p.Name, style.Dim.Render(p.Placeholder()))
+		answer, err := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return prompted
+		}
+		if checkErr := p.Check(answer, nil); checkErr != nil {
+			fmt.Printf("  %s %v\n", style.Dim.Render("✗"), checkErr)
+			if err != nil {
+				return prompted
+			}
+			continue
+		}
+		values[p.Name] = answer
+		prompted[p.Name] = answer
+		if err != nil {
+			return prompted
+		}
+	}
+}
+
+// printFormulaUsage prints how to run a formula and what each variable takes.
+func printFormulaUsage(name string, f *formula.Formula) {
+	params := f.Params()
+
+	run := "gt sling " + name + " <target>"
+	if f.Type == formula.TypeConvoy {
+		run = "gt formula run " + name
+	}
+	var args []string
+	for _, p := range params {
+		arg := "--var " + p.Name + "=" + p.Placeholder()
+		if p.Required && p.Default == "" && len(p.RequiredUnless) == 0 {
+			args = append(args, arg)
+		} else {
+			args = append(args, "["+arg+"]")
+		}
+	}
+
+	fmt.Printf("%s\n", style.Bold.Render("Usage:"))
+	fmt.Printf("  %s\n", strings.Join(append([]string{run}, args...), " "))
+	if len(params) == 0 {
+		return
+	}
+
+	nameWidth, typeWidth := 0, 0
+	for _, p := range params {
+		if len(p.Name) > nameWidth {
+			nameWidth = len(p.Name)
+		}
+		if len(p.Placeholder()) > typeWidth {
+			typeWidth = len(p.Placeholder())
+		}
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Variables:"))
+	for _, p := range params {
+		var notes []string
+		switch {
+		case p.Default != "":
+			notes = append(notes, "default: "+p.Default)
+		case len(p.RequiredUnless) > 0:
+			notes = append(notes, "required unless "+strings.Join(p.RequiredUnless, " or "))
+		case p.Required:
+			notes = append(notes, "required")
+		}
+		if p.Pattern != "" {
+			notes = append(notes, "pattern: "+p.Pattern)
+		}
+		line := fmt.Sprintf("  %-*s  %-*s", nameWidth, p.Name, typeWidth, p.Placeholder())
+		if p.Description != "" {
+			line += "  " + p.Description
+		}
+		if len(notes) > 0 {
+			line += " " + style.Dim.Render("("+strings.Join(notes, ", ")+")")
+		}
+		fmt.Println(line)
+	}
+}
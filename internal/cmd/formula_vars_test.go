@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+func TestParseFormulaVarFlags(t *testing.T) {
+	values, err := parseFormulaVarFlags([]string{"a=1", "b=x=y", "a=2", "empty="})
+	if err != nil {
+		t.Fatalf("parseFormulaVarFlags: %v", err)
+	}
+	if values["a"] != "2" || values["b"] != "x=y" || values["empty"] != "" {
+		t.Errorf("values = %v", values)
+	}
+
+	if _, err := parseFormulaVarFlags([]string{"novalue"}); err == nil {
+		t.Error("expected error for flag without '='")
+	}
+}
+
+func TestResolveFormulaVars(t *testing.T) {
+	f, err := formula.Parse([]byte(`
+formula = "typed"
+[[steps]]
+id = "a"
+[vars.count]
+type = "int"
+required = true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No typed formula: flags pass through untouched
+	vars, err := resolveFormulaVars(nil, []string{"anything=goes"})
+	if err != nil || len(vars) != 1 {
+		t.Errorf("resolveFormulaVars(nil) = %v, %v", vars, err)
+	}
+
+	if vars, err := resolveFormulaVars(f, []string{"count=3"}); err != nil || len(vars) != 1 {
+		t.Errorf("resolveFormulaVars(valid) = %v, %v", vars, err)
+	}
+
+	// Not a terminal under go test, so missing vars are reported, not prompted
+	_, err = resolveFormulaVars(f, []string{"count=lots", "typo=1"})
+	if err == nil || !strings.Contains(err.Error(), "not an integer") || !strings.Contains(err.Error(), "typo: unknown variable") {
+		t.Errorf("resolveFormulaVars(invalid) error = %v", err)
+	}
+	if _, err := resolveFormulaVars(f, nil); err == nil || !strings.Contains(err.Error(), "count: required") {
+		t.Errorf("resolveFormulaVars(missing) error = %v", err)
+	}
+}
@@ -46,6 +46,14 @@ The --collect (-c) flag gathers current state (hooked work, inbox, ready beads,
 in-progress items) and includes it in the handoff mail. This provides context
 for the next session without manual summarization.
 
+Alongside the mail, a structured handoff document (JSON) is written with the
+hooked bead, molecule step, files touched, and anything recorded with
+--decision, --question, --blocker and --resume. The successor's 'gt prime'
+renders it; 'gt handoff history' lists past handoffs.
+
+  gt handoff -c --decision "Use the v2 API" --question "Keep the shim?" \
+    --resume "go test ./internal/web/..."
+
 Any molecule on the hook will be auto-continued by the new session.
 The SessionStart hook runs 'gt prime' to restore context.`,
 	RunE: runHandoff,
@@ -82,6 +90,9 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 		return doneCmd.Run()
 	}
 
+	// The agent's own words, before any collected state is appended
+	handoffSummary := handoffMessage
+
 	// If --collect flag is set, auto-collect state into the message
 	if handoffCollect {
 		collected := collectHandoffState()
@@ -94,6 +105,9 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 			handoffSubject = "Session handoff with context"
 		}
 	}
+	if hasStructuredHandoffFlags() && handoffSubject == "" {
+		handoffSubject = "Session handoff with context"
+	}
 
 	t := tmux.NewTmux()
 
@@ -166,6 +180,7 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 	if handoffDryRun {
 		if handoffSubject != "" || handoffMessage != "" {
 			fmt.Printf("Would send handoff mail: subject=%q (auto-hooked)\n", handoffSubject)
+			fmt.Printf("Would write handoff document to %s\n", style.Dim.Render(".runtime/handoffs/"))
 		}
 		fmt.Printf("Would execute: tmux clear-history -t %s\n", pane)
 		fmt.Printf("Would execute: tmux respawn-pane -k -t %s %s\n", pane, restartCmd)
@@ -182,6 +197,8 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 		} else {
 			fmt.Printf("%s Sent handoff mail %s (auto-hooked)\n", style.Bold.Render("📬"), beadID)
 		}
+		// Structured copy of the handoff for gt prime and gt handoff history
+		writeHandoffDocument(currentSession, handoffSubject, handoffSummary, beadID)
 	}
 
 	// NOTE: reportAgentState("stopped") removed (gt-zecmc)
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/handoff"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var handoffHistoryCmd = &cobra.Command{
+	Use:   "history [agent]",
+	Short: "Show structured handoff documents for an agent",
+	Long: `Show the structured handoff documents written by an agent's sessions,
+newest first.
+
+The agent is an address such as mayor, deacon, gastown/witness or
+gastown/crew/max. Defaults to the current agent.
+
+Examples:
+  gt handoff history                    # This agent's handoffs
+  gt handoff history gastown/crew/max   # Another agent's handoffs
+  gt handoff history mayor -n 1 --json  # Latest mayor handoff as JSON`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runHandoffHistory,
+}
+
+var (
+	handoffDecisions []string
+	handoffQuestions []string
+	handoffBlockers  []string
+	handoffResume    []string
+
+	handoffHistoryLimit int
+	handoffHistoryJSON  bool
+)
+
+func init() {
+	handoffCmd.Flags().StringArrayVar(&handoffDecisions, "decision", nil, "Record a decision made this session (repeatable)")
+	handoffCmd.Flags().StringArrayVar(&handoffQuestions, "question", nil, "Record an open question for the successor (repeatable)")
+	handoffCmd.Flags().StringArrayVar(&handoffBlockers, "blocker", nil, "Record a blocker (repeatable)")
+	handoffCmd.Flags().StringArrayVar(&handoffResume, "resume", nil, "Record a command the successor should run to resume (repeatable)")
+
+	handoffHistoryCmd.Flags().IntVarP(&handoffHistoryLimit, "limit", "n", 5, "Maximum documents to show (0 for all)")
+	handoffHistoryCmd.Flags().BoolVar(&handoffHistoryJSON, "json", false, "Output as JSON")
+	handoffCmd.AddCommand(handoffHistoryCmd)
+}
+
+// hasStructuredHandoffFlags reports whether any structured handoff fields
+// were given on the command line.
+func hasStructuredHandoffFlags() bool {
+	return len(handoffDecisions) > 0 || len(handoffQuestions) > 0 ||
+		len(handoffBlockers) > 0 || len(handoffResume) > 0
+}
+
+// buildHandoffDocument collects the structured state of the current agent
+// for its successor. Collection is best effort: fields that cannot be
+// determined are left empty.
+func buildHandoffDocument(agentID, session, subject, summary string) *handoff.Document {
+	doc := &handoff.Document{
+		Agent:          agentID,
+		Session:        session,
+		Subject:        subject,
+		Summary:        summary,
+		Decisions:      handoffDecisions,
+		OpenQuestions:  handoffQuestions,
+		Blockers:       handoffBlockers,
+		ResumeCommands: handoffResume,
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return doc
+	}
+
+	g := git.NewGit(cwd)
+	if branch, err := g.CurrentBranch(); err == nil {
+		doc.Branch = branch
+	}
+	if status, err := g.Status(); err == nil {
+		for _, files := range [][]string{status.Modified, status.Added, status.Deleted, status.Untracked} {
+			doc.FilesTouched = append(doc.FilesTouched, files...)
+		}
+	}
+
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return doc
+	}
+	roleInfo, err := GetRoleWithContext(cwd, townRoot)
+	if err != nil {
+		return doc
+	}
+
+	var resume []string
+	if hooked := detectHookedBead(cwd, roleInfo); hooked != "" {
+		doc.HookedBead = hooked
+		if issue, err := beads.New(cwd).Show(hooked); err == nil {
+			doc.HookedTitle = issue.Title
+		}
+		resume = append(resume, "gt hook")
+	}
+	if mol, step, title := detectMoleculeContext(cwd, roleInfo); mol != "" {
+		doc.MoleculeID, doc.Step, doc.StepTitle = mol, step, title
+		resume = append(resume, "bd show "+step)
+	}
+
+	assignee := getAgentIdentity(RoleContext{Role: roleInfo.Role, Rig: roleInfo.Rig, Polecat: roleInfo.Polecat})
+	if assignee != "" {
+		issues, err := beads.New(cwd).List(beads.ListOptions{
+			Status:   "in_progress",
+			Assignee: assignee,
+			Priority: -1,
+		})
+		if err == nil {
+			for _, issue := range issues {
+				if issue.ID == doc.Step {
+					continue
+				}
+				doc.InProgress = append(doc.InProgress, fmt.Sprintf("%s: %s", issue.ID, issue.Title))
+			}
+		}
+	}
+
+	doc.ResumeCommands = append(resume, doc.ResumeCommands...)
+	return doc
+}
+
+// writeHandoffDocument saves the structured handoff alongside the mail.
+// Failures are reported but never block the handoff itself.
+func writeHandoffDocument(session, subject, summary, mailID string) {
+	townRoot := detectTownRootFromCwd()
+	if townRoot == "" {
+		return
+	}
+	agentID, _, _, err := resolveSelfTarget()
+	if err != nil {
+		return
+	}
+
+	doc := buildHandoffDocument(agentID, session, subject, summary)
+	doc.MailID = mailID
+	if err := handoff.Write(townRoot, doc); err != nil {
+		style.PrintWarning("could not write handoff document: %v", err)
+		return
+	}
+	fmt.Printf("%s Wrote handoff document %s\n", style.Bold.Render("📋"), doc.Path())
+}
+
+// outputStructuredHandoff renders the latest unconsumed handoff document for
+// the agent, then marks it consumed so later restarts don't repeat it.
+func outputStructuredHandoff(ctx RoleContext) {
+	agentID := getAgentIdentity(ctx)
+	if agentID == "" || ctx.TownRoot == "" {
+		return
+	}
+	doc, err := handoff.Latest(ctx.TownRoot, agentID)
+	if err != nil || doc == nil || doc.ConsumedAt != nil || doc.IsEmpty() {
+		return
+	}
+
+	fmt.Println()
+	fmt.Printf("%s\n\n", style.Bold.Render("## 📋 Structured Handoff"))
+	fmt.Println(handoff.Render(doc))
+	fmt.Println()
+	fmt.Println(style.Dim.Render("(History: gt handoff history)"))
+
+	if !primeDryRun {
+		_ = handoff.MarkConsumed(doc)
+	}
+}
+
+func runHandoffHistory(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return fmt.Errorf("not in a Gas Town workspace")
+	}
+
+	var agentID string
+	if len(args) > 0 {
+		agentID = args[0]
+	} else {
+		agentID, _, _, err = resolveSelfTarget()
+		if err != nil {
+			return fmt.Errorf("detecting agent identity: %w", err)
+		}
+	}
+
+	docs, err := handoff.History(townRoot, agentID, handoffHistoryLimit)
+	if err != nil {
+		return err
+	}
+
+	if handoffHistoryJSON {
+		if docs == nil {
+			docs = []*handoff.Document{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(docs)
+	}
+
+	if len(docs) == 0 {
+		fmt.Printf("%s No handoff documents for %s\n", style.Dim.Render("○"), strings.Trim(agentID, "/"))
+		return nil
+	}
+	for i, doc := range docs {
+		if i > 0 {
+			fmt.Println()
+		}
+		title := doc.Subject
+		if title == "" {
+			title = "Handoff"
+		}
+		fmt.Printf("%s\n", style.Bold.Render("## "+title))
+		fmt.Println(handoff.Render(doc))
+	}
+	return nil
+}
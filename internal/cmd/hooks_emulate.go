@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/hookemu"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var hooksEmulateSettings string
+
+var hooksEmulateCmd = &cobra.Command{
+	Use:   "emulate [--settings <file>] -- <agent> [args...]",
+	Short: "Run an agent under hook emulation",
+	Long: `Run an agent that has no native hook system under Gas Town's hook
+emulation.
+
+Runtimes with hooks provider "emulated" (Cursor, Auggie and Amp by
+default) or "codex" are started through this wrapper. It records the agent
+process for the daemon, which then fires the hooks from .gastown/hooks.json
+(found by walking up from the working directory, or --settings):
+
+  SessionStart  once the agent's pane settles after launch (gt prime, mail)
+  Idle          when the pane has been quiet for idle_after_seconds (mail)
+  Stop          when the agent process exits
+
+Hook output is delivered by nudging the agent's session. PreToolUse hooks
+(gt tap guard) run before every command listed in guard_commands, via shims
+placed first on the agent's PATH.
+
+The agent replaces the wrapper process, so process detection and signals
+work as if it had been started directly.
+
+Example .gastown/hooks.json:
+  {
+    "hooks": {
+      "SessionStart": ["gt prime", "gt mail check --inject"],
+      "Idle": ["gt mail check --inject"],
+      "PreToolUse": ["gt tap guard"]
+    },
+    "idle_after_seconds": 90,
+    "guard_commands": ["git", "gh", "npm"]
+  }`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runHooksEmulate,
+}
+
+var hooksToolCmd = &cobra.Command{
+	Use:                "tool <command> [args...]",
+	Short:              "Run PreToolUse hooks, then a guarded command (internal)",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	RunE:               runHooksTool,
+}
+
+func init() {
+	hooksEmulateCmd.Flags().StringVar(&hooksEmulateSettings, "settings", "", "Hooks file, relative paths are searched for upward")
+	hooksEmulateCmd.Flags().SetInterspersed(false)
+
+	hooksCmd.AddCommand(hooksEmulateCmd)
+	hooksCmd.AddCommand(hooksToolCmd)
+}
+
+func runHooksEmulate(cmd *cobra.Command, args []string) error {
+	agent, err := exec.LookPath(args[0])
+	if err != nil {
+		return fmt.Errorf("agent %s not found: %w", args[0], err)
+	}
+
+	cwd, _ := os.Getwd()
+	settings := findHooksSettings(cwd, hooksEmulateSettings)
+	role := os.Getenv("GT_ROLE")
+	cfg := hookemu.ConfigFor(settings, role)
+
+	env := setEnv(os.Environ(), "GT_HOOKS_EMULATED", "1")
+	var onExit func(int)
+
+	townRoot := os.Getenv("GT_ROOT")
+	if townRoot == "" {
+		townRoot, _ = workspace.FindFromCwd()
+	}
+	sessionName, backend := currentAgentSession()
+	if townRoot != "" && sessionName != "" && role != "" {
+		if runtime.GOOS != "windows" && len(cfg.Hooks[hookemu.EventPreToolUse]) > 0 && len(cfg.GuardCommands) > 0 {
+			env = installGuardShims(env, townRoot, sessionName, settings, cfg)
+		}
+
+		state := &hookemu.State{
+			Session:   sessionName,
+			Backend:   backend,
+			Role:      role,
+			WorkDir:   cwd,
+			Settings:  settings,
+			PID:       os.Getpid(),
+			StartedAt: time.Now(),
+			Env:       hookemu.AgentEnv(os.Environ()),
+		}
+		if err := hookemu.WriteState(townRoot, state); err != nil {
+			fmt.Fprintf(os.Stderr, "gt hooks emulate: recording agent start: %v\n", err)
+		}
+		onExit = func(code int) {
+			now := time.Now()
+			state.ExitedAt, state.ExitCode = &now, code
+			_ = hookemu.WriteState(townRoot, state)
+		}
+	}
+
+	return hookemu.Exec(agent, args, env, onExit)
+}
+
+func runHooksTool(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: gt hooks tool <command> [args...]")
+	}
+	name := args[0]
+	shimDir := os.Getenv("GT_HOOK_SHIMS")
+	real, err := hookemu.LookPathExcluding(name, shimDir)
+	if err != nil {
+		return err
+	}
+
+	cwd, _ := os.Getwd()
+	cfg := hookemu.ConfigFor(os.Getenv("GT_HOOK_SETTINGS"), os.Getenv("GT_ROLE"))
+	if hookemu.RunPreToolUse(cfg, hookemu.ToolCommand(name, args[1:]), cwd, os.Stderr) {
+		os.Exit(2)
+	}
+	return hookemu.Exec(real, args, os.Environ(), nil)
+}
+
+// installGuardShims writes the session's guard shims and returns env with
+// the shim directory first on PATH.
+func installGuardShims(env []string, townRoot, sessionName, settings string, cfg *hookemu.Config) []string {
+	exe, err := os.Executable()
+	if err != nil {
+		return env
+	}
+	dir := hookemu.ShimDir(townRoot, sessionName)
+	if err := hookemu.WriteShims(dir, exe, cfg.GuardCommands); err != nil {
+		fmt.Fprintf(os.Stderr, "gt hooks emulate: %v (PreToolUse hooks disabled)\n", err)
+		return env
+	}
+	env = setEnv(env, "PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	env = setEnv(env, "GT_HOOK_SHIMS", dir)
+	return setEnv(env, "GT_HOOK_SETTINGS", settings)
+}
+
+// findHooksSettings resolves the hooks file: an absolute --settings path is
+// used as is, a relative one is searched for upward from cwd like the
+// default location.
+func findHooksSettings(cwd, flag string) string {
+	if flag == "" {
+		return hookemu.FindConfig(cwd)
+	}
+	if filepath.IsAbs(flag) {
+		return flag
+	}
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		path := filepath.Join(dir, flag)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// currentAgentSession returns the session this process runs in and its
+// backend, or "" outside a Gas Town session.
+func currentAgentSession() (string, string) {
+	if name := os.Getenv("GT_PTY_SESSION"); name != "" {
+		return name, session.BackendPTY
+	}
+	if os.Getenv("TMUX") != "" {
+		if name, err := getCurrentTmuxSession(); err == nil && name != "" {
+			return name, session.BackendTmux
+		}
+	}
+	return "", ""
+}
+
+// setEnv sets key in an environment list, replacing any existing entry.
+// syscall.Exec passes the list verbatim, so duplicates must not remain.
+func setEnv(env []string, key, value string) []string {
+	out := env[:0:0]
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, key+"=") {
+			out = append(out, kv)
+		}
+	}
+	return append(out, key+"="+value)
+}
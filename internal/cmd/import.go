@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tracker"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var importCmd = &cobra.Command{
+	Use:     "import [source...]",
+	GroupID: GroupWork,
+	Short:   "Import issues from GitHub, GitLab or Jira into beads",
+	Long: `Import open issues from external trackers into beads.
+
+Each source is a tracker project tied to a rig. Importing creates a bead in
+the rig for every open issue not imported before, labelled
+external:<ref> (e.g. external:acme/widgets#12, external:OPS-101). The
+links between issues and beads are kept in mayor/imports.json.
+
+gt import sync pushes bead progress back: a comment when work starts, and
+a comment with the merge commit plus closing the issue when the bead
+closes. The daemon runs the same sync periodically (import_sync patrol).
+
+API tokens are read from the environment: GITHUB_TOKEN, GITLAB_TOKEN or
+JIRA_TOKEN by default, or the variable named with --token-env.
+
+Examples:
+  gt import add widgets --kind github --project acme/widgets --rig gastown --query gastown
+  gt import add ops --kind jira --api-url https://acme.atlassian.net --project OPS \
+      --user ops@acme.dev --rig infra --query "labels = gastown"
+  gt import                 # Import from every source
+  gt import widgets --dry-run
+  gt import sync`,
+	RunE: runImport,
+}
+
+var importAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add or replace an import source",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImportAdd,
+}
+
+var importRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an import source (imported beads stay linked)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImportRemove,
+}
+
+var importListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List import sources and imported issues",
+	Args:  cobra.NoArgs,
+	RunE:  runImportList,
+}
+
+var importSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Push bead status changes back to external trackers",
+	Args:  cobra.NoArgs,
+	RunE:  runImportSync,
+}
+
+var (
+	importDryRun bool
+	importJSON   bool
+
+	importKind     string
+	importProject  string
+	importRig      string
+	importQuery    string
+	importAPIURL   string
+	importTokenEnv string
+	importUser     string
+)
+
+func init() {
+	importCmd.Flags().BoolVarP(&importDryRun, "dry-run", "n", false, "Show what would be imported")
+	importSyncCmd.Flags().BoolVarP(&importDryRun, "dry-run", "n", false, "Show what would be pushed")
+	importListCmd.Flags().BoolVar(&importJSON, "json", false, "Output as JSON")
+
+	importAddCmd.Flags().StringVar(&importKind, "kind", "", "Tracker: github, gitlab or jira (required)")
+	importAddCmd.Flags().StringVar(&importProject, "project", "", "owner/repo, GitLab project path, or Jira project key (required)")
+	importAddCmd.Flags().StringVar(&importRig, "rig", "", "Rig to create beads in (required)")
+	importAddCmd.Flags().StringVar(&importQuery, "query", "", "Labels (GitHub, GitLab) or JQL clause (Jira) to filter issues")
+	importAddCmd.Flags().StringVar(&importAPIURL, "api-url", "", "API root (required for Jira: the site URL)")
+	importAddCmd.Flags().StringVar(&importTokenEnv, "token-env", "", "Environment variable holding the API token")
+	importAddCmd.Flags().StringVar(&importUser, "user", "", "Jira Cloud account email for basic auth")
+	_ = importAddCmd.MarkFlagRequired("kind")
+	_ = importAddCmd.MarkFlagRequired("project")
+	_ = importAddCmd.MarkFlagRequired("rig")
+
+	importCmd.AddCommand(importAddCmd, importRemoveCmd, importListCmd, importSyncCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+// loadImportStore loads the town's imports.json. Commands that change it
+// load it locked (and Unlock when done), so a concurrent gt import or daemon
+// sync can't drop their changes.
+func loadImportStore(locked bool) (string, *tracker.Store, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	load := tracker.LoadStore
+	if locked {
+		load = tracker.LoadStoreLocked
+	}
+	store, err := load(townRoot)
+	if err != nil {
+		return "", nil, err
+	}
+	return townRoot, store, nil
+}
+
+func openImportSource(src *tracker.Source) (tracker.Tracker, error) {
+	return src.Open(nil)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	townRoot, store, err := loadImportStore(!importDryRun)
+	if err != nil {
+		return err
+	}
+	defer store.Unlock()
+	if len(store.Sources) == 0 {
+		return fmt.Errorf("no import sources configured (see gt import add --help)")
+	}
+
+	sources := store.Sources
+	if len(args) > 0 {
+		sources = nil
+		for _, name := range args {
+			src := store.Source(name)
+			if src == nil {
+				return fmt.Errorf("unknown import source %q", name)
+			}
+			sources = append(sources, src)
+		}
+	}
+
+	town := &tracker.TownBeads{TownRoot: townRoot}
+	var firstErr error
+	total := 0
+	for _, src := range sources {
+		tr, err := openImportSource(src)
+		if err == nil {
+			var links []*tracker.Link
+			links, err = tracker.Import(context.Background(), store, src, tr, town, importDryRun)
+			for _, l := range links {
+				if importDryRun {
+					fmt.Printf("  would import %s → %s\n", l.Ref, l.Rig)
+				} else {
+					fmt.Printf("%s Imported %s as %s\n", style.Bold.Render("✓"), l.Ref, l.Bead)
+				}
+			}
+			total += len(links)
+		}
+		if err != nil {
+			style.PrintWarning("%s: %v", src.Name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if !importDryRun {
+		// Save even after a partial failure so created beads stay linked.
+		if err := store.Save(); err != nil {
+			return err
+		}
+	}
+	if total == 0 && firstErr == nil {
+		fmt.Printf("%s Nothing new to import\n", style.Dim.Render("○"))
+	}
+	return firstErr
+}
+
+func runImportAdd(cmd *cobra.Command, args []string) error {
+	townRoot, store, err := loadImportStore(true)
+	if err != nil {
+		return err
+	}
+	defer store.Unlock()
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return fmt.Errorf("loading rigs config: %w", err)
+	}
+	if _, ok := rigsConfig.Rigs[importRig]; !ok {
+		return fmt.Errorf("rig %q not found", importRig)
+	}
+
+	src := &tracker.Source{
+		Name:     args[0],
+		Kind:     importKind,
+		APIURL:   importAPIURL,
+		Project:  importProject,
+		Query:    importQuery,
+		TokenEnv: importTokenEnv,
+		User:     importUser,
+		Rig:      importRig,
+	}
+	// Validate the source without needing a token.
+	if _, err := tracker.New(tracker.Config{Kind: src.Kind, APIURL: src.APIURL, Project: src.Project}); err != nil {
+		return err
+	}
+
+	store.SetSource(src)
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("%s Import source %s: %s %s → %s\n", style.Bold.Render("✓"), src.Name, src.Kind, src.Project, src.Rig)
+	return nil
+}
+
+func runImportRemove(cmd *cobra.Command, args []string) error {
+	_, store, err := loadImportStore(true)
+	if err != nil {
+		return err
+	}
+	defer store.Unlock()
+	if !store.RemoveSource(args[0]) {
+		return fmt.Errorf("unknown import source %q", args[0])
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("%s Removed import source %s\n", style.Bold.Render("✓"), args[0])
+	return nil
+}
+
+func runImportList(cmd *cobra.Command, args []string) error {
+	_, store, err := loadImportStore(false)
+	if err != nil {
+		return err
+	}
+
+	if importJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(store)
+	}
+
+	if len(store.Sources) == 0 {
+		fmt.Printf("%s No import sources configured\n", style.Dim.Render("○"))
+	}
+	for _, src := range store.Sources {
+		fmt.Printf("%s %s (%s %s → %s)\n", style.Bold.Render("●"), src.Name, src.Kind, src.Project, src.Rig)
+		if src.Query != "" {
+			fmt.Printf("    query: %s\n", src.Query)
+		}
+	}
+
+	if len(store.Links) > 0 {
+		fmt.Println()
+		fmt.Println(style.Bold.Render("Imported issues:"))
+	}
+	for _, l := range store.Links {
+		status := l.Status
+		if status == "" {
+			status = "open"
+		}
+		line := fmt.Sprintf("  %-28s %-14s %s", l.Ref, l.Bead, status)
+		if l.LastError != "" {
+			line += "  " + style.Dim.Render("(sync error: "+l.LastError+")")
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func runImportSync(cmd *cobra.Command, args []string) error {
+	townRoot, store, err := loadImportStore(!importDryRun)
+	if err != nil {
+		return err
+	}
+	defer store.Unlock()
+
+	town := &tracker.TownBeads{TownRoot: townRoot}
+	updates := tracker.Sync(context.Background(), store, town, openImportSource, importDryRun)
+	failed := 0
+	for _, u := range updates {
+		switch {
+		case importDryRun:
+			fmt.Printf("  would mark %s %s\n", u.Link.Ref, u.Status)
+		case u.Err != nil:
+			failed++
+			style.PrintWarning("%s: %v", u.Link.Ref, u.Err)
+		default:
+			fmt.Printf("%s %s marked %s\n", style.Bold.Render("✓"), u.Link.Ref, u.Status)
+		}
+	}
+
+	if !importDryRun && len(updates) > 0 {
+		if err := store.Save(); err != nil {
+			return err
+		}
+	}
+	if len(updates) == 0 {
+		fmt.Printf("%s Trackers are up to date\n", style.Dim.Render("○"))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d updates failed", failed, len(updates))
+	}
+	return nil
+}
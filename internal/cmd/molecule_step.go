@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -33,33 +34,47 @@ This command handles the step-to-step transition for polecats:
    - Sends POLECAT_DONE to witness
    - Exits the session
 
+Steps cooked from formulas with control flow carry step.* lines in their
+description. Use --output to record values that later conditions read as
+steps.<id>.<key>:
+
+- A step with "until" is repeated (up to its retry bound) while the
+  condition is false, instead of being closed.
+- A ready step whose "when" condition is false is closed as skipped and
+  the search moves on to the step after it.
+
 IMPORTANT: This is the canonical way to complete molecule steps. Do NOT manually
 close steps with 'bd close' - it skips the auto-continuation logic.
 
-Example:
-  gt mol step done gt-abc.1    # Complete step 1 of molecule gt-abc`,
+Examples:
+  gt mol step done gt-abc.1                   # Complete step 1 of molecule gt-abc
+  gt mol step done gt-abc.2 --output clean=no # Record an output for later conditions`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMoleculeStepDone,
 }
 
 var (
-	moleculeStepDryRun bool
+	moleculeStepDryRun  bool
+	moleculeStepOutputs []string
 )
 
 func init() {
 	moleculeStepDoneCmd.Flags().BoolVarP(&moleculeStepDryRun, "dry-run", "n", false, "Show what would be done without executing")
+	moleculeStepDoneCmd.Flags().StringArrayVar(&moleculeStepOutputs, "output", nil, "Record a step output for when/until conditions (key=value, repeatable)")
 	moleculeStepDoneCmd.Flags().BoolVar(&moleculeJSON, "json", false, "Output as JSON")
 }
 
 // StepDoneResult is the result of a step done operation.
 type StepDoneResult struct {
-	StepID       string `json:"step_id"`
-	MoleculeID   string `json:"molecule_id"`
-	StepClosed   bool   `json:"step_closed"`
-	NextStepID   string `json:"next_step_id,omitempty"`
-	NextStepTitle string `json:"next_step_title,omitempty"`
-	Complete     bool   `json:"complete"`
-	Action       string `json:"action"` // "continue", "done", "no_more_ready"
+	StepID        string   `json:"step_id"`
+	MoleculeID    string   `json:"molecule_id"`
+	StepClosed    bool     `json:"step_closed"`
+	NextStepID    string   `json:"next_step_id,omitempty"`
+	NextStepTitle string   `json:"next_step_title,omitempty"`
+	Complete      bool     `json:"complete"`
+	Action        string   `json:"action"` // "continue", "repeat", "done", "no_more_ready"
+	Attempt       int      `json:"attempt,omitempty"`
+	Skipped       []string `json:"skipped,omitempty"`
 }
 
 func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
@@ -104,6 +119,59 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		MoleculeID: moleculeID,
 	}
 
+	// Record outputs and check the step's until loop
+	ctrl := formula.ParseStepControl(step.Description)
+	outputs, err := parseStepOutputFlags(moleculeStepOutputs)
+	if err != nil {
+		return err
+	}
+	if len(outputs) > 0 {
+		if ctrl.Outputs == nil {
+			ctrl.Outputs = make(map[string]string)
+		}
+		for k, v := range outputs {
+			ctrl.Outputs[k] = v
+		}
+		if moleculeStepDryRun {
+			fmt.Printf("[dry-run] Would record outputs on %s: %s\n", stepID, strings.Join(moleculeStepOutputs, ", "))
+		} else if err := setStepControl(b, step, ctrl); err != nil {
+			return fmt.Errorf("recording step outputs: %w", err)
+		}
+	}
+
+	if ctrl.Until != "" {
+		stepOutputs, err := moleculeStepOutputsFor(b, moleculeID)
+		if err != nil {
+			return fmt.Errorf("reading step outputs: %w", err)
+		}
+		if ctrl.ID != "" {
+			stepOutputs[ctrl.ID] = ctrl.Outputs
+		}
+		if stepShouldRepeat(ctrl, stepOutputs) {
+			ctrl.Attempt++
+			result.Action = "repeat"
+			result.Attempt = ctrl.Attempt
+			result.NextStepID = stepID
+			result.NextStepTitle = step.Title
+			if moleculeStepDryRun {
+				fmt.Printf("[dry-run] Would repeat step %s (until %s not met, repeat %d/%d)\n", stepID, ctrl.Until, ctrl.Attempt, ctrl.Retry)
+			} else if err := setStepControl(b, step, ctrl); err != nil {
+				return fmt.Errorf("recording step attempt: %w", err)
+			}
+			if moleculeJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(result)
+			}
+			fmt.Printf("%s Until %s not met: repeating step %s (%d/%d)\n",
+				style.Bold.Render("↻"), ctrl.Until, stepID, ctrl.Attempt, ctrl.Retry)
+			return handleStepContinue(cwd, townRoot, workDir, step, moleculeStepDryRun)
+		}
+		if cond, err := formula.ParseCondition(ctrl.Until); err == nil && !cond.Eval(formula.CondEnv{Outputs: stepOutputs}) {
+			style.PrintWarning("until %s still not met after %d repeats; closing step", ctrl.Until, ctrl.Retry)
+		}
+	}
+
 	// Step 3: Close the step
 	if moleculeStepDryRun {
 		fmt.Printf("[dry-run] Would close step: %s\n", stepID)
@@ -116,11 +184,12 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s Closed step %s: %s\n", style.Bold.Render("✓"), stepID, step.Title)
 	}
 
-	// Step 4: Find the next ready step
-	nextStep, allComplete, err := findNextReadyStep(b, moleculeID)
+	// Step 4: Find the next ready step, skipping steps whose when is false
+	nextStep, allComplete, skipped, err := findNextRunnableStep(b, moleculeID, moleculeStepDryRun)
 	if err != nil {
 		return fmt.Errorf("finding next step: %w", err)
 	}
+	result.Skipped = skipped
 
 	if allComplete {
 		result.Complete = true
@@ -162,9 +231,10 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 // extractMoleculeIDFromStep extracts the molecule ID from a step ID.
 // Step IDs have format: mol-id.N where N is the step number.
 // Examples:
-//   gt-abc.1 -> gt-abc
-//   gt-xyz.3 -> gt-xyz
-//   bd-mol-abc.2 -> bd-mol-abc
+//
+//	gt-abc.1 -> gt-abc
+//	gt-xyz.3 -> gt-xyz
+//	bd-mol-abc.2 -> bd-mol-abc
 func extractMoleculeIDFromStep(stepID string) string {
 	// Find the last dot
 	lastDot := strings.LastIndex(stepID, ".")
@@ -274,6 +344,121 @@ func findNextReadyStep(b *beads.Beads, moleculeID string) (*beads.Issue, bool, e
 	return nil, false, nil
 }
 
+// findNextRunnableStep is findNextReadyStep for molecules with control flow:
+// a ready step whose step.when condition is false is closed as skipped and
+// the search repeats. In dry-run mode the first such step is reported and
+// the search stops, since nothing after it can become ready.
+func findNextRunnableStep(b *beads.Beads, moleculeID string, dryRun bool) (*beads.Issue, bool, []string, error) {
+	var skipped []string
+	for {
+		next, complete, err := findNextReadyStep(b, moleculeID)
+		if err != nil || next == nil {
+			return next, complete, skipped, err
+		}
+		ctrl := formula.ParseStepControl(next.Description)
+		if ctrl.When == "" {
+			return next, false, skipped, nil
+		}
+		outputs, err := moleculeStepOutputsFor(b, moleculeID)
+		if err != nil {
+			return nil, false, skipped, fmt.Errorf("reading step outputs: %w", err)
+		}
+		run, err := stepShouldRun(ctrl, outputs)
+		if err != nil {
+			style.PrintWarning("step %s: %v; running it", next.ID, err)
+			return next, false, skipped, nil
+		}
+		if run {
+			return next, false, skipped, nil
+		}
+
+		skipped = append(skipped, next.ID)
+		if dryRun {
+			fmt.Printf("[dry-run] Would skip step %s: when %s is false\n", next.ID, ctrl.When)
+			return nil, false, skipped, nil
+		}
+		if err := b.CloseWithReason("skipped: when "+ctrl.When+" is false", next.ID); err != nil {
+			return nil, false, skipped, fmt.Errorf("skipping step %s: %w", next.ID, err)
+		}
+		fmt.Printf("%s Skipped step %s: %s (when %s is false)\n", style.Dim.Render("⤼"), next.ID, next.Title, ctrl.When)
+	}
+}
+
+// moleculeStepOutputsFor collects the step.output.* values recorded on a
+// molecule's steps, keyed by formula step ID.
+func moleculeStepOutputsFor(b *beads.Beads, moleculeID string) (map[string]map[string]string, error) {
+	children, err := b.List(beads.ListOptions{
+		Parent:   moleculeID,
+		Status:   "all",
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collectStepOutputs(children), nil
+}
+
+// collectStepOutputs maps formula step IDs to the outputs recorded on steps.
+func collectStepOutputs(steps []*beads.Issue) map[string]map[string]string {
+	outputs := make(map[string]map[string]string)
+	for _, s := range steps {
+		ctrl := formula.ParseStepControl(s.Description)
+		if ctrl.ID != "" && len(ctrl.Outputs) > 0 {
+			outputs[ctrl.ID] = ctrl.Outputs
+		}
+	}
+	return outputs
+}
+
+// stepShouldRun evaluates a step's when condition against recorded outputs.
+func stepShouldRun(ctrl *formula.StepControl, outputs map[string]map[string]string) (bool, error) {
+	if ctrl.When == "" {
+		return true, nil
+	}
+	cond, err := formula.ParseCondition(ctrl.When)
+	if err != nil {
+		return false, err
+	}
+	return cond.Eval(formula.CondEnv{Outputs: outputs}), nil
+}
+
+// stepShouldRepeat reports whether a step's until condition is still false
+// and it has repeats left. Unparseable conditions never repeat.
+func stepShouldRepeat(ctrl *formula.StepControl, outputs map[string]map[string]string) bool {
+	if ctrl.Until == "" || ctrl.Attempt >= ctrl.Retry {
+		return false
+	}
+	cond, err := formula.ParseCondition(ctrl.Until)
+	if err != nil {
+		return false
+	}
+	return !cond.Eval(formula.CondEnv{Outputs: outputs})
+}
+
+// parseStepOutputFlags parses --output key=value flags.
+func parseStepOutputFlags(flags []string) (map[string]string, error) {
+	outputs := make(map[string]string, len(flags))
+	for _, f := range flags {
+		key, value, ok := strings.Cut(f, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, ": \t") {
+			return nil, fmt.Errorf("invalid --output %q: expected key=value", f)
+		}
+		outputs[key] = strings.TrimSpace(value)
+	}
+	return outputs, nil
+}
+
+// setStepControl writes ctrl back to the step bead's description.
+func setStepControl(b *beads.Beads, step *beads.Issue, ctrl *formula.StepControl) error {
+	desc := formula.SetStepControl(step.Description, ctrl)
+	if err := b.Update(step.ID, beads.UpdateOptions{Description: &desc}); err != nil {
+		return err
+	}
+	step.Description = desc
+	return nil
+}
+
 // handleStepContinue handles continuing to the next step.
 func handleStepContinue(cwd, townRoot, _ string, nextStep *beads.Issue, dryRun bool) error { // workDir unused but kept for signature consistency
 	fmt.Printf("\n%s Next step: %s\n", style.Bold.Render("→"), nextStep.ID)
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
 )
 
 func TestExtractMoleculeIDFromStep(t *testing.T) {
@@ -717,3 +718,59 @@ func TestOldBuggyBehavior(t *testing.T) {
 
 	t.Log("Old buggy behavior confirmed: both steps marked ready when only step 1 should be")
 }
+
+// TestStepControlFlow covers the when/until decisions gt mol step done makes
+// from step.* lines on step beads.
+func TestStepControlFlow(t *testing.T) {
+	steps := []*beads.Issue{
+		{ID: "gt-mol.1", Description: "Implement.\n\nstep.id: implement"},
+		{ID: "gt-mol.2", Description: "Review.\n\nstep.id: review\nstep.until: steps.review.clean\nstep.retry: 2\nstep.output.clean: no"},
+		{ID: "gt-mol.3", Description: "Fix.\n\nstep.id: fix\nstep.when: !steps.review.clean"},
+		{ID: "gt-mol.4", Description: "No control lines"},
+	}
+	outputs := collectStepOutputs(steps)
+	if len(outputs) != 1 || outputs["review"]["clean"] != "no" {
+		t.Fatalf("collectStepOutputs = %v", outputs)
+	}
+
+	review := formula.ParseStepControl(steps[1].Description)
+	if !stepShouldRepeat(review, outputs) {
+		t.Error("review should repeat while not clean")
+	}
+	review.Attempt = 2
+	if stepShouldRepeat(review, outputs) {
+		t.Error("review repeated past its retry bound")
+	}
+
+	fix := formula.ParseStepControl(steps[2].Description)
+	if run, err := stepShouldRun(fix, outputs); err != nil || !run {
+		t.Errorf("fix should run while review is not clean: %v, %v", run, err)
+	}
+	outputs["review"]["clean"] = "yes"
+	if run, _ := stepShouldRun(fix, outputs); run {
+		t.Error("fix should be skipped once review is clean")
+	}
+	review.Attempt = 0
+	if stepShouldRepeat(review, outputs) {
+		t.Error("review repeated although until holds")
+	}
+
+	if run, err := stepShouldRun(formula.ParseStepControl(steps[3].Description), outputs); err != nil || !run {
+		t.Error("step without when should always run")
+	}
+	if _, err := stepShouldRun(&formula.StepControl{When: "a =="}, outputs); err == nil {
+		t.Error("expected error for unparseable when")
+	}
+}
+
+func TestParseStepOutputFlags(t *testing.T) {
+	outputs, err := parseStepOutputFlags([]string{"clean=yes", "issues= 3 "})
+	if err != nil || outputs["clean"] != "yes" || outputs["issues"] != "3" {
+		t.Errorf("parseStepOutputFlags = %v, %v", outputs, err)
+	}
+	for _, bad := range []string{"novalue", "=x", "a b=1", "a:b=1"} {
+		if _, err := parseStepOutputFlags([]string{bad}); err == nil {
+			t.Errorf("parseStepOutputFlags(%q) succeeded, want error", bad)
+		}
+	}
+}
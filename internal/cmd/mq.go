@@ -134,6 +134,15 @@ Output format:
   gt-mr-003   blocked      P1        polecat/Capable/gt-def    Capable 8m
               (waiting on gt-mr-001)
 
+When the rig requires code review (merge_queue.require_review), a REVIEW
+column shows each MR's review state: needed, pending, approved, stale or
+changes_requested.
+
+A paused queue (gt mq pause) or an active freeze window is shown above
+the table, its MRs are shown as held, and --ready lists nothing. The
+command then exits with status 2, so scripts and the refinery patrol can
+tell that nothing may merge. Scores moved by 'gt mq bump' are marked ↑ or ↓.
+
 Examples:
   gt mq list greenplace
   gt mq list greenplace --ready
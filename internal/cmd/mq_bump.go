@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ bump command flags
+var (
+	mqBumpToTop bool
+	mqBumpAfter string
+	mqBumpClear bool
+)
+
+var mqBumpCmd = &cobra.Command{
+	Use:   "bump <rig> <mr-id>",
+	Short: "Move a merge request up or down the queue",
+	Long: `Override the merge queue order for one MR.
+
+The Refinery merges in score order (see 'gt mq next'). A bump records an
+explicit score adjustment on the MR so that it lands where you put it:
+
+  --to-top       ahead of every other open MR
+  --after <mr>   directly behind another MR
+  --clear        drop the adjustment and go back to the computed score
+
+Scores keep moving with age, so a bump places the MR now rather than
+pinning it forever; bump again if the order drifts.
+
+Examples:
+  gt mq bump greenplace gp-mr-abc123 --to-top
+  gt mq bump greenplace gp-mr-abc123 --after gp-mr-def456
+  gt mq bump greenplace gp-mr-abc123 --clear`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQBump,
+}
+
+func init() {
+	mqBumpCmd.Flags().BoolVar(&mqBumpToTop, "to-top", false, "Move ahead of every other MR")
+	mqBumpCmd.Flags().StringVar(&mqBumpAfter, "after", "", "Place directly behind this MR")
+	mqBumpCmd.Flags().BoolVar(&mqBumpClear, "clear", false, "Remove a previous bump")
+	mqBumpCmd.MarkFlagsMutuallyExclusive("to-top", "after", "clear")
+	mqBumpCmd.MarkFlagsOneRequired("to-top", "after", "clear")
+
+	mqCmd.AddCommand(mqBumpCmd)
+}
+
+func runMQBump(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	adjust, err := refinery.NewEngineer(r).BumpMR(mrID, mqBumpAfter, mqBumpClear)
+	if err != nil {
+		return fmt.Errorf("bumping %s: %w", mrID, err)
+	}
+
+	switch {
+	case mqBumpClear:
+		fmt.Printf("%s Cleared queue override for %s\n", style.Bold.Render("✓"), mrID)
+	case mqBumpAfter != "":
+		fmt.Printf("%s Placed %s after %s\n", style.Bold.Render("✓"), mrID, mqBumpAfter)
+	default:
+		fmt.Printf("%s Moved %s to the top of the queue\n", style.Bold.Render("✓"), mrID)
+	}
+	if adjust != 0 {
+		fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("Score adjustment: %+.1f", adjust)))
+	}
+	return nil
+}
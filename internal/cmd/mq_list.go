@@ -123,6 +123,15 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		return scored[i].score > scored[j].score
 	})
 
+	// Pauses and freeze windows hold the whole queue: nothing is ready,
+	// and the exit status tells the patrol not to merge
+	eng := refinery.NewEngineer(r)
+	recheckPostMergePause(eng, mqListJSON)
+	hold := eng.QueueHold(now)
+	if hold != nil && mqListReady {
+		scored = nil
+	}
+
 	// Extract filtered issues for JSON output compatibility
 	var filtered []*beads.Issue
 	for _, s := range scored {
@@ -131,27 +140,54 @@ func runMQList(cmd *cobra.Command, args []string) error {
 
 	// JSON output
 	if mqListJSON {
-		return outputJSON(filtered)
+		if err := outputJSON(filtered); err != nil {
+			return err
+		}
+		return queueHeldExit(hold)
 	}
 
 	// Human-readable output
 	fmt.Printf("%s Merge queue for '%s':\n\n", style.Bold.Render("📋"), rigName)
 
+	if hold != nil {
+		icon := "⏸"
+		if hold.IsFreeze() {
+			icon = "❄"
+		}
+		fmt.Printf("  %s %s\n\n", style.Warning.Render(icon), style.Warning.Render(hold.Describe()))
+	}
+	if err := refinery.ValidateFreezeWindows(eng.Config().FreezeWindows); err != nil {
+		style.PrintWarning("ignoring invalid merge_queue.freeze_windows: %v", err)
+	}
+
 	if len(filtered) == 0 {
 		fmt.Printf("  %s\n", style.Dim.Render("(empty)"))
-		return nil
+		return queueHeldExit(hold)
+	}
+
+	// Show the REVIEW column only for rigs that gate merges on review
+	// (or that have review state from an earlier configuration).
+	showReview := eng.ReviewRequired()
+	for _, item := range scored {
+		if item.fields != nil && item.fields.ReviewState != "" {
+			showReview = true
+		}
 	}
 
 	// Create styled table with SCORE column
-	table := style.NewTable(
-		style.Column{Name: "ID", Width: 12},
-		style.Column{Name: "SCORE", Width: 7, Align: style.AlignRight},
-		style.Column{Name: "PRI", Width: 4},
-		style.Column{Name: "CONVOY", Width: 12},
-		style.Column{Name: "BRANCH", Width: 24},
-		style.Column{Name: "STATUS", Width: 10},
-		style.Column{Name: "AGE", Width: 6, Align: style.AlignRight},
-	)
+	columns := []style.Column{
+		{Name: "ID", Width: 12},
+		{Name: "SCORE", Width: 7, Align: style.AlignRight},
+		{Name: "PRI", Width: 4},
+		{Name: "CONVOY", Width: 12},
+		{Name: "BRANCH", Width: 24},
+		{Name: "STATUS", Width: 10},
+	}
+	if showReview {
+		columns = append(columns, style.Column{Name: "REVIEW", Width: 9})
+	}
+	columns = append(columns, style.Column{Name: "AGE", Width: 6, Align: style.AlignRight})
+	table := style.NewTable(columns...)
 
 	// Add rows using scored items (already sorted by score)
 	for _, item := range scored {
@@ -163,6 +199,8 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		if issue.Status == "open" {
 			if len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0 {
 				displayStatus = "blocked"
+			} else if hold != nil {
+				displayStatus = "held"
 			} else {
 				displayStatus = "ready"
 			}
@@ -175,6 +213,8 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			styledStatus = style.Success.Render("ready")
 		case "in_progress":
 			styledStatus = style.Warning.Render("active")
+		case "held":
+			styledStatus = style.Warning.Render("held")
 		case "blocked":
 			styledStatus = style.Dim.Render("blocked")
 		case "closed":
@@ -207,8 +247,13 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			priority = style.Warning.Render(priority)
 		}
 
-		// Format score
+		// Format score, marking manual overrides (gt mq bump)
 		scoreStr := fmt.Sprintf("%.1f", item.score)
+		if fields != nil && fields.ScoreAdjust > 0 {
+			scoreStr += "↑"
+		} else if fields != nil && fields.ScoreAdjust < 0 {
+			scoreStr += "↓"
+		}
 
 		// Calculate age
 		age := formatMRAge(issue.CreatedAt)
@@ -219,7 +264,12 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			displayID = displayID[:12]
 		}
 
-		table.AddRow(displayID, scoreStr, priority, convoyDisplay, branch, styledStatus, style.Dim.Render(age))
+		row := []string{displayID, scoreStr, priority, convoyDisplay, branch, styledStatus}
+		if showReview {
+			row = append(row, formatReviewStatus(eng.ReviewStatus(fields)))
+		}
+		row = append(row, style.Dim.Render(age))
+		table.AddRow(row...)
 	}
 
 	fmt.Print(table.Render())
@@ -241,9 +291,32 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	return queueHeldExit(hold)
+}
+
+// queueHeldExit is gt mq list's result: exit status 2 while the queue is
+// held, so the patrol can tell it must not merge.
+func queueHeldExit(hold *refinery.QueuePause) error {
+	if hold != nil {
+		return NewSilentExit(2)
+	}
 	return nil
 }
 
+// formatReviewStatus styles a review status from Engineer.ReviewStatus.
+func formatReviewStatus(status string) string {
+	switch status {
+	case refinery.ReviewApproved:
+		return style.Success.Render(status)
+	case refinery.ReviewChangesRequested:
+		return style.Error.Render("changes")
+	case "stale", refinery.ReviewPending:
+		return style.Warning.Render(status)
+	default:
+		return style.Dim.Render(status)
+	}
+}
+
 // formatMRAge formats the age of an MR from its created_at timestamp.
 func formatMRAge(createdAt string) string {
 	t, err := time.Parse(time.RFC3339, createdAt)
@@ -298,6 +371,7 @@ func calculateMRScore(issue *beads.Issue, fields *beads.MRFields, now time.Time)
 	// Add fields from MR metadata if available
 	if fields != nil {
 		input.RetryCount = fields.RetryCount
+		input.Adjustment = fields.ScoreAdjust
 
 		// Parse convoy created at if available
 		if fields.ConvoyCreatedAt != "" {
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ pause command flags
+var mqPauseReason string
+
+var mqPauseCmd = &cobra.Command{
+	Use:   "pause <rig>",
+	Short: "Stop the Refinery merging in a rig",
+	Long: `Pause a rig's merge queue, e.g. on release day or during an incident.
+
+While paused the Refinery reports no ready MRs, so nothing merges. MRs can
+still be submitted and reviewed; they wait in the queue. The pause is
+local state in the wisp layer and lasts until 'gt mq resume'.
+
+For recurring freezes (Fridays after 16:00, weekends), set
+merge_queue.freeze_windows in the rig's settings/config.json instead.
+
+Examples:
+  gt mq pause greenplace --reason "v2.3 release in progress"
+  gt mq pause greenplace -r "incident INC-42: main is broken"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQPause,
+}
+
+var mqResumeCmd = &cobra.Command{
+	Use:   "resume <rig>",
+	Short: "Let the Refinery merge in a rig again",
+	Long: `Resume a paused merge queue.
+
+Lifts a pause set by 'gt mq pause' or by a failed post-merge check.
+Freeze windows from the rig's settings still apply.
+
+Examples:
+  gt mq resume greenplace`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQResume,
+}
+
+func init() {
+	mqPauseCmd.Flags().StringVarP(&mqPauseReason, "reason", "r", "", "Why the queue is paused (required)")
+	_ = mqPauseCmd.MarkFlagRequired("reason")
+
+	mqCmd.AddCommand(mqPauseCmd)
+	mqCmd.AddCommand(mqResumeCmd)
+}
+
+func runMQPause(cmd *cobra.Command, args []string) error {
+	_, r, rigName, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+
+	by := detectSender()
+	if err := refinery.PauseQueue(filepath.Dir(r.Path), rigName, mqPauseReason, by, ""); err != nil {
+		return fmt.Errorf("pausing merge queue: %w", err)
+	}
+
+	fmt.Printf("%s Paused merge queue for %s\n", style.Bold.Render("⏸"), rigName)
+	fmt.Printf("  Reason: %s\n", mqPauseReason)
+	fmt.Printf("  %s\n", style.Dim.Render("Resume with: gt mq resume "+rigName))
+	return nil
+}
+
+func runMQResume(cmd *cobra.Command, args []string) error {
+	_, r, rigName, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+
+	townRoot := filepath.Dir(r.Path)
+	pause := refinery.GetQueuePause(townRoot, rigName)
+	if err := refinery.ResumeQueue(townRoot, rigName); err != nil {
+		return fmt.Errorf("resuming merge queue: %w", err)
+	}
+
+	if pause == nil {
+		fmt.Printf("%s Merge queue for %s was not paused\n", style.Dim.Render("ℹ"), rigName)
+	} else {
+		fmt.Printf("%s Resumed merge queue for %s\n", style.Bold.Render("▶"), rigName)
+		fmt.Printf("  %s\n", style.Dim.Render("Was "+pause.Describe()))
+	}
+
+	if hold := refinery.NewEngineer(r).QueueHold(time.Now()); hold != nil {
+		fmt.Printf("  %s %s\n", style.Warning.Render("Still held:"), hold.Describe())
+	}
+	return nil
+}
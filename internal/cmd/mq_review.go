@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// MQ review command flags
+var mqRequestChangesMessage string
+
+var mqApproveCmd = &cobra.Command{
+	Use:   "approve <rig> <mr-id>",
+	Short: "Approve a merge request for merging",
+	Long: `Approve a merge request in a rig that requires code review.
+
+When merge_queue.require_review is set in the rig's settings/config.json,
+the Refinery only merges MRs that carry an approval. The approval is pinned
+to the branch head at the time of approval: if the branch moves afterwards,
+the MR goes back to review.
+
+Approvals come from humans (this command) or from reviewer polecats running
+the review formula on the MR's review task. Workers cannot approve their
+own MRs.
+
+Examples:
+  gt mq approve greenplace gp-mr-abc123`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQApprove,
+}
+
+var mqRequestChangesCmd = &cobra.Command{
+	Use:   "request-changes <rig> <mr-id>",
+	Short: "Send a merge request back for rework",
+	Long: `Request changes on a merge request under code review.
+
+Creates a rework task with the feedback and blocks the MR on it, so the
+queue moves on. The Witness receives a REWORK_REQUEST so a live worker is
+notified. Once the rework task closes, the MR goes back to review.
+
+Examples:
+  gt mq request-changes greenplace gp-mr-abc123 -m "Handle the nil config case in Load"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQRequestChanges,
+}
+
+var mqRequestReviewsCmd = &cobra.Command{
+	Use:   "request-reviews <rig>",
+	Short: "Create review tasks for merge requests awaiting review",
+	Long: `Create review tasks for merge requests that need a review.
+
+For rigs with merge_queue.require_review, every open MR without a current
+approval, an open review task or open rework gets a review task naming the
+review formula. The Refinery runs this each patrol cycle; it does nothing
+for rigs that don't require review.
+
+Examples:
+  gt mq request-reviews greenplace`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQRequestReviews,
+}
+
+func init() {
+	mqRequestChangesCmd.Flags().StringVarP(&mqRequestChangesMessage, "message", "m", "", "What needs to change (required)")
+	_ = mqRequestChangesCmd.MarkFlagRequired("message")
+
+	mqCmd.AddCommand(mqApproveCmd)
+	mqCmd.AddCommand(mqRequestChangesCmd)
+	mqCmd.AddCommand(mqRequestReviewsCmd)
+}
+
+func runMQApprove(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	reviewer := detectSender()
+	if err := refinery.NewEngineer(r).ApproveMR(mrID, reviewer); err != nil {
+		if errors.Is(err, refinery.ErrSelfReview) {
+			return fmt.Errorf("%s cannot approve %s: %w", reviewer, mrID, err)
+		}
+		return fmt.Errorf("approving %s: %w", mrID, err)
+	}
+
+	fmt.Printf("%s Approved %s\n", style.Bold.Render("✓"), mrID)
+	fmt.Printf("  %s\n", style.Dim.Render("The Refinery merges it once nothing else blocks it"))
+	return nil
+}
+
+func runMQRequestChanges(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	reviewer := detectSender()
+	taskID, err := refinery.NewEngineer(r).RequestChanges(mrID, reviewer, mqRequestChangesMessage)
+	if err != nil {
+		if errors.Is(err, refinery.ErrSelfReview) {
+			return fmt.Errorf("%s cannot review %s: %w", reviewer, mrID, err)
+		}
+		return fmt.Errorf("requesting changes on %s: %w", mrID, err)
+	}
+
+	fmt.Printf("%s Requested changes on %s\n", style.Bold.Render("✓"), mrID)
+	fmt.Printf("  Rework task: %s\n", taskID)
+	fmt.Printf("  %s\n", style.Dim.Render("The MR is blocked until the rework task closes"))
+	return nil
+}
+
+func runMQRequestReviews(cmd *cobra.Command, args []string) error {
+	_, r, _, err := getRefineryManager(args[0])
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if !eng.ReviewRequired() {
+		fmt.Printf("%s %s does not require review\n", style.Dim.Render("○"), args[0])
+		return nil
+	}
+	created, err := eng.RequestReviews()
+	if err != nil {
+		return fmt.Errorf("requesting reviews: %w", err)
+	}
+	if len(created) == 0 {
+		fmt.Printf("%s No merge requests need a new review\n", style.Dim.Render("○"))
+		return nil
+	}
+	fmt.Printf("%s Requested review for %d MR(s)\n", style.Bold.Render("🔍"), len(created))
+	return nil
+}
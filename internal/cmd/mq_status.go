@@ -184,6 +184,19 @@ func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
 		if mrFields.CloseReason != "" {
 			fmt.Printf("   Close Reason: %s\n", mrFields.CloseReason)
 		}
+		if mrFields.ReviewState != "" {
+			review := mrFields.ReviewState
+			if mrFields.ReviewedBy != "" {
+				review += " by " + mrFields.ReviewedBy
+			}
+			fmt.Printf("   Review:       %s\n", review)
+		}
+		if mrFields.ReviewTaskID != "" {
+			fmt.Printf("   Review Task:  %s\n", mrFields.ReviewTaskID)
+		}
+		if mrFields.LastFailure != "" {
+			fmt.Printf("   Last Failure: %s %s\n", mrFields.LastFailure, formatTimeAgo(mrFields.LastFailureAt))
+		}
 	}
 
 	// Dependencies (what this MR is waiting on)
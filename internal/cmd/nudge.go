@@ -182,6 +182,7 @@ func runNudge(cmd *cobra.Command, args []string) error {
 		}
 
 		var sessionName string
+		var sessions session.Backend = t
 
 		// Check if this is a crew address (polecatName starts with "crew/")
 		if strings.HasPrefix(polecatName, "crew/") {
@@ -195,10 +196,12 @@ func runNudge(cmd *cobra.Command, args []string) error {
 				return err
 			}
 			sessionName = mgr.SessionName(polecatName)
+			// Polecats run on the town's session backend
+			sessions = session.NewBackend(townRoot)
 		}
 
 		// Send nudge using the reliable NudgeSession
-		if err := t.NudgeSession(sessionName, message); err != nil {
+		if err := sessions.NudgeSession(sessionName, message); err != nil {
 			return fmt.Errorf("nudging session: %w", err)
 		}
 
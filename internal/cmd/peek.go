@@ -5,7 +5,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
@@ -61,7 +63,7 @@ func runPeek(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	mgr, _, err := getSessionManager(rigName)
+	mgr, r, err := getSessionManager(rigName)
 	if err != nil {
 		return err
 	}
@@ -73,7 +75,8 @@ func runPeek(cmd *cobra.Command, args []string) error {
 	if strings.HasPrefix(polecatName, "crew/") {
 		crewName := strings.TrimPrefix(polecatName, "crew/")
 		sessionID := session.CrewSessionName(rigName, crewName)
-		output, err = mgr.CaptureSession(sessionID, lines)
+		// Crew sessions always run in tmux, whatever the polecat backend
+		output, err = polecat.NewSessionManager(tmux.NewTmux(), r).CaptureSession(sessionID, lines)
 	} else {
 		output, err = mgr.Capture(polecatName, lines)
 	}
@@ -12,12 +12,13 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/cgroup"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/util"
 )
 
@@ -138,6 +139,7 @@ Displays comprehensive information including:
   - Session status (running/stopped, attached/detached)
   - Session creation time
   - Last activity time
+  - Resource usage (memory, CPU, tasks, OOM kills) when limits are configured
 
 Examples:
   gt polecat status greenplace/Toast
@@ -344,8 +346,7 @@ func getPolecatManager(rigName string) (*polecat.Manager, *rig.Rig, error) {
 	}
 
 	polecatGit := git.NewGit(r.Path)
-	t := tmux.NewTmux()
-	mgr := polecat.NewManager(r, polecatGit, t)
+	mgr := polecat.NewManager(r, polecatGit, session.NewBackend(filepath.Dir(r.Path)))
 
 	return mgr, r, nil
 }
@@ -373,13 +374,12 @@ func runPolecatList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Collect polecats from all rigs
-	t := tmux.NewTmux()
 	var allPolecats []PolecatListItem
 
 	for _, r := range rigs {
 		polecatGit := git.NewGit(r.Path)
-		mgr := polecat.NewManager(r, polecatGit, t)
-		polecatMgr := polecat.NewSessionManager(t, r)
+		mgr := polecat.NewManager(r, polecatGit, session.NewBackend(filepath.Dir(r.Path)))
+		polecatMgr := polecat.NewSessionManager(session.NewBackend(filepath.Dir(r.Path)), r)
 
 		polecats, err := mgr.List()
 		if err != nil {
@@ -484,14 +484,13 @@ func runPolecatRemove(cmd *cobra.Command, args []string) error {
 	}
 
 	// Remove each polecat
-	t := tmux.NewTmux()
 	var removeErrors []string
 	removed := 0
 
 	for _, p := range targets {
 		// Check if session is running
 		if !polecatForce {
-			polecatMgr := polecat.NewSessionManager(t, p.r)
+			polecatMgr := polecat.NewSessionManager(session.NewBackend(filepath.Dir(p.r.Path)), p.r)
 			running, _ := polecatMgr.IsRunning(p.polecatName)
 			if running {
 				removeErrors = append(removeErrors, fmt.Sprintf("%s/%s: session is running (stop first or use --force)", p.rigName, p.polecatName))
@@ -632,6 +631,7 @@ type PolecatStatus struct {
 	Windows        int           `json:"windows,omitempty"`
 	CreatedAt      string        `json:"created_at,omitempty"`
 	LastActivity   string        `json:"last_activity,omitempty"`
+	Resources      *cgroup.Usage `json:"resources,omitempty"`
 }
 
 func runPolecatStatus(cmd *cobra.Command, args []string) error {
@@ -652,8 +652,7 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get session info
-	t := tmux.NewTmux()
-	polecatMgr := polecat.NewSessionManager(t, r)
+	polecatMgr := polecat.NewSessionManager(session.NewBackend(filepath.Dir(r.Path)), r)
 	sessInfo, err := polecatMgr.Status(polecatName)
 	if err != nil {
 		// Non-fatal - continue without session info
@@ -676,6 +675,7 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 			SessionID:      sessInfo.SessionID,
 			Attached:       sessInfo.Attached,
 			Windows:        sessInfo.Windows,
+			Resources:      sessInfo.Resources,
 		}
 		if !sessInfo.Created.IsZero() {
 			status.CreatedAt = sessInfo.Created.Format("2006-01-02 15:04:05")
@@ -745,6 +745,10 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 				sessInfo.LastActivity.Format("15:04:05"),
 				style.Dim.Render(ago))
 		}
+
+		if res := sessInfo.Resources; res != nil {
+			printResourceUsage(res)
+		}
 	} else {
 		fmt.Printf("  Status:        %s\n", style.Dim.Render("not running"))
 	}
@@ -752,6 +756,29 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printResourceUsage prints cgroup usage for a session running under limits.
+func printResourceUsage(res *cgroup.Usage) {
+	fmt.Println()
+	fmt.Printf("%s\n", style.Bold.Render("Resources"))
+
+	mem := cgroup.FormatBytes(res.MemoryCurrent)
+	if res.MemoryMax > 0 {
+		mem += " / " + cgroup.FormatBytes(res.MemoryMax)
+	}
+	fmt.Printf("  Memory:        %s\n", mem)
+	fmt.Printf("  CPU Time:      %s\n", res.CPUUsage.Round(time.Second))
+
+	pids := fmt.Sprintf("%d", res.PidsCurrent)
+	if res.PidsMax > 0 {
+		pids += fmt.Sprintf(" / %d", res.PidsMax)
+	}
+	fmt.Printf("  Tasks:         %s\n", pids)
+
+	if res.OOMKills > 0 {
+		fmt.Printf("  OOM Kills:     %s\n", style.Warning.Render(fmt.Sprintf("%d", res.OOMKills)))
+	}
+}
+
 // formatActivityTime returns a human-readable relative time string.
 func formatActivityTime(t time.Time) string {
 	d := time.Since(t)
@@ -1162,7 +1189,6 @@ func runPolecatNuke(cmd *cobra.Command, args []string) error {
 	}
 
 	// Nuke each polecat
-	t := tmux.NewTmux()
 	var nukeErrors []string
 	nuked := 0
 
@@ -1186,7 +1212,7 @@ func runPolecatNuke(cmd *cobra.Command, args []string) error {
 		}
 
 		// Step 1: Kill session (force mode - no graceful shutdown)
-		polecatMgr := polecat.NewSessionManager(t, p.r)
+		polecatMgr := polecat.NewSessionManager(session.NewBackend(filepath.Dir(p.r.Path)), p.r)
 		running, _ := polecatMgr.IsRunning(p.polecatName)
 		if running {
 			if err := polecatMgr.Stop(p.polecatName, true); err != nil {
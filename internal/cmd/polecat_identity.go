@@ -16,8 +16,8 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 // Polecat identity command flags
@@ -192,6 +192,9 @@ type CVSummary struct {
 	WorkTypes        map[string]int   `json:"work_types,omitempty"`
 	AvgCompletionMin int              `json:"avg_completion_minutes,omitempty"`
 	FirstPassRate    float64          `json:"first_pass_rate,omitempty"`
+	Runtime          string           `json:"runtime,omitempty"`
+	Model            string           `json:"model,omitempty"`
+	ModelRoute       string           `json:"model_route,omitempty"`
 	RecentWork       []RecentWorkItem `json:"recent_work,omitempty"`
 }
 
@@ -221,8 +224,7 @@ func runPolecatIdentityAdd(cmd *cobra.Command, args []string) error {
 	// Generate name if not provided
 	if polecatName == "" {
 		polecatGit := git.NewGit(r.Path)
-		t := tmux.NewTmux()
-		mgr := polecat.NewManager(r, polecatGit, t)
+		mgr := polecat.NewManager(r, polecatGit, session.NewBackend(filepath.Dir(r.Path)))
 		polecatName, err = mgr.AllocateName()
 		if err != nil {
 			return fmt.Errorf("generating polecat name: %w", err)
@@ -276,8 +278,7 @@ func runPolecatIdentityList(cmd *cobra.Command, args []string) error {
 
 	// Filter for polecat beads in this rig
 	identities := []IdentityInfo{} // Initialize to empty slice (not nil) for JSON
-	t := tmux.NewTmux()
-	polecatMgr := polecat.NewSessionManager(t, r)
+	polecatMgr := polecat.NewSessionManager(session.NewBackend(filepath.Dir(r.Path)), r)
 
 	for id, issue := range agentBeads {
 		// Parse the bead ID to check if it's a polecat for this rig
@@ -295,7 +296,7 @@ func runPolecatIdentityList(cmd *cobra.Command, args []string) error {
 
 		// Check if worktree exists
 		worktreeExists := false
-		mgr := polecat.NewManager(r, nil, t)
+		mgr := polecat.NewManager(r, nil, session.NewBackend(filepath.Dir(r.Path)))
 		if p, err := mgr.Get(name); err == nil && p != nil {
 			worktreeExists = true
 		}
@@ -395,9 +396,8 @@ func runPolecatIdentityShow(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check worktree and session
-	t := tmux.NewTmux()
-	polecatMgr := polecat.NewSessionManager(t, r)
-	mgr := polecat.NewManager(r, nil, t)
+	polecatMgr := polecat.NewSessionManager(session.NewBackend(filepath.Dir(r.Path)), r)
+	mgr := polecat.NewManager(r, nil, session.NewBackend(filepath.Dir(r.Path)))
 
 	worktreeExists := false
 	var clonePath string
@@ -507,6 +507,16 @@ func runPolecatIdentityShow(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Issues completed: %s\n", style.Success.Render(fmt.Sprintf("%d", cv.IssuesCompleted)))
 	fmt.Printf("  Issues failed:    %s\n", formatCountStyled(cv.IssuesFailed, style.Error))
 	fmt.Printf("  Issues abandoned: %s\n", formatCountStyled(cv.IssuesAbandoned, style.Warning))
+	if cv.Runtime != "" {
+		runtime := cv.Runtime
+		if cv.Model != "" {
+			runtime += " (" + cv.Model + ")"
+		}
+		if cv.ModelRoute != "" {
+			runtime += style.Dim.Render(" via route " + cv.ModelRoute)
+		}
+		fmt.Printf("  Runtime:          %s\n", runtime)
+	}
 
 	// Language stats
 	if len(cv.Languages) > 0 {
@@ -582,8 +592,7 @@ func runPolecatIdentityRename(cmd *cobra.Command, args []string) error {
 	}
 
 	// Safety check: no active session
-	t := tmux.NewTmux()
-	polecatMgr := polecat.NewSessionManager(t, r)
+	polecatMgr := polecat.NewSessionManager(session.NewBackend(filepath.Dir(r.Path)), r)
 	running, _ := polecatMgr.IsRunning(oldName)
 	if running {
 		return fmt.Errorf("cannot rename: polecat session %s is running", oldName)
@@ -653,8 +662,7 @@ func runPolecatIdentityRemove(cmd *cobra.Command, args []string) error {
 		var reasons []string
 
 		// Check for active session
-		t := tmux.NewTmux()
-		polecatMgr := polecat.NewSessionManager(t, r)
+		polecatMgr := polecat.NewSessionManager(session.NewBackend(filepath.Dir(r.Path)), r)
 		running, _ := polecatMgr.IsRunning(polecatName)
 		if running {
 			reasons = append(reasons, "session is running")
@@ -725,12 +733,18 @@ func buildCVSummary(rigPath, rigName, polecatName, identityBeadID, clonePath str
 
 	// Get agent bead info for creation date
 	bd := beads.New(beadsQueryPath)
-	agentBead, _, err := bd.GetAgentBead(identityBeadID)
+	agentBead, agentFields, err := bd.GetAgentBead(identityBeadID)
 	if err == nil && agentBead != nil {
 		if agentBead.CreatedAt != "" && len(agentBead.CreatedAt) >= 10 {
 			cv.Created = agentBead.CreatedAt[:10] // Just the date part
 		}
 	}
+	// Runtime the current session was started with (recorded by gt sling)
+	if agentFields != nil {
+		cv.Runtime = agentFields.Runtime
+		cv.Model = agentFields.Model
+		cv.ModelRoute = agentFields.ModelRoute
+	}
 
 	// Count sessions from checkpoint files (session history)
 	cv.Sessions = countPolecatSessions(rigPath, polecatName)
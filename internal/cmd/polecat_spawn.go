@@ -12,8 +12,8 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -24,6 +24,9 @@ type SpawnedPolecatInfo struct {
 	ClonePath   string // Path to polecat's git worktree
 	SessionName string // Tmux session name (e.g., "gt-gastown-p-Toast")
 	Pane        string // Tmux pane ID
+	Agent       string // Agent the session runs (e.g., "claude", "gemini")
+	Model       string // Model selected for the session (empty for agent default)
+	Route       string // Model route that selected Agent/Model (empty if none)
 }
 
 // AgentID returns the agent identifier (e.g., "gastown/polecats/Toast")
@@ -38,6 +41,8 @@ type SlingSpawnOptions struct {
 	Create   bool   // Create polecat if it doesn't exist (currently always true for sling)
 	HookBead string // Bead ID to set as hook_bead at spawn time (atomic assignment)
 	Agent    string // Agent override for this spawn (e.g., "gemini", "codex", "claude-haiku")
+	Model    string // Model for the agent (passed via its model flag)
+	Route    string // Model route that chose Agent/Model/Account, recorded on the agent bead
 }
 
 // SpawnPolecatForSling creates a fresh polecat and optionally starts its session.
@@ -66,8 +71,7 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 
 	// Get polecat manager (with tmux for session-aware allocation)
 	polecatGit := git.NewGit(r.Path)
-	t := tmux.NewTmux()
-	polecatMgr := polecat.NewManager(r, polecatGit, t)
+	polecatMgr := polecat.NewManager(r, polecatGit, session.NewBackend(filepath.Dir(r.Path)))
 
 	// Allocate a new polecat name
 	polecatName, err := polecatMgr.AllocateName()
@@ -126,7 +130,7 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 	}
 
 	// Start session (reuse tmux from manager)
-	polecatSessMgr := polecat.NewSessionManager(t, r)
+	polecatSessMgr := polecat.NewSessionManager(session.NewBackend(filepath.Dir(r.Path)), r)
 
 	// Check if already running
 	running, _ := polecatSessMgr.IsRunning(polecatName)
@@ -135,8 +139,8 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		startOpts := polecat.SessionStartOptions{
 			RuntimeConfigDir: claudeConfigDir,
 		}
-		if opts.Agent != "" {
-			cmd, err := config.BuildPolecatStartupCommandWithAgentOverride(rigName, polecatName, r.Path, "", opts.Agent)
+		if opts.Agent != "" || opts.Model != "" {
+			cmd, err := config.BuildPolecatStartupCommandWithModel(rigName, polecatName, r.Path, "", opts.Agent, opts.Model)
 			if err != nil {
 				return nil, err
 			}
@@ -156,6 +160,14 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 
 	fmt.Printf("%s Polecat %s spawned\n", style.Bold.Render("✓"), polecatName)
 
+	// Record the runtime on the agent bead so the CV can compare runtimes
+	agentName := opts.Agent
+	if agentName == "" {
+		agentName, _ = config.ResolveRoleAgentName("polecat", townRoot, r.Path)
+	}
+	agentID := fmt.Sprintf("%s/polecats/%s", rigName, polecatName)
+	updateAgentRuntime(agentID, polecatObj.ClonePath, agentName, opts.Model, opts.Route)
+
 	// Log spawn event to activity feed
 	_ = events.LogFeed(events.TypeSpawn, "gt", events.SpawnPayload(rigName, polecatName))
 
@@ -165,6 +177,9 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		ClonePath:   polecatObj.ClonePath,
 		SessionName: sessionName,
 		Pane:        pane,
+		Agent:       agentName,
+		Model:       opts.Model,
+		Route:       opts.Route,
 	}, nil
 }
 
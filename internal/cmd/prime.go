@@ -211,6 +211,7 @@ func runPrime(cmd *cobra.Command, args []string) error {
 
 	// Output handoff content if present
 	outputHandoffContent(ctx)
+	outputStructuredHandoff(ctx)
 
 	// Output attachment status (for autonomous work detection)
 	outputAttachmentStatus(ctx)
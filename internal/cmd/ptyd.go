@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/ptyd"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var ptydCmd = &cobra.Command{
+	Use:     "ptyd",
+	GroupID: GroupServices,
+	Short:   "Manage the built-in pty session supervisor",
+	RunE:    requireSubcommand,
+	Long: `Manage the pty session supervisor, Gas Town's tmux-free session backend.
+
+With "session_backend": "pty" in the town's settings/config.json, agent
+sessions run under this daemon instead of tmux: each agent gets a
+pseudo-terminal with a scrollback buffer, and 'gt peek', 'gt nudge',
+'gt session capture' and 'gt session at' work as with tmux (detach from
+an attached session with Ctrl-]). Use it on CI containers and servers
+without tmux.
+
+The daemon starts on demand when the first session is created and listens
+on daemon/ptyd.sock. Stopping it kills every session it runs.`,
+}
+
+var ptydStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the pty supervisor",
+	RunE:  runPtydStart,
+}
+
+var ptydStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the pty supervisor and its sessions",
+	RunE:  runPtydStop,
+}
+
+var ptydStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the pty supervisor and its sessions",
+	RunE:  runPtydStatus,
+}
+
+var ptydRunCmd = &cobra.Command{
+	Use:    "run",
+	Short:  "Run the pty supervisor in the foreground (internal)",
+	Hidden: true,
+	RunE:   runPtydRun,
+}
+
+func init() {
+	ptydCmd.AddCommand(ptydStartCmd)
+	ptydCmd.AddCommand(ptydStopCmd)
+	ptydCmd.AddCommand(ptydStatusCmd)
+	ptydCmd.AddCommand(ptydRunCmd)
+
+	rootCmd.AddCommand(ptydCmd)
+}
+
+func runPtydStart(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	c := ptyd.NewClient(ptyd.SocketPath(townRoot))
+	if c.IsRunning() {
+		fmt.Printf("%s pty supervisor already running\n", style.Bold.Render("●"))
+		return nil
+	}
+	if err := ptyd.StartDaemon(townRoot); err != nil {
+		return fmt.Errorf("starting pty supervisor: %w", err)
+	}
+	fmt.Printf("%s pty supervisor started\n", style.Bold.Render("✓"))
+	if session.BackendName(townRoot) != session.BackendPTY {
+		fmt.Printf("  %s\n", style.Dim.Render(`Agents still use tmux; set "session_backend": "pty" in settings/config.json`))
+	}
+	return nil
+}
+
+func runPtydStop(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	c := ptyd.NewClient(ptyd.SocketPath(townRoot))
+	if !c.IsRunning() {
+		return fmt.Errorf("pty supervisor is not running")
+	}
+	sessions, _ := c.ListSessions()
+	if err := c.Shutdown(); err != nil {
+		return fmt.Errorf("stopping pty supervisor: %w", err)
+	}
+	fmt.Printf("%s pty supervisor stopped (%d session(s) killed)\n", style.Bold.Render("✓"), len(sessions))
+	return nil
+}
+
+func runPtydStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	fmt.Printf("Session backend: %s\n", style.Bold.Render(session.BackendName(townRoot)))
+
+	c := ptyd.NewClient(ptyd.SocketPath(townRoot))
+	if !c.IsRunning() {
+		fmt.Printf("%s pty supervisor is %s\n", style.Dim.Render("○"), "not running")
+		return nil
+	}
+	sessions, err := c.ListSessions()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s pty supervisor is %s (%d session(s))\n", style.Bold.Render("●"), style.Bold.Render("running"), len(sessions))
+	for _, name := range sessions {
+		fmt.Printf("  %s\n", name)
+	}
+	return nil
+}
+
+func runPtydRun(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	srv := ptyd.NewServer(ptyd.SocketPath(townRoot))
+	if err := srv.Listen(); err != nil {
+		return err
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		_ = srv.Close()
+	}()
+
+	fmt.Printf("ptyd listening on %s\n", ptyd.SocketPath(townRoot))
+	return srv.Serve()
+}
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
@@ -217,6 +219,40 @@ Examples:
 
 var refineryBlockedJSON bool
 
+var refineryMergeCmd = &cobra.Command{
+	Use:   "merge <mr-id>",
+	Short: "Merge an MR through the merge queue gates",
+	Long: `Merge a merge request into its target branch.
+
+This is how the patrol lands work. The MR goes through the merge queue's
+gates before anything reaches the remote:
+- Hold: nothing merges while the queue is paused or frozen
+- Review: on rigs with require_review, the MR needs a current approval
+- Conflicts, and tests when run_tests is configured
+- Secret scan: the branch must not add secrets (see .gt-secrets-allow)
+
+On rigs that land through pull requests (merge_queue.landing), the merge is
+pushed to a landing branch and lands once the PR's checks pass. A PR that is
+still running is left open; run the command again on a later pass to check
+on it.
+
+On success the MR bead and its source issue are closed. An MR that is not
+merged stays in the queue. With merge_queue.post_merge configured, the
+target branch is checked once the merge lands; if the check fails the merge
+is reverted, the source issue reopened and the worker told.
+
+Exit codes:
+  0  merged
+  1  merge failed, or the post-merge check failed (see the output for why)
+  2  not merged yet: waiting for review or for the landing PR, or the
+     merge queue is held (gt mq pause, a freeze window)
+
+Examples:
+  gt refinery merge gt-abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRefineryMerge,
+}
+
 func init() {
 	// Start flags
 	refineryStartCmd.Flags().BoolVar(&refineryForeground, "foreground", false, "Run in foreground (default: background)")
@@ -255,6 +291,7 @@ func init() {
 	refineryCmd.AddCommand(refineryUnclaimedCmd)
 	refineryCmd.AddCommand(refineryReadyCmd)
 	refineryCmd.AddCommand(refineryBlockedCmd)
+	refineryCmd.AddCommand(refineryMergeCmd)
 
 	rootCmd.AddCommand(refineryCmd)
 }
@@ -681,6 +718,7 @@ func runRefineryReady(cmd *cobra.Command, args []string) error {
 
 	// Create engineer for the rig (it has beads access for status checking)
 	eng := refinery.NewEngineer(r)
+	recheckPostMergePause(eng, refineryReadyJSON)
 
 	// Get ready MRs (unclaimed AND unblocked)
 	ready, err := eng.ListReadyMRs()
@@ -698,6 +736,10 @@ func runRefineryReady(cmd *cobra.Command, args []string) error {
 	// Human-readable output
 	fmt.Printf("%s Ready MRs for '%s':\n\n", style.Bold.Render("🚀"), rigName)
 
+	if hold := eng.QueueHold(time.Now()); hold != nil {
+		fmt.Printf("  %s %s\n", style.Warning.Render("⏸ Merge queue"), hold.Describe())
+		return nil
+	}
 	if len(ready) == 0 {
 		fmt.Printf("  %s\n", style.Dim.Render("(none ready)"))
 		return nil
@@ -712,6 +754,23 @@ func runRefineryReady(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// recheckPostMergePause resumes a queue paused by a failing post-merge
+// check once the target branch passes again. quiet suppresses the notice
+// (for JSON output).
+func recheckPostMergePause(eng *refinery.Engineer, quiet bool) {
+	pause := eng.QueuePause()
+	if pause == nil || pause.By != refinery.PausedByPostMerge {
+		return
+	}
+	eng.SetOutput(os.Stderr)
+	defer eng.SetOutput(os.Stdout)
+	if resumed, err := eng.RecheckQueuePause(context.Background()); err != nil {
+		style.PrintWarning("could not recheck %s: %v", pause.Target, err)
+	} else if resumed && !quiet {
+		fmt.Printf("%s %s passes again; merge queue resumed\n\n", style.Success.Render("✓"), pause.Target)
+	}
+}
+
 func runRefineryBlocked(cmd *cobra.Command, args []string) error {
 	rigName := ""
 	if len(args) > 0 {
@@ -758,3 +817,57 @@ func runRefineryBlocked(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runRefineryMerge(cmd *cobra.Command, args []string) error {
+	mrID := args[0]
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigName, err := inferRigFromCwd(townRoot)
+	if err != nil {
+		return fmt.Errorf("could not determine rig: %w", err)
+	}
+
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	// A held queue (gt mq pause, a failed post-merge check, a freeze
+	// window) merges nothing
+	recheckPostMergePause(eng, false)
+	if hold := eng.QueueHold(time.Now()); hold != nil {
+		fmt.Printf("%s %s not merged: merge queue %s\n", style.Warning.Render("⏸"), mrID, hold.Describe())
+		return NewSilentExit(2)
+	}
+
+	mr, err := eng.GetMRInfo(mrID)
+	if err != nil {
+		return err
+	}
+
+	result := eng.ProcessMRInfo(context.Background(), mr)
+	if result.Success {
+		if !eng.HandleMRInfoSuccess(mr, result) {
+			fmt.Printf("%s %s merged, but the post-merge check failed on %s\n", style.Error.Render("✗"), mrID, mr.Target)
+			return NewSilentExit(1)
+		}
+		fmt.Printf("%s Merged %s into %s\n", style.Bold.Render("✓"), mrID, mr.Target)
+		return nil
+	}
+
+	eng.HandleMRInfoFailure(mr, result)
+	if result.NeedsReview || result.LandingPending {
+		fmt.Printf("%s %s not merged: %s\n", style.Warning.Render("⏸"), mrID, result.Error)
+		return NewSilentExit(2)
+	}
+	fmt.Printf("%s %s not merged: %s\n", style.Error.Render("✗"), mrID, result.Error)
+	return NewSilentExit(1)
+}
@@ -52,6 +52,7 @@ var beadsExemptCommands = map[string]bool{
 	"config":     true,
 	"install":    true,
 	"tap":        true,
+	"guard":      true, // gt tap guard runs on every tool call
 	"dnd":        true,
 }
 
@@ -59,7 +59,10 @@ executor is an LLM, it interprets these instructions naturally.
 
 Formula Slinging:
   gt sling mol-release mayor/           # Cook + wisp + attach + nudge
-  gt sling towers-of-hanoi --var disks=3
+  gt sling towers-of-hanoi --var target_peg=B
+
+Formula variables are checked against the formula's declared types; missing
+required ones are prompted for in a terminal (see gt formula show <name>).
 
 Formula-on-Bead (--on flag):
   gt sling mol-review --on gt-abc       # Apply formula to existing work
@@ -194,6 +197,16 @@ func runSling(cmd *cobra.Command, args []string) error {
 	var hookWorkDir string        // Working directory for running bd hook commands
 	var hookSetAtomically bool    // True if hook was set during polecat spawn (skip redundant update)
 
+	// Set if a polecat was spawned for this sling (its runtime goes in the sling event)
+	var spawned *SpawnedPolecatInfo
+
+	// Formula that will be applied if the work goes to a polecat (see Issue #288 below).
+	// Model routes can match on it.
+	routeFormula := formulaName
+	if routeFormula == "" && !slingHookRawBead {
+		routeFormula = "mol-polecat-work"
+	}
+
 	if len(args) > 1 {
 		target := args[1]
 
@@ -230,6 +243,10 @@ func runSling(cmd *cobra.Command, args []string) error {
 			if slingDryRun {
 				// Dry run - just indicate what would happen
 				fmt.Printf("Would spawn fresh polecat in rig '%s'\n", rigName)
+				spawnOpts := SlingSpawnOptions{Account: slingAccount, Agent: slingAgent}
+				if err := applyModelRoute(&spawnOpts, townRoot, rigName, beadID, routeFormula); err != nil {
+					return err
+				}
 				targetAgent = fmt.Sprintf("%s/polecats/<new>", rigName)
 				targetPane = "<new-pane>"
 			} else {
@@ -242,10 +259,14 @@ func runSling(cmd *cobra.Command, args []string) error {
 					HookBead: beadID, // Set atomically at spawn time
 					Agent:    slingAgent,
 				}
+				if err := applyModelRoute(&spawnOpts, townRoot, rigName, beadID, routeFormula); err != nil {
+					return err
+				}
 				spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
 					return fmt.Errorf("spawning polecat: %w", spawnErr)
 				}
+				spawned = spawnInfo
 				targetAgent = spawnInfo.AgentID()
 				targetPane = spawnInfo.Pane
 				hookWorkDir = spawnInfo.ClonePath // Run bd commands from polecat's worktree
@@ -274,10 +295,14 @@ func runSling(cmd *cobra.Command, args []string) error {
 							HookBead: beadID,
 							Agent:    slingAgent,
 						}
+						if err := applyModelRoute(&spawnOpts, townRoot, rigName, beadID, routeFormula); err != nil {
+							return err
+						}
 						spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 						if spawnErr != nil {
 							return fmt.Errorf("spawning polecat to replace dead polecat: %w", spawnErr)
 						}
+						spawned = spawnInfo
 						targetAgent = spawnInfo.AgentID()
 						targetPane = spawnInfo.Pane
 						hookWorkDir = spawnInfo.ClonePath
@@ -473,7 +498,11 @@ func runSling(cmd *cobra.Command, args []string) error {
 
 	// Log sling event to activity feed
 	actor := detectActor()
-	_ = events.LogFeed(events.TypeSling, actor, events.SlingPayload(beadID, targetAgent))
+	slingPayload := events.SlingPayload(beadID, targetAgent)
+	if spawned != nil {
+		slingPayload = events.SlingRuntimePayload(beadID, targetAgent, spawned.Agent, spawned.Model, spawned.Route, formulaName)
+	}
+	_ = events.LogFeed(events.TypeSling, actor, slingPayload)
 
 	// Update agent bead's hook_bead field (ZFC: agents track their current work)
 	// Skip if hook was already set atomically during polecat spawn - avoids "agent bead not found"
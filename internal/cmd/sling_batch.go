@@ -73,6 +73,11 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 			HookBead: beadID, // Set atomically at spawn time
 			Agent:    slingAgent,
 		}
+		if err := applyModelRoute(&spawnOpts, townRoot, rigName, beadID, formulaName); err != nil {
+			results = append(results, slingResult{beadID: beadID, success: false, errMsg: err.Error()})
+			fmt.Printf("  %s Could not route bead: %v\n", style.Dim.Render("✗"), err)
+			continue
+		}
 		spawnInfo, err := SpawnPolecatForSling(rigName, spawnOpts)
 		if err != nil {
 			results = append(results, slingResult{beadID: beadID, success: false, errMsg: err.Error()})
@@ -137,7 +142,12 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 
 		// Log sling event
 		actor := detectActor()
-		_ = events.LogFeed(events.TypeSling, actor, events.SlingPayload(beadToHook, targetAgent))
+		appliedFormula := ""
+		if attachedMoleculeID != "" {
+			appliedFormula = formulaName
+		}
+		_ = events.LogFeed(events.TypeSling, actor, events.SlingRuntimePayload(beadToHook, targetAgent,
+			spawnInfo.Agent, spawnInfo.Model, spawnInfo.Route, appliedFormula))
 
 		// Update agent bead state
 		updateAgentHookBead(targetAgent, beadToHook, hookWorkDir, townBeadsDir)
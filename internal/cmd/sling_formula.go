@@ -81,6 +81,21 @@ func runSlingFormula(args []string) error {
 	}
 	townBeadsDir := filepath.Join(townRoot, ".beads")
 
+	// Check --var values against the formula's declared variables (prompting
+	// for missing ones in a terminal) before anything is spawned or cooked
+	typed := findTypedFormula(formulaName)
+	formulaVars, err := resolveFormulaVars(typed, slingVars)
+	if err != nil {
+		return err
+	}
+
+	// Formulas with when/for_each/until are expanded for these vars and the
+	// expansion is cooked instead
+	cookName, err := prepareControlFormula(townRoot, formulaName, typed, formulaVars, !slingDryRun)
+	if err != nil {
+		return err
+	}
+
 	// Determine target (self or specified)
 	var target string
 	if len(args) > 1 {
@@ -169,9 +184,9 @@ func runSlingFormula(args []string) error {
 	fmt.Printf("%s Slinging formula %s to %s...\n", style.Bold.Render("🎯"), formulaName, targetAgent)
 
 	if slingDryRun {
-		fmt.Printf("Would cook formula: %s\n", formulaName)
+		fmt.Printf("Would cook formula: %s\n", cookName)
 		fmt.Printf("Would create wisp and pin to: %s\n", targetAgent)
-		for _, v := range slingVars {
+		for _, v := range formulaVars {
 			fmt.Printf("  --var %s\n", v)
 		}
 		fmt.Printf("Would nudge pane: %s\n", targetPane)
@@ -180,7 +195,7 @@ func runSlingFormula(args []string) error {
 
 	// Step 1: Cook the formula (ensures proto exists)
 	fmt.Printf("  Cooking formula...\n")
-	cookArgs := []string{"--no-daemon", "cook", formulaName}
+	cookArgs := []string{"--no-daemon", "cook", cookName}
 	cookCmd := exec.Command("bd", cookArgs...)
 	cookCmd.Stderr = os.Stderr
 	if err := cookCmd.Run(); err != nil {
@@ -189,8 +204,8 @@ func runSlingFormula(args []string) error {
 
 	// Step 2: Create wisp instance (ephemeral)
 	fmt.Printf("  Creating wisp...\n")
-	wispArgs := []string{"--no-daemon", "mol", "wisp", formulaName}
-	for _, v := range slingVars {
+	wispArgs := []string{"--no-daemon", "mol", "wisp", cookName}
+	for _, v := range formulaVars {
 		wispArgs = append(wispArgs, "--var", v)
 	}
 	wispArgs = append(wispArgs, "--json")
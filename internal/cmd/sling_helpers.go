@@ -5,20 +5,28 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
-// beadInfo holds status and assignee for a bead.
+// beadInfo holds status and assignee for a bead, plus the fields model
+// routes match on.
 type beadInfo struct {
-	Title    string `json:"title"`
-	Status   string `json:"status"`
-	Assignee string `json:"assignee"`
+	Title            string   `json:"title"`
+	Status           string   `json:"status"`
+	Assignee         string   `json:"assignee"`
+	Labels           []string `json:"labels,omitempty"`
+	Type             string   `json:"issue_type,omitempty"`
+	Priority         int      `json:"priority"`
+	EstimatedMinutes int      `json:"estimated_minutes,omitempty"`
 }
 
 // verifyBeadExists checks that the bead exists using bd show.
@@ -433,6 +441,82 @@ func updateAgentHookBead(agentID, beadID, workDir, townBeadsDir string) {
 	}
 }
 
+// updateAgentRuntime records the agent, model and model route a polecat was
+// started with on its agent bead, for cost/quality analysis in the CV.
+// Failures are reported as warnings - the polecat runs either way.
+func updateAgentRuntime(agentID, workDir, runtime, model, route string) {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return
+	}
+	agentBeadID := agentIDToBeadID(agentID, townRoot)
+	if agentBeadID == "" {
+		return
+	}
+	bd := beads.New(beads.ResolveHookDir(townRoot, agentBeadID, workDir))
+	if err := bd.UpdateAgentRuntime(agentBeadID, runtime, model, route); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: couldn't record runtime on agent %s: %v\n", agentBeadID, err)
+	}
+}
+
+// applyModelRoute fills in the agent, model and account for a polecat spawned
+// in rigName to work on beadID, from the first model route matching the bead.
+// formula is the formula that will be applied to the work. Explicit --agent
+// and --account flags take precedence over the route.
+func applyModelRoute(opts *SlingSpawnOptions, townRoot, rigName, beadID, formula string) error {
+	routes, err := config.LoadModelRoutes(townRoot, filepath.Join(townRoot, rigName))
+	if err != nil {
+		return fmt.Errorf("loading model routes: %w", err)
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+
+	info, err := getBeadInfo(beadID)
+	if err != nil {
+		return err
+	}
+	route := config.MatchModelRoute(routes, config.RouteBead{
+		Labels:           info.Labels,
+		Type:             info.Type,
+		Priority:         info.Priority,
+		Formula:          formula,
+		EstimatedMinutes: info.EstimatedMinutes,
+	})
+	if route == nil {
+		return nil
+	}
+
+	applied := false
+	// A route's model belongs to its agent, so --agent overrides both
+	if opts.Agent == "" && (route.Agent != "" || route.Model != "") {
+		opts.Agent = route.Agent
+		opts.Model = route.Model
+		applied = true
+	}
+	if opts.Account == "" && route.Account != "" {
+		opts.Account = route.Account
+		applied = true
+	}
+	if !applied {
+		return nil
+	}
+	opts.Route = route.Name
+
+	var parts []string
+	if opts.Agent != "" {
+		parts = append(parts, "agent="+opts.Agent)
+	}
+	if opts.Model != "" {
+		parts = append(parts, "model="+opts.Model)
+	}
+	if opts.Account != "" {
+		parts = append(parts, "account="+opts.Account)
+	}
+	fmt.Printf("%s Routed by %s: %s\n", style.Bold.Render("→"), route.Name, strings.Join(parts, " "))
+	return nil
+}
+
 // wakeRigAgents wakes the witness and refinery for a rig after polecat dispatch.
 // This ensures the patrol agents are ready to monitor and merge.
 func wakeRigAgents(rigName string) {
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/agentstats"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Stats command flags
+var (
+	statsBy    []string
+	statsSince string
+	statsRig   string
+	statsJSON  bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:     "stats",
+	GroupID: GroupDiag,
+	Short:   "Show work analytics",
+	RunE:    requireSubcommand,
+}
+
+var statsAgentsCmd = &cobra.Command{
+	Use:   "agents",
+	Short: "Show agent quality scorecards per runtime, model, formula and rig",
+	Long: `Show how well polecat work goes, grouped by the runtime, model, formula,
+rig or model route it was slung with.
+
+Each gt sling to a polecat is an attempt. Attempts are rebuilt from the
+activity events log and joined with merge-request beads:
+
+  SLUNG      Attempts in the window
+  SUCCESS    Finished (gt done) without the MR being rejected, out of
+             finished + failed (abandoned or rejected); in-flight work is excluded
+  1ST-TRY    Merged MRs that never hit MERGE_FAILED or a conflict
+  CONFLICTS  Merge conflicts hit by the attempts' MRs
+  REWORK     Changes requested in review, or reverted after merge
+  MEDIAN     Median time from sling to gt done
+  ESC/ATT    Escalations raised per attempt
+
+Runtime and model are recorded by gt sling (see model_routes); older
+events show up as "unknown".
+
+Examples:
+  gt stats agents                     # All dimensions, all time
+  gt stats agents --by model          # Only per-model scorecards
+  gt stats agents --since 7d --rig gastown
+  gt stats agents --json              # Machine-readable output`,
+	RunE: runStatsAgents,
+}
+
+func init() {
+	statsAgentsCmd.Flags().StringSliceVar(&statsBy, "by", nil, "Dimensions to show: runtime, model, formula, rig, route (default all)")
+	statsAgentsCmd.Flags().StringVar(&statsSince, "since", "", "Only count work slung since duration (e.g., 24h, 7d)")
+	statsAgentsCmd.Flags().StringVar(&statsRig, "rig", "", "Only count work in this rig")
+	statsAgentsCmd.Flags().BoolVar(&statsJSON, "json", false, "Output as JSON")
+
+	statsCmd.AddCommand(statsAgentsCmd)
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStatsAgents(cmd *cobra.Command, args []string) error {
+	opts := agentstats.Options{Rig: statsRig}
+	for _, by := range statsBy {
+		d := agentstats.Dimension(strings.ToLower(strings.TrimSpace(by)))
+		if !isStatsDimension(d) {
+			return fmt.Errorf("invalid --by %q: want runtime, model, formula, rig or route", by)
+		}
+		opts.Dimensions = append(opts.Dimensions, d)
+	}
+	if statsSince != "" {
+		d, err := parseDuration(statsSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		opts.Since = time.Now().Add(-d)
+	}
+
+	rigs, townRoot, err := getAllRigs()
+	if err != nil {
+		return err
+	}
+
+	evts, err := agentstats.LoadEvents(townRoot)
+	if err != nil {
+		return fmt.Errorf("reading events: %w", err)
+	}
+
+	// Merge requests live in each rig's beads
+	var mrs []*agentstats.MergeRequest
+	for _, r := range rigs {
+		if statsRig != "" && r.Name != statsRig {
+			continue
+		}
+		issues, err := beads.New(r.BeadsPath()).List(beads.ListOptions{
+			Type:     "merge-request",
+			Status:   "all",
+			Priority: -1,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: couldn't list merge requests in %s: %v\n", r.Name, err)
+			continue
+		}
+		for _, issue := range issues {
+			if mr := agentstats.MergeRequestFromIssue(issue); mr != nil {
+				mrs = append(mrs, mr)
+			}
+		}
+	}
+
+	report := agentstats.Compute(evts, mrs, opts)
+
+	if statsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	if report.Attempts == 0 {
+		fmt.Println("No polecat work found in the events log.")
+		return nil
+	}
+
+	fmt.Printf("%s %d polecat attempt(s)\n", style.Bold.Render("Agent scorecards:"), report.Attempts)
+	dims := opts.Dimensions
+	if len(dims) == 0 {
+		dims = agentstats.Dimensions
+	}
+	for _, d := range dims {
+		printScorecards(d, report.Scorecards[d])
+	}
+	return nil
+}
+
+func isStatsDimension(d agentstats.Dimension) bool {
+	for _, known := range agentstats.Dimensions {
+		if d == known {
+			return true
+		}
+	}
+	return false
+}
+
+// printScorecards prints one dimension's scorecards as a table.
+func printScorecards(d agentstats.Dimension, cards []agentstats.Scorecard) {
+	width := len(d)
+	for _, c := range cards {
+		if len(c.Key) > width {
+			width = len(c.Key)
+		}
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("By "+string(d)+":"))
+	fmt.Printf("  %-*s %6s %8s %8s %10s %7s %8s %8s\n", width, strings.ToUpper(string(d)),
+		"SLUNG", "SUCCESS", "1ST-TRY", "CONFLICTS", "REWORK", "MEDIAN", "ESC/ATT")
+	for _, c := range cards {
+		fmt.Printf("  %-*s %6d %8s %8s %10d %7d %8s %8.2f\n", width, c.Key,
+			c.Slung,
+			formatStatsRate(c.SuccessRate, c.Succeeded+c.Failed),
+			formatStatsRate(c.FirstTryMergeRate, c.Merged),
+			c.Conflicts, c.Rework,
+			formatStatsMinutes(c.MedianTimeToDone, c.Done),
+			c.EscalationRate)
+	}
+}
+
+// formatStatsRate formats a rate, or "-" if it has no samples.
+func formatStatsRate(rate float64, samples int) string {
+	if samples == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
+
+// formatStatsMinutes formats a duration in minutes, or "-" if it has no samples.
+func formatStatsMinutes(minutes float64, samples int) string {
+	if samples == 0 {
+		return "-"
+	}
+	d := time.Duration(minutes * float64(time.Minute)).Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
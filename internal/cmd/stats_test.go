@@ -0,0 +1,30 @@
+package cmd
+
+import "testing"
+
+func TestFormatStatsMinutes(t *testing.T) {
+	tests := []struct {
+		minutes float64
+		samples int
+		want    string
+	}{
+		{0, 0, "-"},
+		{42.4, 3, "42m"},
+		{90, 1, "1h30m"},
+		{1505, 2, "25h05m"},
+	}
+	for _, tt := range tests {
+		if got := formatStatsMinutes(tt.minutes, tt.samples); got != tt.want {
+			t.Errorf("formatStatsMinutes(%v, %d) = %q, want %q", tt.minutes, tt.samples, got, tt.want)
+		}
+	}
+}
+
+func TestFormatStatsRate(t *testing.T) {
+	if got := formatStatsRate(0, 0); got != "-" {
+		t.Errorf("rate with no samples = %q, want -", got)
+	}
+	if got := formatStatsRate(0.666, 3); got != "67%" {
+		t.Errorf("rate = %q, want 67%%", got)
+	}
+}
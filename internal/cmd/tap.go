@@ -22,8 +22,8 @@ Subcommands:
 Hook configuration in .claude/settings.json:
   {
     "PreToolUse": [{
-      "matcher": "Bash(gh pr create*)",
-      "hooks": [{"command": "gt tap guard pr-workflow"}]
+      "matcher": "Bash|Edit|Write|MultiEdit|NotebookEdit",
+      "hooks": [{"command": "gt tap guard"}]
     }]
   }
 
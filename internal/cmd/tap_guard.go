@@ -2,10 +2,16 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/guard"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+	"golang.org/x/term"
 )
 
 var tapGuardCmd = &cobra.Command{
@@ -13,20 +19,46 @@ var tapGuardCmd = &cobra.Command{
 	Short: "Block forbidden operations (PreToolUse hook)",
 	Long: `Block forbidden operations via Claude Code PreToolUse hooks.
 
-Guard commands exit with code 2 to BLOCK tool execution when a policy
-is violated. They're called before the tool runs, preventing the
-forbidden operation entirely.
+Reads the tool call (JSON) from stdin and evaluates it against the guard
+rules. Exits with code 2 to BLOCK tool execution when a deny rule matches.
+Warn rules print a message but let the call through.
 
-Available guards:
-  pr-workflow   - Block PR creation and feature branches
+Rules are layered, highest priority first:
+  <rig>/settings/guards.toml    Per-rig overrides
+  <town>/settings/guards.toml   Town-wide rules
+  built-in                      Shipped with gt (see 'gt tap guard list')
+
+A rule with the same name in a higher layer replaces the lower one;
+set 'disabled = true' to turn a rule off. Rules match on tool name,
+command regex, file path globs and role, with allow/deny/warn actions.
+
+Example settings/guards.toml:
+  [[rule]]
+  name = "no-npm-publish"
+  tools = ["Bash"]
+  command = '\bnpm\s+publish\b'
+  roles = ["polecat"]
+  action = "deny"
+  message = "Publishing is done by the release pipeline."
+
+Every block is recorded as an audit event in .events.jsonl.
 
 Example hook configuration:
   {
     "PreToolUse": [{
-      "matcher": "Bash(gh pr create*)",
-      "hooks": [{"command": "gt tap guard pr-workflow"}]
+      "matcher": "Bash|Edit|Write|MultiEdit|NotebookEdit",
+      "hooks": [{"command": "gt tap guard"}]
     }]
   }`,
+	RunE: runTapGuard,
+}
+
+var tapGuardListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List effective guard rules",
+	Long: `List the guard rules in effect for the current town and rig,
+after applying town and rig overrides to the built-in rules.`,
+	RunE: runTapGuardList,
 }
 
 var tapGuardPRWorkflowCmd = &cobra.Command{
@@ -34,6 +66,9 @@ var tapGuardPRWorkflowCmd = &cobra.Command{
 	Short: "Block PR creation and feature branches",
 	Long: `Block PR workflow operations in Gas Town.
 
+Deprecated: use 'gt tap guard', which evaluates all rules including
+pr-workflow. Kept so existing hook configurations keep working.
+
 Gas Town workers push directly to main. PRs add friction that breaks
 the autonomous execution model (GUPP principle).
 
@@ -53,9 +88,32 @@ witness, etc.). Humans running outside Gas Town can still use PRs.`,
 
 func init() {
 	tapCmd.AddCommand(tapGuardCmd)
+	tapGuardCmd.AddCommand(tapGuardListCmd)
 	tapGuardCmd.AddCommand(tapGuardPRWorkflowCmd)
 }
 
+func runTapGuard(cmd *cobra.Command, args []string) error {
+	// Humans running outside Gas Town are never guarded
+	if !isGasTownAgentContext() {
+		return nil
+	}
+
+	rules, err := loadGuardRules()
+	if err != nil {
+		// A broken guards.toml must not wedge every tool call - warn and allow
+		fmt.Fprintf(os.Stderr, "gt tap guard: %v\n", err)
+		return nil
+	}
+
+	input, err := readGuardInput()
+	if err != nil || input == nil {
+		return nil
+	}
+
+	enforceGuardResult(guard.Evaluate(rules, input, guard.RoleFromEnv(os.Getenv("GT_ROLE"))), input)
+	return nil
+}
+
 func runTapGuardPRWorkflow(cmd *cobra.Command, args []string) error {
 	// Check if we're in a Gas Town agent context
 	if !isGasTownAgentContext() {
@@ -63,23 +121,126 @@ func runTapGuardPRWorkflow(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// We're in a Gas Town context - block PR operations
+	rules, err := loadGuardRules()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gt tap guard: %v\n", err)
+		return nil
+	}
+	var prRule *guard.Rule
+	for _, r := range rules {
+		if r.Name == "pr-workflow" {
+			prRule = r
+		}
+	}
+	if prRule == nil {
+		// Disabled by town or rig config
+		return nil
+	}
+
+	input, err := readGuardInput()
+	if err != nil || input == nil {
+		// Legacy hook configs match on the command themselves and send no
+		// parseable input - block unconditionally as before.
+		input = &guard.Input{ToolName: "Bash"}
+		enforceGuardResult(guard.Result{Blocked: &guard.Match{Rule: prRule}}, input)
+		return nil
+	}
+
+	enforceGuardResult(guard.Evaluate([]*guard.Rule{prRule}, input, guard.RoleFromEnv(os.Getenv("GT_ROLE"))), input)
+	return nil
+}
+
+func runTapGuardList(cmd *cobra.Command, args []string) error {
+	rules, err := loadGuardRules()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render("Guard rules (evaluated in order)"))
+	for _, r := range rules {
+		action := fmt.Sprintf("%-6s", r.Action)
+		switch r.Action {
+		case guard.ActionDeny:
+			action = style.Error.Render(action)
+		case guard.ActionWarn:
+			action = style.Warning.Render(action)
+		case guard.ActionAllow:
+			action = style.Success.Render(action)
+		}
+		fmt.Printf("  %-26s %s %s\n", r.Name, action, style.Dim.Render("["+r.Source+"]"))
+		if r.Description != "" {
+			fmt.Printf("  %-26s %s\n", "", style.Dim.Render(r.Description))
+		}
+	}
+	return nil
+}
+
+// loadGuardRules loads the effective rules for the current town and rig.
+func loadGuardRules() ([]*guard.Rule, error) {
+	townRoot, _ := workspace.FindFromCwd()
+	if townRoot == "" {
+		townRoot = os.Getenv("GT_ROOT")
+	}
+	return guard.Load(townRoot, os.Getenv("GT_RIG"))
+}
+
+// readGuardInput reads the PreToolUse payload from stdin.
+// Returns nil if stdin is a terminal or empty.
+func readGuardInput() (*guard.Input, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+	return guard.ParseInput(data)
+}
+
+// enforceGuardResult prints warnings, records audit events, and exits with
+// code 2 if the call was blocked.
+func enforceGuardResult(result guard.Result, input *guard.Input) {
+	actor := detectSenderFallback()
+	target := input.Command()
+	if target == "" {
+		target = input.FilePath()
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "⚠ guard %s: %s\n", w.Rule.Name, guardMessage(w))
+		_ = events.LogAudit(events.TypeGuardWarned, actor,
+			events.GuardPayload(w.Rule.Name, input.ToolName, target, w.Detail))
+	}
+
+	if result.Blocked == nil {
+		return
+	}
+
+	b := result.Blocked
+	_ = events.LogAudit(events.TypeGuardBlocked, actor,
+		events.GuardPayload(b.Rule.Name, input.ToolName, target, b.Detail))
+
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintf(os.Stderr, "❌ BLOCKED by guard %q (%s rule)\n", b.Rule.Name, b.Rule.Source)
 	fmt.Fprintln(os.Stderr, "")
-	fmt.Fprintln(os.Stderr, "╔══════════════════════════════════════════════════════════════════╗")
-	fmt.Fprintln(os.Stderr, "║  ❌ PR WORKFLOW BLOCKED                                          ║")
-	fmt.Fprintln(os.Stderr, "╠══════════════════════════════════════════════════════════════════╣")
-	fmt.Fprintln(os.Stderr, "║  Gas Town workers push directly to main. PRs are forbidden.     ║")
-	fmt.Fprintln(os.Stderr, "║                                                                  ║")
-	fmt.Fprintln(os.Stderr, "║  Instead of:  gh pr create / git checkout -b / git switch -c    ║")
-	fmt.Fprintln(os.Stderr, "║  Do this:     git add . && git commit && git push origin main   ║")
-	fmt.Fprintln(os.Stderr, "║                                                                  ║")
-	fmt.Fprintln(os.Stderr, "║  Why? PRs add friction that breaks autonomous execution.        ║")
-	fmt.Fprintln(os.Stderr, "║  See: ~/gt/docs/PRIMING.md (GUPP principle)                     ║")
-	fmt.Fprintln(os.Stderr, "╚══════════════════════════════════════════════════════════════════╝")
+	fmt.Fprintln(os.Stderr, guardMessage(*b))
 	fmt.Fprintln(os.Stderr, "")
 	os.Exit(2) // Exit 2 = BLOCK in Claude Code hooks
+}
 
-	return nil
+// guardMessage returns the rule's message with checker detail appended.
+func guardMessage(m guard.Match) string {
+	msg := m.Rule.Message
+	if msg == "" {
+		msg = m.Rule.Description
+	}
+	if m.Detail != "" {
+		msg += "\nOffending: " + m.Detail
+	}
+	return msg
 }
 
 // isGasTownAgentContext returns true if we're running as a Gas Town managed agent.
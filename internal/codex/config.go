@@ -0,0 +1,83 @@
+// Package codex provides OpenAI Codex configuration management.
+package codex
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/steveyegge/gastown/internal/claude"
+)
+
+//go:embed config/config.toml config/AGENTS.md
+var configFS embed.FS
+
+// Default config location, relative to the agent's working directory.
+const (
+	ConfigDir        = ".codex"
+	ConfigFile       = "config.toml"
+	InstructionsFile = "AGENTS.md"
+)
+
+// EnsureConfigAt ensures the Codex project config exists at a custom
+// directory/file, and an AGENTS.md bootstrap next to it. The config makes
+// Codex run unattended and read CLAUDE.md role instructions; AGENTS.md
+// tells the agent how Gas Town starts it. Existing files are left
+// unchanged, so a repository's own AGENTS.md is never replaced.
+func EnsureConfigAt(workDir, role, configDir, configFile string) error {
+	if configDir == "" || configFile == "" {
+		return nil
+	}
+
+	configPath := filepath.Join(workDir, configDir, configFile)
+	if _, err := os.Stat(configPath); err != nil {
+		if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+			return fmt.Errorf("creating config directory: %w", err)
+		}
+		content, err := configFS.ReadFile("config/config.toml")
+		if err != nil {
+			return fmt.Errorf("reading config template: %w", err)
+		}
+		if err := os.WriteFile(configPath, content, 0644); err != nil {
+			return fmt.Errorf("writing config: %w", err)
+		}
+	}
+
+	instructionsPath := filepath.Join(workDir, InstructionsFile)
+	if _, err := os.Stat(instructionsPath); err == nil {
+		return nil
+	}
+	content, err := Instructions(role)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(instructionsPath, content, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", InstructionsFile, err)
+	}
+
+	return nil
+}
+
+// Instructions renders the AGENTS.md bootstrap for a role.
+func Instructions(role string) ([]byte, error) {
+	text, err := configFS.ReadFile("config/AGENTS.md")
+	if err != nil {
+		return nil, fmt.Errorf("reading %s template: %w", InstructionsFile, err)
+	}
+	tmpl, err := template.New(InstructionsFile).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template: %w", InstructionsFile, err)
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, struct {
+		Role       string
+		Autonomous bool
+	}{role, claude.RoleTypeFor(role) == claude.Autonomous})
+	if err != nil {
+		return nil, fmt.Errorf("rendering %s: %w", InstructionsFile, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -3,6 +3,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -67,6 +68,10 @@ type AgentPresetInfo struct {
 	// "subcommand" - pass as 'codex resume <id>'
 	ResumeStyle string `json:"resume_style,omitempty"`
 
+	// ModelFlag is the flag that selects the model (e.g., "--model").
+	// Empty if the agent has no model selection flag.
+	ModelFlag string `json:"model_flag,omitempty"`
+
 	// SupportsHooks indicates if the agent supports hooks system.
 	SupportsHooks bool `json:"supports_hooks,omitempty"`
 
@@ -113,6 +118,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		SessionIDEnv:        "CLAUDE_SESSION_ID",
 		ResumeFlag:          "--resume",
 		ResumeStyle:         "flag",
+		ModelFlag:           "--model",
 		SupportsHooks:       true,
 		SupportsForkSession: true,
 		NonInteractive:      nil, // Claude is native non-interactive
@@ -125,7 +131,8 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		SessionIDEnv:        "GEMINI_SESSION_ID",
 		ResumeFlag:          "--resume",
 		ResumeStyle:         "flag",
-		SupportsHooks:       true,
+		ModelFlag:           "--model",
+		SupportsHooks:       true, // .gemini/settings.json hooks
 		SupportsForkSession: false,
 		NonInteractive: &NonInteractiveConfig{
 			PromptFlag: "-p",
@@ -140,7 +147,8 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		SessionIDEnv:        "", // Codex captures from JSONL output
 		ResumeFlag:          "resume",
 		ResumeStyle:         "subcommand",
-		SupportsHooks:       false, // Use env/files instead
+		ModelFlag:           "--model",
+		SupportsHooks:       true, // .codex/config.toml + AGENTS.md, hooks emulated
 		SupportsForkSession: false,
 		NonInteractive: &NonInteractiveConfig{
 			Subcommand: "exec",
@@ -155,7 +163,8 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		SessionIDEnv:        "", // Uses --resume with chatId directly
 		ResumeFlag:          "--resume",
 		ResumeStyle:         "flag",
-		SupportsHooks:       false, // TODO: verify hooks support
+		ModelFlag:           "--model",
+		SupportsHooks:       true, // Emulated (gt hooks emulate)
 		SupportsForkSession: false,
 		NonInteractive: &NonInteractiveConfig{
 			PromptFlag: "-p",
@@ -170,7 +179,8 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		SessionIDEnv:        "",
 		ResumeFlag:          "--resume",
 		ResumeStyle:         "flag",
-		SupportsHooks:       false,
+		ModelFlag:           "--model",
+		SupportsHooks:       true, // Emulated (gt hooks emulate)
 		SupportsForkSession: false,
 	},
 	AgentAmp: {
@@ -181,7 +191,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		SessionIDEnv:        "",
 		ResumeFlag:          "threads continue",
 		ResumeStyle:         "subcommand", // 'amp threads continue <threadId>'
-		SupportsHooks:       false,
+		SupportsHooks:       true,         // Emulated (gt hooks emulate)
 		SupportsForkSession: false,
 	},
 	AgentOpenCode: {
@@ -196,6 +206,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		SessionIDEnv:        "",                           // OpenCode manages sessions internally
 		ResumeFlag:          "",                           // No resume support yet
 		ResumeStyle:         "",
+		ModelFlag:           "--model",
 		SupportsHooks:       true,  // Uses .opencode/plugin/gastown.js
 		SupportsForkSession: false,
 		NonInteractive: &NonInteractiveConfig{
@@ -363,9 +374,10 @@ func RuntimeConfigFromPreset(preset AgentPreset) *RuntimeConfig {
 	}
 
 	rc := &RuntimeConfig{
-		Command: info.Command,
-		Args:    append([]string(nil), info.Args...), // Copy to avoid mutation
-		Env:     envCopy,
+		Provider: string(preset),
+		Command:  info.Command,
+		Args:     append([]string(nil), info.Args...), // Copy to avoid mutation
+		Env:      envCopy,
 	}
 
 	// Resolve command path for claude preset (handles alias installations)
@@ -465,6 +477,36 @@ func (rc *RuntimeConfig) MergeWithPreset(preset AgentPreset) *RuntimeConfig {
 	return result
 }
 
+// WithModel returns a copy of the RuntimeConfig that runs the given model,
+// by appending the agent's model flag to its args. The agent is identified
+// by Provider, or by Command if Provider is unset. Returns an error if the
+// agent has no known model flag.
+func (rc *RuntimeConfig) WithModel(model string) (*RuntimeConfig, error) {
+	if model == "" {
+		return rc, nil
+	}
+
+	var info *AgentPresetInfo
+	if rc.Provider != "" {
+		info = GetAgentPresetByName(rc.Provider)
+	} else {
+		command := filepath.Base(rc.Command)
+		for _, name := range ListAgentPresets() {
+			if preset := GetAgentPresetByName(name); preset != nil && preset.Command == command {
+				info = preset
+				break
+			}
+		}
+	}
+	if info == nil || info.ModelFlag == "" {
+		return nil, fmt.Errorf("agent '%s' has no model flag, cannot select model '%s'", rc.Command, model)
+	}
+
+	result := *rc
+	result.Args = append(append([]string(nil), rc.Args...), info.ModelFlag, model)
+	return &result, nil
+}
+
 // IsKnownPreset checks if a string is a known agent preset name.
 func IsKnownPreset(name string) bool {
 	ensureRegistry()
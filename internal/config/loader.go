@@ -212,6 +212,44 @@ func validateRigSettings(c *RigSettings) error {
 			return err
 		}
 	}
+	if c.Sandbox != nil {
+		if err := validateSandboxConfig(c.Sandbox); err != nil {
+			return err
+		}
+	}
+	// The sandbox wraps a host command; inside a container it would need
+	// bwrap in the image and privileges the container doesn't have.
+	if c.Sandbox.IsEnabled() && c.Container.IsEnabled() {
+		return fmt.Errorf("%w: sandbox and container cannot both be enabled; choose one", ErrInvalidSandbox)
+	}
+	if err := validateModelRoutes(c.ModelRoutes); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ErrInvalidSandbox indicates an invalid sandbox configuration.
+var ErrInvalidSandbox = errors.New("invalid sandbox config")
+
+// validateSandboxConfig validates a SandboxConfig.
+func validateSandboxConfig(c *SandboxConfig) error {
+	switch c.Mode {
+	case "", SandboxModeNone, SandboxModeBwrap:
+	default:
+		return fmt.Errorf("%w: mode '%s', want '%s' or '%s'",
+			ErrInvalidSandbox, c.Mode, SandboxModeNone, SandboxModeBwrap)
+	}
+	switch c.Network {
+	case "", SandboxNetworkAllow, SandboxNetworkDeny:
+	default:
+		return fmt.Errorf("%w: network '%s', want '%s' or '%s'",
+			ErrInvalidSandbox, c.Network, SandboxNetworkAllow, SandboxNetworkDeny)
+	}
+	for _, p := range c.Writable {
+		if p != "~" && !strings.HasPrefix(p, "~/") && !filepath.IsAbs(p) {
+			return fmt.Errorf("%w: writable path '%s' must be absolute or start with ~/", ErrInvalidSandbox, p)
+		}
+	}
 	return nil
 }
 
@@ -1081,10 +1119,16 @@ func fillRuntimeDefaults(rc *RuntimeConfig) *RuntimeConfig {
 	}
 	// Create a copy to avoid modifying the original
 	result := &RuntimeConfig{
+		Provider:      rc.Provider,
 		Command:       rc.Command,
 		Args:          rc.Args,
 		InitialPrompt: rc.InitialPrompt,
 	}
+	// Keep an explicit hooks provider (e.g., "emulated" for a custom agent)
+	if rc.Hooks != nil {
+		hooks := *rc.Hooks
+		result.Hooks = &hooks
+	}
 	// Copy Env map to avoid mutation and preserve agent-specific env vars
 	if len(rc.Env) > 0 {
 		result.Env = make(map[string]string, len(rc.Env))
@@ -1316,6 +1360,13 @@ func PrependEnv(command string, envVars map[string]string) string {
 //  2. role_agents[GT_ROLE] (if GT_ROLE is in envVars)
 //  3. Default agent resolution (rig's Agent → town's DefaultAgent → "claude")
 func BuildStartupCommandWithAgentOverride(envVars map[string]string, rigPath, prompt, agentOverride string) (string, error) {
+	return buildStartupCommandWithOverrides(envVars, rigPath, prompt, agentOverride, "")
+}
+
+// buildStartupCommandWithOverrides builds a startup command with an optional
+// agent override and model. The model is passed via the agent's model flag
+// and recorded in GT_MODEL.
+func buildStartupCommandWithOverrides(envVars map[string]string, rigPath, prompt, agentOverride, model string) (string, error) {
 	var rc *RuntimeConfig
 	var townRoot string
 
@@ -1373,6 +1424,13 @@ func BuildStartupCommandWithAgentOverride(envVars map[string]string, rigPath, pr
 	if agentOverride != "" {
 		resolvedEnv["GT_AGENT"] = agentOverride
 	}
+	if model != "" {
+		var err error
+		if rc, err = rc.WithModel(model); err != nil {
+			return "", err
+		}
+		resolvedEnv["GT_MODEL"] = model
+	}
 	// Merge agent-specific env vars (e.g., OPENCODE_PERMISSION for yolo mode)
 	for k, v := range rc.Env {
 		resolvedEnv[k] = v
@@ -1457,6 +1515,22 @@ func BuildPolecatStartupCommandWithAgentOverride(rigName, polecatName, rigPath,
 	return BuildStartupCommandWithAgentOverride(envVars, rigPath, prompt, agentOverride)
 }
 
+// BuildPolecatStartupCommandWithModel is like BuildPolecatStartupCommandWithAgentOverride,
+// but also selects the agent's model if model is non-empty. Used for model routing.
+func BuildPolecatStartupCommandWithModel(rigName, polecatName, rigPath, prompt, agentOverride, model string) (string, error) {
+	var townRoot string
+	if rigPath != "" {
+		townRoot = filepath.Dir(rigPath)
+	}
+	envVars := AgentEnv(AgentEnvConfig{
+		Role:      "polecat",
+		Rig:       rigName,
+		AgentName: polecatName,
+		TownRoot:  townRoot,
+	})
+	return buildStartupCommandWithOverrides(envVars, rigPath, prompt, agentOverride, model)
+}
+
 // BuildCrewStartupCommand builds the startup command for a crew member.
 // Sets GT_ROLE, GT_RIG, GT_CREW, BD_ACTOR, GIT_AUTHOR_NAME, and GT_ROOT.
 func BuildCrewStartupCommand(rigName, crewName, rigPath, prompt string) string {
@@ -341,6 +341,67 @@ func TestRigSettingsValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid sandbox",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{
+					Mode:      SandboxModeBwrap,
+					Network:   SandboxNetworkAllow,
+					DenyHosts: []string{"pastebin.com"},
+					Writable:  []string{"~/.claude", "/opt/cache"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid sandbox mode",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Mode: "docker"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid sandbox network",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Mode: SandboxModeBwrap, Network: "partial"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sandbox and container",
+			settings: &RigSettings{
+				Type:      "rig-settings",
+				Version:   1,
+				Sandbox:   &SandboxConfig{Mode: SandboxModeBwrap},
+				Container: &ContainerConfig{Image: "golang:1.24"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "container with sandbox off",
+			settings: &RigSettings{
+				Type:      "rig-settings",
+				Version:   1,
+				Sandbox:   &SandboxConfig{Mode: SandboxModeNone},
+				Container: &ContainerConfig{Image: "golang:1.24"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "relative sandbox writable path",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Mode: SandboxModeBwrap, Writable: []string{"cache"}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1470,9 +1531,10 @@ func TestGetRuntimeCommand_UsesRigAgentWhenRigPathProvided(t *testing.T) {
 		t.Fatalf("SaveRigSettings: %v", err)
 	}
 
+	// Codex has no native hooks, so it runs under the hook emulation wrapper
 	cmd := GetRuntimeCommand(rigPath)
-	if !strings.HasPrefix(cmd, "codex") {
-		t.Fatalf("GetRuntimeCommand() = %q, want prefix %q", cmd, "codex")
+	if !strings.HasPrefix(cmd, "gt hooks emulate -- codex") {
+		t.Fatalf("GetRuntimeCommand() = %q, want prefix %q", cmd, "gt hooks emulate -- codex")
 	}
 }
 
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidModelRoute indicates an invalid model_routes entry.
+var ErrInvalidModelRoute = errors.New("invalid model route")
+
+// Bead size buckets used by ModelRouteMatch.Sizes.
+const (
+	SizeXS = "xs"
+	SizeS  = "s"
+	SizeM  = "m"
+	SizeL  = "l"
+	SizeXL = "xl"
+)
+
+// sizeLimits maps estimate upper bounds (minutes) to size buckets, in order.
+var sizeLimits = []struct {
+	maxMinutes int
+	size       string
+}{
+	{30, SizeXS},
+	{120, SizeS},
+	{480, SizeM},
+	{1440, SizeL},
+}
+
+// modelNamePattern restricts model names to characters safe on a command line.
+var modelNamePattern = regexp.MustCompile(`^[A-Za-z0-9._:/@-]+$`)
+
+// RouteBead describes the work a model route is chosen for.
+type RouteBead struct {
+	Labels           []string
+	Type             string
+	Priority         int
+	Formula          string // Formula applied to the work, if any
+	EstimatedMinutes int    // 0 if the bead has no estimate
+}
+
+// Size returns the bead's size bucket, or "" if it has neither a size label
+// nor an estimate.
+func (b RouteBead) Size() string {
+	for _, label := range b.Labels {
+		if size, ok := strings.CutPrefix(label, "size:"); ok {
+			return strings.ToLower(size)
+		}
+	}
+	if b.EstimatedMinutes <= 0 {
+		return ""
+	}
+	for _, limit := range sizeLimits {
+		if b.EstimatedMinutes <= limit.maxMinutes {
+			return limit.size
+		}
+	}
+	return SizeXL
+}
+
+// Matches reports whether the bead meets every condition set on m.
+func (m *ModelRouteMatch) Matches(b RouteBead) bool {
+	for _, want := range m.Labels {
+		if !containsFold(b.Labels, want) {
+			return false
+		}
+	}
+	if len(m.Types) > 0 && !containsFold(m.Types, b.Type) {
+		return false
+	}
+	if len(m.Priorities) > 0 {
+		found := false
+		for _, p := range m.Priorities {
+			if p == b.Priority {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(m.Formulas) > 0 && !containsFold(m.Formulas, b.Formula) {
+		return false
+	}
+	if len(m.Sizes) > 0 && !containsFold(m.Sizes, b.Size()) {
+		return false
+	}
+	return true
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadModelRoutes returns the model routes for a rig: the rig's routes
+// first, then the town's. Returns an error if either settings file is
+// invalid.
+func LoadModelRoutes(townRoot, rigPath string) ([]ModelRoute, error) {
+	var routes []ModelRoute
+	if rigPath != "" {
+		rigSettings, err := LoadRigSettings(RigSettingsPath(rigPath))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("loading rig settings: %w", err)
+		}
+		if rigSettings != nil {
+			routes = append(routes, rigSettings.ModelRoutes...)
+		}
+	}
+
+	townSettings, err := LoadOrCreateTownSettings(TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	if err := validateModelRoutes(townSettings.ModelRoutes); err != nil {
+		return nil, fmt.Errorf("town settings: %w", err)
+	}
+	return append(routes, townSettings.ModelRoutes...), nil
+}
+
+// MatchModelRoute returns the first route matching the bead, or nil.
+func MatchModelRoute(routes []ModelRoute, b RouteBead) *ModelRoute {
+	for i := range routes {
+		if routes[i].Match.Matches(b) {
+			return &routes[i]
+		}
+	}
+	return nil
+}
+
+// validateModelRoutes validates model_routes entries.
+func validateModelRoutes(routes []ModelRoute) error {
+	for i, r := range routes {
+		if r.Name == "" {
+			return fmt.Errorf("%w: route %d has no name", ErrInvalidModelRoute, i)
+		}
+		if r.Agent == "" && r.Model == "" && r.Account == "" {
+			return fmt.Errorf("%w: route '%s' sets none of agent, model or account", ErrInvalidModelRoute, r.Name)
+		}
+		if r.Model != "" && !modelNamePattern.MatchString(r.Model) {
+			return fmt.Errorf("%w: route '%s' has invalid model '%s'", ErrInvalidModelRoute, r.Name, r.Model)
+		}
+		for _, p := range r.Match.Priorities {
+			if p < 0 || p > 4 {
+				return fmt.Errorf("%w: route '%s' priority %d not in 0-4", ErrInvalidModelRoute, r.Name, p)
+			}
+		}
+		for _, size := range r.Match.Sizes {
+			switch strings.ToLower(size) {
+			case SizeXS, SizeS, SizeM, SizeL, SizeXL:
+			default:
+				return fmt.Errorf("%w: route '%s' size '%s', want xs, s, m, l or xl", ErrInvalidModelRoute, r.Name, size)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRouteBeadSize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		bead RouteBead
+		want string
+	}{
+		{"no estimate", RouteBead{}, ""},
+		{"label wins", RouteBead{Labels: []string{"size:L"}, EstimatedMinutes: 10}, SizeL},
+		{"xs", RouteBead{EstimatedMinutes: 30}, SizeXS},
+		{"s", RouteBead{EstimatedMinutes: 90}, SizeS},
+		{"m", RouteBead{EstimatedMinutes: 480}, SizeM},
+		{"l", RouteBead{EstimatedMinutes: 600}, SizeL},
+		{"xl", RouteBead{EstimatedMinutes: 3000}, SizeXL},
+	}
+	for _, tt := range tests {
+		if got := tt.bead.Size(); got != tt.want {
+			t.Errorf("%s: Size() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestModelRouteMatch(t *testing.T) {
+	t.Parallel()
+	bug := RouteBead{Labels: []string{"backend", "urgent"}, Type: "bug", Priority: 0, Formula: "mol-polecat-work", EstimatedMinutes: 60}
+	tests := []struct {
+		name  string
+		match ModelRouteMatch
+		want  bool
+	}{
+		{"empty matches all", ModelRouteMatch{}, true},
+		{"all labels present", ModelRouteMatch{Labels: []string{"urgent", "Backend"}}, true},
+		{"missing label", ModelRouteMatch{Labels: []string{"docs"}}, false},
+		{"type and priority", ModelRouteMatch{Types: []string{"bug"}, Priorities: []int{0, 1}}, true},
+		{"wrong priority", ModelRouteMatch{Types: []string{"bug"}, Priorities: []int{2}}, false},
+		{"formula", ModelRouteMatch{Formulas: []string{"mol-polecat-work"}}, true},
+		{"size", ModelRouteMatch{Sizes: []string{"s"}}, true},
+		{"wrong size", ModelRouteMatch{Sizes: []string{"xl"}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.match.Matches(bug); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadModelRoutes_RigBeforeTown(t *testing.T) {
+	t.Parallel()
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+
+	townSettings := NewTownSettings()
+	townSettings.ModelRoutes = []ModelRoute{
+		{Name: "town-docs", Match: ModelRouteMatch{Labels: []string{"docs"}}, Model: "haiku"},
+		{Name: "p0", Match: ModelRouteMatch{Priorities: []int{0}}, Model: "opus", Account: "work"},
+	}
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	rigSettings := NewRigSettings()
+	rigSettings.ModelRoutes = []ModelRoute{
+		{Name: "rig-docs", Match: ModelRouteMatch{Labels: []string{"docs"}}, Agent: "gemini"},
+	}
+	if err := SaveRigSettings(RigSettingsPath(rigPath), rigSettings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	routes, err := LoadModelRoutes(townRoot, rigPath)
+	if err != nil {
+		t.Fatalf("LoadModelRoutes: %v", err)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("got %d routes, want 3", len(routes))
+	}
+	if r := MatchModelRoute(routes, RouteBead{Labels: []string{"docs"}, Priority: 2}); r == nil || r.Name != "rig-docs" {
+		t.Errorf("docs bead routed to %+v, want rig-docs", r)
+	}
+	if r := MatchModelRoute(routes, RouteBead{Type: "bug", Priority: 0}); r == nil || r.Name != "p0" {
+		t.Errorf("P0 bead routed to %+v, want p0", r)
+	}
+	if r := MatchModelRoute(routes, RouteBead{Priority: 3}); r != nil {
+		t.Errorf("P3 bead routed to %q, want no route", r.Name)
+	}
+}
+
+func TestValidateModelRoutes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		route ModelRoute
+	}{
+		{"no name", ModelRoute{Model: "opus"}},
+		{"no selection", ModelRoute{Name: "empty"}},
+		{"unsafe model", ModelRoute{Name: "bad", Model: "opus; rm -rf /"}},
+		{"bad priority", ModelRoute{Name: "bad", Model: "opus", Match: ModelRouteMatch{Priorities: []int{5}}}},
+		{"bad size", ModelRoute{Name: "bad", Model: "opus", Match: ModelRouteMatch{Sizes: []string{"huge"}}}},
+	}
+	for _, tt := range tests {
+		err := validateRigSettings(&RigSettings{Type: "rig-settings", ModelRoutes: []ModelRoute{tt.route}})
+		if !errors.Is(err, ErrInvalidModelRoute) {
+			t.Errorf("%s: error = %v, want ErrInvalidModelRoute", tt.name, err)
+		}
+	}
+}
+
+func TestRuntimeConfigWithModel(t *testing.T) {
+	t.Parallel()
+	rc, err := RuntimeConfigFromPreset(AgentCodex).WithModel("gpt-5-codex")
+	if err != nil {
+		t.Fatalf("WithModel: %v", err)
+	}
+	if got := strings.Join(rc.Args, " "); got != "--yolo --model gpt-5-codex" {
+		t.Errorf("args = %q", got)
+	}
+
+	if _, err := RuntimeConfigFromPreset(AgentAmp).WithModel("fast"); err == nil {
+		t.Error("expected error for agent without a model flag")
+	}
+}
+
+func TestBuildPolecatStartupCommandWithModel(t *testing.T) {
+	t.Parallel()
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+	if err := SaveTownSettings(TownSettingsPath(townRoot), NewTownSettings()); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	if err := SaveRigSettings(RigSettingsPath(rigPath), NewRigSettings()); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	cmd, err := BuildPolecatStartupCommandWithModel("testrig", "toast", rigPath, "", "gemini", "gemini-2.5-flash")
+	if err != nil {
+		t.Fatalf("BuildPolecatStartupCommandWithModel: %v", err)
+	}
+	if !strings.Contains(cmd, "gemini --approval-mode yolo --model gemini-2.5-flash") {
+		t.Errorf("expected model flag in command: %q", cmd)
+	}
+	if !strings.Contains(cmd, "GT_MODEL=gemini-2.5-flash") || !strings.Contains(cmd, "GT_AGENT=gemini") {
+		t.Errorf("expected GT_AGENT and GT_MODEL exports in command: %q", cmd)
+	}
+}
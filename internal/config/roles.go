@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -38,6 +39,9 @@ type RoleDefinition struct {
 
 	// PromptTemplate is the name of the role's prompt template file.
 	PromptTemplate string `toml:"prompt_template,omitempty"`
+
+	// Resources contains optional per-session resource limits (Linux cgroup v2).
+	Resources RoleResourceConfig `toml:"resources"`
 }
 
 // RoleSessionConfig contains session-related configuration.
@@ -75,6 +79,46 @@ type RoleHealthConfig struct {
 	StuckThreshold Duration `toml:"stuck_threshold"`
 }
 
+// RoleResourceConfig contains resource limits applied to each agent session.
+// Limits are enforced by wrapping the agent command in a transient cgroup v2
+// scope (Linux only). Zero values mean unlimited.
+type RoleResourceConfig struct {
+	// CPUQuota limits CPU time as a percentage of one CPU.
+	// Examples: "50%", "200%" (two full cores)
+	CPUQuota string `toml:"cpu_quota,omitempty"`
+
+	// MemoryMax is the hard memory limit. Exceeding it triggers the OOM killer.
+	// Accepts a byte count with optional K/M/G/T suffix, e.g. "512M", "4G".
+	MemoryMax string `toml:"memory_max,omitempty"`
+
+	// PidsMax limits the number of tasks (processes and threads) in the session.
+	PidsMax int `toml:"pids_max,omitempty"`
+}
+
+var (
+	cpuQuotaPattern  = regexp.MustCompile(`^[0-9]+%$`)
+	memoryMaxPattern = regexp.MustCompile(`^[0-9]+[KMGT]?$`)
+)
+
+// IsSet returns true if any resource limit is configured.
+func (r RoleResourceConfig) IsSet() bool {
+	return r.CPUQuota != "" || r.MemoryMax != "" || r.PidsMax > 0
+}
+
+// Validate checks that the configured limits are well-formed.
+func (r RoleResourceConfig) Validate() error {
+	if r.CPUQuota != "" && !cpuQuotaPattern.MatchString(r.CPUQuota) {
+		return fmt.Errorf("invalid cpu_quota %q: expected a percentage like \"200%%\"", r.CPUQuota)
+	}
+	if r.MemoryMax != "" && !memoryMaxPattern.MatchString(r.MemoryMax) {
+		return fmt.Errorf("invalid memory_max %q: expected bytes with optional K/M/G/T suffix", r.MemoryMax)
+	}
+	if r.PidsMax < 0 {
+		return fmt.Errorf("invalid pids_max %d: must be positive", r.PidsMax)
+	}
+	return nil
+}
+
 // Duration is a wrapper for time.Duration that supports TOML marshaling.
 type Duration struct {
 	time.Duration
@@ -132,7 +176,8 @@ func isValidRoleName(name string) bool {
 //  3. Rig-level overrides (<rig>/roles/<role>.toml)
 //
 // Each layer merges with (not replaces) the previous. Users only specify
-// fields they want to change.
+// fields they want to change. An override that exists but cannot be read
+// or parsed is an error rather than silently ignored.
 func LoadRoleDefinition(townRoot, rigPath, roleName string) (*RoleDefinition, error) {
 	// Validate role name
 	if !isValidRoleName(roleName) {
@@ -149,6 +194,8 @@ func LoadRoleDefinition(townRoot, rigPath, roleName string) (*RoleDefinition, er
 	townOverridePath := filepath.Join(townRoot, "roles", roleName+".toml")
 	if override, err := loadRoleOverride(townOverridePath); err == nil {
 		mergeRoleDefinition(def, override)
+	} else if !os.IsNotExist(err) {
+		return nil, err
 	}
 
 	// 3. Apply rig-level overrides if present (only for rig-scoped roles)
@@ -156,6 +203,8 @@ func LoadRoleDefinition(townRoot, rigPath, roleName string) (*RoleDefinition, er
 		rigOverridePath := filepath.Join(rigPath, "roles", roleName+".toml")
 		if override, err := loadRoleOverride(rigOverridePath); err == nil {
 			mergeRoleDefinition(def, override)
+		} else if !os.IsNotExist(err) {
+			return nil, err
 		}
 	}
 
@@ -254,6 +303,17 @@ func mergeRoleDefinition(base, override *RoleDefinition) {
 	if override.PromptTemplate != "" {
 		base.PromptTemplate = override.PromptTemplate
 	}
+
+	// Resource limits
+	if override.Resources.CPUQuota != "" {
+		base.Resources.CPUQuota = override.Resources.CPUQuota
+	}
+	if override.Resources.MemoryMax != "" {
+		base.Resources.MemoryMax = override.Resources.MemoryMax
+	}
+	if override.Resources.PidsMax != 0 {
+		base.Resources.PidsMax = override.Resources.PidsMax
+	}
 }
 
 // ExpandPattern expands placeholders in a pattern string.
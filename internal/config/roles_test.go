@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -270,3 +272,87 @@ func TestToLegacyRoleConfig(t *testing.T) {
 		t.Errorf("ConsecutiveFailures = %d, want 3", legacy.ConsecutiveFailures)
 	}
 }
+
+func TestLoadRoleDefinition_ResourceOverrides(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "gastown")
+
+	townRoles := filepath.Join(townRoot, "roles")
+	rigRoles := filepath.Join(rigPath, "roles")
+	for _, dir := range []string{townRoles, rigRoles} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	townOverride := "[resources]\ncpu_quota = \"200%\"\nmemory_max = \"8G\"\n"
+	if err := os.WriteFile(filepath.Join(townRoles, "polecat.toml"), []byte(townOverride), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rigOverride := "[resources]\nmemory_max = \"4G\"\npids_max = 512\n"
+	if err := os.WriteFile(filepath.Join(rigRoles, "polecat.toml"), []byte(rigOverride), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	def, err := LoadRoleDefinition(townRoot, rigPath, "polecat")
+	if err != nil {
+		t.Fatalf("LoadRoleDefinition() error: %v", err)
+	}
+
+	if def.Resources.CPUQuota != "200%" {
+		t.Errorf("CPUQuota = %q, want %q", def.Resources.CPUQuota, "200%")
+	}
+	if def.Resources.MemoryMax != "4G" {
+		t.Errorf("MemoryMax = %q, want %q (rig overrides town)", def.Resources.MemoryMax, "4G")
+	}
+	if def.Resources.PidsMax != 512 {
+		t.Errorf("PidsMax = %d, want 512", def.Resources.PidsMax)
+	}
+	if !def.Resources.IsSet() {
+		t.Error("IsSet() = false, want true")
+	}
+}
+
+func TestLoadRoleDefinition_MalformedOverride(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "gastown")
+	rigRoles := filepath.Join(rigPath, "roles")
+	if err := os.MkdirAll(rigRoles, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// A typo must not silently drop the limits it was meant to set
+	if err := os.WriteFile(filepath.Join(rigRoles, "polecat.toml"), []byte("[resources\nmemory_max = \"4G\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadRoleDefinition(townRoot, rigPath, "polecat"); err == nil || !strings.Contains(err.Error(), "polecat.toml") {
+		t.Errorf("LoadRoleDefinition() error = %v, want parse error naming the override", err)
+	}
+	if _, err := LoadRoleDefinition(townRoot, "", "polecat"); err != nil {
+		t.Errorf("LoadRoleDefinition() without overrides error = %v", err)
+	}
+}
+
+func TestRoleResourceConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		res     RoleResourceConfig
+		wantErr bool
+	}{
+		{"empty", RoleResourceConfig{}, false},
+		{"valid", RoleResourceConfig{CPUQuota: "150%", MemoryMax: "512M", PidsMax: 100}, false},
+		{"plain bytes", RoleResourceConfig{MemoryMax: "1073741824"}, false},
+		{"cpu without percent", RoleResourceConfig{CPUQuota: "2"}, true},
+		{"bad memory suffix", RoleResourceConfig{MemoryMax: "4GB"}, true},
+		{"negative pids", RoleResourceConfig{PidsMax: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.res.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
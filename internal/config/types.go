@@ -68,6 +68,15 @@ type TownSettings struct {
 	// Agent addresses like "gastown/crew/jack" become "gastown.crew.jack@{domain}".
 	// Default: "gastown.local"
 	AgentEmailDomain string `json:"agent_email_domain,omitempty"`
+
+	// SessionBackend selects what runs agent sessions.
+	// Values: "tmux" (default), "pty" (built-in supervisor, see 'gt ptyd').
+	// Use "pty" on hosts without tmux, such as CI containers.
+	SessionBackend string `json:"session_backend,omitempty"`
+
+	// ModelRoutes pick the agent, model and account for polecats spawned by
+	// gt sling, based on the slung bead. Rig routes are tried before these.
+	ModelRoutes []ModelRoute `json:"model_routes,omitempty"`
 }
 
 // NewTownSettings creates a new TownSettings with defaults.
@@ -236,6 +245,153 @@ type RigSettings struct {
 	// Overrides TownSettings.RoleAgents for this specific rig.
 	// Example: {"witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
+
+	// Sandbox configures filesystem and network isolation for polecats.
+	// If nil, polecats run unsandboxed.
+	Sandbox *SandboxConfig `json:"sandbox,omitempty"`
+
+	// Container runs polecat agents inside a container instead of directly
+	// on the host. If nil, polecats run on the host.
+	Container *ContainerConfig `json:"container,omitempty"`
+
+	// ModelRoutes pick the agent, model and account for polecats spawned by
+	// gt sling in this rig. Tried before TownSettings.ModelRoutes.
+	ModelRoutes []ModelRoute `json:"model_routes,omitempty"`
+}
+
+// ModelRoute selects the runtime for work matching its conditions.
+// All non-empty conditions must hold; the first matching route wins.
+//
+// Example (settings/config.json):
+//
+//	"model_routes": [
+//	  {"name": "docs", "match": {"labels": ["docs"]}, "agent": "claude", "model": "haiku"},
+//	  {"name": "p0-bugs", "match": {"types": ["bug"], "priorities": [0]}, "model": "opus", "account": "work"}
+//	]
+type ModelRoute struct {
+	// Name identifies the route in sling output, agent beads and stats.
+	Name string `json:"name"`
+
+	// Match holds the conditions a bead must meet.
+	Match ModelRouteMatch `json:"match"`
+
+	// Agent is the agent preset or custom agent to run (empty keeps the rig's agent).
+	Agent string `json:"agent,omitempty"`
+
+	// Model is passed to the agent via its model flag (e.g., claude --model opus).
+	Model string `json:"model,omitempty"`
+
+	// Account is the account handle to run under (see mayor/accounts.json).
+	Account string `json:"account,omitempty"`
+}
+
+// ModelRouteMatch lists the bead conditions for a ModelRoute.
+type ModelRouteMatch struct {
+	// Labels must all be present on the bead.
+	Labels []string `json:"labels,omitempty"`
+
+	// Types matches any of the listed issue types (bug, feature, task, ...).
+	Types []string `json:"types,omitempty"`
+
+	// Priorities matches any of the listed priorities (0-4).
+	Priorities []int `json:"priorities,omitempty"`
+
+	// Formulas matches any of the listed formulas applied to the work
+	// (mol-polecat-work unless --on or --hook-raw-bead says otherwise).
+	Formulas []string `json:"formulas,omitempty"`
+
+	// Sizes matches any of the listed size buckets: xs, s, m, l, xl.
+	// The size comes from a "size:<bucket>" label, or else from the
+	// bead's estimate (xs up to 30m, s 2h, m 8h, l 24h, xl beyond).
+	Sizes []string `json:"sizes,omitempty"`
+}
+
+// SandboxConfig configures isolation for polecat sessions.
+// In "bwrap" mode the agent command runs under bubblewrap with the whole
+// filesystem mounted read-only, except for the polecat's worktree, the shared
+// beads directories, the git objects and refs, and any explicitly listed
+// writable paths. The shared repository's hooks and config stay read-only.
+type SandboxConfig struct {
+	// Mode selects the isolation mechanism: "none" (default) or "bwrap".
+	Mode string `json:"mode,omitempty"`
+
+	// Network controls network access: "allow" (default) or "deny".
+	// "deny" unshares the network namespace, leaving only loopback.
+	Network string `json:"network,omitempty"`
+
+	// DenyHosts lists hostnames that resolve to an unroutable address inside
+	// the sandbox. Ignored when Network is "deny". This only rewrites
+	// /etc/hosts: it stops name lookups, but connecting to a raw IP address
+	// (or resolving via a custom DNS client) bypasses it. Use "deny" when
+	// the network must be closed.
+	DenyHosts []string `json:"deny_hosts,omitempty"`
+
+	// Writable lists additional paths the agent may write to, such as the
+	// runtime's state directory (e.g., "~/.claude"). "~" expands to $HOME.
+	Writable []string `json:"writable,omitempty"`
+}
+
+// Sandbox mode and network constants.
+const (
+	SandboxModeNone     = "none"
+	SandboxModeBwrap    = "bwrap"
+	SandboxNetworkAllow = "allow"
+	SandboxNetworkDeny  = "deny"
+)
+
+// IsEnabled returns true if the sandbox config requests isolation.
+func (c *SandboxConfig) IsEnabled() bool {
+	return c != nil && c.Mode != "" && c.Mode != SandboxModeNone
+}
+
+// ContainerConfig configures container execution for polecat sessions.
+// The agent command runs in a container created through the Docker or Podman
+// API, with the polecat's worktree and the shared beads directories
+// bind-mounted at their host paths. The session itself keeps running in the
+// town's session backend, attached to the container's terminal.
+type ContainerConfig struct {
+	// Image is the container image providing the project's toolchain and the
+	// agent runtime. Container mode is enabled when Image is set.
+	Image string `json:"image,omitempty"`
+
+	// Runtime selects the container engine: "docker" (default) or "podman".
+	Runtime string `json:"runtime,omitempty"`
+
+	// Host is the engine API endpoint, e.g. "unix:///run/podman/podman.sock".
+	// Defaults to $DOCKER_HOST / $CONTAINER_HOST or the runtime's standard socket.
+	Host string `json:"host,omitempty"`
+
+	// CPUs limits the container to this many CPUs (e.g., 2 or 0.5).
+	CPUs float64 `json:"cpus,omitempty"`
+
+	// Memory limits container memory, e.g. "4g" or "512m".
+	Memory string `json:"memory,omitempty"`
+
+	// Network is the container network mode (e.g., "bridge", "host", "none").
+	// Empty uses the engine default.
+	Network string `json:"network,omitempty"`
+
+	// User is the user the agent runs as. Defaults to the host uid:gid so
+	// files written to the worktree keep their owner.
+	User string `json:"user,omitempty"`
+
+	// Mounts lists additional bind mounts as "src:dst[:ro]", such as the
+	// runtime's state directory. "~" in src expands to $HOME.
+	Mounts []string `json:"mounts,omitempty"`
+
+	// Env lists additional KEY=VALUE environment variables for the agent.
+	Env []string `json:"env,omitempty"`
+}
+
+// Container runtime constants.
+const (
+	ContainerRuntimeDocker = "docker"
+	ContainerRuntimePodman = "podman"
+)
+
+// IsEnabled returns true if the container config requests container execution.
+func (c *ContainerConfig) IsEnabled() bool {
+	return c != nil && c.Image != ""
 }
 
 // CrewConfig represents crew workspace settings for a rig.
@@ -310,7 +466,11 @@ type RuntimeSessionConfig struct {
 
 // RuntimeHooksConfig configures runtime hook installation.
 type RuntimeHooksConfig struct {
-	// Provider controls which hook templates to install: "claude", "opencode", or "none".
+	// Provider controls which hook templates to install: "claude", "opencode",
+	// "gemini", "codex", "emulated" or "none". "emulated" runs the agent under
+	// `gt hooks emulate` and lets the daemon fire SessionStart/Idle/Stop hooks
+	// for runtimes without a native hook system. "codex" installs Codex's
+	// project config and also runs under emulation, as Codex has no hooks.
 	Provider string `json:"provider,omitempty"`
 
 	// Dir is the settings directory (e.g., ".claude").
@@ -320,6 +480,11 @@ type RuntimeHooksConfig struct {
 	SettingsFile string `json:"settings_file,omitempty"`
 }
 
+// Emulated reports whether the runtime runs under `gt hooks emulate`.
+func (h *RuntimeHooksConfig) Emulated() bool {
+	return h != nil && (h.Provider == "emulated" || h.Provider == "codex")
+}
+
 // RuntimeTmuxConfig controls tmux heuristics for detecting runtime readiness.
 type RuntimeTmuxConfig struct {
 	// ProcessNames are tmux pane commands that indicate the runtime is running.
@@ -351,6 +516,15 @@ func (rc *RuntimeConfig) BuildCommand() string {
 	cmd := resolved.Command
 	args := resolved.Args
 
+	// Runtimes without native hooks run under the hook emulation wrapper
+	if resolved.Hooks.Emulated() {
+		var prefix []string
+		for _, arg := range emulatedHooksWrapper(resolved.Hooks) {
+			prefix = append(prefix, ShellQuote(arg))
+		}
+		cmd = strings.Join(prefix, " ") + " " + cmd
+	}
+
 	// Combine command and args
 	if len(args) > 0 {
 		return cmd + " " + strings.Join(args, " ")
@@ -379,10 +553,26 @@ func (rc *RuntimeConfig) BuildCommandWithPrompt(prompt string) string {
 	return base + " " + quoteForShell(p)
 }
 
+// emulatedHooksWrapper returns the argv prefix that runs an agent under
+// hook emulation. The hooks file is found by walking up from the agent's
+// working directory unless a non-default location is configured. (For the
+// codex provider Dir/SettingsFile name Codex's own config, not the hooks.)
+func emulatedHooksWrapper(hooks *RuntimeHooksConfig) []string {
+	wrapper := []string{"gt", "hooks", "emulate"}
+	if hooks.Provider == "emulated" &&
+		(hooks.Dir != defaultHooksDir("emulated") || hooks.SettingsFile != defaultHooksFile("emulated")) {
+		wrapper = append(wrapper, "--settings", filepath.Join(hooks.Dir, hooks.SettingsFile))
+	}
+	return append(wrapper, "--")
+}
+
 // BuildArgsWithPrompt returns the runtime command and args suitable for exec.
 func (rc *RuntimeConfig) BuildArgsWithPrompt(prompt string) []string {
 	resolved := normalizeRuntimeConfig(rc)
 	args := append([]string{resolved.Command}, resolved.Args...)
+	if resolved.Hooks.Emulated() {
+		args = append(emulatedHooksWrapper(resolved.Hooks), args...)
+	}
 
 	p := prompt
 	if p == "" {
@@ -438,11 +628,11 @@ func normalizeRuntimeConfig(rc *RuntimeConfig) *RuntimeConfig {
 	}
 
 	if rc.Hooks.Dir == "" {
-		rc.Hooks.Dir = defaultHooksDir(rc.Provider)
+		rc.Hooks.Dir = defaultHooksDir(rc.Hooks.Provider)
 	}
 
 	if rc.Hooks.SettingsFile == "" {
-		rc.Hooks.SettingsFile = defaultHooksFile(rc.Provider)
+		rc.Hooks.SettingsFile = defaultHooksFile(rc.Hooks.Provider)
 	}
 
 	if rc.Tmux == nil {
@@ -550,17 +740,40 @@ func defaultHooksProvider(provider string) string {
 		return "claude"
 	case "opencode":
 		return "opencode"
+	case "gemini":
+		return "gemini"
+	case "codex":
+		return "codex"
+	case "cursor", "auggie", "amp":
+		return "emulated"
 	default:
 		return "none"
 	}
 }
 
+// DefaultHooksConfig returns the default hooks configuration for a runtime
+// provider (e.g., "gemini" installs .gemini/settings.json).
+func DefaultHooksConfig(provider string) *RuntimeHooksConfig {
+	hooksProvider := defaultHooksProvider(provider)
+	return &RuntimeHooksConfig{
+		Provider:     hooksProvider,
+		Dir:          defaultHooksDir(hooksProvider),
+		SettingsFile: defaultHooksFile(hooksProvider),
+	}
+}
+
 func defaultHooksDir(provider string) string {
 	switch provider {
 	case "claude":
 		return ".claude"
 	case "opencode":
 		return ".opencode/plugin"
+	case "gemini":
+		return ".gemini"
+	case "codex":
+		return ".codex"
+	case "emulated":
+		return ".gastown"
 	default:
 		return ""
 	}
@@ -572,6 +785,12 @@ func defaultHooksFile(provider string) string {
 		return "settings.json"
 	case "opencode":
 		return "gastown.js"
+	case "gemini":
+		return "settings.json"
+	case "codex":
+		return "config.toml"
+	case "emulated":
+		return "hooks.json"
 	default:
 		return ""
 	}
@@ -607,6 +826,9 @@ func defaultReadyDelayMs(provider string) int {
 	if provider == "codex" {
 		return 3000
 	}
+	if provider == "gemini" || provider == "cursor" || provider == "auggie" || provider == "amp" {
+		return 5000
+	}
 	if provider == "opencode" {
 		// OpenCode requires delay-based detection because its TUI uses
 		// box-drawing characters (┃) that break prompt prefix matching.
@@ -623,6 +845,9 @@ func defaultInstructionsFile(provider string) string {
 	if provider == "opencode" {
 		return "AGENTS.md"
 	}
+	if provider == "gemini" {
+		return "GEMINI.md"
+	}
 	return "CLAUDE.md"
 }
 
@@ -716,6 +941,121 @@ type MergeQueueConfig struct {
 
 	// MaxConcurrent is the maximum number of concurrent merges.
 	MaxConcurrent int `json:"max_concurrent"`
+
+	// RequireReview holds MRs out of the ready queue until they are approved,
+	// either by a human (gt mq approve) or by a reviewer polecat.
+	RequireReview bool `json:"require_review,omitempty"`
+
+	// ReviewFormula is the formula reviewer polecats run on review tasks.
+	// Default: "mol-polecat-code-review".
+	ReviewFormula string `json:"review_formula,omitempty"`
+
+	// GitHubPRs also lists the rig's open GitHub pull requests (via gh) in
+	// the dashboard merge queue, next to its MR beads. Needs network access.
+	GitHubPRs bool `json:"github_prs,omitempty"`
+
+	// Landing selects how the refinery lands merged work on the target
+	// branch. Nil means a direct push.
+	Landing *LandingConfig `json:"landing,omitempty"`
+
+	// PostMerge verifies the target branch after each merge. Nil skips it.
+	PostMerge *PostMergeConfig `json:"post_merge,omitempty"`
+
+	// FreezeWindows are recurring periods when the refinery merges nothing
+	// (release days, weekends). See also gt mq pause for one-off freezes.
+	FreezeWindows []FreezeWindow `json:"freeze_windows,omitempty"`
+}
+
+// FreezeWindow is a recurring merge freeze, e.g. Fridays from 16:00:
+//
+//	{"days": ["fri"], "start": "16:00", "reason": "no Friday merges"}
+type FreezeWindow struct {
+	// Days the window starts on: weekday names or three-letter
+	// abbreviations ("fri", "Saturday"). Empty means every day.
+	Days []string `json:"days,omitempty"`
+
+	// Start and End are "HH:MM" clock times. An empty Start is midnight;
+	// an empty End is the end of the day. An End at or before Start runs
+	// past midnight into the next day.
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+
+	// Timezone is an IANA zone name (e.g., "Europe/Berlin"). Default: the
+	// local time zone.
+	Timezone string `json:"timezone,omitempty"`
+
+	// Reason is shown while the window is in effect.
+	Reason string `json:"reason,omitempty"`
+}
+
+// PostMergeConfig configures the refinery's check of the target branch
+// after a merge lands. When the check fails the merge is reverted, its
+// source issue reopened, the worker notified, and the rig's merge queue
+// paused until the target branch passes again.
+type PostMergeConfig struct {
+	// Command is the smoke/test command run on the new target head
+	// (e.g., "make smoke"). Empty disables the check.
+	Command string `json:"command,omitempty"`
+
+	// Timeout bounds one run of Command (e.g., "10m"). Default: 10m.
+	Timeout string `json:"timeout,omitempty"`
+
+	// NoRevert leaves a failing merge in place instead of reverting it.
+	// The queue is still paused.
+	NoRevert bool `json:"no_revert,omitempty"`
+}
+
+// Enabled reports whether the config asks for a post-merge check.
+func (c *PostMergeConfig) Enabled() bool {
+	return c != nil && c.Command != ""
+}
+
+// Landing modes.
+const (
+	// LandingModePush pushes the squashed commit straight to the target branch.
+	LandingModePush = "push"
+
+	// LandingModePR pushes the squashed commit to a landing branch, opens a
+	// pull request through the rig's forge, and merges it once checks pass.
+	// For repos whose target branch is protected against direct pushes.
+	LandingModePR = "pr"
+)
+
+// LandingConfig configures PR-based landing for a rig.
+type LandingConfig struct {
+	// Mode is "push" (default) or "pr".
+	Mode string `json:"mode,omitempty"`
+
+	// Forge is the hosting service: "github", "gitlab" or "gitea".
+	Forge string `json:"forge,omitempty"`
+
+	// APIURL is the forge's REST API root. Defaults to the public GitHub
+	// or GitLab API; required for Gitea.
+	APIURL string `json:"api_url,omitempty"`
+
+	// Repo is the repository path ("owner/name"). Derived from the origin
+	// remote URL if empty.
+	Repo string `json:"repo,omitempty"`
+
+	// TokenEnv names the environment variable holding the API token.
+	// Default: GITHUB_TOKEN, GITLAB_TOKEN or GITEA_TOKEN.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// BranchPrefix prefixes landing branch names. Default: "gt/land/".
+	BranchPrefix string `json:"branch_prefix,omitempty"`
+
+	// MergeMethod is "squash" (default), "merge" or "rebase".
+	MergeMethod string `json:"merge_method,omitempty"`
+
+	// Timeout is how long a landing PR may stay open waiting for checks and
+	// merge before the attempt is retried (e.g., "1h"). The PR is checked
+	// on each refinery pass, not polled.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// IsPR reports whether the config selects PR-based landing.
+func (c *LandingConfig) IsPR() bool {
+	return c != nil && c.Mode == LandingModePR
 }
 
 // OnConflict strategy constants.
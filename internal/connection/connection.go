@@ -8,7 +8,7 @@ import (
 	"time"
 )
 
-// Connection abstracts file operations, command execution, and tmux management
+// Connection abstracts file operations, command execution, and session management
 // for both local and remote (SSH) execution contexts.
 type Connection interface {
 	// Identification
@@ -56,26 +56,26 @@ type Connection interface {
 	// ExecEnv runs a command with additional environment variables.
 	ExecEnv(env map[string]string, cmd string, args ...string) ([]byte, error)
 
-	// Tmux operations
+	// Session operations (tmux or the town's configured session backend)
 
-	// TmuxNewSession creates a new tmux session with the given name.
-	TmuxNewSession(name, dir string) error
+	// NewSession creates a new detached session with the given name.
+	NewSession(name, dir string) error
 
-	// TmuxKillSession terminates the named tmux session.
+	// KillSession terminates the named session.
 	// Uses KillSessionWithProcesses internally to ensure all descendant processes are killed.
-	TmuxKillSession(name string) error
+	KillSession(name string) error
 
-	// TmuxSendKeys sends keys to the named tmux session.
-	TmuxSendKeys(session, keys string) error
+	// SendKeys sends keys to the named session.
+	SendKeys(session, keys string) error
 
-	// TmuxCapturePane captures the last N lines from a tmux pane.
-	TmuxCapturePane(session string, lines int) (string, error)
+	// CapturePane captures the last N lines of a session's output.
+	CapturePane(session string, lines int) (string, error)
 
-	// TmuxHasSession returns true if the named tmux session exists.
-	TmuxHasSession(name string) (bool, error)
+	// HasSession returns true if the named session exists.
+	HasSession(name string) (bool, error)
 
-	// TmuxListSessions returns a list of all tmux session names.
-	TmuxListSessions() ([]string, error)
+	// ListSessions returns a list of all session names.
+	ListSessions() ([]string, error)
 }
 
 // FileInfo abstracts fs.FileInfo for use over remote connections.
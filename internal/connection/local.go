@@ -6,18 +6,25 @@ import (
 	"os/exec"
 	"path/filepath"
 
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 // LocalConnection implements Connection for local file and command operations.
 type LocalConnection struct {
-	tmux *tmux.Tmux
+	sessions session.Backend
 }
 
-// NewLocalConnection creates a new local connection.
+// NewLocalConnection creates a new local connection using tmux for sessions.
 func NewLocalConnection() *LocalConnection {
+	return NewLocalConnectionWithBackend(tmux.NewTmux())
+}
+
+// NewLocalConnectionWithBackend creates a local connection whose sessions
+// run on b, e.g. session.NewBackend(townRoot).
+func NewLocalConnectionWithBackend(b session.Backend) *LocalConnection {
 	return &LocalConnection{
-		tmux: tmux.NewTmux(),
+		sessions: b,
 	}
 }
 
@@ -155,35 +162,35 @@ func (c *LocalConnection) ExecEnv(env map[string]string, cmd string, args ...str
 	return command.CombinedOutput()
 }
 
-// TmuxNewSession creates a new tmux session.
-func (c *LocalConnection) TmuxNewSession(name, dir string) error {
-	return c.tmux.NewSession(name, dir)
+// NewSession creates a new session.
+func (c *LocalConnection) NewSession(name, dir string) error {
+	return c.sessions.NewSession(name, dir)
 }
 
-// TmuxKillSession terminates a tmux session.
+// KillSession terminates a session.
 // Uses KillSessionWithProcesses to ensure all descendant processes are killed.
-func (c *LocalConnection) TmuxKillSession(name string) error {
-	return c.tmux.KillSessionWithProcesses(name)
+func (c *LocalConnection) KillSession(name string) error {
+	return c.sessions.KillSessionWithProcesses(name)
 }
 
-// TmuxSendKeys sends keys to a tmux session.
-func (c *LocalConnection) TmuxSendKeys(session, keys string) error {
-	return c.tmux.SendKeys(session, keys)
+// SendKeys sends keys to a session.
+func (c *LocalConnection) SendKeys(session, keys string) error {
+	return c.sessions.SendKeys(session, keys)
 }
 
-// TmuxCapturePane captures the last N lines from a tmux pane.
-func (c *LocalConnection) TmuxCapturePane(session string, lines int) (string, error) {
-	return c.tmux.CapturePane(session, lines)
+// CapturePane captures the last N lines of a session's output.
+func (c *LocalConnection) CapturePane(session string, lines int) (string, error) {
+	return c.sessions.CapturePane(session, lines)
 }
 
-// TmuxHasSession returns true if the session exists.
-func (c *LocalConnection) TmuxHasSession(name string) (bool, error) {
-	return c.tmux.HasSession(name)
+// HasSession returns true if the session exists.
+func (c *LocalConnection) HasSession(name string) (bool, error) {
+	return c.sessions.HasSession(name)
 }
 
-// TmuxListSessions returns all tmux session names.
-func (c *LocalConnection) TmuxListSessions() ([]string, error) {
-	return c.tmux.ListSessions()
+// ListSessions returns all session names.
+func (c *LocalConnection) ListSessions() ([]string, error) {
+	return c.sessions.ListSessions()
 }
 
 // Verify LocalConnection implements Connection.
@@ -0,0 +1,367 @@
+// Package container runs polecat agents inside containers.
+//
+// It talks to Docker or Podman through the engine's HTTP API on its local
+// socket (Podman serves the Docker-compatible API), so no container CLI needs
+// to be installed. A containerized polecat's session runs `gt container
+// attach`, which relays the session's terminal to the container's TTY; peek,
+// nudge and capture keep working through the regular session backend.
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// apiVersion is the Docker Engine API version used for requests. Podman's
+// compatibility API accepts it as well.
+const apiVersion = "v1.41"
+
+// Errors returned by the engine API.
+var (
+	ErrNotFound = errors.New("container not found")
+	ErrConflict = errors.New("container conflict")
+)
+
+// ErrUnavailable is returned when container mode is configured but the engine
+// cannot be reached. Polecats never silently fall back to running on the host.
+var ErrUnavailable = errors.New("container engine is not reachable")
+
+// DefaultHost returns the API endpoint for a container runtime, honouring
+// $DOCKER_HOST (docker) and $CONTAINER_HOST (podman).
+func DefaultHost(runtime string) string {
+	if runtime == config.ContainerRuntimePodman {
+		if h := os.Getenv("CONTAINER_HOST"); h != "" {
+			return h
+		}
+		if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+			return "unix://" + filepath.Join(dir, "podman", "podman.sock")
+		}
+		return "unix:///run/podman/podman.sock"
+	}
+	if h := os.Getenv("DOCKER_HOST"); h != "" {
+		return h
+	}
+	return "unix:///var/run/docker.sock"
+}
+
+// HostFor returns the API endpoint configured for a rig.
+func HostFor(cfg *config.ContainerConfig) string {
+	if cfg != nil && cfg.Host != "" {
+		return cfg.Host
+	}
+	runtime := ""
+	if cfg != nil {
+		runtime = cfg.Runtime
+	}
+	return DefaultHost(runtime)
+}
+
+// Client is a minimal container engine API client.
+type Client struct {
+	host string
+	dial func(ctx context.Context) (net.Conn, error)
+	http *http.Client
+}
+
+// NewClient creates a client for an endpoint of the form unix:///path or
+// tcp://host:port.
+func NewClient(host string) (*Client, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("parsing container host %q: %w", host, err)
+	}
+
+	var network, addr string
+	switch u.Scheme {
+	case "unix":
+		network, addr = "unix", u.Path
+	case "tcp", "http":
+		network, addr = "tcp", u.Host
+	default:
+		return nil, fmt.Errorf("unsupported container host %q (want unix:// or tcp://)", host)
+	}
+
+	c := &Client{host: host}
+	c.dial = func(ctx context.Context) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	}
+	c.http = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return c.dial(ctx)
+			},
+		},
+	}
+	return c, nil
+}
+
+// Host returns the endpoint the client talks to.
+func (c *Client) Host() string {
+	return c.host
+}
+
+// CreateRequest is the subset of the engine's container create body we use.
+type CreateRequest struct {
+	Image        string            `json:"Image"`
+	Cmd          []string          `json:"Cmd,omitempty"`
+	Env          []string          `json:"Env,omitempty"`
+	WorkingDir   string            `json:"WorkingDir,omitempty"`
+	User         string            `json:"User,omitempty"`
+	Labels       map[string]string `json:"Labels,omitempty"`
+	Tty          bool              `json:"Tty"`
+	OpenStdin    bool              `json:"OpenStdin"`
+	AttachStdin  bool              `json:"AttachStdin"`
+	AttachStdout bool              `json:"AttachStdout"`
+	AttachStderr bool              `json:"AttachStderr"`
+	HostConfig   HostConfig        `json:"HostConfig"`
+}
+
+// HostConfig holds the host-side settings for a container.
+type HostConfig struct {
+	Binds       []string `json:"Binds,omitempty"`
+	NanoCPUs    int64    `json:"NanoCpus,omitempty"`
+	Memory      int64    `json:"Memory,omitempty"`
+	NetworkMode string   `json:"NetworkMode,omitempty"`
+	Init        bool     `json:"Init,omitempty"`
+}
+
+// State is a container's runtime state.
+type State struct {
+	Status   string `json:"Status"`
+	Running  bool   `json:"Running"`
+	ExitCode int    `json:"ExitCode"`
+}
+
+// Info is the result of inspecting a container.
+type Info struct {
+	ID    string `json:"Id"`
+	Name  string `json:"Name"`
+	State State  `json:"State"`
+}
+
+// Summary is one entry of a container listing.
+type Summary struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	State  string            `json:"State"`
+	Status string            `json:"Status"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// Name returns the container's name without the leading slash.
+func (s Summary) Name() string {
+	if len(s.Names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(s.Names[0], "/")
+}
+
+// Ping checks that the engine is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/_ping", nil, nil, nil)
+}
+
+// Create creates a container and returns its ID.
+func (c *Client) Create(ctx context.Context, name string, req CreateRequest) (string, error) {
+	var resp struct {
+		ID string `json:"Id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/containers/create", url.Values{"name": {name}}, req, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// Start starts a created container. Starting a running container is a no-op.
+func (c *Client) Start(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/start", nil, nil, nil)
+}
+
+// Stop stops a container, killing it after timeout.
+func (c *Client) Stop(ctx context.Context, id string, timeout time.Duration) error {
+	q := url.Values{"t": {strconv.Itoa(int(timeout.Seconds()))}}
+	return c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/stop", q, nil, nil)
+}
+
+// Remove force-removes a container and its anonymous volumes.
+func (c *Client) Remove(ctx context.Context, id string) error {
+	q := url.Values{"force": {"1"}, "v": {"1"}}
+	return c.do(ctx, http.MethodDelete, "/containers/"+url.PathEscape(id), q, nil, nil)
+}
+
+// Inspect returns a container's state.
+func (c *Client) Inspect(ctx context.Context, id string) (*Info, error) {
+	var info Info
+	if err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/json", nil, nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// List returns all containers (running or not) carrying the given label.
+func (c *Client) List(ctx context.Context, label string) ([]Summary, error) {
+	filters, _ := json.Marshal(map[string][]string{"label": {label}})
+	q := url.Values{"all": {"1"}, "filters": {string(filters)}}
+	var out []Summary
+	if err := c.do(ctx, http.MethodGet, "/containers/json", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Wait blocks until a container stops and returns its exit code.
+func (c *Client) Wait(ctx context.Context, id string) (int, error) {
+	var resp struct {
+		StatusCode int `json:"StatusCode"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/wait", nil, nil, &resp); err != nil {
+		return -1, err
+	}
+	return resp.StatusCode, nil
+}
+
+// Resize sets the size of a running container's TTY.
+func (c *Client) Resize(ctx context.Context, id string, rows, cols int) error {
+	q := url.Values{"h": {strconv.Itoa(rows)}, "w": {strconv.Itoa(cols)}}
+	return c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/resize", q, nil, nil)
+}
+
+// Stream is a hijacked attach connection to a container's TTY.
+type Stream struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read reads container output, including any bytes buffered while reading
+// the upgrade response.
+func (s *Stream) Read(p []byte) (int, error) {
+	return s.r.Read(p)
+}
+
+// CloseWrite signals end of input to the container, if the transport
+// supports half-close.
+func (s *Stream) CloseWrite() error {
+	if cw, ok := s.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return nil
+}
+
+// Attach connects to a container's stdin, stdout and stderr. For TTY
+// containers the stream carries raw terminal bytes in both directions.
+func (c *Client) Attach(ctx context.Context, id string) (*Stream, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	q := url.Values{"stream": {"1"}, "stdin": {"1"}, "stdout": {"1"}, "stderr": {"1"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url("/containers/"+url.PathEscape(id)+"/attach", q), nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("attaching to %s: %w", id, err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("attaching to %s: %w", id, err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols && resp.StatusCode != http.StatusOK {
+		defer conn.Close()
+		return nil, apiError(resp)
+	}
+	return &Stream{Conn: conn, r: br}, nil
+}
+
+// url builds a request URL for an API path.
+func (c *Client) url(path string, query url.Values) string {
+	u := "http://container/" + apiVersion + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// do performs an API request, encoding body and decoding the response into
+// out when they are non-nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var rd io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path, query), rd)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	// 304 Not Modified: container already started/stopped.
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode >= 400 {
+		return apiError(resp)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decoding %s response: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// apiError converts an error response into an error, mapping 404 and 409 to
+// ErrNotFound and ErrConflict.
+func apiError(resp *http.Response) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &body) != nil || body.Message == "" {
+		body.Message = strings.TrimSpace(string(data))
+	}
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, body.Message)
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %s", ErrConflict, body.Message)
+	}
+	return fmt.Errorf("container engine: %s (HTTP %d)", body.Message, resp.StatusCode)
+}
@@ -0,0 +1,394 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// fakeEngine implements the slice of the Docker Engine API that Client uses.
+// Attached containers echo their input upper-cased and exit with status 3
+// when stdin closes.
+type fakeEngine struct {
+	mu         sync.Mutex
+	containers map[string]*fakeContainer
+	resized    string
+}
+
+type fakeContainer struct {
+	req     CreateRequest
+	state   string
+	code    int
+	exited  chan struct{}
+	started bool
+}
+
+func startFakeEngine(t *testing.T) (*fakeEngine, *Client) {
+	t.Helper()
+	e := &fakeEngine{containers: map[string]*fakeContainer{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1.41/_ping", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("OK")) })
+	mux.HandleFunc("POST /v1.41/containers/create", e.create)
+	mux.HandleFunc("GET /v1.41/containers/json", e.list)
+	mux.HandleFunc("POST /v1.41/containers/{id}/start", e.start)
+	mux.HandleFunc("POST /v1.41/containers/{id}/stop", e.stop)
+	mux.HandleFunc("POST /v1.41/containers/{id}/wait", e.wait)
+	mux.HandleFunc("POST /v1.41/containers/{id}/resize", e.resize)
+	mux.HandleFunc("POST /v1.41/containers/{id}/attach", e.attach)
+	mux.HandleFunc("GET /v1.41/containers/{id}/json", e.inspect)
+	mux.HandleFunc("DELETE /v1.41/containers/{id}", e.remove)
+
+	socket := filepath.Join(t.TempDir(), "engine.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: mux}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	c, err := NewClient("unix://" + socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e, c
+}
+
+func (e *fakeEngine) get(w http.ResponseWriter, r *http.Request) *fakeContainer {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ct := e.containers[r.PathValue("id")]
+	if ct == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"No such container: ` + r.PathValue("id") + `"}`))
+	}
+	return ct
+}
+
+func (e *fakeEngine) create(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.containers[name]; ok {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message":"name in use"}`))
+		return
+	}
+	e.containers[name] = &fakeContainer{req: req, state: "created", exited: make(chan struct{})}
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte(`{"Id":"` + name + `"}`))
+}
+
+func (e *fakeEngine) list(w http.ResponseWriter, r *http.Request) {
+	var filters map[string][]string
+	_ = json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := []Summary{}
+	for name, ct := range e.containers {
+		for _, l := range filters["label"] {
+			k, v, _ := strings.Cut(l, "=")
+			if ct.req.Labels[k] == v {
+				out = append(out, Summary{ID: name, Names: []string{"/" + name}, State: ct.state, Labels: ct.req.Labels})
+			}
+		}
+	}
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func (e *fakeEngine) start(w http.ResponseWriter, r *http.Request) {
+	if ct := e.get(w, r); ct != nil {
+		e.mu.Lock()
+		ct.state, ct.started = "running", true
+		e.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (e *fakeEngine) stop(w http.ResponseWriter, r *http.Request) {
+	if ct := e.get(w, r); ct != nil {
+		e.exit(ct, 143)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (e *fakeEngine) exit(ct *fakeContainer, code int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if ct.state != "exited" {
+		ct.state, ct.code = "exited", code
+		close(ct.exited)
+	}
+}
+
+func (e *fakeEngine) wait(w http.ResponseWriter, r *http.Request) {
+	if ct := e.get(w, r); ct != nil {
+		<-ct.exited
+		e.mu.Lock()
+		code := ct.code
+		e.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]int{"StatusCode": code})
+	}
+}
+
+func (e *fakeEngine) resize(w http.ResponseWriter, r *http.Request) {
+	if ct := e.get(w, r); ct != nil {
+		e.mu.Lock()
+		e.resized = r.URL.Query().Get("h") + "x" + r.URL.Query().Get("w")
+		e.mu.Unlock()
+	}
+}
+
+func (e *fakeEngine) inspect(w http.ResponseWriter, r *http.Request) {
+	if ct := e.get(w, r); ct != nil {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(Info{ID: r.PathValue("id"), Name: "/" + r.PathValue("id"),
+			State: State{Status: ct.state, Running: ct.state == "running", ExitCode: ct.code}})
+	}
+}
+
+func (e *fakeEngine) remove(w http.ResponseWriter, r *http.Request) {
+	if ct := e.get(w, r); ct != nil {
+		e.exit(ct, 137)
+		e.mu.Lock()
+		delete(e.containers, r.PathValue("id"))
+		e.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (e *fakeEngine) attach(w http.ResponseWriter, r *http.Request) {
+	ct := e.get(w, r)
+	if ct == nil {
+		return
+	}
+	if r.Header.Get("Upgrade") != "tcp" {
+		http.Error(w, "attach requires an upgrade", http.StatusBadRequest)
+		return
+	}
+	conn, buf, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n"))
+
+	sc := bufio.NewScanner(buf)
+	for sc.Scan() {
+		_, _ = conn.Write([]byte(strings.ToUpper(sc.Text()) + "\r\n"))
+	}
+	e.exit(ct, 3)
+}
+
+func TestClient_Lifecycle(t *testing.T) {
+	e, c := startFakeEngine(t)
+	ctx := context.Background()
+
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	id, err := c.Create(ctx, "gt-gastown-toast", CreateRequest{Image: "alpine", Labels: map[string]string{LabelRig: "gastown"}})
+	if err != nil || id != "gt-gastown-toast" {
+		t.Fatalf("Create = %q, %v", id, err)
+	}
+	if _, err := c.Create(ctx, "gt-gastown-toast", CreateRequest{Image: "alpine"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("duplicate Create err = %v, want ErrConflict", err)
+	}
+	if err := c.Start(ctx, id); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	info, err := c.Inspect(ctx, id)
+	if err != nil || !info.State.Running {
+		t.Errorf("Inspect = %+v, %v", info, err)
+	}
+	list, err := c.List(ctx, LabelRig+"=gastown")
+	if err != nil || len(list) != 1 || list[0].Name() != "gt-gastown-toast" {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+	if err := c.Stop(ctx, id, time.Second); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if code, err := c.Wait(ctx, id); err != nil || code != 143 {
+		t.Errorf("Wait = %d, %v", code, err)
+	}
+	if err := c.Remove(ctx, id); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := c.Inspect(ctx, id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Inspect after remove err = %v, want ErrNotFound", err)
+	}
+	if len(e.containers) != 0 {
+		t.Errorf("containers left: %v", e.containers)
+	}
+}
+
+func TestClient_Unreachable(t *testing.T) {
+	c, err := NewClient("unix://" + filepath.Join(t.TempDir(), "none.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Ping(context.Background()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Ping err = %v, want ErrUnavailable", err)
+	}
+	if _, err := NewClient("ssh://host"); err == nil {
+		t.Error("expected an error for an unsupported host")
+	}
+}
+
+func TestProxy(t *testing.T) {
+	e, c := startFakeEngine(t)
+	ctx := context.Background()
+	if _, err := c.Create(ctx, "gt-gastown-nux", CreateRequest{Image: "alpine"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	code, err := Proxy(ctx, c, "gt-gastown-nux", ProxyOptions{
+		Start: true,
+		In:    strings.NewReader("hello\nnudge\n"),
+		Out:   &out,
+		Rows:  50,
+		Cols:  200,
+	})
+	if err != nil {
+		t.Fatalf("Proxy: %v", err)
+	}
+	if code != 3 {
+		t.Errorf("exit code = %d, want 3", code)
+	}
+	if out.String() != "HELLO\r\nNUDGE\r\n" {
+		t.Errorf("output = %q", out.String())
+	}
+	if !e.containers["gt-gastown-nux"].started {
+		t.Error("container was not started")
+	}
+	if e.resized != "50x200" {
+		t.Errorf("resize = %q, want 50x200", e.resized)
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	e, c := startFakeEngine(t)
+	ctx := context.Background()
+	town := t.TempDir()
+	workDir := filepath.Join(town, "gastown", "polecats", "toast", "gastown")
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.ContainerConfig{
+		Image:   "ghcr.io/acme/toolchain:1.4",
+		CPUs:    1.5,
+		Memory:  "2g",
+		Network: "none",
+		User:    "1000:1000",
+		Mounts:  []string{"/opt/cache:/cache:ro"},
+		Env:     []string{"GOFLAGS=-mod=mod"},
+	}
+	spec := NewSpec(town, filepath.Dir(workDir), workDir, "gt-gastown-toast")
+
+	// A stale container from a crashed session is replaced.
+	if _, err := c.Create(ctx, "gt-gastown-toast", CreateRequest{Image: "old"}); err != nil {
+		t.Fatal(err)
+	}
+	cmd, err := Prepare(ctx, c, cfg, spec, "gt-gastown-toast", "exec claude")
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if want := ProxyCommand(c.Host(), "gt-gastown-toast"); cmd != want {
+		t.Errorf("command = %q, want %q", cmd, want)
+	}
+
+	req := e.containers["gt-gastown-toast"].req
+	if req.Image != cfg.Image || strings.Join(req.Cmd, " ") != "sh -c exec claude" {
+		t.Errorf("image/cmd = %q %q", req.Image, req.Cmd)
+	}
+	if req.WorkingDir != workDir || req.User != "1000:1000" || !req.Tty || !req.OpenStdin {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if req.HostConfig.NanoCPUs != 1500000000 || req.HostConfig.Memory != 2<<30 || req.HostConfig.NetworkMode != "none" {
+		t.Errorf("resources = %+v", req.HostConfig)
+	}
+	binds := strings.Join(req.HostConfig.Binds, "\n")
+	for _, want := range []string{town + ":" + town + ":ro", workDir + ":" + workDir, "/opt/cache:/cache:ro"} {
+		if !strings.Contains(binds, want) {
+			t.Errorf("binds missing %q:\n%s", want, binds)
+		}
+	}
+	if req.Labels[LabelRig] != "gastown" || req.Labels[LabelSession] != "gt-gastown-toast" {
+		t.Errorf("labels = %v", req.Labels)
+	}
+	if !strings.Contains(strings.Join(req.Env, " "), "GOFLAGS=-mod=mod") {
+		t.Errorf("env = %v", req.Env)
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		err  bool
+	}{
+		{"", 0, false},
+		{"1024", 1024, false},
+		{"512m", 512 << 20, false},
+		{"4G", 4 << 30, false},
+		{"1.5g", 3 << 29, false},
+		{"256mb", 256 << 20, false},
+		{"lots", 0, true},
+		{"-1g", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMemory(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseMemory(%q) = %d, %v; want %d, err=%v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	rigPath := t.TempDir()
+	if cfg, err := LoadConfig(rigPath); cfg != nil || err != nil {
+		t.Errorf("LoadConfig without settings = %v, %v", cfg, err)
+	}
+
+	write := func(body string) {
+		t.Helper()
+		path := config.RigSettingsPath(rigPath)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"type": "rig-settings", "version": 1, "container": {"image": "golang:1.24", "runtime": "podman"}}`)
+	cfg, err := LoadConfig(rigPath)
+	if err != nil || !cfg.IsEnabled() || cfg.Runtime != config.ContainerRuntimePodman {
+		t.Errorf("LoadConfig = %+v, %v", cfg, err)
+	}
+
+	write(`{"type": "rig-settings", "version": 1, "container": {"image": "golang:1.24"}, "sandbox": {"mode": "bwrap"}}`)
+	if _, err := LoadConfig(rigPath); err == nil {
+		t.Error("expected an error when both sandbox and container are enabled")
+	}
+}
@@ -0,0 +1,271 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/sandbox"
+)
+
+// Labels set on every polecat container.
+const (
+	LabelRig     = "gastown.rig"
+	LabelSession = "gastown.session"
+)
+
+// apiTimeout bounds the API calls made while starting or removing a container.
+const apiTimeout = 30 * time.Second
+
+// LoadConfig loads the container settings for a rig.
+// Returns nil (host execution) if the rig has no settings file or no
+// container section. Any other error is returned so that a broken settings
+// file never silently moves a polecat out of its container.
+func LoadConfig(rigPath string) (*config.ContainerConfig, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("loading rig settings: %w", err)
+	}
+	return settings.Container, nil
+}
+
+// ParseMemory parses a memory size such as "512m", "4g" or "1073741824"
+// into bytes.
+func ParseMemory(s string) (int64, error) {
+	orig := s
+	s = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "b")
+	if s == "" {
+		return 0, nil
+	}
+	mult := int64(1)
+	switch s[len(s)-1] {
+	case 'k':
+		mult = 1 << 10
+	case 'm':
+		mult = 1 << 20
+	case 'g':
+		mult = 1 << 30
+	}
+	if mult > 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid memory size %q", orig)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// Spec describes the container a polecat session needs.
+type Spec struct {
+	// TownRoot is mounted read-only so gt and bd can find the workspace.
+	TownRoot string
+
+	// WorkDir is the polecat's git worktree (becomes the container cwd).
+	WorkDir string
+
+	// Writable lists host paths bind-mounted read-write at the same path.
+	Writable []string
+
+	// Labels identify the container's rig and session.
+	Labels map[string]string
+}
+
+// NewSpec builds the standard Spec for a polecat. The writable paths are the
+// same as for the sandbox: worktree, polecat home, the rig and town beads
+// directories, the events log and the git common directory. Paths are
+// mounted at their host locations so the worktree's git links keep working.
+func NewSpec(townRoot, polecatDir, workDir, sessionID string) Spec {
+	rigPath := filepath.Dir(filepath.Dir(polecatDir))
+	return Spec{
+		TownRoot: townRoot,
+		WorkDir:  workDir,
+		Writable: sandbox.NewSpec(townRoot, polecatDir, workDir, nil).Writable,
+		Labels: map[string]string{
+			LabelRig:     filepath.Base(rigPath),
+			LabelSession: sessionID,
+		},
+	}
+}
+
+// NewCreateRequest builds the engine request that runs command in a
+// container configured by cfg.
+func NewCreateRequest(cfg *config.ContainerConfig, spec Spec, command string) (CreateRequest, error) {
+	memory, err := ParseMemory(cfg.Memory)
+	if err != nil {
+		return CreateRequest{}, err
+	}
+	if cfg.CPUs < 0 {
+		return CreateRequest{}, fmt.Errorf("invalid cpus %v", cfg.CPUs)
+	}
+
+	home, _ := os.UserHomeDir()
+	binds := []string{spec.TownRoot + ":" + spec.TownRoot + ":ro"}
+	seen := map[string]bool{spec.TownRoot: true}
+	for _, p := range spec.Writable {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		// The engine creates missing bind sources as root-owned directories.
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		binds = append(binds, p+":"+p)
+	}
+	for _, m := range cfg.Mounts {
+		if strings.HasPrefix(m, "~/") {
+			m = filepath.Join(home, m[2:])
+		}
+		binds = append(binds, m)
+	}
+
+	user := cfg.User
+	if user == "" && os.Getuid() >= 0 {
+		user = fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	}
+
+	env := append([]string{"TERM=xterm-256color", "GT_CONTAINER=" + cfg.Image}, cfg.Env...)
+
+	return CreateRequest{
+		Image:        cfg.Image,
+		Cmd:          []string{"sh", "-c", command},
+		Env:          env,
+		WorkingDir:   spec.WorkDir,
+		User:         user,
+		Labels:       spec.Labels,
+		Tty:          true,
+		OpenStdin:    true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		HostConfig: HostConfig{
+			Binds:       binds,
+			NanoCPUs:    int64(cfg.CPUs * 1e9),
+			Memory:      memory,
+			NetworkMode: cfg.Network,
+			Init:        true,
+		},
+	}, nil
+}
+
+// ProxyCommand returns the session command that attaches to and starts the
+// named container.
+func ProxyCommand(host, name string) string {
+	return fmt.Sprintf("exec gt container attach --start --host %s %s",
+		config.ShellQuote(host), config.ShellQuote(name))
+}
+
+// Prepare creates the container for a session and returns the command the
+// session should run instead of command. A leftover container with the same
+// name (from a crashed session) is removed first. The container is started
+// by the proxy once it is attached, so no early output is lost.
+func Prepare(ctx context.Context, c *Client, cfg *config.ContainerConfig, spec Spec, name, command string) (string, error) {
+	req, err := NewCreateRequest(cfg, spec, command)
+	if err != nil {
+		return "", err
+	}
+	if err := c.Ping(ctx); err != nil {
+		return "", fmt.Errorf("%w at %s: %v", ErrUnavailable, c.Host(), err)
+	}
+	if err := c.Remove(ctx, name); err != nil && !errors.Is(err, ErrNotFound) {
+		return "", fmt.Errorf("removing stale container %s: %w", name, err)
+	}
+	if _, err := c.Create(ctx, name, req); err != nil {
+		return "", fmt.Errorf("creating container %s: %w", name, err)
+	}
+	return ProxyCommand(c.Host(), name), nil
+}
+
+// WrapForRig prepares a container for a polecat session according to the
+// rig's container settings, returning the session command. Returns command
+// unchanged if the rig does not enable containers.
+func WrapForRig(townRoot, rigPath, polecatDir, workDir, sessionID, command string) (string, error) {
+	cfg, err := LoadConfig(rigPath)
+	if err != nil {
+		return "", err
+	}
+	if !cfg.IsEnabled() {
+		return command, nil
+	}
+	c, err := NewClient(HostFor(cfg))
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	return Prepare(ctx, c, cfg, NewSpec(townRoot, polecatDir, workDir, sessionID), sessionID, command)
+}
+
+// RemoveForRig removes a session's container if the rig runs polecats in
+// containers. The proxy normally removes the container when the session
+// ends; this covers sessions killed before the proxy could clean up.
+func RemoveForRig(rigPath, sessionID string) error {
+	cfg, err := LoadConfig(rigPath)
+	if err != nil || !cfg.IsEnabled() {
+		return err
+	}
+	c, err := NewClient(HostFor(cfg))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	if err := c.Remove(ctx, sessionID); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ProxyOptions configures Proxy.
+type ProxyOptions struct {
+	// Start starts the container after attaching.
+	Start bool
+
+	// In is relayed to the container's TTY; Out receives its output.
+	In  io.Reader
+	Out io.Writer
+
+	// Rows and Cols size the container's TTY when non-zero.
+	Rows, Cols int
+}
+
+// Proxy attaches to a container's TTY and relays it until the container
+// exits, returning the container's exit code.
+func Proxy(ctx context.Context, c *Client, id string, opts ProxyOptions) (int, error) {
+	stream, err := c.Attach(ctx, id)
+	if err != nil {
+		return -1, err
+	}
+	defer stream.Close()
+
+	if opts.Start {
+		if err := c.Start(ctx, id); err != nil {
+			return -1, fmt.Errorf("starting container %s: %w", id, err)
+		}
+	}
+	if opts.Rows > 0 && opts.Cols > 0 {
+		_ = c.Resize(ctx, id, opts.Rows, opts.Cols)
+	}
+
+	if opts.In != nil {
+		go func() {
+			_, _ = io.Copy(stream, opts.In)
+			_ = stream.CloseWrite()
+		}()
+	}
+	if _, err := io.Copy(opts.Out, stream); err != nil && ctx.Err() == nil {
+		return -1, fmt.Errorf("relaying container output: %w", err)
+	}
+	return c.Wait(ctx, id)
+}
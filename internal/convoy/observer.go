@@ -8,6 +8,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/steveyegge/gastown/internal/beadstore"
 )
 
 // CheckConvoysForIssue finds any convoys tracking the given issue and triggers
@@ -55,9 +57,12 @@ func CheckConvoysForIssue(townRoot, issueID, observer string, logger func(format
 }
 
 // getTrackingConvoys returns convoy IDs that track the given issue.
-// Uses direct SQLite query for efficiency (same approach as daemon/convoy_watcher).
+// Reads the cached town beads snapshot, falling back to a direct SQLite query.
 func getTrackingConvoys(townRoot, issueID string) []string {
 	townBeads := filepath.Join(townRoot, ".beads")
+	if snap, err := beadstore.For(townBeads).Snapshot(); err == nil {
+		return snap.TrackedBy(issueID)
+	}
 	dbPath := filepath.Join(townBeads, "beads.db")
 
 	// Query for convoys that track this issue
@@ -97,6 +102,11 @@ func getTrackingConvoys(townRoot, issueID string) []string {
 // isConvoyClosed checks if a convoy is already closed.
 func isConvoyClosed(townRoot, convoyID string) bool {
 	townBeads := filepath.Join(townRoot, ".beads")
+	if snap, err := beadstore.For(townBeads).Snapshot(); err == nil {
+		if rec, ok := snap.Get(convoyID); ok {
+			return rec.Status == "closed"
+		}
+	}
 	dbPath := filepath.Join(townBeads, "beads.db")
 
 	safeConvoyID := strings.ReplaceAll(convoyID, "'", "''")
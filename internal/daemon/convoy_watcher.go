@@ -12,6 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/beadstore"
 )
 
 // ConvoyWatcher monitors bd activity for issue closes and triggers convoy completion checks.
@@ -155,6 +157,9 @@ func (w *ConvoyWatcher) processLine(line string) {
 // getTrackingConvoys returns convoy IDs that track the given issue.
 func (w *ConvoyWatcher) getTrackingConvoys(issueID string) []string {
 	townBeads := filepath.Join(w.townRoot, ".beads")
+	if snap, err := beadstore.For(townBeads).Snapshot(); err == nil {
+		return snap.TrackedBy(issueID)
+	}
 	dbPath := filepath.Join(townBeads, "beads.db")
 
 	// Query for convoys that track this issue
@@ -195,31 +200,10 @@ func (w *ConvoyWatcher) getTrackingConvoys(issueID string) []string {
 // checkConvoyCompletion checks if all issues tracked by a convoy are closed.
 // If so, runs gt convoy check to close the convoy.
 func (w *ConvoyWatcher) checkConvoyCompletion(convoyID string) {
-	townBeads := filepath.Join(w.townRoot, ".beads")
-	dbPath := filepath.Join(townBeads, "beads.db")
-
 	// First check if the convoy is still open
-	convoyQuery := fmt.Sprintf(`SELECT status FROM issues WHERE id = '%s'`,
-		strings.ReplaceAll(convoyID, "'", "''"))
-
-	queryCmd := exec.Command("sqlite3", "-json", dbPath, convoyQuery)
-	queryCmd.Env = os.Environ() // Inherit PATH to find sqlite3 executable
-	var stdout bytes.Buffer
-	queryCmd.Stdout = &stdout
-
-	if err := queryCmd.Run(); err != nil {
-		return
-	}
-
-	var convoyStatus []struct {
-		Status string `json:"status"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &convoyStatus); err != nil || len(convoyStatus) == 0 {
-		return
-	}
-
-	if convoyStatus[0].Status == "closed" {
-		return // Already closed
+	status, ok := w.convoyStatus(convoyID)
+	if !ok || status == "closed" {
+		return // Unknown or already closed
 	}
 
 	// Run gt convoy check with specific convoy ID for targeted check
@@ -242,3 +226,35 @@ func (w *ConvoyWatcher) checkConvoyCompletion(convoyID string) {
 		w.logger("convoy watcher: %s", strings.TrimSpace(output))
 	}
 }
+
+// convoyStatus returns a convoy's status from the cached town beads
+// snapshot, falling back to a direct SQLite query.
+func (w *ConvoyWatcher) convoyStatus(convoyID string) (string, bool) {
+	townBeads := filepath.Join(w.townRoot, ".beads")
+	if snap, err := beadstore.For(townBeads).Snapshot(); err == nil {
+		if rec, ok := snap.Get(convoyID); ok {
+			return rec.Status, true
+		}
+	}
+
+	dbPath := filepath.Join(townBeads, "beads.db")
+	convoyQuery := fmt.Sprintf(`SELECT status FROM issues WHERE id = '%s'`,
+		strings.ReplaceAll(convoyID, "'", "''"))
+
+	queryCmd := exec.Command("sqlite3", "-json", dbPath, convoyQuery)
+	queryCmd.Env = os.Environ() // Inherit PATH to find sqlite3 executable
+	var stdout bytes.Buffer
+	queryCmd.Stdout = &stdout
+
+	if err := queryCmd.Run(); err != nil {
+		return "", false
+	}
+
+	var convoyStatus []struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &convoyStatus); err != nil || len(convoyStatus) == 0 {
+		return "", false
+	}
+	return convoyStatus[0].Status, true
+}
@@ -12,22 +12,31 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gofrs/flock"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/boot"
+	"github.com/steveyegge/gastown/internal/bridge"
+	"github.com/steveyegge/gastown/internal/cgroup"
+	"github.com/steveyegge/gastown/internal/checkpoint"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/container"
 	"github.com/steveyegge/gastown/internal/deacon"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/feed"
+	"github.com/steveyegge/gastown/internal/hookemu"
 	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/ptyd"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/tracker"
 	"github.com/steveyegge/gastown/internal/util"
 	"github.com/steveyegge/gastown/internal/wisp"
 	"github.com/steveyegge/gastown/internal/witness"
@@ -38,14 +47,17 @@ import (
 // This is recovery-focused: normal wake is handled by feed subscription (bd activity --follow).
 // The daemon is the safety net for dead sessions, GUPP violations, and orphaned work.
 type Daemon struct {
-	config       *Config
-	patrolConfig *DaemonPatrolConfig
-	tmux         *tmux.Tmux
-	logger       *log.Logger
-	ctx          context.Context
-	cancel       context.CancelFunc
-	curator      *feed.Curator
+	config        *Config
+	patrolConfig  *DaemonPatrolConfig
+	tmux          *tmux.Tmux
+	polecats      session.Backend // polecat sessions (tmux or the town's session_backend)
+	logger        *log.Logger
+	ctx           context.Context
+	cancel        context.CancelFunc
+	curator       *feed.Curator
 	convoyWatcher *ConvoyWatcher
+	bridge        *bridge.Bridge
+	hookEmulator  *hookemu.Emulator
 
 	// Mass death detection: track recent session deaths
 	deathsMu     sync.Mutex
@@ -56,6 +68,35 @@ type Daemon struct {
 	// See: https://github.com/steveyegge/gastown/issues/567
 	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
 	deaconLastStarted time.Time
+
+	// Last cgroup usage sample per polecat session, used to attribute
+	// session deaths to OOM kills after the scope has been torn down.
+	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
+	cgroupUsage map[string]*cgroup.Usage
+
+	// OOM kill count as of the sample before the last one, so a death is
+	// only blamed on kills newer than what the session already survived.
+	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
+	cgroupOOMBase map[string]int64
+
+	// Sandbox violation lines already reported per session, so each denial
+	// is logged once even though it stays visible in the pane.
+	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
+	sandboxSeen map[string]map[string]bool
+
+	// When each live polecat was last snapshotted (or first seen alive).
+	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
+	lastCheckpoint map[string]time.Time
+
+	// When imported issues were last synced back to their trackers.
+	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
+	lastImportSync time.Time
+
+	// Import sync talks to external trackers, so it runs off the heartbeat
+	// goroutine: importSyncing keeps passes from overlapping, and shutdown
+	// waits on importSyncs.
+	importSyncing atomic.Bool
+	importSyncs   sync.WaitGroup
 }
 
 // sessionDeath records a detected session death for mass death analysis.
@@ -97,6 +138,7 @@ func New(config *Config) (*Daemon, error) {
 		config:       config,
 		patrolConfig: patrolConfig,
 		tmux:         tmux.NewTmux(),
+		polecats:     session.NewBackend(config.TownRoot),
 		logger:       logger,
 		ctx:          ctx,
 		cancel:       cancel,
@@ -167,6 +209,25 @@ func (d *Daemon) Run() error {
 		d.logger.Println("Convoy watcher started")
 	}
 
+	// Start chat bridge if the town has one configured
+	if IsPatrolEnabled(d.patrolConfig, "bridge") {
+		b, err := bridge.ForTown(d.config.TownRoot, d.logger.Printf)
+		if err != nil {
+			d.logger.Printf("Warning: failed to start chat bridge: %v", err)
+		} else if b != nil {
+			d.bridge = b
+			d.bridge.Start()
+			d.logger.Println("Chat bridge started")
+		}
+	}
+
+	// Start hook emulation for agents without native hooks
+	if IsPatrolEnabled(d.patrolConfig, "hook_emulation") {
+		d.hookEmulator = hookemu.NewEmulator(d.config.TownRoot, d.hookSession, d.logger.Printf)
+		d.hookEmulator.Start()
+		d.logger.Println("Hook emulator started")
+	}
+
 	// Initial heartbeat
 	d.heartbeat(state)
 
@@ -195,6 +256,14 @@ func (d *Daemon) Run() error {
 	}
 }
 
+// hookSession returns the session backend hosting an emulated-hooks agent.
+func (d *Daemon) hookSession(backend string) hookemu.Session {
+	if backend == session.BackendPTY {
+		return ptyd.NewClient(ptyd.SocketPath(d.config.TownRoot))
+	}
+	return d.tmux
+}
+
 // recoveryHeartbeatInterval is the fixed interval for recovery-focused daemon.
 // Normal wake is handled by feed subscription (bd activity --follow).
 // The daemon is a safety net for dead sessions, GUPP violations, and orphaned work.
@@ -282,6 +351,11 @@ func (d *Daemon) heartbeat(state *State) {
 	// This is a safety net - Deacon patrol also does this more frequently.
 	d.cleanupOrphanedProcesses()
 
+	// 13. Push bead status changes back to external trackers (gt import)
+	if IsPatrolEnabled(d.patrolConfig, "import_sync") {
+		d.startImportSync()
+	}
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -666,6 +740,21 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("Convoy watcher stopped")
 	}
 
+	// Stop chat bridge
+	if d.bridge != nil {
+		d.bridge.Stop()
+		d.logger.Println("Chat bridge stopped")
+	}
+
+	// Stop hook emulator
+	if d.hookEmulator != nil {
+		d.hookEmulator.Stop()
+		d.logger.Println("Hook emulator stopped")
+	}
+
+	// Wait for an in-flight import sync to save its links
+	d.importSyncs.Wait()
+
 	state.Running = false
 	if err := SaveState(d.config.TownRoot, state); err != nil {
 		d.logger.Printf("Warning: failed to save final state: %v", err)
@@ -919,14 +1008,18 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	sessionName := fmt.Sprintf("gt-%s-%s", rigName, polecatName)
 
 	// Check if tmux session exists
-	sessionAlive, err := d.tmux.HasSession(sessionName)
+	sessionAlive, err := d.polecats.HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking session %s: %v", sessionName, err)
 		return
 	}
 
 	if sessionAlive {
-		// Session is alive - nothing to do
+		// Session is alive - sample resource usage for OOM attribution
+		// and surface any sandbox denials from the pane output
+		d.sampleCgroupUsage(sessionName)
+		d.checkSandboxViolations(rigName, polecatName, sessionName)
+		d.checkpointPolecat(rigName, polecatName, sessionName)
 		return
 	}
 
@@ -955,6 +1048,20 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	// Track this death for mass death detection
 	d.recordSessionDeath(sessionName)
 
+	// Record the death with a reason (OOM kills are attributed from the last
+	// cgroup sample, since the transient scope is gone once the session dies)
+	reason := "crash: session died with work on hook"
+	if usage := d.newOOMKills(sessionName); usage != nil {
+		reason = cgroup.OOMReason(usage)
+		d.logger.Printf("OOM KILL: polecat %s/%s %s", rigName, polecatName, reason)
+	}
+	delete(d.cgroupUsage, sessionName)
+	delete(d.cgroupOOMBase, sessionName)
+	delete(d.sandboxSeen, sessionName)
+	delete(d.lastCheckpoint, sessionName)
+	_ = events.LogFeed(events.TypeSessionDeath, fmt.Sprintf("%s/polecats/%s", rigName, polecatName),
+		events.SessionDeathPayload(sessionName, fmt.Sprintf("%s/polecats/%s", rigName, polecatName), reason, "daemon"))
+
 	// Auto-restart the polecat
 	if err := d.restartPolecatSession(rigName, polecatName, sessionName); err != nil {
 		d.logger.Printf("Error restarting polecat %s/%s: %v", rigName, polecatName, err)
@@ -965,6 +1072,136 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	}
 }
 
+// sampleCgroupUsage records the current cgroup usage for a live session.
+// Sessions not running under resource limits are skipped.
+func (d *Daemon) sampleCgroupUsage(sessionName string) {
+	pidStr, err := d.polecats.GetPanePID(sessionName)
+	if err != nil {
+		return
+	}
+	pid, err := strconv.Atoi(pidStr)
+	if err != nil {
+		return
+	}
+	usage, err := cgroup.UsageForPID(pid)
+	if err != nil {
+		return
+	}
+	if d.cgroupUsage == nil {
+		d.cgroupUsage = make(map[string]*cgroup.Usage)
+		d.cgroupOOMBase = make(map[string]int64)
+	}
+	base := usage.OOMKills // the session is alive, so it survived these
+	if prev := d.cgroupUsage[sessionName]; prev != nil {
+		if usage.OOMKills > prev.OOMKills {
+			d.logger.Printf("OOM KILL: %d process(es) killed in session %s (%s)",
+				usage.OOMKills-prev.OOMKills, sessionName, cgroup.FormatBytes(usage.MemoryMax))
+		}
+		base = prev.OOMKills
+	}
+	d.cgroupUsage[sessionName] = usage
+	d.cgroupOOMBase[sessionName] = base
+}
+
+// newOOMKills returns a dead session's cgroup usage if processes were
+// OOM-killed since the previous sample, or nil. The live cgroup is compared
+// with the last heartbeat sample if the scope still exists. Once the scope
+// is gone, the kill that ended it happened after the last sample, so
+// systemd's record of why the scope stopped decides; failing that, the last
+// sample is compared with the one before it.
+func (d *Daemon) newOOMKills(sessionName string) *cgroup.Usage {
+	last := d.cgroupUsage[sessionName]
+	if last == nil {
+		return nil
+	}
+	live, err := cgroup.UsageForPath(last.Path)
+	if err == nil {
+		if live.OOMKills > last.OOMKills {
+			return live
+		}
+		return nil
+	}
+	if last.OOMKills > d.cgroupOOMBase[sessionName] || cgroup.ScopeOOMKilled(last.Path) {
+		return last
+	}
+	return nil
+}
+
+// sandboxScanLines is how much pane output is scanned for sandbox denials.
+const sandboxScanLines = 200
+
+// checkSandboxViolations scans a sandboxed polecat's recent output for denied
+// writes or network access and emits an event for each new denial.
+func (d *Daemon) checkSandboxViolations(rigName, polecatName, sessionName string) {
+	cfg, err := sandbox.LoadConfig(filepath.Join(d.config.TownRoot, rigName))
+	if err != nil || !cfg.IsEnabled() {
+		return
+	}
+	output, err := d.polecats.CapturePane(sessionName, sandboxScanLines)
+	if err != nil {
+		return
+	}
+
+	if d.sandboxSeen == nil {
+		d.sandboxSeen = make(map[string]map[string]bool)
+	}
+	seen := d.sandboxSeen[sessionName]
+	if seen == nil {
+		seen = make(map[string]bool)
+		d.sandboxSeen[sessionName] = seen
+	}
+
+	actor := fmt.Sprintf("%s/polecats/%s", rigName, polecatName)
+	for _, v := range sandbox.DetectViolations(output) {
+		if seen[v.Line] {
+			continue
+		}
+		seen[v.Line] = true
+		d.logger.Printf("SANDBOX VIOLATION: %s %s: %s", actor, v.Kind, v.Line)
+		_ = events.LogFeed(events.TypeSandboxViolation, actor,
+			events.SandboxViolationPayload(rigName, polecatName, v.Kind, v.Line))
+	}
+}
+
+// checkpointPolecat snapshots a live polecat's uncommitted work into
+// refs/gt/checkpoints once per checkpoint interval, so a crash or a lost
+// worktree costs at most one interval of work. The first snapshot is taken
+// one interval after the session is first seen alive, which skips
+// short-lived sessions.
+func (d *Daemon) checkpointPolecat(rigName, polecatName, sessionName string) {
+	if !IsPatrolEnabled(d.patrolConfig, "checkpoints") {
+		return
+	}
+	if d.lastCheckpoint == nil {
+		d.lastCheckpoint = make(map[string]time.Time)
+	}
+	last, seen := d.lastCheckpoint[sessionName]
+	if !seen {
+		d.lastCheckpoint[sessionName] = time.Now()
+		return
+	}
+	if time.Since(last) < CheckpointInterval(d.patrolConfig) {
+		return
+	}
+	d.lastCheckpoint[sessionName] = time.Now()
+
+	// New structure: polecats/<name>/<rig>/, old structure: polecats/<name>/
+	polecatDir := filepath.Join(d.config.TownRoot, rigName, "polecats", polecatName)
+	workDir := filepath.Join(polecatDir, rigName)
+	if _, err := os.Stat(filepath.Join(workDir, ".git")); err != nil {
+		workDir = polecatDir
+	}
+
+	snap, err := checkpoint.SnapshotWorktree(workDir, polecatName, checkpoint.DefaultKeep)
+	if err != nil {
+		d.logger.Printf("Checkpoint of polecat %s/%s failed: %v", rigName, polecatName, err)
+		return
+	}
+	if snap != nil {
+		d.logger.Printf("Checkpointed polecat %s/%s uncommitted work to %s", rigName, polecatName, snap.Ref)
+	}
+}
+
 // recordSessionDeath records a session death and checks for mass death pattern.
 func (d *Daemon) recordSessionDeath(sessionName string) {
 	d.deathsMu.Lock()
@@ -1042,12 +1279,6 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 	// Pre-sync workspace (ensure beads are current)
 	d.syncWorkspace(workDir)
 
-	// Create new tmux session
-	// Use EnsureSessionFresh to handle zombie sessions that exist but have dead Claude
-	if err := d.tmux.EnsureSessionFresh(sessionName, workDir); err != nil {
-		return fmt.Errorf("creating session: %w", err)
-	}
-
 	// Set environment variables using centralized AgentEnv
 	envVars := config.AgentEnv(config.AgentEnvConfig{
 		Role:          "polecat",
@@ -1057,32 +1288,80 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 		BeadsNoDaemon: true,
 	})
 
-	// Set all env vars in tmux session (for debugging) and they'll also be exported to Claude
+	// Launch Claude with environment exported inline
+	// Pass rigPath so rig agent settings are honored (not town-level defaults)
+	startCmd := config.BuildStartupCommand(envVars, rigPath, "")
+	// Never restart a sandboxed polecat outside its sandbox
+	startCmd, err := sandbox.WrapForRig(d.config.TownRoot, rigPath,
+		filepath.Join(rigPath, "polecats", polecatName), workDir, startCmd)
+	if err != nil {
+		return fmt.Errorf("sandboxing polecat: %w", err)
+	}
+	// Likewise, a containerized polecat restarts in a fresh container
+	startCmd, err = container.WrapForRig(d.config.TownRoot, rigPath,
+		filepath.Join(rigPath, "polecats", polecatName), workDir, sessionName, startCmd)
+	if err != nil {
+		return fmt.Errorf("containerizing polecat: %w", err)
+	}
+	// Nor without its resource limits
+	roleDef, err := config.LoadRoleDefinition(d.config.TownRoot, rigPath, "polecat")
+	if err != nil {
+		return fmt.Errorf("loading polecat role: %w", err)
+	}
+	if roleDef.Resources.IsSet() {
+		if err := roleDef.Resources.Validate(); err != nil {
+			return fmt.Errorf("polecat resource limits: %w", err)
+		}
+		startCmd = cgroup.WrapCommand(cgroup.UnitName(sessionName, time.Now()), roleDef.Resources, startCmd)
+	}
+
+	t, isTmux := d.polecats.(*tmux.Tmux)
+	if isTmux {
+		// Replace a zombie session (tmux alive, Claude dead); leave a healthy one
+		if exists, err := t.HasSession(sessionName); err != nil {
+			return fmt.Errorf("checking session: %w", err)
+		} else if exists {
+			if t.IsAgentRunning(sessionName) {
+				return nil
+			}
+			if err := t.KillSessionWithProcesses(sessionName); err != nil {
+				return fmt.Errorf("killing zombie session: %w", err)
+			}
+		}
+	}
+
+	// Create the session with the command as its process, as
+	// SessionManager.Start does, so the sandbox, container and cgroup
+	// wrappers hold from the first instruction (no send-keys race).
+	if err := d.polecats.NewSessionWithCommand(sessionName, workDir, startCmd); err != nil {
+		return fmt.Errorf("creating session: %w", err)
+	}
+
+	// Set all env vars in the session (for debugging); they are also
+	// exported inline in the startup command
 	for k, v := range envVars {
-		_ = d.tmux.SetEnvironment(sessionName, k, v)
+		_ = d.polecats.SetEnvironment(sessionName, k, v)
+	}
+
+	if !isTmux {
+		_ = d.polecats.AcceptBypassPermissionsWarning(sessionName)
+		return nil
 	}
 
 	// Apply theme
 	theme := tmux.AssignTheme(rigName)
-	_ = d.tmux.ConfigureGasTownSession(sessionName, theme, rigName, polecatName, "polecat")
+	_ = t.ConfigureGasTownSession(sessionName, theme, rigName, polecatName, "polecat")
 
 	// Set pane-died hook for future crash detection
 	agentID := fmt.Sprintf("%s/%s", rigName, polecatName)
-	_ = d.tmux.SetPaneDiedHook(sessionName, agentID)
-
-	// Launch Claude with environment exported inline
-	// Pass rigPath so rig agent settings are honored (not town-level defaults)
-	startCmd := config.BuildStartupCommand(envVars, rigPath, "")
-	if err := d.tmux.SendKeys(sessionName, startCmd); err != nil {
-		return fmt.Errorf("sending startup command: %w", err)
-	}
+	_ = t.SetPaneDiedHook(sessionName, agentID)
 
 	// Wait for Claude to start, then accept bypass permissions warning if it appears.
 	// This ensures automated restarts aren't blocked by the warning dialog.
-	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
+	if err := t.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
 		// Non-fatal - Claude might still start
 	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	_ = t.AcceptBypassPermissionsWarning(sessionName)
 
 	return nil
 }
@@ -1129,3 +1408,56 @@ func (d *Daemon) cleanupOrphanedProcesses() {
 		}
 	}
 }
+
+// startImportSync starts a sync of imported beads back to their external
+// trackers in the background, at most every ImportSyncInterval and never
+// two at once, so a slow tracker can't stall the heartbeat.
+func (d *Daemon) startImportSync() {
+	if time.Since(d.lastImportSync) < ImportSyncInterval(d.patrolConfig) {
+		return
+	}
+	if !d.importSyncing.CompareAndSwap(false, true) {
+		return
+	}
+	d.lastImportSync = time.Now()
+
+	d.importSyncs.Add(1)
+	go func() {
+		defer d.importSyncs.Done()
+		defer d.importSyncing.Store(false)
+		d.syncImports()
+	}()
+}
+
+// syncImports pushes status changes of imported beads back to their
+// external trackers. Towns without imported issues skip it.
+func (d *Daemon) syncImports() {
+	store, err := tracker.LoadStoreLocked(d.config.TownRoot)
+	if err != nil {
+		d.logger.Printf("Import sync: %v", err)
+		return
+	}
+	defer store.Unlock()
+	if len(store.Links) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(d.ctx, 2*time.Minute)
+	defer cancel()
+	town := &tracker.TownBeads{TownRoot: d.config.TownRoot}
+	open := func(src *tracker.Source) (tracker.Tracker, error) { return src.Open(nil) }
+	updates := tracker.Sync(ctx, store, town, open, false)
+	if len(updates) == 0 {
+		return
+	}
+	for _, u := range updates {
+		if u.Err != nil {
+			d.logger.Printf("Import sync: %s: %v", u.Link.Ref, u.Err)
+		} else {
+			d.logger.Printf("Import sync: %s marked %s", u.Link.Ref, u.Status)
+		}
+	}
+	if err := store.Save(); err != nil {
+		d.logger.Printf("Import sync: saving links: %v", err)
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"testing"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/cgroup"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -274,3 +277,61 @@ func TestLifecycleRequest_Serialization(t *testing.T) {
 		t.Errorf("Action mismatch: got %q, want %q", loaded.Action, request.Action)
 	}
 }
+
+// stubSystemctl puts a systemctl on PATH that reports Result=oom-kill for
+// the given unit and success for any other.
+func stubSystemctl(t *testing.T, oomUnit string) {
+	t.Helper()
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nfor a in \"$@\"; do unit=$a; done\n" +
+		"if [ \"$unit\" = \"" + oomUnit + "\" ]; then echo oom-kill; else echo success; fi\n"
+	if err := os.WriteFile(filepath.Join(binDir, "systemctl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestNewOOMKills_ComparesWithPreviousSample(t *testing.T) {
+	stubSystemctl(t, "")
+	// A scope path that doesn't exist, as after the session's scope is gone
+	gone := "gastown.slice/gt-test-gone.scope"
+	d := &Daemon{
+		cgroupUsage:   map[string]*cgroup.Usage{"survivor": {Path: gone, OOMKills: 2}, "killed": {Path: gone, OOMKills: 3}},
+		cgroupOOMBase: map[string]int64{"survivor": 2, "killed": 2},
+	}
+
+	if u := d.newOOMKills("survivor"); u != nil {
+		t.Errorf("old OOM kills blamed for the death: %+v", u)
+	}
+	if u := d.newOOMKills("killed"); u == nil {
+		t.Error("new OOM kill not attributed")
+	}
+	if u := d.newOOMKills("unsampled"); u != nil {
+		t.Errorf("unsampled session attributed to OOM: %+v", u)
+	}
+}
+
+func TestNewOOMKills_ScopeKilledAfterLastSample(t *testing.T) {
+	// The kill that ends the session leaves no trace in the heartbeat
+	// samples; systemd's Result for the stopped scope records it.
+	if runtime.GOOS != "linux" {
+		t.Skip("scope results are read from systemd")
+	}
+	stubSystemctl(t, "gt-test-oom.scope")
+	d := &Daemon{
+		cgroupUsage: map[string]*cgroup.Usage{
+			"oom":     {Path: "gastown.slice/gt-test-oom.scope", MemoryMax: 1 << 30},
+			"crashed": {Path: "gastown.slice/gt-test-crash.scope", MemoryMax: 1 << 30},
+		},
+		cgroupOOMBase: map[string]int64{"oom": 0, "crashed": 0},
+	}
+
+	if u := d.newOOMKills("oom"); u == nil {
+		t.Error("scope ended by the OOM killer not attributed")
+	} else if got := cgroup.OOMReason(u); got != "oom-killed: exceeded memory_max (1.0G)" {
+		t.Errorf("OOMReason() = %q", got)
+	}
+	if u := d.newOOMKills("crashed"); u != nil {
+		t.Errorf("crash attributed to OOM: %+v", u)
+	}
+}
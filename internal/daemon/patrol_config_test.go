@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadPatrolConfig(t *testing.T) {
@@ -51,3 +52,37 @@ func TestIsPatrolEnabled_NilConfig(t *testing.T) {
 		t.Error("expected default to be enabled")
 	}
 }
+
+func TestCheckpointInterval(t *testing.T) {
+	if got := CheckpointInterval(nil); got != DefaultCheckpointInterval {
+		t.Errorf("CheckpointInterval(nil) = %v, want default", got)
+	}
+	config := &DaemonPatrolConfig{Patrols: &PatrolsConfig{
+		Checkpoints: &PatrolConfig{Enabled: false, Interval: "5m"},
+	}}
+	if got := CheckpointInterval(config); got != 5*time.Minute {
+		t.Errorf("CheckpointInterval() = %v, want 5m", got)
+	}
+	if IsPatrolEnabled(config, "checkpoints") {
+		t.Error("expected checkpoints to be disabled")
+	}
+	config.Patrols.Checkpoints.Interval = "soon"
+	if got := CheckpointInterval(config); got != DefaultCheckpointInterval {
+		t.Errorf("CheckpointInterval(invalid) = %v, want default", got)
+	}
+}
+
+func TestImportSyncInterval(t *testing.T) {
+	if got := ImportSyncInterval(nil); got != DefaultImportSyncInterval {
+		t.Errorf("ImportSyncInterval(nil) = %v, want default", got)
+	}
+	config := &DaemonPatrolConfig{Patrols: &PatrolsConfig{
+		ImportSync: &PatrolConfig{Enabled: false, Interval: "1h"},
+	}}
+	if got := ImportSyncInterval(config); got != time.Hour {
+		t.Errorf("ImportSyncInterval() = %v, want 1h", got)
+	}
+	if IsPatrolEnabled(config, "import_sync") {
+		t.Error("expected import_sync to be disabled")
+	}
+}
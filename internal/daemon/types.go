@@ -113,6 +113,22 @@ type PatrolsConfig struct {
 	Refinery *PatrolConfig `json:"refinery,omitempty"`
 	Witness  *PatrolConfig `json:"witness,omitempty"`
 	Deacon   *PatrolConfig `json:"deacon,omitempty"`
+
+	// Checkpoints snapshots the uncommitted work of live polecats into
+	// refs/gt/checkpoints every Interval (default 15m).
+	Checkpoints *PatrolConfig `json:"checkpoints,omitempty"`
+
+	// ImportSync pushes bead status changes back to external trackers
+	// (mayor/imports.json) every Interval (default 10m).
+	ImportSync *PatrolConfig `json:"import_sync,omitempty"`
+
+	// Bridge relays mail to and from a chat channel (mayor/bridge.json).
+	// It only runs when bridge.json exists.
+	Bridge *PatrolConfig `json:"bridge,omitempty"`
+
+	// HookEmulation fires SessionStart/Idle/Stop hooks for agents started
+	// under `gt hooks emulate` (runtimes without native hooks).
+	HookEmulation *PatrolConfig `json:"hook_emulation,omitempty"`
 }
 
 // DaemonPatrolConfig is the structure of mayor/daemon.json.
@@ -164,10 +180,54 @@ func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
 		if config.Patrols.Deacon != nil {
 			return config.Patrols.Deacon.Enabled
 		}
+	case "checkpoints":
+		if config.Patrols.Checkpoints != nil {
+			return config.Patrols.Checkpoints.Enabled
+		}
+	case "import_sync":
+		if config.Patrols.ImportSync != nil {
+			return config.Patrols.ImportSync.Enabled
+		}
+	case "bridge":
+		if config.Patrols.Bridge != nil {
+			return config.Patrols.Bridge.Enabled
+		}
+	case "hook_emulation":
+		if config.Patrols.HookEmulation != nil {
+			return config.Patrols.HookEmulation.Enabled
+		}
 	}
 	return true // Default: enabled
 }
 
+// DefaultCheckpointInterval is how often live polecats are snapshotted.
+const DefaultCheckpointInterval = 15 * time.Minute
+
+// CheckpointInterval returns the configured polecat snapshot interval.
+func CheckpointInterval(config *DaemonPatrolConfig) time.Duration {
+	if config == nil || config.Patrols == nil || config.Patrols.Checkpoints == nil {
+		return DefaultCheckpointInterval
+	}
+	if d, err := time.ParseDuration(config.Patrols.Checkpoints.Interval); err == nil && d > 0 {
+		return d
+	}
+	return DefaultCheckpointInterval
+}
+
+// DefaultImportSyncInterval is how often imported issues are synced back.
+const DefaultImportSyncInterval = 10 * time.Minute
+
+// ImportSyncInterval returns the configured tracker sync interval.
+func ImportSyncInterval(config *DaemonPatrolConfig) time.Duration {
+	if config == nil || config.Patrols == nil || config.Patrols.ImportSync == nil {
+		return DefaultImportSyncInterval
+	}
+	if d, err := time.ParseDuration(config.Patrols.ImportSync.Interval); err == nil && d > 0 {
+		return d
+	}
+	return DefaultImportSyncInterval
+}
+
 // LifecycleAction represents a lifecycle request action.
 type LifecycleAction string
 
@@ -0,0 +1,230 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/steveyegge/gastown/internal/claude"
+	"github.com/steveyegge/gastown/internal/codex"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/runtime"
+)
+
+// RuntimeSettingsCheck verifies the settings installed for agents that run on
+// Gemini CLI or Codex: .gemini/settings.json must carry Gas Town's role hooks,
+// and Codex needs its .codex/config.toml and an AGENTS.md.
+type RuntimeSettingsCheck struct {
+	FixableCheck
+	problems []runtimeSettingsProblem
+}
+
+type runtimeSettingsProblem struct {
+	workDir string                // Directory settings are installed in
+	role    string                // Agent role (polecat, refinery, crew)
+	rc      *config.RuntimeConfig // Runtime the agent uses
+	path    string                // Invalid file to replace (empty if only missing files)
+	issues  []string
+}
+
+// runtimeAgentDir is an agent location whose runtime settings are managed by
+// runtime.EnsureSettingsForRole.
+type runtimeAgentDir struct {
+	workDir string
+	role    string
+	rc      *config.RuntimeConfig
+}
+
+// NewRuntimeSettingsCheck creates a new Gemini/Codex settings check.
+func NewRuntimeSettingsCheck() *RuntimeSettingsCheck {
+	return &RuntimeSettingsCheck{
+		FixableCheck: FixableCheck{
+			BaseCheck: BaseCheck{
+				CheckName:        "runtime-settings",
+				CheckDescription: "Verify Gemini and Codex agent settings carry Gas Town hooks",
+				CheckCategory:    CategoryConfig,
+			},
+		},
+	}
+}
+
+// Run checks the settings of every agent location using Gemini or Codex.
+func (c *RuntimeSettingsCheck) Run(ctx *CheckContext) *CheckResult {
+	c.problems = nil
+
+	checked := 0
+	for _, dir := range c.agentDirs(ctx.TownRoot) {
+		var issues []string
+		var invalid string
+		switch dir.rc.Hooks.Provider {
+		case "gemini":
+			invalid, issues = checkGeminiSettings(dir)
+		case "codex":
+			invalid, issues = checkCodexConfig(dir)
+		default:
+			continue
+		}
+		checked++
+		if len(issues) > 0 {
+			c.problems = append(c.problems, runtimeSettingsProblem{
+				workDir: dir.workDir,
+				role:    dir.role,
+				rc:      dir.rc,
+				path:    invalid,
+				issues:  issues,
+			})
+		}
+	}
+
+	if checked == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No agents use Gemini or Codex",
+		}
+	}
+
+	if len(c.problems) == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: fmt.Sprintf("%d Gemini/Codex agent location(s) configured", checked),
+		}
+	}
+
+	var details []string
+	for _, p := range c.problems {
+		rel, err := filepath.Rel(ctx.TownRoot, p.workDir)
+		if err != nil {
+			rel = p.workDir
+		}
+		details = append(details, fmt.Sprintf("%s (%s): %s", rel, p.rc.Hooks.Provider, strings.Join(p.issues, ", ")))
+	}
+
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusWarning,
+		Message: fmt.Sprintf("%d Gemini/Codex agent location(s) with missing or stale settings", len(c.problems)),
+		Details: details,
+		FixHint: "Run 'gt doctor --fix' to reinstall them (restart affected agents afterwards)",
+	}
+}
+
+// Fix replaces invalid settings files and installs missing ones. An existing
+// AGENTS.md is never rewritten.
+func (c *RuntimeSettingsCheck) Fix(ctx *CheckContext) error {
+	var errs []string
+	for _, p := range c.problems {
+		if p.path != "" {
+			if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Sprintf("%s: %v", p.path, err))
+				continue
+			}
+		}
+		if err := runtime.EnsureSettingsForRole(p.workDir, p.role, p.rc); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", p.workDir, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// agentDirs lists the locations runtime settings are installed in, with the
+// runtime each resolves to, mirroring the session managers: polecats/ and
+// crew/<name>/ use the rig runtime, refinery/ the refinery role's agent.
+func (c *RuntimeSettingsCheck) agentDirs(townRoot string) []runtimeAgentDir {
+	rigsConfig, err := loadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return nil
+	}
+	rigNames := make([]string, 0, len(rigsConfig.Rigs))
+	for name := range rigsConfig.Rigs {
+		rigNames = append(rigNames, name)
+	}
+	sort.Strings(rigNames)
+
+	var dirs []runtimeAgentDir
+	for _, rigName := range rigNames {
+		rigPath := filepath.Join(townRoot, rigName)
+		if _, err := os.Stat(rigPath); err != nil {
+			continue
+		}
+		rigRuntime := config.LoadRuntimeConfig(rigPath)
+
+		if dirExists(filepath.Join(rigPath, "polecats")) {
+			dirs = append(dirs, runtimeAgentDir{filepath.Join(rigPath, "polecats"), "polecat", rigRuntime})
+		}
+		if dirExists(filepath.Join(rigPath, "refinery")) {
+			rc := config.ResolveRoleAgentConfig("refinery", townRoot, rigPath)
+			if rc.Hooks == nil {
+				rc.Hooks = config.DefaultHooksConfig(rc.Provider)
+			}
+			dirs = append(dirs, runtimeAgentDir{filepath.Join(rigPath, "refinery"), "refinery", rc})
+		}
+		crewEntries, _ := os.ReadDir(filepath.Join(rigPath, "crew"))
+		for _, entry := range crewEntries {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+				dirs = append(dirs, runtimeAgentDir{filepath.Join(rigPath, "crew", entry.Name()), "crew", rigRuntime})
+			}
+		}
+	}
+	return dirs
+}
+
+// checkGeminiSettings validates .gemini/settings.json. It returns the file
+// path if the file exists but is stale, and the issues found.
+func checkGeminiSettings(dir runtimeAgentDir) (string, []string) {
+	path := filepath.Join(dir.workDir, dir.rc.Hooks.Dir, dir.rc.Hooks.SettingsFile)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed from town layout
+	if err != nil {
+		return "", []string{"missing " + filepath.Join(dir.rc.Hooks.Dir, dir.rc.Hooks.SettingsFile)}
+	}
+
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return path, []string{"invalid JSON"}
+	}
+	hooks, _ := settings["hooks"].(map[string]any)
+
+	// Gemini's hooks use the same shape as Claude's settings.json
+	var issues []string
+	check := new(ClaudeSettingsCheck)
+	if !check.hookHasPattern(hooks, "SessionStart", "gt prime") {
+		issues = append(issues, "SessionStart hook missing gt prime")
+	}
+	if claude.RoleTypeFor(dir.role) == claude.Autonomous && !check.hookHasPattern(hooks, "SessionStart", "gt mail check --inject") {
+		issues = append(issues, "SessionStart hook missing mail injection")
+	}
+	if !check.hookHasPattern(hooks, "BeforeTool", "gt tap guard") {
+		issues = append(issues, "BeforeTool hook missing gt tap guard")
+	}
+	if len(issues) == 0 {
+		return "", nil
+	}
+	return path, issues
+}
+
+// checkCodexConfig validates .codex/config.toml and AGENTS.md. It returns
+// the config path if the config exists but is stale, and the issues found.
+func checkCodexConfig(dir runtimeAgentDir) (string, []string) {
+	var issues []string
+	if !fileExists(filepath.Join(dir.workDir, codex.InstructionsFile)) {
+		issues = append(issues, "missing "+codex.InstructionsFile)
+	}
+
+	path := filepath.Join(dir.workDir, dir.rc.Hooks.Dir, dir.rc.Hooks.SettingsFile)
+	if _, err := os.Stat(path); err != nil {
+		return "", append(issues, "missing "+filepath.Join(dir.rc.Hooks.Dir, dir.rc.Hooks.SettingsFile))
+	}
+	var cfg map[string]any
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		return path, append(issues, "invalid TOML")
+	}
+	return "", issues
+}
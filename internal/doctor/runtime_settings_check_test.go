@@ -0,0 +1,115 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// setupRuntimeRig creates a rig whose agents use the given runtime provider.
+func setupRuntimeRig(t *testing.T, townRoot, rigName, provider string) string {
+	t.Helper()
+	setupRigConfig(t, townRoot, []string{rigName})
+	rigPath := filepath.Join(townRoot, rigName)
+	if err := os.MkdirAll(filepath.Join(rigPath, "polecats"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := config.NewRigSettings()
+	settings.Runtime = &config.RuntimeConfig{Provider: provider}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+	return rigPath
+}
+
+func TestRuntimeSettingsCheck_NoGeminiOrCodex(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupRigConfig(t, tmpDir, []string{"gastown"})
+	if err := os.MkdirAll(filepath.Join(tmpDir, "gastown", "polecats"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewRuntimeSettingsCheck().Run(&CheckContext{TownRoot: tmpDir})
+	if result.Status != StatusOK {
+		t.Errorf("expected StatusOK for claude rigs, got %v: %v", result.Status, result.Details)
+	}
+}
+
+func TestRuntimeSettingsCheck_GeminiMissingThenFixed(t *testing.T) {
+	tmpDir := t.TempDir()
+	rigPath := setupRuntimeRig(t, tmpDir, "gastown", "gemini")
+
+	check := NewRuntimeSettingsCheck()
+	ctx := &CheckContext{TownRoot: tmpDir}
+	if result := check.Run(ctx); result.Status != StatusWarning {
+		t.Fatalf("expected StatusWarning for missing gemini settings, got %v", result.Status)
+	}
+
+	if err := check.Fix(ctx); err != nil {
+		t.Fatalf("Fix() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rigPath, "polecats", ".gemini", "settings.json")); err != nil {
+		t.Fatalf("gemini settings not installed: %v", err)
+	}
+	if result := check.Run(ctx); result.Status != StatusOK {
+		t.Errorf("expected StatusOK after fix, got %v: %v", result.Status, result.Details)
+	}
+}
+
+func TestRuntimeSettingsCheck_GeminiStaleHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	rigPath := setupRuntimeRig(t, tmpDir, "gastown", "gemini")
+	path := filepath.Join(rigPath, "polecats", ".gemini", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"hooks":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	check := NewRuntimeSettingsCheck()
+	ctx := &CheckContext{TownRoot: tmpDir}
+	result := check.Run(ctx)
+	if result.Status != StatusWarning || len(result.Details) != 1 {
+		t.Fatalf("expected one stale location, got %v: %v", result.Status, result.Details)
+	}
+
+	if err := check.Fix(ctx); err != nil {
+		t.Fatalf("Fix() error = %v", err)
+	}
+	if result := check.Run(ctx); result.Status != StatusOK {
+		t.Errorf("expected StatusOK after fix, got %v: %v", result.Status, result.Details)
+	}
+}
+
+func TestRuntimeSettingsCheck_CodexKeepsAgentsMD(t *testing.T) {
+	tmpDir := t.TempDir()
+	rigPath := setupRuntimeRig(t, tmpDir, "gastown", "codex")
+	crewDir := filepath.Join(rigPath, "crew", "max")
+	if err := os.MkdirAll(crewDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	agentsMD := filepath.Join(crewDir, "AGENTS.md")
+	if err := os.WriteFile(agentsMD, []byte("# Repo rules\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := NewRuntimeSettingsCheck()
+	ctx := &CheckContext{TownRoot: tmpDir}
+	result := check.Run(ctx)
+	if result.Status != StatusWarning || len(result.Details) != 2 {
+		t.Fatalf("expected polecats and crew flagged, got %v: %v", result.Status, result.Details)
+	}
+
+	if err := check.Fix(ctx); err != nil {
+		t.Fatalf("Fix() error = %v", err)
+	}
+	if result := check.Run(ctx); result.Status != StatusOK {
+		t.Errorf("expected StatusOK after fix, got %v: %v", result.Status, result.Details)
+	}
+	if data, _ := os.ReadFile(agentsMD); string(data) != "# Repo rules\n" {
+		t.Errorf("existing AGENTS.md was rewritten: %q", data)
+	}
+}
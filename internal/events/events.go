@@ -54,6 +54,13 @@ const (
 	TypeSessionDeath = "session_death" // Feed-visible session termination
 	TypeMassDeath    = "mass_death"    // Multiple sessions died in short window
 
+	// Guard events (emitted by gt tap guard)
+	TypeGuardBlocked = "guard_blocked"
+	TypeGuardWarned  = "guard_warned"
+
+	// Sandbox events
+	TypeSandboxViolation = "sandbox_violation" // Sandboxed agent hit a denied write or network access
+
 	// Witness patrol events
 	TypePatrolStarted   = "patrol_started"
 	TypePolecatChecked  = "polecat_checked"
@@ -64,10 +71,19 @@ const (
 	TypePatrolComplete   = "patrol_complete"
 
 	// Merge queue events (emitted by refinery)
-	TypeMergeStarted = "merge_started"
-	TypeMerged       = "merged"
-	TypeMergeFailed  = "merge_failed"
-	TypeMergeSkipped = "merge_skipped"
+	TypeMergeStarted  = "merge_started"
+	TypeMerged        = "merged"
+	TypeMergeFailed   = "merge_failed"
+	TypeMergeSkipped  = "merge_skipped"
+	TypeMergeReverted = "merge_reverted" // post-merge check failed on the target branch
+
+	// Code review gate events (reviewer is the actor)
+	TypeMergeApproved         = "merge_approved"
+	TypeMergeChangesRequested = "merge_changes_requested"
+
+	// Incoming webhook events (CI and forge notifications)
+	TypeBuildFailed = "build_failed"
+	TypeDeploy      = "deploy"
 )
 
 // EventsFile is the name of the raw events log.
@@ -146,6 +162,27 @@ func SlingPayload(beadID, target string) map[string]interface{} {
 	}
 }
 
+// SlingRuntimePayload creates a sling payload that also records how the
+// target's session was started, for per-runtime stats.
+// runtime: agent the polecat runs (e.g., "claude", "gemini")
+// model: model selected for the session (may be empty)
+// route: model route that made the selection (may be empty)
+// formula: formula applied to the work (may be empty)
+func SlingRuntimePayload(beadID, target, runtime, model, route, formula string) map[string]interface{} {
+	p := SlingPayload(beadID, target)
+	for k, v := range map[string]string{
+		"runtime": runtime,
+		"model":   model,
+		"route":   route,
+		"formula": formula,
+	} {
+		if v != "" {
+			p[k] = v
+		}
+	}
+	return p
+}
+
 // HookPayload creates a payload for hook events.
 func HookPayload(beadID string) map[string]interface{} {
 	return map[string]interface{}{
@@ -257,6 +294,17 @@ func EscalationPayload(rig, target, to, reason string) map[string]interface{} {
 	}
 }
 
+// WebhookPayload creates a payload for events received by webhook.
+func WebhookPayload(rig, repo, branch, sha, url string) map[string]interface{} {
+	return map[string]interface{}{
+		"rig":    rig,
+		"repo":   repo,
+		"branch": branch,
+		"sha":    sha,
+		"url":    url,
+	}
+}
+
 // UnhookPayload creates a payload for unhook events.
 func UnhookPayload(beadID string) map[string]interface{} {
 	return map[string]interface{}{
@@ -294,6 +342,37 @@ func SessionDeathPayload(session, agent, reason, caller string) map[string]inter
 	}
 }
 
+// GuardPayload creates a payload for guard block/warn events.
+// rule: name of the guard rule that fired
+// tool: tool name from the hook input (e.g., "Bash", "Edit")
+// target: the command or file path being guarded
+// detail: checker-specific detail (may be empty)
+func GuardPayload(rule, tool, target, detail string) map[string]interface{} {
+	p := map[string]interface{}{
+		"rule":   rule,
+		"tool":   tool,
+		"target": target,
+	}
+	if detail != "" {
+		p["detail"] = detail
+	}
+	return p
+}
+
+// SandboxViolationPayload creates a payload for sandbox violation events.
+// rig: rig the polecat belongs to
+// polecat: polecat name
+// kind: "write" or "network"
+// detail: the output line that revealed the denial
+func SandboxViolationPayload(rig, polecat, kind, detail string) map[string]interface{} {
+	return map[string]interface{}{
+		"rig":     rig,
+		"polecat": polecat,
+		"kind":    kind,
+		"detail":  detail,
+	}
+}
+
 // MassDeathPayload creates a payload for mass death events.
 // count: number of sessions that died
 // window: time window in which deaths occurred (e.g., "5s")
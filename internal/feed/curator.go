@@ -365,6 +365,24 @@ func (c *Curator) generateSummary(event *events.Event) string {
 		}
 		return "Merge failed"
 
+	case events.TypeMergeReverted:
+		if mr, ok := event.Payload["mr"].(string); ok {
+			return fmt.Sprintf("Reverted %s: post-merge check failed", mr)
+		}
+		return "Merge reverted"
+
+	case events.TypeMergeApproved:
+		if mr, ok := event.Payload["mr"].(string); ok {
+			return fmt.Sprintf("%s approved %s", event.Actor, mr)
+		}
+		return fmt.Sprintf("%s approved a merge request", event.Actor)
+
+	case events.TypeMergeChangesRequested:
+		if mr, ok := event.Payload["mr"].(string); ok {
+			return fmt.Sprintf("%s requested changes on %s", event.Actor, mr)
+		}
+		return fmt.Sprintf("%s requested changes", event.Actor)
+
 	case events.TypeSessionDeath:
 		session, _ := event.Payload["session"].(string)
 		reason, _ := event.Payload["reason"].(string)
@@ -387,6 +405,14 @@ func (c *Curator) generateSummary(event *events.Event) string {
 		}
 		return "Multiple sessions died simultaneously"
 
+	case events.TypeSandboxViolation:
+		kind, _ := event.Payload["kind"].(string)
+		detail, _ := event.Payload["detail"].(string)
+		if kind != "" && detail != "" {
+			return fmt.Sprintf("Sandbox blocked %s by %s: %s", kind, event.Actor, detail)
+		}
+		return fmt.Sprintf("Sandbox blocked %s", event.Actor)
+
 	default:
 		return fmt.Sprintf("%s: %s", event.Actor, event.Type)
 	}
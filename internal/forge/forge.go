@@ -0,0 +1,319 @@
+// Package forge talks to git forges (GitHub, GitLab, Gitea) over their REST
+// APIs so the refinery can land work through pull requests on repositories
+// whose target branch is protected against direct pushes.
+//
+// Each forge is reduced to the handful of operations landing needs: open or
+// update a pull request for a branch, read its merge and check state, and
+// merge it. GitLab's merge requests are called pull requests here too.
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Forge kinds.
+const (
+	KindGitHub = "github"
+	KindGitLab = "gitlab"
+	KindGitea  = "gitea"
+)
+
+// Default API endpoints. Gitea is always self-hosted and has no default.
+const (
+	DefaultGitHubAPI = "https://api.github.com"
+	DefaultGitLabAPI = "https://gitlab.com/api/v4"
+)
+
+// PRState is the lifecycle state of a pull request.
+type PRState string
+
+const (
+	PROpen   PRState = "open"
+	PRMerged PRState = "merged"
+	PRClosed PRState = "closed"
+)
+
+// ChecksState summarizes the CI checks on a pull request's head commit.
+type ChecksState string
+
+const (
+	ChecksPending ChecksState = "pending"
+	ChecksSuccess ChecksState = "success"
+	ChecksFailure ChecksState = "failure"
+)
+
+// Mergeability is whether the forge will let a pull request merge.
+type Mergeability string
+
+const (
+	// MergeUnknown means the forge is still computing mergeability, or is
+	// waiting on something other than checks (e.g. required approvals).
+	MergeUnknown  Mergeability = "unknown"
+	MergeClean    Mergeability = "clean"
+	MergeConflict Mergeability = "conflict"
+)
+
+// PullRequest is the forge-neutral view of a pull (or merge) request.
+type PullRequest struct {
+	Number    int          `json:"number"`
+	URL       string       `json:"url"`
+	Head      string       `json:"head"`
+	Base      string       `json:"base"`
+	HeadSHA   string       `json:"head_sha"`
+	State     PRState      `json:"state"`
+	Mergeable Mergeability `json:"mergeable"`
+	Checks    ChecksState  `json:"checks"`
+
+	// MergeCommit is the commit the PR landed as, once merged.
+	MergeCommit string `json:"merge_commit,omitempty"`
+}
+
+// Forge is a git hosting service that can land branches through pull
+// requests.
+type Forge interface {
+	// Name returns the forge kind (github, gitlab, gitea).
+	Name() string
+
+	// EnsurePR returns the open pull request from head into base, creating
+	// it if none exists and refreshing its title and body if one does.
+	EnsurePR(ctx context.Context, head, base, title, body string) (*PullRequest, error)
+
+	// GetPR reads a pull request with its current mergeability and checks.
+	GetPR(ctx context.Context, number int) (*PullRequest, error)
+
+	// MergePR merges a pull request with the given method (squash, merge or
+	// rebase) and returns the resulting commit on the base branch. The
+	// merge is refused if the head moved away from pr.HeadSHA.
+	MergePR(ctx context.Context, pr *PullRequest, method string) (string, error)
+}
+
+// Config selects and authenticates a forge.
+type Config struct {
+	// Kind is github, gitlab or gitea.
+	Kind string
+
+	// APIURL is the REST API root. Defaults per kind; required for Gitea.
+	APIURL string
+
+	// Repo is the repository path, "owner/name" (GitLab allows nested
+	// groups: "group/sub/name").
+	Repo string
+
+	// Token authenticates API requests.
+	Token string
+
+	// HTTPClient overrides the default client (used by tests).
+	HTTPClient *http.Client
+}
+
+// New returns the forge described by cfg.
+func New(cfg Config) (Forge, error) {
+	if strings.Count(strings.Trim(cfg.Repo, "/"), "/") < 1 {
+		return nil, fmt.Errorf("forge repo %q must be owner/name", cfg.Repo)
+	}
+	repo := strings.Trim(cfg.Repo, "/")
+
+	switch cfg.Kind {
+	case KindGitHub:
+		api := cfg.APIURL
+		if api == "" {
+			api = DefaultGitHubAPI
+		}
+		return &GitHub{repo: repo, c: newClient(api, cfg.HTTPClient, map[string]string{
+			"Authorization": "Bearer " + cfg.Token,
+			"Accept":        "application/vnd.github+json",
+		})}, nil
+	case KindGitLab:
+		api := cfg.APIURL
+		if api == "" {
+			api = DefaultGitLabAPI
+		}
+		return &GitLab{project: url.PathEscape(repo), c: newClient(api, cfg.HTTPClient, map[string]string{
+			"PRIVATE-TOKEN": cfg.Token,
+		})}, nil
+	case KindGitea:
+		if cfg.APIURL == "" {
+			return nil, fmt.Errorf("gitea forge needs an api_url (e.g. https://gitea.example.com/api/v1)")
+		}
+		return &Gitea{repo: repo, c: newClient(cfg.APIURL, cfg.HTTPClient, map[string]string{
+			"Authorization": "token " + cfg.Token,
+		})}, nil
+	default:
+		return nil, fmt.Errorf("unknown forge %q (want github, gitlab or gitea)", cfg.Kind)
+	}
+}
+
+// DefaultTokenEnv returns the environment variable a forge's token is read
+// from when none is configured.
+func DefaultTokenEnv(kind string) string {
+	switch kind {
+	case KindGitLab:
+		return "GITLAB_TOKEN"
+	case KindGitea:
+		return "GITEA_TOKEN"
+	default:
+		return "GITHUB_TOKEN"
+	}
+}
+
+// RepoFromRemoteURL extracts the "owner/name" repository path from a git
+// remote URL in any of the usual forms:
+//
+//	https://github.com/owner/name.git
+//	git@github.com:owner/name.git
+//	ssh://git@gitlab.example.com:2222/group/sub/name
+func RepoFromRemoteURL(remote string) (string, error) {
+	remote = strings.TrimSpace(remote)
+	var path string
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		path = u.Path
+	} else if i := strings.Index(remote, ":"); i > 0 && !strings.Contains(remote[:i], "/") {
+		path = remote[i+1:] // scp-like: user@host:path
+	} else {
+		return "", fmt.Errorf("cannot parse remote URL %q", remote)
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if strings.Count(path, "/") < 1 {
+		return "", fmt.Errorf("remote URL %q has no owner/name path", remote)
+	}
+	return path, nil
+}
+
+// APIError is a non-2xx response from a forge API.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+// IsNotMergeable reports whether err is a forge refusing a merge because the
+// pull request is not (yet) mergeable: checks, approvals, conflicts, or a
+// head that moved.
+func IsNotMergeable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusMethodNotAllowed, http.StatusNotAcceptable, http.StatusConflict, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+// client is a minimal JSON REST client shared by the forges.
+type client struct {
+	base    string
+	http    *http.Client
+	headers map[string]string
+}
+
+func newClient(base string, hc *http.Client, headers map[string]string) *client {
+	if hc == nil {
+		hc = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &client{base: strings.TrimRight(base, "/"), http: hc, headers: headers}
+}
+
+// do sends a JSON request and decodes a JSON response into out (if non-nil).
+func (c *client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: errorMessage(data)}
+	}
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// errorMessage pulls the human-readable message out of a forge error body.
+func errorMessage(data []byte) string {
+	var body struct {
+		Message json.RawMessage `json:"message"`
+		Error   string          `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err == nil {
+		var s string
+		if json.Unmarshal(body.Message, &s) == nil && s != "" {
+			return s
+		}
+		if len(body.Message) > 0 && string(body.Message) != "null" {
+			return string(body.Message) // GitLab sometimes returns an object or list
+		}
+		if body.Error != "" {
+			return body.Error
+		}
+	}
+	s := strings.TrimSpace(string(data))
+	if len(s) > 200 {
+		s = s[:200]
+	}
+	return s
+}
+
+// combineChecks folds individual check results into one state: any failure
+// fails, anything unfinished is pending, otherwise success. No checks at all
+// counts as success; the forge still enforces required checks at merge time.
+func combineChecks(states ...ChecksState) ChecksState {
+	result := ChecksSuccess
+	for _, s := range states {
+		switch s {
+		case ChecksFailure:
+			return ChecksFailure
+		case ChecksPending:
+			result = ChecksPending
+		}
+	}
+	return result
+}
@@ -0,0 +1,292 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// stub is a local HTTP stand-in for a forge API. Routes map "METHOD path"
+// (path without query) to a handler; every request is recorded.
+type stub struct {
+	t      *testing.T
+	routes map[string]func(body map[string]interface{}) (int, interface{})
+	calls  []string
+	header http.Header
+}
+
+func newStub(t *testing.T) (*stub, *httptest.Server) {
+	s := &stub{t: t, routes: map[string]func(map[string]interface{}) (int, interface{}){}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.EscapedPath()
+		s.calls = append(s.calls, key)
+		s.header = r.Header.Clone()
+		h, ok := s.routes[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		code, out := h(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if out != nil {
+			_ = json.NewEncoder(w).Encode(out)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func (s *stub) on(key string, code int, out interface{}) {
+	s.routes[key] = func(map[string]interface{}) (int, interface{}) { return code, out }
+}
+
+func (s *stub) called(key string) bool {
+	for _, c := range s.calls {
+		if c == key {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGitHub_Landing(t *testing.T) {
+	s, srv := newStub(t)
+	f, err := New(Config{Kind: KindGitHub, APIURL: srv.URL, Repo: "acme/widgets", Token: "tok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr := map[string]interface{}{
+		"number": 7, "html_url": "https://github.com/acme/widgets/pull/7", "state": "open",
+		"mergeable": true, "mergeable_state": "clean",
+		"head": map[string]string{"ref": "gt/land/gt-abc", "sha": "abc123"},
+		"base": map[string]string{"ref": "main"},
+	}
+	s.on("GET /repos/acme/widgets/pulls", 200, []interface{}{})
+	var created map[string]interface{}
+	s.routes["POST /repos/acme/widgets/pulls"] = func(body map[string]interface{}) (int, interface{}) {
+		created = body
+		return 201, pr
+	}
+
+	got, err := f.EnsurePR(context.Background(), "gt/land/gt-abc", "main", "feat: widgets", "body")
+	if err != nil {
+		t.Fatalf("EnsurePR: %v", err)
+	}
+	if got.Number != 7 || got.HeadSHA != "abc123" || got.Mergeable != MergeClean {
+		t.Errorf("EnsurePR = %+v", got)
+	}
+	if created["head"] != "gt/land/gt-abc" || created["base"] != "main" {
+		t.Errorf("create body = %v", created)
+	}
+	if s.header.Get("Authorization") != "Bearer tok" {
+		t.Errorf("Authorization = %q", s.header.Get("Authorization"))
+	}
+
+	// An existing PR is updated rather than duplicated.
+	s.on("GET /repos/acme/widgets/pulls", 200, []interface{}{pr})
+	s.on("PATCH /repos/acme/widgets/pulls/7", 200, pr)
+	if _, err := f.EnsurePR(context.Background(), "gt/land/gt-abc", "main", "feat: widgets", "body"); err != nil {
+		t.Fatalf("EnsurePR (update): %v", err)
+	}
+	if !s.called("PATCH /repos/acme/widgets/pulls/7") {
+		t.Error("existing PR was not updated")
+	}
+
+	// Checks: a green status plus one running check run is pending.
+	s.on("GET /repos/acme/widgets/pulls/7", 200, pr)
+	s.on("GET /repos/acme/widgets/commits/abc123/status", 200, map[string]interface{}{"state": "success", "total_count": 1})
+	s.on("GET /repos/acme/widgets/commits/abc123/check-runs", 200, map[string]interface{}{
+		"check_runs": []map[string]string{{"status": "in_progress"}},
+	})
+	got, err = f.GetPR(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetPR: %v", err)
+	}
+	if got.Checks != ChecksPending {
+		t.Errorf("Checks = %s, want pending", got.Checks)
+	}
+
+	s.on("GET /repos/acme/widgets/commits/abc123/check-runs", 200, map[string]interface{}{
+		"check_runs": []map[string]string{{"status": "completed", "conclusion": "failure"}},
+	})
+	got, _ = f.GetPR(context.Background(), 7)
+	if got.Checks != ChecksFailure {
+		t.Errorf("Checks = %s, want failure", got.Checks)
+	}
+
+	var mergeReq map[string]interface{}
+	s.routes["PUT /repos/acme/widgets/pulls/7/merge"] = func(body map[string]interface{}) (int, interface{}) {
+		mergeReq = body
+		return 200, map[string]interface{}{"sha": "def456", "merged": true}
+	}
+	sha, err := f.MergePR(context.Background(), got, "squash")
+	if err != nil {
+		t.Fatalf("MergePR: %v", err)
+	}
+	if sha != "def456" {
+		t.Errorf("merge sha = %q", sha)
+	}
+	if mergeReq["merge_method"] != "squash" || mergeReq["sha"] != "abc123" {
+		t.Errorf("merge body = %v", mergeReq)
+	}
+
+	s.on("PUT /repos/acme/widgets/pulls/7/merge", 405, map[string]string{"message": "Required status check is expected"})
+	_, err = f.MergePR(context.Background(), got, "squash")
+	if !IsNotMergeable(err) {
+		t.Errorf("MergePR error = %v, want not-mergeable", err)
+	}
+	if !strings.Contains(err.Error(), "Required status check") {
+		t.Errorf("error should carry the forge message: %v", err)
+	}
+}
+
+func TestGitLab_Landing(t *testing.T) {
+	s, srv := newStub(t)
+	f, err := New(Config{Kind: KindGitLab, APIURL: srv.URL, Repo: "group/sub/widgets", Token: "tok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mr := map[string]interface{}{
+		"iid": 3, "web_url": "https://gitlab.com/group/sub/widgets/-/merge_requests/3", "state": "opened",
+		"source_branch": "gt/land/gt-abc", "target_branch": "main", "sha": "abc123",
+		"detailed_merge_status": "mergeable", "head_pipeline": map[string]string{"status": "running"},
+	}
+	base := "/projects/group%2Fsub%2Fwidgets/merge_requests"
+	s.on("GET "+base, 200, []interface{}{})
+	s.on("POST "+base, 201, mr)
+
+	got, err := f.EnsurePR(context.Background(), "gt/land/gt-abc", "main", "t", "b")
+	if err != nil {
+		t.Fatalf("EnsurePR: %v", err)
+	}
+	if got.Number != 3 || got.Checks != ChecksPending || got.Mergeable != MergeClean {
+		t.Errorf("EnsurePR = %+v", got)
+	}
+	if s.header.Get("PRIVATE-TOKEN") != "tok" {
+		t.Errorf("PRIVATE-TOKEN = %q", s.header.Get("PRIVATE-TOKEN"))
+	}
+
+	mr["head_pipeline"] = map[string]string{"status": "failed"}
+	s.on("GET "+base+"/3", 200, mr)
+	got, err = f.GetPR(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("GetPR: %v", err)
+	}
+	if got.Checks != ChecksFailure {
+		t.Errorf("Checks = %s, want failure", got.Checks)
+	}
+
+	var mergeReq map[string]interface{}
+	s.routes["PUT "+base+"/3/merge"] = func(body map[string]interface{}) (int, interface{}) {
+		mergeReq = body
+		return 200, map[string]interface{}{"iid": 3, "state": "merged", "sha": "abc123", "squash_commit_sha": "sq789"}
+	}
+	sha, err := f.MergePR(context.Background(), got, "squash")
+	if err != nil {
+		t.Fatalf("MergePR: %v", err)
+	}
+	if sha != "sq789" {
+		t.Errorf("merge sha = %q, want squash commit", sha)
+	}
+	if mergeReq["squash"] != true || mergeReq["sha"] != "abc123" {
+		t.Errorf("merge body = %v", mergeReq)
+	}
+}
+
+func TestGitea_Landing(t *testing.T) {
+	s, srv := newStub(t)
+	f, err := New(Config{Kind: KindGitea, APIURL: srv.URL, Repo: "acme/widgets", Token: "tok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := map[string]interface{}{
+		"number": 1, "state": "open",
+		"head": map[string]string{"ref": "feature", "sha": "111"}, "base": map[string]string{"ref": "main"},
+	}
+	pr := map[string]interface{}{
+		"number": 2, "html_url": "https://gitea.example.com/acme/widgets/pulls/2", "state": "open", "mergeable": true,
+		"head": map[string]string{"ref": "gt/land/gt-abc", "sha": "abc123"}, "base": map[string]string{"ref": "main"},
+	}
+	s.on("GET /repos/acme/widgets/pulls", 200, []interface{}{other, pr})
+	s.on("PATCH /repos/acme/widgets/pulls/2", 200, pr)
+
+	got, err := f.EnsurePR(context.Background(), "gt/land/gt-abc", "main", "t", "b")
+	if err != nil {
+		t.Fatalf("EnsurePR: %v", err)
+	}
+	if got.Number != 2 || s.called("POST /repos/acme/widgets/pulls") {
+		t.Errorf("EnsurePR should update PR #2, got %+v (calls %v)", got, s.calls)
+	}
+	if s.header.Get("Authorization") != "token tok" {
+		t.Errorf("Authorization = %q", s.header.Get("Authorization"))
+	}
+
+	s.on("GET /repos/acme/widgets/pulls/2", 200, pr)
+	s.on("GET /repos/acme/widgets/commits/abc123/status", 200, map[string]interface{}{"state": "success", "total_count": 2})
+	got, err = f.GetPR(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetPR: %v", err)
+	}
+	if got.Checks != ChecksSuccess || got.Mergeable != MergeClean {
+		t.Errorf("GetPR = %+v", got)
+	}
+
+	s.routes["POST /repos/acme/widgets/pulls/2/merge"] = func(body map[string]interface{}) (int, interface{}) {
+		if body["Do"] != "squash" {
+			t.Errorf("merge Do = %v", body["Do"])
+		}
+		pr["state"] = "closed"
+		pr["merged"] = true
+		pr["merge_commit_sha"] = "def456"
+		return 200, nil
+	}
+	sha, err := f.MergePR(context.Background(), got, "squash")
+	if err != nil {
+		t.Fatalf("MergePR: %v", err)
+	}
+	if sha != "def456" {
+		t.Errorf("merge sha = %q", sha)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(Config{Kind: KindGitHub, Repo: "widgets"}); err == nil {
+		t.Error("expected error for repo without owner")
+	}
+	if _, err := New(Config{Kind: KindGitea, Repo: "acme/widgets"}); err == nil {
+		t.Error("expected error for gitea without api_url")
+	}
+	if _, err := New(Config{Kind: "bitbucket", Repo: "acme/widgets"}); err == nil {
+		t.Error("expected error for unknown forge")
+	}
+}
+
+func TestRepoFromRemoteURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://github.com/acme/widgets.git", "acme/widgets"},
+		{"https://github.com/acme/widgets", "acme/widgets"},
+		{"git@github.com:acme/widgets.git", "acme/widgets"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/widgets.git", "group/sub/widgets"},
+	}
+	for _, tt := range tests {
+		got, err := RepoFromRemoteURL(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("RepoFromRemoteURL(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := RepoFromRemoteURL("/srv/git/widgets.git"); err == nil {
+		t.Error("expected error for local path")
+	}
+}
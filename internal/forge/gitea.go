@@ -0,0 +1,126 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+)
+
+// Gitea lands pull requests through the Gitea (or Forgejo) REST API.
+type Gitea struct {
+	repo string
+	c    *client
+}
+
+type giteaPR struct {
+	Number         int    `json:"number"`
+	HTMLURL        string `json:"html_url"`
+	State          string `json:"state"`
+	Merged         bool   `json:"merged"`
+	Mergeable      bool   `json:"mergeable"`
+	MergeCommitSHA string `json:"merge_commit_sha"`
+	Head           struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// Name implements Forge.
+func (g *Gitea) Name() string { return KindGitea }
+
+// EnsurePR implements Forge.
+func (g *Gitea) EnsurePR(ctx context.Context, head, base, title, body string) (*PullRequest, error) {
+	// Gitea's list endpoint can't filter by head branch; landing branches
+	// are few, so scan the open PRs.
+	var open []giteaPR
+	if err := g.c.do(ctx, "GET", "/repos/"+g.repo+"/pulls?state=open&limit=50", nil, &open); err != nil {
+		return nil, err
+	}
+
+	var pr giteaPR
+	for _, p := range open {
+		if p.Head.Ref != head || p.Base.Ref != base {
+			continue
+		}
+		update := map[string]string{"title": title, "body": body}
+		if err := g.c.do(ctx, "PATCH", fmt.Sprintf("/repos/%s/pulls/%d", g.repo, p.Number), update, &pr); err != nil {
+			return nil, err
+		}
+		return g.convert(pr), nil
+	}
+
+	create := map[string]string{"title": title, "body": body, "head": head, "base": base}
+	if err := g.c.do(ctx, "POST", "/repos/"+g.repo+"/pulls", create, &pr); err != nil {
+		return nil, err
+	}
+	return g.convert(pr), nil
+}
+
+// GetPR implements Forge.
+func (g *Gitea) GetPR(ctx context.Context, number int) (*PullRequest, error) {
+	var raw giteaPR
+	if err := g.c.do(ctx, "GET", fmt.Sprintf("/repos/%s/pulls/%d", g.repo, number), nil, &raw); err != nil {
+		return nil, err
+	}
+	pr := g.convert(raw)
+	if pr.State != PROpen || pr.HeadSHA == "" {
+		return pr, nil
+	}
+
+	var status struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := g.c.do(ctx, "GET", "/repos/"+g.repo+"/commits/"+pr.HeadSHA+"/status", nil, &status); err != nil {
+		return nil, err
+	}
+	pr.Checks = ChecksSuccess
+	if status.TotalCount > 0 {
+		pr.Checks = statusState(status.State)
+	}
+	return pr, nil
+}
+
+// MergePR implements Forge.
+func (g *Gitea) MergePR(ctx context.Context, pr *PullRequest, method string) (string, error) {
+	req := map[string]string{"Do": method, "head_commit_id": pr.HeadSHA}
+	if err := g.c.do(ctx, "POST", fmt.Sprintf("/repos/%s/pulls/%d/merge", g.repo, pr.Number), req, nil); err != nil {
+		return "", err
+	}
+
+	// The merge endpoint returns no body; read the landed commit back.
+	merged, err := g.GetPR(ctx, pr.Number)
+	if err != nil {
+		return "", err
+	}
+	if merged.State != PRMerged {
+		return "", fmt.Errorf("gitea did not merge PR #%d", pr.Number)
+	}
+	return merged.MergeCommit, nil
+}
+
+func (g *Gitea) convert(raw giteaPR) *PullRequest {
+	pr := &PullRequest{
+		Number:    raw.Number,
+		URL:       raw.HTMLURL,
+		Head:      raw.Head.Ref,
+		Base:      raw.Base.Ref,
+		HeadSHA:   raw.Head.SHA,
+		State:     PROpen,
+		Mergeable: MergeConflict,
+		Checks:    ChecksPending,
+	}
+	if raw.Mergeable {
+		pr.Mergeable = MergeClean
+	}
+	switch {
+	case raw.Merged:
+		pr.State = PRMerged
+		pr.MergeCommit = raw.MergeCommitSHA
+	case raw.State == "closed":
+		pr.State = PRClosed
+	}
+	return pr
+}
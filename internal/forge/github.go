@@ -0,0 +1,171 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GitHub lands pull requests through the GitHub REST API (github.com or
+// GitHub Enterprise).
+type GitHub struct {
+	repo string
+	c    *client
+}
+
+type githubPR struct {
+	Number         int    `json:"number"`
+	HTMLURL        string `json:"html_url"`
+	State          string `json:"state"`
+	Merged         bool   `json:"merged"`
+	Mergeable      *bool  `json:"mergeable"`
+	MergeableState string `json:"mergeable_state"`
+	MergeCommitSHA string `json:"merge_commit_sha"`
+	Head           struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// Name implements Forge.
+func (g *GitHub) Name() string { return KindGitHub }
+
+// EnsurePR implements Forge.
+func (g *GitHub) EnsurePR(ctx context.Context, head, base, title, body string) (*PullRequest, error) {
+	owner := strings.SplitN(g.repo, "/", 2)[0]
+	q := url.Values{"state": {"open"}, "head": {owner + ":" + head}, "base": {base}}
+	var existing []githubPR
+	if err := g.c.do(ctx, "GET", "/repos/"+g.repo+"/pulls?"+q.Encode(), nil, &existing); err != nil {
+		return nil, err
+	}
+
+	var pr githubPR
+	if len(existing) > 0 {
+		update := map[string]string{"title": title, "body": body}
+		if err := g.c.do(ctx, "PATCH", fmt.Sprintf("/repos/%s/pulls/%d", g.repo, existing[0].Number), update, &pr); err != nil {
+			return nil, err
+		}
+	} else {
+		create := map[string]string{"title": title, "body": body, "head": head, "base": base}
+		if err := g.c.do(ctx, "POST", "/repos/"+g.repo+"/pulls", create, &pr); err != nil {
+			return nil, err
+		}
+	}
+	return g.convert(pr), nil
+}
+
+// GetPR implements Forge.
+func (g *GitHub) GetPR(ctx context.Context, number int) (*PullRequest, error) {
+	var raw githubPR
+	if err := g.c.do(ctx, "GET", fmt.Sprintf("/repos/%s/pulls/%d", g.repo, number), nil, &raw); err != nil {
+		return nil, err
+	}
+	pr := g.convert(raw)
+	if pr.State != PROpen || pr.HeadSHA == "" {
+		return pr, nil
+	}
+
+	checks, err := g.checks(ctx, pr.HeadSHA)
+	if err != nil {
+		return nil, err
+	}
+	pr.Checks = checks
+	return pr, nil
+}
+
+// checks combines legacy commit statuses and check runs for a commit.
+func (g *GitHub) checks(ctx context.Context, sha string) (ChecksState, error) {
+	var status struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := g.c.do(ctx, "GET", "/repos/"+g.repo+"/commits/"+sha+"/status", nil, &status); err != nil {
+		return "", err
+	}
+	var runs struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := g.c.do(ctx, "GET", "/repos/"+g.repo+"/commits/"+sha+"/check-runs", nil, &runs); err != nil {
+		return "", err
+	}
+
+	var states []ChecksState
+	if status.TotalCount > 0 {
+		states = append(states, statusState(status.State))
+	}
+	for _, run := range runs.CheckRuns {
+		if run.Status != "completed" {
+			states = append(states, ChecksPending)
+			continue
+		}
+		switch run.Conclusion {
+		case "success", "neutral", "skipped":
+			states = append(states, ChecksSuccess)
+		default:
+			states = append(states, ChecksFailure)
+		}
+	}
+	return combineChecks(states...), nil
+}
+
+// MergePR implements Forge.
+func (g *GitHub) MergePR(ctx context.Context, pr *PullRequest, method string) (string, error) {
+	req := map[string]string{"merge_method": method, "sha": pr.HeadSHA}
+	var resp struct {
+		SHA    string `json:"sha"`
+		Merged bool   `json:"merged"`
+	}
+	if err := g.c.do(ctx, "PUT", fmt.Sprintf("/repos/%s/pulls/%d/merge", g.repo, pr.Number), req, &resp); err != nil {
+		return "", err
+	}
+	if !resp.Merged {
+		return "", fmt.Errorf("github did not merge PR #%d", pr.Number)
+	}
+	return resp.SHA, nil
+}
+
+func (g *GitHub) convert(raw githubPR) *PullRequest {
+	pr := &PullRequest{
+		Number:    raw.Number,
+		URL:       raw.HTMLURL,
+		Head:      raw.Head.Ref,
+		Base:      raw.Base.Ref,
+		HeadSHA:   raw.Head.SHA,
+		State:     PROpen,
+		Mergeable: MergeUnknown,
+		Checks:    ChecksPending,
+	}
+	switch {
+	case raw.Merged:
+		pr.State = PRMerged
+		pr.MergeCommit = raw.MergeCommitSHA
+	case raw.State == "closed":
+		pr.State = PRClosed
+	}
+	switch {
+	case raw.MergeableState == "dirty" || (raw.Mergeable != nil && !*raw.Mergeable):
+		pr.Mergeable = MergeConflict
+	case raw.Mergeable != nil && *raw.Mergeable:
+		pr.Mergeable = MergeClean
+	}
+	return pr
+}
+
+// statusState maps a GitHub or Gitea commit status state.
+func statusState(s string) ChecksState {
+	switch s {
+	case "success":
+		return ChecksSuccess
+	case "failure", "error":
+		return ChecksFailure
+	default:
+		return ChecksPending
+	}
+}
@@ -0,0 +1,127 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// GitLab lands merge requests through the GitLab REST API (v4).
+type GitLab struct {
+	project string // URL-escaped project path
+	c       *client
+}
+
+type gitlabMR struct {
+	IID                 int    `json:"iid"`
+	WebURL              string `json:"web_url"`
+	State               string `json:"state"`
+	SourceBranch        string `json:"source_branch"`
+	TargetBranch        string `json:"target_branch"`
+	SHA                 string `json:"sha"`
+	MergeCommitSHA      string `json:"merge_commit_sha"`
+	SquashCommitSHA     string `json:"squash_commit_sha"`
+	HasConflicts        bool   `json:"has_conflicts"`
+	DetailedMergeStatus string `json:"detailed_merge_status"`
+	HeadPipeline        *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+}
+
+// Name implements Forge.
+func (g *GitLab) Name() string { return KindGitLab }
+
+// EnsurePR implements Forge.
+func (g *GitLab) EnsurePR(ctx context.Context, head, base, title, body string) (*PullRequest, error) {
+	q := url.Values{"state": {"opened"}, "source_branch": {head}, "target_branch": {base}}
+	var existing []gitlabMR
+	if err := g.c.do(ctx, "GET", "/projects/"+g.project+"/merge_requests?"+q.Encode(), nil, &existing); err != nil {
+		return nil, err
+	}
+
+	var mr gitlabMR
+	if len(existing) > 0 {
+		update := map[string]string{"title": title, "description": body}
+		if err := g.c.do(ctx, "PUT", fmt.Sprintf("/projects/%s/merge_requests/%d", g.project, existing[0].IID), update, &mr); err != nil {
+			return nil, err
+		}
+	} else {
+		create := map[string]string{"title": title, "description": body, "source_branch": head, "target_branch": base}
+		if err := g.c.do(ctx, "POST", "/projects/"+g.project+"/merge_requests", create, &mr); err != nil {
+			return nil, err
+		}
+	}
+	return g.convert(mr), nil
+}
+
+// GetPR implements Forge.
+func (g *GitLab) GetPR(ctx context.Context, number int) (*PullRequest, error) {
+	var mr gitlabMR
+	if err := g.c.do(ctx, "GET", fmt.Sprintf("/projects/%s/merge_requests/%d", g.project, number), nil, &mr); err != nil {
+		return nil, err
+	}
+	return g.convert(mr), nil
+}
+
+// MergePR implements Forge.
+func (g *GitLab) MergePR(ctx context.Context, pr *PullRequest, method string) (string, error) {
+	req := map[string]interface{}{"sha": pr.HeadSHA}
+	if method == "squash" {
+		req["squash"] = true
+	}
+	var mr gitlabMR
+	if err := g.c.do(ctx, "PUT", fmt.Sprintf("/projects/%s/merge_requests/%d/merge", g.project, pr.Number), req, &mr); err != nil {
+		return "", err
+	}
+	merged := g.convert(mr)
+	if merged.State != PRMerged {
+		return "", fmt.Errorf("gitlab did not merge MR !%d (state %s)", pr.Number, mr.State)
+	}
+	return merged.MergeCommit, nil
+}
+
+func (g *GitLab) convert(mr gitlabMR) *PullRequest {
+	pr := &PullRequest{
+		Number:    mr.IID,
+		URL:       mr.WebURL,
+		Head:      mr.SourceBranch,
+		Base:      mr.TargetBranch,
+		HeadSHA:   mr.SHA,
+		State:     PROpen,
+		Mergeable: MergeUnknown,
+		Checks:    ChecksSuccess,
+	}
+	switch mr.State {
+	case "merged":
+		pr.State = PRMerged
+		// Fast-forward projects leave merge_commit_sha empty.
+		pr.MergeCommit = mr.MergeCommitSHA
+		if pr.MergeCommit == "" {
+			pr.MergeCommit = mr.SquashCommitSHA
+		}
+		if pr.MergeCommit == "" {
+			pr.MergeCommit = mr.SHA
+		}
+	case "closed", "locked":
+		pr.State = PRClosed
+	}
+
+	switch {
+	case mr.HasConflicts || mr.DetailedMergeStatus == "conflict":
+		pr.Mergeable = MergeConflict
+	case mr.DetailedMergeStatus == "mergeable":
+		pr.Mergeable = MergeClean
+	}
+
+	if mr.HeadPipeline != nil {
+		switch mr.HeadPipeline.Status {
+		case "success", "skipped", "manual":
+			pr.Checks = ChecksSuccess
+		case "failed", "canceled":
+			pr.Checks = ChecksFailure
+		default:
+			pr.Checks = ChecksPending
+		}
+	}
+	return pr
+}
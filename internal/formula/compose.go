@@ -0,0 +1,322 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ErrFormulaNotFound is returned by a Loader when no formula has the name.
+var ErrFormulaNotFound = errors.New("formula not found")
+
+// Loader returns the raw TOML of a formula by name. It is used to resolve
+// extends and [[include]] references.
+type Loader func(name string) ([]byte, error)
+
+// Names is a list of formula names that may be written in TOML as a single
+// string or as an array: extends = "shiny" or extends = ["shiny"].
+type Names []string
+
+// UnmarshalTOML implements toml.Unmarshaler.
+func (n *Names) UnmarshalTOML(v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		*n = Names{v}
+	case []interface{}:
+		names := make(Names, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected formula name string, got %T", item)
+			}
+			names = append(names, s)
+		}
+		*n = names
+	default:
+		return fmt.Errorf("expected formula name or list of names, got %T", v)
+	}
+	return nil
+}
+
+// Include splices steps from another formula (typically a step library)
+// into this one.
+type Include struct {
+	// Formula is the name of the formula to include steps from.
+	Formula string `toml:"formula"`
+	// Steps limits the include to these step IDs (default all).
+	Steps []string `toml:"steps,omitempty"`
+	// Needs is added to included steps that have no needs of their own
+	// within the include, attaching the library after existing steps.
+	Needs []string `toml:"needs,omitempty"`
+}
+
+// NewLoader returns a Loader that looks for <name>.formula.toml in each
+// directory in order, then falls back to the embedded formulas.
+func NewLoader(dirs ...string) Loader {
+	return func(name string) ([]byte, error) {
+		if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+			return nil, fmt.Errorf("invalid formula name %q", name)
+		}
+		file := name + ".formula.toml"
+		for _, dir := range dirs {
+			data, err := os.ReadFile(filepath.Join(dir, file)) //nolint:gosec // G304: name is validated above
+			if err == nil {
+				return data, nil
+			}
+			if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("reading formula %s: %w", name, err)
+			}
+		}
+		if data, err := formulasFS.ReadFile("formulas/" + file); err == nil {
+			return data, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrFormulaNotFound, name)
+	}
+}
+
+// ParseWithLoader parses formula.toml content, resolving extends and
+// [[include]] through load, and validates the flattened result.
+func ParseWithLoader(data []byte, load Loader) (*Formula, error) {
+	var f Formula
+	if _, err := toml.Decode(string(data), &f); err != nil {
+		return nil, fmt.Errorf("parsing TOML: %w", err)
+	}
+
+	if err := f.resolve(load, nil); err != nil {
+		return nil, err
+	}
+
+	// Infer type from content if not explicitly set
+	f.inferType()
+
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// resolve flattens extends and includes into f. stack holds the formulas
+// currently being resolved, for composition cycle detection.
+func (f *Formula) resolve(load Loader, stack []string) error {
+	if len(f.Extends) == 0 && len(f.Include) == 0 {
+		return nil
+	}
+	if err := checkCompositionCycle(f.Name, stack); err != nil {
+		return err
+	}
+	stack = append(stack, f.Name)
+
+	// Parents apply in order, each overriding the previous; f overrides all
+	var base Formula
+	for _, name := range f.Extends {
+		parent, err := loadComposed(name, load, stack)
+		if err != nil {
+			return fmt.Errorf("extends %s: %w", name, err)
+		}
+		base.overlay(parent)
+	}
+
+	for _, inc := range f.Include {
+		if inc.Formula == "" {
+			return fmt.Errorf("include missing required formula field")
+		}
+		lib, err := loadComposed(inc.Formula, load, stack)
+		if err != nil {
+			return fmt.Errorf("include %s: %w", inc.Formula, err)
+		}
+		if err := base.splice(lib, inc); err != nil {
+			return fmt.Errorf("include %s: %w", inc.Formula, err)
+		}
+	}
+
+	child := *f
+	child.Extends, child.Include = nil, nil
+	base.overlay(&child)
+	base.Name = child.Name
+	*f = base
+	return nil
+}
+
+// checkCompositionCycle reports a cycle if name is already being resolved.
+func checkCompositionCycle(name string, stack []string) error {
+	for i, s := range stack {
+		if s == name {
+			chain := append(append([]string(nil), stack[i:]...), name)
+			return fmt.Errorf("cycle detected in formula composition: %s", strings.Join(chain, " -> "))
+		}
+	}
+	return nil
+}
+
+// loadComposed loads and resolves a referenced formula without validating
+// it, so abstract bases and step libraries need not be complete formulas.
+func loadComposed(name string, load Loader, stack []string) (*Formula, error) {
+	if err := checkCompositionCycle(name, stack); err != nil {
+		return nil, err
+	}
+	if load == nil {
+		return nil, fmt.Errorf("%w: %s (no loader)", ErrFormulaNotFound, name)
+	}
+	data, err := load(name)
+	if err != nil {
+		return nil, err
+	}
+	var f Formula
+	if _, err := toml.Decode(string(data), &f); err != nil {
+		return nil, fmt.Errorf("parsing TOML: %w", err)
+	}
+	if f.Name == "" {
+		f.Name = name
+	}
+	if err := f.resolve(load, stack); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// overlay merges o on top of f. Scalars and single sections are replaced
+// when set in o; steps, legs, templates and aspects are merged by ID, with
+// new IDs appended in o's order; vars, inputs and prompts are merged by key.
+func (f *Formula) overlay(o *Formula) {
+	if o.Name != "" {
+		f.Name = o.Name
+	}
+	if o.Description != "" {
+		f.Description = o.Description
+	}
+	if o.Type != "" {
+		f.Type = o.Type
+	}
+	if o.Version != 0 {
+		f.Version = o.Version
+	}
+	if o.Output != nil {
+		f.Output = o.Output
+	}
+	if o.Synthesis != nil {
+		f.Synthesis = o.Synthesis
+	}
+
+	f.Inputs = mergeMap(f.Inputs, o.Inputs)
+	f.Prompts = mergeMap(f.Prompts, o.Prompts)
+	f.Vars = mergeMap(f.Vars, o.Vars)
+
+	for _, s := range o.Steps {
+		if existing := f.GetStep(s.ID); existing != nil {
+			existing.override(s)
+		} else {
+			f.Steps = append(f.Steps, s)
+		}
+	}
+	for _, l := range o.Legs {
+		if existing := f.GetLeg(l.ID); existing != nil {
+			*existing = l
+		} else {
+			f.Legs = append(f.Legs, l)
+		}
+	}
+	for _, t := range o.Template {
+		if existing := f.GetTemplate(t.ID); existing != nil {
+			*existing = t
+		} else {
+			f.Template = append(f.Template, t)
+		}
+	}
+	for _, a := range o.Aspects {
+		if existing := f.GetAspect(a.ID); existing != nil {
+			*existing = a
+		} else {
+			f.Aspects = append(f.Aspects, a)
+		}
+	}
+}
+
+// override replaces the fields of s that are set in o.
+func (s *Step) override(o Step) {
+	if o.Title != "" {
+		s.Title = o.Title
+	}
+	if o.Description != "" {
+		s.Description = o.Description
+	}
+	if len(o.Needs) > 0 {
+		s.Needs = o.Needs
+	}
+	if o.When != "" {
+		s.When = o.When
+	}
+	if o.ForEach != "" {
+		s.ForEach = o.ForEach
+	}
+	if o.Retry != 0 {
+		s.Retry = o.Retry
+	}
+	if o.Until != "" {
+		s.Until = o.Until
+	}
+}
+
+// splice appends the steps selected by inc from lib, plus any vars the
+// library declares that f doesn't. Steps already present in f are kept.
+func (f *Formula) splice(lib *Formula, inc Include) error {
+	steps := lib.Steps
+	if len(inc.Steps) > 0 {
+		steps = nil
+		for _, id := range inc.Steps {
+			s := lib.GetStep(id)
+			if s == nil {
+				return fmt.Errorf("no step %q", id)
+			}
+			steps = append(steps, *s)
+		}
+	}
+
+	included := make(map[string]bool, len(steps))
+	for _, s := range steps {
+		included[s.ID] = true
+	}
+	for _, s := range steps {
+		if f.GetStep(s.ID) != nil {
+			continue
+		}
+		root := true
+		for _, need := range s.Needs {
+			if included[need] {
+				root = false
+				break
+			}
+		}
+		if root && len(inc.Needs) > 0 {
+			s.Needs = append(append([]string(nil), s.Needs...), inc.Needs...)
+		}
+		f.Steps = append(f.Steps, s)
+	}
+
+	for name, v := range lib.Vars {
+		if _, ok := f.Vars[name]; !ok {
+			f.Vars = mergeMap(f.Vars, map[string]Var{name: v})
+		}
+	}
+	return nil
+}
+
+// mergeMap returns base with every entry of over copied in, allocating when
+// needed so neither input is modified.
+func mergeMap[V any](base, over map[string]V) map[string]V {
+	if len(over) == 0 {
+		return base
+	}
+	merged := make(map[string]V, len(base)+len(over))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range over {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,222 @@
+package formula
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mapLoader serves formulas from an in-memory map.
+func mapLoader(formulas map[string]string) Loader {
+	return func(name string) ([]byte, error) {
+		data, ok := formulas[name]
+		if !ok {
+			return nil, ErrFormulaNotFound
+		}
+		return []byte(data), nil
+	}
+}
+
+const baseRelease = `
+formula = "base-release"
+type = "workflow"
+description = "Base release"
+
+[vars.version]
+required = true
+
+[[steps]]
+id = "test"
+title = "Run tests"
+
+[[steps]]
+id = "build"
+title = "Build"
+needs = ["test"]
+
+[[steps]]
+id = "publish"
+title = "Publish"
+needs = ["build"]
+`
+
+const releaseChecks = `
+formula = "release-checks"
+
+[vars.channel]
+default = "stable"
+
+[[steps]]
+id = "changelog"
+title = "Update changelog"
+
+[[steps]]
+id = "tag"
+title = "Tag {{version}}"
+needs = ["changelog"]
+
+[[steps]]
+id = "announce"
+title = "Announce"
+`
+
+func stepIDs(f *Formula) string {
+	var ids []string
+	for _, s := range f.Steps {
+		ids = append(ids, s.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestParseWithLoader_Extends(t *testing.T) {
+	load := mapLoader(map[string]string{"base-release": baseRelease})
+	f, err := ParseWithLoader([]byte(`
+formula = "beads-release"
+extends = "base-release"
+
+[[steps]]
+id = "build"
+title = "Build with goreleaser"
+
+[[steps]]
+id = "homebrew"
+title = "Update tap"
+needs = ["publish"]
+`), load)
+	if err != nil {
+		t.Fatalf("ParseWithLoader failed: %v", err)
+	}
+
+	if f.Name != "beads-release" || f.Type != TypeWorkflow || f.Description != "Base release" {
+		t.Errorf("inherited fields: name=%q type=%q desc=%q", f.Name, f.Type, f.Description)
+	}
+	if got := stepIDs(f); got != "test,build,publish,homebrew" {
+		t.Errorf("steps = %s", got)
+	}
+	build := f.GetStep("build")
+	if build.Title != "Build with goreleaser" || len(build.Needs) != 1 || build.Needs[0] != "test" {
+		t.Errorf("overridden build step = %+v, want new title and inherited needs", build)
+	}
+	if f.GetParam("version") == nil {
+		t.Error("vars not inherited")
+	}
+	if len(f.Extends) != 0 {
+		t.Errorf("Extends = %v, want cleared after resolving", f.Extends)
+	}
+}
+
+func TestParseWithLoader_Include(t *testing.T) {
+	load := mapLoader(map[string]string{
+		"base-release":   baseRelease,
+		"release-checks": releaseChecks,
+	})
+	f, err := ParseWithLoader([]byte(`
+formula = "gastown-release"
+extends = ["base-release"]
+
+[[include]]
+formula = "release-checks"
+steps = ["changelog", "tag"]
+needs = ["build"]
+`), load)
+	if err != nil {
+		t.Fatalf("ParseWithLoader failed: %v", err)
+	}
+
+	if got := stepIDs(f); got != "test,build,publish,changelog,tag" {
+		t.Errorf("steps = %s", got)
+	}
+	if needs := f.GetStep("changelog").Needs; len(needs) != 1 || needs[0] != "build" {
+		t.Errorf("changelog needs = %v, want [build]", needs)
+	}
+	if needs := f.GetStep("tag").Needs; len(needs) != 1 || needs[0] != "changelog" {
+		t.Errorf("tag needs = %v, want [changelog] only", needs)
+	}
+	if p := f.GetParam("channel"); p == nil || p.Default != "stable" {
+		t.Errorf("library vars not included: %+v", p)
+	}
+
+	_, err = ParseWithLoader([]byte(`
+formula = "x"
+[[include]]
+formula = "release-checks"
+steps = ["nope"]
+`), load)
+	if err == nil || !strings.Contains(err.Error(), `no step "nope"`) {
+		t.Errorf("unknown included step error = %v", err)
+	}
+}
+
+func TestParseWithLoader_CompositionCycle(t *testing.T) {
+	load := mapLoader(map[string]string{
+		"a": "formula = \"a\"\nextends = \"b\"\n",
+		"b": "formula = \"b\"\n[[include]]\nformula = \"a\"\n",
+	})
+	_, err := ParseWithLoader([]byte("formula = \"top\"\nextends = \"a\"\n"), load)
+	if err == nil || !strings.Contains(err.Error(), "cycle detected in formula composition: a -> b -> a") {
+		t.Errorf("cycle error = %v", err)
+	}
+
+	_, err = ParseWithLoader([]byte("formula = \"self\"\nextends = \"self\"\n"), mapLoader(nil))
+	if err == nil || !strings.Contains(err.Error(), "self -> self") {
+		t.Errorf("self-extends error = %v", err)
+	}
+}
+
+func TestParseWithLoader_MissingParent(t *testing.T) {
+	_, err := ParseWithLoader([]byte("formula = \"x\"\nextends = \"ghost\"\n"), mapLoader(nil))
+	if !errors.Is(err, ErrFormulaNotFound) {
+		t.Errorf("error = %v, want ErrFormulaNotFound", err)
+	}
+}
+
+func TestParseFile_ExtendsSiblingAndEmbedded(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "base-release.formula.toml"), []byte(baseRelease), 0644); err != nil {
+		t.Fatal(err)
+	}
+	child := filepath.Join(dir, "mine.formula.toml")
+	if err := os.WriteFile(child, []byte("formula = \"mine\"\nextends = \"base-release\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := ParseFile(child)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if got := stepIDs(f); got != "test,build,publish" {
+		t.Errorf("steps = %s", got)
+	}
+
+	// shiny-enterprise extends the embedded shiny formula
+	data, err := formulasFS.ReadFile("formulas/shiny-enterprise.formula.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err = Parse(data)
+	if err != nil {
+		t.Fatalf("Parse(shiny-enterprise) failed: %v", err)
+	}
+	if f.GetStep("implement") == nil {
+		t.Error("shiny-enterprise did not inherit shiny's steps")
+	}
+}
+
+func TestValidate_TemplateCycle(t *testing.T) {
+	_, err := Parse([]byte(`
+formula = "loop"
+type = "expansion"
+
+[[template]]
+id = "a"
+needs = ["b"]
+
+[[template]]
+id = "b"
+needs = ["a"]
+`))
+	if err == nil || !strings.Contains(err.Error(), "cycle detected") {
+		t.Errorf("template cycle error = %v", err)
+	}
+}
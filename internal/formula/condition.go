@@ -0,0 +1,398 @@
+package formula
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Condition is a parsed when/until expression.
+//
+// Grammar:
+//
+//	expr    = and { ("||" | "or") and }
+//	and     = unary { ("&&" | "and") unary }
+//	unary   = ("!" | "not") unary | "(" expr ")" | operand [ ("==" | "!=") operand ]
+//	operand = "quoted" | 'quoted' | number | true | false | reference | {{var}}
+//
+// References are vars.<name> (or just <name>) for formula variables and
+// steps.<id>.<key> for outputs recorded by earlier steps. A lone operand is
+// true unless it is empty, false, no, off or 0. Comparisons treat two
+// boolean-looking values as booleans, so "yes" == true.
+type Condition struct {
+	src  string
+	root condNode
+}
+
+// CondEnv supplies values for condition references.
+type CondEnv struct {
+	Vars    map[string]string
+	Outputs map[string]map[string]string // step ID -> key -> value
+}
+
+// ParseCondition parses a when/until expression.
+func ParseCondition(s string) (*Condition, error) {
+	toks, err := lexCondition(s)
+	if err != nil {
+		return nil, fmt.Errorf("condition %q: %w", s, err)
+	}
+	p := &condParser{toks: toks}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.toks) {
+		err = fmt.Errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("condition %q: %w", s, err)
+	}
+	return &Condition{src: s, root: root}, nil
+}
+
+// String returns the condition as source text.
+func (c *Condition) String() string {
+	return c.root.String()
+}
+
+// Eval evaluates the condition. Unknown references evaluate as empty.
+func (c *Condition) Eval(env CondEnv) bool {
+	return truthy(c.root.eval(env))
+}
+
+// Refs returns the references the condition reads, normalized to
+// vars.<name> and steps.<id>.<key>.
+func (c *Condition) Refs() []string {
+	var refs []string
+	c.root.walk(func(n condNode) {
+		if r, ok := n.(condRef); ok {
+			refs = append(refs, string(r))
+		}
+	})
+	return refs
+}
+
+// StepRefs returns the IDs of the steps whose outputs the condition reads.
+func (c *Condition) StepRefs() []string {
+	var ids []string
+	for _, ref := range c.Refs() {
+		if id, _, ok := splitStepRef(ref); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Bind returns a copy of the condition with the var references named in
+// vars replaced by their values. Step output references are left in place.
+func (c *Condition) Bind(vars map[string]string) *Condition {
+	return c.bindRefs(func(ref string) (string, bool) {
+		name, ok := strings.CutPrefix(ref, "vars.")
+		if !ok {
+			return "", false
+		}
+		v, ok := vars[name]
+		return v, ok
+	})
+}
+
+// bindRefs returns a copy of the condition with each reference for which
+// lookup returns ok replaced by the value.
+func (c *Condition) bindRefs(lookup func(ref string) (string, bool)) *Condition {
+	return &Condition{src: c.src, root: c.root.bind(lookup)}
+}
+
+// splitStepRef splits steps.<id>.<key> into id and key.
+func splitStepRef(ref string) (id, key string, ok bool) {
+	rest, found := strings.CutPrefix(ref, "steps.")
+	if !found {
+		return "", "", false
+	}
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 || i == len(rest)-1 {
+		return "", "", false
+	}
+	return rest[:i], rest[i+1:], true
+}
+
+// truthy reports whether a value counts as true on its own.
+func truthy(v string) bool {
+	if b, err := parseBool(v); err == nil {
+		return b
+	}
+	return v != ""
+}
+
+func condEqual(a, b string) bool {
+	ab, aErr := parseBool(a)
+	bb, bErr := parseBool(b)
+	if aErr == nil && bErr == nil {
+		return ab == bb
+	}
+	return a == b
+}
+
+// condNode is a node in a parsed condition.
+type condNode interface {
+	eval(env CondEnv) string
+	bind(lookup func(ref string) (string, bool)) condNode
+	walk(fn func(condNode))
+	String() string
+}
+
+type condLit string
+
+func (n condLit) eval(CondEnv) string                       { return string(n) }
+func (n condLit) bind(func(string) (string, bool)) condNode { return n }
+func (n condLit) walk(fn func(condNode))                    { fn(n) }
+func (n condLit) String() string                            { return strconv.Quote(string(n)) }
+
+type condRef string
+
+func (n condRef) eval(env CondEnv) string {
+	if id, key, ok := splitStepRef(string(n)); ok {
+		return env.Outputs[id][key]
+	}
+	return env.Vars[strings.TrimPrefix(string(n), "vars.")]
+}
+
+func (n condRef) bind(lookup func(string) (string, bool)) condNode {
+	if v, ok := lookup(string(n)); ok {
+		return condLit(v)
+	}
+	return n
+}
+
+func (n condRef) walk(fn func(condNode)) { fn(n) }
+func (n condRef) String() string         { return string(n) }
+
+type condNot struct{ x condNode }
+
+func (n condNot) eval(env CondEnv) string {
+	return strconv.FormatBool(!truthy(n.x.eval(env)))
+}
+func (n condNot) bind(lookup func(string) (string, bool)) condNode {
+	return condNot{n.x.bind(lookup)}
+}
+func (n condNot) walk(fn func(condNode)) { fn(n); n.x.walk(fn) }
+func (n condNot) String() string         { return "!" + n.x.String() }
+
+type condBinary struct {
+	op   string // "==", "!=", "&&", "||"
+	l, r condNode
+}
+
+func (n condBinary) eval(env CondEnv) string {
+	var v bool
+	switch n.op {
+	case "==":
+		v = condEqual(n.l.eval(env), n.r.eval(env))
+	case "!=":
+		v = !condEqual(n.l.eval(env), n.r.eval(env))
+	case "&&":
+		v = truthy(n.l.eval(env)) && truthy(n.r.eval(env))
+	case "||":
+		v = truthy(n.l.eval(env)) || truthy(n.r.eval(env))
+	}
+	return strconv.FormatBool(v)
+}
+
+func (n condBinary) bind(lookup func(string) (string, bool)) condNode {
+	return condBinary{op: n.op, l: n.l.bind(lookup), r: n.r.bind(lookup)}
+}
+
+func (n condBinary) walk(fn func(condNode)) { fn(n); n.l.walk(fn); n.r.walk(fn) }
+
+func (n condBinary) String() string {
+	return "(" + n.l.String() + " " + n.op + " " + n.r.String() + ")"
+}
+
+// Lexer
+
+type condTokKind int
+
+const (
+	tokOp condTokKind = iota
+	tokString
+	tokWord
+)
+
+type condTok struct {
+	kind condTokKind
+	text string
+}
+
+func lexCondition(s string) ([]condTok, error) {
+	var toks []condTok
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(s[i:], "{{"):
+			end := strings.Index(s[i:], "}}")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated {{")
+			}
+			name := strings.TrimSpace(s[i+2 : i+end])
+			if name == "" {
+				return nil, fmt.Errorf("empty {{}}")
+			}
+			toks = append(toks, condTok{tokWord, name})
+			i += end + 2
+		case strings.HasPrefix(s[i:], "==") || strings.HasPrefix(s[i:], "!=") ||
+			strings.HasPrefix(s[i:], "&&") || strings.HasPrefix(s[i:], "||"):
+			toks = append(toks, condTok{tokOp, s[i : i+2]})
+			i += 2
+		case c == '!' || c == '(' || c == ')':
+			toks = append(toks, condTok{tokOp, string(c)})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			toks = append(toks, condTok{tokString, s[i+1 : i+1+end]})
+			i += end + 2
+		case isCondWordByte(c):
+			j := i
+			for j < len(s) && isCondWordByte(s[j]) {
+				j++
+			}
+			toks = append(toks, condTok{tokWord, s[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return toks, nil
+}
+
+func isCondWordByte(c byte) bool {
+	return c == '_' || c == '.' || c == '-' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// Parser
+
+type condParser struct {
+	toks []condTok
+	pos  int
+}
+
+func (p *condParser) peek() (condTok, bool) {
+	if p.pos >= len(p.toks) {
+		return condTok{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+// accept consumes the next token if it is one of the given operators or
+// keywords.
+func (p *condParser) accept(texts ...string) (string, bool) {
+	t, ok := p.peek()
+	if !ok || t.kind == tokString {
+		return "", false
+	}
+	for _, text := range texts {
+		if t.text == text {
+			p.pos++
+			return text, true
+		}
+	}
+	return "", false
+}
+
+func (p *condParser) parseOr() (condNode, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||", "or"); !ok {
+			return l, nil
+		}
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = condBinary{op: "||", l: l, r: r}
+	}
+}
+
+func (p *condParser) parseAnd() (condNode, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&", "and"); !ok {
+			return l, nil
+		}
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = condBinary{op: "&&", l: l, r: r}
+	}
+}
+
+func (p *condParser) parseUnary() (condNode, error) {
+	if _, ok := p.accept("!", "not"); ok {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return condNot{x}, nil
+	}
+	if _, ok := p.accept("("); ok {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, fmt.Errorf("missing )")
+		}
+		return x, nil
+	}
+
+	l, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if op, ok := p.accept("==", "!="); ok {
+		r, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return condBinary{op: op, l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *condParser) parseOperand() (condNode, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	switch t.kind {
+	case tokString:
+		return condLit(t.text), nil
+	case tokWord:
+		switch t.text {
+		case "and", "or", "not":
+			return nil, fmt.Errorf("unexpected %q", t.text)
+		case "true", "false":
+			return condLit(t.text), nil
+		}
+		if _, err := strconv.ParseFloat(t.text, 64); err == nil {
+			return condLit(t.text), nil
+		}
+		if strings.HasPrefix(t.text, "steps.") {
+			if _, _, ok := splitStepRef(t.text); !ok {
+				return nil, fmt.Errorf("step reference %q must be steps.<id>.<key>", t.text)
+			}
+			return condRef(t.text), nil
+		}
+		return condRef("vars." + strings.TrimPrefix(t.text, "vars.")), nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
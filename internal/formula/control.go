@@ -0,0 +1,484 @@
+package formula
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxRetry bounds retry on a single step so an until loop always ends.
+const MaxRetry = 10
+
+// HasControlFlow reports whether any step uses when, for_each or until.
+func (f *Formula) HasControlFlow() bool {
+	for _, s := range f.Steps {
+		if s.When != "" || s.ForEach != "" || s.Until != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateControl checks when/for_each/retry/until on workflow steps.
+// Step output references must name the step itself (until only) or a step
+// it transitively needs, so the output exists by the time it is read.
+func (f *Formula) validateControl() error {
+	for _, step := range f.Steps {
+		if step.Retry < 0 || step.Retry > MaxRetry {
+			return fmt.Errorf("step %q: retry must be between 0 and %d", step.ID, MaxRetry)
+		}
+		if step.Until == "" && step.Retry > 0 {
+			return fmt.Errorf("step %q: retry requires until", step.ID)
+		}
+		if step.Until != "" && step.Retry == 0 {
+			return fmt.Errorf("step %q: until requires retry to bound the loop", step.ID)
+		}
+		if step.ForEach != "" {
+			name := forEachVar(step.ForEach)
+			if f.GetParam(name) == nil {
+				return fmt.Errorf("step %q: for_each references unknown variable: %s", step.ID, name)
+			}
+		}
+
+		ancestors := f.ancestors(step.ID)
+		for _, c := range []struct {
+			field, expr string
+			self        bool
+		}{{"when", step.When, false}, {"until", step.Until, true}} {
+			if c.expr == "" {
+				continue
+			}
+			cond, err := ParseCondition(c.expr)
+			if err != nil {
+				return fmt.Errorf("step %q: %s: %w", step.ID, c.field, err)
+			}
+			for _, ref := range cond.Refs() {
+				if id, _, ok := splitStepRef(ref); ok {
+					if !ancestors[id] && !(c.self && id == step.ID) {
+						return fmt.Errorf("step %q: %s reads %s, but %s is not a step it needs", step.ID, c.field, ref, id)
+					}
+					continue
+				}
+				name := strings.TrimPrefix(ref, "vars.")
+				if step.ForEach != "" && (name == "item" || name == "index") {
+					continue
+				}
+				if f.GetParam(name) == nil {
+					return fmt.Errorf("step %q: %s references unknown variable: %s", step.ID, c.field, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ancestors returns the IDs of all steps id transitively needs.
+func (f *Formula) ancestors(id string) map[string]bool {
+	seen := make(map[string]bool)
+	var visit func(string)
+	visit = func(id string) {
+		step := f.GetStep(id)
+		if step == nil {
+			return
+		}
+		for _, need := range step.Needs {
+			if !seen[need] {
+				seen[need] = true
+				visit(need)
+			}
+		}
+	}
+	visit(id)
+	return seen
+}
+
+// forEachVar returns the variable named by a for_each value, which may be
+// written as name, vars.name or {{name}}.
+func forEachVar(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{{"), "}}")
+	return strings.TrimPrefix(strings.TrimSpace(s), "vars.")
+}
+
+// splitList splits a for_each list value on commas and newlines.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// varsWithDefaults returns values with declared defaults filled in.
+func (f *Formula) varsWithDefaults(values map[string]string) map[string]string {
+	vars := make(map[string]string)
+	for _, p := range f.Params() {
+		if p.Default != "" {
+			vars[p.Name] = p.Default
+		}
+	}
+	for k, v := range values {
+		vars[k] = v
+	}
+	return vars
+}
+
+// Expand returns a copy of a workflow formula with control flow decided as
+// far as vars allow:
+//
+//   - for_each steps are fanned out into <id>-1, <id>-2, ... with {{item}}
+//     and {{index}} substituted, and dependents need every copy;
+//   - when conditions that only read vars are evaluated: false drops the
+//     step (its dependents inherit its needs), true clears the condition;
+//   - var references left in when/until are replaced by their values, so
+//     the remaining conditions only read step outputs.
+//
+// The result contains no for_each and can be cooked as a plain DAG, with
+// the remaining when/until evaluated while the molecule runs.
+func (f *Formula) Expand(values map[string]string) (*Formula, error) {
+	if f.Type != TypeWorkflow {
+		return nil, fmt.Errorf("expand: %s formulas have no steps", f.Type)
+	}
+	vars := f.varsWithDefaults(values)
+
+	fanout := make(map[string][]string) // for_each step -> copies
+	dropped := make(map[string]bool)
+	var steps []Step
+
+	for _, step := range f.Steps {
+		copies := []Step{step}
+		if step.ForEach != "" {
+			copies = nil
+			for i, item := range splitList(vars[forEachVar(step.ForEach)]) {
+				c := step
+				c.ID = fmt.Sprintf("%s-%d", step.ID, i+1)
+				c.ForEach = ""
+				r := strings.NewReplacer("{{item}}", item, "{{index}}", strconv.Itoa(i+1))
+				c.Title = r.Replace(c.Title)
+				c.Description = r.Replace(c.Description)
+				self := strings.NewReplacer("steps."+step.ID+".", "steps."+c.ID+".")
+				c.When = self.Replace(c.When)
+				c.Until = self.Replace(c.Until)
+				loop := map[string]string{"item": item, "index": strconv.Itoa(i + 1)}
+				c.When = bindCondition(c.When, loop)
+				c.Until = bindCondition(c.Until, loop)
+				copies = append(copies, c)
+				fanout[step.ID] = append(fanout[step.ID], c.ID)
+			}
+		}
+
+		for _, c := range copies {
+			if c.When != "" {
+				cond, err := ParseCondition(c.When)
+				if err != nil {
+					return nil, fmt.Errorf("step %q: when: %w", c.ID, err)
+				}
+				cond = cond.Bind(vars)
+				if len(cond.StepRefs()) == 0 {
+					if !cond.Eval(CondEnv{}) {
+						dropped[c.ID] = true
+						if step.ForEach != "" {
+							fanout[step.ID] = removeString(fanout[step.ID], c.ID)
+						}
+						continue
+					}
+					c.When = ""
+				} else {
+					c.When = cond.String()
+				}
+			}
+			c.Until = bindCondition(c.Until, vars)
+			c.Needs = append([]string(nil), c.Needs...)
+			steps = append(steps, c)
+		}
+		if step.ForEach != "" && len(fanout[step.ID]) == 0 {
+			dropped[step.ID] = true
+		}
+	}
+
+	// Rewire needs past fanned-out and dropped steps
+	orig := make(map[string]*Step)
+	for i := range f.Steps {
+		orig[f.Steps[i].ID] = &f.Steps[i]
+	}
+	var resolve func(need string) []string
+	resolve = func(need string) []string {
+		if ids := fanout[need]; len(ids) > 0 {
+			return ids
+		}
+		if dropped[need] && orig[need] != nil {
+			var out []string
+			for _, n := range orig[need].Needs {
+				out = append(out, resolve(n)...)
+			}
+			return out
+		}
+		return []string{need}
+	}
+	for i := range steps {
+		var needs []string
+		seen := make(map[string]bool)
+		for _, n := range steps[i].Needs {
+			for _, r := range resolve(n) {
+				if !seen[r] {
+					seen[r] = true
+					needs = append(needs, r)
+				}
+			}
+		}
+		steps[i].Needs = needs
+
+		// Outputs of dropped steps are never recorded
+		steps[i].When = bindDropped(steps[i].When, dropped)
+		steps[i].Until = bindDropped(steps[i].Until, dropped)
+	}
+
+	out := *f
+	out.Steps = steps
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("expand: every step of %s was skipped", f.Name)
+	}
+	if err := out.Validate(); err != nil {
+		return nil, fmt.Errorf("expand: %w", err)
+	}
+	return &out, nil
+}
+
+// bindCondition substitutes values into a condition, leaving it unchanged
+// if it does not parse (Validate reports that separately).
+func bindCondition(expr string, values map[string]string) string {
+	if expr == "" {
+		return ""
+	}
+	cond, err := ParseCondition(expr)
+	if err != nil {
+		return expr
+	}
+	return cond.Bind(values).String()
+}
+
+// bindDropped replaces output references to dropped steps with "".
+func bindDropped(expr string, dropped map[string]bool) string {
+	if expr == "" {
+		return ""
+	}
+	cond, err := ParseCondition(expr)
+	if err != nil {
+		return expr
+	}
+	return cond.bindRefs(func(ref string) (string, bool) {
+		id, _, ok := splitStepRef(ref)
+		return "", ok && dropped[id]
+	}).String()
+}
+
+func removeString(list []string, s string) []string {
+	var out []string
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// ExecutionOrder expands the formula for vars and returns its steps in
+// dependency order, without the steps vars rule out.
+func (f *Formula) ExecutionOrder(vars map[string]string) ([]string, error) {
+	expanded, err := f.Expand(vars)
+	if err != nil {
+		return nil, err
+	}
+	return expanded.TopologicalSort()
+}
+
+// RunState is the progress of a workflow run, used to decide which steps
+// run next.
+type RunState struct {
+	Vars      map[string]string
+	Completed map[string]bool
+	Skipped   map[string]bool
+	Outputs   map[string]map[string]string // step ID -> key -> value
+	Attempts  map[string]int               // step ID -> times finished
+}
+
+func (s *RunState) env() CondEnv {
+	return CondEnv{Vars: s.Vars, Outputs: s.Outputs}
+}
+
+// Advance returns the workflow steps that are ready to run. A step is ready
+// when each of its needs is completed or skipped; ready steps whose when
+// condition is false are marked skipped instead, which may in turn make
+// later steps ready. Conditions that fail to parse count as true.
+func (f *Formula) Advance(state *RunState) []string {
+	if state.Skipped == nil {
+		state.Skipped = make(map[string]bool)
+	}
+	done := func(id string) bool { return state.Completed[id] || state.Skipped[id] }
+
+	for {
+		var ready []string
+		skipped := false
+		for _, step := range f.Steps {
+			if done(step.ID) {
+				continue
+			}
+			allMet := true
+			for _, need := range step.Needs {
+				if !done(need) {
+					allMet = false
+					break
+				}
+			}
+			if !allMet {
+				continue
+			}
+			if step.When != "" {
+				if cond, err := ParseCondition(step.When); err == nil && !cond.Eval(state.env()) {
+					state.Skipped[step.ID] = true
+					skipped = true
+					continue
+				}
+			}
+			ready = append(ready, step.ID)
+		}
+		if !skipped {
+			return ready
+		}
+	}
+}
+
+// Finish records that step id finished an attempt. It returns true if the
+// step must run again because its until condition is still false and it
+// has retries left; otherwise the step is marked completed.
+func (f *Formula) Finish(state *RunState, id string) bool {
+	if state.Attempts == nil {
+		state.Attempts = make(map[string]int)
+	}
+	if state.Completed == nil {
+		state.Completed = make(map[string]bool)
+	}
+	state.Attempts[id]++
+	if step := f.GetStep(id); step != nil && step.Until != "" {
+		repeats := state.Attempts[id] - 1
+		if cond, err := ParseCondition(step.Until); err == nil && !cond.Eval(state.env()) && repeats < step.Retry {
+			return true
+		}
+	}
+	state.Completed[id] = true
+	return false
+}
+
+// StepControl is the control-flow state carried on a step bead's
+// description, so gt can honour when/until once the formula is cooked.
+// It is stored as "step.<field>: value" lines.
+type StepControl struct {
+	ID      string            // formula step ID
+	When    string            // skip the step unless this holds
+	Until   string            // repeat the step until this holds
+	Retry   int               // max repeats
+	Attempt int               // repeats so far
+	Outputs map[string]string // values recorded with gt mol step done --output
+}
+
+// IsZero reports whether c carries no control information.
+func (c *StepControl) IsZero() bool {
+	return c.ID == "" && c.When == "" && c.Until == "" && c.Retry == 0 && c.Attempt == 0 && len(c.Outputs) == 0
+}
+
+// ParseStepControl reads step.* lines from a step bead description.
+func ParseStepControl(description string) *StepControl {
+	c := &StepControl{}
+	for _, line := range strings.Split(description, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || !strings.HasPrefix(key, "step.") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "step.id":
+			c.ID = value
+		case "step.when":
+			c.When = value
+		case "step.until":
+			c.Until = value
+		case "step.retry":
+			c.Retry, _ = strconv.Atoi(value)
+		case "step.attempt":
+			c.Attempt, _ = strconv.Atoi(value)
+		default:
+			if name, ok := strings.CutPrefix(key, "step.output."); ok && name != "" {
+				if c.Outputs == nil {
+					c.Outputs = make(map[string]string)
+				}
+				c.Outputs[name] = value
+			}
+		}
+	}
+	return c
+}
+
+// Format renders c as step.* lines.
+func (c *StepControl) Format() string {
+	var lines []string
+	add := func(key, value string) {
+		if value != "" {
+			lines = append(lines, key+": "+value)
+		}
+	}
+	add("step.id", c.ID)
+	add("step.when", c.When)
+	add("step.until", c.Until)
+	if c.Retry > 0 {
+		add("step.retry", strconv.Itoa(c.Retry))
+	}
+	if c.Attempt > 0 {
+		add("step.attempt", strconv.Itoa(c.Attempt))
+	}
+	keys := make([]string, 0, len(c.Outputs))
+	for k := range c.Outputs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		add("step.output."+k, c.Outputs[k])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetStepControl returns description with its step.* lines replaced by c.
+func SetStepControl(description string, c *StepControl) string {
+	var kept []string
+	for _, line := range strings.Split(description, "\n") {
+		key, _, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if ok && strings.HasPrefix(key, "step.") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	body := strings.TrimRight(strings.Join(kept, "\n"), "\n")
+	block := c.Format()
+	switch {
+	case block == "":
+		return body
+	case body == "":
+		return block
+	}
+	return body + "\n\n" + block
+}
+
+// EmbedControl writes each step's remaining when/until/retry into its
+// description as step.* lines, for use on an expanded formula before it is
+// cooked. Steps of a formula with control flow also record their ID so
+// gt mol step commands can match step output references to beads.
+func (f *Formula) EmbedControl() {
+	for i := range f.Steps {
+		s := &f.Steps[i]
+		c := &StepControl{ID: s.ID, When: s.When, Until: s.Until, Retry: s.Retry}
+		s.Description = SetStepControl(s.Description, c)
+		s.When, s.Until, s.Retry = "", "", 0
+	}
+}
@@ -0,0 +1,238 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCondition(t *testing.T) {
+	env := CondEnv{
+		Vars: map[string]string{"deploy": "yes", "env": "prod", "empty": ""},
+		Outputs: map[string]map[string]string{
+			"review": {"clean": "false", "issues": "3"},
+		},
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"deploy", true},
+		{"vars.deploy == true", true},
+		{"{{env}} == 'prod'", true},
+		{`env != "prod"`, false},
+		{"empty", false},
+		{"!empty && deploy", true},
+		{"not deploy or env == prod", false},
+		{"steps.review.clean", false},
+		{"steps.review.issues == 3", true},
+		{"(steps.review.clean || deploy) && env == 'prod'", true},
+		{"steps.missing.key", false},
+	}
+	for _, tt := range tests {
+		cond, err := ParseCondition(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCondition(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := cond.Eval(env); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.expr, got, tt.want)
+		}
+		// String must round-trip
+		again, err := ParseCondition(cond.String())
+		if err != nil || again.Eval(env) != tt.want {
+			t.Errorf("%q did not round-trip via %q: %v", tt.expr, cond.String(), err)
+		}
+	}
+
+	for _, bad := range []string{"", "a ==", "(a", "a b", "'open", "steps.x", "a = b", "{{}}"} {
+		if _, err := ParseCondition(bad); err == nil {
+			t.Errorf("ParseCondition(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+const controlFormula = `
+formula = "review-loop"
+type = "workflow"
+
+[vars.deploy]
+type = "bool"
+default = "no"
+
+[vars.targets]
+default = "api,web"
+
+[[steps]]
+id = "implement"
+
+[[steps]]
+id = "review"
+needs = ["implement"]
+retry = 3
+until = "steps.review.clean"
+
+[[steps]]
+id = "fix"
+needs = ["review"]
+when = "!steps.review.clean"
+
+[[steps]]
+id = "test"
+needs = ["review"]
+for_each = "targets"
+title = "Test {{item}}"
+
+[[steps]]
+id = "deploy"
+needs = ["fix", "test"]
+when = "deploy"
+`
+
+func TestControl_ReadyStepsSkipsFalseWhen(t *testing.T) {
+	f, err := Parse([]byte(controlFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !f.HasControlFlow() {
+		t.Fatal("HasControlFlow = false")
+	}
+
+	state := &RunState{
+		Vars:      map[string]string{"deploy": "no"},
+		Completed: map[string]bool{"implement": true},
+	}
+	if got := f.Advance(state); strings.Join(got, ",") != "review" {
+		t.Fatalf("Advance = %v, want [review]", got)
+	}
+
+	// Review loops until clean, at most retry times
+	state.Outputs = map[string]map[string]string{"review": {"clean": "no"}}
+	for i := 0; i < 3; i++ {
+		if !f.Finish(state, "review") {
+			t.Fatalf("attempt %d: Finish = false, want repeat", i+1)
+		}
+	}
+	if f.Finish(state, "review") {
+		t.Fatal("Finish repeated past retry bound")
+	}
+
+	// Not clean: fix runs; deploy=no so deploy is skipped once fix and test finish
+	if got := f.Advance(state); strings.Join(got, ",") != "fix,test" {
+		t.Fatalf("Advance = %v, want [fix test]", got)
+	}
+	state.Completed["fix"], state.Completed["test"] = true, true
+	if got := f.Advance(state); len(got) != 0 || !state.Skipped["deploy"] {
+		t.Errorf("Advance = %v skipped = %v, want deploy skipped", got, state.Skipped)
+	}
+
+	// Clean on the first pass: fix is skipped and test becomes ready
+	state = &RunState{
+		Completed: map[string]bool{"implement": true},
+		Outputs:   map[string]map[string]string{"review": {"clean": "true"}},
+	}
+	if f.Finish(state, "review") {
+		t.Error("Finish repeated although until holds")
+	}
+	if got := f.Advance(state); strings.Join(got, ",") != "test" || !state.Skipped["fix"] {
+		t.Errorf("Advance = %v skipped = %v, want [test] with fix skipped", got, state.Skipped)
+	}
+
+	// ReadySteps evaluates when without vars, so deploy is skipped
+	ready := f.ReadySteps(map[string]bool{"implement": true, "review": true, "fix": true, "test": true})
+	if len(ready) != 0 {
+		t.Errorf("ReadySteps = %v, want none", ready)
+	}
+}
+
+func TestControl_Expand(t *testing.T) {
+	f, err := Parse([]byte(controlFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	x, err := f.Expand(map[string]string{"targets": "api, web ,cli", "deploy": "yes"})
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if got := stepIDs(x); got != "implement,review,fix,test-1,test-2,test-3,deploy" {
+		t.Errorf("steps = %s", got)
+	}
+	if got := x.GetStep("test-2").Title; got != "Test web" {
+		t.Errorf("test-2 title = %q", got)
+	}
+	if got := strings.Join(x.GetStep("deploy").Needs, ","); got != "fix,test-1,test-2,test-3" {
+		t.Errorf("deploy needs = %s", got)
+	}
+	if x.GetStep("deploy").When != "" {
+		t.Errorf("deploy when = %q, want decided", x.GetStep("deploy").When)
+	}
+	if x.GetStep("fix").When == "" {
+		t.Error("fix when was dropped, but it depends on a step output")
+	}
+	if !x.HasControlFlow() || f.GetStep("test").ForEach == "" {
+		t.Error("Expand modified the original formula or lost runtime conditions")
+	}
+
+	// deploy defaults to no, so it is dropped along with its when
+	order, err := f.ExecutionOrder(map[string]string{"targets": ""})
+	if err != nil {
+		t.Fatalf("ExecutionOrder failed: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "implement,review,fix" {
+		t.Errorf("ExecutionOrder = %s", got)
+	}
+}
+
+func TestValidate_BadControlFlow(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps string
+		want  string
+	}{
+		{"until without retry", "[[steps]]\nid = \"a\"\nuntil = \"steps.a.ok\"", "until requires retry"},
+		{"retry without until", "[[steps]]\nid = \"a\"\nretry = 2", "retry requires until"},
+		{"unbounded retry", "[[steps]]\nid = \"a\"\nretry = 99\nuntil = \"steps.a.ok\"", "retry must be between"},
+		{"bad condition", "[[steps]]\nid = \"a\"\nwhen = \"x ==\"", "unexpected end"},
+		{"unknown var", "[[steps]]\nid = \"a\"\nwhen = \"nope\"", "unknown variable: nope"},
+		{"unknown for_each var", "[[steps]]\nid = \"a\"\nfor_each = \"nope\"", "for_each references unknown variable"},
+		{"output of unrelated step", "[[steps]]\nid = \"a\"\n[[steps]]\nid = \"b\"\nwhen = \"steps.a.ok\"", "a is not a step it needs"},
+		{"when reads own output", "[[steps]]\nid = \"a\"\nwhen = \"steps.a.ok\"", "a is not a step it needs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte("formula = \"bad\"\n" + tt.steps + "\n"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepControl(t *testing.T) {
+	c := &StepControl{ID: "review", Until: "steps.review.clean", Retry: 3, Attempt: 1,
+		Outputs: map[string]string{"clean": "no", "issues": "2"}}
+	desc := SetStepControl("Review the change.\n\nstep.attempt: 0\n", c)
+	if strings.Count(desc, "step.attempt") != 1 || !strings.HasPrefix(desc, "Review the change.\n\nstep.id: review") {
+		t.Errorf("SetStepControl = %q", desc)
+	}
+
+	got := ParseStepControl(desc)
+	if got.ID != "review" || got.Until != c.Until || got.Retry != 3 || got.Attempt != 1 || got.Outputs["issues"] != "2" {
+		t.Errorf("ParseStepControl = %+v", got)
+	}
+	if !ParseStepControl("plain description").IsZero() {
+		t.Error("plain description has control")
+	}
+
+	f, err := Parse([]byte(controlFormula))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.EmbedControl()
+	if got := ParseStepControl(f.GetStep("fix").Description); got.ID != "fix" || got.When == "" {
+		t.Errorf("embedded control = %+v", got)
+	}
+	if f.GetStep("fix").When != "" {
+		t.Error("EmbedControl left when on the step")
+	}
+}
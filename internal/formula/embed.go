@@ -191,8 +191,15 @@ func CheckFormulaHealth(beadsPath string) (*HealthReport, error) {
 	}
 
 	report := &HealthReport{}
+	locked := lockedFiles(formulasDir)
 
 	for filename, embeddedHash := range embedded {
+		// Formulas installed from a source (see registry.go) replace the
+		// embedded copy on purpose
+		if locked[filename] {
+			continue
+		}
+
 		status := FormulaStatus{
 			Name:         filename,
 			EmbeddedHash: embeddedHash,
@@ -267,7 +274,13 @@ func UpdateFormulas(beadsPath string) (updated, skipped, reinstalled int, err er
 		return 0, 0, 0, err
 	}
 
+	locked := lockedFiles(formulasDir)
+
 	for filename, embeddedHash := range embedded {
+		if locked[filename] {
+			continue // installed from a source; gt formula update owns it
+		}
+
 		installedHash, wasInstalled := installed.Formulas[filename]
 		destPath := filepath.Join(formulasDir, filename)
 		currentHash, fileErr := computeFileHash(destPath)
@@ -3,8 +3,7 @@ package formula
 import (
 	"fmt"
 	"os"
-
-	"github.com/BurntSushi/toml"
+	"path/filepath"
 )
 
 // ParseFile reads and parses a formula.toml file.
@@ -13,24 +12,14 @@ func ParseFile(path string) (*Formula, error) {
 	if err != nil {
 		return nil, fmt.Errorf("reading formula file: %w", err)
 	}
-	return Parse(data)
+	// Composed formulas are resolved from the same directory first
+	return ParseWithLoader(data, NewLoader(filepath.Dir(path)))
 }
 
-// Parse parses formula.toml content from bytes.
+// Parse parses formula.toml content from bytes. Formulas it extends or
+// includes are resolved from the embedded formulas.
 func Parse(data []byte) (*Formula, error) {
-	var f Formula
-	if _, err := toml.Decode(string(data), &f); err != nil {
-		return nil, fmt.Errorf("parsing TOML: %w", err)
-	}
-
-	// Infer type from content if not explicitly set
-	f.inferType()
-
-	if err := f.Validate(); err != nil {
-		return nil, err
-	}
-
-	return &f, nil
+	return ParseWithLoader(data, NewLoader())
 }
 
 // inferType sets the formula type based on content when not explicitly set.
@@ -62,6 +51,10 @@ func (f *Formula) Validate() error {
 		return fmt.Errorf("invalid formula type %q (must be convoy, workflow, expansion, or aspect)", f.Type)
 	}
 
+	if err := f.validateParams(); err != nil {
+		return err
+	}
+
 	// Type-specific validation
 	switch f.Type {
 	case TypeConvoy:
@@ -137,7 +130,7 @@ func (f *Formula) validateWorkflow() error {
 		return err
 	}
 
-	return nil
+	return f.validateControl()
 }
 
 func (f *Formula) validateExpansion() error {
@@ -166,6 +159,11 @@ func (f *Formula) validateExpansion() error {
 		}
 	}
 
+	// Check for cycles
+	if err := f.checkCycles(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -189,12 +187,14 @@ func (f *Formula) validateAspect() error {
 	return nil
 }
 
-// checkCycles detects circular dependencies in steps.
+// checkCycles detects circular dependencies in workflow steps and expansion
+// templates. Cycles in extends/include are caught earlier, while resolving.
 func (f *Formula) checkCycles() error {
 	// Build adjacency list
+	ids := f.GetAllIDs()
 	deps := make(map[string][]string)
-	for _, step := range f.Steps {
-		deps[step.ID] = step.Needs
+	for _, id := range ids {
+		deps[id] = f.GetDependencies(id)
 	}
 
 	// DFS for cycle detection
@@ -222,8 +222,8 @@ func (f *Formula) checkCycles() error {
 		return nil
 	}
 
-	for _, step := range f.Steps {
-		if err := visit(step.ID); err != nil {
+	for _, id := range ids {
+		if err := visit(id); err != nil {
 			return err
 		}
 	}
@@ -329,21 +329,9 @@ func (f *Formula) ReadySteps(completed map[string]bool) []string {
 
 	switch f.Type {
 	case TypeWorkflow:
-		for _, step := range f.Steps {
-			if completed[step.ID] {
-				continue
-			}
-			allMet := true
-			for _, need := range step.Needs {
-				if !completed[need] {
-					allMet = false
-					break
-				}
-			}
-			if allMet {
-				ready = append(ready, step.ID)
-			}
-		}
+		// Steps whose when condition is false are skipped; use Advance
+		// directly to supply vars and step outputs.
+		ready = f.Advance(&RunState{Completed: completed})
 	case TypeExpansion:
 		for _, tmpl := range f.Template {
 			if completed[tmpl.ID] {
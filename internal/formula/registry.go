@@ -0,0 +1,400 @@
+package formula
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LockFileName is the registry lockfile kept in a formulas directory next to
+// .installed.json. It records formulas installed from git or path sources.
+const LockFileName = ".lock.json"
+
+// LockFile records formulas installed with gt formula install.
+type LockFile struct {
+	Version  int                  `json:"version"`
+	Formulas map[string]LockEntry `json:"formulas"` // formula name -> entry
+}
+
+// LockEntry records where an installed formula came from and what was
+// installed, so updates can be checked against the source.
+type LockEntry struct {
+	Source      string    `json:"source"`            // git URL or absolute path
+	Ref         string    `json:"ref,omitempty"`     // requested version; empty tracks the latest
+	Version     string    `json:"version,omitempty"` // resolved tag, or short commit; never a branch
+	Commit      string    `json:"commit,omitempty"`  // full commit for git sources
+	Path        string    `json:"path"`              // formula file within the source
+	Checksum    string    `json:"checksum"`          // "sha256:<hex>" of the installed file
+	Signed      bool      `json:"signed,omitempty"`  // tag or commit signature was verified
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// Pinned reports whether the entry was installed at an exact version (a tag
+// or commit). Entries installed at a branch follow it.
+func (e LockEntry) Pinned() bool {
+	return e.Ref != "" && (e.Ref == e.Version || strings.HasPrefix(e.Commit, e.Ref))
+}
+
+// LoadLockFile loads the lockfile from a formulas directory. A missing file
+// is an empty lockfile.
+func LoadLockFile(formulasDir string) (*LockFile, error) {
+	data, err := os.ReadFile(filepath.Join(formulasDir, LockFileName))
+	if os.IsNotExist(err) {
+		return &LockFile{Version: 1, Formulas: make(map[string]LockEntry)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading lockfile: %w", err)
+	}
+	var l LockFile
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("parsing lockfile: %w", err)
+	}
+	if l.Formulas == nil {
+		l.Formulas = make(map[string]LockEntry)
+	}
+	return &l, nil
+}
+
+// Save writes the lockfile to a formulas directory.
+func (l *LockFile) Save(formulasDir string) error {
+	if l.Version == 0 {
+		l.Version = 1
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding lockfile: %w", err)
+	}
+	return os.WriteFile(filepath.Join(formulasDir, LockFileName), append(data, '\n'), 0644)
+}
+
+// Names returns the locked formula names in order.
+func (l *LockFile) Names() []string {
+	names := make([]string, 0, len(l.Formulas))
+	for name := range l.Formulas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lockedFiles returns the formula filenames recorded in a directory's
+// lockfile. Embedded formula provisioning leaves these files alone.
+func lockedFiles(formulasDir string) map[string]bool {
+	files := make(map[string]bool)
+	l, err := LoadLockFile(formulasDir)
+	if err != nil {
+		return files
+	}
+	for name := range l.Formulas {
+		files[name+".formula.toml"] = true
+	}
+	return files
+}
+
+// Source is where formulas are installed from: a git repository or a local
+// file or directory, with an optional version (tag, branch or commit).
+type Source struct {
+	Location string
+	Version  string
+}
+
+// ParseSource parses <git-url|path>[@version]. The version is split off at
+// the last '@' that follows the final path separator, so scp-style URLs
+// like git@github.com:org/repo are left intact.
+func ParseSource(spec string) Source {
+	at := strings.LastIndex(spec, "@")
+	sep := strings.LastIndexAny(spec, "/:")
+	if at > sep && at > 0 && at < len(spec)-1 {
+		return Source{Location: spec[:at], Version: spec[at+1:]}
+	}
+	return Source{Location: spec}
+}
+
+// IsGit reports whether the location is a git URL rather than a path.
+// Local paths with a version are fetched with git too.
+func (s Source) IsGit() bool {
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://", "file://"} {
+		if strings.HasPrefix(s.Location, prefix) {
+			return true
+		}
+	}
+	// scp-like: user@host:path
+	if at := strings.Index(s.Location, "@"); at > 0 {
+		if colon := strings.Index(s.Location, ":"); colon > at && !strings.Contains(s.Location[:at], "/") {
+			return true
+		}
+	}
+	return strings.HasSuffix(s.Location, ".git")
+}
+
+// String returns the source in <location>[@version] form.
+func (s Source) String() string {
+	if s.Version == "" {
+		return s.Location
+	}
+	return s.Location + "@" + s.Version
+}
+
+// FindSourceFormulas lists the formulas in a fetched source, mapping name to
+// path relative to root. root may be a single .formula.toml file; otherwise
+// root, root/formulas and root/.beads/formulas are searched, earlier
+// directories winning.
+func FindSourceFormulas(root string) (map[string]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]string)
+	if !info.IsDir() {
+		name, ok := strings.CutSuffix(filepath.Base(root), ".formula.toml")
+		if !ok {
+			return nil, fmt.Errorf("%s is not a .formula.toml file", root)
+		}
+		found[name] = ""
+		return found, nil
+	}
+
+	for _, dir := range []string{".", "formulas", filepath.Join(".beads", "formulas")} {
+		entries, err := os.ReadDir(filepath.Join(root, dir))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutSuffix(e.Name(), ".formula.toml")
+			if !ok || e.IsDir() {
+				continue
+			}
+			if _, seen := found[name]; !seen {
+				found[name] = filepath.Join(dir, e.Name())
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no .formula.toml files found in %s", root)
+	}
+	return found, nil
+}
+
+// ContentChecksum returns the lockfile checksum of formula content.
+func ContentChecksum(data []byte) string {
+	return "sha256:" + computeHash(data)
+}
+
+// InstallOptions controls InstallFromDir.
+type InstallOptions struct {
+	Only     []string // install only these formulas (default all)
+	Checksum string   // expected sha256 of the formula (single formula only)
+	Force    bool     // overwrite local edits and files from other sources
+}
+
+// InstallFromDir installs formulas from a fetched source at root into
+// formulasDir and records them in the lockfile. base supplies the source
+// fields of each lock entry (Source, Ref, Version, Commit, Signed).
+//
+// Each formula must parse. An install is refused when the file on disk was
+// edited since it was installed, came from another source, or was not
+// installed from a source at all, unless opts.Force is set. Reinstalling the
+// same version with different content is always refused: the source changed
+// under an existing version (for example a moved tag).
+//
+// Returns the names installed, in order.
+func InstallFromDir(formulasDir, root string, base LockEntry, opts InstallOptions) ([]string, error) {
+	available, err := FindSourceFormulas(root)
+	if err != nil {
+		return nil, err
+	}
+	names := append([]string(nil), opts.Only...)
+	if len(names) == 0 {
+		for name := range available {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if opts.Checksum != "" && len(names) != 1 {
+		return nil, fmt.Errorf("a checksum applies to one formula, but %d were selected", len(names))
+	}
+
+	if err := os.MkdirAll(formulasDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating formulas directory: %w", err)
+	}
+	lock, err := LoadLockFile(formulasDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check everything before writing anything
+	contents := make(map[string][]byte, len(names))
+	for _, name := range names {
+		rel, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("formula %s not found in source", name)
+		}
+		path := filepath.Join(root, rel)
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is within the fetched source
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		if _, err := ParseWithLoader(data, NewLoader(filepath.Dir(path), formulasDir)); err != nil {
+			return nil, fmt.Errorf("formula %s is invalid: %w", name, err)
+		}
+		checksum := ContentChecksum(data)
+		if opts.Checksum != "" && checksum != "sha256:"+strings.TrimPrefix(opts.Checksum, "sha256:") {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, opts.Checksum, checksum)
+		}
+		if err := checkInstallTarget(formulasDir, name, lock, base, checksum, opts.Force); err != nil {
+			return nil, err
+		}
+		contents[name] = data
+	}
+
+	now := time.Now().UTC()
+	for _, name := range names {
+		dest := filepath.Join(formulasDir, name+".formula.toml")
+		if err := os.WriteFile(dest, contents[name], 0644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", name, err)
+		}
+		entry := base
+		entry.Path = filepath.ToSlash(available[name])
+		entry.Checksum = ContentChecksum(contents[name])
+		entry.InstalledAt = now
+		lock.Formulas[name] = entry
+	}
+	if err := lock.Save(formulasDir); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// checkInstallTarget refuses to overwrite a formula that isn't ours to
+// replace, and detects a source whose content changed under a version.
+func checkInstallTarget(formulasDir, name string, lock *LockFile, base LockEntry, checksum string, force bool) error {
+	prev, locked := lock.Formulas[name]
+	if locked && prev.Source == base.Source && prev.Version != "" && prev.Version == base.Version &&
+		prev.Checksum != checksum {
+		return fmt.Errorf("checksum mismatch for %s@%s: lockfile has %s, source has %s (was the version retagged?)",
+			name, base.Version, prev.Checksum, checksum)
+	}
+	if force {
+		return nil
+	}
+
+	current, err := computeFileHash(filepath.Join(formulasDir, name+".formula.toml"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading installed %s: %w", name, err)
+	}
+	current = "sha256:" + current
+	switch {
+	case !locked && current != checksum:
+		return fmt.Errorf("%s already exists and was not installed from a source (use --force to replace it)", name)
+	case locked && prev.Source != base.Source:
+		return fmt.Errorf("%s is installed from %s (remove it first, or use --force)", name, prev.Source)
+	case locked && current != prev.Checksum:
+		return fmt.Errorf("%s was modified since it was installed (use --force to overwrite)", name)
+	}
+	return nil
+}
+
+// RemoveInstalled deletes a formula installed from a source and drops it
+// from the lockfile. Locally modified files are kept unless force is set.
+func RemoveInstalled(formulasDir, name string, force bool) error {
+	lock, err := LoadLockFile(formulasDir)
+	if err != nil {
+		return err
+	}
+	entry, ok := lock.Formulas[name]
+	if !ok {
+		return fmt.Errorf("%s was not installed with gt formula install", name)
+	}
+	path := filepath.Join(formulasDir, name+".formula.toml")
+	current, err := computeFileHash(path)
+	switch {
+	case err == nil && "sha256:"+current != entry.Checksum && !force:
+		return fmt.Errorf("%s was modified since it was installed (use --force to remove it)", name)
+	case err != nil && !os.IsNotExist(err):
+		return fmt.Errorf("reading %s: %w", name, err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing %s: %w", name, err)
+	}
+	delete(lock.Formulas, name)
+	return lock.Save(formulasDir)
+}
+
+// LockedStatus returns the local state of a locked formula: "ok",
+// "modified" (edited since install) or "missing".
+func LockedStatus(formulasDir, name string, entry LockEntry) string {
+	current, err := computeFileHash(filepath.Join(formulasDir, name+".formula.toml"))
+	switch {
+	case os.IsNotExist(err):
+		return "missing"
+	case err != nil || "sha256:"+current != entry.Checksum:
+		return "modified"
+	}
+	return "ok"
+}
+
+// LatestVersion returns the highest release version among tags (vX.Y.Z or
+// X.Y.Z, pre-releases excluded), or "" if none look like versions.
+func LatestVersion(tags []string) string {
+	latest := ""
+	for _, tag := range tags {
+		if _, ok := parseReleaseVersion(tag); !ok {
+			continue
+		}
+		if latest == "" || CompareVersions(tag, latest) > 0 {
+			latest = tag
+		}
+	}
+	return latest
+}
+
+// CompareVersions compares two release versions numerically, returning -1,
+// 0 or 1. Versions that don't parse compare as strings.
+func CompareVersions(a, b string) int {
+	va, okA := parseReleaseVersion(a)
+	vb, okB := parseReleaseVersion(b)
+	if !okA || !okB {
+		return strings.Compare(a, b)
+	}
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseReleaseVersion parses v1, v1.2 or v1.2.3 (the v is optional).
+func parseReleaseVersion(s string) ([]int, bool) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) > 3 {
+		return nil, false
+	}
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		nums[i] = n
+	}
+	return nums, true
+}
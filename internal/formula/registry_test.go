@@ -0,0 +1,230 @@
+package formula
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		spec, location, version string
+		git                     bool
+	}{
+		{"https://github.com/acme/formulas@v1.2.0", "https://github.com/acme/formulas", "v1.2.0", true},
+		{"https://github.com/acme/formulas", "https://github.com/acme/formulas", "", true},
+		{"git@github.com:acme/formulas.git", "git@github.com:acme/formulas.git", "", true},
+		{"git@github.com:acme/formulas@main", "git@github.com:acme/formulas", "main", true},
+		{"../shared/formulas", "../shared/formulas", "", false},
+		{"/srv/formulas@v2", "/srv/formulas", "v2", false},
+		{"./release.formula.toml", "./release.formula.toml", "", false},
+	}
+	for _, tt := range tests {
+		s := ParseSource(tt.spec)
+		if s.Location != tt.location || s.Version != tt.version || s.IsGit() != tt.git {
+			t.Errorf("ParseSource(%q) = %+v (git=%v), want %s @ %s (git=%v)",
+				tt.spec, s, s.IsGit(), tt.location, tt.version, tt.git)
+		}
+		if s.String() != tt.spec {
+			t.Errorf("String() = %q, want %q", s.String(), tt.spec)
+		}
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	tags := []string{"v1.2.0", "v1.10.0", "v1.9.3", "v2.0.0-rc1", "nightly", "1.3"}
+	if got := LatestVersion(tags); got != "v1.10.0" {
+		t.Errorf("LatestVersion = %q, want v1.10.0", got)
+	}
+	if got := LatestVersion([]string{"nightly"}); got != "" {
+		t.Errorf("LatestVersion(no versions) = %q", got)
+	}
+	if CompareVersions("v1.2", "1.2.0") != 0 || CompareVersions("v1.2.1", "v1.10") >= 0 {
+		t.Error("CompareVersions wrong")
+	}
+}
+
+// writeSource creates a source directory with formulas under formulas/.
+func writeSource(t *testing.T, formulas map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "formulas")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range formulas {
+		if err := os.WriteFile(filepath.Join(dir, name+".formula.toml"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func simpleFormula(name, title string) string {
+	return "formula = \"" + name + "\"\n[[steps]]\nid = \"a\"\ntitle = \"" + title + "\"\n"
+}
+
+func TestInstallFromDir(t *testing.T) {
+	src := writeSource(t, map[string]string{
+		"review":  simpleFormula("review", "Review"),
+		"release": simpleFormula("release", "Release"),
+	})
+	formulasDir := filepath.Join(t.TempDir(), ".beads", "formulas")
+	base := LockEntry{Source: "https://example.com/formulas", Version: "v1.0.0", Commit: "abc"}
+
+	names, err := InstallFromDir(formulasDir, src, base, InstallOptions{})
+	if err != nil {
+		t.Fatalf("InstallFromDir: %v", err)
+	}
+	if strings.Join(names, ",") != "release,review" {
+		t.Errorf("installed = %v", names)
+	}
+	lock, err := LoadLockFile(formulasDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := lock.Formulas["review"]
+	if entry.Source != base.Source || entry.Version != "v1.0.0" || entry.Path != "formulas/review.formula.toml" ||
+		!strings.HasPrefix(entry.Checksum, "sha256:") || entry.InstalledAt.IsZero() {
+		t.Errorf("lock entry = %+v", entry)
+	}
+	if got := LockedStatus(formulasDir, "review", entry); got != "ok" {
+		t.Errorf("LockedStatus = %s", got)
+	}
+
+	// Reinstalling identical content is fine
+	if _, err := InstallFromDir(formulasDir, src, base, InstallOptions{}); err != nil {
+		t.Errorf("reinstall: %v", err)
+	}
+
+	// Same version with different content means the tag moved
+	changed := writeSource(t, map[string]string{"review": simpleFormula("review", "Tampered")})
+	_, err = InstallFromDir(formulasDir, changed, base, InstallOptions{Force: true})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch for review@v1.0.0") {
+		t.Errorf("retag error = %v", err)
+	}
+
+	// A new version may change content, but not over local edits
+	path := filepath.Join(formulasDir, "review.formula.toml")
+	if err := os.WriteFile(path, []byte(simpleFormula("review", "Mine")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := LockedStatus(formulasDir, "review", entry); got != "modified" {
+		t.Errorf("LockedStatus after edit = %s", got)
+	}
+	next := base
+	next.Version = "v1.1.0"
+	_, err = InstallFromDir(formulasDir, changed, next, InstallOptions{})
+	if err == nil || !strings.Contains(err.Error(), "modified since it was installed") {
+		t.Errorf("local edit error = %v", err)
+	}
+	if _, err := InstallFromDir(formulasDir, changed, next, InstallOptions{Force: true}); err != nil {
+		t.Errorf("forced update: %v", err)
+	}
+
+	// Another source doesn't silently take over
+	other := LockEntry{Source: "/elsewhere", Version: ""}
+	_, err = InstallFromDir(formulasDir, src, other, InstallOptions{Only: []string{"release"}})
+	if err == nil || !strings.Contains(err.Error(), "installed from https://example.com/formulas") {
+		t.Errorf("other source error = %v", err)
+	}
+}
+
+func TestInstallFromDir_ChecksumAndSelection(t *testing.T) {
+	src := writeSource(t, map[string]string{
+		"review":  simpleFormula("review", "Review"),
+		"release": simpleFormula("release", "Release"),
+		"broken":  "formula = \"broken\"\n",
+	})
+	formulasDir := t.TempDir()
+	base := LockEntry{Source: src}
+
+	if _, err := InstallFromDir(formulasDir, src, base, InstallOptions{}); err == nil || !strings.Contains(err.Error(), "formula broken is invalid") {
+		t.Errorf("invalid formula error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(formulasDir, "review.formula.toml")); !os.IsNotExist(err) {
+		t.Error("a failed install wrote files")
+	}
+
+	if _, err := InstallFromDir(formulasDir, src, base, InstallOptions{Only: []string{"nope"}}); err == nil {
+		t.Error("expected error for formula not in source")
+	}
+	_, err := InstallFromDir(formulasDir, src, base, InstallOptions{Only: []string{"review"}, Checksum: "sha256:0000"})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch for review") {
+		t.Errorf("checksum error = %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(src, "formulas", "review.formula.toml"))
+	sum := computeHash(data)
+	if _, err := InstallFromDir(formulasDir, src, base, InstallOptions{Only: []string{"review"}, Checksum: sum}); err != nil {
+		t.Errorf("install with matching checksum: %v", err)
+	}
+
+	// A hand-written file isn't replaced without --force
+	if err := os.WriteFile(filepath.Join(formulasDir, "release.formula.toml"), []byte(simpleFormula("release", "Hand")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = InstallFromDir(formulasDir, src, base, InstallOptions{Only: []string{"release"}})
+	if err == nil || !strings.Contains(err.Error(), "not installed from a source") {
+		t.Errorf("untracked file error = %v", err)
+	}
+}
+
+func TestRemoveInstalled(t *testing.T) {
+	src := writeSource(t, map[string]string{"review": simpleFormula("review", "Review")})
+	formulasDir := t.TempDir()
+	if _, err := InstallFromDir(formulasDir, src, LockEntry{Source: src}, InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(formulasDir, "review.formula.toml")
+	if err := os.WriteFile(path, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveInstalled(formulasDir, "review", false); err == nil {
+		t.Error("removed a modified formula without force")
+	}
+	if err := RemoveInstalled(formulasDir, "review", true); err != nil {
+		t.Fatalf("RemoveInstalled: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("formula file still exists")
+	}
+	lock, _ := LoadLockFile(formulasDir)
+	if len(lock.Formulas) != 0 {
+		t.Errorf("lock still has %v", lock.Names())
+	}
+	if err := RemoveInstalled(formulasDir, "review", false); err == nil {
+		t.Error("expected error removing a formula that isn't installed")
+	}
+}
+
+// TestUpdateFormulas_SkipsLocked verifies embedded provisioning leaves
+// formulas installed from a source alone.
+func TestUpdateFormulas_SkipsLocked(t *testing.T) {
+	tmpDir := t.TempDir()
+	formulasDir := filepath.Join(tmpDir, ".beads", "formulas")
+	src := writeSource(t, map[string]string{"mol-deacon-patrol": simpleFormula("mol-deacon-patrol", "Ours")})
+	if _, err := InstallFromDir(formulasDir, src, LockEntry{Source: src}, InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := UpdateFormulas(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(formulasDir, "mol-deacon-patrol.formula.toml"))
+	if !strings.Contains(string(data), "Ours") {
+		t.Error("UpdateFormulas overwrote a formula installed from a source")
+	}
+
+	report, err := CheckFormulaHealth(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range report.Formulas {
+		if f.Name == "mol-deacon-patrol.formula.toml" {
+			t.Errorf("health check reported locked formula as %s", f.Status)
+		}
+	}
+}
@@ -25,89 +25,105 @@ const (
 // Formula represents a parsed formula.toml file.
 type Formula struct {
 	// Common fields
-	Name        string      `toml:"formula"`
-	Description string      `toml:"description"`
-	Type        FormulaType `toml:"type"`
-	Version     int         `toml:"version"`
+	Name        string      `toml:"formula,omitempty"`
+	Description string      `toml:"description,omitempty"`
+	Type        FormulaType `toml:"type,omitempty"`
+	Version     int         `toml:"version,omitzero"`
+
+	// Composition, resolved away by ParseWithLoader
+	Extends Names     `toml:"extends,omitempty"`
+	Include []Include `toml:"include,omitempty"`
 
 	// Convoy-specific
-	Inputs    map[string]Input `toml:"inputs"`
-	Prompts   map[string]string `toml:"prompts"`
-	Output    *Output           `toml:"output"`
-	Legs      []Leg             `toml:"legs"`
-	Synthesis *Synthesis        `toml:"synthesis"`
+	Inputs    map[string]Input `toml:"inputs,omitempty"`
+	Prompts   map[string]string `toml:"prompts,omitempty"`
+	Output    *Output           `toml:"output,omitempty"`
+	Legs      []Leg             `toml:"legs,omitempty"`
+	Synthesis *Synthesis        `toml:"synthesis,omitempty"`
 
 	// Workflow-specific
-	Steps []Step           `toml:"steps"`
-	Vars  map[string]Var   `toml:"vars"`
+	Steps []Step           `toml:"steps,omitempty"`
+	Vars  map[string]Var   `toml:"vars,omitempty"`
 
 	// Expansion-specific
-	Template []Template `toml:"template"`
+	Template []Template `toml:"template,omitempty"`
 
 	// Aspect-specific (similar to convoy but for analysis)
-	Aspects []Aspect `toml:"aspects"`
+	Aspects []Aspect `toml:"aspects,omitempty"`
 }
 
 // Aspect represents a parallel analysis aspect in an aspect formula.
 type Aspect struct {
-	ID          string `toml:"id"`
-	Title       string `toml:"title"`
-	Focus       string `toml:"focus"`
-	Description string `toml:"description"`
+	ID          string `toml:"id,omitempty"`
+	Title       string `toml:"title,omitempty"`
+	Focus       string `toml:"focus,omitempty"`
+	Description string `toml:"description,omitempty"`
 }
 
 // Input represents an input parameter for a formula.
 type Input struct {
-	Description    string   `toml:"description"`
-	Type           string   `toml:"type"`
-	Required       bool     `toml:"required"`
-	RequiredUnless []string `toml:"required_unless"`
-	Default        string   `toml:"default"`
+	Description    string   `toml:"description,omitempty"`
+	Type           string   `toml:"type,omitempty"`
+	Required       bool     `toml:"required,omitempty"`
+	RequiredUnless []string `toml:"required_unless,omitempty"`
+	Default        string   `toml:"default,omitempty"`
+	Enum           []string `toml:"enum,omitempty"`
+	Pattern        string   `toml:"pattern,omitempty"`
 }
 
 // Output configures where formula outputs are written.
 type Output struct {
-	Directory  string `toml:"directory"`
-	LegPattern string `toml:"leg_pattern"`
-	Synthesis  string `toml:"synthesis"`
+	Directory  string `toml:"directory,omitempty"`
+	LegPattern string `toml:"leg_pattern,omitempty"`
+	Synthesis  string `toml:"synthesis,omitempty"`
 }
 
 // Leg represents a parallel execution unit in a convoy formula.
 type Leg struct {
-	ID          string `toml:"id"`
-	Title       string `toml:"title"`
-	Focus       string `toml:"focus"`
-	Description string `toml:"description"`
+	ID          string `toml:"id,omitempty"`
+	Title       string `toml:"title,omitempty"`
+	Focus       string `toml:"focus,omitempty"`
+	Description string `toml:"description,omitempty"`
 }
 
 // Synthesis represents the synthesis step that combines leg outputs.
 type Synthesis struct {
-	Title       string   `toml:"title"`
-	Description string   `toml:"description"`
-	DependsOn   []string `toml:"depends_on"`
+	Title       string   `toml:"title,omitempty"`
+	Description string   `toml:"description,omitempty"`
+	DependsOn   []string `toml:"depends_on,omitempty"`
 }
 
 // Step represents a sequential step in a workflow formula.
 type Step struct {
-	ID          string   `toml:"id"`
-	Title       string   `toml:"title"`
-	Description string   `toml:"description"`
-	Needs       []string `toml:"needs"`
+	ID          string   `toml:"id,omitempty"`
+	Title       string   `toml:"title,omitempty"`
+	Description string   `toml:"description,omitempty"`
+	Needs       []string `toml:"needs,omitempty"`
+
+	// Control flow (see control.go)
+	When    string `toml:"when,omitempty"`     // run only if this condition holds
+	ForEach string `toml:"for_each,omitempty"` // fan out over a comma-separated list var
+	Retry   int    `toml:"retry,omitzero"`     // max repeats while until is false
+	Until   string `toml:"until,omitempty"`    // repeat the step until this holds
 }
 
 // Template represents a template step in an expansion formula.
 type Template struct {
-	ID          string   `toml:"id"`
-	Title       string   `toml:"title"`
-	Description string   `toml:"description"`
-	Needs       []string `toml:"needs"`
+	ID          string   `toml:"id,omitempty"`
+	Title       string   `toml:"title,omitempty"`
+	Description string   `toml:"description,omitempty"`
+	Needs       []string `toml:"needs,omitempty"`
 }
 
 // Var represents a variable definition for formulas.
 type Var struct {
-	Description string `toml:"description"`
-	Required    bool   `toml:"required"`
-	Default     string `toml:"default"`
+	Description    string   `toml:"description,omitempty"`
+	Type           string   `toml:"type,omitempty"`
+	Required       bool     `toml:"required,omitempty"`
+	RequiredUnless []string `toml:"required_unless,omitempty"`
+	Default        string   `toml:"default,omitempty"`
+	Enum           []string `toml:"enum,omitempty"`
+	Pattern        string   `toml:"pattern,omitempty"`
 }
 
 // IsValid returns true if the formula type is recognized.
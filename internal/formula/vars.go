@@ -0,0 +1,309 @@
+package formula
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VarType is the type of a formula variable or input.
+type VarType string
+
+const (
+	// VarString accepts any value (the default).
+	VarString VarType = "string"
+	// VarInt accepts a base-10 integer.
+	VarInt VarType = "int"
+	// VarBool accepts true/false, yes/no, 1/0.
+	VarBool VarType = "bool"
+	// VarEnum accepts one of the values listed in enum.
+	VarEnum VarType = "enum"
+	// VarPath accepts a single-line filesystem path.
+	VarPath VarType = "path"
+	// VarBeadID accepts a bead ID such as gt-abc12.
+	VarBeadID VarType = "bead-id"
+	// VarRig accepts the name of a rig in the town.
+	VarRig VarType = "rig"
+)
+
+// varTypeAliases maps alternate spellings seen in formulas to their type.
+var varTypeAliases = map[string]VarType{
+	"":        VarString,
+	"number":  VarInt,
+	"integer": VarInt,
+	"boolean": VarBool,
+	"bead":    VarBeadID,
+}
+
+var (
+	beadIDPattern  = regexp.MustCompile(`^[A-Za-z0-9]+-[A-Za-z0-9._-]+$`)
+	rigNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// Param is a declared formula variable or input, normalized so callers
+// don't need to care which table it came from.
+type Param struct {
+	Name           string
+	Description    string
+	Type           VarType
+	Required       bool
+	RequiredUnless []string
+	Default        string
+	Enum           []string
+	Pattern        string
+}
+
+// IsValid returns true if the variable type is recognized.
+func (t VarType) IsValid() bool {
+	switch t {
+	case VarString, VarInt, VarBool, VarEnum, VarPath, VarBeadID, VarRig:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseVarType normalizes a type string from a formula file.
+func parseVarType(s string) VarType {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if t, ok := varTypeAliases[s]; ok {
+		return t
+	}
+	return VarType(s)
+}
+
+// Params returns the formula's declared vars and inputs, sorted by name.
+// A param with enum values but no type is treated as an enum.
+func (f *Formula) Params() []Param {
+	params := make([]Param, 0, len(f.Vars)+len(f.Inputs))
+	for name, v := range f.Vars {
+		params = append(params, newParam(name, v.Description, v.Type, v.Required, v.RequiredUnless, v.Default, v.Enum, v.Pattern))
+	}
+	for name, in := range f.Inputs {
+		params = append(params, newParam(name, in.Description, in.Type, in.Required, in.RequiredUnless, in.Default, in.Enum, in.Pattern))
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params
+}
+
+func newParam(name, desc, typ string, required bool, unless []string, def string, enum []string, pattern string) Param {
+	t := parseVarType(typ)
+	if typ == "" && len(enum) > 0 {
+		t = VarEnum
+	}
+	return Param{
+		Name:           name,
+		Description:    desc,
+		Type:           t,
+		Required:       required,
+		RequiredUnless: unless,
+		Default:        def,
+		Enum:           enum,
+		Pattern:        pattern,
+	}
+}
+
+// GetParam returns a declared var or input by name, or nil if not found.
+func (f *Formula) GetParam(name string) *Param {
+	for _, p := range f.Params() {
+		if p.Name == name {
+			return &p
+		}
+	}
+	return nil
+}
+
+// IsRequired reports whether the param must be supplied given the other
+// values. Params with a default are never required; required_unless makes a
+// param required only while none of the listed params has a value.
+func (p Param) IsRequired(values map[string]string) bool {
+	if p.Default != "" {
+		return false
+	}
+	if len(p.RequiredUnless) > 0 {
+		for _, other := range p.RequiredUnless {
+			if values[other] != "" {
+				return false
+			}
+		}
+		return true
+	}
+	return p.Required
+}
+
+// Placeholder returns a short hint for the expected value, e.g. "<int>" or
+// "<fast|thorough>".
+func (p Param) Placeholder() string {
+	if p.Type == VarEnum {
+		return "<" + strings.Join(p.Enum, "|") + ">"
+	}
+	return "<" + string(p.Type) + ">"
+}
+
+// Check validates a single value against the param's type, enum and pattern.
+// isRig is consulted for rig params when non-nil.
+func (p Param) Check(value string, isRig func(string) bool) error {
+	switch p.Type {
+	case VarInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s: %q is not an integer", p.Name, value)
+		}
+	case VarBool:
+		if _, err := parseBool(value); err != nil {
+			return fmt.Errorf("%s: %q is not a boolean (use true or false)", p.Name, value)
+		}
+	case VarEnum:
+		found := false
+		for _, allowed := range p.Enum {
+			if value == allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %q is not one of %s", p.Name, value, strings.Join(p.Enum, ", "))
+		}
+	case VarPath:
+		if value == "" || strings.ContainsAny(value, "\x00\r\n") {
+			return fmt.Errorf("%s: %q is not a valid path", p.Name, value)
+		}
+	case VarBeadID:
+		if !beadIDPattern.MatchString(value) {
+			return fmt.Errorf("%s: %q is not a bead ID (e.g. gt-abc12)", p.Name, value)
+		}
+	case VarRig:
+		if !rigNamePattern.MatchString(value) {
+			return fmt.Errorf("%s: %q is not a valid rig name", p.Name, value)
+		}
+		if isRig != nil && !isRig(value) {
+			return fmt.Errorf("%s: rig %q not found", p.Name, value)
+		}
+	}
+
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %w", p.Name, p.Pattern, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("%s: %q does not match pattern %s", p.Name, value, p.Pattern)
+		}
+	}
+	return nil
+}
+
+// parseBool accepts the spellings strconv.ParseBool does, plus yes/no.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// MissingVars returns the params that are required given values but have
+// no value, in name order.
+func (f *Formula) MissingVars(values map[string]string) []Param {
+	var missing []Param
+	for _, p := range f.Params() {
+		if values[p.Name] == "" && p.IsRequired(values) {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// VarsError lists every problem found when validating formula variables.
+type VarsError struct {
+	Formula  string
+	Problems []string
+}
+
+func (e *VarsError) Error() string {
+	return fmt.Sprintf("invalid variables for formula %s:\n  %s", e.Formula, strings.Join(e.Problems, "\n  "))
+}
+
+// ValidateVars checks supplied values against the formula's declared vars
+// and inputs: unknown names, type/enum/pattern violations and missing
+// required values are all reported together in a *VarsError.
+// isRig is used to check rig-typed values and may be nil.
+func (f *Formula) ValidateVars(values map[string]string, isRig func(string) bool) error {
+	var problems []string
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := f.GetParam(name)
+		if p == nil {
+			problems = append(problems, fmt.Sprintf("%s: unknown variable", name))
+			continue
+		}
+		if err := p.Check(values[name], isRig); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	for _, p := range f.MissingVars(values) {
+		if len(p.RequiredUnless) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: required unless one of %s is set", p.Name, strings.Join(p.RequiredUnless, ", ")))
+		} else {
+			problems = append(problems, fmt.Sprintf("%s: required", p.Name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &VarsError{Formula: f.Name, Problems: problems}
+	}
+	return nil
+}
+
+// validateParams checks var and input declarations at parse time.
+func (f *Formula) validateParams() error {
+	for name := range f.Vars {
+		if _, ok := f.Inputs[name]; ok {
+			return fmt.Errorf("%q is declared in both vars and inputs", name)
+		}
+	}
+
+	params := f.Params()
+	known := make(map[string]bool, len(params))
+	for _, p := range params {
+		known[p.Name] = true
+	}
+
+	for _, p := range params {
+		if !p.Type.IsValid() {
+			return fmt.Errorf("variable %q has invalid type %q (must be string, int, bool, enum, path, bead-id, or rig)", p.Name, p.Type)
+		}
+		if p.Type == VarEnum && len(p.Enum) == 0 {
+			return fmt.Errorf("enum variable %q requires enum values", p.Name)
+		}
+		if p.Type != VarEnum && len(p.Enum) > 0 {
+			return fmt.Errorf("variable %q has enum values but type %q", p.Name, p.Type)
+		}
+		if p.Pattern != "" {
+			if _, err := regexp.Compile(p.Pattern); err != nil {
+				return fmt.Errorf("variable %q has invalid pattern: %w", p.Name, err)
+			}
+		}
+		for _, other := range p.RequiredUnless {
+			if !known[other] {
+				return fmt.Errorf("variable %q required_unless references unknown variable: %s", p.Name, other)
+			}
+		}
+		if p.Default != "" {
+			if err := p.Check(p.Default, nil); err != nil {
+				return fmt.Errorf("invalid default: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package formula
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const typedVarsFormula = `
+formula = "typed"
+type = "workflow"
+
+[[steps]]
+id = "only"
+title = "Only step"
+
+[vars.count]
+type = "int"
+required = true
+
+[vars.depth]
+type = "enum"
+enum = ["quick", "thorough"]
+default = "quick"
+
+[vars.version]
+pattern = '^\d+\.\d+\.\d+$'
+
+[vars.issue]
+type = "bead-id"
+
+[vars.target]
+type = "rig"
+
+[vars.verbose]
+type = "bool"
+
+[inputs.pr]
+type = "number"
+required_unless = ["branch"]
+
+[inputs.branch]
+type = "string"
+required_unless = ["pr"]
+`
+
+func TestParams(t *testing.T) {
+	f, err := Parse([]byte(typedVarsFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	params := f.Params()
+	if len(params) != 8 || params[0].Name != "branch" || params[len(params)-1].Name != "version" {
+		t.Fatalf("Params not merged and sorted: %+v", params)
+	}
+	if p := f.GetParam("pr"); p == nil || p.Type != VarInt {
+		t.Errorf("pr param = %+v, want type int (number alias)", p)
+	}
+	if p := f.GetParam("version"); p == nil || p.Type != VarString {
+		t.Errorf("version param = %+v, want default type string", p)
+	}
+	if got := f.GetParam("depth").Placeholder(); got != "<quick|thorough>" {
+		t.Errorf("enum placeholder = %q", got)
+	}
+}
+
+func TestValidateVars(t *testing.T) {
+	f, err := Parse([]byte(typedVarsFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	isRig := func(name string) bool { return name == "gastown" }
+
+	valid := map[string]string{
+		"count":   "3",
+		"depth":   "thorough",
+		"version": "1.2.3",
+		"issue":   "gt-abc12",
+		"target":  "gastown",
+		"verbose": "yes",
+		"pr":      "42",
+	}
+	if err := f.ValidateVars(valid, isRig); err != nil {
+		t.Errorf("ValidateVars(valid) = %v", err)
+	}
+
+	err = f.ValidateVars(map[string]string{
+		"count":   "three",
+		"depth":   "deep",
+		"version": "v1",
+		"issue":   "not a bead",
+		"target":  "nowhere",
+		"verbose": "maybe",
+		"colour":  "red",
+	}, isRig)
+	var varsErr *VarsError
+	if !errors.As(err, &varsErr) {
+		t.Fatalf("ValidateVars(invalid) = %v, want *VarsError", err)
+	}
+	want := []string{
+		`colour: unknown variable`,
+		`count: "three" is not an integer`,
+		`depth: "deep" is not one of quick, thorough`,
+		`issue: "not a bead" is not a bead ID`,
+		`target: rig "nowhere" not found`,
+		`verbose: "maybe" is not a boolean`,
+		`version: "v1" does not match pattern`,
+		`branch: required unless one of pr is set`,
+		`pr: required unless one of branch is set`,
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("error missing %q:\n%v", w, err)
+		}
+	}
+}
+
+func TestMissingVars(t *testing.T) {
+	f, err := Parse([]byte(typedVarsFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var names []string
+	for _, p := range f.MissingVars(map[string]string{}) {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "branch,count,pr" {
+		t.Errorf("MissingVars(empty) = %s, want branch,count,pr", got)
+	}
+
+	// Setting either side of required_unless satisfies both
+	if missing := f.MissingVars(map[string]string{"count": "1", "branch": "main"}); len(missing) != 0 {
+		t.Errorf("MissingVars = %+v, want none", missing)
+	}
+}
+
+func TestValidate_BadVarDeclarations(t *testing.T) {
+	tests := []struct {
+		name string
+		vars string
+		want string
+	}{
+		{"unknown type", "[vars.x]\ntype = \"float\"", `invalid type "float"`},
+		{"enum without values", "[vars.x]\ntype = \"enum\"", "requires enum values"},
+		{"enum values on int", "[vars.x]\ntype = \"int\"\nenum = [\"1\"]", "has enum values"},
+		{"bad pattern", "[vars.x]\npattern = \"[\"", "invalid pattern"},
+		{"bad default", "[vars.x]\ntype = \"int\"\ndefault = \"many\"", "invalid default"},
+		{"unknown required_unless", "[vars.x]\nrequired_unless = [\"y\"]", "unknown variable: y"},
+		{"vars and inputs clash", "[vars.x]\n[inputs.x]", "both vars and inputs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "formula = \"bad\"\n[[steps]]\nid = \"a\"\n" + tt.vars + "\n"
+			_, err := Parse([]byte(data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
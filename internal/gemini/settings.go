@@ -0,0 +1,71 @@
+// Package gemini provides Gemini CLI configuration management.
+package gemini
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/claude"
+)
+
+//go:embed config/*.json
+var configFS embed.FS
+
+// Default settings location, relative to the agent's working directory.
+const (
+	SettingsDir  = ".gemini"
+	SettingsFile = "settings.json"
+)
+
+// EnsureSettingsAt ensures a Gemini CLI settings file exists at a custom
+// directory/file. The template wires Gas Town's role hooks into Gemini's
+// hook events (SessionStart, BeforeAgent, BeforeTool, PreCompress), allows
+// the gt/bd/git shell commands and adds CLAUDE.md and AGENTS.md to the
+// context files so existing role instructions are picked up.
+// If the file already exists, it's left unchanged.
+func EnsureSettingsAt(workDir string, roleType claude.RoleType, settingsDir, settingsFile string) error {
+	if settingsDir == "" || settingsFile == "" {
+		return nil
+	}
+
+	settingsPath := filepath.Join(workDir, settingsDir, settingsFile)
+	if _, err := os.Stat(settingsPath); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		return fmt.Errorf("creating settings directory: %w", err)
+	}
+
+	content, err := Template(roleType)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(settingsPath, content, 0600); err != nil {
+		return fmt.Errorf("writing settings: %w", err)
+	}
+
+	return nil
+}
+
+// EnsureSettingsForRoleAt is a convenience function that combines
+// claude.RoleTypeFor and EnsureSettingsAt.
+func EnsureSettingsForRoleAt(workDir, role, settingsDir, settingsFile string) error {
+	return EnsureSettingsAt(workDir, claude.RoleTypeFor(role), settingsDir, settingsFile)
+}
+
+// Template returns the settings template for a role type.
+func Template(roleType claude.RoleType) ([]byte, error) {
+	templateName := "config/settings-interactive.json"
+	if roleType == claude.Autonomous {
+		templateName = "config/settings-autonomous.json"
+	}
+	content, err := configFS.ReadFile(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("reading template %s: %w", templateName, err)
+	}
+	return content, nil
+}
@@ -781,11 +781,80 @@ func (g *Git) ResetBranch(name, ref string) error {
 	return err
 }
 
+// Revert commits the inverse of ref on the current branch, with git's
+// default "Revert ..." message. Merge commits are reverted to their first
+// parent. A revert that does not apply cleanly is aborted.
+func (g *Git) Revert(ref string) error {
+	args := []string{"revert", "--no-edit"}
+	if out, err := g.run("rev-list", "--parents", "-n", "1", ref); err == nil && len(strings.Fields(out)) > 2 {
+		args = append(args, "-m", "1")
+	}
+	if _, err := g.run(append(args, ref)...); err != nil {
+		_, _ = g.run("revert", "--abort")
+		return err
+	}
+	return nil
+}
+
+// ResetHard resets the current branch, index and working tree to ref.
+func (g *Git) ResetHard(ref string) error {
+	_, err := g.run("reset", "--hard", ref)
+	return err
+}
+
 // Rev returns the commit hash for the given ref.
 func (g *Git) Rev(ref string) (string, error) {
 	return g.run("rev-parse", ref)
 }
 
+// LsRemoteTags returns the tag names of a remote repository.
+func (g *Git) LsRemoteTags(url string) ([]string, error) {
+	out, err := g.run("ls-remote", "--tags", "--refs", url)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			tags = append(tags, strings.TrimPrefix(fields[1], "refs/tags/"))
+		}
+	}
+	return tags, nil
+}
+
+// LsRemoteRef returns the commit hash of a ref (HEAD, a branch or a tag)
+// in a remote repository.
+func (g *Git) LsRemoteRef(url, ref string) (string, error) {
+	out, err := g.run("ls-remote", url, ref)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("remote %s has no ref %s", url, ref)
+	}
+	return fields[0], nil
+}
+
+// IsTag reports whether name is a tag in the repository.
+func (g *Git) IsTag(name string) bool {
+	_, err := g.run("rev-parse", "--verify", "--quiet", "refs/tags/"+name)
+	return err == nil
+}
+
+// VerifyTag checks the GPG/SSH signature of a tag.
+func (g *Git) VerifyTag(tag string) error {
+	_, err := g.run("verify-tag", tag)
+	return err
+}
+
+// VerifyCommit checks the GPG/SSH signature of a commit.
+func (g *Git) VerifyCommit(ref string) error {
+	_, err := g.run("verify-commit", ref)
+	return err
+}
+
 // IsAncestor checks if ancestor is an ancestor of descendant.
 func (g *Git) IsAncestor(ancestor, descendant string) (bool, error) {
 	_, err := g.run("merge-base", "--is-ancestor", ancestor, descendant)
@@ -243,6 +243,46 @@ func TestRev(t *testing.T) {
 	}
 }
 
+func TestLsRemoteTagsAndRef(t *testing.T) {
+	dir := initTestRepo(t)
+	for _, tag := range []string{"v1.0.0", "v1.2.0"} {
+		cmd := exec.Command("git", "tag", tag)
+		cmd.Dir = dir
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("git tag: %v", err)
+		}
+	}
+
+	g := NewGit("")
+	tags, err := g.LsRemoteTags(dir)
+	if err != nil {
+		t.Fatalf("LsRemoteTags: %v", err)
+	}
+	if strings.Join(tags, ",") != "v1.0.0,v1.2.0" {
+		t.Errorf("tags = %v", tags)
+	}
+
+	head, err := g.LsRemoteRef(dir, "HEAD")
+	if err != nil {
+		t.Fatalf("LsRemoteRef: %v", err)
+	}
+	if rev, _ := NewGit(dir).Rev("HEAD"); head != rev {
+		t.Errorf("LsRemoteRef(HEAD) = %s, want %s", head, rev)
+	}
+	if _, err := g.LsRemoteRef(dir, "no-such-branch"); err == nil {
+		t.Error("LsRemoteRef succeeded for a missing ref")
+	}
+
+	local := NewGit(dir)
+	if !local.IsTag("v1.0.0") || local.IsTag("main") {
+		t.Error("IsTag wrong")
+	}
+	// Lightweight tags carry no signature
+	if err := local.VerifyTag("v1.0.0"); err == nil {
+		t.Error("VerifyTag succeeded on unsigned tag")
+	}
+}
+
 func TestFetchBranch(t *testing.T) {
 	// Create a "remote" repo
 	remoteDir := t.TempDir()
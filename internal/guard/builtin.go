@@ -0,0 +1,190 @@
+package guard
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/secrets"
+)
+
+// Checker is a built-in predicate for conditions that a regex cannot express.
+// It returns whether the input matches and a detail string for the message.
+type Checker func(in *Input) (bool, string)
+
+// Checkers are the built-in checks available to rules via `check = "..."`.
+var Checkers = map[string]Checker{
+	"rm-outside-worktree": checkRmOutsideWorktree,
+	"staged-secrets":      checkStagedSecrets,
+}
+
+// BuiltinRules returns the default guard rules shipped with gt.
+func BuiltinRules() []Rule {
+	return []Rule{
+		{
+			Name:        "pr-workflow",
+			Description: "Block PR creation and feature branches",
+			Tools:       []string{"Bash"},
+			Command:     `\bgh\s+pr\s+create\b|\bgit\s+(checkout\s+-b|switch\s+-c)\b`,
+			Action:      ActionDeny,
+			Message: "Gas Town workers push directly to main. PRs are forbidden.\n" +
+				"Instead of: gh pr create / git checkout -b / git switch -c\n" +
+				"Do this:    git add . && git commit && git push origin main",
+		},
+		{
+			Name:        "force-push-main",
+			Description: "Block force-pushes to main or master",
+			Tools:       []string{"Bash"},
+			Command: `\bgit\s+push\b[^;&|]*(` +
+				`\s(--force|--force-with-lease|-f)\b[^;&|]*\b(main|master)\b|` +
+				`\b(main|master)\b[^;&|]*\s(--force|--force-with-lease|-f)\b|` +
+				`\s\+(main|master)\b)`,
+			Action:  ActionDeny,
+			Message: "Force-pushing to main rewrites everyone's history. Push a new commit instead.",
+		},
+		{
+			Name:        "rm-rf-outside-worktree",
+			Description: "Block recursive deletes outside the agent's worktree",
+			Tools:       []string{"Bash"},
+			Command:     `\brm\s`,
+			Check:       "rm-outside-worktree",
+			Action:      ActionDeny,
+			Message:     "Recursive delete outside your worktree is not allowed.",
+		},
+		{
+			Name:        "beads-hand-edit",
+			Description: "Block editing .beads/ files directly",
+			Tools:       []string{"Edit", "Write", "MultiEdit", "NotebookEdit"},
+			Paths:       []string{"**/.beads/**"},
+			Action:      ActionDeny,
+			Message:     "Don't edit .beads/ by hand - use bd commands (bd update, bd close, ...).",
+		},
+		{
+			Name:        "beads-hand-edit-shell",
+			Description: "Block shell writes into .beads/",
+			Tools:       []string{"Bash"},
+			Command:     `(>>?|\btee\b|\bsed\s+-i\b|\bmv\b|\bcp\b)[^;&|]*\.beads/`,
+			Action:      ActionDeny,
+			Message:     "Don't write to .beads/ from the shell - use bd commands (bd update, bd close, ...).",
+		},
+		{
+			Name:        "secrets-in-commit",
+			Description: "Block commits that add credentials or tokens",
+			Tools:       []string{"Bash"},
+			Command:     `\bgit\s+commit\b`,
+			Check:       "staged-secrets",
+			Action:      ActionDeny,
+			Message:     "The commit would add what looks like a secret. Remove it (or unstage the file) and retry.",
+		},
+	}
+}
+
+// rmPattern captures the arguments of each rm invocation in a command line.
+var rmPattern = regexp.MustCompile(`(?:^|[;&|(]\s*|\s)rm\s+([^;&|)]*)`)
+
+// checkRmOutsideWorktree matches recursive rm invocations whose targets
+// resolve outside the agent's worktree (the system temp dir is also allowed
+// as scratch space). Targets containing variables other than $HOME can't be
+// resolved and are treated as outside (fail closed).
+func checkRmOutsideWorktree(in *Input) (bool, string) {
+	cwd := in.Cwd
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	root := worktreeRoot(cwd)
+	home, _ := os.UserHomeDir()
+
+	for _, m := range rmPattern.FindAllStringSubmatch(in.Command(), -1) {
+		recursive := false
+		var targets []string
+		for _, arg := range strings.Fields(m[1]) {
+			switch {
+			case arg == "--recursive":
+				recursive = true
+			case strings.HasPrefix(arg, "--"):
+			case strings.HasPrefix(arg, "-"):
+				if strings.ContainsAny(arg, "rR") {
+					recursive = true
+				}
+			default:
+				targets = append(targets, strings.Trim(arg, `"'`))
+			}
+		}
+		if !recursive {
+			continue
+		}
+		for _, t := range targets {
+			resolved := resolveTarget(t, cwd, home)
+			if !isInside(resolved, root) && !isInside(resolved, os.TempDir()) {
+				return true, t
+			}
+		}
+	}
+	return false, ""
+}
+
+// resolveTarget expands ~ and $HOME and makes a path absolute.
+// Returns "" for targets that cannot be resolved statically.
+func resolveTarget(target, cwd, home string) string {
+	switch {
+	case target == "~" || target == "$HOME" || target == "${HOME}":
+		return home
+	case strings.HasPrefix(target, "~/"):
+		target = filepath.Join(home, target[2:])
+	case strings.HasPrefix(target, "$HOME/"):
+		target = filepath.Join(home, target[len("$HOME/"):])
+	case strings.HasPrefix(target, "${HOME}/"):
+		target = filepath.Join(home, target[len("${HOME}/"):])
+	}
+	if strings.Contains(target, "$") || strings.Contains(target, "`") {
+		return ""
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(cwd, target)
+	}
+	return filepath.Clean(target)
+}
+
+// isInside reports whether path is root or below it.
+func isInside(path, root string) bool {
+	if path == "" || root == "" {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// worktreeRoot returns the git toplevel containing dir, or dir itself.
+func worktreeRoot(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return dir
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commitAllPattern detects `git commit -a` (including combined flags like -am).
+var commitAllPattern = regexp.MustCompile(`\bgit\s+commit\b[^;&|]*\s-[a-zA-Z]*a`)
+
+// checkStagedSecrets matches git commits whose staged changes add a line
+// that looks like a credential (see package secrets).
+func checkStagedSecrets(in *Input) (bool, string) {
+	cwd := in.Cwd
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+
+	// commit -a stages tracked changes at commit time
+	findings, err := secrets.ScanStaged(cwd, commitAllPattern.MatchString(in.Command()))
+	if err != nil || len(findings) == 0 {
+		return false, ""
+	}
+	return true, findings[0].String()
+}
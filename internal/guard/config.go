@@ -0,0 +1,127 @@
+package guard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigFile is the guard rules file name inside a settings directory.
+const ConfigFile = "guards.toml"
+
+// Rule sources, recorded on each loaded rule.
+const (
+	SourceBuiltin = "builtin"
+	SourceTown    = "town"
+	SourceRig     = "rig"
+)
+
+// File is the on-disk guard rules format.
+//
+// Example settings/guards.toml:
+//
+//	[[rule]]
+//	name = "no-npm-publish"
+//	tools = ["Bash"]
+//	command = '\bnpm\s+publish\b'
+//	action = "deny"
+//	message = "Publishing is done by the release pipeline."
+//
+//	[[rule]]
+//	name = "pr-workflow"   # override a built-in by name
+//	disabled = true
+type File struct {
+	Rules []Rule `toml:"rule"`
+}
+
+// TownConfigPath returns the town-level guard rules path.
+func TownConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", ConfigFile)
+}
+
+// RigConfigPath returns the rig-level guard rules path.
+func RigConfigPath(rigPath string) string {
+	return filepath.Join(rigPath, "settings", ConfigFile)
+}
+
+// LoadFile parses a guard rules file. Returns nil, nil if it does not exist.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var f File
+	if err := toml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Load returns the effective, compiled rule list for a town and optional rig.
+// rigName may be empty for town-level agents.
+func Load(townRoot, rigName string) ([]*Rule, error) {
+	var layers [][]Rule
+
+	if townRoot != "" && rigName != "" {
+		f, err := LoadFile(RigConfigPath(filepath.Join(townRoot, rigName)))
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			layers = append(layers, withSource(f.Rules, SourceRig))
+		}
+	}
+	if townRoot != "" {
+		f, err := LoadFile(TownConfigPath(townRoot))
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			layers = append(layers, withSource(f.Rules, SourceTown))
+		}
+	}
+	layers = append(layers, withSource(BuiltinRules(), SourceBuiltin))
+
+	return merge(layers...)
+}
+
+// merge flattens rule layers in priority order. The first occurrence of a
+// name wins; disabled rules suppress the name without contributing a rule.
+func merge(layers ...[]Rule) ([]*Rule, error) {
+	seen := make(map[string]bool)
+	var rules []*Rule
+	for _, layer := range layers {
+		for i := range layer {
+			r := layer[i]
+			if r.Name == "" {
+				return nil, fmt.Errorf("%s guard rule missing name", r.Source)
+			}
+			if seen[r.Name] {
+				continue
+			}
+			seen[r.Name] = true
+			if r.Disabled {
+				continue
+			}
+			if err := r.compile(); err != nil {
+				return nil, fmt.Errorf("%s guard: %w", r.Source, err)
+			}
+			rules = append(rules, &r)
+		}
+	}
+	return rules, nil
+}
+
+func withSource(rules []Rule, source string) []Rule {
+	out := make([]Rule, len(rules))
+	for i, r := range rules {
+		r.Source = source
+		out[i] = r
+	}
+	return out
+}
@@ -0,0 +1,284 @@
+// Package guard implements the declarative policy engine behind `gt tap guard`.
+//
+// Guards run as PreToolUse hooks: the agent runtime pipes a JSON description
+// of the tool call on stdin, the engine evaluates it against an ordered list
+// of rules, and the hook exits with code 2 to block the call.
+//
+// Rules come from three layers, highest priority first:
+//  1. Rig overrides (<rig>/settings/guards.toml)
+//  2. Town rules (<town>/settings/guards.toml)
+//  3. Built-in rules (compiled into gt)
+//
+// A rule in a higher layer replaces any lower-layer rule with the same name.
+package guard
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Rule actions.
+const (
+	ActionAllow = "allow"
+	ActionDeny  = "deny"
+	ActionWarn  = "warn"
+)
+
+// Rule is a single guard policy.
+type Rule struct {
+	// Name uniquely identifies the rule. Overrides match on name.
+	Name string `toml:"name"`
+
+	// Description explains what the rule protects against.
+	Description string `toml:"description,omitempty"`
+
+	// Tools restricts the rule to these tool names (e.g., "Bash", "Edit").
+	// Empty matches any tool.
+	Tools []string `toml:"tools,omitempty"`
+
+	// Command is a regular expression matched against Bash commands.
+	// Empty matches any command.
+	Command string `toml:"command,omitempty"`
+
+	// Paths are glob patterns matched against the file path of file tools
+	// (Edit, Write, ...). "**" matches any number of directories.
+	// Empty matches any path.
+	Paths []string `toml:"paths,omitempty"`
+
+	// Roles restricts the rule to these Gas Town roles (e.g., "polecat").
+	// Empty matches every role.
+	Roles []string `toml:"roles,omitempty"`
+
+	// Check names a built-in checker that must also match (see Checkers).
+	Check string `toml:"check,omitempty"`
+
+	// Action is "allow", "deny" or "warn".
+	Action string `toml:"action"`
+
+	// Message is shown to the agent when the rule fires.
+	Message string `toml:"message,omitempty"`
+
+	// Disabled turns off a lower-layer rule with the same name.
+	Disabled bool `toml:"disabled,omitempty"`
+
+	// Source records which layer the rule came from (not configurable).
+	Source string `toml:"-"`
+
+	commandRe *regexp.Regexp
+	pathRes   []*regexp.Regexp
+}
+
+// Input is the tool call being evaluated, as sent by PreToolUse hooks.
+type Input struct {
+	ToolName  string                 `json:"tool_name"`
+	ToolInput map[string]interface{} `json:"tool_input"`
+	Cwd       string                 `json:"cwd"`
+}
+
+// toolAliases maps other runtimes' tool names to the Claude Code names
+// rules are written against.
+var toolAliases = map[string]string{
+	// Gemini CLI (BeforeTool hook)
+	"run_shell_command": "Bash",
+	"write_file":        "Write",
+	"replace":           "Edit",
+}
+
+// ParseInput parses a PreToolUse hook payload. Tool names from other
+// runtimes are normalized to their Claude Code equivalents.
+func ParseInput(data []byte) (*Input, error) {
+	var in Input
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, fmt.Errorf("parsing hook input: %w", err)
+	}
+	if alias, ok := toolAliases[in.ToolName]; ok {
+		in.ToolName = alias
+	}
+	return &in, nil
+}
+
+// Command returns the shell command of a Bash tool call.
+func (in *Input) Command() string {
+	s, _ := in.ToolInput["command"].(string)
+	return s
+}
+
+// FilePath returns the target path of a file tool call.
+func (in *Input) FilePath() string {
+	for _, key := range []string{"file_path", "notebook_path", "path"} {
+		if s, ok := in.ToolInput[key].(string); ok && s != "" {
+			if !filepath.IsAbs(s) && in.Cwd != "" {
+				s = filepath.Join(in.Cwd, s)
+			}
+			return s
+		}
+	}
+	return ""
+}
+
+// Match is a rule that fired for an input.
+type Match struct {
+	Rule   *Rule
+	Detail string // checker-specific detail (e.g., the offending path)
+}
+
+// Result is the outcome of evaluating a tool call.
+type Result struct {
+	// Blocked is the deny rule that blocked the call, or nil if allowed.
+	Blocked *Match
+
+	// Warnings are warn rules that fired.
+	Warnings []Match
+}
+
+// compile prepares a rule's regular expressions.
+func (r *Rule) compile() error {
+	switch r.Action {
+	case ActionAllow, ActionDeny, ActionWarn:
+	default:
+		return fmt.Errorf("rule %q: invalid action %q (want allow, deny or warn)", r.Name, r.Action)
+	}
+	if r.Check != "" {
+		if _, ok := Checkers[r.Check]; !ok {
+			return fmt.Errorf("rule %q: unknown check %q", r.Name, r.Check)
+		}
+	}
+	if r.Command != "" {
+		re, err := regexp.Compile(r.Command)
+		if err != nil {
+			return fmt.Errorf("rule %q: invalid command regex: %w", r.Name, err)
+		}
+		r.commandRe = re
+	}
+	r.pathRes = nil
+	for _, p := range r.Paths {
+		re, err := globToRegexp(p)
+		if err != nil {
+			return fmt.Errorf("rule %q: invalid path glob %q: %w", r.Name, p, err)
+		}
+		r.pathRes = append(r.pathRes, re)
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to the input, returning
+// checker detail when it does.
+func (r *Rule) matches(in *Input, role string) (bool, string) {
+	if len(r.Tools) > 0 && !containsFold(r.Tools, in.ToolName) {
+		return false, ""
+	}
+	if len(r.Roles) > 0 && !containsFold(r.Roles, role) {
+		return false, ""
+	}
+	if r.commandRe != nil {
+		cmd := in.Command()
+		if cmd == "" || !r.commandRe.MatchString(cmd) {
+			return false, ""
+		}
+	}
+	if len(r.pathRes) > 0 {
+		path := in.FilePath()
+		if path == "" || !matchAny(r.pathRes, path) {
+			return false, ""
+		}
+	}
+	if r.Check != "" {
+		return Checkers[r.Check](in)
+	}
+	return true, ""
+}
+
+// Evaluate runs rules in order against the input. The first matching allow
+// or deny rule decides the outcome; warn rules are collected and evaluation
+// continues past them.
+func Evaluate(rules []*Rule, in *Input, role string) Result {
+	var result Result
+	for _, r := range rules {
+		ok, detail := r.matches(in, role)
+		if !ok {
+			continue
+		}
+		switch r.Action {
+		case ActionWarn:
+			result.Warnings = append(result.Warnings, Match{Rule: r, Detail: detail})
+		case ActionDeny:
+			result.Blocked = &Match{Rule: r, Detail: detail}
+			return result
+		case ActionAllow:
+			return result
+		}
+	}
+	return result
+}
+
+// RoleFromEnv derives the role type from a GT_ROLE value, which may be a
+// bare role ("polecat") or a full address ("gastown/polecats/Toast").
+func RoleFromEnv(gtRole string) string {
+	if !strings.Contains(gtRole, "/") {
+		return gtRole
+	}
+	parts := strings.Split(gtRole, "/")
+	switch {
+	case len(parts) >= 3 && parts[1] == "polecats":
+		return "polecat"
+	case len(parts) >= 3 && parts[1] == "crew":
+		return "crew"
+	default:
+		return parts[len(parts)-1]
+	}
+}
+
+// globToRegexp converts a path glob to an anchored regular expression.
+// "**" matches across directories, "*" and "?" stay within one segment.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !strings.HasPrefix(glob, "/") && !strings.HasPrefix(glob, "**") {
+		// Relative globs match at any depth
+		sb.WriteString("(.*/)?")
+	}
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+func matchAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,275 @@
+// Package handoff stores structured handoff documents.
+//
+// A handoff mail carries free text for the successor session. Alongside it,
+// `gt handoff` writes a Document: the same context as machine-readable
+// fields (hooked work, molecule step, files touched, decisions, open
+// questions, blockers, commands to resume). `gt prime` renders the latest
+// unconsumed document for the successor, and `gt handoff history` lists
+// past ones.
+//
+// Documents live under the town's runtime directory, one directory per
+// agent identity:
+//
+//	<town>/.runtime/handoffs/<identity>/<timestamp>.json
+package handoff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// SchemaVersion is the current Document schema version.
+const SchemaVersion = 1
+
+// DefaultKeep is how many documents are kept per agent.
+const DefaultKeep = 20
+
+// fileTimeFormat sorts lexically in time order.
+const fileTimeFormat = "20060102T150405.000Z"
+
+// Document is a structured handoff from one session to its successor.
+type Document struct {
+	Version int `json:"version"`
+
+	// Agent is the identity that wrote the handoff (e.g. "gastown/crew/max").
+	Agent string `json:"agent"`
+
+	// Session is the tmux session that handed off.
+	Session string `json:"session,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// MailID is the handoff mail bead sent with this document.
+	MailID string `json:"mail_id,omitempty"`
+
+	// Subject and Summary mirror the handoff mail.
+	Subject string `json:"subject,omitempty"`
+	Summary string `json:"summary,omitempty"`
+
+	// HookedBead is the work on the agent's hook at handoff time.
+	HookedBead  string `json:"hooked_bead,omitempty"`
+	HookedTitle string `json:"hooked_title,omitempty"`
+
+	// MoleculeID and Step locate the agent in its molecule.
+	MoleculeID string `json:"molecule_id,omitempty"`
+	Step       string `json:"step,omitempty"`
+	StepTitle  string `json:"step_title,omitempty"`
+
+	// InProgress lists other beads the agent had in progress.
+	InProgress []string `json:"in_progress,omitempty"`
+
+	// Branch and FilesTouched describe the working tree.
+	Branch       string   `json:"branch,omitempty"`
+	FilesTouched []string `json:"files_touched,omitempty"`
+
+	// Free-form context recorded by the agent.
+	Decisions      []string `json:"decisions,omitempty"`
+	OpenQuestions  []string `json:"open_questions,omitempty"`
+	Blockers       []string `json:"blockers,omitempty"`
+	ResumeCommands []string `json:"resume_commands,omitempty"`
+
+	// ConsumedAt is set once a successor session has been shown the
+	// document by gt prime.
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
+
+	// path is where the document was read from or written to.
+	path string
+}
+
+// Path returns the file the document was loaded from or saved to.
+func (d *Document) Path() string {
+	return d.path
+}
+
+// IsEmpty reports whether the document carries no context beyond metadata.
+func (d *Document) IsEmpty() bool {
+	return d.Summary == "" && d.HookedBead == "" && d.MoleculeID == "" &&
+		len(d.InProgress) == 0 && len(d.FilesTouched) == 0 &&
+		len(d.Decisions) == 0 && len(d.OpenQuestions) == 0 &&
+		len(d.Blockers) == 0 && len(d.ResumeCommands) == 0
+}
+
+// Dir returns the directory holding an agent's handoff documents.
+// Identities are normalized so "mayor" and "mayor/" share a directory.
+func Dir(townRoot, agent string) string {
+	key := strings.Trim(agent, "/")
+	return filepath.Join(townRoot, constants.DirRuntime, "handoffs", filepath.FromSlash(key))
+}
+
+// Write saves a document for its agent and prunes all but the newest
+// DefaultKeep documents.
+func Write(townRoot string, doc *Document) error {
+	if strings.Trim(doc.Agent, "/") == "" {
+		return fmt.Errorf("handoff document has no agent")
+	}
+	doc.Version = SchemaVersion
+	if doc.CreatedAt.IsZero() {
+		doc.CreatedAt = time.Now()
+	}
+
+	dir := Dir(townRoot, doc.Agent)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating handoff dir: %w", err)
+	}
+	path := filepath.Join(dir, doc.CreatedAt.UTC().Format(fileTimeFormat)+".json")
+	if err := util.AtomicWriteJSON(path, doc); err != nil {
+		return fmt.Errorf("writing handoff: %w", err)
+	}
+	doc.path = path
+
+	return prune(dir, DefaultKeep)
+}
+
+// History returns an agent's handoff documents, newest first. limit <= 0
+// returns all of them. Unreadable files are skipped.
+func History(townRoot, agent string, limit int) ([]*Document, error) {
+	files, err := listFiles(Dir(townRoot, agent))
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []*Document
+	for _, path := range files {
+		doc, err := Read(path)
+		if err != nil {
+			continue
+		}
+		docs = append(docs, doc)
+		if limit > 0 && len(docs) >= limit {
+			break
+		}
+	}
+	return docs, nil
+}
+
+// Latest returns an agent's newest handoff document, or nil if none exist.
+func Latest(townRoot, agent string) (*Document, error) {
+	docs, err := History(townRoot, agent, 1)
+	if err != nil || len(docs) == 0 {
+		return nil, err
+	}
+	return docs[0], nil
+}
+
+// Read loads a document from a file.
+func Read(path string) (*Document, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the town runtime dir
+	if err != nil {
+		return nil, fmt.Errorf("reading handoff: %w", err)
+	}
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing handoff %s: %w", path, err)
+	}
+	doc.path = path
+	return &doc, nil
+}
+
+// MarkConsumed records that a successor has been shown the document.
+func MarkConsumed(doc *Document) error {
+	if doc.path == "" {
+		return fmt.Errorf("handoff document was not loaded from disk")
+	}
+	now := time.Now()
+	doc.ConsumedAt = &now
+	return util.AtomicWriteJSON(doc.path, doc)
+}
+
+// listFiles returns the document files in dir, newest first.
+func listFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading handoff dir: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files, nil
+}
+
+func prune(dir string, keep int) error {
+	files, err := listFiles(dir)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(files); i++ {
+		_ = os.Remove(files[i])
+	}
+	return nil
+}
+
+// Render formats a document as markdown for an agent to read.
+func Render(doc *Document) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From %s at %s", strings.Trim(doc.Agent, "/"), doc.CreatedAt.Format("2006-01-02 15:04"))
+	if doc.Session != "" {
+		fmt.Fprintf(&b, " (session %s)", doc.Session)
+	}
+	b.WriteString("\n")
+	if doc.MailID != "" {
+		fmt.Fprintf(&b, "Mail: %s\n", doc.MailID)
+	}
+	if doc.Summary != "" {
+		fmt.Fprintf(&b, "\n%s\n", doc.Summary)
+	}
+
+	if doc.HookedBead != "" || doc.MoleculeID != "" || doc.Branch != "" {
+		b.WriteString("\n### Work\n")
+		if doc.HookedBead != "" {
+			if doc.HookedTitle != "" {
+				fmt.Fprintf(&b, "- Hooked: %s: %s\n", doc.HookedBead, doc.HookedTitle)
+			} else {
+				fmt.Fprintf(&b, "- Hooked: %s\n", doc.HookedBead)
+			}
+		}
+		if doc.MoleculeID != "" {
+			step := doc.Step
+			if doc.StepTitle != "" {
+				step += " (" + doc.StepTitle + ")"
+			}
+			fmt.Fprintf(&b, "- Molecule: %s, step %s\n", doc.MoleculeID, step)
+		}
+		if doc.Branch != "" {
+			fmt.Fprintf(&b, "- Branch: %s\n", doc.Branch)
+		}
+	}
+
+	writeList(&b, "In Progress", doc.InProgress)
+	writeList(&b, "Files Touched", doc.FilesTouched)
+	writeList(&b, "Decisions Made", doc.Decisions)
+	writeList(&b, "Open Questions", doc.OpenQuestions)
+	writeList(&b, "Blockers", doc.Blockers)
+	if len(doc.ResumeCommands) > 0 {
+		b.WriteString("\n### To Resume\n")
+		for _, c := range doc.ResumeCommands {
+			fmt.Fprintf(&b, "- `%s`\n", c)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func writeList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+}
@@ -0,0 +1,104 @@
+package handoff
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteHistory(t *testing.T) {
+	town := t.TempDir()
+	base := time.Date(2026, 1, 18, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < DefaultKeep+2; i++ {
+		doc := &Document{
+			Agent:     "mayor/",
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+			Summary:   "handoff",
+		}
+		if err := Write(town, doc); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+	}
+
+	// "mayor" and "mayor/" are the same agent.
+	docs, err := History(town, "mayor", 0)
+	if err != nil {
+		t.Fatalf("History() error: %v", err)
+	}
+	if len(docs) != DefaultKeep {
+		t.Fatalf("History() returned %d docs, want %d after pruning", len(docs), DefaultKeep)
+	}
+	if !docs[0].CreatedAt.After(docs[1].CreatedAt) {
+		t.Error("History() not newest first")
+	}
+	if docs[0].Version != SchemaVersion {
+		t.Errorf("Version = %d, want %d", docs[0].Version, SchemaVersion)
+	}
+
+	if docs, _ := History(town, "mayor", 3); len(docs) != 3 {
+		t.Errorf("History(limit 3) returned %d docs", len(docs))
+	}
+	if docs, _ := History(town, "gastown/crew/max", 0); len(docs) != 0 {
+		t.Errorf("History() of an agent without handoffs = %d docs", len(docs))
+	}
+}
+
+func TestMarkConsumed(t *testing.T) {
+	town := t.TempDir()
+	if err := Write(town, &Document{Agent: "gastown/crew/max", HookedBead: "gt-abc"}); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Latest(town, "gastown/crew/max")
+	if err != nil || doc == nil {
+		t.Fatalf("Latest() = %v, %v", doc, err)
+	}
+	if doc.ConsumedAt != nil {
+		t.Fatal("new document already consumed")
+	}
+	if err := MarkConsumed(doc); err != nil {
+		t.Fatalf("MarkConsumed() error: %v", err)
+	}
+	doc, _ = Latest(town, "gastown/crew/max")
+	if doc.ConsumedAt == nil || doc.HookedBead != "gt-abc" {
+		t.Errorf("after MarkConsumed: %+v", doc)
+	}
+}
+
+func TestWrite_NoAgent(t *testing.T) {
+	if err := Write(t.TempDir(), &Document{Agent: "/"}); err == nil {
+		t.Error("Write() without an agent succeeded")
+	}
+}
+
+func TestRender(t *testing.T) {
+	doc := &Document{
+		Agent:          "gastown/crew/max",
+		CreatedAt:      time.Date(2026, 1, 18, 10, 0, 0, 0, time.UTC),
+		Summary:        "Halfway through the API migration.",
+		HookedBead:     "gt-abc",
+		HookedTitle:    "Migrate API",
+		MoleculeID:     "mol-polecat-work",
+		Step:           "gt-abc.3",
+		Decisions:      []string{"Use the v2 client"},
+		OpenQuestions:  []string{"Keep the shim?"},
+		ResumeCommands: []string{"gt hook"},
+	}
+	out := Render(doc)
+	for _, want := range []string{
+		"From gastown/crew/max at 2026-01-18 10:00",
+		"Halfway through the API migration.",
+		"- Hooked: gt-abc: Migrate API",
+		"- Molecule: mol-polecat-work, step gt-abc.3",
+		"### Decisions Made\n- Use the v2 client",
+		"### Open Questions\n- Keep the shim?",
+		"### To Resume\n- `gt hook`",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Blockers") {
+		t.Errorf("Render() shows an empty section:\n%s", out)
+	}
+}
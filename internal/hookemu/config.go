@@ -0,0 +1,167 @@
+// Package hookemu emulates runtime hooks for agents without a native hook
+// system (Codex, Gemini CLI, Cursor, Auggie, Amp).
+//
+// Claude Code runs Gas Town's hooks itself: `gt prime` on SessionStart, mail
+// injection on UserPromptSubmit and `gt tap guard` on PreToolUse. Other
+// runtimes get the same guarantees from two halves:
+//
+//   - The wrapper (`gt hooks emulate -- <agent>`) records the agent's start
+//     in a state file and puts guard shims for risky commands (git, gh, ...)
+//     first on PATH, so PreToolUse hooks still run before those commands.
+//   - The daemon's Emulator watches the state files and the session panes,
+//     firing SessionStart once the agent settles, Idle when the pane has been
+//     quiet for a while and Stop when the agent process exits. Hook output is
+//     delivered to the agent by nudging its session.
+package hookemu
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Emulated hook events.
+const (
+	EventSessionStart = "SessionStart"
+	EventIdle         = "Idle"
+	EventStop         = "Stop"
+	EventPreToolUse   = "PreToolUse"
+)
+
+// Provider is the RuntimeHooksConfig.Provider value selecting emulation.
+const Provider = "emulated"
+
+// Default location of the hooks file, relative to an agent's working
+// directory (or one of its parents).
+const (
+	DefaultDir  = ".gastown"
+	DefaultFile = "hooks.json"
+)
+
+// DefaultIdleAfter is how long a pane must stay unchanged before Idle fires.
+const DefaultIdleAfter = 60 * time.Second
+
+// Config is the emulated hooks file.
+type Config struct {
+	// Hooks maps an event name to shell commands run in order. A command
+	// that fails stops the chain, like `&&` in Claude hook commands.
+	Hooks map[string][]string `json:"hooks"`
+
+	// IdleAfterSeconds overrides DefaultIdleAfter. Negative disables Idle.
+	IdleAfterSeconds int `json:"idle_after_seconds,omitempty"`
+
+	// GuardCommands lists executables shimmed on PATH so PreToolUse hooks run
+	// before them.
+	GuardCommands []string `json:"guard_commands,omitempty"`
+}
+
+// DefaultConfig returns the hooks matching Gas Town's Claude templates for a
+// role. Autonomous roles get their mail injected at session start; every
+// role gets mail injected when it goes idle, standing in for
+// UserPromptSubmit.
+func DefaultConfig(role string) *Config {
+	start := []string{"gt prime"}
+	if isAutonomous(role) {
+		start = append(start, "gt mail check --inject")
+	}
+	start = append(start, "gt nudge deacon session-started")
+
+	return &Config{
+		Hooks: map[string][]string{
+			EventSessionStart: start,
+			EventIdle:         {"gt mail check --inject"},
+			EventPreToolUse:   {"gt tap guard"},
+		},
+		GuardCommands: []string{"git", "gh", "npm"},
+	}
+}
+
+// IdleAfter returns how long a pane must be quiet before Idle fires, or 0 if
+// Idle is disabled.
+func (c *Config) IdleAfter() time.Duration {
+	switch {
+	case c.IdleAfterSeconds < 0:
+		return 0
+	case c.IdleAfterSeconds > 0:
+		return time.Duration(c.IdleAfterSeconds) * time.Second
+	}
+	return DefaultIdleAfter
+}
+
+// LoadConfig reads a hooks file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from config
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// EnsureConfigAt writes the default hooks file for role to
+// workDir/dir/file. An existing file is left unchanged.
+func EnsureConfigAt(workDir, role, dir, file string) error {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if file == "" {
+		file = DefaultFile
+	}
+	path := filepath.Join(workDir, dir, file)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating hooks directory: %w", err)
+	}
+	data, err := json.MarshalIndent(DefaultConfig(role), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil { //nolint:gosec // G306: hooks file is not sensitive
+		return fmt.Errorf("writing hooks file: %w", err)
+	}
+	return nil
+}
+
+// FindConfig walks up from dir looking for DefaultDir/DefaultFile, the way
+// runtimes find their settings. Returns "" if none is found.
+func FindConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, DefaultDir, DefaultFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ConfigFor loads the hooks file at path, falling back to the role defaults
+// when path is empty or unreadable.
+func ConfigFor(path, role string) *Config {
+	if path != "" {
+		if cfg, err := LoadConfig(path); err == nil {
+			return cfg
+		}
+	}
+	return DefaultConfig(role)
+}
+
+// isAutonomous mirrors runtime's autonomous role list: these roles run
+// without a human prompting them and need mail injected to find work.
+func isAutonomous(role string) bool {
+	switch role {
+	case "polecat", "witness", "refinery", "deacon":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,253 @@
+package hookemu
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timing of the Emulator.
+const (
+	// PollInterval is how often sessions are observed.
+	PollInterval = 2 * time.Second
+
+	// SettleTime is how long a freshly started agent's pane must stay
+	// unchanged before SessionStart fires (the agent is at its prompt).
+	SettleTime = 3 * time.Second
+
+	// StartTimeout fires SessionStart even if the pane never settles.
+	StartTimeout = 30 * time.Second
+
+	// hookTimeout bounds a single hook command.
+	hookTimeout = 2 * time.Minute
+
+	// maxInlineOutput is the longest single-line output nudged verbatim;
+	// longer output is written to a file and the agent is pointed at it.
+	maxInlineOutput = 400
+)
+
+// Session is the slice of a session backend the Emulator needs.
+type Session interface {
+	HasSession(name string) (bool, error)
+	CapturePane(session string, lines int) (string, error)
+	NudgeSession(session, message string) error
+}
+
+// Emulator fires emulated hooks for wrapped agents.
+type Emulator struct {
+	townRoot string
+	sessions func(backend string) Session
+	logger   func(format string, args ...interface{})
+
+	// Overridable for tests.
+	now   func() time.Time
+	alive func(pid int) bool
+	run   func(ctx context.Context, s *State, event, command string) (string, error)
+
+	watches map[string]*watch
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// watch tracks what the Emulator has observed of one agent process.
+type watch struct {
+	startedAt  time.Time
+	paneHash   [32]byte
+	lastChange time.Time
+	idleFired  bool
+}
+
+// NewEmulator creates an Emulator for a town. sessions returns the backend
+// for a State's Backend name.
+func NewEmulator(townRoot string, sessions func(backend string) Session, logger func(format string, args ...interface{})) *Emulator {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Emulator{
+		townRoot: townRoot,
+		sessions: sessions,
+		logger:   logger,
+		now:      time.Now,
+		alive:    processAlive,
+		run:      runHook,
+		watches:  make(map[string]*watch),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins polling in a goroutine.
+func (e *Emulator) Start() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+				e.Tick()
+			}
+		}
+	}()
+}
+
+// Stop halts polling and waits for the current tick to finish.
+func (e *Emulator) Stop() {
+	e.cancel()
+	e.wg.Wait()
+}
+
+// Tick observes every wrapped agent once and fires due hooks.
+func (e *Emulator) Tick() {
+	states, err := ReadStates(e.townRoot)
+	if err != nil {
+		e.logger("hook emulation: reading state: %v", err)
+		return
+	}
+
+	seen := make(map[string]bool, len(states))
+	for _, s := range states {
+		seen[s.Session] = true
+		e.observe(s)
+	}
+	for name := range e.watches {
+		if !seen[name] {
+			delete(e.watches, name)
+		}
+	}
+}
+
+// observe handles one agent: Stop on exit, SessionStart once it settles and
+// Idle once its pane has been quiet long enough.
+func (e *Emulator) observe(s *State) {
+	now := e.now()
+	cfg := ConfigFor(s.Settings, s.Role)
+	sess := e.sessions(s.Backend)
+
+	w := e.watches[s.Session]
+	if w == nil || !w.startedAt.Equal(s.StartedAt) {
+		w = &watch{startedAt: s.StartedAt, lastChange: now}
+		e.watches[s.Session] = w
+	}
+
+	exited := s.ExitedAt != nil || !e.alive(s.PID)
+	if !exited && sess != nil {
+		if has, err := sess.HasSession(s.Session); err == nil && !has {
+			exited = true
+		}
+	}
+	if exited {
+		e.fire(s, cfg, nil, EventStop)
+		if err := RemoveState(e.townRoot, s.Session); err != nil {
+			e.logger("hook emulation: removing state for %s: %v", s.Session, err)
+		}
+		delete(e.watches, s.Session)
+		return
+	}
+	if sess == nil {
+		return
+	}
+
+	pane, err := sess.CapturePane(s.Session, 50)
+	if err != nil {
+		return
+	}
+	if h := sha256.Sum256([]byte(pane)); h != w.paneHash {
+		w.paneHash = h
+		w.lastChange = now
+		w.idleFired = false
+	}
+	quiet := now.Sub(w.lastChange)
+
+	if !s.HasFired(EventSessionStart) {
+		age := now.Sub(s.StartedAt)
+		if (age >= SettleTime && quiet >= SettleTime) || age >= StartTimeout {
+			e.fire(s, cfg, sess, EventSessionStart)
+			s.Fired = append(s.Fired, EventSessionStart)
+			if err := WriteState(e.townRoot, s); err != nil {
+				e.logger("hook emulation: saving state for %s: %v", s.Session, err)
+			}
+			// Give the agent a full idle period after its start hooks.
+			w.lastChange = now
+			w.idleFired = false
+		}
+		return
+	}
+
+	if idle := cfg.IdleAfter(); idle > 0 && !w.idleFired && quiet >= idle {
+		e.fire(s, cfg, sess, EventIdle)
+		w.idleFired = true
+	}
+}
+
+// fire runs an event's hook commands and delivers their output to the
+// session (if sess is non-nil).
+func (e *Emulator) fire(s *State, cfg *Config, sess Session, event string) {
+	commands := cfg.Hooks[event]
+	if len(commands) == 0 {
+		return
+	}
+
+	var outputs []string
+	for _, command := range commands {
+		ctx, cancel := context.WithTimeout(e.ctx, hookTimeout)
+		out, err := e.run(ctx, s, event, command)
+		cancel()
+		if out = strings.TrimSpace(out); out != "" {
+			outputs = append(outputs, out)
+		}
+		if err != nil {
+			e.logger("hook emulation: %s %s hook %q failed: %v", s.Session, event, command, err)
+			break
+		}
+	}
+	e.logger("hook emulation: fired %s for %s", event, s.Session)
+
+	if sess == nil || len(outputs) == 0 {
+		return
+	}
+	if err := e.deliver(s, sess, event, strings.Join(outputs, "\n\n")); err != nil {
+		e.logger("hook emulation: delivering %s output to %s: %v", event, s.Session, err)
+	}
+}
+
+// deliver hands hook output to the agent. Short single-line output is
+// nudged verbatim; anything longer is written to a file the agent is told
+// to read, since multi-line nudges would be submitted line by line.
+func (e *Emulator) deliver(s *State, sess Session, event, output string) error {
+	if !strings.Contains(output, "\n") && len(output) <= maxInlineOutput {
+		return sess.NudgeSession(s.Session, output)
+	}
+	path := filepath.Join(StateDir(e.townRoot), fmt.Sprintf("%s-%s.md", s.Session, event))
+	if err := os.WriteFile(path, []byte(output+"\n"), 0644); err != nil { //nolint:gosec // G306: hook output is not sensitive
+		return err
+	}
+	return sess.NudgeSession(s.Session,
+		fmt.Sprintf("[gt %s hook] Read %s now and follow its instructions.", event, path))
+}
+
+// runHook runs a hook command with the agent's environment in its working
+// directory, returning stdout.
+func runHook(ctx context.Context, s *State, event, command string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = s.WorkDir
+	cmd.Env = append(s.Environ(), "GT_HOOK_EVENT="+event, "GT_HOOKS_EMULATED=1")
+	if exe, err := os.Executable(); err == nil {
+		// Hook commands call gt; make sure they get this one.
+		cmd.Env = append(cmd.Env, "PATH="+filepath.Dir(exe)+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	return stdout.String(), err
+}
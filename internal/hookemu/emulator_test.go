@@ -0,0 +1,281 @@
+package hookemu
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeSession struct {
+	exists bool
+	pane   string
+	nudges []string
+}
+
+func (f *fakeSession) HasSession(string) (bool, error) { return f.exists, nil }
+
+func (f *fakeSession) CapturePane(string, int) (string, error) { return f.pane, nil }
+
+func (f *fakeSession) NudgeSession(_ string, message string) error {
+	f.nudges = append(f.nudges, message)
+	return nil
+}
+
+type ranHook struct {
+	event, command string
+}
+
+func newTestEmulator(t *testing.T, sess *fakeSession) (*Emulator, *time.Time, *[]ranHook, map[string]string) {
+	t.Helper()
+	town := t.TempDir()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var ran []ranHook
+	outputs := map[string]string{}
+
+	e := NewEmulator(town, func(string) Session { return sess }, func(string, ...interface{}) {})
+	e.now = func() time.Time { return now }
+	e.alive = func(int) bool { return true }
+	e.run = func(_ context.Context, _ *State, event, command string) (string, error) {
+		ran = append(ran, ranHook{event, command})
+		return outputs[command], nil
+	}
+	return e, &now, &ran, outputs
+}
+
+func writeTestState(t *testing.T, e *Emulator, settings string, started time.Time) {
+	t.Helper()
+	s := &State{
+		Session:   "gt-gastown-toast",
+		Backend:   "tmux",
+		Role:      "polecat",
+		WorkDir:   t.TempDir(),
+		Settings:  settings,
+		PID:       4242,
+		StartedAt: started,
+	}
+	if err := WriteState(e.townRoot, s); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestConfig(t *testing.T, cfg string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	if err := os.WriteFile(path, []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEmulator_SessionStartWaitsForSettle(t *testing.T) {
+	sess := &fakeSession{exists: true, pane: "booting"}
+	e, now, ran, _ := newTestEmulator(t, sess)
+	settings := writeTestConfig(t, `{"hooks":{"SessionStart":["echo start"]}}`)
+	writeTestState(t, e, settings, *now)
+
+	e.Tick()
+	*now = now.Add(SettleTime)
+	sess.pane = "prompt>"
+	e.Tick()
+	if len(*ran) != 0 {
+		t.Fatalf("SessionStart fired while pane was changing: %v", *ran)
+	}
+
+	*now = now.Add(SettleTime)
+	e.Tick()
+	if len(*ran) != 1 || (*ran)[0].event != EventSessionStart {
+		t.Fatalf("expected SessionStart once pane settled, got %v", *ran)
+	}
+
+	// Persisted: a fresh Emulator (daemon restart) does not fire it again.
+	states, err := ReadStates(e.townRoot)
+	if err != nil || len(states) != 1 || !states[0].HasFired(EventSessionStart) {
+		t.Fatalf("SessionStart not recorded in state: %v %v", states, err)
+	}
+	*now = now.Add(SettleTime)
+	e.Tick()
+	if len(*ran) != 1 {
+		t.Fatalf("SessionStart fired twice: %v", *ran)
+	}
+}
+
+func TestEmulator_SessionStartTimeout(t *testing.T) {
+	sess := &fakeSession{exists: true}
+	e, now, ran, _ := newTestEmulator(t, sess)
+	settings := writeTestConfig(t, `{"hooks":{"SessionStart":["echo start"]}}`)
+	writeTestState(t, e, settings, *now)
+
+	for i := 0; i < int(StartTimeout/PollInterval); i++ {
+		sess.pane = strings.Repeat(".", i)
+		*now = now.Add(PollInterval)
+		e.Tick()
+	}
+	if len(*ran) != 1 || (*ran)[0].event != EventSessionStart {
+		t.Fatalf("expected SessionStart after timeout, got %v", *ran)
+	}
+}
+
+func TestEmulator_IdleFiresOncePerQuietPeriod(t *testing.T) {
+	sess := &fakeSession{exists: true, pane: "prompt>"}
+	e, now, ran, _ := newTestEmulator(t, sess)
+	settings := writeTestConfig(t, `{"hooks":{"Idle":["echo idle"]},"idle_after_seconds":10}`)
+	writeTestState(t, e, settings, now.Add(-time.Minute))
+
+	e.Tick() // SessionStart (no commands) is recorded
+	*now = now.Add(10 * time.Second)
+	e.Tick()
+	*now = now.Add(10 * time.Second)
+	e.Tick()
+	if len(*ran) != 1 || (*ran)[0].event != EventIdle {
+		t.Fatalf("expected one Idle, got %v", *ran)
+	}
+
+	sess.pane = "working..."
+	*now = now.Add(time.Second)
+	e.Tick()
+	*now = now.Add(10 * time.Second)
+	e.Tick()
+	if len(*ran) != 2 {
+		t.Fatalf("expected Idle again after activity, got %v", *ran)
+	}
+}
+
+func TestEmulator_StopRemovesState(t *testing.T) {
+	sess := &fakeSession{exists: true}
+	e, now, ran, _ := newTestEmulator(t, sess)
+	settings := writeTestConfig(t, `{"hooks":{"Stop":["echo bye"]}}`)
+	writeTestState(t, e, settings, *now)
+	e.alive = func(int) bool { return false }
+
+	e.Tick()
+	if len(*ran) != 1 || (*ran)[0].event != EventStop {
+		t.Fatalf("expected Stop, got %v", *ran)
+	}
+	states, _ := ReadStates(e.townRoot)
+	if len(states) != 0 {
+		t.Fatalf("state not removed after Stop: %v", states)
+	}
+	if len(sess.nudges) != 0 {
+		t.Errorf("Stop output must not be nudged to an exited agent: %v", sess.nudges)
+	}
+}
+
+func TestEmulator_DeliversLongOutputViaFile(t *testing.T) {
+	sess := &fakeSession{exists: true}
+	e, now, _, outputs := newTestEmulator(t, sess)
+	settings := writeTestConfig(t, `{"hooks":{"SessionStart":["gt prime","gt mail check"]}}`)
+	writeTestState(t, e, settings, now.Add(-StartTimeout))
+	outputs["gt prime"] = "# Role\nYou are a polecat."
+	outputs["gt mail check"] = "1 new message"
+
+	e.Tick()
+	if len(sess.nudges) != 1 || !strings.Contains(sess.nudges[0], "[gt SessionStart hook] Read ") {
+		t.Fatalf("expected a pointer nudge, got %v", sess.nudges)
+	}
+	data, err := os.ReadFile(filepath.Join(StateDir(e.townRoot), "gt-gastown-toast-SessionStart.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "You are a polecat.") || !strings.Contains(string(data), "1 new message") {
+		t.Errorf("hook output file missing output: %q", data)
+	}
+}
+
+func TestEmulator_DeliversShortOutputInline(t *testing.T) {
+	sess := &fakeSession{exists: true}
+	e, now, _, outputs := newTestEmulator(t, sess)
+	settings := writeTestConfig(t, `{"hooks":{"SessionStart":["gt mail check"]}}`)
+	writeTestState(t, e, settings, now.Add(-StartTimeout))
+	outputs["gt mail check"] = "1 new message\n"
+
+	e.Tick()
+	if len(sess.nudges) != 1 || sess.nudges[0] != "1 new message" {
+		t.Fatalf("expected inline nudge, got %v", sess.nudges)
+	}
+}
+
+func TestConfigFor_FallsBackToRoleDefaults(t *testing.T) {
+	cfg := ConfigFor("", "polecat")
+	start := strings.Join(cfg.Hooks[EventSessionStart], ";")
+	if !strings.Contains(start, "gt prime") || !strings.Contains(start, "gt mail check --inject") {
+		t.Errorf("polecat SessionStart = %q", start)
+	}
+	if strings.Contains(strings.Join(ConfigFor("", "crew").Hooks[EventSessionStart], ";"), "mail check") {
+		t.Error("crew SessionStart should not inject mail")
+	}
+	if cfg.IdleAfter() != DefaultIdleAfter {
+		t.Errorf("IdleAfter = %v, want %v", cfg.IdleAfter(), DefaultIdleAfter)
+	}
+}
+
+func TestEnsureConfigAt_FindConfig(t *testing.T) {
+	work := t.TempDir()
+	if err := EnsureConfigAt(work, "witness", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(work, "src", "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(work, DefaultDir, DefaultFile)
+	if got := FindConfig(sub); got != want {
+		t.Errorf("FindConfig = %q, want %q", got, want)
+	}
+
+	// Existing files are left alone.
+	if err := os.WriteFile(want, []byte(`{"hooks":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureConfigAt(work, "witness", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(want); string(data) != `{"hooks":{}}` {
+		t.Errorf("EnsureConfigAt overwrote existing config: %s", data)
+	}
+}
+
+func TestRunPreToolUse(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run under sh")
+	}
+	cfg := &Config{Hooks: map[string][]string{
+		EventPreToolUse: {`grep -q 'push --force' && { echo blocked >&2; exit 2; } || exit 0`},
+	}}
+	var stderr bytes.Buffer
+	if !RunPreToolUse(cfg, ToolCommand("git", []string{"push", "--force"}), t.TempDir(), &stderr) {
+		t.Error("force push was not blocked")
+	}
+	if !strings.Contains(stderr.String(), "blocked") {
+		t.Errorf("hook stderr not forwarded: %q", stderr.String())
+	}
+	if RunPreToolUse(cfg, ToolCommand("git", []string{"status"}), t.TempDir(), &stderr) {
+		t.Error("git status was blocked")
+	}
+}
+
+func TestLookPathExcluding(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shims are unix-only")
+	}
+	shims, bin := t.TempDir(), t.TempDir()
+	if err := WriteShims(shims, "/usr/local/bin/gt", []string{"git"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", shims+string(os.PathListSeparator)+bin)
+
+	got, err := LookPathExcluding("git", shims)
+	if err != nil || got != filepath.Join(bin, "git") {
+		t.Errorf("LookPathExcluding = %q, %v", got, err)
+	}
+	if _, err := LookPathExcluding("gh", shims); err == nil {
+		t.Error("expected not-found for gh")
+	}
+}
@@ -0,0 +1,89 @@
+package hookemu
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// ShimDir returns the directory holding a session's guard shims.
+func ShimDir(townRoot, session string) string {
+	return filepath.Join(StateDir(townRoot), "shims", session)
+}
+
+// WriteShims writes a shim script into dir for each guarded command. The
+// shim hands the call to `<gt> hooks tool <name> args...`, which runs the
+// PreToolUse hooks and then execs the real command.
+func WriteShims(dir, gt string, commands []string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating shim directory: %w", err)
+	}
+	for _, name := range commands {
+		if name == "" || strings.ContainsRune(name, filepath.Separator) {
+			continue
+		}
+		script := fmt.Sprintf("#!/bin/sh\nexec %s hooks tool %s \"$@\"\n", config.ShellQuote(gt), config.ShellQuote(name))
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil { //nolint:gosec // G306: shims must be executable
+			return fmt.Errorf("writing shim %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ToolCommand renders a shimmed invocation as the shell command a Bash tool
+// call would carry, so guard rules written for Claude match it.
+func ToolCommand(name string, args []string) string {
+	parts := []string{name}
+	for _, a := range args {
+		parts = append(parts, config.ShellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// RunPreToolUse runs the PreToolUse hooks for a Bash command, feeding each
+// the Claude-style hook payload on stdin. It returns true if a hook blocked
+// the call by exiting with status 2. Other failures do not block, matching
+// Claude Code's hook semantics. Hook stderr goes to stderr.
+func RunPreToolUse(cfg *Config, command, cwd string, stderr io.Writer) bool {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"hook_event_name": EventPreToolUse,
+		"tool_name":       "Bash",
+		"tool_input":      map[string]string{"command": command},
+		"cwd":             cwd,
+	})
+	for _, hook := range cfg.Hooks[EventPreToolUse] {
+		cmd := exec.Command("sh", "-c", hook)
+		cmd.Dir = cwd
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout = stderr
+		cmd.Stderr = stderr
+		var exitErr *exec.ExitError
+		if err := cmd.Run(); errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// LookPathExcluding finds an executable on PATH, skipping dir (the shim
+// directory) so a shim never resolves to itself.
+func LookPathExcluding(name, dir string) (string, error) {
+	for _, p := range filepath.SplitList(os.Getenv("PATH")) {
+		if p == "" || filepath.Clean(p) == filepath.Clean(dir) {
+			continue
+		}
+		candidate := filepath.Join(p, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %w", name, exec.ErrNotFound)
+}
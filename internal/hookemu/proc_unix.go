@@ -0,0 +1,24 @@
+//go:build !windows
+
+package hookemu
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether pid is still running.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// Exec replaces the current process with the agent, keeping its PID (and
+// so the session's pane command) for process detection. onExit is unused:
+// the Emulator notices the exit by the PID disappearing.
+func Exec(path string, argv, env []string, onExit func(code int)) error {
+	return syscall.Exec(path, argv, env)
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package hookemu
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// processAlive cannot probe PIDs portably on Windows; the wrapper records
+// the exit itself instead (see Exec).
+func processAlive(pid int) bool {
+	return pid > 0
+}
+
+// Exec runs the agent as a child (Windows has no exec), calls onExit with
+// its exit code and exits with it.
+func Exec(path string, argv, env []string, onExit func(code int)) error {
+	cmd := exec.Command(path, argv[1:]...)
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	code := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		return err
+	}
+	if onExit != nil {
+		onExit(code)
+	}
+	os.Exit(code)
+	return nil
+}
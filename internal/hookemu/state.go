@@ -0,0 +1,136 @@
+package hookemu
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// State describes one wrapped agent process. The wrapper writes it when the
+// agent starts; the Emulator records fired events in it and removes it once
+// the agent has exited.
+type State struct {
+	// Session is the agent's session name; Backend is the session backend
+	// hosting it ("tmux" or "pty").
+	Session string `json:"session"`
+	Backend string `json:"backend"`
+
+	// Role, WorkDir and Settings (the hooks file, if any) determine which
+	// hooks run and where.
+	Role     string `json:"role,omitempty"`
+	WorkDir  string `json:"work_dir"`
+	Settings string `json:"settings,omitempty"`
+
+	// PID is the agent process. The wrapper execs the agent in place, so
+	// this is the wrapper's own PID.
+	PID       int        `json:"pid"`
+	StartedAt time.Time  `json:"started_at"`
+	ExitedAt  *time.Time `json:"exited_at,omitempty"`
+	ExitCode  int        `json:"exit_code,omitempty"`
+
+	// Env holds the agent's GT_*/BD_* variables, passed to hook commands.
+	Env map[string]string `json:"env,omitempty"`
+
+	// Fired lists the once-per-process events already run (SessionStart).
+	Fired []string `json:"fired,omitempty"`
+}
+
+// HasFired reports whether event has already fired for this process.
+func (s *State) HasFired(event string) bool {
+	for _, e := range s.Fired {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Environ returns the environment for hook commands: the current process
+// environment overlaid with the agent's variables.
+func (s *State) Environ() []string {
+	env := os.Environ()
+	keys := make([]string, 0, len(s.Env))
+	for k := range s.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+s.Env[k])
+	}
+	return env
+}
+
+// StateDir returns the directory holding hook emulation state for a town.
+func StateDir(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "hooks")
+}
+
+// statePath returns the state file for a session.
+func statePath(townRoot, session string) string {
+	return filepath.Join(StateDir(townRoot), session+".json")
+}
+
+// WriteState saves a session's state.
+func WriteState(townRoot string, s *State) error {
+	if err := os.MkdirAll(StateDir(townRoot), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(statePath(townRoot, s.Session), s)
+}
+
+// RemoveState deletes a session's state and any delivered hook output.
+func RemoveState(townRoot, session string) error {
+	matches, _ := filepath.Glob(filepath.Join(StateDir(townRoot), session+"-*.md"))
+	for _, m := range matches {
+		_ = os.Remove(m)
+	}
+	if err := os.Remove(statePath(townRoot, session)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ReadStates returns the state of every wrapped agent in the town.
+// Unreadable files are skipped.
+func ReadStates(townRoot string) ([]*State, error) {
+	entries, err := os.ReadDir(StateDir(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var states []*State
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(StateDir(townRoot), e.Name()))
+		if err != nil {
+			continue
+		}
+		var s State
+		if json.Unmarshal(data, &s) != nil || s.Session == "" {
+			continue
+		}
+		states = append(states, &s)
+	}
+	return states, nil
+}
+
+// AgentEnv extracts the Gas Town variables from an environment list.
+func AgentEnv(environ []string) map[string]string {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		k, v, ok := strings.Cut(kv, "=")
+		if ok && (strings.HasPrefix(k, "GT_") || strings.HasPrefix(k, "BD_") || k == "BEADS_DIR" || k == "BEADS_NO_DAEMON") {
+			env[k] = v
+		}
+	}
+	return env
+}
@@ -13,10 +13,11 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/checkpoint"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
-	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -48,11 +49,11 @@ type Manager struct {
 	git      *git.Git
 	beads    *beads.Beads
 	namePool *NamePool
-	tmux     *tmux.Tmux
+	tmux     session.Backend
 }
 
 // NewManager creates a new polecat manager.
-func NewManager(r *rig.Rig, g *git.Git, t *tmux.Tmux) *Manager {
+func NewManager(r *rig.Rig, g *git.Git, t session.Backend) *Manager {
 	// Use the resolved beads directory to find where bd commands should run.
 	// For tracked beads: rig/.beads/redirect -> mayor/rig/.beads, so use mayor/rig
 	// For local beads: rig/.beads is the database, so use rig root
@@ -661,6 +662,17 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 		}
 	}
 
+	// Snapshot any uncommitted work before the worktree is destroyed, so a
+	// forced repair does not lose it. The ref lives in the shared repo and
+	// outlives the worktree.
+	var snap *checkpoint.Snapshot
+	if _, statErr := os.Stat(oldClonePath); statErr == nil {
+		snap, err = checkpoint.SnapshotWorktree(oldClonePath, name, checkpoint.DefaultKeep)
+		if err != nil {
+			fmt.Printf("Warning: could not snapshot uncommitted work: %v\n", err)
+		}
+	}
+
 	// Close old agent bead before recreation (non-fatal)
 	// NOTE: We use CloseAndClearAgentBead instead of DeleteAgentBead because bd delete --hard
 	// creates tombstones that cannot be reopened.
@@ -738,6 +750,16 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 
 	// NOTE: Slash commands inherited from town level - no per-workspace copies needed.
 
+	// Carry the snapshotted work over. The fresh worktree starts from origin,
+	// so this only applies cleanly if the old branch had no local commits.
+	if snap != nil {
+		if err := checkpoint.Restore(newClonePath, snap); err != nil {
+			fmt.Printf("Warning: uncommitted work saved in %s could not be restored: %v\n", snap.Ref, err)
+		} else {
+			fmt.Printf("Restored uncommitted work from %s\n", snap.Ref)
+		}
+	}
+
 	// Create or reopen agent bead for ZFC compliance
 	// HookBead is set atomically at recreation time if provided.
 	// Uses CreateOrReopenAgentBead to handle re-spawning with same name (GH #332).
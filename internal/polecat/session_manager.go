@@ -8,13 +8,17 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/cgroup"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/container"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -35,18 +39,26 @@ var (
 
 // SessionManager handles polecat session lifecycle.
 type SessionManager struct {
-	tmux *tmux.Tmux
+	tmux session.Backend
 	rig  *rig.Rig
 }
 
 // NewSessionManager creates a new polecat session manager for a rig.
-func NewSessionManager(t *tmux.Tmux, r *rig.Rig) *SessionManager {
+// Sessions run on b, usually session.NewBackend for the rig's town.
+func NewSessionManager(b session.Backend, r *rig.Rig) *SessionManager {
 	return &SessionManager{
-		tmux: t,
+		tmux: b,
 		rig:  r,
 	}
 }
 
+// tmuxOnly returns the tmux backend for features only tmux has (themes,
+// status lines, pane hooks), or nil when sessions run elsewhere.
+func (m *SessionManager) tmuxOnly() *tmux.Tmux {
+	t, _ := m.tmux.(*tmux.Tmux)
+	return t
+}
+
 // SessionStartOptions configures polecat session startup.
 type SessionStartOptions struct {
 	// WorkDir overrides the default working directory (polecat clone dir).
@@ -91,6 +103,9 @@ type SessionInfo struct {
 
 	// LastActivity is when the session last had activity.
 	LastActivity time.Time `json:"last_activity,omitempty"`
+
+	// Resources is cgroup resource usage, when the session runs under limits.
+	Resources *cgroup.Usage `json:"resources,omitempty"`
 }
 
 // SessionName generates the tmux session name for a polecat.
@@ -189,16 +204,32 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	if runtimeConfig.Session != nil && runtimeConfig.Session.ConfigDirEnv != "" && opts.RuntimeConfigDir != "" {
 		command = config.PrependEnv(command, map[string]string{runtimeConfig.Session.ConfigDirEnv: opts.RuntimeConfigDir})
 	}
+	// Isolate the agent if the rig enables a sandbox
+	townRoot := filepath.Dir(m.rig.Path)
+	command, err = sandbox.WrapForRig(townRoot, m.rig.Path, m.polecatDir(polecat), workDir, command)
+	if err != nil {
+		return fmt.Errorf("sandboxing polecat: %w", err)
+	}
+	// Run the agent in a container if the rig configures an image
+	command, err = container.WrapForRig(townRoot, m.rig.Path, m.polecatDir(polecat), workDir, sessionID, command)
+	if err != nil {
+		return fmt.Errorf("containerizing polecat: %w", err)
+	}
+	// Wrap in a cgroup scope if the polecat role has resource limits
+	command, err = m.wrapWithResourceLimits(sessionID, townRoot, command)
+	if err != nil {
+		return err
+	}
 
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
 	if err := m.tmux.NewSessionWithCommand(sessionID, workDir, command); err != nil {
+		debugSession("RemoveContainer", container.RemoveForRig(m.rig.Path, sessionID))
 		return fmt.Errorf("creating session: %w", err)
 	}
 
 	// Set environment (non-fatal: session works without these)
 	// Use centralized AgentEnv for consistency across all role startup paths
-	townRoot := filepath.Dir(m.rig.Path)
 	envVars := config.AgentEnv(config.AgentEnvConfig{
 		Role:             "polecat",
 		Rig:              m.rig.Name,
@@ -219,16 +250,18 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 		}
 	}
 
-	// Apply theme (non-fatal)
-	theme := tmux.AssignTheme(m.rig.Name)
-	debugSession("ConfigureGasTownSession", m.tmux.ConfigureGasTownSession(sessionID, theme, m.rig.Name, polecat, "polecat"))
+	if t := m.tmuxOnly(); t != nil {
+		// Apply theme (non-fatal)
+		theme := tmux.AssignTheme(m.rig.Name)
+		debugSession("ConfigureGasTownSession", t.ConfigureGasTownSession(sessionID, theme, m.rig.Name, polecat, "polecat"))
 
-	// Set pane-died hook for crash detection (non-fatal)
-	agentID := fmt.Sprintf("%s/%s", m.rig.Name, polecat)
-	debugSession("SetPaneDiedHook", m.tmux.SetPaneDiedHook(sessionID, agentID))
+		// Set pane-died hook for crash detection (non-fatal)
+		agentID := fmt.Sprintf("%s/%s", m.rig.Name, polecat)
+		debugSession("SetPaneDiedHook", t.SetPaneDiedHook(sessionID, agentID))
 
-	// Wait for Claude to start (non-fatal)
-	debugSession("WaitForCommand", m.tmux.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout))
+		// Wait for Claude to start (non-fatal)
+		debugSession("WaitForCommand", t.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout))
+	}
 
 	// Accept bypass permissions warning dialog if it appears
 	debugSession("AcceptBypassPermissionsWarning", m.tmux.AcceptBypassPermissionsWarning(sessionID))
@@ -263,6 +296,23 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	return nil
 }
 
+// wrapWithResourceLimits wraps command in a transient cgroup scope when the
+// polecat role definition configures resource limits. Limits are optional:
+// without a [resources] section (or on non-Linux systems) command is unchanged.
+func (m *SessionManager) wrapWithResourceLimits(sessionID, townRoot, command string) (string, error) {
+	roleDef, err := config.LoadRoleDefinition(townRoot, m.rig.Path, "polecat")
+	if err != nil {
+		return "", fmt.Errorf("loading polecat role: %w", err)
+	}
+	if !roleDef.Resources.IsSet() {
+		return command, nil
+	}
+	if err := roleDef.Resources.Validate(); err != nil {
+		return "", fmt.Errorf("polecat resource limits: %w", err)
+	}
+	return cgroup.WrapCommand(cgroup.UnitName(sessionID, time.Now()), roleDef.Resources, command), nil
+}
+
 // Stop terminates a polecat session.
 func (m *SessionManager) Stop(polecat string, force bool) error {
 	sessionID := m.SessionName(polecat)
@@ -295,6 +345,10 @@ func (m *SessionManager) Stop(polecat string, force bool) error {
 		return fmt.Errorf("killing session: %w", err)
 	}
 
+	// Remove the polecat's container in case the proxy was killed before it
+	// could clean up (non-fatal)
+	debugSession("RemoveContainer", container.RemoveForRig(m.rig.Path, sessionID))
+
 	return nil
 }
 
@@ -331,7 +385,13 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 		return info, nil
 	}
 
-	tmuxInfo, err := m.tmux.GetSessionInfo(sessionID)
+	info.Resources = m.resourceUsage(sessionID)
+
+	t := m.tmuxOnly()
+	if t == nil {
+		return info, nil
+	}
+	tmuxInfo, err := t.GetSessionInfo(sessionID)
 	if err != nil {
 		return info, nil
 	}
@@ -364,6 +424,24 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 	return info, nil
 }
 
+// resourceUsage returns cgroup usage for a session's pane process.
+// Returns nil if the session is not running under resource limits.
+func (m *SessionManager) resourceUsage(sessionID string) *cgroup.Usage {
+	pidStr, err := m.tmux.GetPanePID(sessionID)
+	if err != nil {
+		return nil
+	}
+	pid, err := strconv.Atoi(pidStr)
+	if err != nil {
+		return nil
+	}
+	usage, err := cgroup.UsageForPID(pid)
+	if err != nil {
+		return nil
+	}
+	return usage
+}
+
 // List returns information about all polecat sessions for this rig.
 func (m *SessionManager) List() ([]SessionInfo, error) {
 	sessions, err := m.tmux.ListSessions()
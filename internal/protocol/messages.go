@@ -142,6 +142,7 @@ func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, c
 		RequestedAt:   time.Now(),
 		TargetBranch:  targetBranch,
 		ConflictFiles: conflictFiles,
+		Reason:        ReworkReasonConflict,
 		Instructions:  formatRebaseInstructions(targetBranch),
 	}
 
@@ -159,6 +160,36 @@ func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, c
 	return msg
 }
 
+// NewReviewReworkMessage creates a REWORK_REQUEST protocol message for
+// changes requested in code review.
+// Sent by Refinery to Witness when a reviewer rejects a merge request.
+func NewReviewReworkMessage(rig, polecat, branch, issue, targetBranch, reviewer, feedback string) *mail.Message {
+	payload := ReworkRequestPayload{
+		Branch:       branch,
+		Issue:        issue,
+		Polecat:      polecat,
+		Rig:          rig,
+		RequestedAt:  time.Now(),
+		TargetBranch: targetBranch,
+		Reason:       ReworkReasonReview,
+		Reviewer:     reviewer,
+		Instructions: formatReviewInstructions(feedback),
+	}
+
+	body := formatReworkRequestBody(payload)
+
+	msg := mail.NewMessage(
+		fmt.Sprintf("%s/refinery", rig),
+		fmt.Sprintf("%s/witness", rig),
+		fmt.Sprintf("REWORK_REQUEST %s", polecat),
+		body,
+	)
+	msg.Priority = mail.PriorityHigh
+	msg.Type = mail.TypeTask
+
+	return msg
+}
+
 // formatReworkRequestBody formats the body of a REWORK_REQUEST message.
 func formatReworkRequestBody(p ReworkRequestPayload) string {
 	var sb strings.Builder
@@ -169,6 +200,12 @@ func formatReworkRequestBody(p ReworkRequestPayload) string {
 	sb.WriteString(fmt.Sprintf("Target: %s\n", p.TargetBranch))
 	sb.WriteString(fmt.Sprintf("Requested-At: %s\n", p.RequestedAt.Format(time.RFC3339)))
 
+	if p.Reason != "" {
+		sb.WriteString(fmt.Sprintf("Reason: %s\n", p.Reason))
+	}
+	if p.Reviewer != "" {
+		sb.WriteString(fmt.Sprintf("Reviewer: %s\n", p.Reviewer))
+	}
 	if len(p.ConflictFiles) > 0 {
 		sb.WriteString(fmt.Sprintf("Conflict-Files: %s\n", strings.Join(p.ConflictFiles, ", ")))
 	}
@@ -191,6 +228,17 @@ func formatRebaseInstructions(targetBranch string) string {
 The Refinery will retry the merge after rebase is complete.`, targetBranch, targetBranch)
 }
 
+// formatReviewInstructions returns the reviewer's feedback with standard
+// rework instructions.
+func formatReviewInstructions(feedback string) string {
+	return fmt.Sprintf(`Requested changes:
+
+%s
+
+Address the feedback on the same branch and push. The merge request is
+blocked until the rework task closes, then goes back to review.`, strings.TrimSpace(feedback))
+}
+
 // ParseMergeReadyPayload parses a MERGE_READY message body into a payload.
 func ParseMergeReadyPayload(body string) *MergeReadyPayload {
 	return &MergeReadyPayload{
@@ -254,6 +302,13 @@ func ParseReworkRequestPayload(body string) *ReworkRequestPayload {
 		Polecat:      parseField(body, "Polecat"),
 		Rig:          parseField(body, "Rig"),
 		TargetBranch: parseField(body, "Target"),
+		Reason:       parseField(body, "Reason"),
+		Reviewer:     parseField(body, "Reviewer"),
+	}
+
+	// Instructions (rebase steps or review feedback) follow the header block
+	if _, rest, ok := strings.Cut(body, "\n\n"); ok {
+		payload.Instructions = strings.TrimSpace(rest)
 	}
 
 	// Parse timestamp
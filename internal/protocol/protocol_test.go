@@ -151,6 +151,32 @@ func TestNewReworkRequestMessage(t *testing.T) {
 	}
 }
 
+func TestNewReviewReworkMessage(t *testing.T) {
+	msg := NewReviewReworkMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main",
+		"gastown/crew/max", "Handle the nil config case in Load.")
+
+	if msg.Subject != "REWORK_REQUEST nux" {
+		t.Errorf("Subject = %q, want %q", msg.Subject, "REWORK_REQUEST nux")
+	}
+
+	payload := ParseReworkRequestPayload(msg.Body)
+	if !payload.IsReview() {
+		t.Errorf("Reason = %q, want %q", payload.Reason, ReworkReasonReview)
+	}
+	if payload.Reviewer != "gastown/crew/max" {
+		t.Errorf("Reviewer = %q, want %q", payload.Reviewer, "gastown/crew/max")
+	}
+	if !strings.Contains(payload.Instructions, "Handle the nil config case") {
+		t.Errorf("Instructions missing feedback: %q", payload.Instructions)
+	}
+
+	// Conflict rework stays distinguishable
+	conflict := ParseReworkRequestPayload(NewReworkRequestMessage("gastown", "nux", "b", "gt-abc", "main", nil).Body)
+	if conflict.IsReview() {
+		t.Error("conflict REWORK_REQUEST parsed as review")
+	}
+}
+
 func TestParseMergeReadyPayload(t *testing.T) {
 	body := `Branch: polecat/nux/gt-abc
 Issue: gt-abc
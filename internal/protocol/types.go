@@ -7,7 +7,7 @@
 //   - MERGE_READY: Witness → Refinery (branch ready for merge)
 //   - MERGED: Refinery → Witness (merge succeeded, cleanup ok)
 //   - MERGE_FAILED: Refinery → Witness (merge failed, needs rework)
-//   - REWORK_REQUEST: Refinery → Witness (rebase needed)
+//   - REWORK_REQUEST: Refinery → Witness (rebase or review changes needed)
 package protocol
 
 import (
@@ -35,7 +35,8 @@ const (
 	TypeMergeFailed MessageType = "MERGE_FAILED"
 
 	// TypeReworkRequest is sent from Refinery to Witness when a polecat's
-	// branch needs rebasing due to conflicts with the target branch.
+	// branch needs rework: rebasing due to conflicts with the target branch,
+	// or changes requested by a code reviewer.
 	// Subject format: "REWORK_REQUEST <polecat-name>"
 	TypeReworkRequest MessageType = "REWORK_REQUEST"
 )
@@ -137,8 +138,18 @@ type MergeFailedPayload struct {
 	TargetBranch string `json:"target_branch"`
 }
 
+// Rework reasons carried by REWORK_REQUEST messages.
+const (
+	// ReworkReasonConflict means the branch must be rebased onto its target.
+	ReworkReasonConflict = "conflict"
+
+	// ReworkReasonReview means a reviewer requested changes.
+	ReworkReasonReview = "review"
+)
+
 // ReworkRequestPayload contains the data for a REWORK_REQUEST message.
-// Sent by Refinery when a polecat's branch has conflicts requiring rebase.
+// Sent by Refinery when a polecat's branch has conflicts requiring rebase,
+// or when a code review requested changes.
 type ReworkRequestPayload struct {
 	// Branch is the source branch that needs rebasing.
 	Branch string `json:"branch"`
@@ -161,10 +172,22 @@ type ReworkRequestPayload struct {
 	// ConflictFiles lists files with conflicts (if known).
 	ConflictFiles []string `json:"conflict_files,omitempty"`
 
-	// Instructions provides specific rebase instructions.
+	// Reason is ReworkReasonConflict or ReworkReasonReview.
+	// Empty is treated as a conflict (messages predating review support).
+	Reason string `json:"reason,omitempty"`
+
+	// Reviewer is who requested changes (review rework only).
+	Reviewer string `json:"reviewer,omitempty"`
+
+	// Instructions provides rebase instructions or the review feedback.
 	Instructions string `json:"instructions,omitempty"`
 }
 
+// IsReview returns true if the rework was requested by a code reviewer.
+func (p *ReworkRequestPayload) IsReview() bool {
+	return p.Reason == ReworkReasonReview
+}
+
 // IsProtocolMessage returns true if the subject matches a known protocol type.
 func IsProtocolMessage(subject string) bool {
 	return ParseMessageType(subject) != ""
@@ -104,7 +104,24 @@ func (h *DefaultWitnessHandler) HandleMergeFailed(payload *MergeFailedPayload) e
 // 1. Logs the conflict
 // 2. Notifies the polecat with rebase instructions
 // 3. Updates the polecat's state to indicate rebase needed
+//
+// Review rework (changes requested by a reviewer) is forwarded to the
+// polecat with the reviewer's feedback instead.
 func (h *DefaultWitnessHandler) HandleReworkRequest(payload *ReworkRequestPayload) error {
+	if payload.IsReview() {
+		fmt.Fprintf(h.Output, "[Witness] REWORK_REQUEST (review) received for polecat %s\n", payload.Polecat)
+		fmt.Fprintf(h.Output, "  Branch: %s\n", payload.Branch)
+		fmt.Fprintf(h.Output, "  Issue: %s\n", payload.Issue)
+		fmt.Fprintf(h.Output, "  Reviewer: %s\n", payload.Reviewer)
+
+		if err := h.notifyPolecatReviewChanges(payload); err != nil {
+			fmt.Fprintf(h.Output, "[Witness] Warning: failed to notify polecat: %v\n", err)
+		}
+
+		fmt.Fprintf(h.Output, "[Witness] ⚠ Polecat %s has review changes to address\n", payload.Polecat)
+		return nil
+	}
+
 	fmt.Fprintf(h.Output, "[Witness] REWORK_REQUEST received for polecat %s\n", payload.Polecat)
 	fmt.Fprintf(h.Output, "  Branch: %s\n", payload.Branch)
 	fmt.Fprintf(h.Output, "  Issue: %s\n", payload.Issue)
@@ -214,5 +231,31 @@ Then run 'gt done' to resubmit for merge.`,
 	return h.Router.Send(msg)
 }
 
+// notifyPolecatReviewChanges forwards reviewer feedback to a polecat.
+func (h *DefaultWitnessHandler) notifyPolecatReviewChanges(payload *ReworkRequestPayload) error {
+	msg := mail.NewMessage(
+		fmt.Sprintf("%s/witness", h.Rig),
+		fmt.Sprintf("%s/%s", h.Rig, payload.Polecat),
+		"Changes requested in code review",
+		fmt.Sprintf(`%s requested changes to your merge request.
+
+Branch: %s
+Issue: %s
+
+%s
+
+Then run 'gt done' to resubmit for review.`,
+			payload.Reviewer,
+			payload.Branch,
+			payload.Issue,
+			payload.Instructions,
+		),
+	)
+	msg.Priority = mail.PriorityHigh
+	msg.Type = mail.TypeTask
+
+	return h.Router.Send(msg)
+}
+
 // Ensure DefaultWitnessHandler implements WitnessHandler.
 var _ WitnessHandler = (*DefaultWitnessHandler)(nil)
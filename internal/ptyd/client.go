@@ -0,0 +1,414 @@
+package ptyd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+	"golang.org/x/term"
+)
+
+// DetachKey ends an attach without stopping the session (Ctrl-]).
+const DetachKey = 0x1d
+
+// callTimeout bounds a single request to the daemon.
+const callTimeout = 30 * time.Second
+
+// ErrNotRunning means no daemon is listening on the socket.
+var ErrNotRunning = errors.New("ptyd is not running")
+
+// nudgeLocks serializes nudges to the same session, as tmux does.
+var nudgeLocks sync.Map // map[string]*sync.Mutex
+
+// SocketPath returns the daemon socket for a town.
+func SocketPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "ptyd.sock")
+}
+
+// Client talks to a ptyd daemon. It provides the session operations of
+// tmux.Tmux and returns tmux's errors, so callers work unchanged.
+type Client struct {
+	socket string
+
+	// spawn starts the daemon when a session is created and none is
+	// running. Nil means the daemon must already be up.
+	spawn func() error
+}
+
+// NewClient returns a client for the daemon on socket.
+func NewClient(socket string) *Client {
+	return &Client{socket: socket}
+}
+
+// ForTown returns a client for the town's daemon that starts it on demand
+// by running 'gt ptyd run' in the background.
+func ForTown(townRoot string) *Client {
+	c := NewClient(SocketPath(townRoot))
+	c.spawn = func() error { return StartDaemon(townRoot) }
+	return c
+}
+
+// StartDaemon starts 'gt ptyd run' for the town in the background, logging
+// to daemon/ptyd.log.
+func StartDaemon(townRoot string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	logDir := filepath.Join(townRoot, "daemon")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(filepath.Join(logDir, "ptyd.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644) //nolint:gosec // G302: log file
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(exe, "ptyd", "run") //nolint:gosec // G204: our own binary
+	cmd.Dir = townRoot
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// IsRunning reports whether the daemon answers on the socket.
+func (c *Client) IsRunning() bool {
+	conn, err := net.DialTimeout("unix", c.socket, time.Second)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// IsAvailable reports whether pty sessions can be used at all.
+func (c *Client) IsAvailable() bool {
+	return c.IsRunning() || c.spawn != nil
+}
+
+func (c *Client) call(req *request) (*response, error) {
+	conn, err := net.DialTimeout("unix", c.socket, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotRunning, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(callTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("ptyd %s: %w", req.Op, err)
+	}
+	if resp.Error != "" {
+		return &resp, wrapError(req.Op, resp.Error)
+	}
+	return &resp, nil
+}
+
+// wrapError maps daemon errors onto tmux's, like Tmux.wrapError.
+func wrapError(op, msg string) error {
+	switch msg {
+	case errExists:
+		return tmux.ErrSessionExists
+	case errNotFound:
+		return tmux.ErrSessionNotFound
+	}
+	return fmt.Errorf("ptyd %s: %s", op, msg)
+}
+
+// ensureRunning starts the daemon if it isn't up and waits for its socket.
+func (c *Client) ensureRunning() error {
+	if c.IsRunning() {
+		return nil
+	}
+	if c.spawn == nil {
+		return ErrNotRunning
+	}
+	if err := c.spawn(); err != nil {
+		return fmt.Errorf("starting ptyd: %w", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		if c.IsRunning() {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("%w: no socket at %s after start", ErrNotRunning, c.socket)
+}
+
+// Shutdown stops the daemon, killing every session it runs.
+func (c *Client) Shutdown() error {
+	_, err := c.call(&request{Op: "shutdown"})
+	return err
+}
+
+// NewSession creates a detached session running the default shell.
+func (c *Client) NewSession(name, workDir string) error {
+	return c.NewSessionWithCommand(name, workDir, "")
+}
+
+// NewSessionWithCommand creates a detached session whose initial process
+// is command, run by the shell.
+func (c *Client) NewSessionWithCommand(name, workDir, command string) error {
+	if err := c.ensureRunning(); err != nil {
+		return err
+	}
+	_, err := c.call(&request{Op: "new", Session: name, Dir: workDir, Command: command})
+	return err
+}
+
+// HasSession reports whether the session exists. No daemon means no sessions.
+func (c *Client) HasSession(name string) (bool, error) {
+	resp, err := c.call(&request{Op: "has", Session: name})
+	if errors.Is(err, ErrNotRunning) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return resp.Exists, nil
+}
+
+// ListSessions returns all session names. No daemon means no sessions.
+func (c *Client) ListSessions() ([]string, error) {
+	resp, err := c.call(&request{Op: "list"})
+	if errors.Is(err, ErrNotRunning) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Sessions, nil
+}
+
+// KillSessionWithProcesses terminates the session's whole process group.
+func (c *Client) KillSessionWithProcesses(name string) error {
+	_, err := c.call(&request{Op: "kill", Session: name})
+	if errors.Is(err, ErrNotRunning) {
+		return tmux.ErrSessionNotFound
+	}
+	return err
+}
+
+// KillSession terminates a session.
+func (c *Client) KillSession(name string) error {
+	return c.KillSessionWithProcesses(name)
+}
+
+func (c *Client) write(session, data string) error {
+	_, err := c.call(&request{Op: "send", Session: session, Data: data})
+	if errors.Is(err, ErrNotRunning) {
+		return tmux.ErrSessionNotFound
+	}
+	return err
+}
+
+// SendKeys types keys literally and presses Enter.
+func (c *Client) SendKeys(session, keys string) error {
+	return c.SendKeysDebounced(session, keys, 100)
+}
+
+// SendKeysDebounced types keys literally, waits debounceMs, then presses Enter.
+func (c *Client) SendKeysDebounced(session, keys string, debounceMs int) error {
+	if err := c.write(session, keys); err != nil {
+		return err
+	}
+	if debounceMs > 0 {
+		time.Sleep(time.Duration(debounceMs) * time.Millisecond)
+	}
+	return c.write(session, "\r")
+}
+
+// SendKeysRaw sends one key by its tmux name (e.g. "C-c", "Enter", "Down").
+// Unknown names are typed literally, as tmux does.
+func (c *Client) SendKeysRaw(session, keys string) error {
+	return c.write(session, KeyBytes(keys))
+}
+
+// NudgeSession sends a message to an agent reliably: the text, a pause for
+// it to land, Escape (for vim mode), then Enter.
+func (c *Client) NudgeSession(session, message string) error {
+	l, _ := nudgeLocks.LoadOrStore(session, &sync.Mutex{})
+	lock := l.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := c.write(session, message); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+	_ = c.write(session, "\x1b")
+	time.Sleep(100 * time.Millisecond)
+	return c.write(session, "\r")
+}
+
+// AcceptBypassPermissionsWarning dismisses Claude's bypass-permissions
+// dialog if it is showing.
+func (c *Client) AcceptBypassPermissionsWarning(session string) error {
+	time.Sleep(1 * time.Second)
+	content, err := c.CapturePane(session, 30)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(content, "Bypass Permissions mode") {
+		return nil
+	}
+	if err := c.SendKeysRaw(session, "Down"); err != nil {
+		return err
+	}
+	time.Sleep(200 * time.Millisecond)
+	return c.SendKeysRaw(session, "Enter")
+}
+
+// CapturePane returns the last lines of the session's output as plain text.
+func (c *Client) CapturePane(session string, lines int) (string, error) {
+	resp, err := c.call(&request{Op: "capture", Session: session, Lines: lines})
+	if errors.Is(err, ErrNotRunning) {
+		return "", tmux.ErrSessionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return resp.Output, nil
+}
+
+// SetEnvironment records a variable on the session. Like tmux, it does
+// not change the environment of processes already running.
+func (c *Client) SetEnvironment(session, key, value string) error {
+	_, err := c.call(&request{Op: "setenv", Session: session, Key: key, Data: value})
+	return err
+}
+
+// GetPanePID returns the PID of the session's initial process.
+func (c *Client) GetPanePID(session string) (string, error) {
+	resp, err := c.call(&request{Op: "pid", Session: session})
+	if errors.Is(err, ErrNotRunning) {
+		return "", tmux.ErrSessionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d", resp.PID), nil
+}
+
+// AttachSession connects the terminal to the session until the session
+// exits or the user presses Ctrl-] to detach.
+func (c *Client) AttachSession(session string) error {
+	conn, err := net.Dial("unix", c.socket)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotRunning, err)
+	}
+	defer conn.Close()
+
+	req := &request{Op: "attach", Session: session}
+	fd := int(os.Stdin.Fd()) //nolint:gosec // G115: fds fit in int
+	if cols, rows, err := term.GetSize(fd); err == nil {
+		req.Rows, req.Cols = rows, cols
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return err
+	}
+	r := bufio.NewReader(conn)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("ptyd attach: %w", err)
+	}
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("ptyd attach: %w", err)
+	}
+	if resp.Error != "" {
+		return wrapError("attach", resp.Error)
+	}
+
+	if term.IsTerminal(fd) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = term.Restore(fd, state) }()
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(os.Stdout, r)
+		done <- struct{}{}
+	}()
+	go func() {
+		copyUntilDetach(conn, os.Stdin)
+		done <- struct{}{}
+	}()
+	<-done
+	return nil
+}
+
+// copyUntilDetach copies input to the session until DetachKey or EOF.
+func copyUntilDetach(w io.Writer, r io.Reader) {
+	buf := make([]byte, 1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			chunk := buf[:n]
+			if i := bytes.IndexByte(chunk, DetachKey); i >= 0 {
+				_, _ = w.Write(chunk[:i])
+				return
+			}
+			if _, werr := w.Write(chunk); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// namedKeys maps tmux key names to the bytes a terminal sends for them.
+var namedKeys = map[string]string{
+	"Enter":  "\r",
+	"Escape": "\x1b",
+	"Tab":    "\t",
+	"BSpace": "\x7f",
+	"Space":  " ",
+	"Up":     "\x1b[A",
+	"Down":   "\x1b[B",
+	"Right":  "\x1b[C",
+	"Left":   "\x1b[D",
+	"Home":   "\x1b[H",
+	"End":    "\x1b[F",
+}
+
+// KeyBytes translates a tmux key name ("C-c", "Enter", "Down") into the
+// bytes to write to a terminal. Anything else is returned as is.
+func KeyBytes(key string) string {
+	if b, ok := namedKeys[key]; ok {
+		return b
+	}
+	if len(key) == 3 && strings.HasPrefix(key, "C-") {
+		c := key[2]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if c >= 'a' && c <= 'z' {
+			return string(rune(c - 'a' + 1))
+		}
+	}
+	return key
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package ptyd
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// attachTTY makes the session's terminal the controlling terminal of cmd,
+// in a new session and process group of its own.
+func attachTTY(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+}
+
+// detach runs cmd in its own session so it outlives the parent.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// signalGroup signals every process in pid's process group.
+func signalGroup(pid int, force bool) error {
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	return syscall.Kill(-pid, sig)
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package ptyd
+
+import (
+	"os"
+	"os/exec"
+)
+
+// attachTTY makes the session's terminal the controlling terminal of cmd.
+func attachTTY(cmd *exec.Cmd) {}
+
+// detach runs cmd in its own session so it outlives the parent.
+func detach(cmd *exec.Cmd) {}
+
+// signalGroup signals every process in pid's process group.
+func signalGroup(pid int, force bool) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
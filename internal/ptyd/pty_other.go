@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package ptyd
+
+import "os"
+
+// openPTY allocates a pseudo-terminal pair.
+func openPTY() (master, tty *os.File, err error) {
+	return nil, nil, ErrUnsupported
+}
+
+// setWinsize sets the terminal size of a pseudo-terminal.
+func setWinsize(f *os.File, rows, cols int) error {
+	return ErrUnsupported
+}
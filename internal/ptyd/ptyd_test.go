@@ -0,0 +1,186 @@
+package ptyd
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+func startServer(t *testing.T) *Client {
+	t.Helper()
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("pty sessions not supported on " + runtime.GOOS)
+	}
+	socket := filepath.Join(t.TempDir(), "ptyd.sock")
+	srv := NewServer(socket)
+	srv.shell = "/bin/sh"
+	if err := srv.Listen(); err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go func() { _ = srv.Serve() }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return NewClient(socket)
+}
+
+// waitFor polls the session's capture until it contains want.
+func waitFor(t *testing.T, c *Client, session, want string) string {
+	t.Helper()
+	var out string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		var err error
+		out, err = c.CapturePane(session, 0)
+		if err == nil && strings.Contains(out, want) {
+			return out
+		}
+	}
+	t.Fatalf("capture of %s never showed %q; last:\n%s", session, want, out)
+	return ""
+}
+
+func TestServer_SessionLifecycle(t *testing.T) {
+	c := startServer(t)
+	dir := t.TempDir()
+
+	if err := c.NewSessionWithCommand("gt-test-one", dir, "pwd; echo ready; exec cat"); err != nil {
+		t.Fatalf("NewSessionWithCommand: %v", err)
+	}
+	if err := c.NewSessionWithCommand("gt-test-one", dir, "true"); !errors.Is(err, tmux.ErrSessionExists) {
+		t.Errorf("duplicate session err = %v, want ErrSessionExists", err)
+	}
+
+	out := waitFor(t, c, "gt-test-one", "ready")
+	if !strings.Contains(out, filepath.Base(dir)) {
+		t.Errorf("session did not start in %s:\n%s", dir, out)
+	}
+
+	if has, err := c.HasSession("gt-test-one"); err != nil || !has {
+		t.Errorf("HasSession = %v, %v", has, err)
+	}
+	if names, err := c.ListSessions(); err != nil || len(names) != 1 || names[0] != "gt-test-one" {
+		t.Errorf("ListSessions = %v, %v", names, err)
+	}
+	if pid, err := c.GetPanePID("gt-test-one"); err != nil || pid == "0" {
+		t.Errorf("GetPanePID = %q, %v", pid, err)
+	}
+
+	// cat echoes what we type back through the terminal.
+	if err := c.SendKeys("gt-test-one", "hello from gt"); err != nil {
+		t.Fatalf("SendKeys: %v", err)
+	}
+	waitFor(t, c, "gt-test-one", "hello from gt")
+
+	if err := c.KillSessionWithProcesses("gt-test-one"); err != nil {
+		t.Fatalf("KillSessionWithProcesses: %v", err)
+	}
+	if has, _ := c.HasSession("gt-test-one"); has {
+		t.Error("session still exists after kill")
+	}
+	if _, err := c.CapturePane("gt-test-one", 10); !errors.Is(err, tmux.ErrSessionNotFound) {
+		t.Errorf("capture after kill err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestServer_SessionEndsWithProcess(t *testing.T) {
+	c := startServer(t)
+	if err := c.NewSessionWithCommand("gt-test-short", "", "echo bye"); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if has, _ := c.HasSession("gt-test-short"); !has {
+			return
+		}
+	}
+	t.Error("session outlived its process")
+}
+
+func TestServer_CaptureLines(t *testing.T) {
+	c := startServer(t)
+	if err := c.NewSessionWithCommand("gt-test-lines", "", "for i in 1 2 3 4 5; do echo line$i; done; exec cat"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, c, "gt-test-lines", "line5")
+	out, err := c.CapturePane("gt-test-lines", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "line4\nline5" {
+		t.Errorf("CapturePane(2) = %q", out)
+	}
+}
+
+func TestClient_NotRunning(t *testing.T) {
+	c := NewClient(filepath.Join(t.TempDir(), "none.sock"))
+	if has, err := c.HasSession("x"); has || err != nil {
+		t.Errorf("HasSession = %v, %v; want false, nil", has, err)
+	}
+	if names, err := c.ListSessions(); names != nil || err != nil {
+		t.Errorf("ListSessions = %v, %v", names, err)
+	}
+	if err := c.NewSessionWithCommand("x", "", "true"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("NewSessionWithCommand err = %v, want ErrNotRunning", err)
+	}
+	if c.IsAvailable() {
+		t.Error("IsAvailable without a daemon or spawner")
+	}
+}
+
+func TestScrollback(t *testing.T) {
+	s := newScrollback(8)
+	_, _ = s.Write([]byte("abc"))
+	if got := string(s.Bytes()); got != "abc" {
+		t.Errorf("Bytes = %q", got)
+	}
+	_, _ = s.Write([]byte("defghij"))
+	if got := string(s.Bytes()); got != "cdefghij" {
+		t.Errorf("Bytes after wrap = %q", got)
+	}
+	_, _ = s.Write([]byte("0123456789"))
+	if got := string(s.Bytes()); got != "23456789" {
+		t.Errorf("Bytes after oversized write = %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{"plain", "one\r\ntwo\r\n", []string{"one", "two"}},
+		{"colors", "\x1b[1;32mgreen\x1b[0m text\r\n", []string{"green text"}},
+		{"carriage return overwrite", "50%\r100%\r\n", []string{"100%"}},
+		{"backspace", "ab\bc\r\n", []string{"ac"}},
+		{"erase line", "old text\r\x1b[2Knew\r\n", []string{"new"}},
+		{"cursor up redraw", "> draft\r\nstatus\r\n\x1b[2A\x1b[2K> final\r\n", []string{"> final", "status"}},
+		{"title and modes", "\x1b]0;title\x07\x1b[?25lvisible\x1b(B\r\n", []string{"visible"}},
+		{"trailing blank lines", "x\r\n\r\n\r\n", []string{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render([]byte(tt.raw), DefaultRows)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("render(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyBytes(t *testing.T) {
+	for key, want := range map[string]string{
+		"C-c":    "\x03",
+		"C-D":    "\x04",
+		"Enter":  "\r",
+		"Escape": "\x1b",
+		"Down":   "\x1b[B",
+		"hello":  "hello",
+	} {
+		if got := KeyBytes(key); got != want {
+			t.Errorf("KeyBytes(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
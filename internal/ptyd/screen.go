@@ -0,0 +1,232 @@
+package ptyd
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// scrollback is a fixed-size ring buffer holding a session's most recent
+// terminal output.
+type scrollback struct {
+	buf  []byte
+	next int // write position
+	full bool
+}
+
+func newScrollback(size int) *scrollback {
+	return &scrollback{buf: make([]byte, size)}
+}
+
+// Write appends p, overwriting the oldest output once the buffer is full.
+func (s *scrollback) Write(p []byte) (int, error) {
+	n := len(p)
+	if n >= len(s.buf) {
+		copy(s.buf, p[n-len(s.buf):])
+		s.next, s.full = 0, true
+		return n, nil
+	}
+	c := copy(s.buf[s.next:], p)
+	if c < n {
+		s.next = copy(s.buf, p[c:])
+		s.full = true
+	} else if s.next += c; s.next == len(s.buf) {
+		s.next, s.full = 0, true
+	}
+	return n, nil
+}
+
+// Bytes returns the buffered output, oldest first.
+func (s *scrollback) Bytes() []byte {
+	if !s.full {
+		return append([]byte(nil), s.buf[:s.next]...)
+	}
+	out := make([]byte, 0, len(s.buf))
+	out = append(out, s.buf[s.next:]...)
+	return append(out, s.buf[:s.next]...)
+}
+
+// render turns raw terminal output into plain text lines, the way a pane
+// capture would show it. It models a line-oriented terminal: carriage
+// returns, backspace, cursor movement and line erasure are applied, other
+// escape sequences (colors, titles, modes) are dropped. screenRows is the
+// terminal height, used to resolve absolute cursor positions.
+//
+// This is an approximation: it is good enough for agent TUIs that redraw
+// by moving the cursor up and erasing lines, but it is not a full VT100.
+func render(raw []byte, screenRows int) []string {
+	t := &textTerm{lines: [][]rune{nil}, rows: screenRows}
+	for i := 0; i < len(raw); {
+		b := raw[i]
+		switch {
+		case b == 0x1b:
+			i += t.escape(raw[i:])
+			continue
+		case b == '\n':
+			t.moveRow(1)
+		case b == '\r':
+			t.col = 0
+		case b == '\b':
+			if t.col > 0 {
+				t.col--
+			}
+		case b == '\t':
+			t.col = (t.col/8 + 1) * 8
+		case b < 0x20 || b == 0x7f:
+			// Other control characters have no visible effect.
+		default:
+			r, size := utf8.DecodeRune(raw[i:])
+			t.put(r)
+			i += size
+			continue
+		}
+		i++
+	}
+
+	out := make([]string, len(t.lines))
+	for i, l := range t.lines {
+		out[i] = strings.TrimRight(string(l), " ")
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+type textTerm struct {
+	lines    [][]rune
+	row, col int
+	rows     int
+}
+
+func (t *textTerm) put(r rune) {
+	line := t.lines[t.row]
+	for len(line) <= t.col {
+		line = append(line, ' ')
+	}
+	line[t.col] = r
+	t.lines[t.row] = line
+	t.col++
+}
+
+func (t *textTerm) moveRow(n int) {
+	t.row += n
+	if t.row < 0 {
+		t.row = 0
+	}
+	for len(t.lines) <= t.row {
+		t.lines = append(t.lines, nil)
+	}
+}
+
+// screenTop is the first line of the visible screen.
+func (t *textTerm) screenTop() int {
+	if top := len(t.lines) - t.rows; top > 0 {
+		return top
+	}
+	return 0
+}
+
+// escape applies the escape sequence at the start of s and returns its length.
+func (t *textTerm) escape(s []byte) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[':
+		return t.csi(s)
+	case ']', 'P', 'X', '^', '_':
+		// OSC/DCS/SOS/PM/APC strings end with BEL or ST (ESC \).
+		for i := 2; i < len(s); i++ {
+			if s[i] == 0x07 {
+				return i + 1
+			}
+			if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	case '(', ')', '*', '+', '#', '%':
+		// Character set designation takes one more byte.
+		if len(s) < 3 {
+			return len(s)
+		}
+		return 3
+	}
+	return 2
+}
+
+// csi applies a control sequence (ESC [ params final).
+func (t *textTerm) csi(s []byte) int {
+	i := 2
+	for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+		i++
+	}
+	if i >= len(s) {
+		return len(s)
+	}
+	params := string(s[2:i])
+	if strings.HasPrefix(params, "?") || strings.HasPrefix(params, ">") {
+		return i + 1 // private modes
+	}
+	args := strings.Split(params, ";")
+	arg := func(k, def int) int {
+		if k < len(args) {
+			if n, err := strconv.Atoi(args[k]); err == nil {
+				return n
+			}
+		}
+		return def
+	}
+
+	switch s[i] {
+	case 'A':
+		t.moveRow(-arg(0, 1))
+	case 'B', 'E':
+		t.moveRow(arg(0, 1))
+		if s[i] == 'E' {
+			t.col = 0
+		}
+	case 'F':
+		t.moveRow(-arg(0, 1))
+		t.col = 0
+	case 'C':
+		t.col += arg(0, 1)
+	case 'D':
+		if t.col -= arg(0, 1); t.col < 0 {
+			t.col = 0
+		}
+	case 'G':
+		t.col = arg(0, 1) - 1
+	case 'H', 'f':
+		t.row = t.screenTop()
+		t.moveRow(arg(0, 1) - 1)
+		t.col = arg(1, 1) - 1
+	case 'K':
+		line := t.lines[t.row]
+		switch arg(0, 0) {
+		case 0:
+			if t.col < len(line) {
+				t.lines[t.row] = line[:t.col]
+			}
+		case 1:
+			for c := 0; c <= t.col && c < len(line); c++ {
+				line[c] = ' '
+			}
+		case 2:
+			t.lines[t.row] = nil
+		}
+	case 'J':
+		if arg(0, 0) == 0 {
+			if line := t.lines[t.row]; t.col < len(line) {
+				t.lines[t.row] = line[:t.col]
+			}
+			t.lines = t.lines[:t.row+1]
+		}
+		// Clearing the whole screen keeps the lines as scrollback.
+	}
+	if t.col < 0 {
+		t.col = 0
+	}
+	return i + 1
+}
@@ -0,0 +1,413 @@
+// Package ptyd is a tmux-free session supervisor. A small daemon runs each
+// agent under a Go pseudo-terminal, keeps a scrollback ring buffer of its
+// output, and serves attach/detach, send-keys and capture over a unix
+// socket, so Gas Town can run where tmux isn't installed (CI containers,
+// servers). Client implements the same session operations as tmux.Tmux.
+package ptyd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default terminal geometry and scrollback for new sessions.
+const (
+	DefaultRows       = 50
+	DefaultCols       = 200
+	DefaultScrollback = 1 << 20 // bytes per session
+)
+
+// killGrace is how long a session gets to exit after SIGTERM.
+const killGrace = 2 * time.Second
+
+// Error strings shared between server and client.
+const (
+	errExists   = "session already exists"
+	errNotFound = "session not found"
+)
+
+// ErrUnsupported is returned on platforms without pseudo-terminal support.
+var ErrUnsupported = errors.New("pty sessions are not supported on this platform")
+
+// request is one client call. Each connection carries a single request;
+// after a successful attach the connection becomes a raw terminal stream.
+type request struct {
+	Op      string            `json:"op"` // new, has, list, kill, send, capture, attach, setenv, pid, shutdown
+	Session string            `json:"session,omitempty"`
+	Dir     string            `json:"dir,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Data    string            `json:"data,omitempty"` // bytes to write (send) or env value (setenv)
+	Key     string            `json:"key,omitempty"`
+	Lines   int               `json:"lines,omitempty"`
+	Rows    int               `json:"rows,omitempty"`
+	Cols    int               `json:"cols,omitempty"`
+}
+
+type response struct {
+	Error    string   `json:"error,omitempty"`
+	Exists   bool     `json:"exists,omitempty"`
+	Sessions []string `json:"sessions,omitempty"`
+	Output   string   `json:"output,omitempty"`
+	PID      int      `json:"pid,omitempty"`
+}
+
+// Server supervises pty sessions and serves them on a unix socket.
+type Server struct {
+	socket     string
+	shell      string
+	scrollback int
+
+	mu       sync.Mutex
+	sessions map[string]*ptySession
+	listener net.Listener
+}
+
+// NewServer creates a server listening on socket once Listen is called.
+func NewServer(socket string) *Server {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return &Server{
+		socket:     socket,
+		shell:      shell,
+		scrollback: DefaultScrollback,
+		sessions:   make(map[string]*ptySession),
+	}
+}
+
+// Listen opens the socket, replacing a stale one left by a dead daemon.
+func (s *Server) Listen() error {
+	if err := os.MkdirAll(filepath.Dir(s.socket), 0755); err != nil {
+		return err
+	}
+	if conn, err := net.Dial("unix", s.socket); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("ptyd already running on %s", s.socket)
+	}
+	_ = os.Remove(s.socket)
+	ln, err := net.Listen("unix", s.socket)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(s.socket, 0600); err != nil {
+		_ = ln.Close()
+		return err
+	}
+	s.mu.Lock()
+	s.listener = ln
+	s.mu.Unlock()
+	return nil
+}
+
+// Serve accepts connections until Close is called.
+func (s *Server) Serve() error {
+	s.mu.Lock()
+	ln := s.listener
+	s.mu.Unlock()
+	if ln == nil {
+		return errors.New("ptyd: Serve called before Listen")
+	}
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// Close stops accepting connections and kills every session.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	ln := s.listener
+	s.listener = nil
+	sessions := make([]*ptySession, 0, len(s.sessions))
+	for _, p := range s.sessions {
+		sessions = append(sessions, p)
+	}
+	s.mu.Unlock()
+
+	for _, p := range sessions {
+		p.kill()
+	}
+	if ln == nil {
+		return nil
+	}
+	err := ln.Close()
+	_ = os.Remove(s.socket)
+	return err
+}
+
+func (s *Server) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	var req request
+	line, err := r.ReadBytes('\n')
+	if err != nil || json.Unmarshal(line, &req) != nil {
+		_ = conn.Close()
+		return
+	}
+
+	if req.Op == "attach" {
+		s.attach(conn, r, &req)
+		return
+	}
+	resp := s.dispatch(&req)
+	_ = json.NewEncoder(conn).Encode(resp)
+	_ = conn.Close()
+}
+
+func (s *Server) dispatch(req *request) *response {
+	if req.Op == "list" {
+		s.mu.Lock()
+		names := make([]string, 0, len(s.sessions))
+		for name := range s.sessions {
+			names = append(names, name)
+		}
+		s.mu.Unlock()
+		sort.Strings(names)
+		return &response{Sessions: names}
+	}
+	if req.Op == "shutdown" {
+		go func() { _ = s.Close() }()
+		return &response{}
+	}
+	if req.Op == "new" {
+		if err := s.start(req); err != nil {
+			return &response{Error: err.Error()}
+		}
+		return &response{}
+	}
+
+	p := s.get(req.Session)
+	if req.Op == "has" {
+		return &response{Exists: p != nil}
+	}
+	if p == nil {
+		return &response{Error: errNotFound}
+	}
+
+	switch req.Op {
+	case "kill":
+		p.kill()
+	case "send":
+		if _, err := p.pty.Write([]byte(req.Data)); err != nil {
+			return &response{Error: err.Error()}
+		}
+	case "capture":
+		p.mu.Lock()
+		raw := p.out.Bytes()
+		p.mu.Unlock()
+		lines := render(raw, p.rows)
+		if req.Lines > 0 && len(lines) > req.Lines {
+			lines = lines[len(lines)-req.Lines:]
+		}
+		return &response{Output: strings.Join(lines, "\n")}
+	case "setenv":
+		p.mu.Lock()
+		p.env[req.Key] = req.Data
+		p.mu.Unlock()
+	case "pid":
+		return &response{PID: p.cmd.Process.Pid}
+	default:
+		return &response{Error: fmt.Sprintf("unknown op %q", req.Op)}
+	}
+	return &response{}
+}
+
+func (s *Server) get(name string) *ptySession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[name]
+}
+
+// start launches a session running req.Command (or the shell) under a new
+// pseudo-terminal.
+func (s *Server) start(req *request) error {
+	if req.Session == "" {
+		return errors.New("session name required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[req.Session]; ok {
+		return errors.New(errExists)
+	}
+
+	master, tty, err := openPTY()
+	if err != nil {
+		return err
+	}
+	rows, cols := req.Rows, req.Cols
+	if rows <= 0 || cols <= 0 {
+		rows, cols = DefaultRows, DefaultCols
+	}
+	_ = setWinsize(master, rows, cols)
+
+	var cmd *exec.Cmd
+	if req.Command == "" {
+		cmd = exec.Command(s.shell) //nolint:gosec // G204: the user's shell
+	} else {
+		cmd = exec.Command(s.shell, "-c", req.Command) //nolint:gosec // G204: command comes from gt itself
+	}
+	cmd.Dir = req.Dir
+	cmd.Env = sessionEnv(os.Environ(), req.Session)
+	for k, v := range req.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	attachTTY(cmd)
+	if err := cmd.Start(); err != nil {
+		_ = master.Close()
+		_ = tty.Close()
+		return err
+	}
+	_ = tty.Close()
+
+	p := &ptySession{
+		name:    req.Session,
+		cmd:     cmd,
+		pty:     master,
+		rows:    rows,
+		env:     make(map[string]string),
+		out:     newScrollback(s.scrollback),
+		clients: make(map[net.Conn]struct{}),
+		done:    make(chan struct{}),
+	}
+	s.sessions[req.Session] = p
+	go p.pump(func() {
+		s.mu.Lock()
+		if s.sessions[p.name] == p {
+			delete(s.sessions, p.name)
+		}
+		s.mu.Unlock()
+	})
+	return nil
+}
+
+// sessionEnv is the environment of a new session: the daemon's own, minus
+// any tmux variables it was started with, so agents don't think they are
+// inside tmux.
+func sessionEnv(base []string, name string) []string {
+	env := make([]string, 0, len(base)+2)
+	for _, kv := range base {
+		if strings.HasPrefix(kv, "TMUX=") || strings.HasPrefix(kv, "TMUX_PANE=") || strings.HasPrefix(kv, "TERM=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(env, "TERM=xterm-256color", "GT_PTY_SESSION="+name)
+}
+
+// attach streams a session to conn: the scrollback first, then live
+// output. Input from conn goes to the session until conn is closed.
+func (s *Server) attach(conn net.Conn, r *bufio.Reader, req *request) {
+	defer conn.Close()
+	p := s.get(req.Session)
+	if p == nil {
+		_ = json.NewEncoder(conn).Encode(&response{Error: errNotFound})
+		return
+	}
+	if req.Rows > 0 && req.Cols > 0 {
+		_ = setWinsize(p.pty, req.Rows, req.Cols)
+	}
+
+	p.mu.Lock()
+	if p.clients == nil { // exited meanwhile
+		p.mu.Unlock()
+		_ = json.NewEncoder(conn).Encode(&response{Error: errNotFound})
+		return
+	}
+	if err := json.NewEncoder(conn).Encode(&response{}); err == nil {
+		_, _ = conn.Write(p.out.Bytes())
+	}
+	p.clients[conn] = struct{}{}
+	p.mu.Unlock()
+
+	_, _ = io.Copy(p.pty, r)
+
+	p.mu.Lock()
+	delete(p.clients, conn)
+	p.mu.Unlock()
+}
+
+// ptySession is one supervised process and its terminal.
+type ptySession struct {
+	name string
+	cmd  *exec.Cmd
+	pty  *os.File
+	rows int
+
+	mu      sync.Mutex
+	env     map[string]string
+	out     *scrollback
+	clients map[net.Conn]struct{}
+	done    chan struct{}
+}
+
+// pump copies terminal output into the scrollback and to attached clients
+// until the process exits, then calls onExit.
+func (p *ptySession) pump(onExit func()) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := p.pty.Read(buf)
+		if n > 0 {
+			p.mu.Lock()
+			_, _ = p.out.Write(buf[:n])
+			for c := range p.clients {
+				_ = c.SetWriteDeadline(time.Now().Add(time.Second))
+				if _, werr := c.Write(buf[:n]); werr != nil {
+					_ = c.Close()
+					delete(p.clients, c)
+				}
+			}
+			p.mu.Unlock()
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	_ = p.cmd.Wait()
+	_ = p.pty.Close()
+	p.mu.Lock()
+	for c := range p.clients {
+		_ = c.Close()
+	}
+	p.clients = nil
+	p.mu.Unlock()
+	onExit()
+	close(p.done)
+}
+
+// kill terminates the session's process group, forcefully if it doesn't
+// exit within killGrace, and waits for it to be gone.
+func (p *ptySession) kill() {
+	_ = signalGroup(p.cmd.Process.Pid, false)
+	select {
+	case <-p.done:
+		return
+	case <-time.After(killGrace):
+	}
+	_ = signalGroup(p.cmd.Process.Pid, true)
+	select {
+	case <-p.done:
+	case <-time.After(killGrace):
+		// Something still holds the terminal open; stop serving it anyway.
+		_ = p.pty.Close()
+	}
+}
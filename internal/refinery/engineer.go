@@ -10,15 +10,19 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/convoy"
+	"github.com/steveyegge/gastown/internal/forge"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/secrets"
 )
 
 // MergeQueueConfig holds configuration for the merge queue processor.
@@ -52,6 +56,21 @@ type MergeQueueConfig struct {
 
 	// MaxConcurrent is the maximum number of MRs to process concurrently.
 	MaxConcurrent int `json:"max_concurrent"`
+
+	// RequireReview gates merging on an approved code review.
+	RequireReview bool `json:"require_review"`
+
+	// ReviewFormula is the formula handed to reviewer polecats.
+	ReviewFormula string `json:"review_formula"`
+
+	// Landing selects PR-based landing through a forge. Nil pushes directly.
+	Landing *config.LandingConfig `json:"landing,omitempty"`
+
+	// PostMerge verifies the target branch after each merge. Nil skips it.
+	PostMerge *config.PostMergeConfig `json:"post_merge,omitempty"`
+
+	// FreezeWindows are recurring periods when nothing is merged.
+	FreezeWindows []config.FreezeWindow `json:"freeze_windows,omitempty"`
 }
 
 // DefaultMergeQueueConfig returns sensible defaults for merge queue configuration.
@@ -67,6 +86,7 @@ func DefaultMergeQueueConfig() *MergeQueueConfig {
 		RetryFlakyTests:      1,
 		PollInterval:         30 * time.Second,
 		MaxConcurrent:        1,
+		ReviewFormula:        DefaultReviewFormula,
 	}
 }
 
@@ -83,10 +103,12 @@ type MRInfo struct {
 	Priority        int        // Priority (lower = higher priority)
 	AgentBead       string     // Agent bead ID that created this MR
 	RetryCount      int        // Conflict retry count
+	ReviewState     string     // Code review state (when review is required)
 	ConvoyID        string     // Parent convoy ID if part of a convoy
 	ConvoyCreatedAt *time.Time // Convoy creation time
 	CreatedAt       time.Time  // MR creation time
 	BlockedBy       string     // Task ID blocking this MR
+	ScoreAdjust     float64    // Manual ordering adjustment (gt mq bump)
 }
 
 // Engineer is the merge queue processor that polls for ready merge-requests
@@ -99,6 +121,7 @@ type Engineer struct {
 	workDir string
 	output  io.Writer    // Output destination for user-facing messages
 	router  *mail.Router // Mail router for sending protocol messages
+	forge   forge.Forge  // Lazily created for PR-based landing
 
 	// stopCh is used for graceful shutdown
 	stopCh chan struct{}
@@ -110,6 +133,18 @@ func NewEngineer(r *rig.Rig) *Engineer {
 	// Override target branch with rig's configured default branch
 	cfg.TargetBranch = r.DefaultBranch()
 
+	// The review gate is a rig setting (settings/config.json) so that
+	// every command touching the queue agrees on it.
+	if settings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path)); err == nil && settings.MergeQueue != nil {
+		cfg.RequireReview = settings.MergeQueue.RequireReview
+		if settings.MergeQueue.ReviewFormula != "" {
+			cfg.ReviewFormula = settings.MergeQueue.ReviewFormula
+		}
+		cfg.Landing = settings.MergeQueue.Landing
+		cfg.PostMerge = settings.MergeQueue.PostMerge
+		cfg.FreezeWindows = settings.MergeQueue.FreezeWindows
+	}
+
 	// Determine the git working directory for refinery operations.
 	// Prefer refinery/rig worktree, fall back to mayor/rig (legacy architecture).
 	// Using rig.Path directly would find town's .git with rig-named remotes instead of "origin".
@@ -174,6 +209,12 @@ func (e *Engineer) LoadConfig() error {
 		RetryFlakyTests      *int    `json:"retry_flaky_tests"`
 		PollInterval         *string `json:"poll_interval"`
 		MaxConcurrent        *int    `json:"max_concurrent"`
+		RequireReview        *bool   `json:"require_review"`
+		ReviewFormula        *string `json:"review_formula"`
+
+		Landing       *config.LandingConfig   `json:"landing"`
+		PostMerge     *config.PostMergeConfig `json:"post_merge"`
+		FreezeWindows []config.FreezeWindow   `json:"freeze_windows"`
 	}
 
 	if err := json.Unmarshal(rawConfig.MergeQueue, &mqRaw); err != nil {
@@ -208,6 +249,21 @@ func (e *Engineer) LoadConfig() error {
 	if mqRaw.MaxConcurrent != nil {
 		e.config.MaxConcurrent = *mqRaw.MaxConcurrent
 	}
+	if mqRaw.RequireReview != nil {
+		e.config.RequireReview = *mqRaw.RequireReview
+	}
+	if mqRaw.ReviewFormula != nil && *mqRaw.ReviewFormula != "" {
+		e.config.ReviewFormula = *mqRaw.ReviewFormula
+	}
+	if mqRaw.Landing != nil {
+		e.config.Landing = mqRaw.Landing
+	}
+	if mqRaw.PostMerge != nil {
+		e.config.PostMerge = mqRaw.PostMerge
+	}
+	if mqRaw.FreezeWindows != nil {
+		e.config.FreezeWindows = mqRaw.FreezeWindows
+	}
 	if mqRaw.PollInterval != nil {
 		dur, err := time.ParseDuration(*mqRaw.PollInterval)
 		if err != nil {
@@ -231,6 +287,48 @@ type ProcessResult struct {
 	Error       string
 	Conflict    bool
 	TestsFailed bool
+
+	// SecretsFound is set when the secret scan blocked the merge.
+	// Error lists the offending file:line locations.
+	SecretsFound bool
+
+	// ScanFailed is set when the secret scan could not run. The work is
+	// not at fault, so the MR is retried rather than sent back.
+	ScanFailed bool
+
+	// NeedsReview is set when the rig requires review and the MR has no
+	// current approval. Not a failure of the work - nothing is sent back.
+	NeedsReview bool
+
+	// LandingPending is set when the MR's landing PR (LandingPR) is open
+	// but not merged yet. Not a failure - the PR is checked on a later pass.
+	LandingPending bool
+	LandingPR      int
+
+	// LandingFailed is set when PR landing stalled (forge errors, timeout).
+	// The work is not at fault, so the MR is retried rather than sent back.
+	LandingFailed bool
+}
+
+// FailureType classifies a failed result for routing.
+func (r ProcessResult) FailureType() FailureType {
+	switch {
+	case r.Success, r.NeedsReview, r.LandingPending:
+		// Waiting on a reviewer or a landing PR is not a failure
+		return FailureNone
+	case r.Conflict:
+		return FailureConflict
+	case r.TestsFailed:
+		return FailureTestsFail
+	case r.SecretsFound:
+		return FailureSecretsFound
+	case r.ScanFailed:
+		return FailureScanError
+	case r.LandingFailed:
+		return FailureLandingError
+	default:
+		return FailureBuildFail
+	}
 }
 
 // ProcessMR processes a single merge request from a beads issue.
@@ -250,7 +348,60 @@ func (e *Engineer) ProcessMR(ctx context.Context, mr *beads.Issue) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Target: %s\n", mrFields.Target)
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mrFields.Worker)
 
-	return e.doMerge(ctx, mrFields.Branch, mrFields.Target, mrFields.SourceIssue)
+	if result := e.checkReview(mr.ID); result != nil {
+		return *result
+	}
+
+	return e.mergeMR(ctx, mr.ID, mrFields.Branch, mrFields.Target, mrFields.SourceIssue)
+}
+
+// mergeMR runs doMerge for an MR. In PR landing mode, an MR whose landing PR
+// is still open from an earlier pass is checked instead of being squashed
+// and pushed again, and the open PR is recorded on (or cleared from) the MR
+// bead.
+func (e *Engineer) mergeMR(ctx context.Context, mrID, branch, target, sourceIssue string) ProcessResult {
+	if !e.config.Landing.IsPR() {
+		return e.doMerge(ctx, branch, target, sourceIssue)
+	}
+
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not read MR %s: %v\n", mrID, err)
+		return e.doMerge(ctx, branch, target, sourceIssue)
+	}
+	fields := beads.ParseMRFields(issue)
+
+	var result ProcessResult
+	if fields != nil && fields.LandingPR > 0 {
+		since, _ := time.Parse(time.RFC3339, fields.LandingSince)
+		result = e.resumeLanding(ctx, fields.LandingPR, target, since)
+	} else {
+		result = e.doMerge(ctx, branch, target, sourceIssue)
+	}
+	e.recordLanding(issue, fields, result)
+	return result
+}
+
+// recordLanding keeps the MR bead's landing_pr in step with the result:
+// set while a PR is pending, cleared once the attempt is over.
+func (e *Engineer) recordLanding(issue *beads.Issue, fields *beads.MRFields, result ProcessResult) {
+	if fields == nil {
+		return
+	}
+	switch {
+	case result.LandingPending && fields.LandingPR != result.LandingPR:
+		fields.LandingPR = result.LandingPR
+		fields.LandingSince = time.Now().UTC().Format(time.RFC3339)
+	case !result.LandingPending && fields.LandingPR > 0:
+		fields.LandingPR = 0
+		fields.LandingSince = ""
+	default:
+		return
+	}
+	newDesc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(issue.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record landing PR on MR %s: %v\n", issue.ID, err)
+	}
 }
 
 // doMerge performs the actual git merge operation.
@@ -319,6 +470,28 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		_, _ = fmt.Fprintln(e.output, "[Engineer] Tests passed")
 	}
 
+	// Step 4.5: Scan the branch for secrets. This runs before anything is
+	// merged, so a rejected branch leaves the target untouched and nothing
+	// reaches the remote. The allowlist is the one committed on the target,
+	// so a branch cannot allowlist its own leak.
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Scanning %s for secrets...\n", branch)
+	findings, err := secrets.ScanRange(e.workDir, target, branch)
+	if err != nil {
+		return ProcessResult{
+			Success:    false,
+			ScanFailed: true,
+			Error:      fmt.Sprintf("secret scan failed: %v", err),
+		}
+	}
+	if len(findings) > 0 {
+		return ProcessResult{
+			Success:      false,
+			SecretsFound: true,
+			Error: fmt.Sprintf("possible secrets added (remove and rotate them, or allowlist in %s):\n%s",
+				secrets.AllowlistFile, secrets.Summary(findings, 10)),
+		}
+	}
+
 	// Step 5: Perform the actual merge using squash merge
 	// Get the original commit message from the polecat branch to preserve the
 	// conventional commit format (feat:/fix:) instead of creating redundant merge commits
@@ -359,7 +532,11 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}
 	}
 
-	// Step 7: Push to origin
+	// Step 7: Land on origin - directly, or through a PR where the target
+	// branch is protected
+	if e.config.Landing.IsPR() {
+		return e.landViaPR(ctx, branch, target, sourceIssue, originalMsg)
+	}
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing to origin/%s...\n", target)
 	if err := e.git.Push("origin", target, false); err != nil {
 		return ProcessResult{
@@ -430,6 +607,7 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 // 3. Close source issue with reference to MR
 // 4. Delete source branch if configured
 // 5. Log success
+// 6. Verify the target branch if a post-merge check is configured
 func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 	// Parse MR fields from description
 	mrFields := beads.ParseMRFields(mr)
@@ -492,21 +670,66 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 
 	// 5. Log success
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
+
+	// 6. Verify the target branch still passes with the merge in it
+	e.verifyAfterMerge(&MRInfo{
+		ID:          mr.ID,
+		Branch:      mrFields.Branch,
+		Target:      mrFields.Target,
+		SourceIssue: mrFields.SourceIssue,
+		Worker:      mrFields.Worker,
+	}, result.MergeCommit)
 }
 
 // handleFailure handles a failed merge request.
 // Reopens the MR for rework and logs the failure.
 func (e *Engineer) handleFailure(mr *beads.Issue, result ProcessResult) {
+	// Awaiting review is not a failure of the work - leave the MR as it is
+	if result.NeedsReview {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s awaiting review: %s\n", mr.ID, result.Error)
+		return
+	}
+
+	// Likewise a landing PR that is still open - it is checked on a later pass
+	if result.LandingPending {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s landing: %s\n", mr.ID, result.Error)
+		return
+	}
+
 	// Reopen the MR (back to open status for rework)
 	open := "open"
 	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Status: &open}); err != nil {
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to reopen MR %s: %v\n", mr.ID, err)
 	}
 
+	e.recordFailure(mr.ID, result.FailureType())
+
 	// Log the failure
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ Failed: %s - %s\n", mr.ID, result.Error)
 }
 
+// recordFailure stores the type and time of a failed merge attempt on the
+// MR bead, for status displays (gt mq status, the dashboard).
+func (e *Engineer) recordFailure(mrID string, failure FailureType) {
+	if failure == FailureNone {
+		return
+	}
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		return
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		return
+	}
+	fields.LastFailure = string(failure)
+	fields.LastFailureAt = time.Now().UTC().Format(time.RFC3339)
+	newDesc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record failure on MR %s: %v\n", mrID, err)
+	}
+}
+
 // ProcessMRInfo processes a merge request from MRInfo.
 func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult {
 	// MR fields are directly on the struct
@@ -516,12 +739,19 @@ func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mr.Worker)
 	_, _ = fmt.Fprintf(e.output, "  Source: %s\n", mr.SourceIssue)
 
+	// Review gate: re-read the bead, since approvals can change after listing
+	if result := e.checkReview(mr.ID); result != nil {
+		return *result
+	}
+
 	// Use the shared merge logic
-	return e.doMerge(ctx, mr.Branch, mr.Target, mr.SourceIssue)
+	return e.mergeMR(ctx, mr.ID, mr.Branch, mr.Target, mr.SourceIssue)
 }
 
 // HandleMRInfoSuccess handles a successful merge from MRInfo.
-func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
+// Returns false if the post-merge check then failed on the target branch:
+// the merge was reverted (unless configured not to) and the worker told.
+func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) bool {
 	// Release merge slot if this was a conflict resolution
 	// The slot is held while conflict resolution is in progress
 	holder := e.rig.Name + "/refinery"
@@ -598,19 +828,47 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 
 	// 3. Log success
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
+
+	// 4. Verify the target branch still passes with the merge in it
+	return e.verifyAfterMerge(mr, result.MergeCommit)
 }
 
 // HandleMRInfoFailure handles a failed merge from MRInfo.
 // For conflicts, creates a resolution task and blocks the MR until resolved.
 // This enables non-blocking delegation: the queue continues to the next MR.
 func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
+	// Awaiting review is not a failure of the work - leave the MR queued
+	if result.NeedsReview {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s awaiting review: %s\n", mr.ID, result.Error)
+		return
+	}
+
+	// Likewise a landing PR that is still open - it is checked on a later pass
+	if result.LandingPending {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s landing: %s\n", mr.ID, result.Error)
+		return
+	}
+
+	// Infrastructure failures (the secret scan could not run, PR landing
+	// stalled) are not the worker's to fix - record them and retry without
+	// MERGE_FAILED
+	if failure := result.FailureType(); !failure.ShouldAssignToWorker() {
+		e.recordFailure(mr.ID, failure)
+		_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ Failed: %s - %s\n", mr.ID, result.Error)
+		_, _ = fmt.Fprintln(e.output, "[Engineer] MR remains in queue for retry")
+		return
+	}
+
 	// Notify Witness of the failure so polecat can be alerted
 	// Determine failure type from result
 	failureType := "build"
-	if result.Conflict {
+	switch result.FailureType() {
+	case FailureConflict:
 		failureType = "conflict"
-	} else if result.TestsFailed {
+	case FailureTestsFail:
 		failureType = "tests"
+	case FailureSecretsFound:
+		failureType = "secrets"
 	}
 	msg := protocol.NewMergeFailedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, failureType, result.Error)
 	if err := e.router.Send(msg); err != nil {
@@ -619,6 +877,8 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 		fmt.Fprintf(e.output, "[Engineer] Notified witness of merge failure for %s\n", mr.Worker)
 	}
 
+	e.recordFailure(mr.ID, result.FailureType())
+
 	// If this was a conflict, create a conflict-resolution task for dispatch
 	// and block the MR until the task is resolved (non-blocking delegation)
 	if result.Conflict {
@@ -776,10 +1036,17 @@ func (e *Engineer) IsBeadOpen(beadID string) (bool, error) {
 // ListReadyMRs returns MRs that are ready for processing:
 // - Not claimed by another worker (checked via assignee field)
 // - Not blocked by an open task (handled by bd ready)
-// Sorted by priority (highest first).
+// - Approved, if the rig requires review
+// Sorted by score (highest first). Nothing is ready while the queue is
+// paused or frozen.
 //
 // This queries beads for merge-request wisps.
 func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
+	now := time.Now()
+	if e.QueueHold(now) != nil {
+		return nil, nil
+	}
+
 	// Query beads for ready merge-request issues
 	issues, err := e.beads.ReadyWithType("merge-request")
 	if err != nil {
@@ -805,41 +1072,71 @@ func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
 			continue
 		}
 
-		// Parse convoy created_at if present
-		var convoyCreatedAt *time.Time
-		if fields.ConvoyCreatedAt != "" {
-			if t, err := time.Parse(time.RFC3339, fields.ConvoyCreatedAt); err == nil {
-				convoyCreatedAt = &t
-			}
+		// Review gate: unapproved MRs are not ready
+		if e.config.RequireReview && !e.IsApproved(fields) {
+			continue
 		}
 
-		// Parse issue created_at
-		var createdAt time.Time
-		if issue.CreatedAt != "" {
-			if t, err := time.Parse(time.RFC3339, issue.CreatedAt); err == nil {
-				createdAt = t
-			}
+		mrs = append(mrs, mrInfoFromIssue(issue, fields))
+	}
+
+	sort.SliceStable(mrs, func(i, j int) bool {
+		return mrs[i].ScoreAt(now) > mrs[j].ScoreAt(now)
+	})
+	return mrs, nil
+}
+
+// GetMRInfo loads an open MR bead as an MRInfo.
+func (e *Engineer) GetMRInfo(mrID string) (*MRInfo, error) {
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		return nil, fmt.Errorf("loading MR %s: %w", mrID, err)
+	}
+	if issue.Status != "open" {
+		return nil, fmt.Errorf("MR %s is %s", mrID, issue.Status)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		return nil, fmt.Errorf("%s has no MR fields", mrID)
+	}
+	return mrInfoFromIssue(issue, fields), nil
+}
+
+// mrInfoFromIssue builds an MRInfo from an MR bead and its parsed fields.
+func mrInfoFromIssue(issue *beads.Issue, fields *beads.MRFields) *MRInfo {
+	// Parse convoy created_at if present
+	var convoyCreatedAt *time.Time
+	if fields.ConvoyCreatedAt != "" {
+		if t, err := time.Parse(time.RFC3339, fields.ConvoyCreatedAt); err == nil {
+			convoyCreatedAt = &t
 		}
+	}
 
-		mr := &MRInfo{
-			ID:              issue.ID,
-			Branch:          fields.Branch,
-			Target:          fields.Target,
-			SourceIssue:     fields.SourceIssue,
-			Worker:          fields.Worker,
-			Rig:             fields.Rig,
-			Title:           issue.Title,
-			Priority:        issue.Priority,
-			AgentBead:       fields.AgentBead,
-			RetryCount:      fields.RetryCount,
-			ConvoyID:        fields.ConvoyID,
-			ConvoyCreatedAt: convoyCreatedAt,
-			CreatedAt:       createdAt,
+	// Parse issue created_at
+	var createdAt time.Time
+	if issue.CreatedAt != "" {
+		if t, err := time.Parse(time.RFC3339, issue.CreatedAt); err == nil {
+			createdAt = t
 		}
-		mrs = append(mrs, mr)
 	}
 
-	return mrs, nil
+	return &MRInfo{
+		ID:              issue.ID,
+		Branch:          fields.Branch,
+		Target:          fields.Target,
+		SourceIssue:     fields.SourceIssue,
+		Worker:          fields.Worker,
+		Rig:             fields.Rig,
+		Title:           issue.Title,
+		Priority:        issue.Priority,
+		AgentBead:       fields.AgentBead,
+		RetryCount:      fields.RetryCount,
+		ReviewState:     fields.ReviewState,
+		ConvoyID:        fields.ConvoyID,
+		ConvoyCreatedAt: convoyCreatedAt,
+		CreatedAt:       createdAt,
+		ScoreAdjust:     fields.ScoreAdjust,
+	}
 }
 
 // ListBlockedMRs returns MRs that are blocked by open tasks.
@@ -2,11 +2,14 @@ package refinery
 
 import (
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/rig"
 )
 
@@ -215,3 +218,113 @@ func TestEngineer_DeleteMergedBranchesConfig(t *testing.T) {
 		t.Error("expected DeleteMergedBranches to be true by default")
 	}
 }
+
+func TestEngineer_LoadConfig_RequireReview(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := map[string]interface{}{
+		"type":    "rig",
+		"version": 1,
+		"name":    "test-rig",
+		"merge_queue": map[string]interface{}{
+			"require_review": true,
+		},
+	}
+	data, _ := json.MarshalIndent(config, "", "  ")
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if e.ReviewRequired() {
+		t.Error("expected review not required by default")
+	}
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	if !e.ReviewRequired() {
+		t.Error("expected review required after loading config")
+	}
+	if e.config.ReviewFormula != DefaultReviewFormula {
+		t.Errorf("expected ReviewFormula %q, got %q", DefaultReviewFormula, e.config.ReviewFormula)
+	}
+}
+
+func TestEngineer_ReviewStatus(t *testing.T) {
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: t.TempDir()})
+
+	tests := []struct {
+		name   string
+		fields *beads.MRFields
+		want   string
+	}{
+		{"no fields", nil, "needed"},
+		{"no review yet", &beads.MRFields{Branch: "polecat/Nux/gt-1"}, "needed"},
+		{"pending", &beads.MRFields{ReviewState: ReviewPending}, ReviewPending},
+		{"changes requested", &beads.MRFields{ReviewState: ReviewChangesRequested}, ReviewChangesRequested},
+		{"approved unpinned", &beads.MRFields{ReviewState: ReviewApproved}, "stale"},
+		// Branch head cannot be resolved outside a repo: fail closed.
+		{"approved pinned, head unknown", &beads.MRFields{ReviewState: ReviewApproved, Branch: "polecat/Nux/gt-1", ReviewedSHA: "abc123"}, "stale"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.ReviewStatus(tt.fields); got != tt.want {
+				t.Errorf("ReviewStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsWorker(t *testing.T) {
+	tests := []struct {
+		reviewer, worker string
+		want             bool
+	}{
+		{"gastown/polecats/Nux", "Nux", true},
+		{"gastown/polecats/nux", "Nux", true},
+		{"gastown/polecats/Toast", "Nux", false},
+		{"overseer", "Nux", false},
+		{"", "Nux", false},
+		{"gastown/polecats/Nux", "", false},
+	}
+	for _, tt := range tests {
+		if got := isWorker(tt.reviewer, tt.worker); got != tt.want {
+			t.Errorf("isWorker(%q, %q) = %v, want %v", tt.reviewer, tt.worker, got, tt.want)
+		}
+	}
+}
+
+// stubBD puts a bd on PATH that logs its arguments and succeeds without
+// output. Returns the log path.
+func stubBD(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("bd stub is a shell script")
+	}
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "bd.log")
+	script := "#!/bin/sh\necho \"$*\" >> \"" + logPath + "\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "bd"), []byte(script), 0755); err != nil {
+		t.Fatalf("write bd stub: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+func TestHandleFailure_AwaitingReview(t *testing.T) {
+	logPath := stubBD(t)
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: t.TempDir()})
+	e.SetOutput(io.Discard)
+
+	result := ProcessResult{NeedsReview: true, Error: "review required (state: pending)"}
+	if got := result.FailureType(); got != FailureNone {
+		t.Errorf("FailureType() = %q, want none for an MR awaiting review", got)
+	}
+
+	mr := &beads.Issue{ID: "gt-mr1", Status: "open"}
+	e.handleFailure(mr, result)
+
+	// Neither reopened nor marked failed: no bd writes at all
+	if log, err := os.ReadFile(logPath); err == nil && len(log) > 0 {
+		t.Errorf("handleFailure touched the MR bead while it awaits review:\n%s", log)
+	}
+}
@@ -0,0 +1,254 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains merge queue holds: pauses and freeze windows.
+
+package refinery
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/wisp"
+)
+
+// Wisp config keys for a paused merge queue. A pause is local, per-rig
+// state (like a parked rig): while set, ListReadyMRs reports nothing.
+const (
+	QueuePausedKey       = "mq_paused"        // why the queue is paused
+	QueuePausedByKey     = "mq_paused_by"     // who paused it
+	QueuePausedAtKey     = "mq_paused_at"     // when (RFC3339)
+	QueuePausedTargetKey = "mq_paused_target" // branch that must pass to resume
+)
+
+// FrozenByWindow is QueuePause.By for a hold from a freeze window.
+const FrozenByWindow = "freeze-window"
+
+// QueuePause describes a merge queue that is not merging: a pause set by
+// gt mq pause or a failed post-merge check, or a freeze window in effect.
+type QueuePause struct {
+	Reason string    `json:"reason"`
+	By     string    `json:"by,omitempty"`
+	At     time.Time `json:"at,omitempty"`
+	Target string    `json:"target,omitempty"`
+
+	// Until is when a freeze window ends. Zero for pauses, which last
+	// until resumed.
+	Until time.Time `json:"until,omitempty"`
+}
+
+// IsFreeze reports whether the hold comes from a freeze window.
+func (p *QueuePause) IsFreeze() bool {
+	return p.By == FrozenByWindow
+}
+
+// Describe is a one-line summary for status displays.
+func (p *QueuePause) Describe() string {
+	if p.IsFreeze() {
+		return fmt.Sprintf("frozen until %s: %s", p.Until.Format("Mon 15:04"), p.Reason)
+	}
+	if p.By != "" {
+		return fmt.Sprintf("paused by %s: %s", p.By, p.Reason)
+	}
+	return "paused: " + p.Reason
+}
+
+// GetQueuePause returns the rig's queue pause, or nil if the queue runs.
+func GetQueuePause(townRoot, rigName string) *QueuePause {
+	cfg := wisp.NewConfig(townRoot, rigName)
+	reason := cfg.GetString(QueuePausedKey)
+	if reason == "" {
+		return nil
+	}
+	p := &QueuePause{
+		Reason: reason,
+		By:     cfg.GetString(QueuePausedByKey),
+		Target: cfg.GetString(QueuePausedTargetKey),
+	}
+	p.At, _ = time.Parse(time.RFC3339, cfg.GetString(QueuePausedAtKey))
+	return p
+}
+
+// PauseQueue pauses the rig's merge queue. target, if set, is the branch a
+// post-merge pause waits on.
+func PauseQueue(townRoot, rigName, reason, by, target string) error {
+	if reason == "" {
+		return fmt.Errorf("pause reason is required")
+	}
+	cfg := wisp.NewConfig(townRoot, rigName)
+	values := map[string]string{
+		QueuePausedByKey:     by,
+		QueuePausedAtKey:     time.Now().UTC().Format(time.RFC3339),
+		QueuePausedTargetKey: target,
+		// Written last: the reason is what marks the queue paused.
+		QueuePausedKey: reason,
+	}
+	for _, key := range []string{QueuePausedByKey, QueuePausedAtKey, QueuePausedTargetKey, QueuePausedKey} {
+		if values[key] == "" {
+			if err := cfg.Unset(key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := cfg.Set(key, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResumeQueue lifts a queue pause. Resuming a running queue is a no-op.
+func ResumeQueue(townRoot, rigName string) error {
+	cfg := wisp.NewConfig(townRoot, rigName)
+	for _, key := range []string{QueuePausedKey, QueuePausedByKey, QueuePausedAtKey, QueuePausedTargetKey} {
+		if err := cfg.Unset(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueuePause returns the engineer's rig queue pause, or nil.
+func (e *Engineer) QueuePause() *QueuePause {
+	if e.rig == nil {
+		return nil
+	}
+	return GetQueuePause(filepath.Dir(e.rig.Path), e.rig.Name)
+}
+
+// QueueHold returns the rig's queue hold at now: a pause if one is set,
+// otherwise a freeze window in effect. Nil means the queue is merging.
+func QueueHold(townRoot, rigName string, windows []config.FreezeWindow, now time.Time) *QueuePause {
+	if p := GetQueuePause(townRoot, rigName); p != nil {
+		return p
+	}
+	w, until := ActiveFreeze(windows, now)
+	if w == nil {
+		return nil
+	}
+	reason := w.Reason
+	if reason == "" {
+		reason = "freeze window"
+	}
+	return &QueuePause{Reason: reason, By: FrozenByWindow, Until: until}
+}
+
+// QueueHold returns the engineer's rig queue hold at now, or nil.
+func (e *Engineer) QueueHold(now time.Time) *QueuePause {
+	if e.rig == nil {
+		return nil
+	}
+	return QueueHold(filepath.Dir(e.rig.Path), e.rig.Name, e.config.FreezeWindows, now)
+}
+
+// ActiveFreeze returns the first freeze window in effect at now, and when
+// it ends. Invalid windows are skipped; see ValidateFreezeWindows.
+func ActiveFreeze(windows []config.FreezeWindow, now time.Time) (*config.FreezeWindow, time.Time) {
+	for i := range windows {
+		w := &windows[i]
+		loc := time.Local
+		if w.Timezone != "" {
+			l, err := time.LoadLocation(w.Timezone)
+			if err != nil {
+				continue
+			}
+			loc = l
+		}
+		local := now.In(loc)
+		today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+		// A window that runs past midnight may have started yesterday.
+		for _, day := range []time.Time{today, today.AddDate(0, 0, -1)} {
+			start, end, err := freezeSpan(w, day)
+			if err != nil {
+				break
+			}
+			if !freezeOnDay(w.Days, day.Weekday()) {
+				continue
+			}
+			if !local.Before(start) && local.Before(end) {
+				return w, end
+			}
+		}
+	}
+	return nil, time.Time{}
+}
+
+// ValidateFreezeWindows reports the first malformed freeze window.
+func ValidateFreezeWindows(windows []config.FreezeWindow) error {
+	for i := range windows {
+		w := &windows[i]
+		if w.Timezone != "" {
+			if _, err := time.LoadLocation(w.Timezone); err != nil {
+				return fmt.Errorf("freeze window %d: bad timezone %q", i+1, w.Timezone)
+			}
+		}
+		for _, d := range w.Days {
+			if _, ok := parseWeekday(d); !ok {
+				return fmt.Errorf("freeze window %d: unknown day %q", i+1, d)
+			}
+		}
+		if _, _, err := freezeSpan(w, time.Now()); err != nil {
+			return fmt.Errorf("freeze window %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// freezeSpan returns when w starts and ends if it starts on day (midnight).
+func freezeSpan(w *config.FreezeWindow, day time.Time) (time.Time, time.Time, error) {
+	start, err := parseClock(w.Start, 0)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseClock(w.End, 24*time.Hour)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if end <= start {
+		end += 24 * time.Hour
+	}
+	return day.Add(start), day.Add(end), nil
+}
+
+// parseClock parses "HH:MM" as an offset from midnight.
+func parseClock(s string, def time.Duration) (time.Duration, error) {
+	switch s {
+	case "":
+		return def, nil
+	case "24:00":
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("bad time %q (want HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func freezeOnDay(days []string, wd time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if w, ok := parseWeekday(d); ok && w == wd {
+			return true
+		}
+	}
+	return false
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 3 {
+		return 0, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if strings.HasPrefix(name, s) {
+			return d, true
+		}
+	}
+	return 0, false
+}
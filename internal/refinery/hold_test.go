@@ -0,0 +1,148 @@
+package refinery
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestActiveFreeze(t *testing.T) {
+	utc := time.UTC
+	fridays := []config.FreezeWindow{{Days: []string{"fri"}, Start: "16:00", Timezone: "UTC", Reason: "no Friday merges"}}
+	overnight := []config.FreezeWindow{{Days: []string{"Friday"}, Start: "22:00", End: "06:00", Timezone: "UTC"}}
+
+	tests := []struct {
+		name      string
+		windows   []config.FreezeWindow
+		now       time.Time
+		wantHeld  bool
+		wantUntil time.Time
+	}{
+		// 2026-10-16 is a Friday.
+		{"friday before window", fridays, time.Date(2026, 10, 16, 15, 59, 0, 0, utc), false, time.Time{}},
+		{"friday in window", fridays, time.Date(2026, 10, 16, 16, 0, 0, 0, utc), true, time.Date(2026, 10, 17, 0, 0, 0, 0, utc)},
+		{"saturday", fridays, time.Date(2026, 10, 17, 10, 0, 0, 0, utc), false, time.Time{}},
+		{"overnight, friday night", overnight, time.Date(2026, 10, 16, 23, 0, 0, 0, utc), true, time.Date(2026, 10, 17, 6, 0, 0, 0, utc)},
+		{"overnight, saturday morning", overnight, time.Date(2026, 10, 17, 5, 59, 0, 0, utc), true, time.Date(2026, 10, 17, 6, 0, 0, 0, utc)},
+		{"overnight, saturday night", overnight, time.Date(2026, 10, 17, 23, 0, 0, 0, utc), false, time.Time{}},
+		{"every day", []config.FreezeWindow{{Start: "12:00", End: "13:00", Timezone: "UTC"}}, time.Date(2026, 10, 14, 12, 30, 0, 0, utc), true, time.Date(2026, 10, 14, 13, 0, 0, 0, utc)},
+		{"invalid window skipped", []config.FreezeWindow{{Start: "4pm"}}, time.Date(2026, 10, 16, 17, 0, 0, 0, utc), false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, until := ActiveFreeze(tt.windows, tt.now)
+			if (w != nil) != tt.wantHeld {
+				t.Fatalf("held = %v, want %v", w != nil, tt.wantHeld)
+			}
+			if !until.Equal(tt.wantUntil) {
+				t.Errorf("until = %v, want %v", until, tt.wantUntil)
+			}
+		})
+	}
+}
+
+func TestActiveFreeze_Timezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no tzdata")
+	}
+	windows := []config.FreezeWindow{{Days: []string{"fri"}, Start: "16:00", Timezone: "Europe/Berlin"}}
+
+	// 15:30 UTC is 17:30 in Berlin (CEST).
+	now := time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC)
+	w, until := ActiveFreeze(windows, now)
+	if w == nil {
+		t.Fatal("expected the Berlin window to be active")
+	}
+	if want := time.Date(2026, 10, 17, 0, 0, 0, 0, berlin); !until.Equal(want) {
+		t.Errorf("until = %v, want %v", until, want)
+	}
+}
+
+func TestValidateFreezeWindows(t *testing.T) {
+	good := []config.FreezeWindow{{Days: []string{"sat", "Sunday"}}, {Start: "09:00", End: "24:00"}}
+	if err := ValidateFreezeWindows(good); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, bad := range []config.FreezeWindow{
+		{Days: []string{"fr"}},
+		{Days: []string{"funday"}},
+		{Start: "16"},
+		{End: "25:00"},
+		{Timezone: "Mars/Olympus"},
+	} {
+		if err := ValidateFreezeWindows([]config.FreezeWindow{bad}); err == nil {
+			t.Errorf("expected an error for %+v", bad)
+		}
+	}
+}
+
+func TestQueueHold(t *testing.T) {
+	town := t.TempDir()
+	friday := time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC)
+	windows := []config.FreezeWindow{{Days: []string{"fri"}, Start: "16:00", Timezone: "UTC"}}
+
+	if h := QueueHold(town, "gastown", nil, friday); h != nil {
+		t.Fatalf("unexpected hold: %+v", h)
+	}
+
+	h := QueueHold(town, "gastown", windows, friday)
+	if h == nil || !h.IsFreeze() || h.Reason != "freeze window" {
+		t.Fatalf("freeze hold = %+v", h)
+	}
+	if got := h.Describe(); !strings.HasPrefix(got, "frozen until Sat 00:00") {
+		t.Errorf("Describe = %q", got)
+	}
+
+	// A pause wins over a freeze window and survives it.
+	if err := PauseQueue(town, "gastown", "release day", "mayor", ""); err != nil {
+		t.Fatal(err)
+	}
+	h = QueueHold(town, "gastown", windows, friday)
+	if h == nil || h.IsFreeze() || h.Describe() != "paused by mayor: release day" {
+		t.Fatalf("pause hold = %+v", h)
+	}
+	if h := QueueHold(town, "other", nil, friday); h != nil {
+		t.Errorf("pause leaked to another rig: %+v", h)
+	}
+
+	if err := ResumeQueue(town, "gastown"); err != nil {
+		t.Fatal(err)
+	}
+	if h := QueueHold(town, "gastown", nil, friday); h != nil {
+		t.Errorf("hold after resume: %+v", h)
+	}
+}
+
+func TestListReadyMRs_Frozen(t *testing.T) {
+	cfg := DefaultMergeQueueConfig()
+	cfg.FreezeWindows = []config.FreezeWindow{{}} // all day, every day
+	e := &Engineer{rig: &rig.Rig{Name: "testrig", Path: filepath.Join(t.TempDir(), "testrig")}, config: cfg}
+
+	// Frozen: answered without touching beads.
+	ready, err := e.ListReadyMRs()
+	if err != nil || len(ready) != 0 {
+		t.Errorf("ListReadyMRs while frozen = %v, %v", ready, err)
+	}
+}
+
+func TestEngineer_LoadConfig_FreezeWindows(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := []byte(`{"merge_queue": {"freeze_windows": [{"days": ["fri"], "start": "16:00", "reason": "weekend"}]}}`)
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	if len(e.config.FreezeWindows) != 1 || e.config.FreezeWindows[0].Reason != "weekend" {
+		t.Errorf("FreezeWindows = %+v", e.config.FreezeWindows)
+	}
+}
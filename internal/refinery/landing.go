@@ -0,0 +1,237 @@
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/forge"
+)
+
+// Landing defaults, used when the rig's landing config leaves them unset.
+const (
+	DefaultLandingBranchPrefix = "gt/land/"
+	DefaultLandingMergeMethod  = "squash"
+	DefaultLandingTimeout      = time.Hour
+)
+
+// landViaPR lands the squash commit at HEAD through a pull request, for rigs
+// whose target branch refuses direct pushes. The commit is pushed to a
+// landing branch, a PR into target is opened (or refreshed), and the PR is
+// merged through the forge if its checks have already passed.
+//
+// Results map onto the direct-push ones so the MERGED / MERGE_FAILED
+// protocol is unchanged: failed checks are TestsFailed and an unmergeable
+// PR is a Conflict. A PR still waiting on checks is LandingPending, and is
+// picked up again by resumeLanding on a later pass rather than waited on
+// here. Forge errors are LandingFailed. The local target branch never keeps
+// the squash commit; it is realigned with origin either way.
+func (e *Engineer) landViaPR(ctx context.Context, branch, target, sourceIssue, message string) ProcessResult {
+	f, err := e.forgeClient()
+	if err != nil {
+		return ProcessResult{Success: false, LandingFailed: true, Error: fmt.Sprintf("PR landing: %v", err)}
+	}
+	defer e.syncTarget(target)
+
+	landing := landingBranch(e.config.Landing.BranchPrefix, branch, sourceIssue)
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing to origin/%s for PR landing...\n", landing)
+	if err := e.git.Push("origin", "HEAD:refs/heads/"+landing, true); err != nil {
+		return ProcessResult{Success: false, LandingFailed: true, Error: fmt.Sprintf("failed to push landing branch: %v", err)}
+	}
+
+	title, body := landingPRText(message, branch, sourceIssue)
+	pr, err := f.EnsurePR(ctx, landing, target, title, body)
+	if err != nil {
+		return ProcessResult{Success: false, LandingFailed: true, Error: fmt.Sprintf("failed to open %s PR: %v", f.Name(), err)}
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] PR #%d open: %s\n", pr.Number, pr.URL)
+
+	return e.settlePR(ctx, f, pr, target)
+}
+
+// resumeLanding checks on a landing PR opened by an earlier pass, without
+// pushing anything. Forge errors leave the PR pending; once it has been
+// open longer than the landing timeout the attempt is LandingFailed, and
+// the next pass lands the MR afresh.
+func (e *Engineer) resumeLanding(ctx context.Context, number int, target string, since time.Time) ProcessResult {
+	f, err := e.forgeClient()
+	if err != nil {
+		return ProcessResult{Success: false, LandingFailed: true, Error: fmt.Sprintf("PR landing: %v", err)}
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking landing PR #%d...\n", number)
+	var result ProcessResult
+	pr, err := f.GetPR(ctx, number)
+	if err != nil {
+		result = ProcessResult{Success: false, LandingPending: true, LandingPR: number, Error: fmt.Sprintf("checking PR #%d: %v", number, err)}
+	} else {
+		result = e.settlePR(ctx, f, pr, target)
+		if result.Success {
+			e.syncTarget(target)
+		}
+	}
+
+	timeout := parseLandingDuration(e.config.Landing.Timeout, DefaultLandingTimeout)
+	if result.LandingPending && time.Since(since) > timeout {
+		return ProcessResult{
+			Success:       false,
+			LandingFailed: true,
+			Error:         fmt.Sprintf("timed out after %s: %s", timeout, result.Error),
+		}
+	}
+	return result
+}
+
+// settlePR runs checkPR and tidies up after a finished attempt: the landing
+// branch is deleted and a missing merge commit is read from origin.
+func (e *Engineer) settlePR(ctx context.Context, f forge.Forge, pr *forge.PullRequest, target string) ProcessResult {
+	result := e.checkPR(ctx, f, pr)
+	if result.Success || result.Conflict || result.TestsFailed {
+		// Done with this attempt either way; a resubmitted MR opens a fresh PR.
+		// Pending and stalled attempts keep the branch so the PR stays open.
+		_ = e.git.DeleteRemoteBranch("origin", pr.Head)
+	}
+	if result.Success && result.MergeCommit == "" {
+		if err := e.git.Fetch("origin"); err == nil {
+			result.MergeCommit, _ = e.git.Rev("origin/" + target)
+		}
+	}
+	if result.Success {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Successfully merged via PR #%d: %s\n", pr.Number, shortSHA(result.MergeCommit))
+	}
+	return result
+}
+
+// checkPR looks at a PR once. If its checks are green it asks the forge to
+// merge; a refusal (approvals still missing, mergeability still computing)
+// or a forge error leaves the PR pending.
+func (e *Engineer) checkPR(ctx context.Context, f forge.Forge, pr *forge.PullRequest) ProcessResult {
+	method := e.config.Landing.MergeMethod
+	if method == "" {
+		method = DefaultLandingMergeMethod
+	}
+
+	waiting := "checks"
+	switch {
+	case pr.State == forge.PRMerged:
+		return ProcessResult{Success: true, MergeCommit: pr.MergeCommit}
+	case pr.State == forge.PRClosed:
+		return ProcessResult{Success: false, Error: fmt.Sprintf("PR #%d was closed without merging: %s", pr.Number, pr.URL)}
+	case pr.Mergeable == forge.MergeConflict:
+		return ProcessResult{Success: false, Conflict: true, Error: fmt.Sprintf("PR #%d has conflicts with the target branch: %s", pr.Number, pr.URL)}
+	case pr.Checks == forge.ChecksFailure:
+		return ProcessResult{Success: false, TestsFailed: true, Error: fmt.Sprintf("checks failed on PR #%d: %s", pr.Number, pr.URL)}
+	case pr.Checks == forge.ChecksSuccess:
+		sha, err := f.MergePR(ctx, pr, method)
+		if err == nil {
+			return ProcessResult{Success: true, MergeCommit: sha}
+		}
+		waiting = err.Error()
+		if !forge.IsNotMergeable(err) {
+			waiting = "merge failed: " + waiting
+		}
+	}
+
+	return ProcessResult{
+		Success:        false,
+		LandingPending: true,
+		LandingPR:      pr.Number,
+		Error:          fmt.Sprintf("PR #%d waiting (%s): %s", pr.Number, waiting, pr.URL),
+	}
+}
+
+// forgeClient returns the rig's forge, creating it on first use. The repo
+// path defaults to the one in the origin remote URL.
+func (e *Engineer) forgeClient() (forge.Forge, error) {
+	if e.forge != nil {
+		return e.forge, nil
+	}
+	lc := e.config.Landing
+	if lc.Forge == "" {
+		return nil, fmt.Errorf("landing mode %q needs a forge (github, gitlab or gitea)", lc.Mode)
+	}
+
+	repo := lc.Repo
+	if repo == "" {
+		remote, err := e.git.RemoteURL("origin")
+		if err != nil {
+			return nil, fmt.Errorf("reading origin URL: %w", err)
+		}
+		if repo, err = forge.RepoFromRemoteURL(remote); err != nil {
+			return nil, err
+		}
+	}
+
+	tokenEnv := lc.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = forge.DefaultTokenEnv(lc.Forge)
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("no %s token: set $%s", lc.Forge, tokenEnv)
+	}
+
+	f, err := forge.New(forge.Config{Kind: lc.Forge, APIURL: lc.APIURL, Repo: repo, Token: token})
+	if err != nil {
+		return nil, err
+	}
+	e.forge = f
+	return f, nil
+}
+
+// syncTarget resets the local target branch to origin's, dropping the local
+// squash commit that PR landing never pushes directly.
+func (e *Engineer) syncTarget(target string) {
+	if err := e.git.Fetch("origin"); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: fetch after PR landing failed: %v\n", err)
+	}
+	if err := e.git.ResetHard("origin/" + target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not reset %s to origin: %v\n", target, err)
+	}
+}
+
+// landingBranch names the branch a merge request is landed from:
+// <prefix><issue>, or <prefix><branch> when there is no source issue.
+func landingBranch(prefix, branch, sourceIssue string) string {
+	if prefix == "" {
+		prefix = DefaultLandingBranchPrefix
+	}
+	name := sourceIssue
+	if name == "" {
+		name = strings.TrimPrefix(branch, "polecat/")
+	}
+	return prefix + name
+}
+
+// landingPRText builds the PR title (the squash commit's subject) and body.
+func landingPRText(message, branch, sourceIssue string) (string, string) {
+	message = strings.TrimSpace(message)
+	title, rest, _ := strings.Cut(message, "\n")
+
+	var b strings.Builder
+	b.WriteString("Landed by the Gas Town refinery.\n\n")
+	fmt.Fprintf(&b, "Branch: `%s`\n", branch)
+	if sourceIssue != "" {
+		fmt.Fprintf(&b, "Issue: %s\n", sourceIssue)
+	}
+	if rest = strings.TrimSpace(rest); rest != "" {
+		b.WriteString("\n" + rest + "\n")
+	}
+	return title, b.String()
+}
+
+func parseLandingDuration(s string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
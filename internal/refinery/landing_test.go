@@ -0,0 +1,262 @@
+package refinery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/forge"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// fakeForge serves a scripted sequence of PR states and merges by moving
+// the bare origin's target branch to the landing branch.
+type fakeForge struct {
+	origin     string
+	head, base string
+	states     []forge.PullRequest
+	getErr     error
+	merges     int
+	polls      int
+}
+
+func (f *fakeForge) Name() string { return "fake" }
+
+func (f *fakeForge) EnsurePR(_ context.Context, head, base, _, _ string) (*forge.PullRequest, error) {
+	f.head, f.base = head, base
+	pr := f.states[0]
+	pr.Head, pr.Base = head, base
+	return &pr, nil
+}
+
+func (f *fakeForge) GetPR(_ context.Context, _ int) (*forge.PullRequest, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	f.polls++
+	i := f.polls
+	if i >= len(f.states) {
+		i = len(f.states) - 1
+	}
+	pr := f.states[i]
+	pr.Head, pr.Base = f.head, f.base
+	return &pr, nil
+}
+
+func (f *fakeForge) MergePR(_ context.Context, pr *forge.PullRequest, _ string) (string, error) {
+	f.merges++
+	out, err := exec.Command("git", "--git-dir="+f.origin, "rev-parse", "refs/heads/"+pr.Head).Output()
+	if err != nil {
+		return "", err
+	}
+	sha := strings.TrimSpace(string(out))
+	if err := exec.Command("git", "--git-dir="+f.origin, "update-ref", "refs/heads/"+pr.Base, sha).Run(); err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setupLanding returns an engineer whose work clone has an unpushed commit
+// on main, as doMerge leaves it just before landing.
+func setupLanding(t *testing.T, ff *fakeForge) (*Engineer, string, string) {
+	t.Helper()
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "Test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "test@example.com")
+	}
+
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	work := filepath.Join(tmp, "work")
+	runGit(t, tmp, "init", "--bare", "-b", "main", origin)
+	runGit(t, tmp, "clone", origin, work)
+	runGit(t, work, "checkout", "-b", "main")
+	runGit(t, work, "commit", "--allow-empty", "-m", "initial")
+	runGit(t, work, "push", "origin", "main")
+	runGit(t, work, "commit", "--allow-empty", "-m", "feat: squashed work")
+
+	ff.origin = origin
+	cfg := DefaultMergeQueueConfig()
+	cfg.Landing = &config.LandingConfig{Mode: config.LandingModePR, Forge: "github", Timeout: "5s"}
+	e := &Engineer{git: git.NewGit(work), config: cfg, workDir: work, output: io.Discard, forge: ff}
+	return e, origin, work
+}
+
+func TestLandViaPR_MergesWhenChecksPass(t *testing.T) {
+	ff := &fakeForge{states: []forge.PullRequest{
+		{Number: 1, State: forge.PROpen, Checks: forge.ChecksPending, Mergeable: forge.MergeUnknown},
+		{Number: 1, State: forge.PROpen, Checks: forge.ChecksSuccess, Mergeable: forge.MergeClean},
+	}}
+	e, origin, work := setupLanding(t, ff)
+	squash := runGit(t, work, "rev-parse", "HEAD")
+	before := runGit(t, origin, "rev-parse", "main")
+
+	// Checks still running: the PR is left pending, not waited on
+	result := e.landViaPR(context.Background(), "polecat/Nux/gt-abc", "main", "gt-abc", "feat: squashed work")
+	if !result.LandingPending || result.LandingPR != 1 {
+		t.Fatalf("expected PR #1 pending, got %+v", result)
+	}
+	if result.FailureType() != FailureNone {
+		t.Errorf("FailureType = %s, want none", result.FailureType())
+	}
+	if got := runGit(t, work, "rev-parse", "HEAD"); got != before {
+		t.Errorf("local main not reset to origin: %s != %s", got, before)
+	}
+	if out := runGit(t, origin, "branch", "--list", "gt/land/*"); out == "" {
+		t.Error("landing branch deleted while the PR is pending")
+	}
+
+	// A later pass finds the checks green and merges
+	result = e.resumeLanding(context.Background(), result.LandingPR, "main", time.Now())
+	if !result.Success {
+		t.Fatalf("resumeLanding failed: %+v", result)
+	}
+	if ff.merges != 1 {
+		t.Errorf("merges = %d, want 1", ff.merges)
+	}
+	if result.MergeCommit != squash {
+		t.Errorf("MergeCommit = %s, want %s", result.MergeCommit, squash)
+	}
+	if got := runGit(t, origin, "rev-parse", "main"); got != squash {
+		t.Errorf("origin main = %s, want %s", got, squash)
+	}
+	if got := runGit(t, work, "rev-parse", "HEAD"); got != squash {
+		t.Errorf("local main = %s, want origin's %s", got, squash)
+	}
+	if out := runGit(t, origin, "branch", "--list", "gt/land/*"); out != "" {
+		t.Errorf("landing branch not deleted: %q", out)
+	}
+}
+
+func TestResumeLanding_ForgeErrorsAndTimeout(t *testing.T) {
+	ff := &fakeForge{states: []forge.PullRequest{
+		{Number: 1, State: forge.PROpen, Checks: forge.ChecksPending, Mergeable: forge.MergeUnknown},
+	}}
+	e, _, _ := setupLanding(t, ff)
+
+	// A forge error within the timeout keeps the PR pending
+	ff.getErr = errors.New("502 bad gateway")
+	result := e.resumeLanding(context.Background(), 1, "main", time.Now())
+	if !result.LandingPending || result.LandingPR != 1 {
+		t.Errorf("expected PR #1 pending after forge error, got %+v", result)
+	}
+
+	// Past the timeout the attempt is retried, not sent back to the worker
+	ff.getErr = nil
+	result = e.resumeLanding(context.Background(), 1, "main", time.Now().Add(-time.Minute))
+	if result.LandingPending || result.FailureType() != FailureLandingError {
+		t.Errorf("expected landing_error after timeout, got %s (%+v)", result.FailureType(), result)
+	}
+	if result.FailureType().ShouldAssignToWorker() {
+		t.Error("landing timeout assigned to the worker")
+	}
+}
+
+func TestLandViaPR_FailedChecks(t *testing.T) {
+	ff := &fakeForge{states: []forge.PullRequest{
+		{Number: 1, State: forge.PROpen, Checks: forge.ChecksFailure, Mergeable: forge.MergeClean},
+	}}
+	e, origin, work := setupLanding(t, ff)
+	before := runGit(t, origin, "rev-parse", "main")
+
+	result := e.landViaPR(context.Background(), "polecat/Nux/gt-abc", "main", "gt-abc", "feat: squashed work")
+	if result.Success || !result.TestsFailed {
+		t.Fatalf("expected TestsFailed, got %+v", result)
+	}
+	if result.FailureType() != FailureTestsFail {
+		t.Errorf("FailureType = %s", result.FailureType())
+	}
+	if ff.merges != 0 {
+		t.Errorf("merges = %d, want 0", ff.merges)
+	}
+	if got := runGit(t, work, "rev-parse", "HEAD"); got != before {
+		t.Errorf("local main not reset to origin: %s != %s", got, before)
+	}
+}
+
+func TestLandViaPR_Conflict(t *testing.T) {
+	ff := &fakeForge{states: []forge.PullRequest{
+		{Number: 1, State: forge.PROpen, Checks: forge.ChecksPending, Mergeable: forge.MergeConflict},
+	}}
+	e, _, _ := setupLanding(t, ff)
+
+	result := e.landViaPR(context.Background(), "polecat/Nux/gt-abc", "main", "gt-abc", "feat: squashed work")
+	if result.FailureType() != FailureConflict {
+		t.Errorf("FailureType = %s, want conflict (%+v)", result.FailureType(), result)
+	}
+}
+
+func TestLandingBranchAndText(t *testing.T) {
+	if got := landingBranch("", "polecat/Nux/gt-abc", "gt-abc"); got != "gt/land/gt-abc" {
+		t.Errorf("landingBranch = %q", got)
+	}
+	if got := landingBranch("land/", "polecat/Nux", ""); got != "land/Nux" {
+		t.Errorf("landingBranch = %q", got)
+	}
+
+	title, body := landingPRText("feat: add widgets\n\nLonger explanation.", "polecat/Nux/gt-abc", "gt-abc")
+	if title != "feat: add widgets" {
+		t.Errorf("title = %q", title)
+	}
+	for _, want := range []string{"polecat/Nux/gt-abc", "Issue: gt-abc", "Longer explanation."} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestEngineer_LoadConfig_Landing(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := []byte(`{"merge_queue": {"landing": {"mode": "pr", "forge": "gitlab", "timeout": "10m"}}}`)
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if e.config.Landing.IsPR() {
+		t.Error("expected direct push by default")
+	}
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	if !e.config.Landing.IsPR() || e.config.Landing.Forge != "gitlab" || e.config.Landing.Timeout != "10m" {
+		t.Errorf("Landing = %+v", e.config.Landing)
+	}
+}
+
+func TestHandleFailure_LandingPending(t *testing.T) {
+	logPath := stubBD(t)
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: t.TempDir()})
+	e.SetOutput(io.Discard)
+
+	result := ProcessResult{LandingPending: true, LandingPR: 7, Error: "PR #7 checks pending"}
+	e.handleFailure(&beads.Issue{ID: "gt-mr1", Status: "open"}, result)
+
+	// A PR still running checks is not a failure: the MR is neither
+	// reopened nor marked failed
+	if log, err := os.ReadFile(logPath); err == nil && len(log) > 0 {
+		t.Errorf("handleFailure touched the MR bead while its PR is pending:\n%s", log)
+	}
+}
@@ -0,0 +1,238 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/protocol"
+)
+
+// PausedByPostMerge marks pauses set by a failing post-merge check. They
+// are lifted automatically once the target branch passes again.
+const PausedByPostMerge = "refinery/post-merge"
+
+// DefaultPostMergeTimeout bounds one run of the post-merge command.
+const DefaultPostMergeTimeout = 10 * time.Minute
+
+// postMergeLogLines is how much of a failing check's output is kept.
+const postMergeLogLines = 40
+
+// FailurePostMerge is the MERGE_FAILED failure type for merges reverted
+// after the post-merge check failed.
+const FailurePostMerge = "post-merge"
+
+// verifyAfterMerge runs the post-merge check on the target branch once a
+// merge has landed. If the target no longer passes, the queue is paused,
+// the merge is reverted (unless configured not to), the source issue is
+// reopened with the failure log, and the witness is told so the worker
+// hears about it. A revert that brings the target back to green lifts the
+// pause straight away. Returns false if the check failed.
+func (e *Engineer) verifyAfterMerge(mr *MRInfo, mergeCommit string) bool {
+	pm := e.config.PostMerge
+	if !pm.Enabled() || mergeCommit == "" {
+		return true
+	}
+	target := mr.Target
+	if target == "" {
+		target = e.config.TargetBranch
+	}
+	ctx := context.Background()
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Post-merge check on %s: %s\n", target, pm.Command)
+	if err := e.checkoutOrigin(target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: skipping post-merge check: %v\n", err)
+		return true
+	}
+	log, err := e.runPostMergeCheck(ctx)
+	if err == nil {
+		_, _ = fmt.Fprintln(e.output, "[Engineer] Post-merge check passed")
+		return true
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ Post-merge check failed on %s after %s: %v\n", target, mr.ID, err)
+
+	reason := fmt.Sprintf("post-merge check failed on %s after %s (%s)", target, mr.ID, shortSHA(mergeCommit))
+	if perr := PauseQueue(filepath.Dir(e.rig.Path), e.rig.Name, reason, PausedByPostMerge, target); perr != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to pause merge queue: %v\n", perr)
+	} else {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Merge queue paused until %s passes\n", target)
+	}
+
+	var revert string
+	if !pm.NoRevert {
+		sha, rerr := e.revertMerge(target, mergeCommit)
+		if rerr != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not revert %s: %v\n", shortSHA(mergeCommit), rerr)
+		} else {
+			revert = sha
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Reverted %s in %s\n", shortSHA(mergeCommit), shortSHA(revert))
+		}
+	}
+
+	e.reopenSourceIssue(mr, target, mergeCommit, revert, err, log)
+
+	summary := fmt.Sprintf("%q failed on %s after merge %s: %v", pm.Command, target, shortSHA(mergeCommit), err)
+	if revert != "" {
+		summary += fmt.Sprintf(" (reverted in %s)", shortSHA(revert))
+	}
+	if mr.SourceIssue != "" {
+		summary += fmt.Sprintf("; log on %s", mr.SourceIssue)
+	}
+	msg := protocol.NewMergeFailedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, target, FailurePostMerge, summary)
+	if e.router != nil {
+		if serr := e.router.Send(msg); serr != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGE_FAILED to witness: %v\n", serr)
+		} else {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Notified witness of post-merge failure for %s\n", mr.Worker)
+		}
+	}
+	_ = events.LogFeed(events.TypeMergeReverted, e.rig.Name+"/refinery", events.MergePayload(mr.ID, mr.Worker, mr.Branch, reason))
+
+	if revert != "" {
+		if resumed, rerr := e.RecheckQueuePause(ctx); rerr != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: post-merge recheck failed: %v\n", rerr)
+		} else if resumed {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] %s passes after the revert; merge queue resumed\n", target)
+		}
+	}
+	return false
+}
+
+// RecheckQueuePause re-runs the post-merge check when the queue was paused
+// by a failing one, and resumes the queue if the target branch passes.
+// Returns whether the queue is running afterwards. Pauses set for other
+// reasons are left alone.
+func (e *Engineer) RecheckQueuePause(ctx context.Context) (bool, error) {
+	p := e.QueuePause()
+	if p == nil {
+		return true, nil
+	}
+	if p.By != PausedByPostMerge || !e.config.PostMerge.Enabled() {
+		return false, nil
+	}
+	target := p.Target
+	if target == "" {
+		target = e.config.TargetBranch
+	}
+	if err := e.checkoutOrigin(target); err != nil {
+		return false, err
+	}
+	if _, err := e.runPostMergeCheck(ctx); err != nil {
+		return false, nil
+	}
+	if err := ResumeQueue(filepath.Dir(e.rig.Path), e.rig.Name); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// checkoutOrigin checks out target at origin's head.
+func (e *Engineer) checkoutOrigin(target string) error {
+	if err := e.git.Fetch("origin"); err != nil {
+		return fmt.Errorf("fetching origin: %w", err)
+	}
+	if err := e.git.Checkout(target); err != nil {
+		return fmt.Errorf("checking out %s: %w", target, err)
+	}
+	if err := e.git.ResetHard("origin/" + target); err != nil {
+		return fmt.Errorf("resetting %s to origin: %w", target, err)
+	}
+	return nil
+}
+
+// runPostMergeCheck runs the post-merge command in the work tree and
+// returns the tail of its combined output.
+func (e *Engineer) runPostMergeCheck(ctx context.Context) (string, error) {
+	pm := e.config.PostMerge
+	timeout := parseLandingDuration(pm.Timeout, DefaultPostMergeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Like TestCommand, the command comes from trusted rig config.
+	cmd := exec.CommandContext(ctx, "sh", "-c", pm.Command) //nolint:gosec // G204: command is from trusted rig config
+	cmd.Dir = e.workDir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	return tailLines(out.String(), postMergeLogLines), err
+}
+
+// revertMerge pushes a revert of mergeCommit straight to target. Reverts
+// skip PR landing even on rigs that land via PRs: putting a broken target
+// right can't wait on the checks it is failing, so such rigs must let the
+// refinery push reverts. On any failure the local target is reset to
+// origin's. Returns the revert commit.
+func (e *Engineer) revertMerge(target, mergeCommit string) (string, error) {
+	if err := e.git.Revert(mergeCommit); err != nil {
+		_ = e.git.ResetHard("origin/" + target)
+		return "", err
+	}
+	revert, err := e.git.Rev("HEAD")
+	if err != nil {
+		_ = e.git.ResetHard("origin/" + target)
+		return "", err
+	}
+	if err := e.git.Push("origin", target, false); err != nil {
+		_ = e.git.ResetHard("origin/" + target)
+		return "", fmt.Errorf("pushing revert: %w", err)
+	}
+	return revert, nil
+}
+
+// reopenSourceIssue puts a reverted merge's source issue back to open,
+// with the failing check's output appended to its description.
+func (e *Engineer) reopenSourceIssue(mr *MRInfo, target, mergeCommit, revert string, checkErr error, log string) {
+	if mr.SourceIssue == "" || e.beads == nil {
+		return
+	}
+	issue, err := e.beads.Show(mr.SourceIssue)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to fetch source issue %s: %v\n", mr.SourceIssue, err)
+		return
+	}
+
+	open := "open"
+	desc := strings.TrimRight(issue.Description, "\n") + "\n\n" + postMergeNote(mr.ID, target, mergeCommit, revert, checkErr, log)
+	if err := e.beads.Update(mr.SourceIssue, beads.UpdateOptions{Status: &open, Description: &desc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to reopen source issue %s: %v\n", mr.SourceIssue, err)
+		return
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Reopened source issue: %s\n", mr.SourceIssue)
+}
+
+// postMergeNote is the section appended to a reopened source issue.
+func postMergeNote(mrID, target, mergeCommit, revert string, checkErr error, log string) string {
+	var b strings.Builder
+	b.WriteString("## Post-merge check failed\n\n")
+	fmt.Fprintf(&b, "Merged in %s (%s), then the check on %s failed: %v\n", mrID, shortSHA(mergeCommit), target, checkErr)
+	if revert != "" {
+		fmt.Fprintf(&b, "Reverted in %s. Fix the failure and resubmit.\n", shortSHA(revert))
+	} else {
+		b.WriteString("Not reverted: the failing commit is still on the target branch.\n")
+	}
+	if log = strings.TrimSpace(log); log != "" {
+		b.WriteString("\n```\n" + log + "\n```\n")
+	}
+	return b.String()
+}
+
+// tailLines returns the last n lines of s.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,244 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// setupPostMerge returns an engineer whose origin main has just received
+// a merge that deletes the file the post-merge check looks for. The test
+// runs inside the engineer's town, so events are logged there.
+func setupPostMerge(t *testing.T, pm *config.PostMergeConfig) (*Engineer, string, string, string) {
+	t.Helper()
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "Test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "test@example.com")
+	}
+
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	work := filepath.Join(tmp, "work")
+	town := filepath.Join(tmp, "town")
+	runGit(t, tmp, "init", "--bare", "-b", "main", origin)
+	runGit(t, tmp, "clone", origin, work)
+	runGit(t, work, "checkout", "-b", "main")
+	if err := os.WriteFile(filepath.Join(work, "ok"), []byte("ok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, work, "add", "ok")
+	runGit(t, work, "commit", "-m", "initial")
+	runGit(t, work, "push", "origin", "main")
+	runGit(t, work, "rm", "-q", "ok")
+	runGit(t, work, "commit", "-m", "feat: break main")
+	runGit(t, work, "push", "origin", "main")
+	merge := runGit(t, work, "rev-parse", "HEAD")
+
+	if err := os.MkdirAll(filepath.Join(town, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	if err := os.Chdir(town); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultMergeQueueConfig()
+	cfg.PostMerge = pm
+	e := &Engineer{
+		rig:     &rig.Rig{Name: "testrig", Path: filepath.Join(town, "testrig")},
+		git:     git.NewGit(work),
+		config:  cfg,
+		workDir: work,
+		output:  io.Discard,
+	}
+	return e, origin, work, merge
+}
+
+func TestVerifyAfterMerge_RevertsAndResumes(t *testing.T) {
+	e, origin, work, merge := setupPostMerge(t, &config.PostMergeConfig{Command: "test -f ok"})
+
+	if e.verifyAfterMerge(&MRInfo{ID: "gt-mr1", Target: "main", Worker: "Nux"}, merge) {
+		t.Error("verifyAfterMerge() = true, want false for a failing check")
+	}
+
+	head := runGit(t, origin, "rev-parse", "main")
+	if head == merge {
+		t.Fatal("origin main still at the failing merge; expected a revert")
+	}
+	if msg := runGit(t, origin, "log", "-1", "--format=%s", "main"); !strings.HasPrefix(msg, "Revert ") {
+		t.Errorf("origin main head = %q, want a revert commit", msg)
+	}
+	if _, err := os.Stat(filepath.Join(work, "ok")); err != nil {
+		t.Errorf("revert did not restore the file: %v", err)
+	}
+	if p := e.QueuePause(); p != nil {
+		t.Errorf("queue still paused after main went green: %+v", p)
+	}
+	feed, err := os.ReadFile(filepath.Join(filepath.Dir(e.rig.Path), events.EventsFile))
+	if err != nil || !strings.Contains(string(feed), events.TypeMergeReverted) {
+		t.Errorf("no %s event in the town's feed: %v\n%s", events.TypeMergeReverted, err, feed)
+	}
+}
+
+func TestRevertMerge_BypassesPRLanding(t *testing.T) {
+	e, origin, work, merge := setupPostMerge(t, &config.PostMergeConfig{Command: "test -f ok"})
+	// No forge token: landing through a PR would fail
+	e.config.Landing = &config.LandingConfig{Mode: config.LandingModePR, Forge: "github", TokenEnv: "GT_TEST_NO_TOKEN"}
+
+	revert, err := e.revertMerge("main", merge)
+	if err != nil {
+		t.Fatalf("revertMerge: %v", err)
+	}
+	if got := runGit(t, origin, "rev-parse", "main"); got != revert {
+		t.Errorf("origin main = %s, want the revert %s", got, revert)
+	}
+	if _, err := os.Stat(filepath.Join(work, "ok")); err != nil {
+		t.Errorf("revert did not restore the file: %v", err)
+	}
+}
+
+func TestRevertMerge_FailureResetsToOrigin(t *testing.T) {
+	e, origin, work, merge := setupPostMerge(t, &config.PostMergeConfig{Command: "test -f ok"})
+	// Recreate the deleted file differently, so reverting the deletion conflicts
+	if err := os.WriteFile(filepath.Join(work, "ok"), []byte("different\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, work, "add", "ok")
+	runGit(t, work, "commit", "-m", "feat: new ok")
+	runGit(t, work, "push", "origin", "main")
+	head := runGit(t, origin, "rev-parse", "main")
+
+	if _, err := e.revertMerge("main", merge); err == nil {
+		t.Fatal("expected the revert to conflict")
+	}
+	if got := runGit(t, work, "rev-parse", "HEAD"); got != head {
+		t.Errorf("local main = %s, want origin's %s", got, head)
+	}
+	if out := runGit(t, work, "status", "--porcelain"); out != "" {
+		t.Errorf("work tree not clean after a failed revert:\n%s", out)
+	}
+}
+
+func TestVerifyAfterMerge_NoRevertPausesUntilGreen(t *testing.T) {
+	e, origin, work, merge := setupPostMerge(t, &config.PostMergeConfig{Command: "test -f ok", NoRevert: true})
+
+	if e.verifyAfterMerge(&MRInfo{ID: "gt-mr1", Target: "main", Worker: "Nux"}, merge) {
+		t.Error("verifyAfterMerge() = true, want false for a failing check")
+	}
+
+	if got := runGit(t, origin, "rev-parse", "main"); got != merge {
+		t.Errorf("origin main = %s, want untouched %s", got, merge)
+	}
+	p := e.QueuePause()
+	if p == nil {
+		t.Fatal("expected the queue to be paused")
+	}
+	if p.By != PausedByPostMerge || p.Target != "main" || !strings.Contains(p.Reason, "gt-mr1") {
+		t.Errorf("pause = %+v", p)
+	}
+	if ready, err := e.ListReadyMRs(); err != nil || len(ready) != 0 {
+		t.Errorf("ListReadyMRs while paused = %v, %v", ready, err)
+	}
+
+	// Still red: the pause holds.
+	if resumed, err := e.RecheckQueuePause(context.Background()); err != nil || resumed {
+		t.Fatalf("RecheckQueuePause on red main = %v, %v", resumed, err)
+	}
+
+	// Someone fixes main by hand.
+	if err := os.WriteFile(filepath.Join(work, "ok"), []byte("ok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, work, "add", "ok")
+	runGit(t, work, "commit", "-m", "fix: restore ok")
+	runGit(t, work, "push", "origin", "main")
+
+	if resumed, err := e.RecheckQueuePause(context.Background()); err != nil || !resumed {
+		t.Fatalf("RecheckQueuePause on green main = %v, %v", resumed, err)
+	}
+	if p := e.QueuePause(); p != nil {
+		t.Errorf("queue still paused: %+v", p)
+	}
+}
+
+func TestVerifyAfterMerge_Passing(t *testing.T) {
+	e, origin, _, merge := setupPostMerge(t, &config.PostMergeConfig{Command: "true"})
+
+	if !e.verifyAfterMerge(&MRInfo{ID: "gt-mr1", Target: "main"}, merge) {
+		t.Error("verifyAfterMerge() = false, want true for a passing check")
+	}
+
+	if got := runGit(t, origin, "rev-parse", "main"); got != merge {
+		t.Errorf("origin main = %s, want %s", got, merge)
+	}
+	if p := e.QueuePause(); p != nil {
+		t.Errorf("unexpected pause: %+v", p)
+	}
+}
+
+func TestRecheckQueuePause_LeavesManualPause(t *testing.T) {
+	e, _, _, _ := setupPostMerge(t, &config.PostMergeConfig{Command: "true"})
+	town := filepath.Dir(e.rig.Path)
+	if err := PauseQueue(town, e.rig.Name, "release freeze", "mayor", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if resumed, err := e.RecheckQueuePause(context.Background()); err != nil || resumed {
+		t.Errorf("RecheckQueuePause = %v, %v; manual pauses must stay", resumed, err)
+	}
+	if p := GetQueuePause(town, e.rig.Name); p == nil || p.Reason != "release freeze" || p.By != "mayor" {
+		t.Errorf("pause = %+v", p)
+	}
+
+	if err := ResumeQueue(town, e.rig.Name); err != nil {
+		t.Fatal(err)
+	}
+	if p := GetQueuePause(town, e.rig.Name); p != nil {
+		t.Errorf("pause after resume = %+v", p)
+	}
+}
+
+func TestPostMergeNote(t *testing.T) {
+	note := postMergeNote("gt-mr1", "main", "0123456789abcdef", "fedcba9876543210", io.ErrUnexpectedEOF, "line 1\nFAIL: TestThing\n")
+	for _, want := range []string{"## Post-merge check failed", "gt-mr1", "01234567", "Reverted in fedcba98", "FAIL: TestThing"} {
+		if !strings.Contains(note, want) {
+			t.Errorf("note missing %q:\n%s", want, note)
+		}
+	}
+	if got := tailLines("a\nb\nc\n", 2); got != "b\nc" {
+		t.Errorf("tailLines = %q", got)
+	}
+}
+
+func TestEngineer_LoadConfig_PostMerge(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := []byte(`{"merge_queue": {"post_merge": {"command": "make smoke", "timeout": "5m"}}}`)
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if e.config.PostMerge.Enabled() {
+		t.Error("expected no post-merge check by default")
+	}
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	if !e.config.PostMerge.Enabled() || e.config.PostMerge.Command != "make smoke" || e.config.PostMerge.Timeout != "5m" {
+		t.Errorf("PostMerge = %+v", e.config.PostMerge)
+	}
+}
@@ -0,0 +1,393 @@
+package refinery
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/protocol"
+)
+
+// Review states recorded on MR beads (review_state field).
+const (
+	ReviewPending          = "pending"
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes_requested"
+)
+
+// DefaultReviewFormula is the formula reviewer polecats run on review tasks.
+const DefaultReviewFormula = "mol-polecat-code-review"
+
+// Review gate errors.
+var (
+	ErrNotMergeRequest = errors.New("not a merge request")
+	ErrSelfReview      = errors.New("workers cannot review their own merge requests")
+)
+
+// ReviewRequired returns true if the rig gates merges on code review.
+func (e *Engineer) ReviewRequired() bool {
+	return e.config.RequireReview
+}
+
+// IsApproved returns true if the MR carries an approval that still covers
+// the branch. Approvals record the reviewed branch head; if the branch has
+// moved since, the approval is stale and the MR needs another review.
+// Fails closed when the branch head cannot be resolved or the approval
+// records no head.
+func (e *Engineer) IsApproved(fields *beads.MRFields) bool {
+	if fields == nil || fields.ReviewState != ReviewApproved || fields.ReviewedSHA == "" {
+		return false
+	}
+	head, err := e.git.Rev(fields.Branch)
+	if err != nil {
+		return false
+	}
+	return head == fields.ReviewedSHA
+}
+
+// ReviewStatus returns the display state of an MR's review:
+// "approved", "stale" (approved, but the branch moved), "pending",
+// "changes_requested", or "needed" (no review requested yet).
+func (e *Engineer) ReviewStatus(fields *beads.MRFields) string {
+	if fields == nil {
+		return "needed"
+	}
+	switch fields.ReviewState {
+	case ReviewApproved:
+		if e.IsApproved(fields) {
+			return ReviewApproved
+		}
+		return "stale"
+	case ReviewPending, ReviewChangesRequested:
+		return fields.ReviewState
+	default:
+		return "needed"
+	}
+}
+
+// RequestReviews creates review tasks for open MRs that need a review:
+// not approved (or approval is stale), not blocked on rework, and without
+// an open review task. Review tasks are ordinary task beads that name the
+// review formula, so they can be slung to a reviewer polecat or picked up
+// by a human. Returns the IDs of tasks created. A no-op unless the rig
+// requires review.
+func (e *Engineer) RequestReviews() ([]string, error) {
+	if !e.config.RequireReview {
+		return nil, nil
+	}
+
+	issues, err := e.beads.List(beads.ListOptions{
+		Status:   "open",
+		Label:    "gt:merge-request",
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("querying beads for merge-requests: %w", err)
+	}
+
+	var created []string
+	for _, issue := range issues {
+		if issue.Status != "open" || issue.Assignee != "" {
+			continue
+		}
+		taskID, err := e.requestReview(issue, beads.ParseMRFields(issue))
+		if err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to create review task for %s: %v\n", issue.ID, err)
+			continue
+		}
+		if taskID != "" {
+			created = append(created, taskID)
+		}
+	}
+	return created, nil
+}
+
+// requestReview creates a review task for one MR and marks the MR pending.
+// Returns "" without creating anything if the MR is approved, blocked on
+// rework, or already has an open review task.
+func (e *Engineer) requestReview(issue *beads.Issue, fields *beads.MRFields) (string, error) {
+	if fields == nil || e.IsApproved(fields) {
+		return "", nil
+	}
+	if e.hasOpenBlocker(issue) {
+		// Waiting on rework or conflict resolution - review the result
+		return "", nil
+	}
+	if fields.ReviewTaskID != "" {
+		if open, _ := e.IsBeadOpen(fields.ReviewTaskID); open {
+			return "", nil
+		}
+	}
+
+	taskID, err := e.createReviewTask(issue, fields)
+	if err != nil {
+		return "", err
+	}
+
+	fields.ReviewState = ReviewPending
+	fields.ReviewTaskID = taskID
+	fields.ReviewedBy = ""
+	fields.ReviewedSHA = ""
+	newDesc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(issue.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record review task on %s: %v\n", issue.ID, err)
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Requested review of %s: %s\n", issue.ID, taskID)
+	return taskID, nil
+}
+
+// hasOpenBlocker returns true if any of the issue's blockers is still open.
+func (e *Engineer) hasOpenBlocker(issue *beads.Issue) bool {
+	for _, id := range issue.BlockedBy {
+		if open, err := e.IsBeadOpen(id); err == nil && open {
+			return true
+		}
+	}
+	return false
+}
+
+// createReviewTask creates the task bead a reviewer works from.
+func (e *Engineer) createReviewTask(mr *beads.Issue, fields *beads.MRFields) (string, error) {
+	title := fields.SourceIssue
+	if fields.SourceIssue != "" {
+		if src, err := e.beads.Show(fields.SourceIssue); err == nil && src != nil {
+			title = src.Title
+		}
+	}
+	if title == "" {
+		title = mr.Title
+	}
+
+	description := fmt.Sprintf(`Review merge request %s before it lands on %s
+
+## Metadata
+- MR: %s
+- Branch: %s
+- Target: %s
+- Original issue: %s
+- Worker: %s
+- Formula: %s
+
+## Instructions
+Review with the %s formula, scoped to the branch diff:
+
+  git fetch origin
+  git diff origin/%s...%s
+
+Then record your verdict (this closes this task):
+
+  gt mq approve %s %s
+  gt mq request-changes %s %s -m "<what needs to change>"
+
+Requested changes go back to the worker; the MR returns for review once
+the rework task closes.`,
+		mr.ID, fields.Target,
+		mr.ID,
+		fields.Branch,
+		fields.Target,
+		fields.SourceIssue,
+		fields.Worker,
+		e.config.ReviewFormula,
+		e.config.ReviewFormula,
+		fields.Target, fields.Branch,
+		e.rig.Name, mr.ID,
+		e.rig.Name, mr.ID,
+	)
+
+	task, err := e.beads.Create(beads.CreateOptions{
+		Title:       fmt.Sprintf("Review: %s", title),
+		Type:        "task",
+		Priority:    mr.Priority,
+		Description: description,
+		Actor:       e.rig.Name + "/refinery",
+	})
+	if err != nil {
+		return "", err
+	}
+	return task.ID, nil
+}
+
+// loadMRForReview fetches an open MR bead and its fields.
+func (e *Engineer) loadMRForReview(mrID, reviewer string) (*beads.Issue, *beads.MRFields, error) {
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		return nil, nil, fmt.Errorf("%s: %w", mrID, ErrNotMergeRequest)
+	}
+	if issue.Status == "closed" {
+		return nil, nil, fmt.Errorf("%s is already closed", mrID)
+	}
+	if isWorker(reviewer, fields.Worker) {
+		return nil, nil, ErrSelfReview
+	}
+	return issue, fields, nil
+}
+
+// isWorker returns true if the reviewer address belongs to the MR's worker
+// (e.g., "gastown/polecats/Nux" for worker "Nux").
+func isWorker(reviewer, worker string) bool {
+	if reviewer == "" || worker == "" {
+		return false
+	}
+	return strings.EqualFold(path.Base(reviewer), worker)
+}
+
+// ApproveMR records an approval for the MR's current branch head and closes
+// its review task. The MR becomes ready once nothing else blocks it.
+func (e *Engineer) ApproveMR(mrID, reviewer string) error {
+	issue, fields, err := e.loadMRForReview(mrID, reviewer)
+	if err != nil {
+		return err
+	}
+
+	// Pin the approval to the reviewed head. Without one a later push to the
+	// branch would merge unreviewed, so refuse to approve.
+	head, err := e.git.Rev(fields.Branch)
+	if err != nil {
+		return fmt.Errorf("resolving head of %s: %w", fields.Branch, err)
+	}
+	if head == "" {
+		return fmt.Errorf("resolving head of %s: branch not found", fields.Branch)
+	}
+
+	fields.ReviewState = ReviewApproved
+	fields.ReviewedBy = reviewer
+	fields.ReviewedSHA = head
+	newDesc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("recording approval: %w", err)
+	}
+
+	e.closeReviewTask(fields.ReviewTaskID, "Approved by "+reviewer)
+	_ = events.LogFeed(events.TypeMergeApproved, reviewer, events.MergePayload(mrID, fields.Worker, fields.Branch, ""))
+	return nil
+}
+
+// RequestChanges records a changes-requested review. Like conflict
+// resolution, the rework becomes a task the MR is blocked on, so the queue
+// moves on; a REWORK_REQUEST tells the Witness so a live worker can react.
+// Returns the rework task ID.
+func (e *Engineer) RequestChanges(mrID, reviewer, feedback string) (string, error) {
+	issue, fields, err := e.loadMRForReview(mrID, reviewer)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(feedback) == "" {
+		return "", errors.New("feedback is required when requesting changes")
+	}
+
+	title := fields.SourceIssue
+	if fields.SourceIssue != "" {
+		if src, err := e.beads.Show(fields.SourceIssue); err == nil && src != nil {
+			title = src.Title
+		}
+	}
+
+	description := fmt.Sprintf(`Address review feedback on branch %s
+
+## Metadata
+- Original MR: %s
+- Branch: %s
+- Original issue: %s
+- Reviewer: %s
+
+## Requested changes
+%s
+
+## Instructions
+1. Check out the branch: git checkout %s
+2. Make the requested changes and commit them
+3. Push the branch: git push origin %s
+4. Close this task: bd close <this-task-id>
+
+The MR goes back to review after this task closes.`,
+		fields.Branch,
+		mrID,
+		fields.Branch,
+		fields.SourceIssue,
+		reviewer,
+		strings.TrimSpace(feedback),
+		fields.Branch,
+		fields.Branch,
+	)
+
+	task, err := e.beads.Create(beads.CreateOptions{
+		Title:       fmt.Sprintf("Address review feedback: %s", title),
+		Type:        "task",
+		Priority:    issue.Priority,
+		Description: description,
+		Actor:       e.rig.Name + "/refinery",
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating rework task: %w", err)
+	}
+
+	fields.ReviewState = ReviewChangesRequested
+	fields.ReviewedBy = reviewer
+	fields.ReviewedSHA = ""
+	newDesc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return task.ID, fmt.Errorf("recording review: %w", err)
+	}
+	if err := e.beads.AddDependency(mrID, task.ID); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to block MR on rework task: %v\n", err)
+	}
+
+	e.closeReviewTask(fields.ReviewTaskID, "Changes requested by "+reviewer)
+
+	msg := protocol.NewReviewReworkMessage(e.rig.Name, fields.Worker, fields.Branch, fields.SourceIssue, fields.Target, reviewer, feedback)
+	if err := e.router.Send(msg); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to send REWORK_REQUEST to witness: %v\n", err)
+	}
+
+	_ = events.LogFeed(events.TypeMergeChangesRequested, reviewer, events.MergePayload(mrID, fields.Worker, fields.Branch, firstLine(feedback)))
+	return task.ID, nil
+}
+
+// closeReviewTask closes an open review task, if any.
+func (e *Engineer) closeReviewTask(taskID, reason string) {
+	if taskID == "" {
+		return
+	}
+	if open, _ := e.IsBeadOpen(taskID); !open {
+		return
+	}
+	if err := e.beads.CloseWithReason(reason, taskID); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close review task %s: %v\n", taskID, err)
+	}
+}
+
+// checkReview returns a failed result if the MR may not merge yet because
+// review is required and it has no current approval. An MR reaching the
+// front of the queue without a review task gets one here.
+func (e *Engineer) checkReview(mrID string) *ProcessResult {
+	if !e.config.RequireReview {
+		return nil
+	}
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		return &ProcessResult{NeedsReview: true, Error: fmt.Sprintf("cannot verify review of %s: %v", mrID, err)}
+	}
+	fields := beads.ParseMRFields(issue)
+	if status := e.ReviewStatus(fields); status != ReviewApproved {
+		if _, err := e.requestReview(issue, fields); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to create review task for %s: %v\n", mrID, err)
+		}
+		return &ProcessResult{NeedsReview: true, Error: fmt.Sprintf("review required (state: %s)", status)}
+	}
+	return nil
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -4,7 +4,10 @@
 package refinery
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
 )
 
 // ScoreConfig contains tunable weights for MR priority scoring.
@@ -69,6 +72,10 @@ type ScoreInput struct {
 	// 0 = first attempt.
 	RetryCount int
 
+	// Adjustment is an explicit offset set by hand (gt mq bump) to move
+	// the MR up or down the queue.
+	Adjustment float64
+
 	// Now is the current time (for deterministic testing).
 	// If zero, time.Now() is used.
 	Now time.Time
@@ -84,6 +91,7 @@ type ScoreInput struct {
 //	      + PriorityWeight * (4 - priority)          // P0=+400, P4=+0
 //	      - min(RetryPenalty * retryCount, MaxRetryPenalty)  // Prevent thrashing
 //	      + MRAgeWeight * hoursOld(MR)               // FIFO tiebreaker
+//	      + adjustment                               // Manual ordering
 func ScoreMR(input ScoreInput, config ScoreConfig) float64 {
 	now := input.Now
 	if now.IsZero() {
@@ -125,6 +133,9 @@ func ScoreMR(input ScoreInput, config ScoreConfig) float64 {
 		score += config.MRAgeWeight * mrHours
 	}
 
+	// Manual ordering overrides
+	score += input.Adjustment
+
 	return score
 }
 
@@ -146,7 +157,106 @@ func (mr *MRInfo) ScoreAt(now time.Time) float64 {
 		MRCreatedAt:     mr.CreatedAt,
 		ConvoyCreatedAt: mr.ConvoyCreatedAt,
 		RetryCount:      mr.RetryCount,
+		Adjustment:      mr.ScoreAdjust,
 		Now:             now,
 	}
 	return ScoreMRWithDefaults(input)
 }
+
+// BumpAdjustment returns the score adjustment that moves mr to the top of
+// queue (after == "") or directly behind the MR with ID after. queue is the
+// rest of the rig's open MRs; mr itself is skipped if present. Later
+// scoring drifts with age, so a bump holds the position it was given
+// rather than guaranteeing it forever.
+func BumpAdjustment(mr *MRInfo, queue []*MRInfo, after string, now time.Time) (float64, error) {
+	base := mr.ScoreAt(now) - mr.ScoreAdjust
+
+	var others []float64
+	anchor, found := 0.0, false
+	for _, other := range queue {
+		if other.ID == mr.ID {
+			continue
+		}
+		score := other.ScoreAt(now)
+		if other.ID == after {
+			anchor, found = score, true
+			continue
+		}
+		others = append(others, score)
+	}
+
+	if after == "" {
+		if len(others) == 0 {
+			return mr.ScoreAdjust, nil
+		}
+		top := others[0]
+		for _, s := range others[1:] {
+			if s > top {
+				top = s
+			}
+		}
+		return top + 1 - base, nil
+	}
+
+	if after == mr.ID {
+		return 0, fmt.Errorf("cannot place %s after itself", mr.ID)
+	}
+	if !found {
+		return 0, fmt.Errorf("%s is not in the queue", after)
+	}
+	// Halfway between the anchor and the next MR below it
+	target := anchor - 1
+	below, hasBelow := 0.0, false
+	for _, s := range others {
+		if s < anchor && (!hasBelow || s > below) {
+			below, hasBelow = s, true
+		}
+	}
+	if hasBelow {
+		target = (anchor + below) / 2
+	}
+	return target - base, nil
+}
+
+// BumpMR reorders the queue by hand, setting the MR's score adjustment so
+// it scores above every other open MR (after == "") or just below the MR
+// after. clear removes the adjustment instead. Returns the adjustment.
+func (e *Engineer) BumpMR(mrID, after string, clear bool) (float64, error) {
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		return 0, fmt.Errorf("fetching %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		return 0, fmt.Errorf("%s is not a merge request", mrID)
+	}
+
+	adjust := 0.0
+	if !clear {
+		issues, err := e.beads.List(beads.ListOptions{
+			Status:   "open",
+			Label:    "gt:merge-request",
+			Priority: -1,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("listing merge requests: %w", err)
+		}
+		var queue []*MRInfo
+		for _, other := range issues {
+			if f := beads.ParseMRFields(other); f != nil && other.Status == "open" {
+				queue = append(queue, mrInfoFromIssue(other, f))
+			}
+		}
+		adjust, err = BumpAdjustment(mrInfoFromIssue(issue, fields), queue, after, time.Now())
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	fields.ScoreAdjust = adjust
+	newDesc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return 0, fmt.Errorf("updating %s: %w", mrID, err)
+	}
+	return adjust, nil
+}
@@ -0,0 +1,76 @@
+package refinery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScoreMR_Adjustment(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	in := ScoreInput{Priority: 2, MRCreatedAt: now.Add(-time.Hour), Now: now}
+	base := ScoreMRWithDefaults(in)
+
+	in.Adjustment = 250
+	if got := ScoreMRWithDefaults(in); got != base+250 {
+		t.Errorf("adjusted score = %v, want %v", got, base+250)
+	}
+}
+
+func TestBumpAdjustment(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-time.Hour)
+	p0 := &MRInfo{ID: "gt-mr-p0", Priority: 0, CreatedAt: created}
+	p1 := &MRInfo{ID: "gt-mr-p1", Priority: 1, CreatedAt: created}
+	p2 := &MRInfo{ID: "gt-mr-p2", Priority: 2, CreatedAt: created}
+	p4 := &MRInfo{ID: "gt-mr-p4", Priority: 4, CreatedAt: created}
+	queue := []*MRInfo{p0, p1, p2, p4}
+
+	// With adj applied to mr, where does it land?
+	scoreWith := func(mr *MRInfo, adj float64) float64 {
+		moved := *mr
+		moved.ScoreAdjust = adj
+		return moved.ScoreAt(now)
+	}
+
+	adj, err := BumpAdjustment(p4, queue, "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := scoreWith(p4, adj); s <= p0.ScoreAt(now) {
+		t.Errorf("--to-top score %v not above top %v", s, p0.ScoreAt(now))
+	}
+
+	adj, err = BumpAdjustment(p4, queue, "gt-mr-p1", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := scoreWith(p4, adj); s >= p1.ScoreAt(now) || s <= p2.ScoreAt(now) {
+		t.Errorf("--after p1 score %v not between %v and %v", s, p1.ScoreAt(now), p2.ScoreAt(now))
+	}
+
+	// After the last MR: just below it.
+	adj, err = BumpAdjustment(p0, queue, "gt-mr-p4", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := scoreWith(p0, adj); s >= p4.ScoreAt(now) {
+		t.Errorf("--after last score %v not below %v", s, p4.ScoreAt(now))
+	}
+
+	// A previous bump is replaced, not stacked.
+	bumped := &MRInfo{ID: "gt-mr-p4", Priority: 4, CreatedAt: created, ScoreAdjust: 5000}
+	adj, err = BumpAdjustment(bumped, []*MRInfo{p0, p1, p2, bumped}, "gt-mr-p1", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := scoreWith(bumped, adj); s >= p1.ScoreAt(now) || s <= p2.ScoreAt(now) {
+		t.Errorf("re-bump score %v not between %v and %v", s, p1.ScoreAt(now), p2.ScoreAt(now))
+	}
+
+	if _, err := BumpAdjustment(p2, queue, "gt-mr-p2", now); err == nil {
+		t.Error("expected an error placing an MR after itself")
+	}
+	if _, err := BumpAdjustment(p2, queue, "gt-mr-gone", now); err == nil {
+		t.Error("expected an error for an anchor not in the queue")
+	}
+}
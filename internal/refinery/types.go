@@ -248,6 +248,19 @@ const (
 
 	// FailureCheckout indicates checkout of target branch failed.
 	FailureCheckout FailureType = "checkout_fail"
+
+	// FailureSecretsFound indicates the branch adds what looks like a
+	// credential. The worker must remove it (and rotate it) before resubmitting.
+	FailureSecretsFound FailureType = "secrets_found"
+
+	// FailureScanError indicates the secret scan itself could not run (e.g.
+	// a missing ref). Nothing is wrong with the work; the MR is retried.
+	FailureScanError FailureType = "scan_error"
+
+	// FailureLandingError indicates PR landing stalled: the forge could not
+	// be reached or the PR did not merge within the landing timeout. Nothing
+	// is wrong with the work; the MR is retried.
+	FailureLandingError FailureType = "landing_error"
 )
 
 // FailureLabel returns the beads label for this failure type.
@@ -255,9 +268,9 @@ func (f FailureType) FailureLabel() string {
 	switch f {
 	case FailureConflict:
 		return "needs-rebase"
-	case FailureTestsFail, FailureBuildFail, FailureFlakyTest:
+	case FailureTestsFail, FailureBuildFail, FailureFlakyTest, FailureSecretsFound:
 		return "needs-fix"
-	case FailurePushFail:
+	case FailurePushFail, FailureScanError, FailureLandingError:
 		return "needs-retry"
 	default:
 		return ""
@@ -267,7 +280,7 @@ func (f FailureType) FailureLabel() string {
 // ShouldAssignToWorker returns true if this failure should be assigned back to the worker.
 func (f FailureType) ShouldAssignToWorker() bool {
 	switch f {
-	case FailureConflict, FailureTestsFail, FailureBuildFail, FailureFlakyTest:
+	case FailureConflict, FailureTestsFail, FailureBuildFail, FailureFlakyTest, FailureSecretsFound:
 		return true
 	default:
 		return false
@@ -269,6 +269,9 @@ func TestFailureType_FailureLabel(t *testing.T) {
 		{FailurePushFail, "needs-retry"},
 		{FailureFetch, ""},
 		{FailureCheckout, ""},
+		{FailureSecretsFound, "needs-fix"},
+		{FailureScanError, "needs-retry"},
+		{FailureLandingError, "needs-retry"},
 	}
 
 	for _, tt := range tests {
@@ -294,6 +297,9 @@ func TestFailureType_ShouldAssignToWorker(t *testing.T) {
 		{FailurePushFail, false},
 		{FailureFetch, false},
 		{FailureCheckout, false},
+		{FailureSecretsFound, true},
+		{FailureScanError, false},
+		{FailureLandingError, false},
 	}
 
 	for _, tt := range tests {
@@ -7,9 +7,11 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/claude"
+	"github.com/steveyegge/gastown/internal/codex"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/gemini"
+	"github.com/steveyegge/gastown/internal/hookemu"
 	"github.com/steveyegge/gastown/internal/opencode"
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 // EnsureSettingsForRole installs runtime hook settings when supported.
@@ -18,15 +20,30 @@ func EnsureSettingsForRole(workDir, role string, rc *config.RuntimeConfig) error
 		rc = config.DefaultRuntimeConfig()
 	}
 
-	if rc.Hooks == nil {
-		return nil
+	hooks := rc.Hooks
+	if hooks == nil {
+		// Agent presets leave hooks to the provider's defaults
+		if rc.Provider == "" {
+			return nil
+		}
+		hooks = config.DefaultHooksConfig(rc.Provider)
 	}
 
-	switch rc.Hooks.Provider {
+	switch hooks.Provider {
 	case "claude":
-		return claude.EnsureSettingsForRoleAt(workDir, role, rc.Hooks.Dir, rc.Hooks.SettingsFile)
+		return claude.EnsureSettingsForRoleAt(workDir, role, hooks.Dir, hooks.SettingsFile)
 	case "opencode":
-		return opencode.EnsurePluginAt(workDir, rc.Hooks.Dir, rc.Hooks.SettingsFile)
+		return opencode.EnsurePluginAt(workDir, hooks.Dir, hooks.SettingsFile)
+	case "gemini":
+		return gemini.EnsureSettingsForRoleAt(workDir, role, hooks.Dir, hooks.SettingsFile)
+	case "codex":
+		// Codex has no hooks: install its config, then the emulated hooks.
+		if err := codex.EnsureConfigAt(workDir, role, hooks.Dir, hooks.SettingsFile); err != nil {
+			return err
+		}
+		return hookemu.EnsureConfigAt(workDir, role, "", "")
+	case hookemu.Provider:
+		return hookemu.EnsureConfigAt(workDir, role, hooks.Dir, hooks.SettingsFile)
 	default:
 		return nil
 	}
@@ -73,8 +90,13 @@ func StartupFallbackCommands(role string, rc *config.RuntimeConfig) []string {
 	return []string{command}
 }
 
-// RunStartupFallback sends the startup fallback commands via tmux.
-func RunStartupFallback(t *tmux.Tmux, sessionID, role string, rc *config.RuntimeConfig) error {
+// Nudger delivers a message to an agent session (tmux or another session backend).
+type Nudger interface {
+	NudgeSession(session, message string) error
+}
+
+// RunStartupFallback sends the startup fallback commands to the session.
+func RunStartupFallback(t Nudger, sessionID, role string, rc *config.RuntimeConfig) error {
 	commands := StartupFallbackCommands(role, rc)
 	for _, cmd := range commands {
 		if err := t.NudgeSession(sessionID, cmd); err != nil {
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -285,6 +286,55 @@ func TestEnsureSettingsForRole_UnknownProvider(t *testing.T) {
 	}
 }
 
+func TestEnsureSettingsForRole_Gemini(t *testing.T) {
+	dir := t.TempDir()
+	rc := config.RuntimeConfigFromPreset(config.AgentGemini)
+
+	if err := EnsureSettingsForRole(dir, "polecat", rc); err != nil {
+		t.Fatalf("EnsureSettingsForRole() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".gemini", "settings.json"))
+	if err != nil {
+		t.Fatalf("gemini settings not written: %v", err)
+	}
+	if !contains(string(data), "gt mail check --inject && gt nudge deacon") {
+		t.Error("polecat gemini settings should inject mail on SessionStart")
+	}
+}
+
+func TestEnsureSettingsForRole_Codex(t *testing.T) {
+	dir := t.TempDir()
+	rc := config.RuntimeConfigFromPreset(config.AgentCodex)
+
+	if err := EnsureSettingsForRole(dir, "crew", rc); err != nil {
+		t.Fatalf("EnsureSettingsForRole() error = %v", err)
+	}
+
+	for _, rel := range []string{".codex/config.toml", "AGENTS.md", ".gastown/hooks.json"} {
+		if _, err := os.Stat(filepath.Join(dir, rel)); err != nil {
+			t.Errorf("%s not written: %v", rel, err)
+		}
+	}
+}
+
+func TestEnsureSettingsForRole_CodexKeepsAgentsMD(t *testing.T) {
+	dir := t.TempDir()
+	agentsMD := filepath.Join(dir, "AGENTS.md")
+	if err := os.WriteFile(agentsMD, []byte("# Project rules\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := EnsureSettingsForRole(dir, "crew", config.RuntimeConfigFromPreset(config.AgentCodex)); err != nil {
+		t.Fatalf("EnsureSettingsForRole() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(agentsMD)
+	if string(data) != "# Project rules\n" {
+		t.Errorf("existing AGENTS.md was overwritten: %q", data)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && findSubstring(s, substr)
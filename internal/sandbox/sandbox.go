@@ -0,0 +1,259 @@
+// Package sandbox isolates polecat agent processes from the rest of the host.
+//
+// In bwrap mode the agent command is launched under bubblewrap with the root
+// filesystem mounted read-only. Only the polecat's worktree, the shared beads
+// directories, the git object store and refs, and explicitly configured paths
+// are writable. The shared repository's config and hooks stay read-only, since
+// they run outside the sandbox whenever the refinery or a user runs git.
+// Network access can be denied entirely or restricted with a host deny-list.
+// The deny-list only rewrites /etc/hosts, so it blocks name lookups, not
+// connections to raw IP addresses. This lets Gas Town run untrusted formulas
+// without risking the user's home directory.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// ErrUnavailable is returned when a sandbox is configured but the isolation
+// tool is not installed. Polecats never silently fall back to running
+// unsandboxed.
+var ErrUnavailable = errors.New("sandbox requested but bwrap is not installed")
+
+// HostsFile is the name of the generated hosts file used for DenyHosts,
+// written to the polecat's home directory.
+const HostsFile = ".sandbox-hosts"
+
+// Spec describes the filesystem layout a sandboxed session needs.
+type Spec struct {
+	// WorkDir is the polecat's git worktree (becomes the sandbox cwd).
+	WorkDir string
+
+	// StateDir is where generated sandbox files (hosts file) are written.
+	// Typically the polecat's home directory (polecats/<name>/).
+	StateDir string
+
+	// Writable lists absolute paths that must stay writable in the sandbox.
+	Writable []string
+
+	// ReadOnly lists paths mounted read-only over the writable ones, for
+	// files inside a writable tree that the agent must not change.
+	ReadOnly []string
+}
+
+// NewSpec builds the standard Spec for a polecat: its worktree, its home
+// directory, the rig and town beads directories, the town events log and the
+// parts of the git common directory that commits write to (objects, refs,
+// logs, packed-refs and the worktree's own git directory). The common
+// directory's hooks and config are read-only: git runs them for the refinery
+// and for users outside the sandbox.
+func NewSpec(townRoot, polecatDir, workDir string, cfg *config.SandboxConfig) Spec {
+	writable := []string{
+		workDir,
+		polecatDir,
+		beads.ResolveBeadsDir(workDir),
+		filepath.Join(townRoot, ".beads"),
+		filepath.Join(townRoot, ".events.jsonl"),
+	}
+	var readOnly []string
+	if gitDir, commonDir := gitDirs(workDir); commonDir != "" {
+		// Reflogs are created on demand; make sure there is a directory to
+		// bind, since the common directory itself stays read-only.
+		_ = os.MkdirAll(filepath.Join(commonDir, "logs"), 0755)
+		for _, sub := range []string{"objects", "refs", "logs", "packed-refs"} {
+			writable = append(writable, filepath.Join(commonDir, sub))
+		}
+		readOnly = append(readOnly,
+			filepath.Join(commonDir, "hooks"),
+			filepath.Join(commonDir, "config"))
+		if gitDir != commonDir {
+			// A linked worktree: its HEAD and index live in its own git
+			// directory, and its .git file must not be pointed elsewhere.
+			writable = append(writable, gitDir)
+			readOnly = append(readOnly, filepath.Join(workDir, ".git"))
+		}
+	}
+	if cfg != nil {
+		home, _ := os.UserHomeDir()
+		for _, p := range cfg.Writable {
+			writable = append(writable, expandHome(p, home))
+		}
+	}
+	return Spec{
+		WorkDir:  workDir,
+		StateDir: polecatDir,
+		Writable: writable,
+		ReadOnly: readOnly,
+	}
+}
+
+// LoadConfig loads the sandbox settings for a rig.
+// Returns nil (unsandboxed) if the rig has no settings file or no sandbox
+// section. Any other error is returned so that a broken settings file never
+// silently disables isolation.
+func LoadConfig(rigPath string) (*config.SandboxConfig, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("loading rig settings: %w", err)
+	}
+	return settings.Sandbox, nil
+}
+
+// WrapForRig wraps a polecat startup command according to the rig's sandbox
+// settings. Returns command unchanged if the rig does not enable a sandbox.
+func WrapForRig(townRoot, rigPath, polecatDir, workDir, command string) (string, error) {
+	cfg, err := LoadConfig(rigPath)
+	if err != nil {
+		return "", err
+	}
+	if !cfg.IsEnabled() {
+		return command, nil
+	}
+	return Wrap(cfg, NewSpec(townRoot, polecatDir, workDir, cfg), command)
+}
+
+// Wrap returns command wrapped in the configured sandbox. If the sandbox is
+// not enabled, command is returned unchanged.
+func Wrap(cfg *config.SandboxConfig, spec Spec, command string) (string, error) {
+	if !cfg.IsEnabled() {
+		return command, nil
+	}
+	if _, err := exec.LookPath("bwrap"); err != nil {
+		return "", ErrUnavailable
+	}
+
+	hostsPath := ""
+	if cfg.Network != config.SandboxNetworkDeny && len(cfg.DenyHosts) > 0 {
+		var err error
+		hostsPath, err = writeHostsFile(spec.StateDir, cfg.DenyHosts)
+		if err != nil {
+			return "", fmt.Errorf("writing sandbox hosts file: %w", err)
+		}
+	}
+
+	return buildBwrapCommand(cfg, spec, hostsPath, command), nil
+}
+
+// buildBwrapCommand assembles the bubblewrap invocation.
+// Writable paths that do not exist are skipped, since bwrap refuses to bind
+// missing sources. Read-only paths are bound after the writable ones so they
+// take precedence.
+func buildBwrapCommand(cfg *config.SandboxConfig, spec Spec, hostsPath, command string) string {
+	args := []string{
+		"exec", "bwrap",
+		"--die-with-parent",
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+	}
+
+	for _, p := range dedupePaths(spec.Writable) {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		q := config.ShellQuote(p)
+		args = append(args, "--bind", q, q)
+	}
+	for _, p := range dedupePaths(spec.ReadOnly) {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		q := config.ShellQuote(p)
+		args = append(args, "--ro-bind", q, q)
+	}
+
+	if cfg.Network == config.SandboxNetworkDeny {
+		args = append(args, "--unshare-net")
+	} else if hostsPath != "" {
+		args = append(args, "--ro-bind", config.ShellQuote(hostsPath), "/etc/hosts")
+	}
+
+	args = append(args,
+		"--setenv", "GT_SANDBOX", cfg.Mode,
+		"--chdir", config.ShellQuote(spec.WorkDir),
+		"--", "sh", "-c", config.ShellQuote(command))
+	return strings.Join(args, " ")
+}
+
+// writeHostsFile writes a copy of /etc/hosts with denied hosts mapped to an
+// unroutable address, returning its path.
+func writeHostsFile(dir string, denyHosts []string) (string, error) {
+	base, _ := os.ReadFile("/etc/hosts")
+
+	var sb strings.Builder
+	sb.Write(base)
+	if len(base) > 0 && base[len(base)-1] != '\n' {
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("# Gas Town sandbox deny-list\n")
+	for _, host := range denyHosts {
+		fmt.Fprintf(&sb, "0.0.0.0 %s\n", host)
+		fmt.Fprintf(&sb, "::      %s\n", host)
+	}
+
+	path := filepath.Join(dir, HostsFile)
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil { //nolint:gosec // G306: hosts file is not sensitive
+		return "", err
+	}
+	return path, nil
+}
+
+// gitDirs returns a worktree's own git directory and the shared git
+// directory, or "", "" if they cannot be determined. For a main checkout
+// both are the same.
+func gitDirs(workDir string) (gitDir, commonDir string) {
+	cmd := exec.Command("git", "rev-parse", "--path-format=absolute", "--git-dir", "--git-common-dir")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", ""
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 {
+		return "", ""
+	}
+	return lines[0], lines[1]
+}
+
+// expandHome expands a leading "~" to the user's home directory.
+func expandHome(path, home string) string {
+	if path == "~" {
+		return home
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, path[2:])
+	}
+	return path
+}
+
+// dedupePaths returns cleaned, unique, sorted paths.
+func dedupePaths(paths []string) []string {
+	seen := make(map[string]bool, len(paths))
+	var result []string
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		p = filepath.Clean(p)
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		result = append(result, p)
+	}
+	sort.Strings(result)
+	return result
+}
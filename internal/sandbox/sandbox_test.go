@@ -0,0 +1,188 @@
+package sandbox
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestBuildBwrapCommand(t *testing.T) {
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "polecats", "Toast", "gastown")
+	beadsDir := filepath.Join(dir, ".beads")
+	for _, d := range []string{workDir, beadsDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.SandboxConfig{Mode: config.SandboxModeBwrap, Network: config.SandboxNetworkDeny}
+	spec := Spec{
+		WorkDir:  workDir,
+		Writable: []string{workDir, beadsDir, filepath.Join(dir, "missing"), workDir},
+	}
+	got := buildBwrapCommand(cfg, spec, "", "exec env GT_ROLE=polecat claude")
+
+	for _, want := range []string{
+		"exec bwrap --die-with-parent --ro-bind / /",
+		"--bind " + workDir + " " + workDir,
+		"--bind " + beadsDir + " " + beadsDir,
+		"--unshare-net",
+		"--chdir " + workDir,
+		"-- sh -c 'exec env GT_ROLE=polecat claude'",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("buildBwrapCommand() missing %q\ngot: %s", want, got)
+		}
+	}
+	if strings.Contains(got, "missing") {
+		t.Errorf("buildBwrapCommand() bound nonexistent path: %s", got)
+	}
+	if strings.Count(got, "--bind "+workDir+" ") != 1 {
+		t.Errorf("buildBwrapCommand() did not dedupe worktree bind: %s", got)
+	}
+}
+
+func TestBuildBwrapCommand_DenyHosts(t *testing.T) {
+	cfg := &config.SandboxConfig{Mode: config.SandboxModeBwrap, DenyHosts: []string{"evil.example"}}
+	got := buildBwrapCommand(cfg, Spec{WorkDir: "/w"}, "/state/.sandbox-hosts", "claude")
+
+	if !strings.Contains(got, "--ro-bind /state/.sandbox-hosts /etc/hosts") {
+		t.Errorf("buildBwrapCommand() missing hosts bind: %s", got)
+	}
+	if strings.Contains(got, "--unshare-net") {
+		t.Errorf("buildBwrapCommand() unshared network with network allowed: %s", got)
+	}
+}
+
+// TestNewSpec_GitCommonDir verifies a worktree's shared repository is only
+// writable where commits land: its hooks and config stay read-only.
+func TestNewSpec_GitCommonDir(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	bare := filepath.Join(dir, ".repo.git")
+	workDir := filepath.Join(dir, "polecats", "Toast", "gastown")
+	for _, args := range [][]string{
+		{"init", "-q", src},
+		{"-C", src, "-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"clone", "-q", "--bare", src, bare},
+		{"-C", bare, "worktree", "add", "-q", "-b", "polecat/Toast", workDir},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	// Resolve symlinks (macOS /var -> /private/var) to match git's output.
+	bare, _ = filepath.EvalSymlinks(bare)
+
+	spec := NewSpec(dir, filepath.Dir(workDir), workDir, nil)
+	has := func(paths []string, p string) bool {
+		for _, q := range paths {
+			if q == p {
+				return true
+			}
+		}
+		return false
+	}
+	for _, want := range []string{"objects", "refs", "logs", "packed-refs", filepath.Join("worktrees", "gastown")} {
+		if !has(spec.Writable, filepath.Join(bare, want)) {
+			t.Errorf("Writable missing %s: %v", want, spec.Writable)
+		}
+	}
+	if has(spec.Writable, bare) {
+		t.Errorf("Writable includes the whole common dir: %v", spec.Writable)
+	}
+	for _, want := range []string{filepath.Join(bare, "hooks"), filepath.Join(bare, "config"), filepath.Join(workDir, ".git")} {
+		if !has(spec.ReadOnly, want) {
+			t.Errorf("ReadOnly missing %s: %v", want, spec.ReadOnly)
+		}
+	}
+
+	got := buildBwrapCommand(&config.SandboxConfig{Mode: config.SandboxModeBwrap}, spec, "", "claude")
+	roHooks := strings.Index(got, "--ro-bind "+filepath.Join(bare, "hooks"))
+	if roHooks < 0 || roHooks < strings.LastIndex(got, "--bind ") {
+		t.Errorf("hooks not bound read-only after the writable binds: %s", got)
+	}
+}
+
+func TestWriteHostsFile(t *testing.T) {
+	dir := t.TempDir()
+	path, err := writeHostsFile(dir, []string{"evil.example", "pastebin.com"})
+	if err != nil {
+		t.Fatalf("writeHostsFile() error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"0.0.0.0 evil.example", "0.0.0.0 pastebin.com"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("hosts file missing %q", want)
+		}
+	}
+}
+
+func TestWrap_Disabled(t *testing.T) {
+	for _, cfg := range []*config.SandboxConfig{nil, {}, {Mode: config.SandboxModeNone}} {
+		got, err := Wrap(cfg, Spec{}, "claude")
+		if err != nil || got != "claude" {
+			t.Errorf("Wrap(%+v) = %q, %v; want unchanged", cfg, got, err)
+		}
+	}
+}
+
+func TestWrapForRig_RejectsContainer(t *testing.T) {
+	// bwrap on the host says nothing about the container the command runs
+	// in, so the rig must not be wrapped at all.
+	rigPath := t.TempDir()
+	path := config.RigSettingsPath(rigPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	body := `{"type": "rig-settings", "version": 1, "sandbox": {"mode": "bwrap"}, "container": {"image": "golang:1.24"}}`
+	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := WrapForRig(t.TempDir(), rigPath, t.TempDir(), t.TempDir(), "claude")
+	if !errors.Is(err, config.ErrInvalidSandbox) {
+		t.Errorf("WrapForRig() = %q, %v; want ErrInvalidSandbox", got, err)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	if got := expandHome("~/.claude", "/home/u"); got != "/home/u/.claude" {
+		t.Errorf("expandHome() = %q", got)
+	}
+	if got := expandHome("/opt/cache", "/home/u"); got != "/opt/cache" {
+		t.Errorf("expandHome() = %q", got)
+	}
+}
+
+func TestDetectViolations(t *testing.T) {
+	output := `$ touch ~/.bashrc
+touch: cannot touch '/home/u/.bashrc': Read-only file system
+touch: cannot touch '/home/u/.bashrc': Read-only file system
+$ curl https://evil.example
+curl: (6) Could not resolve host: evil.example
+all good here`
+
+	got := DetectViolations(output)
+	if len(got) != 2 {
+		t.Fatalf("DetectViolations() returned %d violations, want 2: %+v", len(got), got)
+	}
+	if got[0].Kind != ViolationWrite {
+		t.Errorf("violation[0].Kind = %q, want %q", got[0].Kind, ViolationWrite)
+	}
+	if got[1].Kind != ViolationNetwork {
+		t.Errorf("violation[1].Kind = %q, want %q", got[1].Kind, ViolationNetwork)
+	}
+}
@@ -0,0 +1,57 @@
+package sandbox
+
+import (
+	"strings"
+)
+
+// Violation kinds reported from sandboxed session output.
+const (
+	ViolationWrite   = "write"
+	ViolationNetwork = "network"
+)
+
+// Violation is a sandbox denial observed in a session's output.
+type Violation struct {
+	// Kind is ViolationWrite or ViolationNetwork.
+	Kind string
+
+	// Line is the output line that revealed the denial.
+	Line string
+}
+
+// violationPatterns maps error strings produced by denied operations to the
+// kind of restriction they indicate. The sandbox itself cannot report denied
+// syscalls, so we recognize the errors they surface as in the agent's pane.
+var violationPatterns = []struct {
+	pattern string
+	kind    string
+}{
+	{"read-only file system", ViolationWrite},
+	{"erofs", ViolationWrite},
+	{"network is unreachable", ViolationNetwork},
+	{"could not resolve host", ViolationNetwork},
+	{"temporary failure in name resolution", ViolationNetwork},
+	{"name or service not known", ViolationNetwork},
+}
+
+// DetectViolations scans session output for sandbox denials.
+// Each distinct line is reported at most once.
+func DetectViolations(output string) []Violation {
+	var violations []Violation
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || seen[line] {
+			continue
+		}
+		lower := strings.ToLower(line)
+		for _, vp := range violationPatterns {
+			if strings.Contains(lower, vp.pattern) {
+				violations = append(violations, Violation{Kind: vp.kind, Line: line})
+				seen[line] = true
+				break
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ScanRange scans every commit reachable from head but not from base
+// (git log -p base..head) in the repository containing repoDir. Each
+// commit's patch is scanned on its own, so a secret added in one commit and
+// deleted in a later one is still found: pushing the branch publishes it.
+// Findings repeated across commits are reported once.
+// The allowlist is the one committed at base, so a branch cannot allowlist
+// its own leak.
+func ScanRange(repoDir, base, head string) ([]Finding, error) {
+	allow, err := LoadAllowlistAt(repoDir, base)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", AllowlistFile, err)
+	}
+	patches, err := gitOutput(repoDir, "log", "-p", "--format=", "-U0", "--no-color", "--no-ext-diff", base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	seen := make(map[Finding]bool)
+	for _, f := range NewScanner(allow).ScanDiff(patches) {
+		if !seen[f] {
+			seen[f] = true
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// ScanStaged scans the staged changes of the repository containing repoDir.
+// With includeUnstaged, tracked but unstaged changes are scanned too (what
+// `git commit -a` would record).
+func ScanStaged(repoDir string, includeUnstaged bool) ([]Finding, error) {
+	allow, err := LoadAllowlist(topLevel(repoDir))
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", AllowlistFile, err)
+	}
+	args := []string{"diff", "--cached", "-U0", "--no-color", "--no-ext-diff"}
+	if includeUnstaged {
+		args = []string{"diff", "HEAD", "-U0", "--no-color", "--no-ext-diff"}
+	}
+	diff, err := gitOutput(repoDir, args...)
+	if err != nil {
+		return nil, err
+	}
+	return NewScanner(allow).ScanDiff(diff), nil
+}
+
+// topLevel returns the work tree root containing dir, or dir itself.
+func topLevel(dir string) string {
+	if out, err := gitOutput(dir, "rev-parse", "--show-toplevel"); err == nil {
+		return strings.TrimSpace(out)
+	}
+	return dir
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...) //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
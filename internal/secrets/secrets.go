@@ -0,0 +1,252 @@
+// Package secrets is a small, offline secret scanner for git diffs.
+//
+// It looks at lines added by a change and flags two kinds of leaks:
+//   - well-known credential formats (cloud keys, forge tokens, private keys)
+//   - high-entropy values assigned to secret-looking names, which catches
+//     the `.env` style API_KEY=... values the fixed patterns miss
+//
+// Findings can be suppressed with an allowlist file at the repository root
+// (see AllowlistFile) or by putting "gt:allow-secret" on the offending line.
+// No external service is contacted.
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// InlineAllowMarker suppresses findings on a line that contains it.
+const InlineAllowMarker = "gt:allow-secret"
+
+// Rule is a named credential pattern.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// Finding is a suspected secret on an added line.
+type Finding struct {
+	File  string // path relative to the repository root
+	Line  int    // 1-based line number in the new version of File
+	Rule  string // rule name that matched
+	Match string // redacted match, safe to print and mail
+}
+
+// String formats the finding as "file:line: rule (match)".
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", f.File, f.Line, f.Rule, f.Match)
+}
+
+// DefaultRules are high-confidence credential formats.
+var DefaultRules = []Rule{
+	{"aws-access-key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github-token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}`)},
+	{"github-fine-grained-token", regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{60,}`)},
+	{"gitlab-token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{"slack-token", regexp.MustCompile(`\bxox[baprs]-[A-Za-z0-9-]{10,}`)},
+	{"slack-webhook", regexp.MustCompile(`hooks\.slack\.com/services/T[A-Za-z0-9_]+/B[A-Za-z0-9_]+/[A-Za-z0-9_]+`)},
+	{"anthropic-api-key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{32,}`)},
+	{"openai-api-key", regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9_-]{32,}`)},
+	{"google-api-key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{"stripe-key", regexp.MustCompile(`\b(sk|rk)_live_[0-9A-Za-z]{24,}`)},
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`)},
+	{"url-credentials", regexp.MustCompile(`[a-z][a-z0-9+.-]*://[^/\s:@'"]+:[^/\s:@'"$]{6,}@[^/\s'"]+`)},
+}
+
+// assignmentPattern captures values assigned to secret-looking names, e.g.
+// API_KEY=..., "client_secret": "...", password: '...'.
+var assignmentPattern = regexp.MustCompile(
+	`(?i)([a-z0-9_.-]*(?:secret|token|passw(?:or)?d|api[_-]?key|access[_-]?key|private[_-]?key|credential|auth)[a-z0-9_.-]*)` +
+		`["']?\s*(?::=|=|:)\s*["']?([A-Za-z0-9+/=_.~-]{16,})`)
+
+// Entropy thresholds (bits per character) for assigned values.
+// Hex strings have at most 4 bits per character, so they get a lower bar.
+const (
+	minEntropy    = 3.5
+	minHexEntropy = 3.0
+)
+
+var hexOnly = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// Scanner scans diffs for secrets.
+type Scanner struct {
+	Rules     []Rule
+	Allowlist *Allowlist
+}
+
+// NewScanner returns a scanner with the default rules and the given
+// allowlist, which may be nil.
+func NewScanner(allow *Allowlist) *Scanner {
+	return &Scanner{Rules: DefaultRules, Allowlist: allow}
+}
+
+// ScanLine returns the rule name and raw match for the first secret on a
+// line, or "" if the line looks clean.
+func (s *Scanner) ScanLine(line string) (rule, match string) {
+	if strings.Contains(line, InlineAllowMarker) {
+		return "", ""
+	}
+	for _, r := range s.Rules {
+		if m := r.Pattern.FindString(line); m != "" && !s.Allowlist.allowsMatch(m) {
+			return r.Name, m
+		}
+	}
+	for _, m := range assignmentPattern.FindAllStringSubmatch(line, -1) {
+		value := m[2]
+		if isPlaceholder(value) || s.Allowlist.allowsMatch(value) {
+			continue
+		}
+		threshold := minEntropy
+		if hexOnly.MatchString(value) {
+			threshold = minHexEntropy
+		}
+		if ShannonEntropy(value) >= threshold {
+			return "high-entropy-assignment", value
+		}
+	}
+	return "", ""
+}
+
+// ScanDiff scans the added lines of a unified diff. Line numbers are taken
+// from hunk headers, so diffs produced with any context size work.
+func (s *Scanner) ScanDiff(diff string) []Finding {
+	var findings []Finding
+	file := ""
+	line := 0
+	for _, l := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "+++ "):
+			file = strings.TrimPrefix(l, "+++ ")
+			file = strings.TrimPrefix(file, "b/")
+			if file == "/dev/null" {
+				file = ""
+			}
+			continue
+		case strings.HasPrefix(l, "--- "):
+			continue
+		case strings.HasPrefix(l, "@@"):
+			line = hunkStart(l)
+			continue
+		}
+		if file == "" || line == 0 {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(l, "+"):
+			if !s.Allowlist.allowsPath(file) {
+				if rule, match := s.ScanLine(l[1:]); rule != "" {
+					findings = append(findings, Finding{File: file, Line: line, Rule: rule, Match: Redact(match)})
+				}
+			}
+			line++
+		case strings.HasPrefix(l, " "):
+			line++
+		}
+	}
+	return findings
+}
+
+// hunkStart parses the new-file start line from a hunk header
+// ("@@ -a,b +c,d @@"). Returns 0 if the header is malformed.
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	for _, f := range fields[1:] {
+		if !strings.HasPrefix(f, "+") {
+			continue
+		}
+		start, _, _ := strings.Cut(f[1:], ",")
+		n, err := strconv.Atoi(start)
+		if err != nil {
+			return 0
+		}
+		if n == 0 {
+			// "+0,0" is a pure deletion; there are no added lines.
+			return 0
+		}
+		return n
+	}
+	return 0
+}
+
+// ShannonEntropy returns the Shannon entropy of s in bits per character.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len([]rune(s)))
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// Redact keeps a short prefix of a match so the finding is recognizable
+// without re-leaking the secret in mail, logs or bead descriptions.
+func Redact(match string) string {
+	const keep = 4
+	if len(match) <= keep*2 {
+		return strings.Repeat("*", len(match))
+	}
+	return match[:keep] + strings.Repeat("*", 8)
+}
+
+// placeholderWords mark values that are obviously not real credentials.
+var placeholderWords = []string{
+	"example", "placeholder", "changeme", "your_", "your-", "xxxxxxxx",
+	"redacted", "dummy", "sample", "<", "${", "{{",
+}
+
+func isPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	for _, w := range placeholderWords {
+		if strings.Contains(lower, w) {
+			return true
+		}
+	}
+	// Dotted identifiers are code references (config.api.token), not values.
+	if strings.Count(value, ".") >= 2 && !strings.ContainsAny(value, "+/=") {
+		return true
+	}
+	return false
+}
+
+// Summary formats findings for an error message, one per line, capped at
+// max entries.
+func Summary(findings []Finding, max int) string {
+	var sb strings.Builder
+	for i, f := range findings {
+		if i == max {
+			fmt.Fprintf(&sb, "  ... and %d more\n", len(findings)-max)
+			break
+		}
+		fmt.Fprintf(&sb, "  %s\n", f)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// matchGlob reports whether a slash-separated path matches a glob. Globs
+// without a slash match the base name at any depth; a trailing "/**" (or
+// "/") matches everything below a directory.
+func matchGlob(glob, file string) bool {
+	glob = strings.TrimPrefix(glob, "/")
+	if strings.HasSuffix(glob, "/**") || strings.HasSuffix(glob, "/") {
+		dir := strings.TrimSuffix(strings.TrimSuffix(glob, "**"), "/")
+		return file == dir || strings.HasPrefix(file, dir+"/")
+	}
+	if !strings.Contains(glob, "/") {
+		ok, _ := path.Match(glob, path.Base(file))
+		return ok
+	}
+	ok, _ := path.Match(glob, file)
+	return ok
+}
@@ -0,0 +1,93 @@
+package session
+
+import (
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ptyd"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// Session backends selectable with session_backend in town settings.
+const (
+	BackendTmux = "tmux" // default
+	BackendPTY  = "pty"  // built-in supervisor daemon (gt ptyd), no tmux needed
+)
+
+// Backend runs agent sessions. tmux.Tmux is the default implementation;
+// ptyd.Client runs sessions under the built-in pty supervisor for hosts
+// without tmux. Callers that need tmux-only features (themes, status
+// lines, hooks) type-assert to *tmux.Tmux and skip them otherwise.
+type Backend interface {
+	// NewSession creates a detached session running the default shell.
+	NewSession(name, workDir string) error
+
+	// NewSessionWithCommand creates a detached session whose initial
+	// process is command.
+	NewSessionWithCommand(name, workDir, command string) error
+
+	// HasSession reports whether the named session exists.
+	HasSession(name string) (bool, error)
+
+	// ListSessions returns all session names.
+	ListSessions() ([]string, error)
+
+	// KillSessionWithProcesses terminates a session and every process in it.
+	KillSessionWithProcesses(name string) error
+
+	// SendKeys types keys literally followed by Enter.
+	SendKeys(session, keys string) error
+
+	// SendKeysDebounced types keys, waits debounceMs, then presses Enter.
+	SendKeysDebounced(session, keys string, debounceMs int) error
+
+	// SendKeysRaw sends a single key by tmux name, e.g. "C-c" or "Enter".
+	SendKeysRaw(session, keys string) error
+
+	// NudgeSession delivers a message to an agent and submits it.
+	NudgeSession(session, message string) error
+
+	// AcceptBypassPermissionsWarning dismisses Claude's bypass-permissions
+	// dialog if it is showing.
+	AcceptBypassPermissionsWarning(session string) error
+
+	// CapturePane returns the last lines of the session's output.
+	CapturePane(session string, lines int) (string, error)
+
+	// AttachSession connects the current terminal to the session.
+	AttachSession(session string) error
+
+	// SetEnvironment records a variable on the session.
+	SetEnvironment(session, key, value string) error
+
+	// GetPanePID returns the PID of the session's initial process.
+	GetPanePID(session string) (string, error)
+
+	// IsAvailable reports whether the backend can run sessions here.
+	IsAvailable() bool
+}
+
+// Both backends implement Backend.
+var (
+	_ Backend = (*tmux.Tmux)(nil)
+	_ Backend = (*ptyd.Client)(nil)
+)
+
+// NewBackend returns the session backend configured for the town
+// (session_backend in settings/config.json), defaulting to tmux.
+func NewBackend(townRoot string) Backend {
+	if BackendName(townRoot) == BackendPTY {
+		return ptyd.ForTown(townRoot)
+	}
+	return tmux.NewTmux()
+}
+
+// BackendName returns the town's configured session backend.
+func BackendName(townRoot string) string {
+	if townRoot == "" {
+		return BackendTmux
+	}
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil || settings.SessionBackend == "" {
+		return BackendTmux
+	}
+	return settings.SessionBackend
+}
@@ -0,0 +1,34 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/ptyd"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+func TestNewBackend(t *testing.T) {
+	town := t.TempDir()
+	if _, ok := NewBackend(town).(*tmux.Tmux); !ok {
+		t.Error("expected tmux by default")
+	}
+	if _, ok := NewBackend("").(*tmux.Tmux); !ok {
+		t.Error("expected tmux without a town")
+	}
+
+	settings := filepath.Join(town, "settings", "config.json")
+	if err := os.MkdirAll(filepath.Dir(settings), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settings, []byte(`{"type": "town-settings", "session_backend": "pty"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := BackendName(town); got != BackendPTY {
+		t.Errorf("BackendName = %q, want %q", got, BackendPTY)
+	}
+	if _, ok := NewBackend(town).(*ptyd.Client); !ok {
+		t.Error("expected the pty supervisor client")
+	}
+}
@@ -4,8 +4,6 @@ package session
 import (
 	"fmt"
 	"time"
-
-	"github.com/steveyegge/gastown/internal/tmux"
 )
 
 // StartupNudgeConfig configures a startup nudge message.
@@ -40,7 +38,7 @@ type StartupNudgeConfig struct {
 //
 // The message content doesn't trigger GUPP - CLAUDE.md and hooks handle that.
 // The metadata makes sessions identifiable in /resume.
-func StartupNudge(t *tmux.Tmux, session string, cfg StartupNudgeConfig) error {
+func StartupNudge(t Backend, session string, cfg StartupNudgeConfig) error {
 	message := FormatStartupNudge(cfg)
 	return t.NudgeSession(session, message)
 }
@@ -9,7 +9,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/polecat"
-	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/session"
 )
 
 // LandingConfig configures the landing protocol.
@@ -58,8 +58,7 @@ func (m *Manager) ExecuteLanding(swarmID string, config LandingConfig) (*Landing
 	}
 
 	// Phase 1: Stop all polecat sessions
-	t := tmux.NewTmux()
-	polecatMgr := polecat.NewSessionManager(t, m.rig)
+	polecatMgr := polecat.NewSessionManager(session.NewBackend(config.TownRoot), m.rig)
 
 	for _, worker := range swarm.Workers {
 		running, _ := polecatMgr.IsRunning(worker)
@@ -149,11 +149,14 @@ func TestRenderRole_Refinery_DefaultBranch(t *testing.T) {
 	if !strings.Contains(output, "origin/develop") {
 		t.Error("output missing 'origin/develop' - DefaultBranch not being used for rebase")
 	}
-	if !strings.Contains(output, "git checkout develop") {
-		t.Error("output missing 'git checkout develop' - DefaultBranch not being used for checkout")
+
+	// Merges land through gt refinery merge, which runs the queue gates,
+	// never through a hand-run merge and push
+	if !strings.Contains(output, "gt refinery merge <mr-id>") {
+		t.Error("output missing 'gt refinery merge' - merge-push must go through the queue gates")
 	}
-	if !strings.Contains(output, "git push origin develop") {
-		t.Error("output missing 'git push origin develop' - DefaultBranch not being used for push")
+	if strings.Contains(output, "git merge --ff-only") {
+		t.Error("output contains a hand-run 'git merge --ff-only' - merge-push must use gt refinery merge")
 	}
 
 	// Verify it does NOT contain hardcoded "main" in git commands
@@ -0,0 +1,87 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// GitHub imports from GitHub Issues.
+type GitHub struct {
+	repo  string
+	query string
+	c     *client
+}
+
+func newGitHub(cfg Config) *GitHub {
+	api := cfg.APIURL
+	if api == "" {
+		api = DefaultGitHubAPI
+	}
+	return &GitHub{repo: cfg.Project, query: cfg.Query, c: newClient(api, cfg.HTTPClient, map[string]string{
+		"Authorization": "Bearer " + cfg.Token,
+		"Accept":        "application/vnd.github+json",
+	})}
+}
+
+// Name implements Tracker.
+func (g *GitHub) Name() string { return KindGitHub }
+
+// ListOpen implements Tracker.
+func (g *GitHub) ListOpen(ctx context.Context) ([]Issue, error) {
+	q := url.Values{"state": {"open"}, "per_page": {"100"}}
+	if g.query != "" {
+		q.Set("labels", g.query)
+	}
+	var raw []struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+		Labels  []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		// Pull requests are issues too in this API; skip them.
+		PullRequest json.RawMessage `json:"pull_request"`
+	}
+	if err := g.c.do(ctx, "GET", "/repos/"+g.repo+"/issues?"+q.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, r := range raw {
+		if len(r.PullRequest) > 0 {
+			continue
+		}
+		var labels []string
+		for _, l := range r.Labels {
+			labels = append(labels, l.Name)
+		}
+		key := strconv.Itoa(r.Number)
+		issues = append(issues, Issue{
+			Key:      key,
+			Ref:      g.repo + "#" + key,
+			Title:    r.Title,
+			Body:     r.Body,
+			URL:      r.HTMLURL,
+			Labels:   labels,
+			Priority: priorityFromLabels(labels),
+		})
+	}
+	return issues, nil
+}
+
+// Comment implements Tracker.
+func (g *GitHub) Comment(ctx context.Context, key, body string) error {
+	return g.c.do(ctx, "POST", "/repos/"+g.repo+"/issues/"+key+"/comments", map[string]string{"body": body}, nil)
+}
+
+// SetStatus implements Tracker. GitHub issues are only open or closed.
+func (g *GitHub) SetStatus(ctx context.Context, key string, status Status) error {
+	if status != StatusDone {
+		return nil
+	}
+	req := map[string]string{"state": "closed", "state_reason": "completed"}
+	return g.c.do(ctx, "PATCH", "/repos/"+g.repo+"/issues/"+key, req, nil)
+}
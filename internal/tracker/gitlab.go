@@ -0,0 +1,77 @@
+package tracker
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// GitLab imports from GitLab issues.
+type GitLab struct {
+	project string // project path, e.g. "group/widgets"
+	query   string
+	c       *client
+}
+
+func newGitLab(cfg Config) *GitLab {
+	api := cfg.APIURL
+	if api == "" {
+		api = DefaultGitLabAPI
+	}
+	return &GitLab{project: cfg.Project, query: cfg.Query, c: newClient(api, cfg.HTTPClient, map[string]string{
+		"PRIVATE-TOKEN": cfg.Token,
+	})}
+}
+
+// Name implements Tracker.
+func (g *GitLab) Name() string { return KindGitLab }
+
+func (g *GitLab) issuesPath() string {
+	return "/projects/" + url.PathEscape(g.project) + "/issues"
+}
+
+// ListOpen implements Tracker.
+func (g *GitLab) ListOpen(ctx context.Context) ([]Issue, error) {
+	q := url.Values{"state": {"opened"}, "per_page": {"100"}}
+	if g.query != "" {
+		q.Set("labels", g.query)
+	}
+	var raw []struct {
+		IID         int      `json:"iid"`
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		WebURL      string   `json:"web_url"`
+		Labels      []string `json:"labels"`
+	}
+	if err := g.c.do(ctx, "GET", g.issuesPath()+"?"+q.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, r := range raw {
+		key := strconv.Itoa(r.IID)
+		issues = append(issues, Issue{
+			Key:      key,
+			Ref:      g.project + "#" + key,
+			Title:    r.Title,
+			Body:     r.Description,
+			URL:      r.WebURL,
+			Labels:   r.Labels,
+			Priority: priorityFromLabels(r.Labels),
+		})
+	}
+	return issues, nil
+}
+
+// Comment implements Tracker.
+func (g *GitLab) Comment(ctx context.Context, key, body string) error {
+	return g.c.do(ctx, "POST", g.issuesPath()+"/"+key+"/notes", map[string]string{"body": body}, nil)
+}
+
+// SetStatus implements Tracker. GitLab issues are only opened or closed.
+func (g *GitLab) SetStatus(ctx context.Context, key string, status Status) error {
+	if status != StatusDone {
+		return nil
+	}
+	return g.c.do(ctx, "PUT", g.issuesPath()+"/"+key, map[string]string{"state_event": "close"}, nil)
+}
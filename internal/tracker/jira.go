@@ -0,0 +1,140 @@
+package tracker
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Jira imports from a Jira project (REST API v2, Cloud or Data Center).
+type Jira struct {
+	site    string
+	project string // project key, e.g. "PROJ"
+	query   string // extra JQL
+	c       *client
+}
+
+func newJira(cfg Config) *Jira {
+	auth := "Bearer " + cfg.Token
+	if cfg.User != "" {
+		auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(cfg.User+":"+cfg.Token))
+	}
+	return &Jira{
+		site:    strings.TrimRight(cfg.APIURL, "/"),
+		project: cfg.Project,
+		query:   cfg.Query,
+		c: newClient(cfg.APIURL, cfg.HTTPClient, map[string]string{
+			"Authorization": auth,
+			"Accept":        "application/json",
+		}),
+	}
+}
+
+// Name implements Tracker.
+func (j *Jira) Name() string { return KindJira }
+
+// jiraPriorities maps Jira's default priority scheme onto bead priorities.
+var jiraPriorities = map[string]int{
+	"highest":  0,
+	"blocker":  0,
+	"high":     1,
+	"critical": 1,
+	"medium":   2,
+	"major":    2,
+	"low":      3,
+	"minor":    3,
+	"lowest":   4,
+	"trivial":  4,
+}
+
+// ListOpen implements Tracker.
+func (j *Jira) ListOpen(ctx context.Context) ([]Issue, error) {
+	jql := fmt.Sprintf("project = %q AND statusCategory != Done", j.project)
+	if j.query != "" {
+		jql += " AND (" + j.query + ")"
+	}
+	jql += " ORDER BY created ASC"
+	q := url.Values{
+		"jql":        {jql},
+		"fields":     {"summary,description,priority,labels"},
+		"maxResults": {"100"},
+	}
+
+	var resp struct {
+		Issues []struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Summary     string   `json:"summary"`
+				Description string   `json:"description"`
+				Labels      []string `json:"labels"`
+				Priority    *struct {
+					Name string `json:"name"`
+				} `json:"priority"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err := j.c.do(ctx, "GET", "/rest/api/2/search?"+q.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, r := range resp.Issues {
+		priority := priorityFromLabels(r.Fields.Labels)
+		if r.Fields.Priority != nil {
+			if p, ok := jiraPriorities[strings.ToLower(r.Fields.Priority.Name)]; ok {
+				priority = p
+			}
+		}
+		issues = append(issues, Issue{
+			Key:      r.Key,
+			Ref:      r.Key,
+			Title:    r.Fields.Summary,
+			Body:     r.Fields.Description,
+			URL:      j.site + "/browse/" + r.Key,
+			Labels:   r.Fields.Labels,
+			Priority: priority,
+		})
+	}
+	return issues, nil
+}
+
+// Comment implements Tracker.
+func (j *Jira) Comment(ctx context.Context, key, body string) error {
+	return j.c.do(ctx, "POST", "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", map[string]string{"body": body}, nil)
+}
+
+// SetStatus implements Tracker. Workflows differ per project, so the
+// transition is picked by the status category it leads to rather than by
+// name.
+func (j *Jira) SetStatus(ctx context.Context, key string, status Status) error {
+	category := "done"
+	if status == StatusInProgress {
+		category = "indeterminate"
+	}
+
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	var resp struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := j.c.do(ctx, "GET", path, nil, &resp); err != nil {
+		return err
+	}
+	for _, t := range resp.Transitions {
+		if t.To.StatusCategory.Key == category {
+			return j.c.do(ctx, "POST", path, map[string]interface{}{"transition": map[string]string{"id": t.ID}}, nil)
+		}
+	}
+	if status == StatusInProgress {
+		return nil // already in progress, or the workflow skips it
+	}
+	return fmt.Errorf("jira issue %s has no transition to a done status", key)
+}
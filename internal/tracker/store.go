@@ -0,0 +1,226 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// FileImports is the import configuration and link map, under mayor/.
+const FileImports = "imports.json"
+
+// StoreVersion is the current imports.json schema version.
+const StoreVersion = 1
+
+// storeLockTimeout bounds the wait for another import or sync (which may be
+// talking to a slow tracker) to release imports.json.
+const storeLockTimeout = 5 * time.Minute
+
+// Source is a configured external tracker and the rig its issues land in.
+type Source struct {
+	// Name identifies the source in commands and links (e.g. "widgets-gh").
+	Name string `json:"name"`
+
+	// Kind is github, gitlab or jira.
+	Kind string `json:"kind"`
+
+	// APIURL overrides the tracker's API root; required for Jira.
+	APIURL string `json:"api_url,omitempty"`
+
+	// Project is the repository, GitLab project path, or Jira project key.
+	Project string `json:"project"`
+
+	// Query narrows the import: labels (GitHub, GitLab) or JQL (Jira).
+	Query string `json:"query,omitempty"`
+
+	// TokenEnv names the environment variable holding the API token.
+	// Default: GITHUB_TOKEN, GITLAB_TOKEN or JIRA_TOKEN.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// User is the Jira Cloud account email for basic auth.
+	User string `json:"user,omitempty"`
+
+	// Rig is the rig imported issues become beads in.
+	Rig string `json:"rig"`
+}
+
+// Open connects to the source's tracker, reading its token from the
+// environment.
+func (s *Source) Open(hc *http.Client) (Tracker, error) {
+	tokenEnv := s.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = DefaultTokenEnv(s.Kind)
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("source %s: no token, set $%s", s.Name, tokenEnv)
+	}
+	return New(Config{
+		Kind:       s.Kind,
+		APIURL:     s.APIURL,
+		Project:    s.Project,
+		Query:      s.Query,
+		Token:      token,
+		User:       s.User,
+		HTTPClient: hc,
+	})
+}
+
+// Link ties an external issue to the bead it was imported as.
+type Link struct {
+	Source     string    `json:"source"`
+	Key        string    `json:"key"`
+	Ref        string    `json:"ref"`
+	URL        string    `json:"url"`
+	Bead       string    `json:"bead"`
+	Rig        string    `json:"rig"`
+	ImportedAt time.Time `json:"imported_at"`
+
+	// Status is the bead status last pushed to the tracker ("" until the
+	// first push, then in_progress or closed).
+	Status   string     `json:"status,omitempty"`
+	SyncedAt *time.Time `json:"synced_at,omitempty"`
+
+	// LastError is the most recent sync failure, cleared on success.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Store is mayor/imports.json: the configured sources and the link map.
+type Store struct {
+	Version int       `json:"version"`
+	Sources []*Source `json:"sources"`
+	Links   []*Link   `json:"links"`
+
+	path string
+	lock *flock.Flock
+}
+
+// StorePath returns the path of a town's imports.json.
+func StorePath(townRoot string) string {
+	return filepath.Join(townRoot, constants.DirMayor, FileImports)
+}
+
+// LoadStore reads a town's imports.json. A missing file is an empty store.
+func LoadStore(townRoot string) (*Store, error) {
+	path := StorePath(townRoot)
+	s := &Store{Version: StoreVersion, path: path}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the town root
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", FileImports, err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", FileImports, err)
+	}
+	return s, nil
+}
+
+// LoadStoreLocked is LoadStore for a load-modify-save: it takes an
+// exclusive lock on imports.json first, so a concurrent gt import or daemon
+// sync can't overwrite the links saved here. The caller must Unlock.
+func LoadStoreLocked(townRoot string) (*Store, error) {
+	lockPath := StorePath(townRoot) + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, fmt.Errorf("creating %s dir: %w", FileImports, err)
+	}
+
+	lock := flock.New(lockPath)
+	ctx, cancel := context.WithTimeout(context.Background(), storeLockTimeout)
+	defer cancel()
+	locked, err := lock.TryLockContext(ctx, 100*time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("locking %s: %w", FileImports, err)
+	}
+	if !locked {
+		return nil, fmt.Errorf("timeout waiting for %s lock", FileImports)
+	}
+
+	s, err := LoadStore(townRoot)
+	if err != nil {
+		_ = lock.Unlock()
+		return nil, err
+	}
+	s.lock = lock
+	return s, nil
+}
+
+// Unlock releases the lock taken by LoadStoreLocked. It is a no-op for a
+// store loaded without one.
+func (s *Store) Unlock() {
+	if s.lock != nil {
+		_ = s.lock.Unlock()
+		s.lock = nil
+	}
+}
+
+// Save writes the store back to disk.
+func (s *Store) Save() error {
+	s.Version = StoreVersion
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating %s dir: %w", FileImports, err)
+	}
+	return util.AtomicWriteJSON(s.path, s)
+}
+
+// Source returns the named source, or nil.
+func (s *Store) Source(name string) *Source {
+	for _, src := range s.Sources {
+		if src.Name == name {
+			return src
+		}
+	}
+	return nil
+}
+
+// SetSource adds a source, replacing any source with the same name.
+func (s *Store) SetSource(src *Source) {
+	for i, existing := range s.Sources {
+		if existing.Name == src.Name {
+			s.Sources[i] = src
+			return
+		}
+	}
+	s.Sources = append(s.Sources, src)
+}
+
+// RemoveSource removes the named source. Its links are kept so beads
+// already imported stay traceable.
+func (s *Store) RemoveSource(name string) bool {
+	for i, src := range s.Sources {
+		if src.Name == name {
+			s.Sources = append(s.Sources[:i], s.Sources[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Link returns the link for an external issue, or nil.
+func (s *Store) Link(source, key string) *Link {
+	for _, l := range s.Links {
+		if l.Source == source && l.Key == key {
+			return l
+		}
+	}
+	return nil
+}
+
+// LinkForBead returns the link for a bead, or nil.
+func (s *Store) LinkForBead(bead string) *Link {
+	for _, l := range s.Links {
+		if l.Bead == bead {
+			return l
+		}
+	}
+	return nil
+}
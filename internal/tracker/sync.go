@@ -0,0 +1,303 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// LabelPrefix marks beads imported from a tracker; the rest of the label is
+// the external reference (external:acme/widgets#12, external:PROJ-12).
+const LabelPrefix = "external:"
+
+// ExternalLabel returns the label put on a bead imported from ref.
+func ExternalLabel(ref string) string {
+	return LabelPrefix + ref
+}
+
+// Town is what import and sync need from the town: creating and reading
+// beads, and finding the commit a bead was merged in.
+type Town interface {
+	// CreateBead creates a bead for an external issue in a rig.
+	CreateBead(rig string, issue Issue) (string, error)
+
+	// ShowBeads reads beads by ID from any rig. Missing IDs are left out.
+	ShowBeads(ids []string) map[string]*beads.Issue
+
+	// MergeCommit returns the commit a closed bead's work was merged in
+	// and a web link to it, if known.
+	MergeCommit(rig string, bead *beads.Issue) (sha, link string)
+}
+
+// Import creates beads for the source's open issues that are not linked
+// yet, records the links in the store, and returns the new links. With
+// dryRun, nothing is created and the would-be links have no bead.
+func Import(ctx context.Context, store *Store, src *Source, tr Tracker, town Town, dryRun bool) ([]*Link, error) {
+	issues, err := tr.ListOpen(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s issues: %w", src.Name, err)
+	}
+
+	var created []*Link
+	for _, issue := range issues {
+		if store.Link(src.Name, issue.Key) != nil {
+			continue
+		}
+		link := &Link{
+			Source:     src.Name,
+			Key:        issue.Key,
+			Ref:        issue.Ref,
+			URL:        issue.URL,
+			Rig:        src.Rig,
+			ImportedAt: time.Now(),
+		}
+		if dryRun {
+			created = append(created, link)
+			continue
+		}
+		id, err := town.CreateBead(src.Rig, issue)
+		if id != "" {
+			// Link even a partly failed create (e.g. labelling failed), so
+			// the next import doesn't make a duplicate bead.
+			link.Bead = id
+			store.Links = append(store.Links, link)
+			created = append(created, link)
+		}
+		if err != nil {
+			return created, fmt.Errorf("importing %s: %w", issue.Ref, err)
+		}
+	}
+	return created, nil
+}
+
+// Update is one status change pushed (or, in a dry run, to be pushed) to a
+// tracker.
+type Update struct {
+	Link    *Link
+	Status  Status
+	Comment string
+	Err     error
+}
+
+// Sync pushes bead status changes to the trackers of linked issues: a
+// comment when a bead goes in progress, and a comment naming the merge
+// commit plus closing the issue when the bead closes. Links are updated in
+// the store; failures are recorded per link and retried on the next sync.
+// open connects to a link's source; links whose source was removed are
+// skipped.
+func Sync(ctx context.Context, store *Store, town Town, open func(*Source) (Tracker, error), dryRun bool) []Update {
+	var pending []*Link
+	var ids []string
+	for _, l := range store.Links {
+		if l.Status == "closed" || l.Bead == "" {
+			continue
+		}
+		pending = append(pending, l)
+		ids = append(ids, l.Bead)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	shown := town.ShowBeads(ids)
+
+	trackers := make(map[string]Tracker)
+	openErrs := make(map[string]error)
+	var updates []Update
+	for _, l := range pending {
+		bead := shown[l.Bead]
+		if bead == nil {
+			continue
+		}
+		u := planUpdate(l, bead, town)
+		if u == nil {
+			continue
+		}
+		if dryRun {
+			updates = append(updates, *u)
+			continue
+		}
+
+		src := store.Source(l.Source)
+		if src == nil {
+			continue
+		}
+		tr, ok := trackers[src.Name]
+		if !ok && openErrs[src.Name] == nil {
+			tr, openErrs[src.Name] = open(src)
+			trackers[src.Name] = tr
+		}
+		if err := openErrs[src.Name]; err != nil {
+			u.Err = err
+		} else {
+			u.Err = push(ctx, tr, l.Key, u)
+		}
+
+		now := time.Now()
+		l.SyncedAt = &now
+		if u.Err != nil {
+			l.LastError = u.Err.Error()
+		} else {
+			l.LastError = ""
+			l.Status = bead.Status
+			if u.Status == StatusDone {
+				l.Status = "closed"
+			}
+		}
+		updates = append(updates, *u)
+	}
+	return updates
+}
+
+// planUpdate decides what to tell the tracker about a bead, or nil if
+// nothing changed since the last push.
+func planUpdate(l *Link, bead *beads.Issue, town Town) *Update {
+	switch bead.Status {
+	case "closed":
+		comment := fmt.Sprintf("Closed in Gas Town (%s).", bead.ID)
+		if bead.CloseReason != "" {
+			comment = fmt.Sprintf("Closed in Gas Town (%s): %s", bead.ID, bead.CloseReason)
+		}
+		if sha, link := town.MergeCommit(l.Rig, bead); sha != "" {
+			if link != "" {
+				comment += fmt.Sprintf("\n\nMerge commit: [%s](%s)", shortSHA(sha), link)
+			} else {
+				comment += "\n\nMerge commit: " + sha
+			}
+		}
+		return &Update{Link: l, Status: StatusDone, Comment: comment}
+	case "in_progress", "hooked":
+		if l.Status == "in_progress" || l.Status == "hooked" {
+			return nil
+		}
+		comment := fmt.Sprintf("Work started in Gas Town (%s).", bead.ID)
+		if bead.Assignee != "" {
+			comment = fmt.Sprintf("Work started in Gas Town (%s), assigned to %s.", bead.ID, bead.Assignee)
+		}
+		return &Update{Link: l, Status: StatusInProgress, Comment: comment}
+	}
+	return nil
+}
+
+func push(ctx context.Context, tr Tracker, key string, u *Update) error {
+	if err := tr.Comment(ctx, key, u.Comment); err != nil {
+		return err
+	}
+	return tr.SetStatus(ctx, key, u.Status)
+}
+
+// TownBeads implements Town with bd, routing beads by rig prefix.
+type TownBeads struct {
+	TownRoot string
+}
+
+// rigBeadsDir returns the directory to run bd in for a rig.
+func (t *TownBeads) rigBeadsDir(rig string) string {
+	prefix := beads.GetPrefixForRig(t.TownRoot, rig) + "-"
+	if dir := beads.GetRigPathForPrefix(t.TownRoot, prefix); dir != "" {
+		return dir
+	}
+	return filepath.Join(t.TownRoot, rig)
+}
+
+// CreateBead implements Town.
+func (t *TownBeads) CreateBead(rig string, issue Issue) (string, error) {
+	desc := strings.TrimSpace(issue.Body)
+	if desc != "" {
+		desc += "\n\n"
+	}
+	desc += "Imported from " + issue.URL
+
+	bd := beads.New(t.rigBeadsDir(rig))
+	created, err := bd.Create(beads.CreateOptions{
+		Title:       issue.Title,
+		Priority:    issue.Priority,
+		Description: desc,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := bd.Update(created.ID, beads.UpdateOptions{AddLabels: []string{ExternalLabel(issue.Ref)}}); err != nil {
+		return created.ID, fmt.Errorf("labelling %s: %w", created.ID, err)
+	}
+	return created.ID, nil
+}
+
+// ShowBeads implements Town.
+func (t *TownBeads) ShowBeads(ids []string) map[string]*beads.Issue {
+	return beads.ShowRouted(t.TownRoot, ids)
+}
+
+// mergedInRe matches the refinery's close reason for merged source issues.
+var mergedInRe = regexp.MustCompile(`Merged in (\S+)`)
+
+// MergeCommit implements Town. The refinery closes source issues with
+// "Merged in <mr-id>" and records the merge commit on the MR bead.
+func (t *TownBeads) MergeCommit(rig string, bead *beads.Issue) (string, string) {
+	m := mergedInRe.FindStringSubmatch(bead.CloseReason)
+	if m == nil {
+		return "", ""
+	}
+	mr := beads.ShowRouted(t.TownRoot, []string{m[1]})[m[1]]
+	if mr == nil {
+		return "", ""
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil || fields.MergeCommit == "" {
+		return "", ""
+	}
+
+	var link string
+	rigs, err := config.LoadRigsConfig(filepath.Join(t.TownRoot, constants.DirMayor, constants.FileRigsJSON))
+	if err == nil {
+		if entry, ok := rigs.Rigs[rig]; ok {
+			link = CommitURL(entry.GitURL, fields.MergeCommit)
+		}
+	}
+	return fields.MergeCommit, link
+}
+
+// CommitURL builds a web link to a commit from a git remote URL hosted on
+// GitHub, GitLab or a Gitea-style forge. Returns "" for remotes that have
+// no web host (local paths).
+func CommitURL(remote, sha string) string {
+	remote = strings.TrimSpace(remote)
+	var host, path string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil || u.Scheme == "file" || u.Host == "" {
+			return ""
+		}
+		host, path = u.Hostname(), u.Path
+	} else if i := strings.Index(remote, ":"); i > 0 && !strings.Contains(remote[:i], "/") {
+		host = remote[:i]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		path = remote[i+1:]
+	} else {
+		return ""
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return ""
+	}
+	if strings.Contains(host, "gitlab") {
+		return fmt.Sprintf("https://%s/%s/-/commit/%s", host, path, sha)
+	}
+	return fmt.Sprintf("https://%s/%s/commit/%s", host, path, sha)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,232 @@
+// Package tracker connects external issue trackers (GitHub Issues, GitLab,
+// Jira) to beads.
+//
+// `gt import` pulls open issues from a configured source into beads in the
+// source's rig, labelled with their external reference. The links between
+// external issues and beads are kept in mayor/imports.json, and a sync pass
+// (`gt import sync`, or the daemon's import_sync patrol) pushes bead status
+// changes back to the tracker: a comment when work starts, and a comment
+// with the merge commit plus closing the issue when the bead closes.
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Tracker kinds.
+const (
+	KindGitHub = "github"
+	KindGitLab = "gitlab"
+	KindJira   = "jira"
+)
+
+// Default API endpoints. Jira sites have no default.
+const (
+	DefaultGitHubAPI = "https://api.github.com"
+	DefaultGitLabAPI = "https://gitlab.com/api/v4"
+)
+
+// DefaultPriority is the bead priority for issues that don't carry one.
+const DefaultPriority = 2
+
+// Issue is the tracker-neutral view of an external issue.
+type Issue struct {
+	// Key identifies the issue within its source: the issue number for
+	// GitHub and GitLab, the issue key (PROJ-12) for Jira.
+	Key string `json:"key"`
+
+	// Ref is the human-readable reference (acme/widgets#12, PROJ-12).
+	Ref string `json:"ref"`
+
+	Title    string   `json:"title"`
+	Body     string   `json:"body,omitempty"`
+	URL      string   `json:"url"`
+	Labels   []string `json:"labels,omitempty"`
+	Priority int      `json:"priority"`
+}
+
+// Status is a work state pushed back to a tracker.
+type Status string
+
+const (
+	StatusInProgress Status = "in_progress"
+	StatusDone       Status = "done"
+)
+
+// Tracker is an external issue tracker.
+type Tracker interface {
+	// Name returns the tracker kind (github, gitlab, jira).
+	Name() string
+
+	// ListOpen returns the open issues matching the source's query.
+	ListOpen(ctx context.Context) ([]Issue, error)
+
+	// Comment posts a comment on an issue.
+	Comment(ctx context.Context, key, body string) error
+
+	// SetStatus moves an issue to a work state. Trackers without an
+	// in-progress state treat StatusInProgress as a no-op.
+	SetStatus(ctx context.Context, key string, status Status) error
+}
+
+// Config selects and authenticates a tracker.
+type Config struct {
+	// Kind is github, gitlab or jira.
+	Kind string
+
+	// APIURL is the API root (GitHub, GitLab) or site URL (Jira).
+	APIURL string
+
+	// Project is the repository ("owner/name"), GitLab project path, or
+	// Jira project key.
+	Project string
+
+	// Query narrows the import: comma-separated labels for GitHub and
+	// GitLab, a JQL clause for Jira.
+	Query string
+
+	// Token authenticates API requests.
+	Token string
+
+	// User is the Jira Cloud account email for basic auth. Without it the
+	// token is sent as a bearer token (Jira Data Center PATs).
+	User string
+
+	// HTTPClient overrides the default client (used by tests).
+	HTTPClient *http.Client
+}
+
+// New returns the tracker described by cfg.
+func New(cfg Config) (Tracker, error) {
+	if cfg.Project == "" {
+		return nil, fmt.Errorf("tracker needs a project")
+	}
+	switch cfg.Kind {
+	case KindGitHub:
+		if !strings.Contains(cfg.Project, "/") {
+			return nil, fmt.Errorf("github project %q must be owner/name", cfg.Project)
+		}
+		return newGitHub(cfg), nil
+	case KindGitLab:
+		return newGitLab(cfg), nil
+	case KindJira:
+		if cfg.APIURL == "" {
+			return nil, fmt.Errorf("jira needs an api_url (e.g. https://acme.atlassian.net)")
+		}
+		return newJira(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown tracker %q (want github, gitlab or jira)", cfg.Kind)
+	}
+}
+
+// DefaultTokenEnv returns the environment variable a tracker's token is
+// read from when none is configured.
+func DefaultTokenEnv(kind string) string {
+	switch kind {
+	case KindGitLab:
+		return "GITLAB_TOKEN"
+	case KindJira:
+		return "JIRA_TOKEN"
+	default:
+		return "GITHUB_TOKEN"
+	}
+}
+
+var priorityLabel = regexp.MustCompile(`(?i)^(?:priority[:/ ]*)?p([0-4])$`)
+
+// priorityFromLabels reads a P0-P4 style label, defaulting to
+// DefaultPriority.
+func priorityFromLabels(labels []string) int {
+	for _, l := range labels {
+		if m := priorityLabel.FindStringSubmatch(strings.TrimSpace(l)); m != nil {
+			return int(m[1][0] - '0')
+		}
+	}
+	return DefaultPriority
+}
+
+// APIError is a non-2xx response from a tracker API.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+// client is a minimal JSON REST client shared by the trackers.
+type client struct {
+	base    string
+	http    *http.Client
+	headers map[string]string
+}
+
+func newClient(base string, hc *http.Client, headers map[string]string) *client {
+	if hc == nil {
+		hc = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &client{base: strings.TrimRight(base, "/"), http: hc, headers: headers}
+}
+
+// do sends a JSON request and decodes a JSON response into out (if non-nil).
+func (c *client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: msg}
+	}
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,409 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// request is one call recorded by the fixture server.
+type request struct {
+	Method, Path, Query string
+	Header              http.Header
+	Body                map[string]interface{}
+}
+
+// fixtureServer replays recorded API responses from testdata. Routes map
+// "METHOD /path" to a fixture file name ("" for an empty 200/201).
+func fixtureServer(t *testing.T, routes map[string]string) (*httptest.Server, *[]request) {
+	t.Helper()
+	var calls []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{Method: r.Method, Path: r.URL.EscapedPath(), Query: r.URL.RawQuery, Header: r.Header.Clone()}
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			_ = json.Unmarshal(data, &req.Body)
+		}
+		calls = append(calls, req)
+
+		fixture, ok := routes[r.Method+" "+req.Path]
+		if !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		if fixture == "" {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte("{}"))
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Errorf("reading fixture: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestGitHub_ListAndUpdate(t *testing.T) {
+	srv, calls := fixtureServer(t, map[string]string{
+		"GET /repos/acme/widgets/issues":              "github_issues.json",
+		"POST /repos/acme/widgets/issues/12/comments": "",
+		"PATCH /repos/acme/widgets/issues/12":         "",
+	})
+	tr, err := New(Config{Kind: KindGitHub, APIURL: srv.URL, Project: "acme/widgets", Query: "gastown", Token: "tok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := tr.ListOpen(context.Background())
+	if err != nil {
+		t.Fatalf("ListOpen: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("got %d issues, want 2 (pull requests skipped): %+v", len(issues), issues)
+	}
+	if got := issues[0]; got.Key != "12" || got.Ref != "acme/widgets#12" || got.Priority != 1 {
+		t.Errorf("issue = %+v", got)
+	}
+	if issues[1].Priority != DefaultPriority {
+		t.Errorf("unlabelled priority = %d, want %d", issues[1].Priority, DefaultPriority)
+	}
+	if q := (*calls)[0].Query; !strings.Contains(q, "labels=gastown") || !strings.Contains(q, "state=open") {
+		t.Errorf("query = %q", q)
+	}
+	if auth := (*calls)[0].Header.Get("Authorization"); auth != "Bearer tok" {
+		t.Errorf("Authorization = %q", auth)
+	}
+
+	if err := tr.SetStatus(context.Background(), "12", StatusInProgress); err != nil {
+		t.Fatalf("SetStatus(in_progress): %v", err)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("in_progress should not call GitHub, got %d calls", len(*calls))
+	}
+	if err := tr.Comment(context.Background(), "12", "done"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	if err := tr.SetStatus(context.Background(), "12", StatusDone); err != nil {
+		t.Fatalf("SetStatus(done): %v", err)
+	}
+	last := (*calls)[len(*calls)-1]
+	if last.Body["state"] != "closed" {
+		t.Errorf("close body = %v", last.Body)
+	}
+}
+
+func TestGitLab_ListAndUpdate(t *testing.T) {
+	base := "/projects/acme%2Fplatform%2Flogin/issues"
+	srv, calls := fixtureServer(t, map[string]string{
+		"GET " + base:               "gitlab_issues.json",
+		"POST " + base + "/7/notes": "",
+		"PUT " + base + "/7":        "",
+	})
+	tr, err := New(Config{Kind: KindGitLab, APIURL: srv.URL, Project: "acme/platform/login", Token: "tok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := tr.ListOpen(context.Background())
+	if err != nil {
+		t.Fatalf("ListOpen: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Ref != "acme/platform/login#7" || issues[0].Priority != 0 {
+		t.Fatalf("issues = %+v", issues)
+	}
+	if h := (*calls)[0].Header.Get("PRIVATE-TOKEN"); h != "tok" {
+		t.Errorf("PRIVATE-TOKEN = %q", h)
+	}
+
+	if err := tr.Comment(context.Background(), "7", "merged"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	if err := tr.SetStatus(context.Background(), "7", StatusDone); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	if last := (*calls)[len(*calls)-1]; last.Body["state_event"] != "close" {
+		t.Errorf("close body = %v", last.Body)
+	}
+}
+
+func TestJira_ListAndTransition(t *testing.T) {
+	srv, calls := fixtureServer(t, map[string]string{
+		"GET /rest/api/2/search":                     "jira_search.json",
+		"GET /rest/api/2/issue/OPS-101/transitions":  "jira_transitions.json",
+		"POST /rest/api/2/issue/OPS-101/transitions": "",
+		"POST /rest/api/2/issue/OPS-101/comment":     "",
+	})
+	tr, err := New(Config{Kind: KindJira, APIURL: srv.URL, Project: "OPS", Query: "labels = gastown", Token: "tok", User: "ops@acme.dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := tr.ListOpen(context.Background())
+	if err != nil {
+		t.Fatalf("ListOpen: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("got %d issues", len(issues))
+	}
+	if got := issues[0]; got.Key != "OPS-101" || got.Priority != 1 || got.URL != srv.URL+"/browse/OPS-101" {
+		t.Errorf("issue = %+v", got)
+	}
+	if issues[1].Priority != DefaultPriority {
+		t.Errorf("no-priority issue = %d", issues[1].Priority)
+	}
+	if q := (*calls)[0].Query; !strings.Contains(q, "labels+%3D+gastown") || !strings.Contains(q, "statusCategory") {
+		t.Errorf("jql query = %q", q)
+	}
+	if auth := (*calls)[0].Header.Get("Authorization"); !strings.HasPrefix(auth, "Basic ") {
+		t.Errorf("Authorization = %q, want basic auth", auth)
+	}
+
+	if err := tr.SetStatus(context.Background(), "OPS-101", StatusInProgress); err != nil {
+		t.Fatalf("SetStatus(in_progress): %v", err)
+	}
+	if body := (*calls)[len(*calls)-1].Body; body["transition"].(map[string]interface{})["id"] != "21" {
+		t.Errorf("in-progress transition = %v", body)
+	}
+	if err := tr.SetStatus(context.Background(), "OPS-101", StatusDone); err != nil {
+		t.Fatalf("SetStatus(done): %v", err)
+	}
+	if body := (*calls)[len(*calls)-1].Body; body["transition"].(map[string]interface{})["id"] != "31" {
+		t.Errorf("done transition = %v", body)
+	}
+}
+
+// fakeTown records created beads and serves their statuses.
+type fakeTown struct {
+	created []Issue
+	beads   map[string]*beads.Issue
+
+	// createErr fails each create after the bead exists, like a failed label.
+	createErr error
+}
+
+func (f *fakeTown) CreateBead(rig string, issue Issue) (string, error) {
+	f.created = append(f.created, issue)
+	id := "gt-" + issue.Key
+	f.beads[id] = &beads.Issue{ID: id, Title: issue.Title, Status: "open"}
+	return id, f.createErr
+}
+
+func (f *fakeTown) ShowBeads(ids []string) map[string]*beads.Issue {
+	out := make(map[string]*beads.Issue)
+	for _, id := range ids {
+		if b, ok := f.beads[id]; ok {
+			out[id] = b
+		}
+	}
+	return out
+}
+
+func (f *fakeTown) MergeCommit(rig string, bead *beads.Issue) (string, string) {
+	if bead.CloseReason == "Merged in gt-mr-1" {
+		return "abc123def456", "https://github.com/acme/widgets/commit/abc123def456"
+	}
+	return "", ""
+}
+
+func TestImportLinksPartlyFailedCreate(t *testing.T) {
+	srv, _ := fixtureServer(t, map[string]string{
+		"GET /repos/acme/widgets/issues": "github_issues.json",
+	})
+	t.Setenv("GITHUB_TOKEN", "tok")
+
+	store, err := LoadStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := &Source{Name: "widgets", Kind: KindGitHub, APIURL: srv.URL, Project: "acme/widgets", Rig: "gastown"}
+	tr, err := src.Open(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ft := &fakeTown{beads: map[string]*beads.Issue{}, createErr: errors.New("labelling failed")}
+
+	links, err := Import(context.Background(), store, src, tr, ft, false)
+	if err == nil {
+		t.Fatal("expected the create error")
+	}
+	if len(links) != 1 || store.Link("widgets", links[0].Key) == nil {
+		t.Fatalf("partly created bead not linked: links %+v, store %+v", links, store.Links)
+	}
+
+	// The retry skips the linked issue instead of creating it again
+	ft.createErr = nil
+	if _, err := Import(context.Background(), store, src, tr, ft, false); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if len(ft.created) != 2 || len(store.Links) != 2 {
+		t.Errorf("created %d beads for %d links, want 2 and 2", len(ft.created), len(store.Links))
+	}
+}
+
+func TestLoadStoreLocked(t *testing.T) {
+	town := t.TempDir()
+	store, err := LoadStoreLocked(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := flock.New(StorePath(town) + ".lock")
+	if ok, _ := other.TryLock(); ok {
+		t.Fatal("imports.json lock taken twice")
+	}
+	store.Unlock()
+	if ok, err := other.TryLock(); !ok || err != nil {
+		t.Fatalf("lock not released: %v", err)
+	}
+	_ = other.Unlock()
+}
+
+func TestImportAndSync(t *testing.T) {
+	srv, calls := fixtureServer(t, map[string]string{
+		"GET /repos/acme/widgets/issues":              "github_issues.json",
+		"POST /repos/acme/widgets/issues/12/comments": "",
+		"PATCH /repos/acme/widgets/issues/12":         "",
+		"POST /repos/acme/widgets/issues/14/comments": "",
+	})
+	t.Setenv("GITHUB_TOKEN", "tok")
+
+	town := t.TempDir()
+	store, err := LoadStore(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := &Source{Name: "widgets", Kind: KindGitHub, APIURL: srv.URL, Project: "acme/widgets", Rig: "gastown"}
+	store.SetSource(src)
+	ft := &fakeTown{beads: map[string]*beads.Issue{}}
+	open := func(s *Source) (Tracker, error) { return s.Open(nil) }
+
+	tr, err := open(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	links, err := Import(context.Background(), store, src, tr, ft, false)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(links) != 2 || links[0].Bead != "gt-12" || links[0].Rig != "gastown" {
+		t.Fatalf("links = %+v", links)
+	}
+
+	// Re-importing skips linked issues.
+	links, err = Import(context.Background(), store, src, tr, ft, false)
+	if err != nil || len(links) != 0 {
+		t.Fatalf("re-import created %d links (err %v)", len(links), err)
+	}
+
+	// Round-trip the store through disk.
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	store, err = LoadStore(town)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.LinkForBead("gt-14") == nil || store.Source("widgets") == nil {
+		t.Fatalf("store lost data: %+v", store)
+	}
+
+	ft.beads["gt-12"].Status = "closed"
+	ft.beads["gt-12"].CloseReason = "Merged in gt-mr-1"
+	ft.beads["gt-14"].Status = "in_progress"
+	n := len(*calls)
+
+	updates := Sync(context.Background(), store, ft, open, false)
+	if len(updates) != 2 {
+		t.Fatalf("updates = %+v", updates)
+	}
+	for _, u := range updates {
+		if u.Err != nil {
+			t.Errorf("update %s: %v", u.Link.Ref, u.Err)
+		}
+	}
+	var closeComment string
+	for _, c := range (*calls)[n:] {
+		if c.Path == "/repos/acme/widgets/issues/12/comments" {
+			closeComment, _ = c.Body["body"].(string)
+		}
+	}
+	if !strings.Contains(closeComment, "abc123de") || !strings.Contains(closeComment, "/commit/abc123def456") {
+		t.Errorf("close comment missing merge commit link: %q", closeComment)
+	}
+	if l := store.LinkForBead("gt-12"); l.Status != "closed" {
+		t.Errorf("gt-12 link status = %q", l.Status)
+	}
+	if l := store.LinkForBead("gt-14"); l.Status != "in_progress" {
+		t.Errorf("gt-14 link status = %q", l.Status)
+	}
+
+	// Nothing changed since: no further pushes.
+	if updates := Sync(context.Background(), store, ft, open, false); len(updates) != 0 {
+		t.Errorf("second sync pushed %d updates", len(updates))
+	}
+}
+
+func TestCommitURL(t *testing.T) {
+	tests := []struct{ remote, want string }{
+		{"https://github.com/acme/widgets.git", "https://github.com/acme/widgets/commit/abc"},
+		{"git@github.com:acme/widgets.git", "https://github.com/acme/widgets/commit/abc"},
+		{"git@gitlab.com:acme/platform/login.git", "https://gitlab.com/acme/platform/login/-/commit/abc"},
+		{"/srv/git/widgets.git", ""},
+		{"file:///srv/git/widgets.git", ""},
+	}
+	for _, tt := range tests {
+		if got := CommitURL(tt.remote, "abc"); got != tt.want {
+			t.Errorf("CommitURL(%q) = %q, want %q", tt.remote, got, tt.want)
+		}
+	}
+}
+
+func TestTownBeads_ClosedBead(t *testing.T) {
+	t.Setenv("GT_BEADS_CACHE", "")
+	town := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(town, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".beads/routes.jsonl", `{"prefix":"gt-","path":"gastown"}`+"\n")
+	write("mayor/rigs.json", `{"version":1,"rigs":{"gastown":{"git_url":"git@github.com:acme/widgets.git"}}}`)
+	write("gastown/.beads/issues.jsonl",
+		`{"id":"gt-12","title":"Fix widgets","status":"closed","priority":1,"issue_type":"task","close_reason":"Merged in gt-mr1"}`+"\n"+
+			`{"id":"gt-mr1","title":"Merge: gt-12","status":"closed","priority":1,"issue_type":"merge-request","description":"branch: polecat/nux\ntarget: main\nmerge_commit: abc123def456"}`+"\n")
+
+	tb := &TownBeads{TownRoot: town}
+	bead := tb.ShowBeads([]string{"gt-12"})["gt-12"]
+	if bead == nil || bead.CloseReason != "Merged in gt-mr1" {
+		t.Fatalf("ShowBeads(gt-12) = %+v, want close_reason loaded", bead)
+	}
+
+	u := planUpdate(&Link{Bead: "gt-12", Rig: "gastown"}, bead, tb)
+	if u == nil || u.Status != StatusDone {
+		t.Fatalf("planUpdate() = %+v, want done", u)
+	}
+	if !strings.Contains(u.Comment, "Merged in gt-mr1") ||
+		!strings.Contains(u.Comment, "https://github.com/acme/widgets/commit/abc123def456") {
+		t.Errorf("close comment = %q, want reason and merge commit link", u.Comment)
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/beadstore"
 )
 
 // convoyIDPattern validates convoy IDs to prevent SQL injection
@@ -86,8 +88,25 @@ func FetchConvoys(townRoot string) (*ConvoyState, error) {
 	return state, nil
 }
 
-// listConvoys returns convoys with the given status
+// listConvoys returns convoys with the given status, from the cached
+// beads snapshot when available, otherwise from bd list.
 func listConvoys(beadsDir, status string) ([]convoyListItem, error) {
+	if snap, err := beadstore.For(beadsDir).Snapshot(); err == nil {
+		var items []convoyListItem
+		for _, rec := range snap.ByType("convoy") {
+			if rec.Status == status {
+				items = append(items, convoyListItem{
+					ID:        rec.ID,
+					Title:     rec.Title,
+					Status:    rec.Status,
+					CreatedAt: rec.CreatedAt,
+					ClosedAt:  rec.ClosedAt,
+				})
+			}
+		}
+		return items, nil
+	}
+
 	listArgs := []string{"list", "--type=convoy", "--status=" + status, "--json"}
 
 	ctx, cancel := context.WithTimeout(context.Background(), convoySubprocessTimeout)
@@ -157,6 +176,34 @@ type trackedStatus struct {
 
 // getTrackedIssueStatus queries tracked issues and their status
 func getTrackedIssueStatus(beadsDir, convoyID string) []trackedStatus {
+	issueIDs := getTrackedIssueIDs(beadsDir, convoyID)
+	if len(issueIDs) == 0 {
+		return nil
+	}
+
+	// Fetch all statuses in one batch, routed to each issue's rig
+	issues := beads.ShowRouted(filepath.Dir(beadsDir), issueIDs)
+
+	tracked := make([]trackedStatus, 0, len(issueIDs))
+	for _, id := range issueIDs {
+		status := "unknown"
+		if issue, ok := issues[id]; ok {
+			status = issue.Status
+		}
+		tracked = append(tracked, trackedStatus{ID: id, Status: status})
+	}
+	return tracked
+}
+
+// getTrackedIssueIDs returns the issue IDs a convoy tracks, from the cached
+// beads snapshot or, failing that, a direct SQLite query.
+func getTrackedIssueIDs(beadsDir, convoyID string) []string {
+	if snap, err := beadstore.For(beadsDir).Snapshot(); err == nil {
+		if _, ok := snap.Get(convoyID); ok {
+			return snap.Tracked(convoyID)
+		}
+	}
+
 	// Validate convoyID to prevent SQL injection
 	if !convoyIDPattern.MatchString(convoyID) {
 		return nil
@@ -185,47 +232,12 @@ func getTrackedIssueStatus(beadsDir, convoyID string) []trackedStatus {
 		return nil
 	}
 
-	var tracked []trackedStatus
+	issueIDs := make([]string, 0, len(deps))
 	for _, dep := range deps {
-		issueID := dep.DependsOnID
-
 		// Handle external reference format: external:rig:issue-id
-		if strings.HasPrefix(issueID, "external:") {
-			parts := strings.SplitN(issueID, ":", 3)
-			if len(parts) == 3 {
-				issueID = parts[2]
-			}
-		}
-
-		// Get issue status
-		status := getIssueStatus(issueID)
-		tracked = append(tracked, trackedStatus{ID: issueID, Status: status})
-	}
-
-	return tracked
-}
-
-// getIssueStatus fetches just the status of an issue
-func getIssueStatus(issueID string) string {
-	ctx, cancel := context.WithTimeout(context.Background(), convoySubprocessTimeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "bd", "show", issueID, "--json")
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-
-	if err := cmd.Run(); err != nil {
-		return "unknown"
+		issueIDs = append(issueIDs, beadstore.StripExternal(dep.DependsOnID))
 	}
-
-	var issues []struct {
-		Status string `json:"status"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil || len(issues) == 0 {
-		return "unknown"
-	}
-
-	return issues[0].Status
+	return issueIDs
 }
 
 // Convoy panel styles
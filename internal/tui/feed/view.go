@@ -255,7 +255,7 @@ func (m *Model) renderEvent(e Event) string {
 		symbolStyle = EventUpdateStyle
 	case "complete", "patrol_complete", "merged", "done":
 		symbolStyle = EventCompleteStyle
-	case "fail", "merge_failed":
+	case "fail", "merge_failed", "merge_reverted":
 		symbolStyle = EventFailStyle
 	case "delete":
 		symbolStyle = EventDeleteStyle
@@ -10,7 +10,10 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/activity"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/beadstore"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -36,26 +39,9 @@ func NewLiveConvoyFetcher() (*LiveConvoyFetcher, error) {
 
 // FetchConvoys fetches all open convoys with their activity data.
 func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
-	// List all open convoy-type issues
-	listArgs := []string{"list", "--type=convoy", "--status=open", "--json"}
-	listCmd := exec.Command("bd", listArgs...)
-	listCmd.Dir = f.townBeads
-
-	var stdout bytes.Buffer
-	listCmd.Stdout = &stdout
-
-	if err := listCmd.Run(); err != nil {
-		return nil, fmt.Errorf("listing convoys: %w", err)
-	}
-
-	var convoys []struct {
-		ID        string `json:"id"`
-		Title     string `json:"title"`
-		Status    string `json:"status"`
-		CreatedAt string `json:"created_at"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
-		return nil, fmt.Errorf("parsing convoy list: %w", err)
+	convoys, err := f.listOpenConvoys()
+	if err != nil {
+		return nil, err
 	}
 
 	// Build convoy rows with activity data
@@ -143,6 +129,46 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 	return rows, nil
 }
 
+// convoyItem is an open convoy as listed by bd.
+type convoyItem struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+}
+
+// listOpenConvoys lists open convoys from the cached town beads snapshot,
+// falling back to bd list.
+func (f *LiveConvoyFetcher) listOpenConvoys() ([]convoyItem, error) {
+	if snap, err := beadstore.For(f.townBeads).Snapshot(); err == nil {
+		var convoys []convoyItem
+		for _, rec := range snap.ByType("convoy") {
+			if rec.Status == "open" {
+				convoys = append(convoys, convoyItem{ID: rec.ID, Title: rec.Title, Status: rec.Status, CreatedAt: rec.CreatedAt})
+			}
+		}
+		return convoys, nil
+	}
+
+	// List all open convoy-type issues
+	listArgs := []string{"list", "--type=convoy", "--status=open", "--json"}
+	listCmd := exec.Command("bd", listArgs...)
+	listCmd.Dir = f.townBeads
+
+	var stdout bytes.Buffer
+	listCmd.Stdout = &stdout
+
+	if err := listCmd.Run(); err != nil {
+		return nil, fmt.Errorf("listing convoys: %w", err)
+	}
+
+	var convoys []convoyItem
+	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
+		return nil, fmt.Errorf("parsing convoy list: %w", err)
+	}
+	return convoys, nil
+}
+
 // trackedIssueInfo holds info about an issue being tracked by a convoy.
 type trackedIssueInfo struct {
 	ID           string
@@ -155,41 +181,11 @@ type trackedIssueInfo struct {
 
 // getTrackedIssues fetches tracked issues for a convoy.
 func (f *LiveConvoyFetcher) getTrackedIssues(convoyID string) []trackedIssueInfo {
-	dbPath := filepath.Join(f.townBeads, "beads.db")
-
-	// Query tracked dependencies from SQLite
-	safeConvoyID := strings.ReplaceAll(convoyID, "'", "''")
-	// #nosec G204 -- sqlite3 path is from trusted config, convoyID is escaped
-	queryCmd := exec.Command("sqlite3", "-json", dbPath,
-		fmt.Sprintf(`SELECT depends_on_id, type FROM dependencies WHERE issue_id = '%s' AND type = 'tracks'`, safeConvoyID))
-
-	var stdout bytes.Buffer
-	queryCmd.Stdout = &stdout
-	if err := queryCmd.Run(); err != nil {
-		return nil
-	}
-
-	var deps []struct {
-		DependsOnID string `json:"depends_on_id"`
-		Type        string `json:"type"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &deps); err != nil {
+	issueIDs := f.getTrackedIDs(convoyID)
+	if len(issueIDs) == 0 {
 		return nil
 	}
 
-	// Collect issue IDs (normalize external refs)
-	issueIDs := make([]string, 0, len(deps))
-	for _, dep := range deps {
-		issueID := dep.DependsOnID
-		if strings.HasPrefix(issueID, "external:") {
-			parts := strings.SplitN(issueID, ":", 3)
-			if len(parts) == 3 {
-				issueID = parts[2]
-			}
-		}
-		issueIDs = append(issueIDs, issueID)
-	}
-
 	// Batch fetch issue details
 	details := f.getIssueDetailsBatch(issueIDs)
 
@@ -221,6 +217,45 @@ func (f *LiveConvoyFetcher) getTrackedIssues(convoyID string) []trackedIssueInfo
 	return result
 }
 
+// getTrackedIDs returns the issue IDs a convoy tracks, from the cached town
+// beads snapshot or, failing that, a direct SQLite query.
+func (f *LiveConvoyFetcher) getTrackedIDs(convoyID string) []string {
+	if snap, err := beadstore.For(f.townBeads).Snapshot(); err == nil {
+		if _, ok := snap.Get(convoyID); ok {
+			return snap.Tracked(convoyID)
+		}
+	}
+
+	dbPath := filepath.Join(f.townBeads, "beads.db")
+
+	// Query tracked dependencies from SQLite
+	safeConvoyID := strings.ReplaceAll(convoyID, "'", "''")
+	// #nosec G204 -- sqlite3 path is from trusted config, convoyID is escaped
+	queryCmd := exec.Command("sqlite3", "-json", dbPath,
+		fmt.Sprintf(`SELECT depends_on_id, type FROM dependencies WHERE issue_id = '%s' AND type = 'tracks'`, safeConvoyID))
+
+	var stdout bytes.Buffer
+	queryCmd.Stdout = &stdout
+	if err := queryCmd.Run(); err != nil {
+		return nil
+	}
+
+	var deps []struct {
+		DependsOnID string `json:"depends_on_id"`
+		Type        string `json:"type"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &deps); err != nil {
+		return nil
+	}
+
+	// Collect issue IDs (normalize external refs)
+	issueIDs := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		issueIDs = append(issueIDs, beadstore.StripExternal(dep.DependsOnID))
+	}
+	return issueIDs
+}
+
 // issueDetail holds basic issue info.
 type issueDetail struct {
 	ID        string
@@ -230,37 +265,15 @@ type issueDetail struct {
 	UpdatedAt time.Time
 }
 
-// getIssueDetailsBatch fetches details for multiple issues.
+// getIssueDetailsBatch fetches details for multiple issues, routed to
+// their rigs' beads and served from the read cache where possible.
 func (f *LiveConvoyFetcher) getIssueDetailsBatch(issueIDs []string) map[string]*issueDetail {
 	result := make(map[string]*issueDetail)
 	if len(issueIDs) == 0 {
 		return result
 	}
 
-	args := append([]string{"show"}, issueIDs...)
-	args = append(args, "--json")
-
-	// #nosec G204 -- bd is a trusted internal tool, args are issue IDs
-	showCmd := exec.Command("bd", args...)
-	var stdout bytes.Buffer
-	showCmd.Stdout = &stdout
-
-	if err := showCmd.Run(); err != nil {
-		return result
-	}
-
-	var issues []struct {
-		ID        string `json:"id"`
-		Title     string `json:"title"`
-		Status    string `json:"status"`
-		Assignee  string `json:"assignee"`
-		UpdatedAt string `json:"updated_at"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
-		return result
-	}
-
-	for _, issue := range issues {
+	for id, issue := range beads.ShowRouted(f.townRoot, issueIDs) {
 		detail := &issueDetail{
 			ID:       issue.ID,
 			Title:    issue.Title,
@@ -273,7 +286,7 @@ func (f *LiveConvoyFetcher) getIssueDetailsBatch(issueIDs []string) map[string]*
 				detail.UpdatedAt = t
 			}
 		}
-		result[issue.ID] = detail
+		result[id] = detail
 	}
 
 	return result
@@ -442,7 +455,9 @@ func calculateWorkStatus(completed, total int, activityColor string) string {
 	}
 }
 
-// FetchMergeQueue fetches open PRs from registered rigs.
+// FetchMergeQueue fetches the merge queue of every registered rig: its open
+// MR beads, plus open GitHub PRs for rigs that opt in with
+// merge_queue.github_prs in their settings.
 func (f *LiveConvoyFetcher) FetchMergeQueue() ([]MergeQueueRow, error) {
 	// Load registered rigs from config
 	rigsConfigPath := filepath.Join(f.townRoot, "mayor", "rigs.json")
@@ -452,8 +467,27 @@ func (f *LiveConvoyFetcher) FetchMergeQueue() ([]MergeQueueRow, error) {
 	}
 
 	var result []MergeQueueRow
+	now := time.Now()
 
 	for rigName, entry := range rigsConfig.Rigs {
+		rigPath := filepath.Join(f.townRoot, rigName)
+
+		settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+		var freezeWindows []config.FreezeWindow
+		if err == nil && settings.MergeQueue != nil {
+			freezeWindows = settings.MergeQueue.FreezeWindows
+		}
+		hold := refinery.QueueHold(f.townRoot, rigName, freezeWindows, now)
+
+		// Non-fatal: continue with other rigs
+		if mrs, err := f.fetchMRsForRig(rigPath, rigName, hold, now); err == nil {
+			result = append(result, mrs...)
+		}
+
+		if err != nil || settings.MergeQueue == nil || !settings.MergeQueue.GitHubPRs {
+			continue
+		}
+
 		// Convert git URL to owner/repo format for gh CLI
 		repoPath := gitURLToRepoPath(entry.GitURL)
 		if repoPath == "" {
@@ -468,6 +502,7 @@ func (f *LiveConvoyFetcher) FetchMergeQueue() ([]MergeQueueRow, error) {
 		result = append(result, prs...)
 	}
 
+	sortMergeQueue(result)
 	return result, nil
 }
 
@@ -738,7 +773,7 @@ func (f *LiveConvoyFetcher) getPolecatStatusHint(sessionName string) string {
 	return ""
 }
 
-// getMergeQueueCount returns the total number of open PRs across all repos.
+// getMergeQueueCount returns the total number of queued MRs (and PRs).
 func (f *LiveConvoyFetcher) getMergeQueueCount() int {
 	mergeQueue, err := f.FetchMergeQueue()
 	if err != nil {
@@ -750,12 +785,12 @@ func (f *LiveConvoyFetcher) getMergeQueueCount() int {
 // getRefineryStatusHint returns appropriate status for refinery based on merge queue.
 func (f *LiveConvoyFetcher) getRefineryStatusHint(mergeQueueCount int) string {
 	if mergeQueueCount == 0 {
-		return "Idle - Waiting for PRs"
+		return "Idle - Waiting for MRs"
 	}
 	if mergeQueueCount == 1 {
-		return "Processing 1 PR"
+		return "Processing 1 MR"
 	}
-	return fmt.Sprintf("Processing %d PRs", mergeQueueCount)
+	return fmt.Sprintf("Processing %d MRs", mergeQueueCount)
 }
 
 // truncateStatusHint truncates a status hint to 60 characters with ellipsis.
@@ -288,10 +288,10 @@ func TestGetRefineryStatusHint(t *testing.T) {
 		mergeQueueCount int
 		want            string
 	}{
-		{"idle when no PRs", 0, "Idle - Waiting for PRs"},
-		{"singular PR", 1, "Processing 1 PR"},
-		{"multiple PRs", 2, "Processing 2 PRs"},
-		{"many PRs", 10, "Processing 10 PRs"},
+		{"idle when no MRs", 0, "Idle - Waiting for MRs"},
+		{"singular MR", 1, "Processing 1 MR"},
+		{"multiple MRs", 2, "Processing 2 MRs"},
+		{"many MRs", 10, "Processing 10 MRs"},
 	}
 
 	for _, tt := range tests {
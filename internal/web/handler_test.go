@@ -311,7 +311,7 @@ func TestConvoyHandler_EmptyMergeQueue(t *testing.T) {
 	body := w.Body.String()
 
 	// Should show empty state for merge queue
-	if !strings.Contains(body, "No PRs in queue") {
+	if !strings.Contains(body, "No merge requests in queue") {
 		t.Error("Response should show empty merge queue message")
 	}
 }
@@ -777,8 +777,8 @@ func TestE2E_Server_MergeQueueEmpty(t *testing.T) {
 	}
 
 	// Empty state message
-	if !strings.Contains(body, "No PRs in queue") {
-		t.Error("Should show 'No PRs in queue' when empty")
+	if !strings.Contains(body, "No merge requests in queue") {
+		t.Error("Should show 'No merge requests in queue' when empty")
 	}
 }
 
@@ -901,7 +901,7 @@ func TestE2E_Server_RefineryInPolecats(t *testing.T) {
 				Rig:          "roxas",
 				SessionID:    "gt-roxas-refinery",
 				LastActivity: activity.Calculate(time.Now().Add(-10 * time.Second)),
-				StatusHint:   "Idle - Waiting for PRs",
+				StatusHint:   "Idle - Waiting for MRs",
 			},
 			{
 				Name:         "dag",
@@ -934,7 +934,7 @@ func TestE2E_Server_RefineryInPolecats(t *testing.T) {
 	if !strings.Contains(body, "refinery") {
 		t.Error("Refinery should appear in polecat workers section")
 	}
-	if !strings.Contains(body, "Idle - Waiting for PRs") {
+	if !strings.Contains(body, "Idle - Waiting for MRs") {
 		t.Error("Refinery idle status should be shown")
 	}
 
@@ -0,0 +1,141 @@
+package web
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/activity"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// Merge request states shown in the dashboard.
+const (
+	mrStateReady   = "ready"   // Waiting for the refinery
+	mrStateClaimed = "claimed" // Being processed
+	mrStateBlocked = "blocked" // Waiting on an open task (e.g. conflict resolution)
+	mrStateReview  = "review"  // Waiting on code review
+	mrStateHeld    = "held"    // Ready, but the queue is paused or frozen
+)
+
+// fetchMRsForRig lists a rig's open MR beads as merge queue rows. This is
+// the queue the refinery actually processes (see refinery.Engineer).
+// Ready MRs are shown as held while the rig's queue is paused or frozen,
+// and, on rigs that require review, in review until approved at the
+// branch's current head.
+func (f *LiveConvoyFetcher) fetchMRsForRig(rigPath, rigName string, hold *refinery.QueuePause, now time.Time) ([]MergeQueueRow, error) {
+	issues, err := beads.New(rigPath).List(beads.ListOptions{
+		Status:   "open",
+		Label:    "gt:merge-request",
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing merge requests for %s: %w", rigName, err)
+	}
+
+	// Resolve blocker status in one batch: an MR is only blocked while a
+	// blocker is still open.
+	var blockerIDs []string
+	for _, issue := range issues {
+		blockerIDs = append(blockerIDs, issue.BlockedBy...)
+	}
+	blockers := beads.ShowRouted(f.townRoot, blockerIDs)
+
+	// The refinery's own review check, so stale approvals don't show as ready
+	eng := refinery.NewEngineer(&rig.Rig{Name: rigName, Path: rigPath})
+
+	rows := make([]MergeQueueRow, 0, len(issues))
+	for _, issue := range issues {
+		fields := beads.ParseMRFields(issue)
+		if fields == nil {
+			continue
+		}
+		openBlocker := ""
+		for _, id := range issue.BlockedBy {
+			if b, ok := blockers[id]; !ok || b.Status != "closed" {
+				openBlocker = id
+				break
+			}
+		}
+		review := ""
+		if eng.ReviewRequired() {
+			review = eng.ReviewStatus(fields)
+		}
+		row := mrRow(issue, fields, rigName, openBlocker, review, now)
+		if hold != nil {
+			row.Hold = hold.Describe()
+			if row.State == mrStateReady {
+				row.State = mrStateHeld
+				row.ColorClass = "mq-yellow"
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// mrRow builds the dashboard row for an MR bead. review is the MR's
+// refinery.Engineer.ReviewStatus, or "" when the rig doesn't require review.
+func mrRow(issue *beads.Issue, fields *beads.MRFields, rigName, openBlocker, review string, now time.Time) MergeQueueRow {
+	info := &refinery.MRInfo{
+		Priority:    issue.Priority,
+		RetryCount:  fields.RetryCount,
+		ScoreAdjust: fields.ScoreAdjust,
+	}
+	if t, err := time.Parse(time.RFC3339, issue.CreatedAt); err == nil {
+		info.CreatedAt = t
+	}
+	if t, err := time.Parse(time.RFC3339, fields.ConvoyCreatedAt); err == nil {
+		info.ConvoyCreatedAt = &t
+	}
+
+	row := MergeQueueRow{
+		ID:          issue.ID,
+		Repo:        rigName,
+		Title:       issue.Title,
+		Branch:      fields.Branch,
+		Worker:      fields.Worker,
+		Score:       info.ScoreAt(now),
+		RetryCount:  fields.RetryCount,
+		LastFailure: fields.LastFailure,
+		BlockedBy:   openBlocker,
+		ClaimedBy:   issue.Assignee,
+	}
+	if !info.CreatedAt.IsZero() {
+		row.InQueue = activity.Calculate(info.CreatedAt).FormattedAge
+	}
+
+	switch {
+	case issue.Assignee != "":
+		row.State = mrStateClaimed
+	case openBlocker != "":
+		row.State = mrStateBlocked
+	case review != "" && review != refinery.ReviewApproved:
+		row.State = mrStateReview
+	default:
+		row.State = mrStateReady
+	}
+
+	switch {
+	case row.LastFailure != "" && row.State != mrStateClaimed:
+		row.ColorClass = "mq-red"
+	case row.State == mrStateBlocked || row.State == mrStateReview:
+		row.ColorClass = "mq-yellow"
+	default:
+		row.ColorClass = "mq-green"
+	}
+	return row
+}
+
+// sortMergeQueue orders MR beads by refinery score (processing order),
+// followed by GitHub PRs.
+func sortMergeQueue(rows []MergeQueueRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].IsMR() != rows[j].IsMR() {
+			return rows[i].IsMR()
+		}
+		return rows[i].Score > rows[j].Score
+	})
+}
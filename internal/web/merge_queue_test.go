@@ -0,0 +1,120 @@
+package web
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestMRRow(t *testing.T) {
+	now := time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC)
+	issue := &beads.Issue{
+		ID:        "gt-mr1",
+		Title:     "Merge: gt-abc",
+		Priority:  1,
+		CreatedAt: now.Add(-3 * time.Hour).Format(time.RFC3339),
+	}
+	fields := &beads.MRFields{Branch: "polecat/nux/gt-abc", Worker: "nux", RetryCount: 2}
+
+	tests := []struct {
+		name        string
+		assignee    string
+		blocker     string
+		review      string
+		lastFailure string
+		wantState   string
+		wantColor   string
+	}{
+		{"ready", "", "", "", "", mrStateReady, "mq-green"},
+		{"claimed", "gastown/refinery", "", "", "conflict", mrStateClaimed, "mq-green"},
+		{"blocked", "", "gt-task", "", "", mrStateBlocked, "mq-yellow"},
+		{"review", "", "", "pending", "", mrStateReview, "mq-yellow"},
+		{"stale approval", "", "", "stale", "", mrStateReview, "mq-yellow"},
+		{"review needed", "", "", "needed", "", mrStateReview, "mq-yellow"},
+		{"approved", "", "", "approved", "", mrStateReady, "mq-green"},
+		{"failed", "", "gt-task", "", "tests_fail", mrStateBlocked, "mq-red"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := *issue
+			is.Assignee = tt.assignee
+			f := *fields
+			f.LastFailure = tt.lastFailure
+
+			row := mrRow(&is, &f, "gastown", tt.blocker, tt.review, now)
+			if row.State != tt.wantState || row.ColorClass != tt.wantColor {
+				t.Errorf("state=%q color=%q, want %q %q", row.State, row.ColorClass, tt.wantState, tt.wantColor)
+			}
+			if !row.IsMR() || row.Repo != "gastown" || row.RetryCount != 2 || row.BlockedBy != tt.blocker {
+				t.Errorf("row = %+v", row)
+			}
+			if row.Score <= 0 {
+				t.Errorf("Score = %v, want positive", row.Score)
+			}
+		})
+	}
+}
+
+func TestMRRow_ScoreAdjust(t *testing.T) {
+	now := time.Date(2026, 1, 18, 12, 0, 0, 0, time.UTC)
+	older := &beads.Issue{ID: "gt-old", Priority: 1, CreatedAt: now.Add(-6 * time.Hour).Format(time.RFC3339)}
+	bumped := &beads.Issue{ID: "gt-bumped", Priority: 1, CreatedAt: now.Add(-time.Hour).Format(time.RFC3339)}
+
+	rows := []MergeQueueRow{
+		mrRow(older, &beads.MRFields{Branch: "polecat/a"}, "gastown", "", "", now),
+		mrRow(bumped, &beads.MRFields{Branch: "polecat/b", ScoreAdjust: 5000}, "gastown", "", "", now),
+	}
+	sortMergeQueue(rows)
+	if rows[0].ID != "gt-bumped" {
+		t.Errorf("order = %s, %s; want the bumped MR first", rows[0].ID, rows[1].ID)
+	}
+}
+
+func TestSortMergeQueue(t *testing.T) {
+	rows := []MergeQueueRow{
+		{Number: 7, Title: "PR"},
+		{ID: "gt-low", Score: 100},
+		{ID: "gt-high", Score: 1100},
+	}
+	sortMergeQueue(rows)
+	if rows[0].ID != "gt-high" || rows[1].ID != "gt-low" || rows[2].Number != 7 {
+		t.Errorf("order = %v, %v, %v; want MRs by score, then PRs", rows[0], rows[1], rows[2])
+	}
+}
+
+func TestConvoyHandler_MRBeadRendering(t *testing.T) {
+	mock := &MockConvoyFetcher{
+		MergeQueue: []MergeQueueRow{
+			{
+				ID:          "gt-mr1",
+				Repo:        "gastown",
+				Title:       "Merge: gt-abc",
+				Branch:      "polecat/nux/gt-abc",
+				State:       mrStateBlocked,
+				Score:       1234.4,
+				InQueue:     "3h",
+				RetryCount:  2,
+				LastFailure: "conflict",
+				BlockedBy:   "gt-task",
+				ColorClass:  "mq-red",
+			},
+		},
+	}
+	handler, err := NewConvoyHandler(mock)
+	if err != nil {
+		t.Fatalf("NewConvoyHandler() error = %v", err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	body := w.Body.String()
+	for _, want := range []string{"gt-mr1", "polecat/nux/gt-abc", "Blocked", "score 1234", "3h in queue",
+		"2 retries", "last failure: conflict", "blocked by gt-task"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("response missing %q", want)
+		}
+	}
+}
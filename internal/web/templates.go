@@ -28,15 +28,36 @@ type PolecatRow struct {
 	StatusHint   string        // Last line from pane (optional)
 }
 
-// MergeQueueRow represents a PR in the merge queue.
+// MergeQueueRow represents an entry in the merge queue: an MR bead
+// processed by the refinery, or (when enabled) a GitHub PR.
 type MergeQueueRow struct {
-	Number     int
-	Repo       string // Short repo name (e.g., "roxas", "gastown")
+	Repo       string // Rig / short repo name (e.g., "roxas", "gastown")
 	Title      string
-	URL        string
-	CIStatus   string // "pass", "fail", "pending"
-	Mergeable  string // "ready", "conflict", "pending"
 	ColorClass string // "mq-green", "mq-yellow", "mq-red"
+
+	// MR bead fields (ID is empty for GitHub PRs)
+	ID          string  // MR bead ID
+	Branch      string  // Source branch
+	Worker      string  // Who did the work
+	State       string  // "ready", "claimed", "blocked", "review"
+	Score       float64 // Refinery priority score (higher merges first)
+	ClaimedBy   string  // Refinery worker processing the MR
+	BlockedBy   string  // Open task blocking the MR
+	RetryCount  int     // Conflict retries so far
+	LastFailure string  // Type of the last failed attempt
+	InQueue     string  // Time since the MR was submitted (e.g., "2h")
+	Hold        string  // Why the rig's queue is not merging (pause or freeze window)
+
+	// GitHub PR fields
+	Number    int
+	URL       string
+	CIStatus  string // "pass", "fail", "pending"
+	Mergeable string // "ready", "conflict", "pending"
+}
+
+// IsMR reports whether the row is an MR bead rather than a GitHub PR.
+func (r MergeQueueRow) IsMR() bool {
+	return r.ID != ""
 }
 
 // ConvoyRow represents a single convoy in the dashboard.
@@ -0,0 +1,226 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigFile is the incoming webhook rules file inside the town settings
+// directory.
+const ConfigFile = "webhooks-in.toml"
+
+// Source kinds: how payloads are parsed and signatures checked.
+const (
+	KindGitHub  = "github"
+	KindGitLab  = "gitlab"
+	KindGeneric = "generic"
+)
+
+// Route actions.
+const (
+	ActionBead   = "bead"   // create a bead in the rig (and sling it)
+	ActionMail   = "mail"   // mail the polecat that owns the branch
+	ActionLog    = "log"    // record in .events.jsonl
+	ActionIgnore = "ignore" // accept and drop
+)
+
+// File is the on-disk webhook rules format.
+//
+// Example settings/webhooks-in.toml:
+//
+//	[[source]]
+//	name = "github"            # POST /webhooks/github
+//	kind = "github"
+//	secret_env = "GT_GITHUB_WEBHOOK_SECRET"
+//
+//	[[route]]
+//	event = "build_failed"
+//	branch = "release/*"       # also file beads for release branches
+//	priority = 1
+//
+//	[[route]]
+//	event = "deploy"
+//	source = "github"
+//	action = "ignore"
+//
+// User routes are tried in order before DefaultRoutes; the first match wins.
+type File struct {
+	Sources []Source `toml:"source"`
+	Routes  []Route  `toml:"route"`
+}
+
+// Source is an endpoint that accepts webhooks from one sender.
+type Source struct {
+	// Name is the URL path segment: /webhooks/<name>.
+	Name string `toml:"name"`
+
+	// Kind is github, gitlab or generic.
+	Kind string `toml:"kind"`
+
+	// SecretEnv names the environment variable holding the shared secret.
+	// Requests are rejected unless it is set.
+	SecretEnv string `toml:"secret_env"`
+
+	// Rig is the rig events land in when the repository does not match a
+	// rig's git_url.
+	Rig string `toml:"rig"`
+}
+
+// Route decides what happens to an event.
+type Route struct {
+	// Event is build_failed, build_passed, review_comment or deploy.
+	Event string `toml:"event"`
+
+	// Source, Repo and Branch narrow the match. Repo and Branch are glob
+	// patterns. For build_failed an empty Branch matches only the rig's
+	// default branch; use "*" for any branch.
+	Source string `toml:"source"`
+	Repo   string `toml:"repo"`
+	Branch string `toml:"branch"`
+
+	// Action is bead, mail, log or ignore. Default: the action of the
+	// matching default route.
+	Action string `toml:"action"`
+
+	// Rig overrides the rig the event is routed to.
+	Rig string `toml:"rig"`
+
+	// Priority of created beads (default 0).
+	Priority *int `toml:"priority"`
+
+	// NoSling leaves created beads unassigned instead of slinging them.
+	NoSling bool `toml:"no_sling"`
+
+	// To overrides the mail recipient. When the branch has no owning
+	// polecat, mail goes here; without To it is dropped.
+	To string `toml:"to"`
+}
+
+// DefaultRoutes apply after the user's routes: failed default-branch builds
+// become P0 beads slung to the rig, review comments are mailed to the
+// branch's polecat, and deploys are logged.
+func DefaultRoutes() []Route {
+	return []Route{
+		{Event: EventBuildFailed, Action: ActionBead},
+		{Event: EventBuildPassed, Action: ActionIgnore},
+		{Event: EventReviewComment, Action: ActionMail},
+		{Event: EventDeploy, Action: ActionLog},
+	}
+}
+
+// TownConfigPath returns the town-level webhook rules path.
+func TownConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", ConfigFile)
+}
+
+// LoadFile parses a webhook rules file. Returns nil, nil if it does not
+// exist.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var f File
+	if err := toml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &f, nil
+}
+
+func (f *File) validate() error {
+	seen := make(map[string]bool)
+	for _, s := range f.Sources {
+		if s.Name == "" {
+			return fmt.Errorf("webhook source missing name")
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("webhook source %q defined twice", s.Name)
+		}
+		seen[s.Name] = true
+		switch s.Kind {
+		case KindGitHub, KindGitLab, KindGeneric:
+		default:
+			return fmt.Errorf("webhook source %q: unknown kind %q (want github, gitlab or generic)", s.Name, s.Kind)
+		}
+	}
+	for i, r := range f.Routes {
+		switch r.Event {
+		case EventBuildFailed, EventBuildPassed, EventReviewComment, EventDeploy:
+		default:
+			return fmt.Errorf("route %d: unknown event %q", i+1, r.Event)
+		}
+		switch r.Action {
+		case "", ActionBead, ActionMail, ActionLog, ActionIgnore:
+		default:
+			return fmt.Errorf("route %d: unknown action %q", i+1, r.Action)
+		}
+		for _, pattern := range []string{r.Repo, r.Branch} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("route %d: bad pattern %q", i+1, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// Source returns the named source, or nil.
+func (f *File) Source(name string) *Source {
+	for i := range f.Sources {
+		if f.Sources[i].Name == name {
+			return &f.Sources[i]
+		}
+	}
+	return nil
+}
+
+// match returns the first route (user routes, then defaults) matching an
+// event, with its action filled in. defaultBranch is the routed rig's
+// default branch.
+func (f *File) match(ev *Event, defaultBranch string) Route {
+	routes := append(append([]Route(nil), f.Routes...), DefaultRoutes()...)
+	for _, r := range routes {
+		if r.Event != ev.Kind {
+			continue
+		}
+		if r.Source != "" && r.Source != ev.Source {
+			continue
+		}
+		if r.Repo != "" && !globMatch(r.Repo, ev.Repo) {
+			continue
+		}
+		switch {
+		case r.Branch != "":
+			if !globMatch(r.Branch, ev.Branch) {
+				continue
+			}
+		case ev.Kind == EventBuildFailed:
+			if ev.Branch != defaultBranch {
+				continue
+			}
+		}
+		if r.Action == "" {
+			for _, d := range DefaultRoutes() {
+				if d.Event == r.Event {
+					r.Action = d.Action
+				}
+			}
+		}
+		return r
+	}
+	return Route{Event: ev.Kind, Action: ActionIgnore}
+}
+
+func globMatch(pattern, s string) bool {
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
@@ -0,0 +1,293 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Normalized event kinds.
+const (
+	EventBuildFailed   = "build_failed"
+	EventBuildPassed   = "build_passed"
+	EventReviewComment = "review_comment"
+	EventDeploy        = "deploy"
+)
+
+// Event is a webhook normalized across senders.
+type Event struct {
+	// Kind is one of the Event* constants, or "" for payloads that carry
+	// nothing Gas Town acts on.
+	Kind string `json:"event"`
+
+	// Source is the configured source name it arrived on.
+	Source string `json:"-"`
+
+	// Delivery is the sender's delivery ID, used to drop redeliveries.
+	Delivery string `json:"-"`
+
+	Repo        string `json:"repo"`
+	Branch      string `json:"branch"`
+	SHA         string `json:"sha"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	Actor       string `json:"actor"`
+	Environment string `json:"environment"`
+	Status      string `json:"status"`
+}
+
+// Verify checks a request's signature against secret.
+//
+// GitHub and generic senders sign the body with HMAC-SHA256 in
+// X-Hub-Signature-256 (generic senders may use X-Signature-256);
+// GitLab sends the secret itself in X-Gitlab-Token.
+func Verify(kind, secret string, header http.Header, body []byte) error {
+	if secret == "" {
+		return fmt.Errorf("no webhook secret configured")
+	}
+	if kind == KindGitLab {
+		token := header.Get("X-Gitlab-Token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return fmt.Errorf("bad X-Gitlab-Token")
+		}
+		return nil
+	}
+
+	sig := header.Get("X-Hub-Signature-256")
+	if sig == "" {
+		sig = header.Get("X-Signature-256")
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(sig, "sha256="))
+	if err != nil || !strings.HasPrefix(sig, "sha256=") {
+		return fmt.Errorf("missing or malformed signature")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// Sign returns the X-Hub-Signature-256 value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Parse normalizes a verified webhook payload.
+func Parse(kind string, header http.Header, body []byte) (*Event, error) {
+	var (
+		ev  *Event
+		err error
+	)
+	switch kind {
+	case KindGitHub:
+		ev, err = parseGitHub(header.Get("X-GitHub-Event"), body)
+		if ev != nil {
+			ev.Delivery = header.Get("X-GitHub-Delivery")
+		}
+	case KindGitLab:
+		ev, err = parseGitLab(header.Get("X-Gitlab-Event"), body)
+		if ev != nil {
+			ev.Delivery = header.Get("X-Gitlab-Event-UUID")
+		}
+	default:
+		ev = &Event{Delivery: header.Get("X-Delivery")}
+		err = json.Unmarshal(body, ev)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s payload: %w", kind, err)
+	}
+	return ev, nil
+}
+
+type githubPayload struct {
+	Action     string `json:"action"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+	WorkflowRun *struct {
+		Name       string `json:"name"`
+		HeadBranch string `json:"head_branch"`
+		HeadSHA    string `json:"head_sha"`
+		HTMLURL    string `json:"html_url"`
+		Conclusion string `json:"conclusion"`
+	} `json:"workflow_run"`
+	PullRequest *struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		Head   struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Comment *struct {
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+		Path    string `json:"path"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"comment"`
+	Review *struct {
+		Body    string `json:"body"`
+		State   string `json:"state"`
+		HTMLURL string `json:"html_url"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"review"`
+	Deployment *struct {
+		Ref         string `json:"ref"`
+		SHA         string `json:"sha"`
+		Environment string `json:"environment"`
+	} `json:"deployment"`
+	DeploymentStatus *struct {
+		State          string `json:"state"`
+		TargetURL      string `json:"target_url"`
+		EnvironmentURL string `json:"environment_url"`
+	} `json:"deployment_status"`
+}
+
+func parseGitHub(event string, body []byte) (*Event, error) {
+	var p githubPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+	ev := &Event{Repo: p.Repository.FullName, Actor: p.Sender.Login}
+
+	switch {
+	case event == "workflow_run" && p.WorkflowRun != nil:
+		run := p.WorkflowRun
+		if p.Action != "completed" {
+			return ev, nil
+		}
+		switch run.Conclusion {
+		case "failure", "timed_out", "startup_failure":
+			ev.Kind = EventBuildFailed
+		case "success":
+			ev.Kind = EventBuildPassed
+		}
+		ev.Branch, ev.SHA, ev.URL, ev.Title, ev.Status = run.HeadBranch, run.HeadSHA, run.HTMLURL, run.Name, run.Conclusion
+
+	case event == "pull_request_review_comment" && p.Comment != nil && p.PullRequest != nil:
+		if p.Action != "created" {
+			return ev, nil
+		}
+		ev.Kind = EventReviewComment
+		ev.Branch, ev.SHA = p.PullRequest.Head.Ref, p.PullRequest.Head.SHA
+		ev.Title = fmt.Sprintf("#%d %s", p.PullRequest.Number, p.PullRequest.Title)
+		ev.Body, ev.URL, ev.Actor = p.Comment.Body, p.Comment.HTMLURL, p.Comment.User.Login
+		if p.Comment.Path != "" {
+			ev.Body = fmt.Sprintf("On %s:\n%s", p.Comment.Path, p.Comment.Body)
+		}
+
+	case event == "pull_request_review" && p.Review != nil && p.PullRequest != nil:
+		// Approvals without text carry nothing to act on.
+		if p.Action != "submitted" || (strings.TrimSpace(p.Review.Body) == "" && p.Review.State != "changes_requested") {
+			return ev, nil
+		}
+		ev.Kind = EventReviewComment
+		ev.Branch, ev.SHA = p.PullRequest.Head.Ref, p.PullRequest.Head.SHA
+		ev.Title = fmt.Sprintf("#%d %s", p.PullRequest.Number, p.PullRequest.Title)
+		ev.Body, ev.URL, ev.Actor, ev.Status = p.Review.Body, p.Review.HTMLURL, p.Review.User.Login, p.Review.State
+
+	case event == "deployment_status" && p.Deployment != nil && p.DeploymentStatus != nil:
+		ev.Kind = EventDeploy
+		ev.Branch, ev.SHA, ev.Environment = p.Deployment.Ref, p.Deployment.SHA, p.Deployment.Environment
+		ev.Status = p.DeploymentStatus.State
+		ev.URL = p.DeploymentStatus.EnvironmentURL
+		if ev.URL == "" {
+			ev.URL = p.DeploymentStatus.TargetURL
+		}
+	}
+	return ev, nil
+}
+
+type gitlabPayload struct {
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		WebURL            string `json:"web_url"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		ID           int    `json:"id"`
+		Status       string `json:"status"`
+		Ref          string `json:"ref"`
+		SHA          string `json:"sha"`
+		Name         string `json:"name"`
+		Note         string `json:"note"`
+		NoteableType string `json:"noteable_type"`
+		URL          string `json:"url"`
+	} `json:"object_attributes"`
+	MergeRequest *struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		SourceBranch string `json:"source_branch"`
+		LastCommit   struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"merge_request"`
+
+	// Deployment hooks are flat.
+	Status        string `json:"status"`
+	Environment   string `json:"environment"`
+	Ref           string `json:"ref"`
+	ShortSHA      string `json:"short_sha"`
+	DeployableURL string `json:"deployable_url"`
+}
+
+func parseGitLab(event string, body []byte) (*Event, error) {
+	var p gitlabPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+	ev := &Event{Repo: p.Project.PathWithNamespace, Actor: p.User.Username}
+	attrs := p.ObjectAttributes
+
+	switch event {
+	case "Pipeline Hook":
+		switch attrs.Status {
+		case "failed":
+			ev.Kind = EventBuildFailed
+		case "success":
+			ev.Kind = EventBuildPassed
+		}
+		ev.Branch, ev.SHA, ev.Status = attrs.Ref, attrs.SHA, attrs.Status
+		ev.Title = attrs.Name
+		if ev.Title == "" {
+			ev.Title = fmt.Sprintf("pipeline %d", attrs.ID)
+		}
+		if p.Project.WebURL != "" {
+			ev.URL = fmt.Sprintf("%s/-/pipelines/%d", p.Project.WebURL, attrs.ID)
+		}
+
+	case "Note Hook":
+		if attrs.NoteableType != "MergeRequest" || p.MergeRequest == nil {
+			return ev, nil
+		}
+		ev.Kind = EventReviewComment
+		ev.Branch, ev.SHA = p.MergeRequest.SourceBranch, p.MergeRequest.LastCommit.ID
+		ev.Title = fmt.Sprintf("!%d %s", p.MergeRequest.IID, p.MergeRequest.Title)
+		ev.Body, ev.URL = attrs.Note, attrs.URL
+
+	case "Deployment Hook":
+		ev.Kind = EventDeploy
+		ev.Branch, ev.SHA, ev.Environment = p.Ref, p.ShortSHA, p.Environment
+		ev.Status, ev.URL = p.Status, p.DeployableURL
+	}
+	return ev, nil
+}
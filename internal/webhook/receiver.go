@@ -0,0 +1,418 @@
+// Package webhook receives signed webhooks from CI systems and forges and
+// turns them into town actions.
+//
+// Sources and routing rules live in settings/webhooks-in.toml. Events are
+// normalized (build_failed, build_passed, review_comment, deploy) and
+// routed: by default a failed build on a rig's default branch becomes a P0
+// bead slung to the rig, a review comment is mailed to the polecat that
+// owns the branch, and a deploy is logged to .events.jsonl.
+package webhook
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/forge"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// PathPrefix is where the receiver is mounted; sources are served at
+// PathPrefix + name.
+const PathPrefix = "/webhooks/"
+
+// MailFrom is the sender address of mail generated from webhooks.
+const MailFrom = "webhooks"
+
+// maxBody bounds webhook payloads.
+const maxBody = 5 << 20
+
+// deliveryTTL is how long delivery IDs are remembered to drop redeliveries.
+const deliveryTTL = time.Hour
+
+// Town is what the receiver needs to act on events.
+type Town interface {
+	// RigForRepo returns the rig whose git_url is repo ("owner/name"), or "".
+	RigForRepo(repo string) string
+
+	// DefaultBranch returns a rig's default branch.
+	DefaultBranch(rig string) string
+
+	// CreateBead files a bug bead in a rig.
+	CreateBead(rig, title, description string, priority int) (string, error)
+
+	// Sling assigns a bead to a fresh polecat in a rig.
+	Sling(bead, rig string) error
+
+	// Mail sends a message.
+	Mail(msg *mail.Message) error
+
+	// Log records an event in the town's events log.
+	Log(eventType, actor string, payload map[string]interface{}) error
+}
+
+// Receiver is an http.Handler for PathPrefix.
+type Receiver struct {
+	file *File
+	town Town
+	logf func(format string, args ...interface{})
+
+	mu         sync.Mutex
+	deliveries map[string]time.Time
+	// broken maps rig/branch to the bead filed for its failing build, so a
+	// red branch files one bead until a passing build clears it.
+	broken map[string]string
+}
+
+// NewReceiver returns a receiver for the sources and routes in file.
+func NewReceiver(file *File, town Town, logf func(format string, args ...interface{})) *Receiver {
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+	return &Receiver{
+		file:       file,
+		town:       town,
+		logf:       logf,
+		deliveries: make(map[string]time.Time),
+		broken:     make(map[string]string),
+	}
+}
+
+// ForTown loads a town's settings/webhooks-in.toml and returns a receiver
+// acting on the town. Returns nil, nil if the file does not exist.
+func ForTown(townRoot string, logf func(format string, args ...interface{})) (*Receiver, error) {
+	file, err := LoadFile(TownConfigPath(townRoot))
+	if err != nil || file == nil {
+		return nil, err
+	}
+	return NewReceiver(file, &TownActions{TownRoot: townRoot}, logf), nil
+}
+
+// ServeHTTP accepts POST PathPrefix/<source>.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	src := r.file.Source(strings.Trim(strings.TrimPrefix(req.URL.Path, PathPrefix), "/"))
+	if src == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBody))
+	if err != nil {
+		http.Error(w, "reading body", http.StatusBadRequest)
+		return
+	}
+	if err := Verify(src.Kind, os.Getenv(src.SecretEnv), req.Header, body); err != nil {
+		r.logf("webhook %s: rejected: %v", src.Name, err)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	ev, err := Parse(src.Kind, req.Header, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ev.Source = src.Name
+
+	result, err := r.Handle(src, ev)
+	if err != nil {
+		r.logf("webhook %s: %s: %v", src.Name, ev.Kind, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ev.Kind != "" {
+		r.logf("webhook %s: %s %s@%s: %s", src.Name, ev.Kind, ev.Repo, ev.Branch, result)
+	}
+	_, _ = fmt.Fprintln(w, result)
+}
+
+// Handle routes a parsed event and performs its action, returning a short
+// description of what was done.
+func (r *Receiver) Handle(src *Source, ev *Event) (string, error) {
+	if ev.Kind == "" {
+		return "ignored", nil
+	}
+	if r.seen(ev) {
+		return "duplicate delivery", nil
+	}
+
+	rigName := r.town.RigForRepo(ev.Repo)
+	if rigName == "" {
+		rigName = src.Rig
+	}
+	route := r.file.match(ev, r.defaultBranch(rigName))
+	if route.Rig != "" {
+		rigName = route.Rig
+	}
+	key := rigName + "/" + ev.Branch
+
+	if ev.Kind == EventBuildPassed {
+		r.mu.Lock()
+		delete(r.broken, key)
+		r.mu.Unlock()
+	}
+
+	switch route.Action {
+	case ActionBead:
+		return r.fileBead(rigName, key, route, ev)
+	case ActionMail:
+		return r.mailOwner(rigName, route, ev)
+	case ActionLog:
+		// Event kinds double as event types (events.TypeDeploy etc.).
+		if err := r.town.Log(ev.Kind, actor(ev), eventPayload(rigName, ev)); err != nil {
+			return "", err
+		}
+		return "logged", nil
+	default:
+		return "ignored", nil
+	}
+}
+
+func (r *Receiver) defaultBranch(rigName string) string {
+	if rigName == "" {
+		return "main"
+	}
+	return r.town.DefaultBranch(rigName)
+}
+
+// seen reports whether a delivery was already handled, remembering it if
+// not. Events without a delivery ID are never duplicates.
+func (r *Receiver) seen(ev *Event) bool {
+	if ev.Delivery == "" {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for id, at := range r.deliveries {
+		if now.Sub(at) > deliveryTTL {
+			delete(r.deliveries, id)
+		}
+	}
+	id := ev.Source + "/" + ev.Delivery
+	if _, ok := r.deliveries[id]; ok {
+		return true
+	}
+	r.deliveries[id] = now
+	return false
+}
+
+func (r *Receiver) fileBead(rigName, key string, route Route, ev *Event) (string, error) {
+	if rigName == "" {
+		return "", fmt.Errorf("no rig for repository %q (set rig on the source or route)", ev.Repo)
+	}
+
+	r.mu.Lock()
+	existing := r.broken[key]
+	r.mu.Unlock()
+	if existing != "" {
+		return "already tracked as " + existing, nil
+	}
+
+	priority := 0
+	if route.Priority != nil {
+		priority = *route.Priority
+	}
+	title := fmt.Sprintf("%s build failed: %s", ev.Branch, ev.Title)
+	if ev.SHA != "" {
+		title += fmt.Sprintf(" (%s)", shortSHA(ev.SHA))
+	}
+	id, err := r.town.CreateBead(rigName, title, buildFailedDescription(ev), priority)
+	if err != nil {
+		return "", fmt.Errorf("creating bead: %w", err)
+	}
+
+	r.mu.Lock()
+	r.broken[key] = id
+	r.mu.Unlock()
+	_ = r.town.Log(events.TypeBuildFailed, actor(ev), eventPayload(rigName, ev))
+
+	if route.NoSling {
+		return "filed " + id, nil
+	}
+	if err := r.town.Sling(id, rigName); err != nil {
+		// The bead exists; a redelivery would not help, so report success
+		// and leave the bead for the rig to pick up.
+		return fmt.Sprintf("filed %s (sling failed: %v)", id, err), nil
+	}
+	return fmt.Sprintf("filed %s, slung to %s", id, rigName), nil
+}
+
+func buildFailedDescription(ev *Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CI reported a failed build on %s", ev.Branch)
+	if ev.Repo != "" {
+		fmt.Fprintf(&b, " of %s", ev.Repo)
+	}
+	b.WriteString(".\n\n")
+	if ev.SHA != "" {
+		fmt.Fprintf(&b, "Commit: %s\n", ev.SHA)
+	}
+	if ev.URL != "" {
+		fmt.Fprintf(&b, "Build: %s\n", ev.URL)
+	}
+	if ev.Actor != "" {
+		fmt.Fprintf(&b, "Triggered by: %s\n", ev.Actor)
+	}
+	b.WriteString("\nFind the breaking change, fix it, and get the branch green again.")
+	return b.String()
+}
+
+func (r *Receiver) mailOwner(rigName string, route Route, ev *Event) (string, error) {
+	to := PolecatForBranch(rigName, ev.Branch)
+	if to == "" {
+		to = route.To
+	}
+	if to == "" {
+		return "no polecat owns " + ev.Branch, nil
+	}
+
+	subject := fmt.Sprintf("Review comment on %s", ev.Title)
+	var b strings.Builder
+	who := ev.Actor
+	if who == "" {
+		who = "A reviewer"
+	}
+	fmt.Fprintf(&b, "%s commented on your branch %s", who, ev.Branch)
+	if ev.Status == "changes_requested" {
+		b.WriteString(" and requested changes")
+	}
+	b.WriteString(":\n\n")
+	b.WriteString(strings.TrimSpace(ev.Body))
+	if ev.URL != "" {
+		fmt.Fprintf(&b, "\n\n%s", ev.URL)
+	}
+
+	msg := mail.NewMessage(MailFrom, to, subject, b.String())
+	msg.Type = mail.TypeTask
+	if err := r.town.Mail(msg); err != nil {
+		return "", fmt.Errorf("mailing %s: %w", to, err)
+	}
+	return "mailed " + to, nil
+}
+
+// PolecatForBranch returns the mail address of the polecat that owns a
+// branch (polecat/<name>/..., polecat/<name>-<ts> or polecat/<name>), or ""
+// if the branch is not a polecat branch.
+func PolecatForBranch(rigName, branch string) string {
+	name, ok := strings.CutPrefix(branch, constants.BranchPolecatPrefix)
+	if !ok || rigName == "" {
+		return ""
+	}
+	if i := strings.Index(name, "/"); i >= 0 {
+		name = name[:i]
+	} else if i := strings.LastIndex(name, "-"); i > 0 {
+		name = name[:i]
+	}
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/polecats/%s", rigName, name)
+}
+
+func actor(ev *Event) string {
+	if ev.Actor != "" {
+		return ev.Actor
+	}
+	return ev.Source
+}
+
+func eventPayload(rigName string, ev *Event) map[string]interface{} {
+	payload := events.WebhookPayload(rigName, ev.Repo, ev.Branch, ev.SHA, ev.URL)
+	payload["source"] = ev.Source
+	if ev.Environment != "" {
+		payload["environment"] = ev.Environment
+	}
+	if ev.Status != "" {
+		payload["status"] = ev.Status
+	}
+	return payload
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// TownActions implements Town for a town on disk.
+type TownActions struct {
+	TownRoot string
+}
+
+// RigForRepo implements Town.
+func (t *TownActions) RigForRepo(repo string) string {
+	if repo == "" {
+		return ""
+	}
+	rigs, err := config.LoadRigsConfig(filepath.Join(t.TownRoot, constants.DirMayor, constants.FileRigsJSON))
+	if err != nil {
+		return ""
+	}
+	for name, entry := range rigs.Rigs {
+		if path, err := forge.RepoFromRemoteURL(entry.GitURL); err == nil && strings.EqualFold(path, repo) {
+			return name
+		}
+	}
+	return ""
+}
+
+// DefaultBranch implements Town.
+func (t *TownActions) DefaultBranch(rigName string) string {
+	r := &rig.Rig{Name: rigName, Path: filepath.Join(t.TownRoot, rigName)}
+	return r.DefaultBranch()
+}
+
+// CreateBead implements Town.
+func (t *TownActions) CreateBead(rigName, title, description string, priority int) (string, error) {
+	dir := beads.GetRigPathForPrefix(t.TownRoot, beads.GetPrefixForRig(t.TownRoot, rigName)+"-")
+	if dir == "" {
+		dir = filepath.Join(t.TownRoot, rigName)
+	}
+	issue, err := beads.New(dir).Create(beads.CreateOptions{
+		Title:       title,
+		Type:        "bug",
+		Priority:    priority,
+		Description: description,
+		Actor:       MailFrom,
+	})
+	if err != nil {
+		return "", err
+	}
+	return issue.ID, nil
+}
+
+// Sling implements Town by running gt sling.
+func (t *TownActions) Sling(bead, rigName string) error {
+	cmd := exec.Command("gt", "sling", bead, rigName)
+	cmd.Dir = t.TownRoot
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Mail implements Town.
+func (t *TownActions) Mail(msg *mail.Message) error {
+	return mail.NewRouter(t.TownRoot).Send(msg)
+}
+
+// Log implements Town.
+func (t *TownActions) Log(eventType, actor string, payload map[string]interface{}) error {
+	return events.LogFeed(eventType, actor, payload)
+}
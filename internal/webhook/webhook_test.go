@@ -0,0 +1,302 @@
+package webhook
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+type fakeTown struct {
+	beads  []string
+	slung  []string
+	mailed []*mail.Message
+	logged []string
+}
+
+func (t *fakeTown) RigForRepo(repo string) string {
+	if repo == "acme/widgets" {
+		return "widgets"
+	}
+	return ""
+}
+
+func (t *fakeTown) DefaultBranch(rig string) string { return "main" }
+
+func (t *fakeTown) CreateBead(rig, title, description string, priority int) (string, error) {
+	id := fmt.Sprintf("wd-%d", len(t.beads)+1)
+	t.beads = append(t.beads, fmt.Sprintf("%s %s P%d %s", id, rig, priority, title))
+	return id, nil
+}
+
+func (t *fakeTown) Sling(bead, rig string) error {
+	t.slung = append(t.slung, bead+" "+rig)
+	return nil
+}
+
+func (t *fakeTown) Mail(msg *mail.Message) error {
+	t.mailed = append(t.mailed, msg)
+	return nil
+}
+
+func (t *fakeTown) Log(eventType, actor string, payload map[string]interface{}) error {
+	t.logged = append(t.logged, eventType+" "+actor)
+	return nil
+}
+
+const testSecret = "s3cret"
+
+func newTestReceiver(t *testing.T, toml string) (*fakeTown, *httptest.Server) {
+	t.Helper()
+	t.Setenv("GT_TEST_WEBHOOK_SECRET", testSecret)
+	path := filepath.Join(t.TempDir(), ConfigFile)
+	if err := os.WriteFile(path, []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	town := &fakeTown{}
+	mux := http.NewServeMux()
+	mux.Handle(PathPrefix, NewReceiver(file, town, t.Logf))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return town, srv
+}
+
+const sourcesTOML = `
+[[source]]
+name = "gh"
+kind = "github"
+secret_env = "GT_TEST_WEBHOOK_SECRET"
+
+[[source]]
+name = "gl"
+kind = "gitlab"
+secret_env = "GT_TEST_WEBHOOK_SECRET"
+rig = "widgets"
+`
+
+func post(t *testing.T, srv *httptest.Server, source string, header map[string]string, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, srv.URL+PathPrefix+source, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(out)
+}
+
+func githubHeaders(event, delivery, body string) map[string]string {
+	return map[string]string{
+		"X-GitHub-Event":      event,
+		"X-GitHub-Delivery":   delivery,
+		"X-Hub-Signature-256": Sign(testSecret, []byte(body)),
+	}
+}
+
+func workflowRun(branch, conclusion string) string {
+	return fmt.Sprintf(`{"action":"completed","repository":{"full_name":"acme/widgets"},"sender":{"login":"ci-bot"},
+		"workflow_run":{"name":"CI","head_branch":%q,"head_sha":"0123456789abcdef","html_url":"https://github.com/acme/widgets/actions/runs/1","conclusion":%q}}`,
+		branch, conclusion)
+}
+
+func TestFailedMainBuildFilesAndSlingsBead(t *testing.T) {
+	town, srv := newTestReceiver(t, sourcesTOML)
+
+	body := workflowRun("main", "failure")
+	code, out := post(t, srv, "gh", githubHeaders("workflow_run", "d1", body), body)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, out)
+	}
+	if len(town.beads) != 1 || town.beads[0] != "wd-1 widgets P0 main build failed: CI (01234567)" {
+		t.Fatalf("beads = %v", town.beads)
+	}
+	if len(town.slung) != 1 || town.slung[0] != "wd-1 widgets" {
+		t.Errorf("slung = %v", town.slung)
+	}
+
+	// Redelivery and further failures while main is red file nothing new.
+	post(t, srv, "gh", githubHeaders("workflow_run", "d1", body), body)
+	post(t, srv, "gh", githubHeaders("workflow_run", "d2", body), body)
+	if len(town.beads) != 1 {
+		t.Errorf("beads after repeats = %v", town.beads)
+	}
+
+	// A passing build clears it; the next failure files again.
+	pass := workflowRun("main", "success")
+	post(t, srv, "gh", githubHeaders("workflow_run", "d3", pass), pass)
+	post(t, srv, "gh", githubHeaders("workflow_run", "d4", body), body)
+	if len(town.beads) != 2 {
+		t.Errorf("beads after green→red = %v", town.beads)
+	}
+
+	// Feature branch failures are not routed by default.
+	feature := workflowRun("polecat/toast/wd-9@abc", "failure")
+	post(t, srv, "gh", githubHeaders("workflow_run", "d5", feature), feature)
+	if len(town.beads) != 2 {
+		t.Errorf("feature branch failure filed a bead: %v", town.beads)
+	}
+}
+
+func TestSignatureRequired(t *testing.T) {
+	town, srv := newTestReceiver(t, sourcesTOML)
+	body := workflowRun("main", "failure")
+
+	hdr := githubHeaders("workflow_run", "d1", body)
+	hdr["X-Hub-Signature-256"] = Sign("wrong", []byte(body))
+	if code, _ := post(t, srv, "gh", hdr, body); code != http.StatusUnauthorized {
+		t.Errorf("bad signature status = %d, want 401", code)
+	}
+	if code, _ := post(t, srv, "gl", map[string]string{"X-Gitlab-Event": "Pipeline Hook", "X-Gitlab-Token": "nope"}, `{}`); code != http.StatusUnauthorized {
+		t.Errorf("bad gitlab token status = %d, want 401", code)
+	}
+	if code, _ := post(t, srv, "unknown", nil, body); code != http.StatusNotFound {
+		t.Errorf("unknown source status = %d, want 404", code)
+	}
+	if len(town.beads) != 0 {
+		t.Errorf("beads = %v", town.beads)
+	}
+}
+
+func TestReviewCommentMailsPolecat(t *testing.T) {
+	town, srv := newTestReceiver(t, sourcesTOML)
+
+	body := `{"action":"created","repository":{"full_name":"acme/widgets"},
+		"pull_request":{"number":7,"title":"Add sprockets","head":{"ref":"polecat/Toast/wd-12@lx1","sha":"abc"}},
+		"comment":{"body":"Please handle nil here.","html_url":"https://github.com/acme/widgets/pull/7#c1","path":"sprocket.go","user":{"login":"alice"}}}`
+	code, out := post(t, srv, "gh", githubHeaders("pull_request_review_comment", "r1", body), body)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, out)
+	}
+	if len(town.mailed) != 1 {
+		t.Fatalf("mailed = %d, want 1", len(town.mailed))
+	}
+	msg := town.mailed[0]
+	if msg.To != "widgets/polecats/Toast" || msg.From != MailFrom || msg.Type != mail.TypeTask {
+		t.Errorf("msg = %s → %s (%s)", msg.From, msg.To, msg.Type)
+	}
+	if msg.Subject != "Review comment on #7 Add sprockets" {
+		t.Errorf("subject = %q", msg.Subject)
+	}
+	for _, want := range []string{"alice commented", "On sprocket.go:", "Please handle nil here.", "pull/7#c1"} {
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("body missing %q:\n%s", want, msg.Body)
+		}
+	}
+
+	// Comments on branches no polecat owns are dropped.
+	body = strings.ReplaceAll(body, "polecat/Toast/wd-12@lx1", "feature/x")
+	post(t, srv, "gh", githubHeaders("pull_request_review_comment", "r2", body), body)
+	if len(town.mailed) != 1 {
+		t.Errorf("mailed = %d after unowned comment", len(town.mailed))
+	}
+}
+
+func TestGitLabPipelineAndDeploy(t *testing.T) {
+	town, srv := newTestReceiver(t, sourcesTOML)
+	hdr := func(event string) map[string]string {
+		return map[string]string{"X-Gitlab-Event": event, "X-Gitlab-Token": testSecret}
+	}
+
+	pipeline := `{"user":{"username":"bob"},"project":{"path_with_namespace":"team/gadgets","web_url":"https://gitlab.com/team/gadgets"},
+		"object_attributes":{"id":42,"status":"failed","ref":"main","sha":"feedface00"}}`
+	if code, out := post(t, srv, "gl", hdr("Pipeline Hook"), pipeline); code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, out)
+	}
+	// The repo matches no rig, so the source's rig is used.
+	if len(town.beads) != 1 || !strings.HasPrefix(town.beads[0], "wd-1 widgets P0 main build failed: pipeline 42") {
+		t.Errorf("beads = %v", town.beads)
+	}
+
+	deploy := `{"status":"success","environment":"production","ref":"main","short_sha":"feedface","deployable_url":"https://gitlab.com/team/gadgets/-/jobs/9","user":{"username":"bob"},"project":{"path_with_namespace":"team/gadgets"}}`
+	post(t, srv, "gl", hdr("Deployment Hook"), deploy)
+	if len(town.logged) != 2 || town.logged[1] != "deploy bob" {
+		t.Errorf("logged = %v", town.logged)
+	}
+}
+
+func TestRoutesOverrideDefaults(t *testing.T) {
+	town, srv := newTestReceiver(t, sourcesTOML+`
+[[route]]
+event = "build_failed"
+branch = "release/*"
+priority = 1
+no_sling = true
+
+[[route]]
+event = "deploy"
+action = "ignore"
+`)
+	body := workflowRun("release/2.0", "failure")
+	post(t, srv, "gh", githubHeaders("workflow_run", "d1", body), body)
+	if len(town.beads) != 1 || !strings.Contains(town.beads[0], "P1 release/2.0 build failed") {
+		t.Errorf("beads = %v", town.beads)
+	}
+	if len(town.slung) != 0 {
+		t.Errorf("no_sling route slung: %v", town.slung)
+	}
+
+	// Default route still applies to main.
+	body = workflowRun("main", "timed_out")
+	post(t, srv, "gh", githubHeaders("workflow_run", "d2", body), body)
+	if len(town.beads) != 2 || len(town.slung) != 1 {
+		t.Errorf("beads = %v slung = %v", town.beads, town.slung)
+	}
+
+	deploy := `{"repository":{"full_name":"acme/widgets"},"deployment":{"ref":"main","sha":"abc","environment":"prod"},"deployment_status":{"state":"success"}}`
+	post(t, srv, "gh", githubHeaders("deployment_status", "d3", deploy), deploy)
+	if len(town.logged) != 2 { // the two build_failed beads only
+		t.Errorf("logged = %v", town.logged)
+	}
+}
+
+func TestLoadFileValidation(t *testing.T) {
+	for name, content := range map[string]string{
+		"bad kind":   "[[source]]\nname = \"x\"\nkind = \"jenkins\"\n",
+		"dup source": "[[source]]\nname = \"x\"\nkind = \"github\"\n[[source]]\nname = \"x\"\nkind = \"github\"\n",
+		"bad event":  "[[route]]\nevent = \"push\"\n",
+		"bad action": "[[route]]\nevent = \"deploy\"\naction = \"page\"\n",
+	} {
+		path := filepath.Join(t.TempDir(), ConfigFile)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("%s: LoadFile accepted invalid config", name)
+		}
+	}
+	if f, err := LoadFile(filepath.Join(t.TempDir(), "missing.toml")); f != nil || err != nil {
+		t.Errorf("missing file = %v, %v; want nil, nil", f, err)
+	}
+}
+
+func TestPolecatForBranch(t *testing.T) {
+	tests := map[string]string{
+		"polecat/Toast/gt-12@lx1": "gastown/polecats/Toast",
+		"polecat/Toast-lx1":       "gastown/polecats/Toast",
+		"polecat/Toast":           "gastown/polecats/Toast",
+		"feature/x":               "",
+		"main":                    "",
+	}
+	for branch, want := range tests {
+		if got := PolecatForBranch("gastown", branch); got != want {
+			t.Errorf("PolecatForBranch(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
@@ -1,5 +1,5 @@
 // ABOUTME: Manages wrapper scripts for non-Claude agentic coding tools.
-// ABOUTME: Provides gt-codex and gt-opencode wrappers that run gt prime first.
+// ABOUTME: Each runs gt prime first, unless the agent already runs under gt hooks emulation.
 
 package wrappers
 
@@ -13,6 +13,9 @@ import (
 //go:embed scripts/*
 var scriptsFS embed.FS
 
+// names lists the installed wrapper scripts.
+var names = []string{"gt-codex", "gt-opencode", "gt-gemini", "gt-cursor", "gt-auggie", "gt-amp"}
+
 func Install() error {
 	binDir, err := binPath()
 	if err != nil {
@@ -23,8 +26,7 @@ func Install() error {
 		return fmt.Errorf("creating bin directory: %w", err)
 	}
 
-	wrappers := []string{"gt-codex", "gt-opencode"}
-	for _, name := range wrappers {
+	for _, name := range names {
 		content, err := scriptsFS.ReadFile("scripts/" + name)
 		if err != nil {
 			return fmt.Errorf("reading embedded %s: %w", name, err)
@@ -45,8 +47,7 @@ func Remove() error {
 		return err
 	}
 
-	wrappers := []string{"gt-codex", "gt-opencode"}
-	for _, name := range wrappers {
+	for _, name := range names {
 		destPath := filepath.Join(binDir, name)
 		if err := os.Remove(destPath); err != nil && !os.IsNotExist(err) {
 			return fmt.Errorf("removing %s: %w", name, err)